@@ -38,6 +38,11 @@ func deduplicate(sds []*cb.SignedData) []*cb.SignedData {
 // compile recursively builds a go evaluatable function corresponding to the policy specified, remember to call deduplicate on identities before
 // passing them to this function for evaluation
 func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserializer msp.IdentityDeserializer) (func([]*cb.SignedData, []bool) bool, error) {
+	return compileWith(policy, identities, deserializer, true)
+}
+
+// compileWith builds the function as compile does, which verifies the signatures of the identities only if verify is set
+func compileWith(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserializer msp.IdentityDeserializer, verify bool) (func([]*cb.SignedData, []bool) bool, error) {
 	if policy == nil {
 		return nil, fmt.Errorf("Empty policy element")
 	}
@@ -46,7 +51,7 @@ func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserial
 	case *cb.SignaturePolicy_NOutOf_:
 		policies := make([]func([]*cb.SignedData, []bool) bool, len(t.NOutOf.Rules))
 		for i, policy := range t.NOutOf.Rules {
-			compiledPolicy, err := compile(policy, identities, deserializer)
+			compiledPolicy, err := compileWith(policy, identities, deserializer, verify)
 			if err != nil {
 				return nil, err
 			}
@@ -101,10 +106,12 @@ func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserial
 					continue
 				}
 				cauthdslLogger.Debugf("%p principal matched by identity %d", signedData, i)
-				err = identity.Verify(sd.Data, sd.Signature)
-				if err != nil {
-					cauthdslLogger.Debugf("%p signature for identity %d is invalid: %s", signedData, i, err)
-					continue
+				if verify {
+					err = identity.Verify(sd.Data, sd.Signature)
+					if err != nil {
+						cauthdslLogger.Debugf("%p signature for identity %d is invalid: %s", signedData, i, err)
+						continue
+					}
 				}
 				cauthdslLogger.Debugf("%p principal evaluation succeeds for identity %d", signedData, i)
 				used[i] = true
@@ -44,14 +44,21 @@ func (pr *provider) NewPolicy(data []byte) (policies.Policy, proto.Message, erro
 		return nil, nil, err
 	}
 
+	identityEvaluator, err := compileWith(sigPolicy.Rule, sigPolicy.Identities, pr.deserializer, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &policy{
-		evaluator: compiled,
+		evaluator:         compiled,
+		identityEvaluator: identityEvaluator,
 	}, sigPolicy, nil
 
 }
 
 type policy struct {
-	evaluator func([]*cb.SignedData, []bool) bool
+	evaluator         func([]*cb.SignedData, []bool) bool
+	identityEvaluator func([]*cb.SignedData, []bool) bool
 }
 
 // Evaluate takes a set of SignedData and evaluates whether this set of signatures satisfies the policy
@@ -66,3 +73,22 @@ func (p *policy) Evaluate(signatureSet []*cb.SignedData) error {
 	}
 	return nil
 }
+
+// EvaluateIdentities takes a set of serialized identities, which were authenticated by the caller, and evaluates
+// whether they satisfy the policy without verifying any signature
+func (p *policy) EvaluateIdentities(identities [][]byte) error {
+	if p == nil || p.identityEvaluator == nil {
+		return fmt.Errorf("No such policy")
+	}
+
+	signatureSet := make([]*cb.SignedData, len(identities))
+	for i, identity := range identities {
+		signatureSet[i] = &cb.SignedData{Identity: identity}
+	}
+	signatureSet = deduplicate(signatureSet)
+	ok := p.identityEvaluator(signatureSet, make([]bool, len(signatureSet)))
+	if !ok {
+		return errors.New("Failed to authenticate policy")
+	}
+	return nil
+}
@@ -112,3 +112,26 @@ func TestRejectOnUnknown(t *testing.T) {
 		t.Fatal("Should have errored evaluating the default policy")
 	}
 }
+
+func TestEvaluateIdentities(t *testing.T) {
+	policyID := "policyID"
+	m := policies.NewManagerImpl("test", providerMap())
+	addPolicy(m, policyID, &cb.Policy{
+		Type:  int32(cb.Policy_SIGNATURE),
+		Value: marshalOrPanic(Envelope(SignedBy(0), signers)),
+	})
+	policy, ok := m.GetPolicy(policyID)
+	if !ok {
+		t.Fatal("Should have found policy which was just added, but did not")
+	}
+	evaluator, ok := policy.(policies.IdentityEvaluator)
+	if !ok {
+		t.Fatal("Signature policies should evaluate identities")
+	}
+	if err := evaluator.EvaluateIdentities([][]byte{signers[0]}); err != nil {
+		t.Fatalf("Should not have errored evaluating the identity of the principal: %s", err)
+	}
+	if err := evaluator.EvaluateIdentities([][]byte{signers[1]}); err == nil {
+		t.Fatal("Should have errored evaluating an identity which is not the principal")
+	}
+}
@@ -89,6 +89,22 @@ type Orderer interface {
 	// MaxChannelsCount returns the maximum count of channels to allow for an ordering network
 	MaxChannelsCount() uint64
 
+	// BroadcastQueueDepth returns the maximum number of broadcast messages of the
+	// channel waiting to be ordered, or 0 if each orderer enforces its own
+	BroadcastQueueDepth() uint32
+
+	// BroadcastQuota returns the limits on the broadcast messages of the
+	// channel submitted by each identity, across all its connections
+	BroadcastQuota() *ab.BroadcastQuota
+
+	// ReplayWindowSize returns the number of recently ordered messages of the
+	// channel tracked to reject their replays, or 0 for the default
+	ReplayWindowSize() uint32
+
+	// ReplayTTL returns how long a message of the channel is tracked to
+	// reject its replays, or 0 if it is only tracked by the window size
+	ReplayTTL() time.Duration
+
 	// KafkaBrokers returns the addresses (IP:port notation) of a set of "bootstrap"
 	// Kafka brokers, i.e. this is not necessarily the entire set of Kafka brokers
 	// used for ordering
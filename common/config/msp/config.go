@@ -81,6 +81,23 @@ func (bh *MSPConfigHandler) CommitProposals(tx interface{}) {
 	delete(bh.pendingConfig, tx)
 }
 
+// DeserializeIdentity deserializes the identity with the MSP manager of the
+// config, failing if the config defines no MSPs
+func (bh *MSPConfigHandler) DeserializeIdentity(serializedID []byte) (msp.Identity, error) {
+	if bh.MSPManager == nil {
+		return nil, fmt.Errorf("no MSPs are defined")
+	}
+	return bh.MSPManager.DeserializeIdentity(serializedID)
+}
+
+// GetMSPs returns the MSPs of the config, failing if it defines none
+func (bh *MSPConfigHandler) GetMSPs() (map[string]msp.MSP, error) {
+	if bh.MSPManager == nil {
+		return nil, fmt.Errorf("no MSPs are defined")
+	}
+	return bh.MSPManager.GetMSPs()
+}
+
 // ProposeValue called when config is added to a proposal
 func (bh *MSPConfigHandler) ProposeMSP(tx interface{}, mspConfig *mspprotos.MSPConfig) (msp.MSP, error) {
 	bh.pendingLock.RLock()
@@ -108,3 +108,15 @@ func TestTemplates(t *testing.T) {
 	}, "Expected panic with bad msp config")
 
 }
+
+func TestNoMSPs(t *testing.T) {
+	mspCH := NewMSPConfigHandler()
+	mspCH.BeginConfig(t)
+	assert.NoError(t, mspCH.PreCommit(t))
+	mspCH.CommitProposals(t)
+
+	_, err := mspCH.DeserializeIdentity([]byte("identity"))
+	assert.EqualError(t, err, "no MSPs are defined")
+	_, err = mspCH.GetMSPs()
+	assert.EqualError(t, err, "no MSPs are defined")
+}
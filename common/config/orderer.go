@@ -47,6 +47,15 @@ const (
 
 	// KafkaBrokersKey is the cb.ConfigItem type key name for the KafkaBrokers message
 	KafkaBrokersKey = "KafkaBrokers"
+
+	// BroadcastQueueKey is the cb.ConfigItem type key name for the BroadcastQueue message
+	BroadcastQueueKey = "BroadcastQueue"
+
+	// BroadcastQuotaKey is the cb.ConfigItem type key name for the BroadcastQuota message
+	BroadcastQuotaKey = "BroadcastQuota"
+
+	// ReplayWindowKey is the cb.ConfigItem type key name for the ReplayWindow message
+	ReplayWindowKey = "ReplayWindow"
 )
 
 // OrdererProtos is used as the source of the OrdererConfig
@@ -56,6 +65,9 @@ type OrdererProtos struct {
 	BatchTimeout        *ab.BatchTimeout
 	KafkaBrokers        *ab.KafkaBrokers
 	ChannelRestrictions *ab.ChannelRestrictions
+	BroadcastQueue      *ab.BroadcastQueue
+	BroadcastQuota      *ab.BroadcastQuota
+	ReplayWindow        *ab.ReplayWindow
 }
 
 // Config is stores the orderer component configuration
@@ -92,6 +104,7 @@ type OrdererConfig struct {
 	orgs         map[string]Org
 
 	batchTimeout time.Duration
+	replayTTL    time.Duration
 }
 
 // NewOrdererConfig creates a new instance of the orderer config
@@ -141,6 +154,30 @@ func (oc *OrdererConfig) MaxChannelsCount() uint64 {
 	return oc.protos.ChannelRestrictions.MaxCount
 }
 
+// BroadcastQueueDepth returns the maximum number of broadcast messages of the
+// channel waiting to be ordered, or 0 if each orderer enforces its own
+func (oc *OrdererConfig) BroadcastQueueDepth() uint32 {
+	return oc.protos.BroadcastQueue.MaxDepth
+}
+
+// BroadcastQuota returns the limits on the broadcast messages of the channel
+// submitted by each identity
+func (oc *OrdererConfig) BroadcastQuota() *ab.BroadcastQuota {
+	return oc.protos.BroadcastQuota
+}
+
+// ReplayWindowSize returns the number of recently ordered messages of the
+// channel tracked to reject their replays, or 0 for the default
+func (oc *OrdererConfig) ReplayWindowSize() uint32 {
+	return oc.protos.ReplayWindow.Size
+}
+
+// ReplayTTL returns how long a message of the channel is tracked to reject
+// its replays, or 0 if it is only tracked by the window size
+func (oc *OrdererConfig) ReplayTTL() time.Duration {
+	return oc.replayTTL
+}
+
 // Organizations returns a map of the orgs in the channel
 func (oc *OrdererConfig) Organizations() map[string]Org {
 	return oc.orgs
@@ -152,6 +189,7 @@ func (oc *OrdererConfig) Validate(tx interface{}, groups map[string]ValuePropose
 		oc.validateBatchSize,
 		oc.validateBatchTimeout,
 		oc.validateKafkaBrokers,
+		oc.validateReplayWindow,
 	} {
 		if err := validator(); err != nil {
 			return err
@@ -206,6 +244,22 @@ func (oc *OrdererConfig) validateBatchTimeout() error {
 	return nil
 }
 
+func (oc *OrdererConfig) validateReplayWindow() error {
+	oc.replayTTL = 0
+	if oc.protos.ReplayWindow.Ttl == "" {
+		return nil
+	}
+	var err error
+	oc.replayTTL, err = time.ParseDuration(oc.protos.ReplayWindow.Ttl)
+	if err != nil {
+		return fmt.Errorf("Attempted to set the replay TTL to a invalid value: %s", err)
+	}
+	if oc.replayTTL < 0 {
+		return fmt.Errorf("Attempted to set the replay TTL to a negative value: %s", oc.replayTTL)
+	}
+	return nil
+}
+
 func (oc *OrdererConfig) validateKafkaBrokers() error {
 	for _, broker := range oc.protos.KafkaBrokers.Brokers {
 		if !brokerEntrySeemsValid(broker) {
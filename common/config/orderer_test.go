@@ -18,6 +18,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	ab "github.com/hyperledger/fabric/protos/orderer"
 
@@ -83,3 +84,38 @@ func TestKafkaBrokers(t *testing.T) {
 	oc = &OrdererConfig{protos: &OrdererProtos{KafkaBrokers: &ab.KafkaBrokers{Brokers: []string{"127.0.0.1", "foo.bar", "127.0.0.1:-1", "localhost:65536", "foo.bar.:9092", ".127.0.0.1:9092", "-foo.bar:9092"}}}}
 	assert.Error(t, oc.validateKafkaBrokers(), "Invalid kafka brokers")
 }
+
+func TestBroadcastQueueDepth(t *testing.T) {
+	oc := NewOrdererConfig(NewOrdererGroup(nil))
+	assert.Equal(t, uint32(0), oc.BroadcastQueueDepth(), "Channels without a broadcast queue depth should defer to the local config")
+
+	oc = &OrdererConfig{protos: &OrdererProtos{BroadcastQueue: &ab.BroadcastQueue{MaxDepth: 5000}}}
+	assert.Equal(t, uint32(5000), oc.BroadcastQueueDepth())
+}
+
+func TestBroadcastQuota(t *testing.T) {
+	oc := NewOrdererConfig(NewOrdererGroup(nil))
+	assert.Equal(t, &ab.BroadcastQuota{}, oc.BroadcastQuota(), "Channels without a broadcast quota should not limit identities")
+
+	quota := &ab.BroadcastQuota{MaxInFlight: 100, Rate: 50, Burst: 10}
+	oc = &OrdererConfig{protos: &OrdererProtos{BroadcastQuota: quota}}
+	assert.Equal(t, quota, oc.BroadcastQuota())
+}
+
+func TestReplayWindow(t *testing.T) {
+	oc := NewOrdererConfig(NewOrdererGroup(nil))
+	assert.NoError(t, oc.validateReplayWindow(), "Channels without a replay window should use the default")
+	assert.Equal(t, uint32(0), oc.ReplayWindowSize())
+	assert.Equal(t, time.Duration(0), oc.ReplayTTL())
+
+	oc = &OrdererConfig{protos: &OrdererProtos{ReplayWindow: &ab.ReplayWindow{Size: 100, Ttl: "1h"}}}
+	assert.NoError(t, oc.validateReplayWindow(), "Valid replay window")
+	assert.Equal(t, uint32(100), oc.ReplayWindowSize())
+	assert.Equal(t, time.Hour, oc.ReplayTTL())
+
+	oc = &OrdererConfig{protos: &OrdererProtos{ReplayWindow: &ab.ReplayWindow{Ttl: "-1s"}}}
+	assert.Error(t, oc.validateReplayWindow(), "Negative replay TTL")
+
+	oc = &OrdererConfig{protos: &OrdererProtos{ReplayWindow: &ab.ReplayWindow{Ttl: "soon"}}}
+	assert.Error(t, oc.validateReplayWindow(), "Invalid replay TTL")
+}
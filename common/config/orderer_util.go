@@ -51,6 +51,21 @@ func TemplateChannelRestrictions(maxChannels uint64) *cb.ConfigGroup {
 	return ordererConfigGroup(ChannelRestrictionsKey, utils.MarshalOrPanic(&ab.ChannelRestrictions{MaxCount: maxChannels}))
 }
 
+// TemplateBroadcastQueue creates a config group with BroadcastQueue specified
+func TemplateBroadcastQueue(maxDepth uint32) *cb.ConfigGroup {
+	return ordererConfigGroup(BroadcastQueueKey, utils.MarshalOrPanic(&ab.BroadcastQueue{MaxDepth: maxDepth}))
+}
+
+// TemplateBroadcastQuota creates a config group with BroadcastQuota specified
+func TemplateBroadcastQuota(quota *ab.BroadcastQuota) *cb.ConfigGroup {
+	return ordererConfigGroup(BroadcastQuotaKey, utils.MarshalOrPanic(quota))
+}
+
+// TemplateReplayWindow creates a config group with ReplayWindow specified
+func TemplateReplayWindow(window *ab.ReplayWindow) *cb.ConfigGroup {
+	return ordererConfigGroup(ReplayWindowKey, utils.MarshalOrPanic(window))
+}
+
 // TemplateKafkaBrokers creates a headerless config item representing the kafka brokers
 func TemplateKafkaBrokers(brokers []string) *cb.ConfigGroup {
 	return ordererConfigGroup(KafkaBrokersKey, utils.MarshalOrPanic(&ab.KafkaBrokers{Brokers: brokers}))
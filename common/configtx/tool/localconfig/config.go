@@ -133,6 +133,43 @@ type Orderer struct {
 	Kafka         Kafka           `yaml:"Kafka"`
 	Organizations []*Organization `yaml:"Organizations"`
 	MaxChannels   uint64          `yaml:"MaxChannels"`
+	BlockSigners  BlockSigners    `yaml:"BlockSigners"`
+	// BroadcastQueueDepth is the number of broadcast messages of each
+	// channel waiting to be ordered beyond which further messages are
+	// refused, 0 deferring to the local config of each orderer
+	BroadcastQueueDepth uint32         `yaml:"BroadcastQueueDepth"`
+	BroadcastQuota      BroadcastQuota `yaml:"BroadcastQuota"`
+	ReplayWindow        ReplayWindow   `yaml:"ReplayWindow"`
+}
+
+// BroadcastQuota contains the limits on the broadcast messages of each
+// channel submitted by each identity, across all its connections. At most
+// MaxInFlight messages of an identity are admitted but not yet committed,
+// and an identity may submit Rate messages per second, in bursts of up to
+// Burst messages. A value of 0 means no limit.
+type BroadcastQuota struct {
+	MaxInFlight uint32  `yaml:"MaxInFlight"`
+	Rate        float64 `yaml:"Rate"`
+	Burst       uint32  `yaml:"Burst"`
+}
+
+// ReplayWindow contains the extent of the recently ordered messages of each
+// channel tracked to reject their replays. The Size most recently ordered
+// messages are tracked, 0 meaning the default, and if TTL is positive, only
+// until a message timestamped TTL later is ordered.
+type ReplayWindow struct {
+	Size uint32        `yaml:"Size"`
+	TTL  time.Duration `yaml:"TTL"`
+}
+
+// BlockSigners contains configuration of the signatures each block must carry.
+// When Required is set, the block validation policy requires that many
+// signatures by orderers each satisfying a different one of the Principals,
+// of the form "MSPID.member" or "MSPID.admin". Otherwise a single signature by
+// any orderer satisfies it.
+type BlockSigners struct {
+	Required   int32    `yaml:"Required"`
+	Principals []string `yaml:"Principals"`
 }
 
 // BatchSize contains configuration affecting the size of batches.
@@ -18,6 +18,7 @@ package provisional
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/config"
@@ -30,6 +31,7 @@ import (
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -120,12 +122,30 @@ func New(conf *genesisconfig.Profile) Generator {
 			config.TemplateChannelRestrictions(conf.Orderer.MaxChannels),
 
 			// Initialize the default Reader/Writer/Admins orderer policies, as well as block validation policy
-			policies.TemplateImplicitMetaPolicyWithSubPolicy([]string{config.OrdererGroupKey}, BlockValidationPolicyKey, configvaluesmsp.WritersPolicyKey, cb.ImplicitMetaPolicy_ANY),
+			blockValidationPolicy(conf.Orderer.BlockSigners),
 			policies.TemplateImplicitMetaAnyPolicy([]string{config.OrdererGroupKey}, configvaluesmsp.ReadersPolicyKey),
 			policies.TemplateImplicitMetaAnyPolicy([]string{config.OrdererGroupKey}, configvaluesmsp.WritersPolicyKey),
 			policies.TemplateImplicitMetaMajorityPolicy([]string{config.OrdererGroupKey}, configvaluesmsp.AdminsPolicyKey),
 		}
 
+		if conf.Orderer.BroadcastQueueDepth > 0 {
+			bs.ordererGroups = append(bs.ordererGroups, config.TemplateBroadcastQueue(conf.Orderer.BroadcastQueueDepth))
+		}
+		if quota := conf.Orderer.BroadcastQuota; quota.MaxInFlight > 0 || quota.Rate > 0 {
+			bs.ordererGroups = append(bs.ordererGroups, config.TemplateBroadcastQuota(&ab.BroadcastQuota{
+				MaxInFlight: quota.MaxInFlight,
+				Rate:        quota.Rate,
+				Burst:       quota.Burst,
+			}))
+		}
+		if window := conf.Orderer.ReplayWindow; window.Size > 0 || window.TTL > 0 {
+			replayWindow := &ab.ReplayWindow{Size: window.Size}
+			if window.TTL > 0 {
+				replayWindow.Ttl = window.TTL.String()
+			}
+			bs.ordererGroups = append(bs.ordererGroups, config.TemplateReplayWindow(replayWindow))
+		}
+
 		for _, org := range conf.Orderer.Organizations {
 			mspConfig, err := msp.GetVerifyingMspConfig(org.MSPDir, org.ID)
 			if err != nil {
@@ -274,3 +294,36 @@ func (bs *bootstrapper) GenesisBlockForChannel(channelID string) *cb.Block {
 	}
 	return block
 }
+
+// blockValidationPolicy returns the block validation policy of the orderer
+// group, which is satisfied by the signature of any orderer unless the
+// signatures of several are required
+func blockValidationPolicy(signers genesisconfig.BlockSigners) *cb.ConfigGroup {
+	if signers.Required == 0 {
+		return policies.TemplateImplicitMetaPolicyWithSubPolicy([]string{config.OrdererGroupKey}, BlockValidationPolicyKey, configvaluesmsp.WritersPolicyKey, cb.ImplicitMetaPolicy_ANY)
+	}
+	if signers.Required < 0 || int(signers.Required) > len(signers.Principals) {
+		logger.Panicf("Invalid block signers: %d required of %d principals", signers.Required, len(signers.Principals))
+	}
+
+	principals := make([]*mspproto.MSPPrincipal, len(signers.Principals))
+	rules := make([]*cb.SignaturePolicy, len(signers.Principals))
+	for i, principal := range signers.Principals {
+		dot := strings.LastIndex(principal, ".")
+		role, ok := mspproto.MSPRole_MSPRoleType_value[strings.ToUpper(principal[dot+1:])]
+		if dot <= 0 || !ok {
+			logger.Panicf("Invalid block signer principal %s, expected MSPID.member or MSPID.admin", principal)
+		}
+		principals[i] = &mspproto.MSPPrincipal{
+			PrincipalClassification: mspproto.MSPPrincipal_ROLE,
+			Principal:               utils.MarshalOrPanic(&mspproto.MSPRole{MspIdentifier: principal[:dot], Role: mspproto.MSPRole_MSPRoleType(role)}),
+		}
+		rules[i] = cauthdsl.SignedBy(int32(i))
+	}
+	root := cb.NewConfigGroup()
+	root.Groups[config.OrdererGroupKey] = cauthdsl.TemplatePolicy(BlockValidationPolicyKey, &cb.SignaturePolicyEnvelope{
+		Rule:       cauthdsl.NOutOf(signers.Required, rules),
+		Identities: principals,
+	})
+	return root
+}
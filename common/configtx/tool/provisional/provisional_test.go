@@ -20,9 +20,13 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var confSolo *genesisconfig.Profile
@@ -71,3 +75,28 @@ func TestGenesisBlockForChannelHeader(t *testing.T) {
 		assert.Nil(t, genesisBlock.Header.PreviousHash, "Case %s: Header previousHash to be nil", tc.Orderer.OrdererType)
 	}
 }
+
+func TestBlockValidationPolicy(t *testing.T) {
+	group := blockValidationPolicy(genesisconfig.BlockSigners{})
+	policy := group.Groups[config.OrdererGroupKey].Policies[BlockValidationPolicyKey].Policy
+	assert.Equal(t, int32(cb.Policy_IMPLICIT_META), policy.Type, "Any orderer should sign blocks by default")
+
+	group = blockValidationPolicy(genesisconfig.BlockSigners{Required: 2, Principals: []string{"OrdererMSP1.member", "OrdererMSP2.admin", "OrdererMSP3.member"}})
+	policy = group.Groups[config.OrdererGroupKey].Policies[BlockValidationPolicyKey].Policy
+	require.Equal(t, int32(cb.Policy_SIGNATURE), policy.Type)
+	envelope := &cb.SignaturePolicyEnvelope{}
+	require.NoError(t, proto.Unmarshal(policy.Value, envelope))
+	assert.Len(t, envelope.Identities, 3)
+	assert.Equal(t, int32(2), envelope.Rule.GetNOutOf().N)
+	role := &mspproto.MSPRole{}
+	require.NoError(t, proto.Unmarshal(envelope.Identities[1].Principal, role))
+	assert.Equal(t, "OrdererMSP2", role.MspIdentifier)
+	assert.Equal(t, mspproto.MSPRole_ADMIN, role.Role)
+
+	assert.Panics(t, func() {
+		blockValidationPolicy(genesisconfig.BlockSigners{Required: 2, Principals: []string{"OrdererMSP1.member"}})
+	}, "More signers than principals should not be required")
+	assert.Panics(t, func() {
+		blockValidationPolicy(genesisconfig.BlockSigners{Required: 1, Principals: []string{"OrdererMSP1.peer"}})
+	}, "Principals should be members or admins")
+}
@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package flogging
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/op/go-logging"
+)
+
+// The names of the fields used consistently across modules
+const (
+	ChannelField = "channel"
+	TxHashField  = "tx_hash"
+	ClientField  = "client"
+)
+
+// Fields are structured values attached to each record logged through a
+// FieldLogger. The text format prefixes the message with them, the JSON
+// format emits each as a separate key.
+type Fields map[string]string
+
+// String returns the fields in the form "[key: value, key: value] ", ordered
+// by key, which is how they prefix the message in the text format
+func (f Fields) String() string {
+	if len(f) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + ": " + f[key]
+	}
+	return "[" + strings.Join(pairs, ", ") + "] "
+}
+
+// FieldLogger logs through a Logger, attaching Fields to every record
+type FieldLogger struct {
+	logger *logging.Logger
+	fields Fields
+}
+
+// wrapped holds, for each Logger, a copy reporting the caller of the
+// FieldLogger rather than the FieldLogger itself
+var wrapped sync.Map
+
+// WithFields returns a FieldLogger attaching fields to the records of logger
+func WithFields(logger *logging.Logger, fields Fields) *FieldLogger {
+	w, ok := wrapped.Load(logger)
+	if !ok {
+		copied := *logger
+		copied.ExtraCalldepth++
+		w, _ = wrapped.LoadOrStore(logger, &copied)
+	}
+	return &FieldLogger{logger: w.(*logging.Logger), fields: fields}
+}
+
+// WithChannel returns a FieldLogger attaching the channel to the records of
+// logger
+func WithChannel(logger *logging.Logger, chainID string) *FieldLogger {
+	return WithFields(logger, Fields{ChannelField: chainID})
+}
+
+// With returns a FieldLogger attaching fields in addition to those of l
+func (l *FieldLogger) With(fields Fields) *FieldLogger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &FieldLogger{logger: l.logger, fields: merged}
+}
+
+// IsEnabledFor returns true if records at level are logged
+func (l *FieldLogger) IsEnabledFor(level logging.Level) bool {
+	return l.logger.IsEnabledFor(level)
+}
+
+func (l *FieldLogger) args(args []interface{}) []interface{} {
+	return append([]interface{}{l.fields}, args...)
+}
+
+// Debugf logs a message at DEBUG level
+func (l *FieldLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debugf("%s"+format, l.args(args)...)
+}
+
+// Infof logs a message at INFO level
+func (l *FieldLogger) Infof(format string, args ...interface{}) {
+	l.logger.Infof("%s"+format, l.args(args)...)
+}
+
+// Warningf logs a message at WARNING level
+func (l *FieldLogger) Warningf(format string, args ...interface{}) {
+	l.logger.Warningf("%s"+format, l.args(args)...)
+}
+
+// Errorf logs a message at ERROR level
+func (l *FieldLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Errorf("%s"+format, l.args(args)...)
+}
+
+// Criticalf logs a message at CRITICAL level
+func (l *FieldLogger) Criticalf(format string, args ...interface{}) {
+	l.logger.Criticalf("%s"+format, l.args(args)...)
+}
+
+// Panicf logs a message at CRITICAL level and panics
+func (l *FieldLogger) Panicf(format string, args ...interface{}) {
+	l.logger.Panicf("%s"+format, l.args(args)...)
+}
+
+// Fatalf logs a message at CRITICAL level and exits
+func (l *FieldLogger) Fatalf(format string, args ...interface{}) {
+	l.logger.Fatalf("%s"+format, l.args(args)...)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package flogging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldLoggerText(t *testing.T) {
+	defer flogging.Reset()
+	buf := &bytes.Buffer{}
+	flogging.InitBackend(flogging.SetFormat("[%{module}] %{shortfunc} -> %{level:.4s} %{message}"), buf)
+
+	logger := flogging.WithChannel(logging.MustGetLogger("fieldsTest"), "foo")
+	logger.Infof("Hello %s", "world")
+	logger.With(flogging.Fields{flogging.TxHashField: "abcd"}).Warningf("Rejected")
+
+	assert.Equal(t, "[fieldsTest] TestFieldLoggerText -> INFO [channel: foo] Hello world\n"+
+		"[fieldsTest] TestFieldLoggerText -> WARN [channel: foo, tx_hash: abcd] Rejected\n", buf.String())
+}
+
+func TestFieldLoggerJSON(t *testing.T) {
+	defer flogging.Reset()
+	buf := &bytes.Buffer{}
+	flogging.InitBackend(flogging.SetFormat(flogging.JSONFormat), buf)
+
+	flogging.WithFields(logging.MustGetLogger("fieldsTest"), flogging.Fields{
+		flogging.ChannelField: "foo",
+		flogging.ClientField:  "alice",
+	}).Infof("Hello %s", "world")
+	logging.MustGetLogger("fieldsTest").Warningf("No fields")
+
+	decoder := json.NewDecoder(buf)
+	var entry map[string]string
+	assert.NoError(t, decoder.Decode(&entry))
+	assert.NotEmpty(t, entry["ts"])
+	assert.Equal(t, "fields_test.go:40", entry["caller"])
+	delete(entry, "ts")
+	delete(entry, "caller")
+	assert.Equal(t, map[string]string{
+		"level":     "INFO",
+		"subsystem": "fieldsTest",
+		"msg":       "Hello world",
+		"channel":   "foo",
+		"client":    "alice",
+	}, entry)
+
+	entry = nil
+	assert.NoError(t, decoder.Decode(&entry))
+	assert.Equal(t, "No fields", entry["msg"])
+	assert.Equal(t, "WARNING", entry["level"])
+}
@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package flogging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+// JSONFormat is the format spec selecting the JSONFormatter
+const JSONFormat = "json"
+
+// JSONFormatter writes each record as a single line JSON object with the
+// keys ts, level, subsystem, caller and msg, and a key for each of the Fields
+// attached by a FieldLogger
+type JSONFormatter struct{}
+
+// Format writes the record as JSON
+func (JSONFormatter) Format(calldepth int, r *logging.Record, w io.Writer) error {
+	msg := r.Message()
+	entry := make(map[string]string)
+	if len(r.Args) > 0 {
+		if fields, ok := r.Args[0].(Fields); ok {
+			for key, value := range fields {
+				entry[key] = value
+			}
+			msg = strings.TrimPrefix(msg, fields.String())
+		}
+	}
+	entry["ts"] = r.Time.UTC().Format(time.RFC3339Nano)
+	entry["level"] = r.Level.String()
+	entry["subsystem"] = r.Module
+	entry["msg"] = msg
+	if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+		entry["caller"] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encoded)
+	return err
+}
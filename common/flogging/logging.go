@@ -60,8 +60,12 @@ func Reset() {
 	InitFromSpec("")
 }
 
-// SetFormat sets the logging format.
+// SetFormat sets the logging format. The spec "json" selects the
+// JSONFormatter.
 func SetFormat(formatSpec string) logging.Formatter {
+	if formatSpec == JSONFormat {
+		return JSONFormatter{}
+	}
 	if formatSpec == "" {
 		formatSpec = defaultFormat
 	}
@@ -0,0 +1,239 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// Problem is an inconsistency found by Verify in or after block BlockNum
+type Problem struct {
+	BlockNum    uint64
+	Description string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("block %d: %s", p.BlockNum, p.Description)
+}
+
+// VerifyResult is the outcome of verifying a block store
+type VerifyResult struct {
+	// Height is the number of consecutive, intact blocks found in the block
+	// files from block 0
+	Height   uint64
+	Problems []Problem
+}
+
+// Verify checks, without modifying it, the block store of ledgerid: that the
+// block files hold consecutive blocks each linked to the previous by its
+// hash and holding the data its header commits to, that the block number
+// and block hash indexes configured by indexConfig locate each block, and
+// that the checkpoint recorded in the index database matches the end of the
+// block files. It must not be run while the block store is open.
+func Verify(conf *Conf, indexConfig *blkstorage.IndexConfig, ledgerid string) (result *VerifyResult, err error) {
+	rootDir := conf.getLedgerBlockDir(ledgerid)
+	exists, _, err := util.FileExists(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("no block store for ledger %s in %s", ledgerid, conf.blockStorageDir)
+	}
+
+	// The leveldb helpers and block file streams panic on errors which are
+	// to be reported here
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not verify ledger %s: %v", ledgerid, r)
+		}
+	}()
+
+	indexProvider := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: conf.getIndexDir()})
+	defer indexProvider.Close()
+	v := &verifier{
+		rootDir: rootDir,
+		db:      indexProvider.GetDBHandle(ledgerid),
+		indexed: make(map[blkstorage.IndexableAttr]bool),
+		result:  &VerifyResult{},
+	}
+	for _, attr := range indexConfig.AttrsToIndex {
+		v.indexed[attr] = true
+	}
+
+	lastFileNum, err := retrieveLastFileSuffix(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	for fileNum := 0; fileNum <= lastFileNum; fileNum++ {
+		if !v.verifyFile(fileNum) {
+			break
+		}
+	}
+	v.verifyCheckpoints()
+	return v.result, nil
+}
+
+type verifier struct {
+	rootDir string
+	db      *leveldbhelper.DBHandle
+	indexed map[blkstorage.IndexableAttr]bool
+	result  *VerifyResult
+
+	previous *common.BlockHeader
+	// fileNum and endOffset locate the end of the last intact block
+	fileNum   int
+	endOffset int64
+}
+
+func (v *verifier) problem(blockNum uint64, format string, args ...interface{}) {
+	v.result.Problems = append(v.result.Problems, Problem{BlockNum: blockNum, Description: fmt.Sprintf(format, args...)})
+}
+
+// verifyFile checks the blocks of a block file, returning false if no
+// further blocks could be read
+func (v *verifier) verifyFile(fileNum int) bool {
+	stream, err := newBlockfileStream(v.rootDir, fileNum, 0)
+	if err != nil {
+		v.problem(v.result.Height, "could not open block file %d: %s", fileNum, err)
+		return false
+	}
+	defer stream.close()
+
+	v.fileNum, v.endOffset = fileNum, 0
+	for {
+		blockBytes, placement, err := stream.nextBlockBytesAndPlacementInfo()
+		if err == ErrUnexpectedEndOfBlockfile {
+			v.problem(v.result.Height, "block file %d ends with a partially written block at offset %d", fileNum, stream.currentOffset)
+			return false
+		}
+		if err != nil {
+			v.problem(v.result.Height, "could not read block file %d at offset %d: %s", fileNum, stream.currentOffset, err)
+			return false
+		}
+		if blockBytes == nil {
+			return true
+		}
+		if !v.verifyBlock(blockBytes, placement) {
+			return false
+		}
+		v.fileNum, v.endOffset = fileNum, stream.currentOffset
+	}
+}
+
+// verifyBlock checks a block is the successor of the previous one and is
+// indexed at its placement, returning false if it could not be decoded or
+// is out of sequence
+func (v *verifier) verifyBlock(blockBytes []byte, placement *blockPlacementInfo) bool {
+	expected := v.result.Height
+	block, err := deserializeBlock(blockBytes)
+	if err != nil {
+		v.problem(expected, "could not decode block in file %d at offset %d: %s", placement.fileNum, placement.blockStartOffset, err)
+		return false
+	}
+	if block.Header.Number != expected {
+		v.problem(expected, "found block %d in file %d at offset %d", block.Header.Number, placement.fileNum, placement.blockStartOffset)
+		return false
+	}
+	if v.previous != nil && !bytes.Equal(block.Header.PreviousHash, v.previous.Hash()) {
+		v.problem(expected, "previous hash %x does not match the hash %x of block %d", block.Header.PreviousHash, v.previous.Hash(), v.previous.Number)
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		v.problem(expected, "data hash %x does not match the hash %x of its data", block.Header.DataHash, block.Data.Hash())
+	}
+
+	if v.indexed[blkstorage.IndexableAttrBlockNum] {
+		v.verifyLocation(expected, "block number", constructBlockNumKey(expected), placement)
+	}
+	if v.indexed[blkstorage.IndexableAttrBlockHash] {
+		v.verifyLocation(expected, "block hash", constructBlockHashKey(block.Header.Hash()), placement)
+	}
+
+	v.previous = block.Header
+	v.result.Height++
+	return true
+}
+
+// verifyLocation checks the index entry at key locates the block
+func (v *verifier) verifyLocation(blockNum uint64, index string, key []byte, placement *blockPlacementInfo) {
+	value, err := v.db.Get(key)
+	if err != nil {
+		v.problem(blockNum, "could not read %s index: %s", index, err)
+		return
+	}
+	if value == nil {
+		v.problem(blockNum, "missing from %s index", index)
+		return
+	}
+	flp := &fileLocPointer{}
+	if err := flp.unmarshal(value); err != nil {
+		v.problem(blockNum, "could not decode %s index entry: %s", index, err)
+		return
+	}
+	if flp.fileSuffixNum != placement.fileNum || int64(flp.offset) != placement.blockStartOffset {
+		v.problem(blockNum, "%s index locates the block in file %d at offset %d, it is in file %d at offset %d",
+			index, flp.fileSuffixNum, flp.offset, placement.fileNum, placement.blockStartOffset)
+	}
+}
+
+// verifyCheckpoints checks the checkpoints of the block files and the index
+// match the last intact block
+func (v *verifier) verifyCheckpoints() {
+	height := v.result.Height
+
+	cpBytes, err := v.db.Get(blkMgrInfoKey)
+	switch {
+	case err != nil:
+		v.problem(height, "could not read block files checkpoint: %s", err)
+	case cpBytes == nil:
+		if height > 0 {
+			v.problem(height, "block files checkpoint is missing")
+		}
+	default:
+		cpInfo := &checkpointInfo{}
+		if err := cpInfo.unmarshal(cpBytes); err != nil {
+			v.problem(height, "could not decode block files checkpoint: %s", err)
+			break
+		}
+		if height == 0 {
+			if !cpInfo.isChainEmpty {
+				v.problem(0, "block files checkpoint records block %d but no block was found", cpInfo.lastBlockNumber)
+			}
+			break
+		}
+		if cpInfo.isChainEmpty || cpInfo.lastBlockNumber != height-1 {
+			v.problem(height-1, "block files checkpoint records last block %d (empty=%t), the block files end with block %d",
+				cpInfo.lastBlockNumber, cpInfo.isChainEmpty, height-1)
+		}
+		if cpInfo.latestFileChunkSuffixNum != v.fileNum || int64(cpInfo.latestFileChunksize) != v.endOffset {
+			v.problem(height-1, "block files checkpoint records file %d size %d, the last block ends in file %d at offset %d",
+				cpInfo.latestFileChunkSuffixNum, cpInfo.latestFileChunksize, v.fileNum, v.endOffset)
+		}
+	}
+
+	if len(v.indexed) == 0 {
+		return
+	}
+	indexBytes, err := v.db.Get(indexCheckpointKey)
+	switch {
+	case err != nil:
+		v.problem(height, "could not read index checkpoint: %s", err)
+	case indexBytes == nil:
+		if height > 0 {
+			v.problem(0, "index checkpoint is missing, no block is indexed")
+		}
+	default:
+		if lastIndexed := decodeBlockNum(indexBytes); height == 0 || lastIndexed != height-1 {
+			v.problem(lastIndexed, "index checkpoint records last indexed block %d, the block files hold %d blocks", lastIndexed, height)
+		}
+	}
+}
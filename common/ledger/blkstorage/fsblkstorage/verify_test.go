@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var verifyIndexConfig = &blkstorage.IndexConfig{AttrsToIndex: []blkstorage.IndexableAttr{
+	blkstorage.IndexableAttrBlockNum,
+	blkstorage.IndexableAttrBlockHash,
+}}
+
+func newVerifyTestEnv(t *testing.T) *testEnv {
+	return newTestEnvSelectiveIndexing(t, NewConf(testPath(), 0), verifyIndexConfig.AttrsToIndex)
+}
+
+func TestVerify(t *testing.T) {
+	env := newVerifyTestEnv(t)
+	defer env.Cleanup()
+	w := newTestBlockfileWrapper(env, "testLedger")
+	w.addBlocks(testutil.ConstructTestBlocks(t, 10))
+	w.close()
+	env.provider.Close()
+
+	result, err := Verify(env.provider.conf, verifyIndexConfig, "testLedger")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), result.Height)
+	assert.Empty(t, result.Problems)
+
+	_, err = Verify(env.provider.conf, verifyIndexConfig, "missing")
+	assert.Error(t, err)
+}
+
+func TestVerifyEmpty(t *testing.T) {
+	env := newVerifyTestEnv(t)
+	defer env.Cleanup()
+	newTestBlockfileWrapper(env, "testLedger").close()
+	env.provider.Close()
+
+	result, err := Verify(env.provider.conf, verifyIndexConfig, "testLedger")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), result.Height)
+	assert.Empty(t, result.Problems)
+}
+
+func TestVerifyHashChain(t *testing.T) {
+	env := newVerifyTestEnv(t)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	blocks[5].Header.DataHash = []byte("wrong")
+	blocks[7].Header.PreviousHash = []byte("wrong")
+	w := newTestBlockfileWrapper(env, "testLedger")
+	w.addBlocks(blocks)
+	w.close()
+	env.provider.Close()
+
+	result, err := Verify(env.provider.conf, verifyIndexConfig, "testLedger")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), result.Height)
+	// Changing the header of a block also breaks the link from its successor
+	var blockNums []uint64
+	for _, problem := range result.Problems {
+		blockNums = append(blockNums, problem.BlockNum)
+	}
+	assert.Equal(t, []uint64{5, 6, 7, 8}, blockNums)
+	assert.Contains(t, result.Problems[0].Description, "data hash")
+	assert.Contains(t, result.Problems[2].Description, "previous hash")
+}
+
+func TestVerifyIndex(t *testing.T) {
+	env := newVerifyTestEnv(t)
+	defer env.Cleanup()
+	w := newTestBlockfileWrapper(env, "testLedger")
+	w.addBlocks(testutil.ConstructTestBlocks(t, 10))
+	w.close()
+	db := env.provider.leveldbProvider.GetDBHandle("testLedger")
+	require.NoError(t, db.Delete(constructBlockNumKey(3), true))
+	require.NoError(t, db.Put(indexCheckpointKey, encodeBlockNum(8), true))
+	env.provider.Close()
+
+	result, err := Verify(env.provider.conf, verifyIndexConfig, "testLedger")
+	require.NoError(t, err)
+	require.Len(t, result.Problems, 2)
+	assert.Equal(t, Problem{BlockNum: 3, Description: "missing from block number index"}, result.Problems[0])
+	assert.Equal(t, uint64(8), result.Problems[1].BlockNum)
+	assert.Contains(t, result.Problems[1].Description, "index checkpoint")
+}
+
+func TestVerifyPartialBlock(t *testing.T) {
+	env := newVerifyTestEnv(t)
+	defer env.Cleanup()
+	w := newTestBlockfileWrapper(env, "testLedger")
+	w.addBlocks(testutil.ConstructTestBlocks(t, 3))
+	w.close()
+	env.provider.Close()
+
+	f, err := os.OpenFile(deriveBlockfilePath(env.provider.conf.getLedgerBlockDir("testLedger"), 0), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 1, 2})
+	require.NoError(t, err)
+	f.Close()
+
+	result, err := Verify(env.provider.conf, verifyIndexConfig, "testLedger")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), result.Height)
+	require.Len(t, result.Problems, 1)
+	assert.Equal(t, uint64(3), result.Problems[0].BlockNum)
+	assert.Contains(t, result.Problems[0].Description, "partially written")
+}
@@ -35,6 +35,14 @@ type Orderer struct {
 	KafkaBrokersVal []string
 	// MaxChannelsCountVal is returns as the result of MaxChannelsCount()
 	MaxChannelsCountVal uint64
+	// BroadcastQueueDepthVal is returned as the result of BroadcastQueueDepth()
+	BroadcastQueueDepthVal uint32
+	// BroadcastQuotaVal is returned as the result of BroadcastQuota()
+	BroadcastQuotaVal *ab.BroadcastQuota
+	// ReplayWindowSizeVal is returned as the result of ReplayWindowSize()
+	ReplayWindowSizeVal uint32
+	// ReplayTTLVal is returned as the result of ReplayTTL()
+	ReplayTTLVal time.Duration
 	// OrganizationsVal is returned as the result of Organizations()
 	OrganizationsVal map[string]config.Org
 }
@@ -64,6 +72,26 @@ func (scm *Orderer) MaxChannelsCount() uint64 {
 	return scm.MaxChannelsCountVal
 }
 
+// BroadcastQueueDepth returns the BroadcastQueueDepthVal
+func (scm *Orderer) BroadcastQueueDepth() uint32 {
+	return scm.BroadcastQueueDepthVal
+}
+
+// BroadcastQuota returns the BroadcastQuotaVal
+func (scm *Orderer) BroadcastQuota() *ab.BroadcastQuota {
+	return scm.BroadcastQuotaVal
+}
+
+// ReplayWindowSize returns the ReplayWindowSizeVal
+func (scm *Orderer) ReplayWindowSize() uint32 {
+	return scm.ReplayWindowSizeVal
+}
+
+// ReplayTTL returns the ReplayTTLVal
+func (scm *Orderer) ReplayTTL() time.Duration {
+	return scm.ReplayTTLVal
+}
+
 // Organizations returns OrganizationsVal
 func (scm *Orderer) Organizations() map[string]config.Org {
 	return scm.OrganizationsVal
@@ -58,7 +58,7 @@ func (r *Resources) ChannelConfig() config.Channel {
 
 // Returns the OrdererConfigVal
 func (r *Resources) OrdererConfig() (config.Orderer, bool) {
-	return r.OrdererConfigVal, r.OrdererConfigVal == nil
+	return r.OrdererConfigVal, r.OrdererConfigVal != nil
 }
 
 // Returns the ApplicationConfigVal
@@ -32,6 +32,11 @@ func (p *Policy) Evaluate(signatureSet []*cb.SignedData) error {
 	return p.Err
 }
 
+// EvaluateIdentities returns the Err set in Policy
+func (p *Policy) EvaluateIdentities(identities [][]byte) error {
+	return p.Err
+}
+
 // Manager is a mock implementation of the policies.Manager interface
 type Manager struct {
 	// Policy is returned as the output to GetPolicy if a Policy
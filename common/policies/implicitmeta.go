@@ -81,3 +81,25 @@ func (imp *implicitMetaPolicy) Evaluate(signatureSet []*cb.SignedData) error {
 	}
 	return fmt.Errorf("Failed to reach implicit threshold of %d sub-policies, required %d remaining", imp.threshold, remaining)
 }
+
+// EvaluateIdentities takes a set of serialized identities and evaluates whether they satisfy the policy,
+// a sub-policy which cannot evaluate identities is not satisfied
+func (imp *implicitMetaPolicy) EvaluateIdentities(identities [][]byte) error {
+	remaining := imp.threshold
+	for _, policy := range imp.subPolicies {
+		evaluator, ok := policy.(IdentityEvaluator)
+		if !ok {
+			continue
+		}
+		if evaluator.EvaluateIdentities(identities) == nil {
+			remaining--
+			if remaining == 0 {
+				return nil
+			}
+		}
+	}
+	if remaining == 0 {
+		return nil
+	}
+	return fmt.Errorf("Failed to reach implicit threshold of %d sub-policies, required %d remaining", imp.threshold, remaining)
+}
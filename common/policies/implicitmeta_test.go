@@ -34,6 +34,10 @@ func (rp acceptPolicy) Evaluate(signedData []*cb.SignedData) error {
 	return nil
 }
 
+func (rp acceptPolicy) EvaluateIdentities(identities [][]byte) error {
+	return nil
+}
+
 func TestImplicitMarshalError(t *testing.T) {
 	_, err := newImplicitMetaPolicy([]byte("GARBAGE"))
 	assert.Error(t, err, "Should have errored unmarshaling garbage")
@@ -99,3 +103,17 @@ func TestImplicitMetaMajority(t *testing.T) {
 	assert.Error(t, runPolicyTest(cb.ImplicitMetaPolicy_MAJORITY, 10, 0))
 	assert.NoError(t, runPolicyTest(cb.ImplicitMetaPolicy_MAJORITY, 0, 0))
 }
+
+func TestImplicitMetaIdentities(t *testing.T) {
+	imp, err := newImplicitMetaPolicy(utils.MarshalOrPanic(&cb.ImplicitMetaPolicy{
+		Rule:      cb.ImplicitMetaPolicy_MAJORITY,
+		SubPolicy: TestPolicyName,
+	}))
+	assert.NoError(t, err)
+
+	imp.initialize(&policyConfig{managers: makeManagers(3, 2)})
+	assert.NoError(t, imp.EvaluateIdentities(nil))
+
+	imp.initialize(&policyConfig{managers: makeManagers(3, 1)})
+	assert.Error(t, imp.EvaluateIdentities(nil))
+}
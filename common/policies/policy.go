@@ -68,6 +68,15 @@ type Policy interface {
 	Evaluate(signatureSet []*cb.SignedData) error
 }
 
+// IdentityEvaluator is implemented by the policies which can tell whether a
+// set of identities satisfies them, for a request which was authenticated by
+// other means than a signature, such as a TLS client certificate
+type IdentityEvaluator interface {
+	// EvaluateIdentities takes a set of serialized identities and evaluates
+	// whether they satisfy the policy, as if each of them signed the request
+	EvaluateIdentities(identities [][]byte) error
+}
+
 // Manager is a read only subset of the policy ManagerImpl
 type Manager interface {
 	// GetPolicy returns a policy and true if it was the policy requested, or false if it is the default policy
@@ -163,6 +172,10 @@ func (rp rejectPolicy) Evaluate(signedData []*cb.SignedData) error {
 	return fmt.Errorf("No such policy type: %s", rp)
 }
 
+func (rp rejectPolicy) EvaluateIdentities(identities [][]byte) error {
+	return fmt.Errorf("No such policy type: %s", rp)
+}
+
 // Basepath returns the basePath the manager was instnatiated with
 func (pm *ManagerImpl) BasePath() string {
 	return pm.basePath
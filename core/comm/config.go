@@ -22,13 +22,10 @@ var (
 	// Max send and receive bytes for grpc clients and servers
 	maxRecvMsgSize = 100 * 1024 * 1024
 	maxSendMsgSize = 100 * 1024 * 1024
+	// Max concurrent streams per connection for grpc servers, 0 is unlimited
+	maxConcurrentStreams uint32
 	// Default keepalive options
-	keepaliveOptions = KeepaliveOptions{
-		ClientKeepaliveTime:    60,   // 1 min
-		ClientKeepaliveTimeout: 20,   // 20 sec - gRPC default
-		ServerKeepaliveTime:    7200, // 2 hours - gRPC default
-		ServerKeepaliveTimeout: 20,   // 20 sec - gRPC default
-	}
+	keepaliveOptions = DefaultKeepaliveOptions()
 )
 
 // KeepAliveOptions is used to set the gRPC keepalive settings for both
@@ -48,6 +45,29 @@ type KeepaliveOptions struct {
 	// ServerKeepaliveTimeout is the duration the server waits for a response
 	// from the client after sending a ping before closing the connection
 	ServerKeepaliveTimeout int
+	// ServerMaxConnectionIdle is the duration in seconds after which the
+	// server closes a connection on which no RPC is active, where 0 means
+	// never
+	ServerMaxConnectionIdle int
+	// ServerMaxConnectionAge is the duration in seconds, give or take 10%,
+	// after which the server asks clients to move to a new connection, where
+	// 0 means never
+	ServerMaxConnectionAge int
+	// ServerMaxConnectionAgeGrace is the duration in seconds the server lets
+	// the RPCs of a connection past its maximum age complete before closing
+	// it, where 0 means they may run to completion
+	ServerMaxConnectionAgeGrace int
+}
+
+// DefaultKeepaliveOptions returns the default gRPC keepalive settings for
+// both clients and servers
+func DefaultKeepaliveOptions() KeepaliveOptions {
+	return KeepaliveOptions{
+		ClientKeepaliveTime:    60,   // 1 min
+		ClientKeepaliveTimeout: 20,   // 20 sec - gRPC default
+		ServerKeepaliveTime:    7200, // 2 hours - gRPC default
+		ServerKeepaliveTimeout: 20,   // 20 sec - gRPC default
+	}
 }
 
 // cacheConfiguration caches common package scoped variables
@@ -90,6 +110,18 @@ func SetMaxSendMsgSize(size int) {
 	maxSendMsgSize = size
 }
 
+// MaxConcurrentStreams returns the maximum number of concurrent streams
+// gRPC servers allow on each client connection, where 0 means no limit
+func MaxConcurrentStreams() uint32 {
+	return maxConcurrentStreams
+}
+
+// SetMaxConcurrentStreams sets the maximum number of concurrent streams
+// gRPC servers allow on each client connection, where 0 means no limit
+func SetMaxConcurrentStreams(streams uint32) {
+	maxConcurrentStreams = streams
+}
+
 // SetKeepaliveOptions sets the gRPC keepalive options for both clients and
 // servers
 func SetKeepaliveOptions(ka KeepaliveOptions) {
@@ -102,6 +134,10 @@ func ServerKeepaliveOptions() []grpc.ServerOption {
 	kap := keepalive.ServerParameters{
 		Time:    time.Duration(keepaliveOptions.ServerKeepaliveTime) * time.Second,
 		Timeout: time.Duration(keepaliveOptions.ServerKeepaliveTimeout) * time.Second,
+		// gRPC treats zero durations as infinite
+		MaxConnectionIdle:     time.Duration(keepaliveOptions.ServerMaxConnectionIdle) * time.Second,
+		MaxConnectionAge:      time.Duration(keepaliveOptions.ServerMaxConnectionAge) * time.Second,
+		MaxConnectionAgeGrace: time.Duration(keepaliveOptions.ServerMaxConnectionAgeGrace) * time.Second,
 	}
 	serverOpts = append(serverOpts, grpc.KeepaliveParams(kap))
 	kep := keepalive.EnforcementPolicy{
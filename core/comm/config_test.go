@@ -27,6 +27,12 @@ func TestConfig(t *testing.T) {
 	assert.EqualValues(t, size, MaxRecvMsgSize())
 	assert.EqualValues(t, size, MaxSendMsgSize())
 
+	// set max concurrent streams
+	assert.EqualValues(t, 0, MaxConcurrentStreams())
+	SetMaxConcurrentStreams(50)
+	assert.EqualValues(t, 50, MaxConcurrentStreams())
+	SetMaxConcurrentStreams(0)
+
 	// set keepalive options
 	timeout := 1000
 	ka := KeepaliveOptions{
@@ -34,12 +40,14 @@ func TestConfig(t *testing.T) {
 		ClientKeepaliveTimeout: timeout + 1,
 		ServerKeepaliveTime:    timeout + 2,
 		ServerKeepaliveTimeout: timeout + 3,
+		ServerMaxConnectionAge: timeout + 4,
 	}
 	SetKeepaliveOptions(ka)
 	assert.EqualValues(t, timeout, keepaliveOptions.ClientKeepaliveTime)
 	assert.EqualValues(t, timeout+1, keepaliveOptions.ClientKeepaliveTimeout)
 	assert.EqualValues(t, timeout+2, keepaliveOptions.ServerKeepaliveTime)
 	assert.EqualValues(t, timeout+3, keepaliveOptions.ServerKeepaliveTimeout)
+	assert.EqualValues(t, timeout+4, keepaliveOptions.ServerMaxConnectionAge)
 	assert.EqualValues(t, 2, len(ServerKeepaliveOptions()))
 	assert.Equal(t, 1, len(ClientKeepaliveOptions()))
 
@@ -78,3 +78,25 @@ func (sc *serverCreds) Clone() credentials.TransportCredentials {
 func (sc *serverCreds) OverrideServerName(string) error {
 	return OverrrideHostnameNotSupportedError
 }
+
+// handshakeObserver reports failed server handshakes of the wrapped
+// credentials.TransportCredentials
+type handshakeObserver struct {
+	credentials.TransportCredentials
+	onError func(remote net.Addr, err error)
+}
+
+// ServerHandshake does the authentication handshake for servers, reporting
+// it if it fails
+func (ho *handshakeObserver) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, authInfo, err := ho.TransportCredentials.ServerHandshake(rawConn)
+	if err != nil {
+		ho.onError(rawConn.RemoteAddr(), err)
+	}
+	return conn, authInfo, err
+}
+
+// Clone makes a copy of this TransportCredentials.
+func (ho *handshakeObserver) Clone() credentials.TransportCredentials {
+	return &handshakeObserver{TransportCredentials: ho.TransportCredentials.Clone(), onError: ho.onError}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestChainUnaryServerInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+"-before")
+			resp, err := handler(ctx, req)
+			calls = append(calls, name+"-after")
+			return resp, err
+		}
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, errors.New("handler error")
+	}
+
+	chain := comm.ChainUnaryServerInterceptors(interceptor("first"), interceptor("second"))
+	resp, err := chain(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, handler)
+	assert.Equal(t, "request", resp)
+	assert.EqualError(t, err, "handler error")
+	assert.Equal(t, []string{"first-before", "second-before", "handler", "second-after", "first-after"}, calls)
+
+	t.Run("Empty", func(t *testing.T) {
+		calls = nil
+		resp, err := comm.ChainUnaryServerInterceptors()(context.Background(), "request", &grpc.UnaryServerInfo{}, handler)
+		assert.Equal(t, "request", resp)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"handler"}, calls)
+	})
+}
+
+func TestChainStreamServerInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.StreamServerInterceptor {
+		return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, name+"-before")
+			err := handler(srv, ss)
+			calls = append(calls, name+"-after")
+			return err
+		}
+	}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	chain := comm.ChainStreamServerInterceptors(interceptor("first"), interceptor("second"), interceptor("third"))
+	err := chain(nil, nil, &grpc.StreamServerInfo{FullMethod: "/test/Stream"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first-before", "second-before", "third-before", "handler",
+		"third-after", "second-after", "first-after"}, calls)
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package comm
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
@@ -25,6 +27,9 @@ type SecureServerConfig struct {
 	ServerCertificate []byte
 	//PEM-encoded private key to be used by the server for TLS communication
 	ServerKey []byte
+	//ServerSigner, if set, is used in place of ServerKey so that the private
+	//key may be held by a hardware security module
+	ServerSigner crypto.Signer
 	//Set of PEM-encoded X509 certificate authorities to optionally send
 	//as part of the server handshake
 	ServerRootCAs [][]byte
@@ -35,6 +40,13 @@ type SecureServerConfig struct {
 	UseTLS bool
 	//Whether or not TLS client must present certificates for authentication
 	RequireClientCert bool
+	//Interceptors applied, in order, to unary RPCs handled by the server
+	UnaryInterceptors []grpc.UnaryServerInterceptor
+	//Interceptors applied, in order, to streaming RPCs handled by the server
+	StreamInterceptors []grpc.StreamServerInterceptor
+	//HandshakeErrorHandler, if set, is called with the remote address and
+	//error when a TLS handshake with a client fails
+	HandshakeErrorHandler func(remote net.Addr, err error)
 }
 
 //GRPCServer defines an interface representing a GRPC-based server
@@ -63,6 +75,15 @@ type GRPCServer interface {
 	//SetClientRootCAs sets the list of authorities used to verify client
 	//certificates based on a list of PEM-encoded X509 certificate authorities
 	SetClientRootCAs(clientRoots [][]byte) error
+	//SetServerCertificate replaces the certificate presented by the server
+	//for new TLS connections based on a PEM-encoded X509 public and private
+	//key pair. Existing connections are unaffected
+	SetServerCertificate(certPEM, keyPEM []byte) error
+	//SetServerCertificateSigner replaces the certificate presented by the
+	//server for new TLS connections based on a PEM-encoded X509 certificate
+	//chain and a signer holding the private key. Existing connections are
+	//unaffected
+	SetServerCertificateSigner(certPEM []byte, signer crypto.Signer) error
 }
 
 type grpcServerImpl struct {
@@ -122,11 +143,17 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 	var serverOpts []grpc.ServerOption
 	//check secureConfig
 	if secureConfig.UseTLS {
-		//both key and cert are required
-		if secureConfig.ServerKey != nil && secureConfig.ServerCertificate != nil {
+		//both key (or signer) and cert are required
+		if (secureConfig.ServerKey != nil || secureConfig.ServerSigner != nil) && secureConfig.ServerCertificate != nil {
 			grpcServer.tlsEnabled = true
 			//load server public and private keys
-			cert, err := tls.X509KeyPair(secureConfig.ServerCertificate, secureConfig.ServerKey)
+			var cert tls.Certificate
+			var err error
+			if secureConfig.ServerSigner != nil {
+				cert, err = signerKeyPair(secureConfig.ServerCertificate, secureConfig.ServerSigner)
+			} else {
+				cert, err = tls.X509KeyPair(secureConfig.ServerCertificate, secureConfig.ServerKey)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -134,10 +161,10 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 
 			//set up our TLS config
 
-			//base server certificate
-			certificates := []tls.Certificate{grpcServer.serverCertificate}
+			//base server certificate, looked up per handshake so that it
+			//may be replaced at runtime
 			grpcServer.tlsConfig = &tls.Config{
-				Certificates:           certificates,
+				GetCertificate:         grpcServer.getServerCertificate,
 				SessionTicketsDisabled: true,
 			}
 			grpcServer.tlsConfig.ClientAuth = tls.RequestClientCert
@@ -160,6 +187,12 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 
 			// create credentials and add to server options
 			creds := NewServerTransportCredentials(grpcServer.tlsConfig)
+			if secureConfig.HandshakeErrorHandler != nil {
+				creds = &handshakeObserver{
+					TransportCredentials: creds,
+					onError:              secureConfig.HandshakeErrorHandler,
+				}
+			}
 			serverOpts = append(serverOpts, grpc.Creds(creds))
 		} else {
 			return nil, errors.New("secureConfig must contain both ServerKey and " +
@@ -169,8 +202,21 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 	// set max send and recv msg sizes
 	serverOpts = append(serverOpts, grpc.MaxSendMsgSize(MaxSendMsgSize()))
 	serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(MaxRecvMsgSize()))
+	// limit the concurrent streams per connection, if configured
+	if MaxConcurrentStreams() > 0 {
+		serverOpts = append(serverOpts, grpc.MaxConcurrentStreams(MaxConcurrentStreams()))
+	}
 	// set the keepalive options
 	serverOpts = append(serverOpts, ServerKeepaliveOptions()...)
+	// chain the interceptors, as grpc supports only one of each kind
+	if len(secureConfig.UnaryInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.UnaryInterceptor(
+			ChainUnaryServerInterceptors(secureConfig.UnaryInterceptors...)))
+	}
+	if len(secureConfig.StreamInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.StreamInterceptor(
+			ChainStreamServerInterceptors(secureConfig.StreamInterceptors...)))
+	}
 
 	grpcServer.server = grpc.NewServer(serverOpts...)
 
@@ -194,9 +240,89 @@ func (gServer *grpcServerImpl) Server() *grpc.Server {
 
 //ServerCertificate returns the tls.Certificate used by the grpc.Server
 func (gServer *grpcServerImpl) ServerCertificate() tls.Certificate {
+	gServer.lock.Lock()
+	defer gServer.lock.Unlock()
 	return gServer.serverCertificate
 }
 
+//internal function used as the tls.Config GetCertificate callback
+func (gServer *grpcServerImpl) getServerCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := gServer.ServerCertificate()
+	return &cert, nil
+}
+
+//SetServerCertificate replaces the certificate presented by the server
+//for new TLS connections based on a PEM-encoded X509 public and private
+//key pair. Existing connections are unaffected
+func (gServer *grpcServerImpl) SetServerCertificate(certPEM, keyPEM []byte) error {
+	if !gServer.tlsEnabled {
+		return errors.New("Failed to set server certificate: TLS is not enabled")
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("Failed to set server certificate: %s", err)
+	}
+	gServer.lock.Lock()
+	defer gServer.lock.Unlock()
+	gServer.serverCertificate = cert
+	return nil
+}
+
+//SetServerCertificateSigner replaces the certificate presented by the server
+//for new TLS connections based on a PEM-encoded X509 certificate chain and a
+//signer holding the corresponding private key. Existing connections are
+//unaffected
+func (gServer *grpcServerImpl) SetServerCertificateSigner(certPEM []byte, signer crypto.Signer) error {
+	if !gServer.tlsEnabled {
+		return errors.New("Failed to set server certificate: TLS is not enabled")
+	}
+	cert, err := signerKeyPair(certPEM, signer)
+	if err != nil {
+		return fmt.Errorf("Failed to set server certificate: %s", err)
+	}
+	gServer.lock.Lock()
+	defer gServer.lock.Unlock()
+	gServer.serverCertificate = cert
+	return nil
+}
+
+//signerKeyPair creates a tls.Certificate from a PEM-encoded X509 certificate
+//chain and a signer for the public key of the leaf certificate
+func signerKeyPair(certPEM []byte, signer crypto.Signer) (tls.Certificate, error) {
+	var cert tls.Certificate
+	for {
+		var block *pem.Block
+		block, certPEM = pem.Decode(certPEM)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			cert.Certificate = append(cert.Certificate, block.Bytes)
+		}
+	}
+	if len(cert.Certificate) == 0 {
+		return cert, errors.New("failed to find any PEM data in certificate input")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return cert, err
+	}
+	leafKey, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+	if err != nil {
+		return cert, err
+	}
+	signerKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return cert, err
+	}
+	if !bytes.Equal(leafKey, signerKey) {
+		return cert, errors.New("private key does not match public key")
+	}
+	cert.PrivateKey = signer
+	cert.Leaf = leaf
+	return cert, nil
+}
+
 //TLSEnabled is a flag indicating whether or not TLS is enabled for the
 //GRPCServer instance
 func (gServer *grpcServerImpl) TLSEnabled() bool {
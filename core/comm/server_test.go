@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package comm_test
 
 import (
+	"crypto"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -1421,3 +1422,141 @@ func TestKeepaliveClientResponse(t *testing.T) {
 	_, err = clientTransport.NewStream(context.Background(), &transport.CallHdr{})
 	assert.NoError(t, err, "Unexpected error creating stream")
 }
+
+func TestSetServerCertificate(t *testing.T) {
+
+	t.Parallel()
+
+	//start with the embedded self-signed certificate
+	srv, err := comm.NewGRPCServer("localhost:0", comm.SecureServerConfig{
+		UseTLS:            true,
+		ServerCertificate: []byte(selfSignedCertPEM),
+		ServerKey:         []byte(selfSignedKeyPEM),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create GRPCServer due to: %s", err.Error())
+	}
+	testpb.RegisterTestServiceServer(srv.Server(), &testServiceServer{})
+	go srv.Start()
+	defer srv.Stop()
+
+	//returns the leaf certificate presented by the server
+	serverCert := func() []byte {
+		conn, err := tls.Dial("tcp", srv.Address(), &tls.Config{
+			InsecureSkipVerify: true,
+			NextProtos:         []string{"h2"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to dial %s due to: %s", srv.Address(), err.Error())
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Raw
+	}
+
+	original := srv.ServerCertificate()
+	assert.Equal(t, original.Certificate[0], serverCert())
+
+	//replace it with an Org1 server certificate
+	newConfig := testOrgs[0].testServers(0, [][]byte{})[0].config
+	err = srv.SetServerCertificate(newConfig.ServerCertificate, newConfig.ServerKey)
+	assert.NoError(t, err)
+	rotated := srv.ServerCertificate()
+	assert.NotEqual(t, original.Certificate[0], rotated.Certificate[0])
+	assert.Equal(t, rotated.Certificate[0], serverCert())
+
+	//a bad key pair leaves the current certificate in place
+	err = srv.SetServerCertificate([]byte(badPEM), newConfig.ServerKey)
+	assert.Error(t, err)
+	assert.Equal(t, rotated.Certificate[0], serverCert())
+
+	//not supported without TLS
+	insecureSrv, err := comm.NewGRPCServer("localhost:0", comm.SecureServerConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create GRPCServer due to: %s", err.Error())
+	}
+	defer insecureSrv.Listener().Close()
+	err = insecureSrv.SetServerCertificate(newConfig.ServerCertificate, newConfig.ServerKey)
+	assert.EqualError(t, err, "Failed to set server certificate: TLS is not enabled")
+}
+
+func TestServerSigner(t *testing.T) {
+
+	t.Parallel()
+
+	//signers taken from key pairs stand in for keys held by an HSM
+	signer := func(certPEM, keyPEM []byte) crypto.Signer {
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatalf("Failed to load key pair due to: %s", err.Error())
+		}
+		return pair.PrivateKey.(crypto.Signer)
+	}
+	selfSigned := signer([]byte(selfSignedCertPEM), []byte(selfSignedKeyPEM))
+
+	srv, err := comm.NewGRPCServer("localhost:0", comm.SecureServerConfig{
+		UseTLS:            true,
+		ServerCertificate: []byte(selfSignedCertPEM),
+		ServerSigner:      selfSigned,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create GRPCServer due to: %s", err.Error())
+	}
+	testpb.RegisterTestServiceServer(srv.Server(), &testServiceServer{})
+	go srv.Start()
+	defer srv.Stop()
+
+	_, err = invokeEmptyCall(srv.Address(), []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true}))})
+	assert.NoError(t, err, "The handshake should succeed with the signer")
+	assert.True(t, srv.TLSEnabled())
+
+	original := srv.ServerCertificate()
+	newConfig := testOrgs[0].testServers(0, [][]byte{})[0].config
+	err = srv.SetServerCertificateSigner(newConfig.ServerCertificate, selfSigned)
+	assert.EqualError(t, err, "Failed to set server certificate: private key does not match public key")
+	err = srv.SetServerCertificateSigner(newConfig.ServerCertificate, signer(newConfig.ServerCertificate, newConfig.ServerKey))
+	assert.NoError(t, err)
+	assert.NotEqual(t, original.Certificate[0], srv.ServerCertificate().Certificate[0])
+
+	_, err = comm.NewGRPCServer("localhost:0", comm.SecureServerConfig{
+		UseTLS:            true,
+		ServerCertificate: []byte(badPEM),
+		ServerSigner:      selfSigned,
+	})
+	assert.Error(t, err)
+}
+
+func TestHandshakeErrorHandler(t *testing.T) {
+
+	t.Parallel()
+
+	failures := make(chan net.Addr, 1)
+	srv, err := comm.NewGRPCServer("localhost:0", comm.SecureServerConfig{
+		UseTLS:            true,
+		ServerCertificate: []byte(selfSignedCertPEM),
+		ServerKey:         []byte(selfSignedKeyPEM),
+		HandshakeErrorHandler: func(remote net.Addr, err error) {
+			failures <- remote
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create GRPCServer due to: %s", err.Error())
+	}
+	go srv.Start()
+	defer srv.Stop()
+
+	//a client which does not speak TLS fails the handshake
+	conn, err := net.Dial("tcp", srv.Address())
+	if err != nil {
+		t.Fatalf("Failed to dial %s due to: %s", srv.Address(), err.Error())
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+
+	select {
+	case remote := <-failures:
+		assert.Equal(t, conn.LocalAddr().String(), remote.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handshake error handler was not called")
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/x509"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ExtractCertificateFromContext returns the verified TLS certificate
+// presented by the remote peer of the gRPC stream or call associated with
+// the given context, or nil if the connection is not TLS or the client did
+// not present a certificate
+func ExtractCertificateFromContext(ctx context.Context) *x509.Certificate {
+	pr, extracted := peer.FromContext(ctx)
+	if !extracted {
+		return nil
+	}
+
+	tlsInfo, isTLSConn := pr.AuthInfo.(credentials.TLSInfo)
+	if !isTLSConn {
+		return nil
+	}
+
+	certs := tlsInfo.State.PeerCertificates
+	if len(certs) == 0 {
+		return nil
+	}
+	return certs[0]
+}
+
+// ClientIdentity returns a description of the remote peer of the gRPC stream
+// or call associated with the given context, for logging: the common name of
+// its TLS certificate if it presented one, its address otherwise
+func ClientIdentity(ctx context.Context) string {
+	if cert := ExtractCertificateFromContext(ctx); cert != nil {
+		return cert.Subject.CommonName
+	}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		return pr.Addr.String()
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestExtractCertificateFromContext(t *testing.T) {
+	pemBytes, err := ioutil.ReadFile(filepath.Join("testdata", "certs", "Org1-client1-cert.pem"))
+	assert.NoError(t, err)
+	block, _ := pem.Decode(pemBytes)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 7050}
+
+	t.Run("NoPeer", func(t *testing.T) {
+		assert.Nil(t, comm.ExtractCertificateFromContext(context.Background()))
+	})
+
+	t.Run("NoTLS", func(t *testing.T) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+		assert.Nil(t, comm.ExtractCertificateFromContext(ctx))
+	})
+
+	t.Run("NoClientCert", func(t *testing.T) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr:     addr,
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{}},
+		})
+		assert.Nil(t, comm.ExtractCertificateFromContext(ctx))
+	})
+
+	t.Run("ClientCert", func(t *testing.T) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: addr,
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+			}},
+		})
+		assert.Equal(t, cert, comm.ExtractCertificateFromContext(ctx))
+	})
+}
+
+func TestClientIdentity(t *testing.T) {
+	pemBytes, err := ioutil.ReadFile(filepath.Join("testdata", "certs", "Org1-client1-cert.pem"))
+	assert.NoError(t, err)
+	block, _ := pem.Decode(pemBytes)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 7050}
+	assert.Equal(t, "", comm.ClientIdentity(context.Background()))
+	assert.Equal(t, "127.0.0.1:7050", comm.ClientIdentity(peer.NewContext(context.Background(), &peer.Peer{Addr: addr})))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: addr,
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+		}},
+	})
+	assert.Equal(t, cert.Subject.CommonName, comm.ClientIdentity(ctx))
+}
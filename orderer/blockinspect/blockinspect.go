@@ -0,0 +1,279 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blockinspect decodes blocks into a readable summary of their
+// header, metadata and envelopes, checking the data hash and signatures
+package blockinspect
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/extensions"
+	"github.com/hyperledger/fabric/orderer/common/hlc"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Block summarizes a block
+type Block struct {
+	Number       uint64 `json:"number"`
+	PreviousHash string `json:"previous_hash"`
+	DataHash     string `json:"data_hash"`
+	// DataHashValid is whether DataHash is the hash of the envelopes
+	DataHashValid bool       `json:"data_hash_valid"`
+	Metadata      []Metadata `json:"metadata"`
+	Envelopes     []Envelope `json:"envelopes"`
+}
+
+// Metadata summarizes a metadata slot of a block
+type Metadata struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	// Value is the hex encoded value of the slot, or of the value of its
+	// Metadata message if the slot holds one
+	Value string `json:"value,omitempty"`
+	// LastConfig is the index of the last config block, for the LAST_CONFIG
+	// slot
+	LastConfig *uint64 `json:"last_config,omitempty"`
+	// Timestamp is the hybrid logical clock time the block was ordered, for
+	// the TIMESTAMP slot
+	Timestamp  string      `json:"timestamp,omitempty"`
+	Signatures []Signature `json:"signatures,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// Envelope summarizes an envelope of a block. OrderedAt is the hybrid logical
+// clock time the envelope was ordered, if the transactions of the block are
+// stamped, and Extensions the hex encoded extensions it carries, as recorded
+// in the EXTENSIONS metadata of the block.
+type Envelope struct {
+	Index       int       `json:"index"`
+	Type        string    `json:"type"`
+	ChannelID   string    `json:"channel_id"`
+	TxID        string    `json:"tx_id,omitempty"`
+	Timestamp   string    `json:"timestamp,omitempty"`
+	OrderedAt   string    `json:"ordered_at,omitempty"`
+	Extensions  string    `json:"extensions,omitempty"`
+	Epoch       uint64    `json:"epoch,omitempty"`
+	Version     int32     `json:"version,omitempty"`
+	Nonce       string    `json:"nonce,omitempty"`
+	PayloadSize int       `json:"payload_size"`
+	Signature   Signature `json:"signature"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Signature summarizes a signature and the identity which created it. Valid
+// is whether the signature was made by the key of the creator certificate,
+// the certificate itself is not validated against the MSPs of the channel.
+type Signature struct {
+	MSPID   string `json:"msp_id,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Valid   bool   `json:"valid"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Inspect summarizes the block. Malformed parts of the block are reported in
+// the Error of the part rather than failing the inspection.
+func Inspect(block *cb.Block) *Block {
+	header := block.GetHeader()
+	result := &Block{
+		Number:       header.GetNumber(),
+		PreviousHash: hex.EncodeToString(header.GetPreviousHash()),
+		DataHash:     hex.EncodeToString(header.GetDataHash()),
+	}
+	if block.Data != nil {
+		result.DataHashValid = bytes.Equal(header.GetDataHash(), block.Data.Hash())
+	}
+
+	for i, value := range block.GetMetadata().GetMetadata() {
+		result.Metadata = append(result.Metadata, inspectMetadata(block, i, value))
+	}
+	for i, data := range block.GetData().GetData() {
+		result.Envelopes = append(result.Envelopes, inspectEnvelope(i, data))
+	}
+	// A malformed TIMESTAMP slot is reported with the metadata
+	if ts, err := hlc.FromBlock(block); err == nil && len(ts.GetTransactions()) == len(result.Envelopes) {
+		for i, txTimestamp := range ts.GetTransactions() {
+			result.Envelopes[i].OrderedAt = hlc.FromProto(txTimestamp).String()
+		}
+	}
+	// As is a malformed EXTENSIONS slot
+	if txExtensions, err := extensions.FromBlock(block); err == nil {
+		for i, ext := range txExtensions {
+			result.Envelopes[i].Extensions = hex.EncodeToString(ext)
+		}
+	}
+	return result
+}
+
+// InspectBytes unmarshals and summarizes a block
+func InspectBytes(raw []byte) (*Block, error) {
+	block := &cb.Block{}
+	if err := proto.Unmarshal(raw, block); err != nil {
+		return nil, fmt.Errorf("could not unmarshal block: %s", err)
+	}
+	return Inspect(block), nil
+}
+
+func inspectMetadata(block *cb.Block, index int, value []byte) Metadata {
+	result := Metadata{Index: index, Name: cb.BlockMetadataIndex(index).String()}
+	if len(value) == 0 {
+		return result
+	}
+
+	// The TRANSACTIONS_FILTER slot holds a bit array rather than a Metadata
+	// message, as may slots unknown to this version
+	if index != int(cb.BlockMetadataIndex_SIGNATURES) && index != int(cb.BlockMetadataIndex_LAST_CONFIG) && index != int(cb.BlockMetadataIndex_TIMESTAMP) && index != int(cb.BlockMetadataIndex_EXTENSIONS) {
+		result.Value = hex.EncodeToString(value)
+		return result
+	}
+
+	md := &cb.Metadata{}
+	if err := proto.Unmarshal(value, md); err != nil {
+		result.Value = hex.EncodeToString(value)
+		result.Error = fmt.Sprintf("could not unmarshal metadata: %s", err)
+		return result
+	}
+	result.Value = hex.EncodeToString(md.Value)
+	if index == int(cb.BlockMetadataIndex_LAST_CONFIG) {
+		lastConfig := &cb.LastConfig{}
+		if err := proto.Unmarshal(md.Value, lastConfig); err != nil {
+			result.Error = fmt.Sprintf("could not unmarshal last config: %s", err)
+		} else {
+			result.LastConfig = &lastConfig.Index
+		}
+	}
+	if index == int(cb.BlockMetadataIndex_TIMESTAMP) {
+		ts := &cb.OrderingTimestamps{}
+		if err := proto.Unmarshal(md.Value, ts); err != nil {
+			result.Error = fmt.Sprintf("could not unmarshal ordering timestamps: %s", err)
+		} else {
+			result.Timestamp = hlc.FromProto(ts.Block).String()
+		}
+	}
+
+	for _, sig := range md.Signatures {
+		signed := util.ConcatenateBytes(md.Value, sig.SignatureHeader, block.Header.Bytes())
+		result.Signatures = append(result.Signatures, inspectSignature(sig.SignatureHeader, signed, sig.Signature))
+	}
+	return result
+}
+
+func inspectEnvelope(index int, data []byte) Envelope {
+	result := Envelope{Index: index}
+	env, err := utils.UnmarshalEnvelope(data)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.PayloadSize = len(env.Payload)
+
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if payload.Header == nil {
+		result.Error = "payload has no header"
+		return result
+	}
+
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Type = cb.HeaderType(chdr.Type).String()
+	result.ChannelID = chdr.ChannelId
+	result.TxID = chdr.TxId
+	result.Epoch = chdr.Epoch
+	result.Version = chdr.Version
+	if chdr.Timestamp != nil {
+		result.Timestamp = time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos)).UTC().Format(time.RFC3339Nano)
+	}
+
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Nonce = hex.EncodeToString(shdr.Nonce)
+	result.Signature = inspectSignature(payload.Header.SignatureHeader, env.Payload, env.Signature)
+	return result
+}
+
+// inspectSignature checks signature over signed against the creator of the
+// marshaled signature header
+func inspectSignature(signatureHeader, signed, signature []byte) Signature {
+	result := Signature{}
+	shdr, err := utils.GetSignatureHeader(signatureHeader)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if len(shdr.Creator) == 0 {
+		result.Error = "unsigned"
+		return result
+	}
+
+	creator := &mspproto.SerializedIdentity{}
+	if err := proto.Unmarshal(shdr.Creator, creator); err != nil {
+		result.Error = fmt.Sprintf("could not unmarshal creator: %s", err)
+		return result
+	}
+	result.MSPID = creator.Mspid
+
+	block, _ := pem.Decode(creator.IdBytes)
+	if block == nil {
+		result.Error = "creator has no PEM encoded certificate"
+		return result
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		result.Error = fmt.Sprintf("could not parse creator certificate: %s", err)
+		return result
+	}
+	result.Subject = cert.Subject.CommonName
+
+	if err := verify(cert, signed, signature); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Valid = true
+	return result
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// verify checks an ECDSA signature over the SHA-256 digest of signed, as
+// produced by the software BCCSP
+func verify(cert *x509.Certificate, signed, signature []byte) error {
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported public key type %T", cert.PublicKey)
+	}
+	sig := &ecdsaSignature{}
+	if _, err := asn1.Unmarshal(signature, sig); err != nil {
+		return fmt.Errorf("could not unmarshal signature: %s", err)
+	}
+	if sig.R == nil || sig.S == nil || !ecdsa.Verify(pub, util.ComputeSHA256(signed), sig.R, sig.S) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
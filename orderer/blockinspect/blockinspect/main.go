@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// blockinspect prints a JSON summary of each of the marshaled blocks in the
+// files given, or of the block read from stdin, as written by orderer-cli
+// fetch
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hyperledger/fabric/orderer/blockinspect"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [block file]...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	failed := false
+	for _, name := range files {
+		if err := inspect(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error inspecting %s: %s\n", name, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func inspect(name string) error {
+	var raw []byte
+	var err error
+	if name == "-" {
+		raw, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		raw, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	block, err := blockinspect.InspectBytes(raw)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(block, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockinspect
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/common/util"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := mspmgmt.LoadDevMsp(); err != nil {
+		os.Exit(-1)
+	}
+	os.Exit(m.Run())
+}
+
+// signedMetadata returns a Metadata message signed over the value and the
+// block header as the orderer signs it
+func signedMetadata(signer crypto.LocalSigner, block *cb.Block, value []byte) []byte {
+	sig := &cb.MetadataSignature{SignatureHeader: utils.MarshalOrPanic(utils.NewSignatureHeaderOrPanic(signer))}
+	sig.Signature = utils.SignOrPanic(signer, util.ConcatenateBytes(value, sig.SignatureHeader, block.Header.Bytes()))
+	return utils.MarshalOrPanic(&cb.Metadata{Value: value, Signatures: []*cb.MetadataSignature{sig}})
+}
+
+func makeBlock(t *testing.T) *cb.Block {
+	signer := localmsp.NewSigner()
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_MESSAGE, "foo", signer, &cb.Envelope{Payload: []byte("data")}, 0, 0)
+	require.NoError(t, err)
+
+	block := cb.NewBlock(4, []byte("prev"))
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env), []byte("garbage")}
+	block.Header.DataHash = block.Data.Hash()
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = signedMetadata(signer, block, nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = signedMetadata(signer, block, utils.MarshalOrPanic(&cb.LastConfig{Index: 2}))
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TIMESTAMP] = signedMetadata(signer, block, utils.MarshalOrPanic(&cb.OrderingTimestamps{
+		Block:        &cb.HybridTimestamp{Physical: 1e9},
+		Transactions: []*cb.HybridTimestamp{{Physical: 1e9, Logical: 1}, {Physical: 1e9, Logical: 2}},
+	}))
+	block.Metadata.Metadata[cb.BlockMetadataIndex_EXTENSIONS] = utils.MarshalOrPanic(&cb.Metadata{Value: utils.MarshalOrPanic(&cb.TransactionExtensions{
+		Transactions: [][]byte{[]byte("trace"), nil},
+	})})
+	return block
+}
+
+func TestInspect(t *testing.T) {
+	block := makeBlock(t)
+	result, err := InspectBytes(utils.MarshalOrPanic(block))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(4), result.Number)
+	assert.Equal(t, "70726576", result.PreviousHash)
+	assert.True(t, result.DataHashValid)
+
+	require.Len(t, result.Metadata, len(block.Metadata.Metadata))
+	assert.Equal(t, "SIGNATURES", result.Metadata[0].Name)
+	require.Len(t, result.Metadata[0].Signatures, 1)
+	assert.True(t, result.Metadata[0].Signatures[0].Valid, result.Metadata[0].Signatures[0].Error)
+	assert.Equal(t, "DEFAULT", result.Metadata[0].Signatures[0].MSPID)
+	assert.Equal(t, "LAST_CONFIG", result.Metadata[1].Name)
+	require.NotNil(t, result.Metadata[1].LastConfig)
+	assert.Equal(t, uint64(2), *result.Metadata[1].LastConfig)
+	assert.True(t, result.Metadata[1].Signatures[0].Valid)
+	assert.Equal(t, "TIMESTAMP", result.Metadata[4].Name)
+	assert.Equal(t, "1970-01-01T00:00:01Z+0", result.Metadata[4].Timestamp)
+	assert.True(t, result.Metadata[4].Signatures[0].Valid)
+
+	require.Len(t, result.Envelopes, 2)
+	env := result.Envelopes[0]
+	assert.Equal(t, "MESSAGE", env.Type)
+	assert.Equal(t, "foo", env.ChannelID)
+	assert.NotEmpty(t, env.Timestamp)
+	assert.Equal(t, "1970-01-01T00:00:01Z+1", env.OrderedAt)
+	assert.Equal(t, "7472616365", env.Extensions)
+	assert.True(t, env.Signature.Valid, env.Signature.Error)
+	assert.Empty(t, env.Error)
+	assert.NotEmpty(t, result.Envelopes[1].Error)
+}
+
+func TestInspectTampered(t *testing.T) {
+	block := makeBlock(t)
+	block.Header.Number = 5
+	env, err := utils.UnmarshalEnvelope(block.Data.Data[0])
+	require.NoError(t, err)
+	env.Signature[len(env.Signature)-1] ^= 0xff
+	block.Data.Data[0] = utils.MarshalOrPanic(env)
+
+	result := Inspect(block)
+	assert.False(t, result.DataHashValid)
+	assert.False(t, result.Metadata[0].Signatures[0].Valid, "The header is covered by the block signature")
+	assert.NotEmpty(t, result.Metadata[0].Signatures[0].Error)
+	assert.False(t, result.Envelopes[0].Signature.Valid)
+}
+
+func TestInspectUnsigned(t *testing.T) {
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_MESSAGE, "foo", nil, &cb.Envelope{}, 0, 0)
+	require.NoError(t, err)
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+
+	result := Inspect(block)
+	assert.False(t, result.Envelopes[0].Signature.Valid)
+	assert.Equal(t, "unsigned", result.Envelopes[0].Signature.Error)
+
+	_, err = InspectBytes([]byte("not a block"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/client"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"golang.org/x/net/context"
+)
+
+// NewEnvelope wraps data in a payload of the header type for the channel and
+// signs it. The envelope is left unsigned if signer is nil.
+func NewEnvelope(headerType cb.HeaderType, channelID string, data []byte, signer crypto.LocalSigner) (*cb.Envelope, error) {
+	return client.NewEnvelope(headerType, channelID, data, signer)
+}
+
+// RetryOptions control the resubmission of a message the orderer could not
+// accept
+type RetryOptions struct {
+	// Retries is the number of times a message is resubmitted
+	Retries int
+	// Interval is the time waited before each retry
+	Interval time.Duration
+}
+
+// Broadcast submits env and returns the response of the orderer. The message
+// is resubmitted, on a new stream, if the stream fails or the orderer
+// responds with SERVICE_UNAVAILABLE, which it does while it cannot enqueue
+// messages. Any other response is returned as is.
+func Broadcast(client ab.AtomicBroadcastClient, env *cb.Envelope, retry RetryOptions) (*ab.BroadcastResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := broadcastOnce(client, env)
+		retriable := err != nil || resp.Status == cb.Status_SERVICE_UNAVAILABLE
+		if !retriable || attempt >= retry.Retries {
+			return resp, err
+		}
+		if err != nil {
+			logger.Warningf("Broadcast attempt %d failed: %s", attempt+1, err)
+		} else {
+			logger.Warningf("Broadcast attempt %d returned %s", attempt+1, resp.Status)
+		}
+		time.Sleep(retry.Interval)
+	}
+}
+
+func broadcastOnce(client ab.AtomicBroadcastClient, env *cb.Envelope) (*ab.BroadcastResponse, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Broadcast(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not open broadcast stream: %s", err)
+	}
+	if err := stream.Send(env); err != nil {
+		return nil, fmt.Errorf("could not send message: %s", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("could not receive response: %s", err)
+	}
+	stream.CloseSend()
+	return resp, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cli
+
+import (
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// mockOrderer responds to each broadcast message with the next of statuses,
+// and SUCCESS once they are exhausted, and to each seek request with the
+// blocks sought followed by deliverStatus. The blocks are sent in a single
+// response compressed with compression if it is set.
+type mockOrderer struct {
+	statuses []cb.Status
+	received []*cb.Envelope
+
+	blocks        []*cb.Block
+	deliverStatus cb.Status
+	seeks         []*cb.Envelope
+	compression   ab.Compression
+}
+
+func (mo *mockOrderer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	for {
+		env, err := srv.Recv()
+		if err != nil {
+			return nil
+		}
+		mo.received = append(mo.received, env)
+		status := cb.Status_SUCCESS
+		if len(mo.statuses) > 0 {
+			status, mo.statuses = mo.statuses[0], mo.statuses[1:]
+		}
+		if err := srv.Send(&ab.BroadcastResponse{Status: status}); err != nil {
+			return err
+		}
+	}
+}
+
+func (mo *mockOrderer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	env, err := srv.Recv()
+	if err != nil {
+		return nil
+	}
+	mo.seeks = append(mo.seeks, env)
+	if mo.compression != ab.Compression_NONE {
+		data, err := compression.Compress(mo.compression, utils.MarshalOrPanic(&ab.Blocks{Blocks: mo.seekBlocks(env)}))
+		if err != nil {
+			return err
+		}
+		if err := srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_CompressedBlocks{CompressedBlocks: &ab.CompressedBlocks{Compression: mo.compression, Data: data}}}); err != nil {
+			return err
+		}
+		return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: mo.deliverStatus}})
+	}
+	for _, block := range mo.seekBlocks(env) {
+		if err := srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Block{Block: block}}); err != nil {
+			return err
+		}
+	}
+	return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: mo.deliverStatus}})
+}
+
+// seekBlocks returns the blocks between the start and stop of a seek for a
+// specific block or the newest block, and every block otherwise
+func (mo *mockOrderer) seekBlocks(env *cb.Envelope) []*cb.Block {
+	seekInfo := &ab.SeekInfo{}
+	if _, err := utils.UnmarshalEnvelopeOfType(env, cb.HeaderType_DELIVER_SEEK_INFO, seekInfo); err != nil || len(mo.blocks) == 0 {
+		return mo.blocks
+	}
+	number := func(position *ab.SeekPosition, fallback uint64) uint64 {
+		switch t := position.GetType().(type) {
+		case *ab.SeekPosition_Newest:
+			return uint64(len(mo.blocks) - 1)
+		case *ab.SeekPosition_Specified:
+			return t.Specified.Number
+		}
+		return fallback
+	}
+	var blocks []*cb.Block
+	start, stop := number(seekInfo.Start, 0), number(seekInfo.Stop, math.MaxUint64)
+	for _, block := range mo.blocks {
+		if block.Header.Number >= start && block.Header.Number <= stop {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func startMockOrderer(t *testing.T, mo *mockOrderer) (ab.AtomicBroadcastClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	ab.RegisterAtomicBroadcastServer(server, mo)
+	go server.Serve(listener)
+
+	conn, err := Dial(ConnectionOptions{Address: listener.Addr().String(), Timeout: time.Second})
+	require.NoError(t, err)
+	return ab.NewAtomicBroadcastClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestNewEnvelope(t *testing.T) {
+	env, err := NewEnvelope(cb.HeaderType_MESSAGE, "foo", []byte("data"), mockcrypto.FakeLocalSigner)
+	require.NoError(t, err)
+	assert.NotNil(t, env.Signature)
+
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), payload.Data)
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", chdr.ChannelId)
+	assert.Equal(t, int32(cb.HeaderType_MESSAGE), chdr.Type)
+
+	env, err = NewEnvelope(cb.HeaderType_MESSAGE, "foo", []byte("data"), nil)
+	require.NoError(t, err)
+	assert.Nil(t, env.Signature)
+}
+
+func TestBroadcastRetry(t *testing.T) {
+	mo := &mockOrderer{statuses: []cb.Status{cb.Status_SERVICE_UNAVAILABLE, cb.Status_SERVICE_UNAVAILABLE}}
+	client, stop := startMockOrderer(t, mo)
+	defer stop()
+
+	env := &cb.Envelope{Payload: []byte("payload")}
+	resp, err := Broadcast(client, env, RetryOptions{Retries: 1})
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, resp.Status, "The last response is returned once the retries are exhausted")
+
+	resp, err = Broadcast(client, env, RetryOptions{Retries: 1})
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_SUCCESS, resp.Status)
+	assert.Len(t, mo.received, 3)
+}
+
+func TestBroadcastNoRetryOnRejection(t *testing.T) {
+	mo := &mockOrderer{statuses: []cb.Status{cb.Status_BAD_REQUEST}}
+	client, stop := startMockOrderer(t, mo)
+	defer stop()
+
+	resp, err := Broadcast(client, &cb.Envelope{}, RetryOptions{Retries: 3})
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_BAD_REQUEST, resp.Status)
+	assert.Len(t, mo.received, 1)
+}
+
+func TestDialTLSErrors(t *testing.T) {
+	_, err := Dial(ConnectionOptions{Address: "127.0.0.1:0", TLS: true, CAFile: "nonexistent"})
+	assert.Error(t, err)
+	_, err = Dial(ConnectionOptions{Address: "127.0.0.1:0", TLS: true, CertFile: "nonexistent"})
+	assert.Error(t, err)
+}
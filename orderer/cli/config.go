@@ -0,0 +1,220 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/tools/configtxlator/sanitycheck"
+	"github.com/hyperledger/fabric/common/tools/configtxlator/update"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// FetchConfig returns the latest config block of the channel, found through
+// the last config index of its newest block, and the config it holds
+func FetchConfig(client ab.AtomicBroadcastClient, channelID string, signer crypto.LocalSigner) (*cb.Block, *cb.Config, error) {
+	newest, err := fetchOne(client, channelID, &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	index, err := utils.GetLastConfigIndexFromBlock(newest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read last config index of block %d: %s", newest.Header.Number, err)
+	}
+
+	block := newest
+	if index != newest.Header.Number {
+		if block, err = fetchOne(client, channelID, specified(index), signer); err != nil {
+			return nil, nil, err
+		}
+	}
+	config, err := ConfigFromBlock(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, config, nil
+}
+
+// fetchOne returns the block at the position
+func fetchOne(client ab.AtomicBroadcastClient, channelID string, position *ab.SeekPosition, signer crypto.LocalSigner) (*cb.Block, error) {
+	var block *cb.Block
+	seekInfo := &ab.SeekInfo{Start: position, Stop: position, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY}
+	err := Fetch(client, channelID, seekInfo, signer, func(b *cb.Block) error {
+		block = b
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("no block was delivered")
+	}
+	return block, nil
+}
+
+// ConfigFromBlock returns the config held by a config block
+func ConfigFromBlock(block *cb.Block) (*cb.Config, error) {
+	if block.Data == nil || len(block.Data.Data) != 1 {
+		return nil, fmt.Errorf("block %d is not a config block, it does not hold exactly one envelope", block.GetHeader().GetNumber())
+	}
+	env, err := utils.UnmarshalEnvelope(block.Data.Data[0])
+	if err != nil {
+		return nil, err
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("block %d holds an envelope without header", block.Header.Number)
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, err
+	}
+	if chdr.Type != int32(cb.HeaderType_CONFIG) {
+		return nil, fmt.Errorf("block %d is not a config block, it holds a %s envelope", block.Header.Number, cb.HeaderType(chdr.Type))
+	}
+	configEnv, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return nil, err
+	}
+	if configEnv.Config == nil {
+		return nil, fmt.Errorf("block %d holds an empty config", block.Header.Number)
+	}
+	return configEnv.Config, nil
+}
+
+// WriteJSON writes the message as JSON with its nested messages decoded,
+// followed by a newline, so that it may be edited and read back with
+// ReadJSON
+func WriteJSON(w io.Writer, msg proto.Message) error {
+	if err := protolator.DeepMarshalJSON(w, msg); err != nil {
+		return fmt.Errorf("could not encode %T: %s", msg, err)
+	}
+	_, err := w.Write([]byte("\n"))
+	return err
+}
+
+// ReadJSON reads into msg the JSON written by WriteJSON
+func ReadJSON(r io.Reader, msg proto.Message) error {
+	if err := protolator.DeepUnmarshalJSON(r, msg); err != nil {
+		return fmt.Errorf("could not decode %T: %s", msg, err)
+	}
+	return nil
+}
+
+// ComputeUpdate returns the config update for the channel which changes the
+// original config into the updated one
+func ComputeUpdate(channelID string, original, updated *cb.Config) (*cb.ConfigUpdate, error) {
+	configUpdate, err := update.Compute(original, updated)
+	if err != nil {
+		return nil, err
+	}
+	configUpdate.ChannelId = channelID
+	return configUpdate, nil
+}
+
+// CheckConfig sanity checks a config, as the orderer would when validating
+// an update to it, returning an error if it is invalid and otherwise any
+// warnings, such as policies referring to unknown MSPs
+func CheckConfig(config *cb.Config) ([]string, error) {
+	messages, err := sanitycheck.Check(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages.GeneralErrors) > 0 {
+		return nil, fmt.Errorf("invalid config: %s", strings.Join(messages.GeneralErrors, ", "))
+	}
+	var problems []string
+	for _, msg := range messages.ElementErrors {
+		problems = append(problems, fmt.Sprintf("%s: %s", msg.Path, msg.Message))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid config: %s", strings.Join(problems, ", "))
+	}
+	var warnings []string
+	for _, msg := range messages.ElementWarnings {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", msg.Path, msg.Message))
+	}
+	return warnings, nil
+}
+
+// NewConfigUpdateEnvelope wraps the config update in a CONFIG_UPDATE
+// envelope, ready to be broadcast. If signer is set, it signs both the
+// config update and the envelope, otherwise the envelope is unsigned and
+// carries no signature, which may be added with SignConfigUpdate.
+func NewConfigUpdateEnvelope(configUpdate *cb.ConfigUpdate, signer crypto.LocalSigner) (*cb.Envelope, error) {
+	configUpdateEnv := &cb.ConfigUpdateEnvelope{ConfigUpdate: utils.MarshalOrPanic(configUpdate)}
+	if signer != nil {
+		if err := addConfigSignature(configUpdateEnv, signer); err != nil {
+			return nil, err
+		}
+	}
+	return NewEnvelope(cb.HeaderType_CONFIG_UPDATE, configUpdate.ChannelId, utils.MarshalOrPanic(configUpdateEnv), signer)
+}
+
+// SignConfigUpdate adds the signature of signer to the config update of a
+// CONFIG_UPDATE envelope, returning the envelope re-signed by signer, so
+// that a config update may be passed around for each of the signatures its
+// modification policies require
+func SignConfigUpdate(env *cb.Envelope, signer crypto.LocalSigner) (*cb.Envelope, error) {
+	configUpdateEnv, channelID, err := UnmarshalConfigUpdateEnvelope(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := addConfigSignature(configUpdateEnv, signer); err != nil {
+		return nil, err
+	}
+	return NewEnvelope(cb.HeaderType_CONFIG_UPDATE, channelID, utils.MarshalOrPanic(configUpdateEnv), signer)
+}
+
+// UnmarshalConfigUpdateEnvelope returns the config update envelope of a
+// CONFIG_UPDATE envelope and the channel it is for
+func UnmarshalConfigUpdateEnvelope(env *cb.Envelope) (*cb.ConfigUpdateEnvelope, string, error) {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, "", err
+	}
+	if payload.Header == nil {
+		return nil, "", fmt.Errorf("envelope has no header")
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, "", err
+	}
+	if chdr.Type != int32(cb.HeaderType_CONFIG_UPDATE) {
+		return nil, "", fmt.Errorf("envelope is a %s rather than a %s", cb.HeaderType(chdr.Type), cb.HeaderType_CONFIG_UPDATE)
+	}
+	configUpdateEnv, err := configtx.UnmarshalConfigUpdateEnvelope(payload.Data)
+	if err != nil {
+		return nil, "", err
+	}
+	return configUpdateEnv, chdr.ChannelId, nil
+}
+
+func addConfigSignature(configUpdateEnv *cb.ConfigUpdateEnvelope, signer crypto.LocalSigner) error {
+	signatureHeader, err := signer.NewSignatureHeader()
+	if err != nil {
+		return fmt.Errorf("could not create signature header: %s", err)
+	}
+	configSig := &cb.ConfigSignature{SignatureHeader: utils.MarshalOrPanic(signatureHeader)}
+	if configSig.Signature, err = signer.Sign(util.ConcatenateBytes(configSig.SignatureHeader, configUpdateEnv.ConfigUpdate)); err != nil {
+		return fmt.Errorf("could not sign config update: %s", err)
+	}
+	configUpdateEnv.Signatures = append(configUpdateEnv.Signatures, configSig)
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	"github.com/hyperledger/fabric/common/configtx"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configChain returns a channel whose genesis config block is followed by
+// two blocks pointing back at it
+func configChain() []*cb.Block {
+	genesis := provisional.New(genesisconfig.Load(genesisconfig.SampleInsecureProfile)).GenesisBlockForChannel("foo")
+	blocks := []*cb.Block{genesis}
+	for i := uint64(1); i < 3; i++ {
+		block := cb.NewBlock(i, genesis.Header.Hash())
+		block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+			Value: utils.MarshalOrPanic(&cb.LastConfig{Index: 0}),
+		})
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestFetchConfig(t *testing.T) {
+	blocks := configChain()
+	mo := &mockOrderer{blocks: blocks, deliverStatus: cb.Status_SUCCESS}
+	client, stop := startMockOrderer(t, mo)
+	defer stop()
+
+	block, conf, err := FetchConfig(client, "foo", nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), block.Header.Number)
+	assert.Contains(t, conf.ChannelGroup.Groups, config.OrdererGroupKey)
+	assert.Len(t, mo.seeks, 2)
+
+	_, err = ConfigFromBlock(blocks[1])
+	assert.Error(t, err)
+}
+
+func TestConfigJSONRoundTrip(t *testing.T) {
+	conf, err := ConfigFromBlock(configChain()[0])
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteJSON(buf, conf))
+	assert.Contains(t, buf.String(), `"BatchSize"`)
+
+	decoded := &cb.Config{}
+	require.NoError(t, ReadJSON(buf, decoded))
+	assert.True(t, proto.Equal(conf, decoded))
+
+	assert.Error(t, ReadJSON(bytes.NewBufferString("{"), decoded))
+}
+
+func TestCheckConfig(t *testing.T) {
+	conf, err := ConfigFromBlock(configChain()[0])
+	require.NoError(t, err)
+	_, err = CheckConfig(conf)
+	assert.NoError(t, err)
+
+	delete(conf.ChannelGroup.Values, config.HashingAlgorithmKey)
+	_, err = CheckConfig(conf)
+	assert.Error(t, err)
+}
+
+func TestConfigUpdate(t *testing.T) {
+	original, err := ConfigFromBlock(configChain()[0])
+	require.NoError(t, err)
+
+	updated := proto.Clone(original).(*cb.Config)
+	batchSize := updated.ChannelGroup.Groups[config.OrdererGroupKey].Values[config.BatchSizeKey]
+	batchSize.Value = utils.MarshalOrPanic(&ab.BatchSize{MaxMessageCount: 500, AbsoluteMaxBytes: 1 << 20, PreferredMaxBytes: 1 << 19})
+
+	configUpdate, err := ComputeUpdate("foo", original, updated)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", configUpdate.ChannelId)
+	written := configUpdate.WriteSet.Groups[config.OrdererGroupKey].Values[config.BatchSizeKey]
+	require.NotNil(t, written)
+	assert.Equal(t, batchSize.Version+1, written.Version)
+
+	_, err = ComputeUpdate("foo", original, original)
+	assert.Error(t, err)
+
+	env, err := NewConfigUpdateEnvelope(configUpdate, nil)
+	require.NoError(t, err)
+	configUpdateEnv, channelID, err := UnmarshalConfigUpdateEnvelope(env)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", channelID)
+	assert.Empty(t, configUpdateEnv.Signatures)
+	assert.Nil(t, env.Signature)
+
+	for i := 1; i <= 2; i++ {
+		env, err = SignConfigUpdate(env, mockcrypto.FakeLocalSigner)
+		require.NoError(t, err)
+		configUpdateEnv, _, err = UnmarshalConfigUpdateEnvelope(env)
+		require.NoError(t, err)
+		require.Len(t, configUpdateEnv.Signatures, i)
+		assert.NotNil(t, env.Signature)
+	}
+	sig := configUpdateEnv.Signatures[0]
+	assert.Equal(t, util.ConcatenateBytes(sig.SignatureHeader, configUpdateEnv.ConfigUpdate), sig.Signature)
+
+	decoded, err := configtx.UnmarshalConfigUpdate(configUpdateEnv.ConfigUpdate)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(configUpdate, decoded))
+
+	_, _, err = UnmarshalConfigUpdateEnvelope(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(utils.MakeChannelHeader(cb.HeaderType_MESSAGE, 0, "foo", 0), &cb.SignatureHeader{}),
+	})})
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cli implements the commands of orderer-cli, a client for
+// smoke-testing and scripting against the atomic broadcast service of an
+// orderer.
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/localmsp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/op/go-logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var logger = logging.MustGetLogger("orderer/cli")
+
+// ConnectionOptions describe how to reach an orderer
+type ConnectionOptions struct {
+	// Address is the host and port of the orderer
+	Address string
+	// TLS enables TLS, with the server certificate verified against the
+	// certificates of CAFile, or the system roots if it is empty
+	TLS    bool
+	CAFile string
+	// CertFile and KeyFile, if set, are the client certificate and key
+	// presented for mutual TLS
+	CertFile string
+	KeyFile  string
+	// ServerNameOverride replaces the host of Address when verifying the
+	// server certificate
+	ServerNameOverride string
+	// Timeout bounds the time taken to connect
+	Timeout time.Duration
+}
+
+// Dial connects to the orderer, blocking until the connection is established
+// or the timeout expires
+func Dial(opts ConnectionOptions) (*grpc.ClientConn, error) {
+	dialOpts := []grpc.DialOption{grpc.WithBlock(), grpc.WithTimeout(opts.Timeout)}
+	if opts.TLS {
+		tlsConfig, err := clientTLSConfig(opts)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(opts.Address, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %s", opts.Address, err)
+	}
+	return conn, nil
+}
+
+func clientTLSConfig(opts ConnectionOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: opts.ServerNameOverride}
+	if opts.CAFile != "" {
+		caPEM, err := ioutil.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %s", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", opts.CAFile)
+		}
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// LoadSigner loads the MSP in mspDir as the local MSP, returning a signer
+// for its identity
+func LoadSigner(mspDir, mspID string) (crypto.LocalSigner, error) {
+	if err := mspmgmt.LoadLocalMsp(mspDir, nil, mspID); err != nil {
+		return nil, fmt.Errorf("could not load MSP %s from %s: %s", mspID, mspDir, err)
+	}
+	return localmsp.NewSigner(), nil
+}
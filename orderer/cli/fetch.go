@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+)
+
+// The positions which may be given to ParseSeek besides a block number
+const (
+	SeekOldest = "oldest"
+	SeekNewest = "newest"
+	// SeekFollow delivers the newest block and each block after it as it is
+	// written
+	SeekFollow = "follow"
+)
+
+// ParseSeek returns the SeekInfo for a position: oldest, newest, follow or a
+// block number. The block at the position is delivered, unless until is set,
+// in which case the blocks up to and including block until are.
+func ParseSeek(position string, until *uint64) (*ab.SeekInfo, error) {
+	var start *ab.SeekPosition
+	switch position {
+	case SeekOldest:
+		start = &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}}
+	case SeekNewest:
+		start = &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}
+	case SeekFollow:
+		if until != nil {
+			return nil, fmt.Errorf("a block to stop at may not be given when following")
+		}
+		return &ab.SeekInfo{
+			Start:    &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}},
+			Stop:     specified(math.MaxUint64),
+			Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		}, nil
+	default:
+		number, err := strconv.ParseUint(position, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid position %q, expected oldest, newest, follow or a block number", position)
+		}
+		start = specified(number)
+	}
+
+	stop := start
+	if until != nil {
+		stop = specified(*until)
+	}
+	return &ab.SeekInfo{Start: start, Stop: stop, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY}, nil
+}
+
+func specified(number uint64) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: number}}}
+}
+
+// Fetch requests the blocks of seekInfo from the channel, calling handle with
+// each block delivered, which is decompressed if the orderer compressed it
+// with one of the algorithms accepted by seekInfo. It returns once every block was delivered, or with
+// an error if the orderer responds with any other status than SUCCESS or
+// handle fails. The request is unsigned if signer is nil.
+func Fetch(client ab.AtomicBroadcastClient, channelID string, seekInfo *ab.SeekInfo, signer crypto.LocalSigner, handle func(*cb.Block) error) error {
+	env, err := NewEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, channelID, utils.MarshalOrPanic(seekInfo), signer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Deliver(ctx)
+	if err != nil {
+		return fmt.Errorf("could not open deliver stream: %s", err)
+	}
+	if err := stream.Send(env); err != nil {
+		return fmt.Errorf("could not send seek request: %s", err)
+	}
+	stream.CloseSend()
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("could not receive block: %s", err)
+		}
+		switch t := resp.Type.(type) {
+		case *ab.DeliverResponse_Block:
+			if err := handle(t.Block); err != nil {
+				return err
+			}
+		case *ab.DeliverResponse_CompressedBlocks:
+			data, err := compression.Decompress(t.CompressedBlocks.Compression, t.CompressedBlocks.Data, 0)
+			if err != nil {
+				return fmt.Errorf("could not decompress blocks: %s", err)
+			}
+			blocks := &ab.Blocks{}
+			if err := proto.Unmarshal(data, blocks); err != nil {
+				return fmt.Errorf("could not decode blocks: %s", err)
+			}
+			for _, block := range blocks.Blocks {
+				if err := handle(block); err != nil {
+					return err
+				}
+			}
+		case *ab.DeliverResponse_Status:
+			if t.Status != cb.Status_SUCCESS {
+				return fmt.Errorf("orderer responded with %s", t.Status)
+			}
+			return nil
+		default:
+			return fmt.Errorf("unexpected response type %T", t)
+		}
+	}
+}
+
+// Block formats
+const (
+	FormatProtobuf = "protobuf"
+	FormatJSON     = "json"
+)
+
+// WriteBlock writes the block in the format, JSON blocks have their nested
+// messages decoded and are followed by a newline
+func WriteBlock(w io.Writer, block *cb.Block, format string) error {
+	switch format {
+	case FormatProtobuf:
+		_, err := w.Write(utils.MarshalOrPanic(block))
+		return err
+	case FormatJSON:
+		if err := protolator.DeepMarshalJSON(w, block); err != nil {
+			return fmt.Errorf("could not encode block %d: %s", block.GetHeader().GetNumber(), err)
+		}
+		_, err := w.Write([]byte("\n"))
+		return err
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
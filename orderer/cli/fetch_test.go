@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeek(t *testing.T) {
+	seekInfo, err := ParseSeek(SeekOldest, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, seekInfo.Start.GetOldest())
+	assert.NotNil(t, seekInfo.Stop.GetOldest())
+
+	seekInfo, err = ParseSeek(SeekNewest, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, seekInfo.Start.GetNewest())
+	assert.NotNil(t, seekInfo.Stop.GetNewest())
+
+	until := uint64(7)
+	seekInfo, err = ParseSeek("3", &until)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), seekInfo.Start.GetSpecified().Number)
+	assert.Equal(t, uint64(7), seekInfo.Stop.GetSpecified().Number)
+	assert.Equal(t, ab.SeekInfo_BLOCK_UNTIL_READY, seekInfo.Behavior)
+
+	seekInfo, err = ParseSeek(SeekFollow, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, seekInfo.Start.GetNewest())
+	assert.Equal(t, uint64(math.MaxUint64), seekInfo.Stop.GetSpecified().Number)
+
+	_, err = ParseSeek(SeekFollow, &until)
+	assert.Error(t, err)
+	_, err = ParseSeek("latest", nil)
+	assert.Error(t, err)
+}
+
+func TestFetch(t *testing.T) {
+	blocks := []*cb.Block{cb.NewBlock(0, nil), cb.NewBlock(1, []byte("prev"))}
+	mo := &mockOrderer{blocks: blocks, deliverStatus: cb.Status_SUCCESS}
+	client, stop := startMockOrderer(t, mo)
+	defer stop()
+
+	seekInfo, _ := ParseSeek(SeekOldest, nil)
+	var fetched []*cb.Block
+	err := Fetch(client, "foo", seekInfo, nil, func(block *cb.Block) error {
+		fetched = append(fetched, block)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, fetched, 2)
+	assert.True(t, proto.Equal(blocks[1], fetched[1]))
+
+	require.Len(t, mo.seeks, 1)
+	payload, err := utils.UnmarshalPayload(mo.seeks[0].Payload)
+	require.NoError(t, err)
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", chdr.ChannelId)
+	assert.Equal(t, int32(cb.HeaderType_DELIVER_SEEK_INFO), chdr.Type)
+	assert.Equal(t, utils.MarshalOrPanic(seekInfo), payload.Data)
+
+	err = Fetch(client, "foo", seekInfo, nil, func(block *cb.Block) error {
+		return fmt.Errorf("disk full")
+	})
+	assert.EqualError(t, err, "disk full")
+}
+
+func TestFetchCompressed(t *testing.T) {
+	blocks := []*cb.Block{cb.NewBlock(0, nil), cb.NewBlock(1, []byte("prev"))}
+	mo := &mockOrderer{blocks: blocks, deliverStatus: cb.Status_SUCCESS, compression: ab.Compression_SNAPPY}
+	client, stop := startMockOrderer(t, mo)
+	defer stop()
+
+	seekInfo, _ := ParseSeek(SeekOldest, nil)
+	seekInfo.Compression = []ab.Compression{ab.Compression_SNAPPY}
+	var fetched []*cb.Block
+	err := Fetch(client, "foo", seekInfo, nil, func(block *cb.Block) error {
+		fetched = append(fetched, block)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, fetched, 2)
+	assert.True(t, proto.Equal(blocks[1], fetched[1]))
+}
+
+func TestFetchStatus(t *testing.T) {
+	mo := &mockOrderer{deliverStatus: cb.Status_NOT_FOUND}
+	client, stop := startMockOrderer(t, mo)
+	defer stop()
+
+	seekInfo, _ := ParseSeek("5", nil)
+	err := Fetch(client, "foo", seekInfo, nil, func(block *cb.Block) error { return nil })
+	assert.EqualError(t, err, "orderer responded with NOT_FOUND")
+}
+
+func TestWriteBlock(t *testing.T) {
+	block := cb.NewBlock(3, []byte("prev"))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteBlock(buf, block, FormatProtobuf))
+	decoded := &cb.Block{}
+	require.NoError(t, proto.Unmarshal(buf.Bytes(), decoded))
+	assert.True(t, proto.Equal(block, decoded))
+
+	buf.Reset()
+	require.NoError(t, WriteBlock(buf, block, FormatJSON))
+	assert.Contains(t, buf.String(), `"number": "3"`)
+
+	assert.Error(t, WriteBlock(buf, block, "yaml"))
+}
@@ -0,0 +1,372 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// orderer-cli is a client of the atomic broadcast service of an orderer
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/cli"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	app = kingpin.New("orderer-cli", "Hyperledger Fabric orderer client")
+
+	address            = app.Flag("orderer", "The host and port of the orderer.").Short('o').Default("127.0.0.1:7050").String()
+	tlsEnabled         = app.Flag("tls", "Connect to the orderer with TLS.").Bool()
+	caFile             = app.Flag("cafile", "The PEM encoded CA certificates trusted to verify the orderer, the system roots if unset.").String()
+	certFile           = app.Flag("certfile", "The PEM encoded client certificate for mutual TLS.").String()
+	keyFile            = app.Flag("keyfile", "The PEM encoded client key for mutual TLS.").String()
+	serverNameOverride = app.Flag("servername", "The name verified in the orderer TLS certificate, the host of the orderer if unset.").String()
+	connTimeout        = app.Flag("timeout", "How long to wait to connect to the orderer.").Default("3s").Duration()
+	mspDir             = app.Flag("mspdir", "The MSP directory of the identity signing messages, messages are unsigned if unset.").String()
+	mspID              = app.Flag("mspid", "The ID of the MSP of the identity signing messages.").Default("DEFAULT").String()
+
+	broadcastCmd      = app.Command("broadcast", "Submit a message for ordering.")
+	broadcastChannel  = broadcastCmd.Flag("channel", "The channel to broadcast to, required unless --envelope is set.").Short('c').String()
+	broadcastType     = broadcastCmd.Flag("type", "The header type of the message.").Default(cb.HeaderType_MESSAGE.String()).Enum(headerTypes()...)
+	broadcastFile     = broadcastCmd.Arg("file", "The file holding the payload data, or - for stdin.").Required().String()
+	broadcastEnvelope = broadcastCmd.Flag("envelope", "The file holds a marshaled envelope which is submitted as is.").Bool()
+	broadcastRetries  = broadcastCmd.Flag("retries", "How many times to resubmit the message while the orderer is unavailable.").Default("0").Int()
+	broadcastInterval = broadcastCmd.Flag("retry-interval", "How long to wait before resubmitting the message.").Default("1s").Duration()
+
+	fetchCmd      = app.Command("fetch", "Fetch blocks from a channel.")
+	fetchChannel  = fetchCmd.Flag("channel", "The channel to fetch from.").Short('c').Required().String()
+	fetchPosition = fetchCmd.Arg("position", "oldest, newest, follow or the number of the block to fetch.").Default(cli.SeekNewest).String()
+	fetchUntil    = fetchCmd.Flag("until", "Fetch every block from the position up to and including this block number.").String()
+	fetchFormat   = fetchCmd.Flag("format", "The format blocks are written in.").Default(cli.FormatProtobuf).Enum(cli.FormatProtobuf, cli.FormatJSON)
+	fetchOutput   = fetchCmd.Flag("output", "The directory each block is written to as a file, blocks are written to stdout if unset.").String()
+	fetchFailFast = fetchCmd.Flag("fail-if-not-ready", "Fail with NOT_FOUND if a block was not written yet, rather than waiting for it.").Bool()
+	fetchCompress = fetchCmd.Flag("compression", "An algorithm, gzip or snappy, by which the orderer may compress blocks, by preference. May be repeated.").Enums("gzip", "snappy")
+
+	configCmd = app.Command("config", "Inspect and update the configuration of a channel. A channel is updated by fetching its config, editing it, computing the update, collecting signatures and broadcasting the update envelope.")
+
+	configFetchCmd     = configCmd.Command("fetch", "Fetch the latest config of a channel as JSON.")
+	configFetchChannel = configFetchCmd.Flag("channel", "The channel to fetch the config of.").Short('c').Required().String()
+	configFetchOutput  = configFetchCmd.Flag("output", "The file the config is written to, stdout if unset.").String()
+
+	configUpdateCmd      = configCmd.Command("update", "Compute the config update envelope which changes the config of a channel into an edited config, signed by the --mspdir identity if set.")
+	configUpdateChannel  = configUpdateCmd.Flag("channel", "The channel to update.").Short('c').Required().String()
+	configUpdateUpdated  = configUpdateCmd.Arg("updated", "The JSON file of the desired config.").Required().String()
+	configUpdateOriginal = configUpdateCmd.Flag("original", "The JSON file of the config to update, the latest config of the channel is fetched if unset.").String()
+	configUpdateOutput   = configUpdateCmd.Flag("output", "The file the config update envelope is written to.").Required().String()
+
+	configSignCmd    = configCmd.Command("sign", "Add the signature of the --mspdir identity to a config update envelope.")
+	configSignFile   = configSignCmd.Arg("file", "The file of the config update envelope.").Required().String()
+	configSignOutput = configSignCmd.Flag("output", "The file the signed envelope is written to, the input file is replaced if unset.").String()
+
+	configDecodeCmd  = configCmd.Command("decode", "Print a config update envelope as JSON.")
+	configDecodeFile = configDecodeCmd.Arg("file", "The file of the config update envelope, or - for stdin.").Required().String()
+)
+
+func headerTypes() []string {
+	var types []string
+	for name := range cb.HeaderType_value {
+		types = append(types, name)
+	}
+	return types
+}
+
+func main() {
+	var err error
+	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	case broadcastCmd.FullCommand():
+		err = broadcast()
+	case fetchCmd.FullCommand():
+		err = fetch()
+	case configFetchCmd.FullCommand():
+		err = configFetch()
+	case configUpdateCmd.FullCommand():
+		err = configUpdate()
+	case configSignCmd.FullCommand():
+		err = configSign()
+	case configDecodeCmd.FullCommand():
+		err = configDecode()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func connectionOptions() cli.ConnectionOptions {
+	return cli.ConnectionOptions{
+		Address:            *address,
+		TLS:                *tlsEnabled,
+		CAFile:             *caFile,
+		CertFile:           *certFile,
+		KeyFile:            *keyFile,
+		ServerNameOverride: *serverNameOverride,
+		Timeout:            *connTimeout,
+	}
+}
+
+func signer() (crypto.LocalSigner, error) {
+	if *mspDir == "" {
+		return nil, nil
+	}
+	return cli.LoadSigner(*mspDir, *mspID)
+}
+
+func readFile(name string) ([]byte, error) {
+	if name == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(name)
+}
+
+func broadcast() error {
+	data, err := readFile(*broadcastFile)
+	if err != nil {
+		return err
+	}
+
+	env := &cb.Envelope{}
+	if *broadcastEnvelope {
+		if err := proto.Unmarshal(data, env); err != nil {
+			return fmt.Errorf("could not unmarshal envelope: %s", err)
+		}
+	} else {
+		if *broadcastChannel == "" {
+			return fmt.Errorf("a channel is required")
+		}
+		s, err := signer()
+		if err != nil {
+			return err
+		}
+		headerType := cb.HeaderType(cb.HeaderType_value[*broadcastType])
+		if env, err = cli.NewEnvelope(headerType, *broadcastChannel, data, s); err != nil {
+			return err
+		}
+	}
+
+	conn, err := cli.Dial(connectionOptions())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	resp, err := cli.Broadcast(ab.NewAtomicBroadcastClient(conn), env, cli.RetryOptions{
+		Retries:  *broadcastRetries,
+		Interval: *broadcastInterval,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Status: %s (%s)\n", resp.Status, time.Since(start))
+	if resp.Status != cb.Status_SUCCESS {
+		return fmt.Errorf("message was not accepted")
+	}
+	return nil
+}
+
+func fetch() error {
+	var until *uint64
+	if *fetchUntil != "" {
+		number, err := strconv.ParseUint(*fetchUntil, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid block number %q", *fetchUntil)
+		}
+		until = &number
+	}
+	seekInfo, err := cli.ParseSeek(*fetchPosition, until)
+	if err != nil {
+		return err
+	}
+	if *fetchFailFast {
+		if *fetchPosition == cli.SeekFollow {
+			return fmt.Errorf("--fail-if-not-ready may not be set when following")
+		}
+		seekInfo.Behavior = ab.SeekInfo_FAIL_IF_NOT_READY
+	}
+	if seekInfo.Compression, err = compression.ParseAll(*fetchCompress); err != nil {
+		return err
+	}
+	if *fetchOutput == "" && *fetchFormat == cli.FormatProtobuf && (until != nil || *fetchPosition == cli.SeekFollow) {
+		return fmt.Errorf("several protobuf blocks may not be written to stdout, set --output or --format json")
+	}
+
+	s, err := signer()
+	if err != nil {
+		return err
+	}
+
+	conn, err := cli.Dial(connectionOptions())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return cli.Fetch(ab.NewAtomicBroadcastClient(conn), *fetchChannel, seekInfo, s, func(block *cb.Block) error {
+		if *fetchOutput == "" {
+			return cli.WriteBlock(os.Stdout, block, *fetchFormat)
+		}
+		extension := "block"
+		if *fetchFormat == cli.FormatJSON {
+			extension = "json"
+		}
+		name := filepath.Join(*fetchOutput, fmt.Sprintf("%s_%d.%s", *fetchChannel, block.GetHeader().GetNumber(), extension))
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := cli.WriteBlock(f, block, *fetchFormat); err != nil {
+			f.Close()
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Wrote", name)
+		return f.Close()
+	})
+}
+
+func fetchConfig(channelID string) (*cb.Config, error) {
+	s, err := signer()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := cli.Dial(connectionOptions())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	block, config, err := cli.FetchConfig(ab.NewAtomicBroadcastClient(conn), channelID, s)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(os.Stderr, "Fetched config from block", block.Header.Number)
+	return config, nil
+}
+
+func readConfig(name string) (*cb.Config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	config := &cb.Config{}
+	if err := cli.ReadJSON(f, config); err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", name, err)
+	}
+	return config, nil
+}
+
+func readEnvelope(name string) (*cb.Envelope, error) {
+	data, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	env := &cb.Envelope{}
+	if err := proto.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("could not unmarshal envelope: %s", err)
+	}
+	return env, nil
+}
+
+func configFetch() error {
+	config, err := fetchConfig(*configFetchChannel)
+	if err != nil {
+		return err
+	}
+	if *configFetchOutput == "" {
+		return cli.WriteJSON(os.Stdout, config)
+	}
+	f, err := os.Create(*configFetchOutput)
+	if err != nil {
+		return err
+	}
+	if err := cli.WriteJSON(f, config); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func configUpdate() error {
+	updated, err := readConfig(*configUpdateUpdated)
+	if err != nil {
+		return err
+	}
+	warnings, err := cli.CheckConfig(updated)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", warning)
+	}
+
+	var original *cb.Config
+	if *configUpdateOriginal != "" {
+		original, err = readConfig(*configUpdateOriginal)
+	} else {
+		original, err = fetchConfig(*configUpdateChannel)
+	}
+	if err != nil {
+		return err
+	}
+
+	configUpdate, err := cli.ComputeUpdate(*configUpdateChannel, original, updated)
+	if err != nil {
+		return err
+	}
+	s, err := signer()
+	if err != nil {
+		return err
+	}
+	env, err := cli.NewConfigUpdateEnvelope(configUpdate, s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(*configUpdateOutput, utils.MarshalOrPanic(env), 0644)
+}
+
+func configSign() error {
+	if *mspDir == "" {
+		return fmt.Errorf("--mspdir is required to sign")
+	}
+	env, err := readEnvelope(*configSignFile)
+	if err != nil {
+		return err
+	}
+	s, err := signer()
+	if err != nil {
+		return err
+	}
+	if env, err = cli.SignConfigUpdate(env, s); err != nil {
+		return err
+	}
+	output := *configSignOutput
+	if output == "" {
+		output = *configSignFile
+	}
+	return ioutil.WriteFile(output, utils.MarshalOrPanic(env), 0644)
+}
+
+func configDecode() error {
+	env, err := readEnvelope(*configDecodeFile)
+	if err != nil {
+		return err
+	}
+	if _, _, err := cli.UnmarshalConfigUpdateEnvelope(env); err != nil {
+		return err
+	}
+	return cli.WriteJSON(os.Stdout, env)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// NewEnvelope wraps data in a payload of the header type for the channel and
+// signs it. The envelope is left unsigned if signer is nil.
+func NewEnvelope(headerType cb.HeaderType, channelID string, data []byte, signer crypto.LocalSigner) (*cb.Envelope, error) {
+	signatureHeader := &cb.SignatureHeader{}
+	if signer != nil {
+		var err error
+		if signatureHeader, err = signer.NewSignatureHeader(); err != nil {
+			return nil, fmt.Errorf("could not create signature header: %s", err)
+		}
+	}
+
+	payload := utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(utils.MakeChannelHeader(headerType, 0, channelID, 0), signatureHeader),
+		Data:   data,
+	})
+
+	env := &cb.Envelope{Payload: payload}
+	if signer != nil {
+		sig, err := signer.Sign(payload)
+		if err != nil {
+			return nil, fmt.Errorf("could not sign payload: %s", err)
+		}
+		env.Signature = sig
+	}
+	return env, nil
+}
+
+// Broadcast submits the envelope to the ordering service, returning the
+// response of the orderer which accepted it. It returns a *StatusError if the
+// orderer rejected it and an *UnavailableError if no orderer could take it.
+// Orderers which redirect the envelope, as read replicas do, are failed over.
+func (c *Client) Broadcast(ctx context.Context, env *cb.Envelope) (*ab.BroadcastResponse, error) {
+	var resp *ab.BroadcastResponse
+	err := c.withFailover(ctx, func(conn *grpc.ClientConn) error {
+		var err error
+		resp, err = broadcastOnce(ctx, ab.NewAtomicBroadcastClient(conn), env)
+		if err == nil && resp.Status != cb.Status_SUCCESS {
+			return &StatusError{Status: resp.Status, Endpoints: resp.Endpoints}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Submit wraps data in an envelope of the header type for the channel,
+// signed by the signer of the client, and broadcasts it
+func (c *Client) Submit(ctx context.Context, headerType cb.HeaderType, channelID string, data []byte) (*ab.BroadcastResponse, error) {
+	env, err := NewEnvelope(headerType, channelID, data, c.config.Signer)
+	if err != nil {
+		return nil, err
+	}
+	return c.Broadcast(ctx, env)
+}
+
+func broadcastOnce(ctx context.Context, client ab.AtomicBroadcastClient, env *cb.Envelope) (*ab.BroadcastResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.Broadcast(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not open broadcast stream: %s", err)
+	}
+	if err := stream.Send(env); err != nil {
+		return nil, fmt.Errorf("could not send message: %s", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("could not receive response: %s", err)
+	}
+	stream.CloseSend()
+	return resp, nil
+}
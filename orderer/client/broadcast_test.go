@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"testing"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestNewEnvelope(t *testing.T) {
+	env, err := NewEnvelope(cb.HeaderType_MESSAGE, "foo", []byte("data"), nil)
+	require.NoError(t, err)
+	assert.Nil(t, env.Signature)
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	require.NoError(t, err)
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", chdr.ChannelId)
+	assert.Equal(t, int32(cb.HeaderType_MESSAGE), chdr.Type)
+	assert.Equal(t, []byte("data"), payload.Data)
+
+	env, err = NewEnvelope(cb.HeaderType_MESSAGE, "foo", []byte("data"), mockcrypto.FakeLocalSigner)
+	require.NoError(t, err)
+	assert.Equal(t, env.Payload, env.Signature, "Fake signer signs with the message itself")
+	payload, err = utils.UnmarshalPayload(env.Payload)
+	require.NoError(t, err)
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	require.NoError(t, err)
+	assert.Equal(t, mockcrypto.FakeLocalSigner.Identity, shdr.Creator)
+}
+
+func TestBroadcast(t *testing.T) {
+	orderer := startOrderer(t, "foo")
+	defer orderer.Stop()
+	other := startOrderer(t)
+	defer other.Stop()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: orderer.Address}, {Address: other.Address}}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	resp, err := c.Submit(context.Background(), cb.HeaderType_MESSAGE, "foo", []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_SUCCESS, resp.Status)
+
+	_, err = c.Submit(context.Background(), cb.HeaderType_MESSAGE, "missing", []byte("data"))
+	assert.Equal(t, &StatusError{Status: cb.Status_NOT_FOUND}, err)
+	assert.EqualError(t, err, "orderer responded with NOT_FOUND")
+	assert.Equal(t, []*endpoint{c.endpoints[0], c.endpoints[1]}, c.candidates(), "Rejection should not count as a failure of the orderer")
+}
+
+// redirectingOrderer redirects every broadcast message, as read replicas do
+type redirectingOrderer struct {
+	flakyOrderer
+	endpoints []string
+}
+
+func (ro *redirectingOrderer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	if _, err := srv.Recv(); err != nil {
+		return err
+	}
+	return srv.Send(&ab.BroadcastResponse{Status: cb.Status_TEMPORARY_REDIRECT, Endpoints: ro.endpoints})
+}
+
+func TestBroadcastRedirect(t *testing.T) {
+	orderer := startOrderer(t, "foo")
+	defer orderer.Stop()
+	replicaAddress, stopReplica := serve(t, &redirectingOrderer{endpoints: []string{orderer.Address}})
+	defer stopReplica()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: replicaAddress}, {Address: orderer.Address}}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	resp, err := c.Submit(context.Background(), cb.HeaderType_MESSAGE, "foo", []byte("data"))
+	require.NoError(t, err, "The message should be broadcast to the next orderer")
+	assert.Equal(t, cb.Status_SUCCESS, resp.Status)
+
+	c, err = New(Config{Endpoints: []Endpoint{{Address: replicaAddress}}})
+	require.NoError(t, err)
+	defer c.Close()
+	_, err = c.Submit(context.Background(), cb.HeaderType_MESSAGE, "foo", []byte("data"))
+	require.IsType(t, &UnavailableError{}, err)
+	assert.Equal(t, &StatusError{Status: cb.Status_TEMPORARY_REDIRECT, Endpoints: []string{orderer.Address}}, err.(*UnavailableError).Errors[0].Err)
+	assert.Contains(t, err.Error(), "orderer responded with TEMPORARY_REDIRECT to "+orderer.Address)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package client is a Go client of the atomic broadcast service of the
+// ordering service. A Client spreads its requests over a pool of connections
+// to each orderer it is configured with, and fails over to the next orderer
+// when one cannot be reached.
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var logger = logging.MustGetLogger("orderer/client")
+
+const (
+	defaultDialTimeout    = 5 * time.Second
+	defaultFailureBackoff = 5 * time.Second
+)
+
+// Endpoint is an orderer of the ordering service
+type Endpoint struct {
+	// Address is the host and port of the orderer
+	Address string
+	// TLS, if set, is the configuration of the TLS connections to the
+	// orderer, which are otherwise insecure
+	TLS *tls.Config
+}
+
+// Config is the configuration of a Client
+type Config struct {
+	// Endpoints are the orderers requests are sent to. Requests go to the
+	// first orderer which is reachable, trying them in order.
+	Endpoints []Endpoint
+	// Signer, if set, signs the envelopes created by the client, which are
+	// otherwise unsigned
+	Signer crypto.LocalSigner
+	// ConnectionsPerEndpoint is the number of connections opened to each
+	// orderer, over which streams are spread in turn, one if zero
+	ConnectionsPerEndpoint int
+	// DialTimeout bounds the time taken to connect to an orderer, five
+	// seconds if zero
+	DialTimeout time.Duration
+	// FailureBackoff is how long an orderer which could not be reached is
+	// only tried after the others, five seconds if zero
+	FailureBackoff time.Duration
+	// DialOptions are added to the options of every connection
+	DialOptions []grpc.DialOption
+	// Compression lists the algorithms, by preference, in which the blocks
+	// of deliver requests which accept none may be sent compressed
+	Compression []ab.Compression
+	// MaxDecompressedSize bounds the size of the blocks of a compressed
+	// response once decompressed, compression.DefaultMaxSize if zero
+	MaxDecompressedSize int
+	// Clock times the failure backoff, the wall clock if nil
+	Clock clock.Clock
+}
+
+// Client sends requests to the ordering service. It is safe for concurrent
+// use.
+type Client struct {
+	config    Config
+	endpoints []*endpoint
+
+	mutex  sync.Mutex
+	closed bool
+}
+
+// endpoint is an orderer along with the pool of connections to it
+type endpoint struct {
+	Endpoint
+
+	mutex    sync.Mutex
+	conns    []*grpc.ClientConn
+	next     int
+	failedAt time.Time
+}
+
+// New creates a client of the orderers of the configuration. Connections are
+// opened on first use.
+func New(config Config) (*Client, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("at least one endpoint is required")
+	}
+	if config.ConnectionsPerEndpoint <= 0 {
+		config.ConnectionsPerEndpoint = 1
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultDialTimeout
+	}
+	if config.FailureBackoff <= 0 {
+		config.FailureBackoff = defaultFailureBackoff
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real()
+	}
+
+	c := &Client{config: config}
+	for _, e := range config.Endpoints {
+		if e.Address == "" {
+			return nil, fmt.Errorf("endpoint address is required")
+		}
+		c.endpoints = append(c.endpoints, &endpoint{
+			Endpoint: e,
+			conns:    make([]*grpc.ClientConn, config.ConnectionsPerEndpoint),
+		})
+	}
+	return c, nil
+}
+
+// Signer returns the signer of the envelopes created by the client
+func (c *Client) Signer() crypto.LocalSigner {
+	return c.config.Signer
+}
+
+// Close closes the connections of the client, after which it may no longer
+// be used
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	c.closed = true
+	c.mutex.Unlock()
+
+	for _, e := range c.endpoints {
+		e.mutex.Lock()
+		for i, conn := range e.conns {
+			if conn != nil {
+				conn.Close()
+				e.conns[i] = nil
+			}
+		}
+		e.mutex.Unlock()
+	}
+	return nil
+}
+
+// conn returns the next connection of the pool, connecting if it was not
+// yet opened
+func (e *endpoint) conn(ctx context.Context, config Config) (*grpc.ClientConn, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	i := e.next
+	e.next = (e.next + 1) % len(e.conns)
+	if e.conns[i] != nil {
+		return e.conns[i], nil
+	}
+
+	opts := append([]grpc.DialOption{grpc.WithBlock()}, config.DialOptions...)
+	if e.TLS != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(e.TLS)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, config.DialTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(dialCtx, e.Address, opts...)
+	if err != nil {
+		return nil, err
+	}
+	e.conns[i] = conn
+	return conn, nil
+}
+
+func (e *endpoint) failed(now time.Time) {
+	e.mutex.Lock()
+	e.failedAt = now
+	e.mutex.Unlock()
+}
+
+func (e *endpoint) backingOff(now time.Time, backoff time.Duration) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return !e.failedAt.IsZero() && now.Sub(e.failedAt) < backoff
+}
+
+// candidates returns the endpoints in the order they are tried, those which
+// failed recently last
+func (c *Client) candidates() []*endpoint {
+	now := c.config.Clock.Now()
+	var healthy, backingOff []*endpoint
+	for _, e := range c.endpoints {
+		if e.backingOff(now, c.config.FailureBackoff) {
+			backingOff = append(backingOff, e)
+		} else {
+			healthy = append(healthy, e)
+		}
+	}
+	return append(healthy, backingOff...)
+}
+
+// withFailover calls do with a connection to each endpoint in turn until it
+// succeeds. An endpoint is given up on if it cannot be connected to or do
+// fails with an error which is not final; a final error or the end of the
+// context is returned as is.
+func (c *Client) withFailover(ctx context.Context, do func(conn *grpc.ClientConn) error) error {
+	c.mutex.Lock()
+	closed := c.closed
+	c.mutex.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	unavailable := &UnavailableError{}
+	for _, e := range c.candidates() {
+		conn, err := e.conn(ctx, c.config)
+		if err == nil {
+			err = do(conn)
+			if err == nil {
+				return nil
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if isFinal(err) {
+			return err
+		}
+		logger.Warningf("Orderer %s failed, trying the next one: %s", e.Address, err)
+		e.failed(c.config.Clock.Now())
+		unavailable.Errors = append(unavailable.Errors, &EndpointError{Address: e.Address, Err: err})
+	}
+	return unavailable
+}
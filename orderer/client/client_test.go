@@ -0,0 +1,191 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// closedAddress returns an address nothing listens on
+func closedAddress(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	lis.Close()
+	return lis.Addr().String()
+}
+
+// systemChannelID is the channel every orderer started by the tests serves
+const systemChannelID = "testchainid"
+
+// channelOrderer accepts the messages broadcast to its channels, and delivers
+// the genesis block of each, answering NOT_FOUND for any other channel
+type channelOrderer struct {
+	channels map[string]bool
+}
+
+// channel returns the channel of the envelope, if the orderer serves it
+func (co *channelOrderer) channel(env *cb.Envelope) (string, bool) {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil || payload.Header == nil {
+		return "", false
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return "", false
+	}
+	return chdr.ChannelId, co.channels[chdr.ChannelId]
+}
+
+func (co *channelOrderer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	for {
+		env, err := srv.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		status := cb.Status_SUCCESS
+		if _, ok := co.channel(env); !ok {
+			status = cb.Status_NOT_FOUND
+		}
+		if err := srv.Send(&ab.BroadcastResponse{Status: status}); err != nil {
+			return err
+		}
+	}
+}
+
+func (co *channelOrderer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	env, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	if _, ok := co.channel(env); !ok {
+		return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_NOT_FOUND}})
+	}
+	if err := srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Block{Block: cb.NewBlock(0, nil)}}); err != nil {
+		return err
+	}
+	return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_SUCCESS}})
+}
+
+// testOrderer is an orderer started by the tests
+type testOrderer struct {
+	Address string
+	Stop    func()
+}
+
+// startOrderer starts an orderer serving the system channel and the channels
+func startOrderer(t *testing.T, channels ...string) *testOrderer {
+	co := &channelOrderer{channels: map[string]bool{systemChannelID: true}}
+	for _, chainID := range channels {
+		co.channels[chainID] = true
+	}
+	address, stop := serve(t, co)
+	return &testOrderer{Address: address, Stop: stop}
+}
+
+func TestNewValidation(t *testing.T) {
+	_, err := New(Config{})
+	assert.EqualError(t, err, "at least one endpoint is required")
+
+	_, err = New(Config{Endpoints: []Endpoint{{}}})
+	assert.EqualError(t, err, "endpoint address is required")
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: "127.0.0.1:7050"}}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.config.ConnectionsPerEndpoint)
+	assert.Equal(t, defaultDialTimeout, c.config.DialTimeout)
+	assert.Equal(t, defaultFailureBackoff, c.config.FailureBackoff)
+}
+
+func TestConnectionPool(t *testing.T) {
+	orderer := startOrderer(t)
+	defer orderer.Stop()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: orderer.Address}}, ConnectionsPerEndpoint: 2})
+	require.NoError(t, err)
+	e := c.endpoints[0]
+
+	first, err := e.conn(context.Background(), c.config)
+	require.NoError(t, err)
+	second, err := e.conn(context.Background(), c.config)
+	require.NoError(t, err)
+	third, err := e.conn(context.Background(), c.config)
+	require.NoError(t, err)
+	assert.True(t, first != second, "Each connection of the pool should be used in turn")
+	assert.True(t, first == third, "Connections should be reused")
+
+	require.NoError(t, c.Close())
+	assert.Nil(t, e.conns[0])
+	assert.Nil(t, e.conns[1])
+	_, err = c.Submit(context.Background(), 0, systemChannelID, nil)
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestFailover(t *testing.T) {
+	orderer := startOrderer(t)
+	defer orderer.Stop()
+
+	down := closedAddress(t)
+	clk := clock.NewManual(time.Unix(0, 0))
+	c, err := New(Config{
+		Endpoints:   []Endpoint{{Address: down}, {Address: orderer.Address}},
+		DialTimeout: 100 * time.Millisecond,
+		Clock:       clk,
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, []*endpoint{c.endpoints[0], c.endpoints[1]}, c.candidates())
+	_, err = c.Submit(context.Background(), 0, systemChannelID, []byte("failover"))
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint{c.endpoints[1], c.endpoints[0]}, c.candidates(), "Failed orderer should be tried last")
+
+	clk.Advance(defaultFailureBackoff)
+	assert.Equal(t, []*endpoint{c.endpoints[0], c.endpoints[1]}, c.candidates(), "Failed orderer should be tried again after the backoff")
+}
+
+func TestUnavailable(t *testing.T) {
+	first, second := closedAddress(t), closedAddress(t)
+	c, err := New(Config{
+		Endpoints:   []Endpoint{{Address: first}, {Address: second}},
+		DialTimeout: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.Submit(context.Background(), 0, systemChannelID, nil)
+	require.IsType(t, &UnavailableError{}, err)
+	unavailable := err.(*UnavailableError)
+	require.Len(t, unavailable.Errors, 2)
+	assert.Equal(t, first, unavailable.Errors[0].Address)
+	assert.Equal(t, second, unavailable.Errors[1].Address)
+	assert.Contains(t, err.Error(), "no orderer is available: "+first)
+}
+
+func TestContextCanceled(t *testing.T) {
+	c, err := New(Config{Endpoints: []Endpoint{{Address: closedAddress(t)}}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Submit(ctx, 0, systemChannelID, nil)
+	assert.Equal(t, context.Canceled, err)
+}
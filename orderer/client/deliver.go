@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Oldest is the position of the oldest block of a channel
+func Oldest() *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}}
+}
+
+// Newest is the position of the newest block of a channel
+func Newest() *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}
+}
+
+// Specified is the position of the block with the number
+func Specified(number uint64) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: number}}}
+}
+
+// handlerError is the failure of the handler of the delivered blocks, which
+// ends the request
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+// Deliver requests the blocks of seekInfo from the channel, calling handle
+// with each block delivered, and returns once every block was delivered. If
+// the orderer fails, the request is resumed on the next orderer from the
+// block following the last one delivered, so that no block is handled twice.
+// Requests which accept no compression algorithm accept those of the client
+// configuration. It returns the error of handle if it fails, a *StatusError if an orderer
+// responds with any other status than SUCCESS and an *UnavailableError if no
+// orderer could serve the request.
+func (c *Client) Deliver(ctx context.Context, channelID string, seekInfo *ab.SeekInfo, handle func(*cb.Block) error) error {
+	seekInfo = proto.Clone(seekInfo).(*ab.SeekInfo)
+	if len(seekInfo.Compression) == 0 {
+		seekInfo.Compression = c.config.Compression
+	}
+	done := false
+	err := c.withFailover(ctx, func(conn *grpc.ClientConn) error {
+		env, err := NewEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, channelID, utils.MarshalOrPanic(seekInfo), c.config.Signer)
+		if err != nil {
+			return &handlerError{err: err}
+		}
+		err = deliverOnce(ctx, ab.NewAtomicBroadcastClient(conn), env, c.config.MaxDecompressedSize, func(block *cb.Block) error {
+			if err := handle(block); err != nil {
+				return &handlerError{err: err}
+			}
+			// Resume from the next block should the orderer fail
+			number := block.Header.Number
+			seekInfo.Start = Specified(number + 1)
+			if stop, ok := seekInfo.Stop.GetType().(*ab.SeekPosition_Specified); ok && number >= stop.Specified.Number {
+				done = true
+			}
+			return nil
+		})
+		if done {
+			// The stream may fail after the last block, before its status
+			return nil
+		}
+		return err
+	})
+	if handlerErr, ok := err.(*handlerError); ok {
+		return handlerErr.err
+	}
+	return err
+}
+
+func deliverOnce(ctx context.Context, client ab.AtomicBroadcastClient, env *cb.Envelope, maxDecompressedSize int, handle func(*cb.Block) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.Deliver(ctx)
+	if err != nil {
+		return fmt.Errorf("could not open deliver stream: %s", err)
+	}
+	if err := stream.Send(env); err != nil {
+		return fmt.Errorf("could not send seek request: %s", err)
+	}
+	stream.CloseSend()
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("could not receive block: %s", err)
+		}
+		switch t := resp.Type.(type) {
+		case *ab.DeliverResponse_Block:
+			if err := handle(t.Block); err != nil {
+				return err
+			}
+		case *ab.DeliverResponse_Blocks:
+			for _, block := range t.Blocks.Blocks {
+				if err := handle(block); err != nil {
+					return err
+				}
+			}
+		case *ab.DeliverResponse_CompressedBlocks:
+			data, err := compression.Decompress(t.CompressedBlocks.Compression, t.CompressedBlocks.Data, maxDecompressedSize)
+			if err != nil {
+				return fmt.Errorf("could not decompress blocks: %s", err)
+			}
+			blocks := &ab.Blocks{}
+			if err := proto.Unmarshal(data, blocks); err != nil {
+				return fmt.Errorf("could not decode blocks: %s", err)
+			}
+			for _, block := range blocks.Blocks {
+				if err := handle(block); err != nil {
+					return err
+				}
+			}
+		case *ab.DeliverResponse_Status:
+			if t.Status != cb.Status_SUCCESS {
+				return &StatusError{Status: t.Status}
+			}
+			return nil
+		default:
+			return fmt.Errorf("unexpected response type %T", t)
+		}
+	}
+}
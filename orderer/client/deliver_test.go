@@ -0,0 +1,203 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// flakyOrderer delivers blocks numbered from the start of the seek, in
+// batches if the seek accepts them, compressed with the first of the offered
+// algorithms the seek accepts, failing the stream after failAfter blocks if it
+// is positive
+type flakyOrderer struct {
+	failAfter int
+	offered   []ab.Compression
+
+	mutex  sync.Mutex
+	starts []uint64
+}
+
+func (fo *flakyOrderer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (fo *flakyOrderer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	env, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	seekInfo := &ab.SeekInfo{}
+	if _, err := utils.UnmarshalEnvelopeOfType(env, cb.HeaderType_DELIVER_SEEK_INFO, seekInfo); err != nil {
+		return err
+	}
+
+	start := seekInfo.Start.GetSpecified().GetNumber()
+	stop := seekInfo.Stop.GetSpecified().GetNumber()
+	fo.mutex.Lock()
+	fo.starts = append(fo.starts, start)
+	fo.mutex.Unlock()
+
+	var batch []*cb.Block
+	for number, sent := start, 0; number <= stop; number, sent = number+1, sent+1 {
+		if fo.failAfter > 0 && sent == fo.failAfter {
+			return fmt.Errorf("orderer failed")
+		}
+		block := &cb.Block{Header: &cb.BlockHeader{Number: number}}
+		if seekInfo.MaxBatchSize > 1 {
+			batch = append(batch, block)
+			if uint32(len(batch)) < seekInfo.MaxBatchSize && number < stop {
+				continue
+			}
+			err = sendBatch(srv, compression.Negotiate(seekInfo.Compression, fo.offered), batch)
+			batch = nil
+		} else {
+			err = srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Block{Block: block}})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_SUCCESS}})
+}
+
+func sendBatch(srv ab.AtomicBroadcast_DeliverServer, algorithm ab.Compression, batch []*cb.Block) error {
+	if algorithm == ab.Compression_NONE {
+		return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Blocks{Blocks: &ab.Blocks{Blocks: batch}}})
+	}
+	data, err := compression.Compress(algorithm, utils.MarshalOrPanic(&ab.Blocks{Blocks: batch}))
+	if err != nil {
+		return err
+	}
+	return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_CompressedBlocks{CompressedBlocks: &ab.CompressedBlocks{Compression: algorithm, Data: data}}})
+}
+
+func serve(t *testing.T, server ab.AtomicBroadcastServer) (string, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	ab.RegisterAtomicBroadcastServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	return lis.Addr().String(), grpcServer.Stop
+}
+
+func collect(blocks *[]uint64) func(*cb.Block) error {
+	return func(block *cb.Block) error {
+		*blocks = append(*blocks, block.Header.Number)
+		return nil
+	}
+}
+
+func TestDeliverResumes(t *testing.T) {
+	flaky := &flakyOrderer{failAfter: 2}
+	flakyAddress, stopFlaky := serve(t, flaky)
+	defer stopFlaky()
+	healthy := &flakyOrderer{}
+	healthyAddress, stopHealthy := serve(t, healthy)
+	defer stopHealthy()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: flakyAddress}, {Address: healthyAddress}}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	var blocks []uint64
+	seekInfo := &ab.SeekInfo{Start: Specified(3), Stop: Specified(7), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY}
+	err = c.Deliver(context.Background(), "foo", seekInfo, collect(&blocks))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3, 4, 5, 6, 7}, blocks, "Each block should be handled exactly once")
+	assert.Equal(t, []uint64{3}, flaky.starts)
+	assert.Equal(t, []uint64{5}, healthy.starts, "Request should resume after the last block delivered")
+	assert.Equal(t, uint64(3), seekInfo.Start.GetSpecified().Number, "Request of the caller should not be modified")
+}
+
+func TestDeliverBatches(t *testing.T) {
+	flaky := &flakyOrderer{failAfter: 3}
+	flakyAddress, stopFlaky := serve(t, flaky)
+	defer stopFlaky()
+	healthy := &flakyOrderer{}
+	healthyAddress, stopHealthy := serve(t, healthy)
+	defer stopHealthy()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: flakyAddress}, {Address: healthyAddress}}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	var blocks []uint64
+	seekInfo := &ab.SeekInfo{Start: Specified(3), Stop: Specified(9), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, MaxBatchSize: 2}
+	require.NoError(t, c.Deliver(context.Background(), "foo", seekInfo, collect(&blocks)))
+	assert.Equal(t, []uint64{3, 4, 5, 6, 7, 8, 9}, blocks, "Each block of the batches should be handled exactly once")
+	assert.Equal(t, []uint64{5}, healthy.starts, "Request should resume after the last batch delivered")
+}
+
+func TestDeliverCompressed(t *testing.T) {
+	address, stop := serve(t, &flakyOrderer{offered: []ab.Compression{ab.Compression_GZIP, ab.Compression_SNAPPY}})
+	defer stop()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: address}}, Compression: []ab.Compression{ab.Compression_SNAPPY}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	var blocks []uint64
+	seekInfo := &ab.SeekInfo{Start: Specified(3), Stop: Specified(9), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, MaxBatchSize: 2}
+	require.NoError(t, c.Deliver(context.Background(), "foo", seekInfo, collect(&blocks)))
+	assert.Equal(t, []uint64{3, 4, 5, 6, 7, 8, 9}, blocks, "Compressed batches should be decompressed")
+	assert.Empty(t, seekInfo.Compression, "Request of the caller should not be modified")
+
+	c, err = New(Config{Endpoints: []Endpoint{{Address: address}}, Compression: []ab.Compression{ab.Compression_GZIP}, MaxDecompressedSize: 8})
+	require.NoError(t, err)
+	defer c.Close()
+	err = c.Deliver(context.Background(), "foo", seekInfo, collect(new([]uint64)))
+	require.IsType(t, &UnavailableError{}, err)
+	assert.Contains(t, err.Error(), "could not decompress blocks: decompressed data exceeds 8 bytes")
+}
+
+func TestDeliverFailsAfterLastBlock(t *testing.T) {
+	// The stream fails after the last block instead of sending its status
+	flakyAddress, stopFlaky := serve(t, &flakyOrderer{failAfter: 2})
+	defer stopFlaky()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: flakyAddress}}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	var blocks []uint64
+	seekInfo := &ab.SeekInfo{Start: Specified(0), Stop: Specified(1), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY}
+	require.NoError(t, c.Deliver(context.Background(), "foo", seekInfo, collect(&blocks)))
+	assert.Equal(t, []uint64{0, 1}, blocks)
+}
+
+func TestDeliverErrors(t *testing.T) {
+	orderer := startOrderer(t)
+	defer orderer.Stop()
+	healthyAddress, stopHealthy := serve(t, &flakyOrderer{})
+	defer stopHealthy()
+
+	c, err := New(Config{Endpoints: []Endpoint{{Address: orderer.Address}, {Address: healthyAddress}}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	seekInfo := &ab.SeekInfo{Start: Oldest(), Stop: Newest(), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY}
+	err = c.Deliver(context.Background(), "missing", seekInfo, collect(new([]uint64)))
+	assert.Equal(t, &StatusError{Status: cb.Status_NOT_FOUND}, err, "Rejection should not be retried on the next orderer")
+
+	err = c.Deliver(context.Background(), systemChannelID, seekInfo, func(*cb.Block) error {
+		return fmt.Errorf("handler failed")
+	})
+	assert.EqualError(t, err, "handler failed")
+}
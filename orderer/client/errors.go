@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// ErrClosed is returned by the requests of a client which was closed
+var ErrClosed = errors.New("client is closed")
+
+// StatusError is returned when an orderer responds with a status other than
+// SUCCESS
+type StatusError struct {
+	Status cb.Status
+	// Endpoints are the orderers a TEMPORARY_REDIRECT response points to
+	Endpoints []string
+}
+
+func (e *StatusError) Error() string {
+	if len(e.Endpoints) > 0 {
+		return fmt.Sprintf("orderer responded with %s to %s", e.Status, strings.Join(e.Endpoints, ", "))
+	}
+	return fmt.Sprintf("orderer responded with %s", e.Status)
+}
+
+// EndpointError is the failure of a request to a single orderer
+type EndpointError struct {
+	Address string
+	Err     error
+}
+
+func (e *EndpointError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Address, e.Err)
+}
+
+// UnavailableError is returned when a request failed on every orderer
+type UnavailableError struct {
+	// Errors are the failures of each orderer, in the order they were tried
+	Errors []*EndpointError
+}
+
+func (e *UnavailableError) Error() string {
+	failures := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		failures[i] = err.Error()
+	}
+	return fmt.Sprintf("no orderer is available: %s", strings.Join(failures, "; "))
+}
+
+// isFinal reports whether the error would be the same on any orderer, so
+// that the request should not be retried on another. An orderer responding
+// SERVICE_UNAVAILABLE may be unable to reach its consenter, which others may,
+// and one responding TEMPORARY_REDIRECT does not order messages.
+func isFinal(err error) bool {
+	switch err := err.(type) {
+	case *StatusError:
+		return err.Status != cb.Status_SERVICE_UNAVAILABLE && err.Status != cb.Status_TEMPORARY_REDIRECT
+	case *handlerError:
+		return true
+	default:
+		return false
+	}
+}
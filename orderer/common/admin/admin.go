@@ -0,0 +1,480 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admin
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/common/config"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	"github.com/hyperledger/fabric/orderer/common/onboarding"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+)
+
+var logger = logging.MustGetLogger("orderer/common/admin")
+
+// ChainSupport provides the resources of a chain needed to report its status
+type ChainSupport interface {
+	// Reader returns the chain Reader for the chain
+	Reader() ledger.Reader
+
+	// Errored returns a channel which is closed while the consenter is unavailable
+	Errored() <-chan struct{}
+
+	// SharedConfig returns the orderer config for the chain
+	SharedConfig() config.Orderer
+
+	// ConfigEnvelope returns the envelope of the current config
+	ConfigEnvelope() *cb.ConfigEnvelope
+
+	// ProposeConfigUpdate applies a CONFIG_UPDATE to an existing config to produce a *cb.ConfigEnvelope
+	ProposeConfigUpdate(env *cb.Envelope) (*cb.ConfigEnvelope, error)
+}
+
+// Support provides the resources needed to administer the chains of the orderer
+type Support interface {
+	// GetChain gets the chain support for a given ChannelId
+	GetChain(chainID string) (ChainSupport, bool)
+
+	// SystemChannelID returns the channel ID for the system channel
+	SystemChannelID() string
+
+	// ChannelIDs returns the IDs of all chains
+	ChannelIDs() []string
+
+	// JoinChannel creates and starts a chain for a standard channel from its
+	// genesis config block
+	JoinChannel(configBlock *cb.Block) error
+
+	// LogSpec returns the logging spec currently in effect
+	LogSpec() string
+
+	// SetLogSpec replaces the logging spec currently in effect
+	SetLogSpec(spec string)
+
+	// Signer returns the signer of the config update transactions proposed
+	// to add organizations
+	Signer() crypto.LocalSigner
+}
+
+// onboardingSupport provides the chains of Support to the onboarding registry
+type onboardingSupport struct {
+	Support
+}
+
+func (obs onboardingSupport) GetChain(chainID string) (onboarding.ChainSupport, bool) {
+	return obs.Support.GetChain(chainID)
+}
+
+// MaintenanceMode records whether the orderer is in maintenance mode, it is
+// safe for concurrent use
+type MaintenanceMode struct {
+	enabled int32
+}
+
+// Enabled returns whether maintenance mode is enabled
+func (mm *MaintenanceMode) Enabled() bool {
+	return atomic.LoadInt32(&mm.enabled) == 1
+}
+
+// Set enables or disables maintenance mode
+func (mm *MaintenanceMode) Set(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&mm.enabled, value)
+}
+
+// Server implements the orderer Admin service
+type Server struct {
+	support     Support
+	maintenance *MaintenanceMode
+	shutdown    func()
+	clientRoots *x509.CertPool
+	onboarding  *onboarding.Registry
+	trail       *audit.Trail
+}
+
+// NewServer creates an Admin service which authorizes callers whose TLS
+// client certificate was issued by one of the PEM encoded clientRootCAs, or
+// whose bearer token claims the role of an administrator, and
+// which invokes shutdown when asked to stop the orderer. Failed
+// authorizations and state changing requests are recorded in the audit
+// trail, unless it is nil.
+func NewServer(support Support, maintenance *MaintenanceMode, shutdown func(), clientRootCAs [][]byte, trail *audit.Trail) (*Server, error) {
+	clientRoots := x509.NewCertPool()
+	for _, clientRootCA := range clientRootCAs {
+		if !clientRoots.AppendCertsFromPEM(clientRootCA) {
+			return nil, fmt.Errorf("no certificates found in admin client root CA")
+		}
+	}
+	if len(clientRootCAs) == 0 {
+		return nil, fmt.Errorf("at least one admin client root CA is required")
+	}
+
+	return &Server{
+		support:     support,
+		maintenance: maintenance,
+		shutdown:    shutdown,
+		clientRoots: clientRoots,
+		onboarding:  onboarding.NewRegistry(onboardingSupport{support}, support.Signer()),
+		trail:       trail,
+	}, nil
+}
+
+// authorize verifies that the caller presented a TLS client certificate
+// issued by one of the admin client root CAs, returning its common name, or
+// else a bearer token of an administrator, returning its principal. Failures
+// are recorded in the audit trail.
+func (s *Server) authorize(ctx context.Context, action string) (string, error) {
+	var remote string
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		remote = pr.Addr.String()
+	}
+
+	cert := comm.ExtractCertificateFromContext(ctx)
+	if principal, ok := interceptor.PrincipalFromContext(ctx); ok && cert == nil {
+		if !principal.Admin {
+			logger.Warningf("Rejecting admin request from %s: the token does not claim the %s role", principal, interceptor.AdminRole)
+			s.trail.Record(audit.Event{
+				Type:    audit.AccessDenied,
+				Subject: principal.String(),
+				Remote:  remote,
+				Action:  action,
+				Detail:  "the bearer token does not claim the admin role",
+			})
+			return "", grpc.Errorf(codes.PermissionDenied, "bearer token is not authorized for admin requests")
+		}
+		logger.Debugf("Authorized admin request from %s", principal)
+		return principal.String(), nil
+	}
+	if cert == nil {
+		s.trail.Record(audit.Event{
+			Type:   audit.AuthenticationFailure,
+			Remote: remote,
+			Action: action,
+			Detail: "no TLS client certificate presented",
+		})
+		return "", grpc.Errorf(codes.Unauthenticated, "admin requests require a TLS client certificate")
+	}
+
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:     s.clientRoots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		logger.Warningf("Rejecting admin request from %s: %s", cert.Subject.CommonName, err)
+		s.trail.Record(audit.Event{
+			Type:    audit.AccessDenied,
+			Subject: cert.Subject.CommonName,
+			Remote:  remote,
+			Action:  action,
+			Detail:  err.Error(),
+		})
+		return "", grpc.Errorf(codes.PermissionDenied, "client certificate is not authorized for admin requests")
+	}
+
+	logger.Debugf("Authorized admin request from %s", cert.Subject.CommonName)
+	return cert.Subject.CommonName, nil
+}
+
+// recordAction records a state changing admin request in the audit trail
+func (s *Server) recordAction(subject, action, detail string) {
+	s.trail.Record(audit.Event{
+		Type:    audit.AdminAction,
+		Subject: subject,
+		Action:  action,
+		Detail:  detail,
+	})
+}
+
+// ListChannels returns the IDs of the channels served by this orderer
+func (s *Server) ListChannels(ctx context.Context, _ *empty.Empty) (*ab.ChannelList, error) {
+	if _, err := s.authorize(ctx, "ListChannels"); err != nil {
+		return nil, err
+	}
+	return &ab.ChannelList{ChannelIds: s.support.ChannelIDs()}, nil
+}
+
+// GetChannelStatus returns the status of a single channel
+func (s *Server) GetChannelStatus(ctx context.Context, req *ab.ChannelStatusRequest) (*ab.ChannelStatus, error) {
+	if _, err := s.authorize(ctx, "GetChannelStatus"); err != nil {
+		return nil, err
+	}
+	return s.channelStatus(req.ChannelId)
+}
+
+func (s *Server) channelStatus(chainID string) (*ab.ChannelStatus, error) {
+	cs, ok := s.support.GetChain(chainID)
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "channel %s not found", chainID)
+	}
+
+	status := &ab.ChannelStatus{
+		ChannelId:          chainID,
+		Height:             cs.Reader().Height(),
+		ConsensusType:      cs.SharedConfig().ConsensusType(),
+		SystemChannel:      chainID == s.support.SystemChannelID(),
+		ConsenterAvailable: true,
+	}
+	select {
+	case <-cs.Errored():
+		status.ConsenterAvailable = false
+	default:
+	}
+	return status, nil
+}
+
+// JoinChannel starts serving a standard channel from its genesis block
+func (s *Server) JoinChannel(ctx context.Context, req *ab.JoinChannelRequest) (*ab.ChannelStatus, error) {
+	subject, err := s.authorize(ctx, "JoinChannel")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.support.JoinChannel(req.ConfigBlock); err != nil {
+		logger.Warningf("Rejecting request to join channel: %s", err)
+		s.recordAction(subject, "JoinChannel", fmt.Sprintf("rejected: %s", err))
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	chainID, err := utils.GetChainIDFromBlock(req.ConfigBlock)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "%s", err)
+	}
+	logger.Infof("Joined channel %s", chainID)
+	s.recordAction(subject, "JoinChannel", fmt.Sprintf("joined channel %s", chainID))
+	return s.channelStatus(chainID)
+}
+
+// SetMaintenanceMode enables or disables maintenance mode
+func (s *Server) SetMaintenanceMode(ctx context.Context, req *ab.MaintenanceMode) (*ab.MaintenanceMode, error) {
+	subject, err := s.authorize(ctx, "SetMaintenanceMode")
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Setting maintenance mode to %t", req.Enabled)
+	s.recordAction(subject, "SetMaintenanceMode", fmt.Sprintf("enabled=%t", req.Enabled))
+	s.maintenance.Set(req.Enabled)
+	return &ab.MaintenanceMode{Enabled: s.maintenance.Enabled()}, nil
+}
+
+// Shutdown gracefully stops the orderer, after the response has been sent
+func (s *Server) Shutdown(ctx context.Context, _ *empty.Empty) (*empty.Empty, error) {
+	subject, err := s.authorize(ctx, "Shutdown")
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Warningf("Shutdown requested through the admin service")
+	s.recordAction(subject, "Shutdown", "")
+	go s.shutdown()
+	return &empty.Empty{}, nil
+}
+
+// GetLogSpec returns the logging spec currently in effect
+func (s *Server) GetLogSpec(ctx context.Context, _ *empty.Empty) (*ab.LogSpec, error) {
+	if _, err := s.authorize(ctx, "GetLogSpec"); err != nil {
+		return nil, err
+	}
+	return &ab.LogSpec{Spec: s.support.LogSpec()}, nil
+}
+
+// SetLogSpec replaces the logging spec until the configuration is reloaded
+func (s *Server) SetLogSpec(ctx context.Context, req *ab.LogSpec) (*ab.LogSpec, error) {
+	subject, err := s.authorize(ctx, "SetLogSpec")
+	if err != nil {
+		return nil, err
+	}
+	return s.setLogSpec(subject, req.Spec)
+}
+
+// GetModuleLevel returns the level at which a module logs
+func (s *Server) GetModuleLevel(ctx context.Context, req *ab.ModuleLevel) (*ab.ModuleLevel, error) {
+	if _, err := s.authorize(ctx, "GetModuleLevel"); err != nil {
+		return nil, err
+	}
+	if req.Module == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "a module is required")
+	}
+	return &ab.ModuleLevel{Module: req.Module, Level: flogging.GetModuleLevel(req.Module)}, nil
+}
+
+// SetModuleLevel overrides the level of a module in the logging spec
+func (s *Server) SetModuleLevel(ctx context.Context, req *ab.ModuleLevel) (*ab.LogSpec, error) {
+	subject, err := s.authorize(ctx, "SetModuleLevel")
+	if err != nil {
+		return nil, err
+	}
+	if req.Module == "" || strings.ContainsAny(req.Module, ",=:") {
+		return nil, grpc.Errorf(codes.InvalidArgument, "invalid module name '%s'", req.Module)
+	}
+	return s.setLogSpec(subject, overrideModuleLevel(s.support.LogSpec(), req.Module, req.Level))
+}
+
+// StageOrganization registers the MSP material of an organization to be added
+// to a consortium, returning the proposal to add it on the system channel
+func (s *Server) StageOrganization(ctx context.Context, req *ab.StageOrganizationRequest) (*ab.ConfigUpdateProposal, error) {
+	subject, err := s.authorize(ctx, "StageOrganization")
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.onboarding.StageOrganization(req.Consortium, req.MspConfig)
+	if err != nil {
+		return nil, onboardingError(err)
+	}
+	s.recordAction(subject, "StageOrganization", fmt.Sprintf("staged %s for consortium %s in proposal %s", p.MspId, req.Consortium, p.ProposalId))
+	return p, nil
+}
+
+// ProposeChannelMembership returns the proposals to add an organization to
+// application channels
+func (s *Server) ProposeChannelMembership(ctx context.Context, req *ab.ChannelMembershipRequest) (*ab.ConfigUpdateProposals, error) {
+	subject, err := s.authorize(ctx, "ProposeChannelMembership")
+	if err != nil {
+		return nil, err
+	}
+	proposals, err := s.onboarding.ProposeChannelMembership(req.MspId, req.ChannelIds)
+	if err != nil {
+		return nil, onboardingError(err)
+	}
+	s.recordAction(subject, "ProposeChannelMembership", fmt.Sprintf("proposed %s for channels %s", req.MspId, strings.Join(req.ChannelIds, ",")))
+	return &ab.ConfigUpdateProposals{Proposals: proposals}, nil
+}
+
+// AddProposalSignatures adds signatures of the config update of a proposal
+func (s *Server) AddProposalSignatures(ctx context.Context, req *ab.ProposalSignatures) (*ab.ConfigUpdateProposal, error) {
+	subject, err := s.authorize(ctx, "AddProposalSignatures")
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.onboarding.AddSignatures(req.ProposalId, req.Signatures)
+	if err != nil {
+		return nil, onboardingError(err)
+	}
+	s.recordAction(subject, "AddProposalSignatures", fmt.Sprintf("added %d signatures to proposal %s", len(req.Signatures), req.ProposalId))
+	return p, nil
+}
+
+// GetProposal returns the signature collection progress of a proposal
+func (s *Server) GetProposal(ctx context.Context, req *ab.ProposalRequest) (*ab.ConfigUpdateProposal, error) {
+	if _, err := s.authorize(ctx, "GetProposal"); err != nil {
+		return nil, err
+	}
+	p, err := s.onboarding.Get(req.ProposalId)
+	if err != nil {
+		return nil, onboardingError(err)
+	}
+	return p, nil
+}
+
+// ListProposals returns the pending proposals
+func (s *Server) ListProposals(ctx context.Context, _ *empty.Empty) (*ab.ConfigUpdateProposals, error) {
+	if _, err := s.authorize(ctx, "ListProposals"); err != nil {
+		return nil, err
+	}
+	return &ab.ConfigUpdateProposals{Proposals: s.onboarding.List()}, nil
+}
+
+// DiscardProposal forgets a proposal
+func (s *Server) DiscardProposal(ctx context.Context, req *ab.ProposalRequest) (*empty.Empty, error) {
+	subject, err := s.authorize(ctx, "DiscardProposal")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.onboarding.Discard(req.ProposalId); err != nil {
+		return nil, onboardingError(err)
+	}
+	s.recordAction(subject, "DiscardProposal", req.ProposalId)
+	return &empty.Empty{}, nil
+}
+
+// onboardingError returns the gRPC error of a failed onboarding request
+func onboardingError(err error) error {
+	if _, ok := err.(*onboarding.NotFoundError); ok {
+		return grpc.Errorf(codes.NotFound, "%s", err)
+	}
+	return grpc.Errorf(codes.InvalidArgument, "%s", err)
+}
+
+func (s *Server) setLogSpec(subject, spec string) (*ab.LogSpec, error) {
+	if err := validateLogSpec(spec); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	logger.Infof("Setting logging spec to '%s'", spec)
+	s.recordAction(subject, "SetLogSpec", spec)
+	s.support.SetLogSpec(spec)
+	return &ab.LogSpec{Spec: s.support.LogSpec()}, nil
+}
+
+// validateLogSpec rejects the specs which flogging.InitFromSpec would only
+// partially apply
+func validateLogSpec(spec string) error {
+	for _, field := range strings.Split(spec, ":") {
+		split := strings.Split(field, "=")
+		switch {
+		case field == "" && spec == "":
+			// An empty spec restores the default level
+		case len(split) == 1:
+			if _, err := logging.LogLevel(split[0]); err != nil {
+				return fmt.Errorf("invalid default level '%s'", split[0])
+			}
+		case len(split) == 2 && split[0] != "":
+			if _, err := logging.LogLevel(split[1]); err != nil {
+				return fmt.Errorf("invalid level '%s' for modules %s", split[1], split[0])
+			}
+		default:
+			return fmt.Errorf("invalid logging spec field '%s'", field)
+		}
+	}
+	return nil
+}
+
+// overrideModuleLevel returns the spec with the module set to level, having
+// removed the module from any override already in the spec
+func overrideModuleLevel(spec, module, level string) string {
+	var fields []string
+	for _, field := range strings.Split(spec, ":") {
+		if field == "" {
+			continue
+		}
+		split := strings.Split(field, "=")
+		if len(split) != 2 {
+			fields = append(fields, field)
+			continue
+		}
+		var modules []string
+		for _, m := range strings.Split(split[0], ",") {
+			if m != module {
+				modules = append(modules, m)
+			}
+		}
+		if len(modules) > 0 {
+			fields = append(fields, strings.Join(modules, ",")+"="+split[1])
+		}
+	}
+	return strings.Join(append([]string{module + "=" + level}, fields...), ":")
+}
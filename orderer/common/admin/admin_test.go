@@ -0,0 +1,412 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/common/config"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	coreconfig "github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var _ ab.AdminServer = &Server{}
+
+const systemChainID = "system"
+
+type mockChainSupport struct {
+	*mockconfigtx.Manager
+	reader  ledger.ReadWriter
+	errored chan struct{}
+}
+
+func (mcs *mockChainSupport) Reader() ledger.Reader {
+	return mcs.reader
+}
+
+func (mcs *mockChainSupport) Errored() <-chan struct{} {
+	return mcs.errored
+}
+
+func (mcs *mockChainSupport) SharedConfig() config.Orderer {
+	return &mockconfig.Orderer{ConsensusTypeVal: "solo"}
+}
+
+type mockSupport struct {
+	chains  map[string]*mockChainSupport
+	joinErr error
+	logSpec string
+}
+
+func newMockSupport() *mockSupport {
+	return &mockSupport{chains: map[string]*mockChainSupport{systemChainID: newMockChainSupport()}}
+}
+
+func newMockChainSupport() *mockChainSupport {
+	rl, _ := ramledger.New(10).GetOrCreate("")
+	rl.Append(&cb.Block{Header: &cb.BlockHeader{}})
+
+	consortiums := cb.NewConfigGroup()
+	consortiums.Groups["consortium"] = cb.NewConfigGroup()
+	channel := cb.NewConfigGroup()
+	channel.Groups[config.ConsortiumsGroupKey] = consortiums
+	manager := &mockconfigtx.Manager{
+		ConfigEnvelopeVal:        &cb.ConfigEnvelope{Config: &cb.Config{ChannelGroup: channel}},
+		ProposeConfigUpdateError: fmt.Errorf("signatures do not satisfy policy"),
+	}
+	return &mockChainSupport{Manager: manager, reader: rl, errored: make(chan struct{})}
+}
+
+func (ms *mockSupport) GetChain(chainID string) (ChainSupport, bool) {
+	cs, ok := ms.chains[chainID]
+	return cs, ok
+}
+
+func (ms *mockSupport) SystemChannelID() string {
+	return systemChainID
+}
+
+func (ms *mockSupport) ChannelIDs() []string {
+	return []string{systemChainID}
+}
+
+func (ms *mockSupport) JoinChannel(configBlock *cb.Block) error {
+	if ms.joinErr != nil {
+		return ms.joinErr
+	}
+	chainID, err := utils.GetChainIDFromBlock(configBlock)
+	if err != nil {
+		return err
+	}
+	ms.chains[chainID] = newMockChainSupport()
+	return nil
+}
+
+func (ms *mockSupport) LogSpec() string {
+	return ms.logSpec
+}
+
+func (ms *mockSupport) SetLogSpec(spec string) {
+	ms.logSpec = spec
+}
+
+func (ms *mockSupport) Signer() crypto.LocalSigner {
+	return &mockcrypto.LocalSigner{}
+}
+
+// certificates creates a CA certificate in PEM form along with a client
+// certificate it issued
+func certificates(t *testing.T) ([]byte, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "admin-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &key.PublicKey, key)
+	assert.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	assert.NoError(t, err)
+
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, ca, &key.PublicKey, key)
+	assert.NoError(t, err)
+	client, err := x509.ParseCertificate(clientDER)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), client
+}
+
+func peerContext(cert *x509.Certificate) context.Context {
+	pr := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 7050}}
+	if cert != nil {
+		pr.AuthInfo = credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+	}
+	return peer.NewContext(context.Background(), pr)
+}
+
+func newTestServer(t *testing.T) (*Server, *mockSupport, context.Context) {
+	caPEM, client := certificates(t)
+	support := newMockSupport()
+	s, err := NewServer(support, &MaintenanceMode{}, func() {}, [][]byte{caPEM}, nil)
+	assert.NoError(t, err)
+	return s, support, peerContext(client)
+}
+
+func TestNewServer(t *testing.T) {
+	_, err := NewServer(newMockSupport(), &MaintenanceMode{}, func() {}, nil, nil)
+	assert.EqualError(t, err, "at least one admin client root CA is required")
+
+	_, err = NewServer(newMockSupport(), &MaintenanceMode{}, func() {}, [][]byte{[]byte("garbage")}, nil)
+	assert.EqualError(t, err, "no certificates found in admin client root CA")
+}
+
+func TestAuthorization(t *testing.T) {
+	s, _, _ := newTestServer(t)
+
+	_, err := s.ListChannels(peerContext(nil), &empty.Empty{})
+	assert.Equal(t, codes.Unauthenticated, grpc.Code(err))
+
+	_, untrusted := certificates(t)
+	_, err = s.ListChannels(peerContext(untrusted), &empty.Empty{})
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err))
+
+	_, err = s.Shutdown(peerContext(untrusted), &empty.Empty{})
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err))
+
+	_, err = s.ListChannels(interceptor.WithPrincipal(peerContext(nil), &interceptor.Principal{Subject: "app1", MSPID: "SampleOrg"}), &empty.Empty{})
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err), "A token which does not claim the admin role should be denied")
+
+	_, err = s.ListChannels(interceptor.WithPrincipal(peerContext(nil), &interceptor.Principal{Subject: "ops", MSPID: "SampleOrg", Admin: true}), &empty.Empty{})
+	assert.NoError(t, err, "A token which claims the admin role should be authorized")
+}
+
+func TestChannels(t *testing.T) {
+	s, support, ctx := newTestServer(t)
+
+	list, err := s.ListChannels(ctx, &empty.Empty{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{systemChainID}, list.ChannelIds)
+
+	status, err := s.GetChannelStatus(ctx, &ab.ChannelStatusRequest{ChannelId: systemChainID})
+	assert.NoError(t, err)
+	assert.Equal(t, &ab.ChannelStatus{
+		ChannelId:          systemChainID,
+		Height:             1,
+		ConsensusType:      "solo",
+		SystemChannel:      true,
+		ConsenterAvailable: true,
+	}, status)
+
+	close(support.chains[systemChainID].errored)
+	status, err = s.GetChannelStatus(ctx, &ab.ChannelStatusRequest{ChannelId: systemChainID})
+	assert.NoError(t, err)
+	assert.False(t, status.ConsenterAvailable)
+
+	_, err = s.GetChannelStatus(ctx, &ab.ChannelStatusRequest{ChannelId: "missing"})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+}
+
+func TestJoinChannel(t *testing.T) {
+	s, support, ctx := newTestServer(t)
+
+	block := &cb.Block{
+		Header: &cb.BlockHeader{},
+		Data: &cb.BlockData{Data: [][]byte{utils.MarshalOrPanic(&cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: "joined"})},
+			}),
+		})}},
+	}
+
+	support.joinErr = fmt.Errorf("Channel joined already exists")
+	_, err := s.JoinChannel(ctx, &ab.JoinChannelRequest{ConfigBlock: block})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	assert.Contains(t, err.Error(), "already exists")
+
+	support.joinErr = nil
+	status, err := s.JoinChannel(ctx, &ab.JoinChannelRequest{ConfigBlock: block})
+	assert.NoError(t, err)
+	assert.Equal(t, "joined", status.ChannelId)
+	assert.False(t, status.SystemChannel)
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	s, _, ctx := newTestServer(t)
+	assert.False(t, s.maintenance.Enabled())
+
+	mode, err := s.SetMaintenanceMode(ctx, &ab.MaintenanceMode{Enabled: true})
+	assert.NoError(t, err)
+	assert.True(t, mode.Enabled)
+	assert.True(t, s.maintenance.Enabled())
+
+	mode, err = s.SetMaintenanceMode(ctx, &ab.MaintenanceMode{Enabled: false})
+	assert.NoError(t, err)
+	assert.False(t, mode.Enabled)
+	assert.False(t, s.maintenance.Enabled())
+}
+
+func TestShutdown(t *testing.T) {
+	s, _, ctx := newTestServer(t)
+	stopped := make(chan struct{})
+	s.shutdown = func() { close(stopped) }
+
+	_, err := s.Shutdown(ctx, &empty.Empty{})
+	assert.NoError(t, err)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("Shutdown should have been invoked")
+	}
+}
+
+func TestLogSpec(t *testing.T) {
+	s, support, ctx := newTestServer(t)
+	support.logSpec = "info"
+
+	spec, err := s.GetLogSpec(ctx, &empty.Empty{})
+	assert.NoError(t, err)
+	assert.Equal(t, "info", spec.Spec)
+
+	spec, err = s.SetLogSpec(ctx, &ab.LogSpec{Spec: "orderer/kafka,orderer/solo=debug:warning"})
+	assert.NoError(t, err)
+	assert.Equal(t, "orderer/kafka,orderer/solo=debug:warning", spec.Spec)
+
+	spec, err = s.SetModuleLevel(ctx, &ab.ModuleLevel{Module: "orderer/kafka", Level: "error"})
+	assert.NoError(t, err)
+	assert.Equal(t, "orderer/kafka=error:orderer/solo=debug:warning", spec.Spec)
+	assert.Equal(t, spec.Spec, support.logSpec)
+
+	for _, bad := range []string{"loud", "orderer/kafka=loud:info", "=debug", "a=b=c"} {
+		_, err = s.SetLogSpec(ctx, &ab.LogSpec{Spec: bad})
+		assert.Equal(t, codes.InvalidArgument, grpc.Code(err), bad)
+	}
+	_, err = s.SetModuleLevel(ctx, &ab.ModuleLevel{Module: "orderer/kafka", Level: "loud"})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	_, err = s.SetModuleLevel(ctx, &ab.ModuleLevel{Module: "a:b", Level: "debug"})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	assert.Equal(t, "orderer/kafka=error:orderer/solo=debug:warning", support.logSpec, "Invalid specs should not be applied")
+
+	_, err = s.SetLogSpec(ctx, &ab.LogSpec{})
+	assert.NoError(t, err, "An empty spec should restore the default level")
+
+	level, err := s.GetModuleLevel(ctx, &ab.ModuleLevel{Module: "orderer/common/admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "orderer/common/admin", level.Module)
+	assert.NotEmpty(t, level.Level)
+	_, err = s.GetModuleLevel(ctx, &ab.ModuleLevel{})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+}
+
+func TestOnboarding(t *testing.T) {
+	s, _, ctx := newTestServer(t)
+	dir, err := coreconfig.GetDevMspDir()
+	assert.NoError(t, err)
+	mspConfig, err := msp.GetVerifyingMspConfig(dir, "NewOrgMSP")
+	assert.NoError(t, err)
+
+	_, untrusted := certificates(t)
+	_, err = s.StageOrganization(peerContext(untrusted), &ab.StageOrganizationRequest{Consortium: "consortium", MspConfig: mspConfig})
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err))
+
+	_, err = s.StageOrganization(ctx, &ab.StageOrganizationRequest{Consortium: "missing", MspConfig: mspConfig})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	_, err = s.StageOrganization(ctx, &ab.StageOrganizationRequest{Consortium: "consortium"})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+
+	staged, err := s.StageOrganization(ctx, &ab.StageOrganizationRequest{Consortium: "consortium", MspConfig: mspConfig})
+	assert.NoError(t, err)
+	assert.Equal(t, systemChainID, staged.ChannelId)
+	assert.Equal(t, "NewOrgMSP", staged.MspId)
+	assert.False(t, staged.Ready)
+	assert.Equal(t, "signatures do not satisfy policy", staged.Pending)
+
+	_, err = s.ProposeChannelMembership(ctx, &ab.ChannelMembershipRequest{MspId: "NewOrgMSP", ChannelIds: []string{systemChainID}})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err), "The system channel has no application organizations")
+
+	p, err := s.AddProposalSignatures(ctx, &ab.ProposalSignatures{
+		ProposalId: staged.ProposalId,
+		Signatures: []*cb.ConfigSignature{{
+			SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte("admin")}),
+			Signature:       []byte("signature"),
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, p.Signatures, 1)
+
+	p, err = s.GetProposal(ctx, &ab.ProposalRequest{ProposalId: staged.ProposalId})
+	assert.NoError(t, err)
+	assert.Len(t, p.Signatures, 1)
+
+	list, err := s.ListProposals(ctx, &empty.Empty{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Proposals, 1)
+
+	_, err = s.DiscardProposal(ctx, &ab.ProposalRequest{ProposalId: staged.ProposalId})
+	assert.NoError(t, err)
+	_, err = s.GetProposal(ctx, &ab.ProposalRequest{ProposalId: staged.ProposalId})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+}
+
+type auditSink struct {
+	events []audit.Event
+}
+
+func (as *auditSink) Write(entry *audit.Entry) error {
+	as.events = append(as.events, entry.Event)
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	sink := &auditSink{}
+	s, _, ctx := newTestServer(t)
+	s.trail = audit.NewTrail(nil, sink)
+	s.ListChannels(peerContext(nil), &empty.Empty{})
+	_, untrusted := certificates(t)
+	s.SetMaintenanceMode(peerContext(untrusted), &ab.MaintenanceMode{Enabled: true})
+	s.ListChannels(ctx, &empty.Empty{})
+	s.SetMaintenanceMode(ctx, &ab.MaintenanceMode{Enabled: true})
+
+	assert.Len(t, sink.events, 3, "Read only requests should not be recorded")
+	assert.Equal(t, audit.Event{
+		Type:   audit.AuthenticationFailure,
+		Remote: "127.0.0.1:7050",
+		Action: "ListChannels",
+		Detail: "no TLS client certificate presented",
+	}, sink.events[0])
+	assert.Equal(t, audit.AccessDenied, sink.events[1].Type)
+	assert.Equal(t, "SetMaintenanceMode", sink.events[1].Action)
+	assert.Equal(t, audit.Event{
+		Type:    audit.AdminAction,
+		Subject: "admin",
+		Action:  "SetMaintenanceMode",
+		Detail:  "enabled=true",
+	}, sink.events[2])
+}
@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/audit")
+
+// EventType classifies the security events recorded in the audit trail
+type EventType string
+
+const (
+	// AuthenticationFailure is recorded when a client cannot be authenticated
+	AuthenticationFailure EventType = "AuthenticationFailure"
+	// AccessDenied is recorded when an authenticated client is not permitted
+	// to perform a request
+	AccessDenied EventType = "AccessDenied"
+	// TLSHandshakeFailure is recorded when a TLS handshake with a client fails
+	TLSHandshakeFailure EventType = "TLSHandshakeFailure"
+	// AdminAction is recorded when an administrator changes the state of the
+	// orderer
+	AdminAction EventType = "AdminAction"
+)
+
+// Event describes a security event
+type Event struct {
+	Type EventType `json:"type"`
+	// Subject identifies the client, if known
+	Subject string `json:"subject,omitempty"`
+	// Remote is the network address of the client, if known
+	Remote string `json:"remote,omitempty"`
+	// Action is the request, policy or method concerned
+	Action string `json:"action,omitempty"`
+	// Detail describes the outcome
+	Detail string `json:"detail,omitempty"`
+}
+
+// Entry is an Event as recorded in the audit trail. Each entry includes the
+// hash of its predecessor, so that the removal, reordering or modification of
+// entries can be detected with Verify.
+type Entry struct {
+	Sequence uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	Event
+	PrevHash []byte `json:"prev,omitempty"`
+	Hash     []byte `json:"hash"`
+}
+
+// computeHash returns the hash of the entry, covering all of its fields but
+// the hash itself
+func (e *Entry) computeHash() []byte {
+	unhashed := *e
+	unhashed.Hash = nil
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		logger.Panicf("Failed to marshal audit entry: %s", err)
+	}
+	digest := sha256.Sum256(data)
+	return digest[:]
+}
+
+// Sink persists audit entries
+type Sink interface {
+	// Write persists the entry. Sinks may buffer entries, making them
+	// durable shortly after Write returns.
+	Write(entry *Entry) error
+}
+
+// Trail appends events to a hash chain of entries, which it writes to each
+// of its sinks. A nil Trail, of an orderer which does not audit, records
+// nothing.
+type Trail struct {
+	sinks []Sink
+	now   func() time.Time
+
+	lock     sync.Mutex
+	sequence uint64
+	head     []byte
+}
+
+// NewTrail creates a Trail which continues the chain after the entry last,
+// if any, which is typically the last entry of an existing audit log
+func NewTrail(last *Entry, sinks ...Sink) *Trail {
+	t := &Trail{sinks: sinks, now: time.Now}
+	if last != nil {
+		t.sequence = last.Sequence + 1
+		t.head = last.Hash
+	}
+	return t
+}
+
+// Record appends the event to the trail. Failures to write to a sink are
+// logged, but do not prevent the event being written to other sinks.
+func (t *Trail) Record(event Event) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	entry := &Entry{
+		Sequence: t.sequence,
+		Time:     t.now().UTC(),
+		Event:    event,
+		PrevHash: t.head,
+	}
+	entry.Hash = entry.computeHash()
+	t.sequence++
+	t.head = entry.Hash
+
+	for _, sink := range t.sinks {
+		if err := sink.Write(entry); err != nil {
+			logger.Errorf("Failed to write audit entry %d: %s", entry.Sequence, err)
+		}
+	}
+}
+
+// Close closes those of the trail's sinks which hold resources, such as a
+// FileSink, writing out the entries they have buffered
+func (t *Trail) Close() error {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var firstErr error
+	for _, sink := range t.sinks {
+		closer, ok := sink.(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Verify checks that the entries form an unbroken hash chain, returning an
+// error describing the first entry which does not
+func Verify(entries []*Entry) error {
+	for i, entry := range entries {
+		if !bytes.Equal(entry.computeHash(), entry.Hash) {
+			return fmt.Errorf("entry %d has been modified", entry.Sequence)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := entries[i-1]
+		if entry.Sequence != prev.Sequence+1 || !bytes.Equal(entry.PrevHash, prev.Hash) {
+			return fmt.Errorf("entry %d does not follow entry %d", entry.Sequence, prev.Sequence)
+		}
+	}
+	return nil
+}
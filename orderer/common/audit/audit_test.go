@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSink struct {
+	entries []*Entry
+	err     error
+}
+
+func (ms *mockSink) Write(entry *Entry) error {
+	ms.entries = append(ms.entries, entry)
+	return ms.err
+}
+
+func TestTrail(t *testing.T) {
+	sink := &mockSink{}
+	failing := &mockSink{err: fmt.Errorf("disk full")}
+	trail := NewTrail(nil, failing, sink)
+	trail.now = func() time.Time { return time.Unix(1000, 0) }
+
+	trail.Record(Event{Type: AuthenticationFailure, Remote: "10.0.0.1:1000", Detail: "no certificate"})
+	trail.Record(Event{Type: AdminAction, Subject: "admin", Action: "Shutdown"})
+
+	assert.Len(t, sink.entries, 2, "A failing sink should not prevent writes to other sinks")
+	assert.Equal(t, uint64(0), sink.entries[0].Sequence)
+	assert.Nil(t, sink.entries[0].PrevHash)
+	assert.Equal(t, sink.entries[0].Hash, sink.entries[1].PrevHash)
+	assert.NoError(t, Verify(sink.entries))
+
+	resumed := NewTrail(sink.entries[1], sink)
+	resumed.Record(Event{Type: AccessDenied})
+	assert.Equal(t, uint64(2), sink.entries[2].Sequence)
+	assert.NoError(t, Verify(sink.entries), "A resumed trail should continue the chain")
+}
+
+func TestVerify(t *testing.T) {
+	record := func() []*Entry {
+		sink := &mockSink{}
+		trail := NewTrail(nil, sink)
+		for i := 0; i < 3; i++ {
+			trail.Record(Event{Type: AccessDenied, Detail: fmt.Sprintf("request %d", i)})
+		}
+		return sink.entries
+	}
+
+	entries := record()
+	entries[1].Detail = "nothing to see here"
+	assert.EqualError(t, Verify(entries), "entry 1 has been modified")
+
+	entries = record()
+	assert.EqualError(t, Verify([]*Entry{entries[0], entries[2]}), "entry 2 does not follow entry 0")
+}
+
+func TestNilTrail(t *testing.T) {
+	var trail *Trail
+	trail.Record(Event{Type: AccessDenied}) // Auditing is disabled, should be a no-op
+	assert.NoError(t, trail.Close())
+}
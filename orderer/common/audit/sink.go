@@ -0,0 +1,185 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+// defaultSyncInterval is the sync interval of a FileSink created without one
+const defaultSyncInterval = time.Second
+
+// FileSink appends entries to a file, one JSON object per line. Entries are
+// buffered, and the buffer is written and the file synced every sync
+// interval, so that a burst of events does not cost a sync each.
+type FileSink struct {
+	lock   sync.Mutex
+	file   *os.File
+	buffer *bufio.Writer
+	dirty  bool
+	done   chan struct{}
+	exited chan struct{}
+}
+
+// NewFileSink opens the audit log at path for appending, creating it if
+// necessary, and syncs it every syncInterval. The last entry of the existing
+// log, if any, is returned so that a Trail may continue its chain.
+func NewFileSink(path string, syncInterval time.Duration) (*FileSink, *Entry, error) {
+	if syncInterval <= 0 {
+		syncInterval = defaultSyncInterval
+	}
+
+	entries, err := ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	var last *Entry
+	if len(entries) > 0 {
+		last = entries[len(entries)-1]
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	fs := &FileSink{
+		file:   file,
+		buffer: bufio.NewWriter(file),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	go fs.syncEvery(syncInterval)
+	return fs, last, nil
+}
+
+// Write appends the entry to the buffer, which is written to the file by the
+// next sync
+func (fs *FileSink) Write(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	if _, err := fs.buffer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	fs.dirty = true
+	return nil
+}
+
+// Sync writes the buffered entries to the file and syncs it
+func (fs *FileSink) Sync() error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	if !fs.dirty {
+		return nil
+	}
+	if err := fs.buffer.Flush(); err != nil {
+		return err
+	}
+	if err := fs.file.Sync(); err != nil {
+		return err
+	}
+	fs.dirty = false
+	return nil
+}
+
+func (fs *FileSink) syncEvery(interval time.Duration) {
+	defer close(fs.exited)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := fs.Sync(); err != nil {
+				logger.Errorf("Failed to sync audit log %s: %s", fs.file.Name(), err)
+			}
+		case <-fs.done:
+			return
+		}
+	}
+}
+
+// Close syncs the buffered entries and closes the file
+func (fs *FileSink) Close() error {
+	close(fs.done)
+	<-fs.exited
+	if err := fs.Sync(); err != nil {
+		fs.file.Close()
+		return err
+	}
+	return fs.file.Close()
+}
+
+// ReadFile reads the entries of the audit log at path
+func ReadFile(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read reads entries, one JSON object per line, from r
+func Read(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("malformed audit entry on line %d: %s", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// LoggerSink writes entries to an audit logger with a backend of its own, so
+// that they are kept out of the operational log and are not subject to its
+// log levels
+type LoggerSink struct {
+	logger *logging.Logger
+}
+
+// NewLoggerSink creates a LoggerSink which writes each entry, as a timestamped
+// line of JSON, to out
+func NewLoggerSink(out io.Writer) *LoggerSink {
+	backend := logging.NewBackendFormatter(
+		logging.NewLogBackend(out, "", 0),
+		logging.MustStringFormatter("%{time:2006-01-02 15:04:05.000 MST} [%{module}] %{message}"),
+	)
+	leveled := logging.AddModuleLevel(backend)
+	leveled.SetLevel(logging.NOTICE, "")
+	auditLogger := logging.MustGetLogger("audit")
+	auditLogger.SetBackend(leveled)
+	return &LoggerSink{logger: auditLogger}
+}
+
+// Write logs the entry
+func (ls *LoggerSink) Write(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	ls.logger.Notice(string(data))
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	sink, last, err := NewFileSink(path, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, last, "A new log should have no entries")
+	trail := NewTrail(last, sink)
+	trail.Record(Event{Type: TLSHandshakeFailure, Remote: "10.0.0.1:1000"})
+	trail.Record(Event{Type: AdminAction, Action: "SetMaintenanceMode"})
+	assert.NoError(t, trail.Close())
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	sink, last, err = NewFileSink(path, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), last.Sequence)
+	NewTrail(last, sink).Record(Event{Type: AccessDenied})
+	assert.NoError(t, sink.Close())
+
+	entries, err := ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "SetMaintenanceMode", entries[1].Action)
+	assert.NoError(t, Verify(entries), "The chain should be unbroken across restarts")
+}
+
+func TestFileSinkSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	sink, _, err := NewFileSink(path, time.Hour)
+	assert.NoError(t, err)
+	defer sink.Close()
+	NewTrail(nil, sink).Record(Event{Type: AccessDenied})
+
+	entries, err := ReadFile(path)
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "The entry should be buffered until the next sync")
+
+	assert.NoError(t, sink.Sync())
+	entries, err = ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileSinkSyncInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	sink, _, err := NewFileSink(path, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer sink.Close()
+	NewTrail(nil, sink).Record(Event{Type: AccessDenied})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries, err := ReadFile(path)
+		assert.NoError(t, err)
+		if len(entries) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("The entry should be synced within the interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRead(t *testing.T) {
+	_, err := Read(strings.NewReader("{}\n\ngarbage\n"))
+	assert.EqualError(t, err, "malformed audit entry on line 3: invalid character 'g' looking for beginning of value")
+}
+
+func TestLoggerSink(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, NewLoggerSink(&out).Write(&Entry{Event: Event{Type: AccessDenied}}))
+	assert.Contains(t, out.String(), `[audit] {"seq":0,`)
+	assert.Contains(t, out.String(), `"type":"AccessDenied"`)
+}
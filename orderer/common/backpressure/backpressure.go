@@ -0,0 +1,292 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package backpressure propagates the pressure of a slow ledger up through
+// the chain. While appending blocks is slow, the consenter lengthens its batch
+// timeout, cutting fewer and larger blocks, and once the ledger or the queue
+// of messages waiting on the consenter passes its threshold, the chain refuses
+// messages, which Broadcast rejects with SERVICE_UNAVAILABLE, telling clients
+// the load of the chain and when to retry, before the buffers of the orderer
+// grow without bound.
+package backpressure
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+)
+
+// MaxBatchTimeoutFactor is the most by which the batch timeout is lengthened
+// while the ledger is slow
+const MaxBatchTimeoutFactor = 4
+
+// MinRetryDelay is the shortest delay after which clients are told to retry
+// the messages refused by an overloaded chain
+const MinRetryDelay = 100 * time.Millisecond
+
+// smoothing is the weight of each append in the average append latency
+const smoothing = 0.2
+
+// monitorMetrics are the metrics updated by a monitor
+type monitorMetrics struct {
+	appendLatency metrics.Gauge
+	queueDepth    metrics.Gauge
+	overloaded    metrics.Gauge
+	refused       metrics.Counter
+}
+
+// newMonitorMetrics creates the metrics of a monitor with the provider, or in
+// the default registry if it is nil
+func newMonitorMetrics(provider metrics.Provider) *monitorMetrics {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &monitorMetrics{
+		appendLatency: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "backpressure",
+			Name:       "append_latency_seconds",
+			Help:       "The moving average of the time taken to append a block to the ledger.",
+			LabelNames: []string{"channel"},
+		}),
+		queueDepth: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "backpressure",
+			Name:       "queue_depth",
+			Help:       "The number of messages waiting to be enqueued on the consenter.",
+			LabelNames: []string{"channel"},
+		}),
+		overloaded: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "backpressure",
+			Name:       "overloaded",
+			Help:       "Whether the chain is refusing messages because it is overloaded, 1 if it is.",
+			LabelNames: []string{"channel"},
+		}),
+		refused: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "backpressure",
+			Name:       "refused_total",
+			Help:       "The number of messages refused because the chain was overloaded.",
+			LabelNames: []string{"channel"},
+		}),
+	}
+}
+
+// Config holds the thresholds of the chains, each of which is disabled if
+// it is not positive
+type Config struct {
+	// SlowAppendLatency is the append latency beyond which the batch
+	// timeout is lengthened, in proportion, up to MaxBatchTimeoutFactor
+	SlowAppendLatency time.Duration
+	// MaxAppendLatency is the append latency beyond which messages are
+	// refused
+	MaxAppendLatency time.Duration
+	// MaxQueueDepth is the number of messages waiting to be enqueued beyond
+	// which further messages are refused
+	MaxQueueDepth int
+	// MaxUrgentQueueDepth is the number of messages of urgent classes
+	// waiting to be enqueued beyond which further urgent messages are
+	// refused. Urgent messages wait in a lane of their own, bypassing the
+	// other thresholds, which this keeps small.
+	MaxUrgentQueueDepth int
+}
+
+// Monitor tracks the pressure on a chain
+type Monitor struct {
+	chainID string
+	config  Config
+	metrics *monitorMetrics
+
+	lock          sync.Mutex
+	latency       time.Duration
+	appendStarted time.Time
+	waiting       int
+	urgentWaiting int
+	overloaded    bool
+	maxQueueDepth int
+}
+
+// NewMonitor creates a Monitor for the chain, whose metrics are created with
+// the provider, or in the default registry if it is nil
+func NewMonitor(chainID string, config Config, provider metrics.Provider) *Monitor {
+	return &Monitor{chainID: chainID, config: config, metrics: newMonitorMetrics(provider)}
+}
+
+// AppendStarted records that a block is being appended, so that a ledger
+// which does not complete the append is seen as slow before it does
+func (m *Monitor) AppendStarted() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.appendStarted = time.Now()
+}
+
+// Appended records the time taken to append a block
+func (m *Monitor) Appended(d time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.appendStarted = time.Time{}
+	if m.latency == 0 {
+		m.latency = d
+	} else {
+		m.latency += time.Duration(smoothing * float64(d-m.latency))
+	}
+	m.metrics.appendLatency.With(m.chainID).Set(m.latency.Seconds())
+}
+
+// AppendLatency returns the moving average of the append latency, or the
+// time since the append in progress started if that is longer
+func (m *Monitor) AppendLatency() time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.appendLatency()
+}
+
+func (m *Monitor) appendLatency() time.Duration {
+	if !m.appendStarted.IsZero() {
+		if inProgress := time.Since(m.appendStarted); inProgress > m.latency {
+			return inProgress
+		}
+	}
+	return m.latency
+}
+
+// BatchTimeout returns the timeout lengthened in proportion to how far the
+// append latency exceeds SlowAppendLatency
+func (m *Monitor) BatchTimeout(timeout time.Duration) time.Duration {
+	if m.config.SlowAppendLatency <= 0 {
+		return timeout
+	}
+	factor := float64(m.AppendLatency()) / float64(m.config.SlowAppendLatency)
+	if factor <= 1 {
+		return timeout
+	}
+	if factor > MaxBatchTimeoutFactor {
+		factor = MaxBatchTimeoutFactor
+	}
+	return time.Duration(factor * float64(timeout))
+}
+
+// SetMaxQueueDepth overrides the MaxQueueDepth of the config of the monitor
+// with the depth configured for the chain, or restores it if depth is 0
+func (m *Monitor) SetMaxQueueDepth(depth int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.maxQueueDepth = depth
+}
+
+// effectiveConfig returns the config of the monitor with the MaxQueueDepth
+// configured for the chain, if any. The lock must be held.
+func (m *Monitor) effectiveConfig() Config {
+	config := m.config
+	if m.maxQueueDepth > 0 {
+		config.MaxQueueDepth = m.maxQueueDepth
+	}
+	return config
+}
+
+// Enter admits a message to wait to be enqueued, returning false if the chain
+// is overloaded, in which case the message is refused and Exit must not be
+// called
+func (m *Monitor) Enter() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	config := m.effectiveConfig()
+	m.setOverloaded((config.MaxAppendLatency > 0 && m.appendLatency() > config.MaxAppendLatency) ||
+		(config.MaxQueueDepth > 0 && m.waiting >= config.MaxQueueDepth))
+	if m.overloaded {
+		m.metrics.refused.With(m.chainID).Add(1)
+		return false
+	}
+	m.waiting++
+	m.metrics.queueDepth.With(m.chainID).Set(float64(m.waiting))
+	return true
+}
+
+// EnterUrgent admits a message of an urgent priority class to wait to be
+// enqueued even while the chain is overloaded, so that control-plane
+// transactions are not refused with the load they are meant to relieve. It
+// returns false if MaxUrgentQueueDepth urgent messages are already waiting,
+// in which case the message is refused and ExitUrgent must not be called.
+func (m *Monitor) EnterUrgent() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.config.MaxUrgentQueueDepth > 0 && m.urgentWaiting >= m.config.MaxUrgentQueueDepth {
+		m.metrics.refused.With(m.chainID).Add(1)
+		return false
+	}
+	m.urgentWaiting++
+	m.waiting++
+	m.metrics.queueDepth.With(m.chainID).Set(float64(m.waiting))
+	return true
+}
+
+// Exit records that a message admitted by Enter is no longer waiting
+func (m *Monitor) Exit() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.waiting--
+	m.metrics.queueDepth.With(m.chainID).Set(float64(m.waiting))
+}
+
+// ExitUrgent records that a message admitted by EnterUrgent is no longer
+// waiting
+func (m *Monitor) ExitUrgent() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.urgentWaiting--
+	m.waiting--
+	m.metrics.queueDepth.With(m.chainID).Set(float64(m.waiting))
+}
+
+func (m *Monitor) setOverloaded(isOverloaded bool) {
+	if isOverloaded == m.overloaded {
+		return
+	}
+	m.overloaded = isOverloaded
+	if isOverloaded {
+		m.metrics.overloaded.With(m.chainID).Set(1)
+	} else {
+		m.metrics.overloaded.With(m.chainID).Set(0)
+	}
+}
+
+// Status describes the load of a chain, so that the clients of a chain which
+// refuses their messages may back off rather than retry at once
+type Status struct {
+	// Overloaded is whether the chain refuses messages
+	Overloaded bool
+	// QueueDepth is the number of messages waiting to be enqueued
+	QueueDepth int
+	// AppendLatency is as returned by AppendLatency
+	AppendLatency time.Duration
+	// Config holds the thresholds beyond which the chain refuses messages
+	Config Config
+}
+
+// Status returns the load of the chain
+func (m *Monitor) Status() Status {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return Status{
+		Overloaded:    m.overloaded,
+		QueueDepth:    m.waiting,
+		AppendLatency: m.appendLatency(),
+		Config:        m.effectiveConfig(),
+	}
+}
+
+// RetryAfter estimates the delay after which a refused message may be sent
+// again: the time the ledger takes to append a block, over which a batch of
+// the waiting messages is drained, and by the end of which an append in
+// progress will likely have completed. It is at least MinRetryDelay.
+func (s Status) RetryAfter() time.Duration {
+	if s.AppendLatency < MinRetryDelay {
+		return MinRetryDelay
+	}
+	return s.AppendLatency
+}
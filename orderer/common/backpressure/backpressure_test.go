@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package backpressure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendLatency(t *testing.T) {
+	m := NewMonitor("foo", Config{}, nil)
+	m.Appended(100 * time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, m.AppendLatency(), "The first append should set the average")
+	m.Appended(200 * time.Millisecond)
+	assert.Equal(t, 120*time.Millisecond, m.AppendLatency())
+
+	m.AppendStarted()
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, m.AppendLatency() >= 150*time.Millisecond, "An append in progress longer than the average should count")
+	m.Appended(150 * time.Millisecond)
+	assert.Equal(t, 126*time.Millisecond, m.AppendLatency())
+}
+
+func TestBatchTimeout(t *testing.T) {
+	m := NewMonitor("foo", Config{}, nil)
+	m.Appended(time.Second)
+	assert.Equal(t, time.Second, m.BatchTimeout(time.Second), "The timeout should not change when disabled")
+
+	m = NewMonitor("foo", Config{SlowAppendLatency: 100 * time.Millisecond}, nil)
+	m.Appended(50 * time.Millisecond)
+	assert.Equal(t, time.Second, m.BatchTimeout(time.Second), "The timeout should not change while the ledger is fast")
+
+	m = NewMonitor("foo", Config{SlowAppendLatency: 100 * time.Millisecond}, nil)
+	m.Appended(200 * time.Millisecond)
+	assert.Equal(t, 2*time.Second, m.BatchTimeout(time.Second))
+
+	m = NewMonitor("foo", Config{SlowAppendLatency: 100 * time.Millisecond}, nil)
+	m.Appended(time.Second)
+	assert.Equal(t, MaxBatchTimeoutFactor*time.Second, m.BatchTimeout(time.Second))
+}
+
+func TestMaxQueueDepth(t *testing.T) {
+	m := NewMonitor("foo", Config{MaxQueueDepth: 2}, nil)
+	assert.True(t, m.Enter())
+	assert.True(t, m.Enter())
+	assert.False(t, m.Enter(), "Messages beyond the queue depth should be refused")
+	m.Exit()
+	assert.True(t, m.Enter())
+}
+
+func TestSetMaxQueueDepth(t *testing.T) {
+	m := NewMonitor("foo", Config{MaxQueueDepth: 1}, nil)
+	m.SetMaxQueueDepth(2)
+	assert.True(t, m.Enter())
+	assert.True(t, m.Enter(), "The queue depth set should replace that of the config")
+	assert.False(t, m.Enter())
+	assert.Equal(t, 2, m.Status().Config.MaxQueueDepth)
+
+	m.SetMaxQueueDepth(0)
+	m.Exit()
+	assert.False(t, m.Enter(), "The queue depth of the config should be restored")
+	assert.Equal(t, 1, m.Status().Config.MaxQueueDepth)
+}
+
+func TestEnterUrgent(t *testing.T) {
+	m := NewMonitor("foo", Config{MaxQueueDepth: 1}, nil)
+	assert.True(t, m.Enter())
+	assert.True(t, m.EnterUrgent(), "Urgent messages should bypass the queue depth")
+	assert.False(t, m.Enter(), "Urgent messages should count toward the queue depth")
+	m.Exit()
+	m.ExitUrgent()
+	assert.True(t, m.Enter())
+}
+
+func TestMaxUrgentQueueDepth(t *testing.T) {
+	m := NewMonitor("foo", Config{MaxQueueDepth: 1, MaxUrgentQueueDepth: 2}, nil)
+	assert.True(t, m.Enter())
+	assert.True(t, m.EnterUrgent())
+	assert.True(t, m.EnterUrgent())
+	assert.False(t, m.EnterUrgent(), "Urgent messages beyond the depth of the priority lane should be refused")
+	m.Exit()
+	assert.False(t, m.EnterUrgent(), "Other messages leaving should not make room in the priority lane")
+	m.ExitUrgent()
+	assert.True(t, m.EnterUrgent())
+}
+
+func TestMaxAppendLatency(t *testing.T) {
+	m := NewMonitor("foo", Config{MaxAppendLatency: 100 * time.Millisecond}, nil)
+	m.Appended(50 * time.Millisecond)
+	assert.True(t, m.Enter())
+	m.Exit()
+
+	m.AppendStarted()
+	time.Sleep(150 * time.Millisecond)
+	assert.False(t, m.Enter(), "Messages should be refused while an append is stuck")
+
+	m.Appended(500 * time.Millisecond)
+	assert.False(t, m.Enter(), "Messages should be refused while the average is too high")
+
+	for i := 0; i < 10; i++ {
+		m.Appended(10 * time.Millisecond)
+	}
+	assert.True(t, m.Enter(), "Messages should be accepted once the ledger recovers")
+}
+
+func TestDisabled(t *testing.T) {
+	m := NewMonitor("foo", Config{}, nil)
+	m.Appended(time.Hour)
+	for i := 0; i < 100; i++ {
+		assert.True(t, m.Enter())
+	}
+}
+
+func TestStatus(t *testing.T) {
+	config := Config{MaxQueueDepth: 1}
+	m := NewMonitor("foo", config, nil)
+	assert.Equal(t, Status{Config: config}, m.Status())
+	assert.Equal(t, MinRetryDelay, m.Status().RetryAfter(), "The retry delay should not be shorter than the minimum")
+
+	m.Appended(time.Second)
+	assert.True(t, m.Enter())
+	assert.False(t, m.Enter())
+	status := m.Status()
+	assert.Equal(t, Status{Overloaded: true, QueueDepth: 1, AppendLatency: time.Second, Config: config}, status)
+	assert.Equal(t, time.Second, status.RetryAfter(), "Clients should retry after the ledger appends a block")
+}
@@ -17,15 +17,80 @@ limitations under the License.
 package blockcutter
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric/common/config"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/latency"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 
 	"github.com/op/go-logging"
 )
 
 var logger = logging.MustGetLogger("orderer/common/blockcutter")
 
+// cutterMetrics are the metrics updated by a receiver
+type cutterMetrics struct {
+	cutBatches          metrics.Counter
+	batchFillRatio      metrics.Histogram
+	batchBytesFillRatio metrics.Histogram
+}
+
+// newCutterMetrics creates the metrics of a receiver with the provider, or in
+// the default registry if it is nil
+func newCutterMetrics(provider metrics.Provider) *cutterMetrics {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &cutterMetrics{
+		cutBatches: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "blockcutter",
+			Name:       "batches_cut_total",
+			Help:       "The number of batches cut, by the reason they were cut.",
+			LabelNames: []string{"channel", "reason"},
+		}),
+		batchFillRatio: provider.NewHistogram(metrics.HistogramOpts{
+			Opts: metrics.Opts{
+				Namespace:  "orderer",
+				Subsystem:  "blockcutter",
+				Name:       "batch_fill_ratio",
+				Help:       "The number of messages in each batch cut, as a fraction of BatchSize.MaxMessageCount.",
+				LabelNames: []string{"channel"},
+			},
+			Buckets: fillBuckets,
+		}),
+		batchBytesFillRatio: provider.NewHistogram(metrics.HistogramOpts{
+			Opts: metrics.Opts{
+				Namespace:  "orderer",
+				Subsystem:  "blockcutter",
+				Name:       "batch_bytes_fill_ratio",
+				Help:       "The size of each batch cut, as a fraction of BatchSize.PreferredMaxBytes.",
+				LabelNames: []string{"channel"},
+			},
+			Buckets: fillBuckets,
+		}),
+	}
+}
+
+// fillBuckets divide fill ratios into tenths, isolated messages larger than
+// the preferred size fall beyond the last
+var fillBuckets = []float64{.1, .2, .3, .4, .5, .6, .7, .8, .9, 1}
+
+// The reasons for which batches are cut
+const (
+	cutIsolated         = "isolated"
+	cutPreferredMaxSize = "preferred_max_bytes"
+	cutMaxMessageCount  = "max_message_count"
+	cutTimeout          = "timeout"
+	cutPriority         = "priority"
+)
+
 // Receiver defines a sink for the ordered broadcast messages
 type Receiver interface {
 	// Ordered should be invoked sequentially as messages are ordered
@@ -45,27 +110,44 @@ type Receiver interface {
 	//   - The current message needs to be isolated (as determined during filtering).
 	//   - The current message will cause the pending batch size in bytes to exceed BatchSize.PreferredMaxBytes.
 	//   - After adding the current message to the pending batch, the message count has reached BatchSize.MaxMessageCount.
+	//   - The current message is of an urgent priority class, in which case it is placed ahead of the pending messages.
 	//
 	// In any case, `pending` is set to true if there are still messages pending in the receiver after cutting the block.
-	Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, committers [][]filter.Committer, validTx bool, pending bool)
+	Ordered(msg *filter.Message) (messageBatches [][]*cb.Envelope, committers [][]filter.Committer, validTx bool, pending bool)
 
 	// Cut returns the current batch and starts a new one
 	Cut() ([]*cb.Envelope, []filter.Committer)
 }
 
 type receiver struct {
+	chainID               string
 	sharedConfigManager   config.Orderer
 	filters               *filter.RuleSet
-	pendingBatch          []*cb.Envelope
+	pendingBatch          []*filter.Message
 	pendingBatchSizeBytes uint32
 	pendingCommitters     []filter.Committer
+	// pendingLow is the number of LOW priority messages at the end of the
+	// pending batch
+	pendingLow int
+	memory     *memory.Accountant
+	tracer     *tracing.Tracer
+	latency    *latency.Tracker
+	metrics    *cutterMetrics
 }
 
-// NewReceiverImpl creates a Receiver implementation based on the given configtxorderer manager and filters
-func NewReceiverImpl(sharedConfigManager config.Orderer, filters *filter.RuleSet) Receiver {
+// NewReceiverImpl creates a Receiver implementation for the chain based on the given configtxorderer manager and filters,
+// which accounts for the pending batch with the memory accountant, traces the batches cut with the tracer, and stops
+// following rejected messages with the latency tracker, if set.
+// The batches cut are counted with the metrics created by provider, or in the default registry if it is nil.
+func NewReceiverImpl(chainID string, sharedConfigManager config.Orderer, filters *filter.RuleSet, memory *memory.Accountant, tracer *tracing.Tracer, latency *latency.Tracker, provider metrics.Provider) Receiver {
 	return &receiver{
+		chainID:             chainID,
 		sharedConfigManager: sharedConfigManager,
 		filters:             filters,
+		memory:              memory,
+		tracer:              tracer,
+		latency:             latency,
+		metrics:             newCutterMetrics(provider),
 	}
 }
 
@@ -86,20 +168,24 @@ func NewReceiverImpl(sharedConfigManager config.Orderer, filters *filter.RuleSet
 //   - The current message needs to be isolated (as determined during filtering).
 //   - The current message will cause the pending batch size in bytes to exceed BatchSize.PreferredMaxBytes.
 //   - After adding the current message to the pending batch, the message count has reached BatchSize.MaxMessageCount.
+//   - The current message is of an urgent priority class, in which case it is placed ahead of the pending messages.
+//
+// Within the pending batch, messages of the LOW priority class are kept behind the others.
 //
 // In any case, `pending` is set to true if there are still messages pending in the receiver after cutting the block.
-func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, committerBatches [][]filter.Committer, validTx bool, pending bool) {
+func (r *receiver) Ordered(msg *filter.Message) (messageBatches [][]*cb.Envelope, committerBatches [][]filter.Committer, validTx bool, pending bool) {
 	// The messages must be filtered a second time in case configuration has changed since the message was received
-	committer, err := r.filters.Apply(msg)
+	committer, err := r.filters.ApplyOrdering(msg)
 	if err != nil {
 		logger.Debugf("Rejecting message: %s", err)
+		r.latency.Forget(string(msg.Hash()))
 		return // We don't bother to determine `pending` here as it's not processed in error case
 	}
 
 	// message is valid
 	validTx = true
 
-	messageSizeBytes := messageSizeBytes(msg)
+	messageSizeBytes := msg.Size()
 
 	if committer.Isolated() || messageSizeBytes > r.sharedConfigManager.BatchSize().PreferredMaxBytes {
 
@@ -111,13 +197,14 @@ func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, c
 
 		// cut pending batch, if it has any messages
 		if len(r.pendingBatch) > 0 {
-			messageBatch, committerBatch := r.Cut()
+			messageBatch, committerBatch := r.cut(cutIsolated)
 			messageBatches = append(messageBatches, messageBatch)
 			committerBatches = append(committerBatches, committerBatch)
 		}
 
 		// create new batch with single message
-		messageBatches = append(messageBatches, []*cb.Envelope{msg})
+		r.record([]*filter.Message{msg}, cutIsolated)
+		messageBatches = append(messageBatches, []*cb.Envelope{msg.Envelope})
 		committerBatches = append(committerBatches, []filter.Committer{committer})
 
 		return
@@ -128,20 +215,40 @@ func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, c
 	if messageWillOverflowBatchSizeBytes {
 		logger.Debugf("The current message, with %v bytes, will overflow the pending batch of %v bytes.", messageSizeBytes, r.pendingBatchSizeBytes)
 		logger.Debugf("Pending batch would overflow if current message is added, cutting batch now.")
-		messageBatch, committerBatch := r.Cut()
+		messageBatch, committerBatch := r.cut(cutPreferredMaxSize)
 		messageBatches = append(messageBatches, messageBatch)
 		committerBatches = append(committerBatches, committerBatch)
 	}
 
 	logger.Debugf("Enqueuing message into batch")
-	r.pendingBatch = append(r.pendingBatch, msg)
+	// Config messages are isolated by the filters, so only the class a
+	// message requests is honored here
+	class := priority.Requested(msg)
+	switch {
+	case priority.Urgent(class):
+		r.insert(0, msg, committer)
+	case class == ab.PriorityClass_LOW:
+		r.insert(len(r.pendingBatch), msg, committer)
+		r.pendingLow++
+	default:
+		r.insert(len(r.pendingBatch)-r.pendingLow, msg, committer)
+	}
 	r.pendingBatchSizeBytes += messageSizeBytes
-	r.pendingCommitters = append(r.pendingCommitters, committer)
+	r.memory.Hold(memory.HolderBlockCutter, int64(messageSizeBytes))
 	pending = true
 
+	if priority.Urgent(class) {
+		logger.Debugf("Message of priority class %s is urgent, cutting batch", class)
+		messageBatch, committerBatch := r.cut(cutPriority)
+		messageBatches = append(messageBatches, messageBatch)
+		committerBatches = append(committerBatches, committerBatch)
+		pending = false
+		return
+	}
+
 	if uint32(len(r.pendingBatch)) >= r.sharedConfigManager.BatchSize().MaxMessageCount {
 		logger.Debugf("Batch size met, cutting batch")
-		messageBatch, committerBatch := r.Cut()
+		messageBatch, committerBatch := r.cut(cutMaxMessageCount)
 		messageBatches = append(messageBatches, messageBatch)
 		committerBatches = append(committerBatches, committerBatch)
 		pending = false
@@ -152,14 +259,50 @@ func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, c
 
 // Cut returns the current batch and starts a new one
 func (r *receiver) Cut() ([]*cb.Envelope, []filter.Committer) {
-	batch := r.pendingBatch
+	return r.cut(cutTimeout)
+}
+
+// insert inserts the message at index i of the pending batch
+func (r *receiver) insert(i int, msg *filter.Message, committer filter.Committer) {
+	r.pendingBatch = append(r.pendingBatch, nil)
+	copy(r.pendingBatch[i+1:], r.pendingBatch[i:])
+	r.pendingBatch[i] = msg
+	r.pendingCommitters = append(r.pendingCommitters, nil)
+	copy(r.pendingCommitters[i+1:], r.pendingCommitters[i:])
+	r.pendingCommitters[i] = committer
+}
+
+func (r *receiver) cut(reason string) ([]*cb.Envelope, []filter.Committer) {
+	r.record(r.pendingBatch, reason)
+	batch := make([]*cb.Envelope, len(r.pendingBatch))
+	for i, msg := range r.pendingBatch {
+		batch[i] = msg.Envelope
+	}
 	r.pendingBatch = nil
 	committers := r.pendingCommitters
 	r.pendingCommitters = nil
+	r.pendingLow = 0
+	r.memory.Release(memory.HolderBlockCutter, int64(r.pendingBatchSizeBytes))
 	r.pendingBatchSizeBytes = 0
 	return batch, committers
 }
 
-func messageSizeBytes(message *cb.Envelope) uint32 {
-	return uint32(len(message.Payload) + len(message.Signature))
+// record counts a non-empty batch cut for the reason
+func (r *receiver) record(batch []*filter.Message, reason string) {
+	if len(batch) == 0 {
+		return
+	}
+	r.metrics.cutBatches.With(r.chainID, reason).Add(1)
+	r.tracer.Batched(batch, time.Now())
+	batchSize := r.sharedConfigManager.BatchSize()
+	if batchSize.MaxMessageCount > 0 {
+		r.metrics.batchFillRatio.With(r.chainID).Observe(float64(len(batch)) / float64(batchSize.MaxMessageCount))
+	}
+	if batchSize.PreferredMaxBytes > 0 {
+		var size uint32
+		for _, msg := range batch {
+			size += msg.Size()
+		}
+		r.metrics.batchBytesFillRatio.With(r.chainID).Observe(float64(size) / float64(batchSize.PreferredMaxBytes))
+	}
 }
@@ -19,11 +19,16 @@ package blockcutter
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/latency"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
 )
@@ -40,8 +45,8 @@ func (ic isolatedCommitter) Commit() {}
 
 type mockIsolatedFilter struct{}
 
-func (mif *mockIsolatedFilter) Apply(msg *cb.Envelope) (filter.Action, filter.Committer) {
-	if bytes.Equal(msg.Payload, isolatedTx.Payload) {
+func (mif *mockIsolatedFilter) Apply(msg *filter.Message) (filter.Action, filter.Committer) {
+	if bytes.Equal(msg.Envelope.Payload, isolatedTx.Envelope.Payload) {
 		return filter.Accept, isolatedCommitter{}
 	}
 	return filter.Forward, nil
@@ -49,8 +54,8 @@ func (mif *mockIsolatedFilter) Apply(msg *cb.Envelope) (filter.Action, filter.Co
 
 type mockRejectFilter struct{}
 
-func (mrf mockRejectFilter) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
-	if bytes.Equal(message.Payload, badTx.Payload) {
+func (mrf mockRejectFilter) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	if bytes.Equal(message.Envelope.Payload, badTx.Envelope.Payload) {
 		return filter.Reject, nil
 	}
 	return filter.Forward, nil
@@ -58,8 +63,8 @@ func (mrf mockRejectFilter) Apply(message *cb.Envelope) (filter.Action, filter.C
 
 type mockAcceptFilter struct{}
 
-func (mrf mockAcceptFilter) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
-	if bytes.Equal(message.Payload, goodTx.Payload) {
+func (mrf mockAcceptFilter) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	if bytes.Equal(message.Envelope.Payload, goodTx.Envelope.Payload) {
 		return filter.Accept, filter.NoopCommitter
 	}
 	return filter.Forward, nil
@@ -73,18 +78,18 @@ func getFilters() *filter.RuleSet {
 	})
 }
 
-var badTx = &cb.Envelope{Payload: []byte("BAD")}
-var goodTx = &cb.Envelope{Payload: []byte("GOOD")}
-var goodTxLarge = &cb.Envelope{Payload: []byte("GOOD"), Signature: make([]byte, 1000)}
-var isolatedTx = &cb.Envelope{Payload: []byte("ISOLATED")}
-var unmatchedTx = &cb.Envelope{Payload: []byte("UNMATCHED")}
+var badTx = &filter.Message{Envelope: &cb.Envelope{Payload: []byte("BAD")}}
+var goodTx = &filter.Message{Envelope: &cb.Envelope{Payload: []byte("GOOD")}}
+var goodTxLarge = &filter.Message{Envelope: &cb.Envelope{Payload: []byte("GOOD"), Signature: make([]byte, 1000)}}
+var isolatedTx = &filter.Message{Envelope: &cb.Envelope{Payload: []byte("ISOLATED")}}
+var unmatchedTx = &filter.Message{Envelope: &cb.Envelope{Payload: []byte("UNMATCHED")}}
 
 func TestNormalBatch(t *testing.T) {
 	filters := getFilters()
 	maxMessageCount := uint32(2)
 	absoluteMaxBytes := uint32(1000)
 	preferredMaxBytes := uint32(100)
-	r := NewReceiverImpl(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters, nil, nil, nil, nil)
 
 	batches, committers, ok, pending := r.Ordered(goodTx)
 
@@ -101,12 +106,40 @@ func TestNormalBatch(t *testing.T) {
 	assert.False(t, pending, "Should not have pending messages")
 }
 
+func TestRejectedForgotten(t *testing.T) {
+	registry := metrics.NewRegistry()
+	tracker := latency.NewTracker(latency.DefaultExpiry, latency.DefaultMaxPending, nil, registry)
+	r := NewReceiverImpl("rejectchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 10, AbsoluteMaxBytes: 1000, PreferredMaxBytes: 100}}, getFilters(), nil, nil, tracker, nil)
+
+	now := time.Now()
+	tracker.Filtered("rejectchain", string(badTx.Hash()), now, now)
+	_, _, ok, _ := r.Ordered(badTx)
+	assert.False(t, ok, "Should have rejected bad message")
+
+	block := cb.NewBlock(1, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(badTx.Envelope)}
+	tracker.Appended(block, now, now)
+	assert.Equal(t, uint64(0), series(registry, "orderer_transaction_latency_seconds", "rejectchain").Count, "The rejected message should no longer be followed")
+}
+
+func TestPendingBytes(t *testing.T) {
+	accountant := memory.NewAccountant(0, nil)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 10, AbsoluteMaxBytes: 1000, PreferredMaxBytes: 100}}, getFilters(), accountant, nil, nil, nil)
+
+	r.Ordered(goodTx)
+	r.Ordered(goodTx)
+	assert.Equal(t, 2*int64(goodTx.Size()), accountant.Held(), "The pending batch should be held")
+
+	r.Cut()
+	assert.Equal(t, int64(0), accountant.Held(), "The batch should be released once cut")
+}
+
 func TestBadMessageInBatch(t *testing.T) {
 	filters := getFilters()
 	maxMessageCount := uint32(2)
 	absoluteMaxBytes := uint32(1000)
 	preferredMaxBytes := uint32(100)
-	r := NewReceiverImpl(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters, nil, nil, nil, nil)
 
 	batches, committers, ok, _ := r.Ordered(badTx)
 
@@ -133,7 +166,7 @@ func TestUnmatchedMessageInBatch(t *testing.T) {
 	maxMessageCount := uint32(2)
 	absoluteMaxBytes := uint32(1000)
 	preferredMaxBytes := uint32(100)
-	r := NewReceiverImpl(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters, nil, nil, nil, nil)
 
 	batches, committers, ok, _ := r.Ordered(unmatchedTx)
 
@@ -160,7 +193,7 @@ func TestIsolatedEmptyBatch(t *testing.T) {
 	maxMessageCount := uint32(2)
 	absoluteMaxBytes := uint32(1000)
 	preferredMaxBytes := uint32(100)
-	r := NewReceiverImpl(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters, nil, nil, nil, nil)
 
 	batches, committers, ok, pending := r.Ordered(isolatedTx)
 
@@ -170,7 +203,7 @@ func TestIsolatedEmptyBatch(t *testing.T) {
 	assert.Len(t, committers[0], 1, "Should have had one isolatedTx in the committer batch, got %d", len(committers[0]))
 	assert.True(t, ok, "Should have enqueued isolated message into batch")
 	assert.False(t, pending, "Should not have pending messages")
-	assert.Equal(t, isolatedTx.Payload, batches[0][0].Payload, "Should have had the isolated tx in the first batch")
+	assert.Equal(t, isolatedTx.Envelope.Payload, batches[0][0].Payload, "Should have had the isolated tx in the first batch")
 }
 
 func TestIsolatedPartialBatch(t *testing.T) {
@@ -178,7 +211,7 @@ func TestIsolatedPartialBatch(t *testing.T) {
 	maxMessageCount := uint32(2)
 	absoluteMaxBytes := uint32(1000)
 	preferredMaxBytes := uint32(100)
-	r := NewReceiverImpl(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: absoluteMaxBytes, PreferredMaxBytes: preferredMaxBytes}}, filters, nil, nil, nil, nil)
 
 	batches, committers, ok, pending := r.Ordered(goodTx)
 
@@ -197,14 +230,14 @@ func TestIsolatedPartialBatch(t *testing.T) {
 	assert.Len(t, committers[1], 1, "Should have had 1 committer in the second committer batch, got %d", len(committers[1]))
 	assert.True(t, ok, "Should have enqueued isolated message into batch")
 	assert.False(t, pending, "Should not have pending messages")
-	assert.Equal(t, goodTx.Payload, batches[0][0].Payload, "Should have had the good tx in the first batch")
-	assert.Equal(t, isolatedTx.Payload, batches[1][0].Payload, "Should have had the isolated tx in the second batch")
+	assert.Equal(t, goodTx.Envelope.Payload, batches[0][0].Payload, "Should have had the good tx in the first batch")
+	assert.Equal(t, isolatedTx.Envelope.Payload, batches[1][0].Payload, "Should have had the isolated tx in the second batch")
 }
 
 func TestBatchSizePreferredMaxBytesOverflow(t *testing.T) {
 	filters := getFilters()
 
-	goodTxBytes := messageSizeBytes(goodTx)
+	goodTxBytes := goodTx.Size()
 
 	// set preferred max bytes such that 10 goodTx will not fit
 	preferredMaxBytes := goodTxBytes*10 - 1
@@ -212,7 +245,7 @@ func TestBatchSizePreferredMaxBytesOverflow(t *testing.T) {
 	// set message count > 9
 	maxMessageCount := uint32(20)
 
-	r := NewReceiverImpl(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: preferredMaxBytes * 2, PreferredMaxBytes: preferredMaxBytes}}, filters)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: preferredMaxBytes * 2, PreferredMaxBytes: preferredMaxBytes}}, filters, nil, nil, nil, nil)
 
 	// enqueue 9 messages
 	for i := 0; i < 9; i++ {
@@ -246,7 +279,7 @@ func TestBatchSizePreferredMaxBytesOverflow(t *testing.T) {
 func TestBatchSizePreferredMaxBytesOverflowNoPending(t *testing.T) {
 	filters := getFilters()
 
-	goodTxLargeBytes := messageSizeBytes(goodTxLarge)
+	goodTxLargeBytes := goodTxLarge.Size()
 
 	// set preferred max bytes such that 1 goodTxLarge will not fit
 	preferredMaxBytes := goodTxLargeBytes - 1
@@ -254,7 +287,7 @@ func TestBatchSizePreferredMaxBytesOverflowNoPending(t *testing.T) {
 	// set message count > 1
 	maxMessageCount := uint32(20)
 
-	r := NewReceiverImpl(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: preferredMaxBytes * 3, PreferredMaxBytes: preferredMaxBytes}}, filters)
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: maxMessageCount, AbsoluteMaxBytes: preferredMaxBytes * 3, PreferredMaxBytes: preferredMaxBytes}}, filters, nil, nil, nil, nil)
 
 	// submit large message
 	batches, committers, ok, pending := r.Ordered(goodTxLarge)
@@ -266,3 +299,118 @@ func TestBatchSizePreferredMaxBytesOverflowNoPending(t *testing.T) {
 	assert.True(t, ok, "Should have enqueued message into batch")
 	assert.False(t, pending, "Should not have pending messages")
 }
+
+// cutCount returns the number of batches cut for the reason so far
+func series(registry *metrics.Registry, name string, labelValues ...string) metrics.Series {
+	for _, f := range registry.Gather() {
+		if f.Name != name {
+			continue
+		}
+		for _, s := range f.Series {
+			if assert.ObjectsAreEqual(labelValues, s.LabelValues) {
+				return s
+			}
+		}
+	}
+	return metrics.Series{}
+}
+
+// prioritizedTx returns a good message of the class, told apart by its signature
+func prioritizedTx(class ab.PriorityClass, signature string) *filter.Message {
+	return &filter.Message{
+		Envelope:  &cb.Envelope{Payload: []byte("GOOD"), Signature: []byte(signature)},
+		Extension: &ab.OrdererHeaderExtension{Priority: class},
+	}
+}
+
+func signatures(batch []*cb.Envelope) []string {
+	var result []string
+	for _, env := range batch {
+		result = append(result, string(env.Signature))
+	}
+	return result
+}
+
+func TestPriorityOrdering(t *testing.T) {
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 10, AbsoluteMaxBytes: 1000, PreferredMaxBytes: 100}}, getFilters(), nil, nil, nil, nil)
+
+	for _, msg := range []*filter.Message{
+		prioritizedTx(ab.PriorityClass_LOW, "low1"),
+		prioritizedTx(ab.PriorityClass_NORMAL, "normal1"),
+		prioritizedTx(ab.PriorityClass_LOW, "low2"),
+		prioritizedTx(ab.PriorityClass_NORMAL, "normal2"),
+	} {
+		batches, _, ok, pending := r.Ordered(msg)
+		assert.Nil(t, batches)
+		assert.True(t, ok)
+		assert.True(t, pending)
+	}
+
+	batches, committers, ok, pending := r.Ordered(prioritizedTx(ab.PriorityClass_HIGH, "high"))
+	assert.True(t, ok)
+	assert.False(t, pending, "An urgent message should cut the batch")
+	assert.Len(t, batches, 1)
+	assert.Len(t, committers[0], 5)
+	assert.Equal(t, []string{"high", "normal1", "normal2", "low1", "low2"}, signatures(batches[0]),
+		"The urgent message should be ahead of the pending messages, and LOW messages behind the others")
+
+	batch, _ := r.Cut()
+	assert.Empty(t, batch)
+	r.Ordered(prioritizedTx(ab.PriorityClass_LOW, "low3"))
+	r.Ordered(prioritizedTx(ab.PriorityClass_NORMAL, "normal3"))
+	batch, _ = r.Cut()
+	assert.Equal(t, []string{"normal3", "low3"}, signatures(batch), "The LOW messages should be forgotten once cut")
+}
+
+func TestPriorityOverflow(t *testing.T) {
+	r := NewReceiverImpl("testchain", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 10, AbsoluteMaxBytes: 1000, PreferredMaxBytes: 20}}, getFilters(), nil, nil, nil, nil)
+
+	r.Ordered(prioritizedTx(ab.PriorityClass_NORMAL, "normal1"))
+	batches, _, _, pending := r.Ordered(prioritizedTx(ab.PriorityClass_CONTROL, "control"))
+	assert.False(t, pending)
+	assert.Equal(t, [][]string{{"normal1"}, {"control"}}, [][]string{signatures(batches[0]), signatures(batches[1])},
+		"The pending batch should be cut first when the urgent message would overflow it")
+}
+
+func cutCount(registry *metrics.Registry, reason string) float64 {
+	return series(registry, "orderer_blockcutter_batches_cut_total", "cutmetrics", reason).Value
+}
+
+func TestCutMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	r := NewReceiverImpl("cutmetrics", &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 2, AbsoluteMaxBytes: 1000, PreferredMaxBytes: 100}}, getFilters(), nil, nil, nil, registry)
+	counts := map[string]float64{}
+	for _, reason := range []string{cutIsolated, cutPreferredMaxSize, cutMaxMessageCount, cutTimeout, cutPriority} {
+		counts[reason] = cutCount(registry, reason)
+	}
+
+	r.Ordered(goodTx)
+	r.Ordered(goodTx)
+	assert.Equal(t, counts[cutMaxMessageCount]+1, cutCount(registry, cutMaxMessageCount))
+
+	r.Ordered(goodTx)
+	r.Ordered(isolatedTx)
+	assert.Equal(t, counts[cutIsolated]+2, cutCount(registry, cutIsolated), "Both the pending batch and the isolated message should be counted")
+
+	r.Cut()
+	assert.Equal(t, counts[cutTimeout], cutCount(registry, cutTimeout), "Empty batches should not be counted")
+	r.Ordered(goodTx)
+	r.Cut()
+	assert.Equal(t, counts[cutTimeout]+1, cutCount(registry, cutTimeout))
+
+	mediumTx := &filter.Message{Envelope: &cb.Envelope{Payload: []byte("GOOD"), Signature: make([]byte, 56)}}
+	r.Ordered(mediumTx)
+	r.Ordered(mediumTx)
+	assert.Equal(t, counts[cutPreferredMaxSize]+1, cutCount(registry, cutPreferredMaxSize))
+
+	r.Ordered(prioritizedTx(ab.PriorityClass_HIGH, ""))
+	assert.Equal(t, counts[cutPreferredMaxSize]+1, cutCount(registry, cutPreferredMaxSize), "The urgent message should join the pending message")
+	assert.Equal(t, counts[cutPriority]+1, cutCount(registry, cutPriority))
+
+	fill := series(registry, "orderer_blockcutter_batch_fill_ratio", "cutmetrics")
+	assert.Equal(t, uint64(6), fill.Count)
+	assert.Equal(t, 4.0, fill.Sum, "Batches of 2, 1, 1, 1, 1 and 2 messages of at most 2")
+	bytesFill := series(registry, "orderer_blockcutter_batch_bytes_fill_ratio", "cutmetrics")
+	assert.Equal(t, uint64(6), bytesFill.Count)
+	assert.InDelta(t, 1.48, bytesFill.Sum, 1e-9, "Batches of 8, 4, 8, 4, 60 and 64 bytes of a preferred 100")
+}
@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package deliver bootstraps an orderer with the genesis block of the system
+// channel of an existing orderer, fetched over its Deliver service, so that
+// a node may join an ordering service without the genesis block being
+// distributed out of band.
+package deliver
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/common/bootstrap"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var logger = logging.MustGetLogger("orderer/common/bootstrap/deliver")
+
+// Options configure the fetching of the genesis block
+type Options struct {
+	// Address is the host and port of the existing orderer
+	Address string
+	// ChainID is the ID of the system channel
+	ChainID string
+	// Hash, if set, is the expected hash of the header of the genesis block,
+	// so that the existing orderer need not be trusted to serve the right
+	// block
+	Hash []byte
+	// DialOptions are the options connections are made with, such as their
+	// transport credentials
+	DialOptions []grpc.DialOption
+	// Signer signs the seek requests, which are unsigned if it is nil
+	Signer crypto.LocalSigner
+	// Timeout is how long to keep retrying while the orderer cannot be
+	// reached or cannot deliver the block
+	Timeout time.Duration
+	// RetryInterval is the time waited between attempts
+	RetryInterval time.Duration
+	// Clock times the attempts, the wall clock if nil
+	Clock clock.Clock
+}
+
+type deliverBootstrapper struct {
+	opts Options
+}
+
+// New returns a bootstrap helper which fetches the genesis block from an
+// existing orderer
+func New(opts Options) bootstrap.Helper {
+	if opts.Clock == nil {
+		opts.Clock = clock.Real()
+	}
+	return &deliverBootstrapper{opts: opts}
+}
+
+// GenesisBlock returns the genesis block fetched from the existing orderer,
+// panicking if it could not be fetched within the timeout or is not the
+// genesis block of the system channel
+func (b *deliverBootstrapper) GenesisBlock() *cb.Block {
+	deadline := b.opts.Clock.Now().Add(b.opts.Timeout)
+	for {
+		block, err := b.fetch(deadline)
+		if err == nil {
+			if err := b.validate(block); err != nil {
+				panic(fmt.Errorf("Unable to bootstrap orderer. Invalid genesis block from %s: %v", b.opts.Address, err))
+			}
+			logger.Infof("Fetched genesis block of channel %s from %s", b.opts.ChainID, b.opts.Address)
+			return block
+		}
+		if b.opts.Clock.Now().Add(b.opts.RetryInterval).After(deadline) {
+			panic(fmt.Errorf("Unable to bootstrap orderer. Error fetching genesis block from %s: %v", b.opts.Address, err))
+		}
+		logger.Warningf("Could not fetch genesis block from %s, retrying in %s: %s", b.opts.Address, b.opts.RetryInterval, err)
+		<-b.opts.Clock.After(b.opts.RetryInterval)
+	}
+}
+
+// fetch requests block 0 of the system channel
+func (b *deliverBootstrapper) fetch(deadline time.Time) (*cb.Block, error) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, b.opts.Address, append(b.opts.DialOptions, grpc.WithBlock())...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	stream, err := ab.NewAtomicBroadcastClient(conn).Deliver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	genesis := &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 0}}}
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, b.opts.ChainID, b.opts.Signer, &ab.SeekInfo{
+		Start:    genesis,
+		Stop:     genesis,
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+	}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(env); err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	block := resp.GetBlock()
+	if block == nil {
+		return nil, fmt.Errorf("orderer responded with %s", resp.GetStatus())
+	}
+	return block, nil
+}
+
+// validate checks the block is the genesis config block of the system
+// channel, with the expected hash if any
+func (b *deliverBootstrapper) validate(block *cb.Block) error {
+	if block.Header == nil || block.Data == nil {
+		return fmt.Errorf("block is missing its header or data")
+	}
+	if block.Header.Number != 0 || block.Header.PreviousHash != nil {
+		return fmt.Errorf("block %d is not a genesis block", block.Header.Number)
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("data hash does not match the data of the block")
+	}
+	if b.opts.Hash != nil && !bytes.Equal(block.Header.Hash(), b.opts.Hash) {
+		return fmt.Errorf("block hash %x is not the expected hash %x", block.Header.Hash(), b.opts.Hash)
+	}
+
+	env, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return err
+	}
+	chdr, err := utils.UnmarshalEnvelopeOfType(env, cb.HeaderType_CONFIG, &cb.ConfigEnvelope{})
+	if err != nil {
+		return fmt.Errorf("block does not hold a config: %s", err)
+	}
+	if chdr.ChannelId != b.opts.ChainID {
+		return fmt.Errorf("block is the genesis block of channel %s rather than %s", chdr.ChannelId, b.opts.ChainID)
+	}
+	return nil
+}
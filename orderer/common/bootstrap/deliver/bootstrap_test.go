@@ -0,0 +1,163 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// mockOrderer delivers block to every seek request, or responds with
+// NOT_FOUND if it is nil
+type mockOrderer struct {
+	block *cb.Block
+	seeks chan *cb.Envelope
+}
+
+func (mo *mockOrderer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	return nil
+}
+
+func (mo *mockOrderer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	env, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	mo.seeks <- env
+	if mo.block == nil {
+		return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_NOT_FOUND}})
+	}
+	return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Block{Block: mo.block}})
+}
+
+func serve(t *testing.T, listener net.Listener, mo *mockOrderer) *grpc.Server {
+	server := grpc.NewServer()
+	ab.RegisterAtomicBroadcastServer(server, mo)
+	go server.Serve(listener)
+	return server
+}
+
+func start(t *testing.T, block *cb.Block) (string, *mockOrderer, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	mo := &mockOrderer{block: block, seeks: make(chan *cb.Envelope, 100)}
+	server := serve(t, listener, mo)
+	return listener.Addr().String(), mo, server.Stop
+}
+
+func options(address string) Options {
+	return Options{
+		Address:       address,
+		ChainID:       provisional.TestChainID,
+		DialOptions:   []grpc.DialOption{grpc.WithInsecure()},
+		Signer:        mockcrypto.FakeLocalSigner,
+		Timeout:       time.Second,
+		RetryInterval: 10 * time.Millisecond,
+	}
+}
+
+func genesisBlock() *cb.Block {
+	return provisional.New(genesisconfig.Load(genesisconfig.SampleInsecureProfile)).GenesisBlock()
+}
+
+func TestGenesisBlock(t *testing.T) {
+	genesis := genesisBlock()
+	address, mo, stop := start(t, genesis)
+	defer stop()
+
+	opts := options(address)
+	opts.Hash = genesis.Header.Hash()
+	block := New(opts).GenesisBlock()
+	assert.True(t, proto.Equal(genesis, block))
+
+	seek := <-mo.seeks
+	seekInfo := &ab.SeekInfo{}
+	chdr, err := utils.UnmarshalEnvelopeOfType(seek, cb.HeaderType_DELIVER_SEEK_INFO, seekInfo)
+	require.NoError(t, err)
+	assert.Equal(t, provisional.TestChainID, chdr.ChannelId)
+	assert.Equal(t, uint64(0), seekInfo.Start.GetSpecified().Number)
+	assert.NotNil(t, seek.Signature)
+}
+
+func TestGenesisBlockRetry(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	// The orderer starts serving after the first attempts failed
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return
+		}
+		server := serve(t, listener, &mockOrderer{block: genesisBlock(), seeks: make(chan *cb.Envelope, 100)})
+		time.Sleep(2 * time.Second)
+		server.Stop()
+	}()
+
+	opts := options(address)
+	opts.Timeout = 2 * time.Second
+	block := New(opts).GenesisBlock()
+	assert.Equal(t, uint64(0), block.Header.Number)
+}
+
+func TestGenesisBlockErrors(t *testing.T) {
+	t.Run("NotFound", func(t *testing.T) {
+		address, mo, stop := start(t, nil)
+		defer stop()
+		assert.Panics(t, func() { New(options(address)).GenesisBlock() })
+		assert.True(t, len(mo.seeks) > 1, "the request should have been retried")
+	})
+
+	t.Run("WrongHash", func(t *testing.T) {
+		address, _, stop := start(t, genesisBlock())
+		defer stop()
+		opts := options(address)
+		opts.Hash = []byte("other")
+		assert.Panics(t, func() { New(opts).GenesisBlock() })
+	})
+
+	t.Run("WrongChannel", func(t *testing.T) {
+		block := provisional.New(genesisconfig.Load("SampleNoConsortium")).GenesisBlockForChannel("foo")
+		address, _, stop := start(t, block)
+		defer stop()
+		assert.Panics(t, func() { New(options(address)).GenesisBlock() })
+	})
+
+	t.Run("NotGenesis", func(t *testing.T) {
+		block := genesisBlock()
+		block.Header.Number = 1
+		address, _, stop := start(t, block)
+		defer stop()
+		assert.Panics(t, func() { New(options(address)).GenesisBlock() })
+	})
+
+	t.Run("NotConfig", func(t *testing.T) {
+		block := cb.NewBlock(0, nil)
+		block.Data.Data = [][]byte{utils.MarshalOrPanic(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: utils.MakePayloadHeader(utils.MakeChannelHeader(cb.HeaderType_MESSAGE, 0, provisional.TestChainID, 0), &cb.SignatureHeader{}),
+		})})}
+		block.Header.DataHash = block.Data.Hash()
+		address, _, stop := start(t, block)
+		defer stop()
+		assert.Panics(t, func() { New(options(address)).GenesisBlock() })
+	})
+}
@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// defaultMaxBatchMessages bounds the messages of a batch if the handler is
+// not configured otherwise
+const defaultMaxBatchMessages = 1000
+
+// BatchHandler is a Handler which also handles the streams of the
+// BatchBroadcast service
+type BatchHandler interface {
+	Handler
+
+	// HandleBatch services a stream of batches of messages, answering each
+	// batch with the responses to its messages
+	HandleBatch(srv ab.BatchBroadcast_BroadcastBatchServer) error
+}
+
+// HandleBatch filters the messages of each batch, with the workers of the
+// verifier if the handler has one, and enqueues those admitted in the order
+// of the batch. Once a message cannot be enqueued, as its chain is overloaded
+// or halted, none of the messages after it are, so that the messages of a
+// batch which are ordered are never reordered by a retry of the rest. The
+// messages of a chain whose queue cannot take all those of the batch are
+// refused before any is enqueued, so that a batch is not half ordered for
+// want of room. A rejected message does not end the stream, as its rejection
+// is reported in the response to its batch, but a batch of more messages
+// than the handler allows does.
+func (bh *handlerImpl) HandleBatch(srv ab.BatchBroadcast_BroadcastBatchServer) error {
+	streamLogger := flogging.WithFields(logger, flogging.Fields{flogging.ClientField: comm.ClientIdentity(srv.Context())})
+	streamLogger.Debugf("Starting new batch broadcast loop")
+	bh.metrics.openStreams.With("BroadcastBatch").Add(1)
+	defer bh.metrics.openStreams.With("BroadcastBatch").Add(-1)
+	connection, trace := remoteAddress(srv.Context()), traceParent(srv.Context())
+	for {
+		batch, err := srv.Recv()
+		if err != nil {
+			return streamEnded(streamLogger, err)
+		}
+		resp, err := bh.handleBatch(srv.Context(), batch, connection, trace, streamLogger)
+		if err != nil {
+			return err
+		}
+		if err := srv.Send(resp); err != nil {
+			streamLogger.Warningf("Error sending to stream: %s", err)
+			return err
+		}
+	}
+}
+
+func (bh *handlerImpl) handleBatch(ctx context.Context, batch *ab.BroadcastBatchRequest, connection string, trace *tracing.SpanContext, streamLogger *flogging.FieldLogger) (*ab.BroadcastBatchResponse, error) {
+	bh.metrics.batchSize.Observe(float64(len(batch.Envelopes)))
+	if len(batch.Envelopes) > bh.maxBatch {
+		streamLogger.Warningf("Rejecting batch of %d messages, more than the maximum of %d", len(batch.Envelopes), bh.maxBatch)
+		return nil, grpc.Errorf(codes.InvalidArgument, "batch of %d messages exceeds the maximum of %d", len(batch.Envelopes), bh.maxBatch)
+	}
+
+	// The batch is held as a whole, as holding its messages one at a time
+	// could wait forever for the messages of the batch already held
+	var size int64
+	for _, env := range batch.Envelopes {
+		size += envelopeSize(env)
+	}
+	if !bh.memory.Acquire(memory.HolderBroadcast, size, ctx.Done()) {
+		return nil, ctx.Err()
+	}
+	defer bh.memory.Release(memory.HolderBroadcast, size)
+
+	admissions := make([]*admission, len(batch.Envelopes))
+	// The messages of the batch are held as a whole, but each holds its own
+	// slot of the quota of its creator
+	defer func() {
+		for _, adm := range admissions {
+			adm.release()
+		}
+	}()
+	for i, env := range batch.Envelopes {
+		adm := &admission{received: env, connection: connection, trace: trace, ctx: ctx, receivedAt: time.Now(), done: make(chan struct{})}
+		admissions[i] = adm
+		if bh.verifier == nil {
+			bh.admit(adm, streamLogger)
+			close(adm.done)
+			continue
+		}
+		bh.verifier.submit(bh.class(adm), func() {
+			bh.admit(adm, streamLogger)
+			close(adm.done)
+		})
+	}
+
+	for _, adm := range admissions {
+		<-adm.done
+	}
+	full := fullChains(admissions)
+
+	resp := &ab.BroadcastBatchResponse{Responses: make([]*ab.BroadcastResponse, len(admissions))}
+	var refused *admission
+	for i, adm := range admissions {
+		enqueuing := adm.status == cb.Status_SUCCESS && adm.receipt == nil
+		if pushback, ok := full[adm.chainID]; enqueuing && ok && refused == nil {
+			adm.status, adm.reason, adm.retryAfter, adm.pressure = pushback.status, pushback.reason, pushback.retryAfter, pushback.pressure
+			adm.info = pushback.info
+			refused, enqueuing = adm, false
+		}
+		if enqueuing && refused != nil {
+			adm.status, adm.reason, adm.retryAfter, adm.pressure = refused.status, refused.reason, refused.retryAfter, refused.pressure
+			adm.info = fmt.Sprintf("not enqueued as an earlier message of the batch was refused: %s", refused.info)
+			enqueuing = false
+		}
+		var accepted bool
+		resp.Responses[i], accepted = bh.respond(adm)
+		if enqueuing && !accepted {
+			refused = adm
+		}
+	}
+	// The commits of the messages are awaited once all are enqueued, so that
+	// they may be cut into the same blocks
+	for i, adm := range admissions {
+		resp.Responses[i] = bh.awaitCommit(adm, resp.Responses[i])
+	}
+	return resp, nil
+}
+
+// fullChains returns the refusal of each chain whose queue cannot take all
+// the admitted messages of the batch bound to it, keyed by chain ID. The
+// messages of the batch are counted as if all waited in the queue of the
+// chain at once, along with those already waiting, but a batch of more
+// messages than the queue may hold is let through while none wait, so that
+// it can ever be enqueued.
+func fullChains(admissions []*admission) map[string]*admission {
+	counts := make(map[string]int)
+	supports := make(map[string]Support)
+	for _, adm := range admissions {
+		if adm.status == cb.Status_SUCCESS && adm.receipt == nil {
+			counts[adm.chainID]++
+			supports[adm.chainID] = adm.support
+		}
+	}
+
+	full := make(map[string]*admission)
+	for chainID, count := range counts {
+		pressure := supports[chainID].Pressure()
+		maxDepth := pressure.Config.MaxQueueDepth
+		if !pressure.Overloaded && (maxDepth <= 0 || pressure.QueueDepth == 0 || pressure.QueueDepth+count <= maxDepth) {
+			continue
+		}
+		full[chainID] = &admission{
+			status:     cb.Status_SERVICE_UNAVAILABLE,
+			reason:     "overloaded",
+			retryAfter: pressure.RetryAfter(),
+			pressure:   backpressureInfo(pressure),
+			info: fmt.Sprintf("channel %s cannot queue the %d messages of the batch, %d are queued of at most %d, retry in %s",
+				chainID, count, pressure.QueueDepth, maxDepth, pressure.RetryAfter()),
+		}
+	}
+	return full
+}
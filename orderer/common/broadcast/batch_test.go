@@ -0,0 +1,166 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast_test
+
+import (
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	mockbroadcast "github.com/hyperledger/fabric/orderer/mocks/broadcast"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type mockBatchB struct {
+	grpc.ServerStream
+	recvChan chan *ab.BroadcastBatchRequest
+	sendChan chan *ab.BroadcastBatchResponse
+}
+
+func newMockBatchB() *mockBatchB {
+	return &mockBatchB{
+		recvChan: make(chan *ab.BroadcastBatchRequest),
+		sendChan: make(chan *ab.BroadcastBatchResponse),
+	}
+}
+
+func (m *mockBatchB) Context() context.Context {
+	return context.Background()
+}
+
+func (m *mockBatchB) Send(resp *ab.BroadcastBatchResponse) error {
+	m.sendChan <- resp
+	return nil
+}
+
+func (m *mockBatchB) Recv() (*ab.BroadcastBatchRequest, error) {
+	batch, ok := <-m.recvChan
+	if !ok {
+		return nil, io.EOF
+	}
+	return batch, nil
+}
+
+func statuses(resp *ab.BroadcastBatchResponse) []cb.Status {
+	var result []cb.Status
+	for _, r := range resp.Responses {
+		result = append(result, r.Status)
+	}
+	return result
+}
+
+func TestBatch(t *testing.T) {
+	for _, verifier := range []*broadcast.Verifier{nil, broadcast.NewVerifier(4)} {
+		support := &mockbroadcast.Support{FiltersVal: filter.NewRuleSet([]filter.Rule{slowRule{reject: 3}, filter.AcceptRule})}
+		bh := broadcast.NewParallelHandler(mockbroadcast.NewSupportManager(systemChain, support), nil, verifier, broadcast.Config{})
+		m := newMockBatchB()
+		done := make(chan error)
+		go func() {
+			done <- bh.HandleBatch(m)
+		}()
+
+		batch := &ab.BroadcastBatchRequest{}
+		for i := 0; i < 6; i++ {
+			batch.Envelopes = append(batch.Envelopes, makeMessage(systemChain, []byte(strconv.Itoa(i))))
+		}
+		m.recvChan <- batch
+		resp := <-m.sendChan
+		assert.Equal(t, []cb.Status{cb.Status_SUCCESS, cb.Status_SUCCESS, cb.Status_SUCCESS, cb.Status_BAD_REQUEST, cb.Status_SUCCESS, cb.Status_SUCCESS}, statuses(resp))
+		assert.Equal(t, []string{"0", "1", "2", "4", "5"}, enqueuedData(support), "The admitted messages should be enqueued in the order of the batch")
+
+		m.recvChan <- &ab.BroadcastBatchRequest{Envelopes: []*cb.Envelope{makeMessage(systemChain, []byte("6"))}}
+		resp = <-m.sendChan
+		assert.Equal(t, []cb.Status{cb.Status_SUCCESS}, statuses(resp), "A rejected message should not end the stream")
+
+		close(m.recvChan)
+		assert.NoError(t, <-done)
+		if verifier != nil {
+			verifier.Stop()
+		}
+	}
+}
+
+func TestBatchRefused(t *testing.T) {
+	support := &mockbroadcast.Support{FiltersVal: filter.NewRuleSet([]filter.Rule{filter.AcceptRule})}
+	halted := &mockbroadcast.Support{FiltersVal: filter.NewRuleSet([]filter.Rule{filter.AcceptRule}), RejectEnqueue: true}
+	mm := &mockbroadcast.SupportManager{Chains: map[string]broadcast.Support{systemChain: support, "halted": halted}}
+	bh := broadcast.NewParallelHandler(mm, nil, nil, broadcast.Config{})
+	m := newMockBatchB()
+	defer close(m.recvChan)
+	go bh.HandleBatch(m)
+
+	m.recvChan <- &ab.BroadcastBatchRequest{Envelopes: []*cb.Envelope{
+		makeMessage(systemChain, []byte("0")),
+		makeMessage("halted", []byte("1")),
+		makeMessage(systemChain, []byte("2")),
+		makeMessage("unknown", []byte("3")),
+	}}
+	resp := <-m.sendChan
+	assert.Equal(t, []cb.Status{cb.Status_SUCCESS, cb.Status_SERVICE_UNAVAILABLE, cb.Status_SERVICE_UNAVAILABLE, cb.Status_NOT_FOUND}, statuses(resp))
+	assert.Equal(t, []string{"0"}, enqueuedData(support), "No message after one which could not be enqueued should be enqueued")
+}
+
+func TestBatchPushedBack(t *testing.T) {
+	support := &mockbroadcast.Support{FiltersVal: filter.NewRuleSet([]filter.Rule{filter.AcceptRule})}
+	busy := &mockbroadcast.Support{
+		FiltersVal:  filter.NewRuleSet([]filter.Rule{filter.AcceptRule}),
+		PressureVal: backpressure.Status{QueueDepth: 8, Config: backpressure.Config{MaxQueueDepth: 10}},
+	}
+	mm := &mockbroadcast.SupportManager{Chains: map[string]broadcast.Support{systemChain: support, "busy": busy}}
+	bh := broadcast.NewParallelHandler(mm, nil, nil, broadcast.Config{})
+	m := newMockBatchB()
+	defer close(m.recvChan)
+	go bh.HandleBatch(m)
+
+	m.recvChan <- &ab.BroadcastBatchRequest{Envelopes: []*cb.Envelope{
+		makeMessage(systemChain, []byte("0")),
+		makeMessage("busy", []byte("1")),
+		makeMessage("busy", []byte("2")),
+	}}
+	resp := <-m.sendChan
+	assert.Equal(t, []cb.Status{cb.Status_SUCCESS, cb.Status_SUCCESS, cb.Status_SUCCESS}, statuses(resp), "A batch which fits in the queue should be enqueued")
+
+	m.recvChan <- &ab.BroadcastBatchRequest{Envelopes: []*cb.Envelope{
+		makeMessage(systemChain, []byte("3")),
+		makeMessage("busy", []byte("4")),
+		makeMessage("busy", []byte("5")),
+		makeMessage("busy", []byte("6")),
+		makeMessage(systemChain, []byte("7")),
+	}}
+	resp = <-m.sendChan
+	assert.Equal(t, []cb.Status{cb.Status_SUCCESS, cb.Status_SERVICE_UNAVAILABLE, cb.Status_SERVICE_UNAVAILABLE, cb.Status_SERVICE_UNAVAILABLE, cb.Status_SERVICE_UNAVAILABLE}, statuses(resp))
+	assert.Contains(t, resp.Responses[1].Info, "cannot queue the 3 messages of the batch")
+	assert.NotNil(t, resp.Responses[1].Backpressure, "The client should be told the load of the chain")
+	assert.Equal(t, []string{"1", "2"}, enqueuedData(busy), "No message of a batch which does not fit in the queue should be enqueued")
+	assert.Equal(t, []string{"0", "3"}, enqueuedData(support))
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	support := &mockbroadcast.Support{FiltersVal: filter.NewRuleSet([]filter.Rule{filter.AcceptRule})}
+	bh := broadcast.NewParallelHandler(mockbroadcast.NewSupportManager(systemChain, support), nil, nil, broadcast.Config{MaxBatchMessages: 2})
+	m := newMockBatchB()
+	done := make(chan error)
+	go func() {
+		done <- bh.HandleBatch(m)
+	}()
+
+	m.recvChan <- &ab.BroadcastBatchRequest{Envelopes: []*cb.Envelope{
+		makeMessage(systemChain, []byte("0")),
+		makeMessage(systemChain, []byte("1")),
+		makeMessage(systemChain, []byte("2")),
+	}}
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(<-done))
+	assert.Empty(t, enqueuedData(support))
+}
@@ -17,12 +17,38 @@ limitations under the License.
 package broadcast
 
 import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
+	"github.com/hyperledger/fabric/orderer/common/clock"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	"github.com/hyperledger/fabric/orderer/common/latency"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	"github.com/hyperledger/fabric/orderer/common/receipts"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	"github.com/hyperledger/fabric/orderer/common/sigfilter"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
 
 	"io"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -55,108 +81,696 @@ type SupportManager interface {
 // Support provides the backing resources needed to support broadcast on a chain
 type Support interface {
 	// Enqueue accepts a message and returns true on acceptance, or false on shutdown
-	Enqueue(env *cb.Envelope) bool
+	Enqueue(msg *filter.Message) bool
 
 	// Filters returns the set of broadcast filters for this chain
 	Filters() *filter.RuleSet
+
+	// Pressure returns the load of the chain, which tells clients whose
+	// messages it refuses when to retry
+	Pressure() backpressure.Status
+
+	// PolicyManager returns the policy manager of the chain, by whose Writers
+	// policy messages are authorized
+	PolicyManager() policies.Manager
+
+	// WatchCommit returns a channel which receives the COMMITTED status of
+	// the message with the envelope hash once it is committed, and a function
+	// to call once done watching
+	WatchCommit(txHash []byte) (<-chan *ab.TransactionStatusResponse, func())
+
+	// BroadcastQuota returns the limits the config of the chain sets on the
+	// messages submitted by each identity
+	BroadcastQuota() *ab.BroadcastQuota
+}
+
+// Config is the configuration of the handlers created by NewHandlerImpl and
+// NewParallelHandler. Its zero value limits no rate, holds back no message,
+// keeps no receipts, neither times, follows nor traces the messages, ends
+// rejected streams with an OK status, and records nothing in an audit trail.
+type Config struct {
+	// Priorities are the priority classes the creators of messages are
+	// entitled to
+	Priorities priority.Config
+	// Receipts, if set, keeps the signed responses to the messages enqueued
+	Receipts *receipts.Log
+	// RateLimits are the rates at which connections and identities may
+	// broadcast messages
+	RateLimits RateLimits
+	// CommitTimeout bounds the wait for the commit of the messages whose
+	// clients await it, thirty seconds if zero
+	CommitTimeout time.Duration
+	// MaxBatchMessages bounds the number of messages of each batch received
+	// by BroadcastBatch, 1000 if zero
+	MaxBatchMessages int
+	// Pipeline, if set, times the stages of the pipeline the handler runs
+	Pipeline *pipeline.Timers
+	// Memory, if set, holds back the messages received while the orderer
+	// holds more pending messages than its limit
+	Memory *memory.Accountant
+	// Latency, if set, follows the messages enqueued until they are appended
+	Latency *latency.Tracker
+	// Tracer, if set, traces the sampled messages
+	Tracer *tracing.Tracer
+	// Status configures the errors with which rejected streams end
+	Status rpcstatus.Config
+	// Audit, if set, records the config updates denied to their creators
+	Audit *audit.Trail
+	// Metrics, if set, creates the metrics of the handler instead of the
+	// default registry
+	Metrics metrics.Provider
 }
 
 type handlerImpl struct {
-	sm SupportManager
+	sm            SupportManager
+	signer        crypto.LocalSigner
+	verifier      *Verifier
+	priorities    priority.Config
+	receipts      *receipts.Log
+	limiter       *rateLimiter
+	quotas        *quotas
+	commitTimeout time.Duration
+	maxBatch      int
+	memory        *memory.Accountant
+	pipeline      *pipeline.Timers
+	latency       *latency.Tracker
+	tracer        *tracing.Tracer
+	status        rpcstatus.Config
+	audit         *audit.Trail
+	metrics       *handlerMetrics
+	// clock times the rate limits, the quotas and the commit waits
+	clock clock.Clock
+}
+
+// NewHandlerImpl constructs a new implementation of the Handler interface, which
+// signs its responses with signer unless it is nil
+func NewHandlerImpl(sm SupportManager, signer crypto.LocalSigner, conf Config) Handler {
+	return NewParallelHandler(sm, signer, nil, conf)
 }
 
-// NewHandlerImpl constructs a new implementation of the Handler interface
-func NewHandlerImpl(sm SupportManager) Handler {
+// NewParallelHandler constructs a Handler as NewHandlerImpl does, which
+// filters the messages of each stream with the workers of the verifier while
+// it receives the next messages. Messages are still enqueued, and responded
+// to, in the order in which they were received, while the verifier filters
+// the messages of the streams by the priority classes they are entitled to.
+// If the verifier is nil, each stream filters its messages itself, one at a
+// time. The signed responses to the messages enqueued are kept as receipts in
+// the configured receipts log, if any, and messages submitted again within its
+// dedup window are answered with their receipts instead of being enqueued.
+// Clients exceeding the configured rate limits, by connection or by identity,
+// are answered with SERVICE_UNAVAILABLE and the delay after which they may
+// retry, as are identities exceeding the broadcast quota of the chain across
+// all their streams. Clients which ask to await the commit of a message are answered
+// once it is committed, or after the configured commit timeout. The handler
+// also handles streams of batches of messages.
+func NewParallelHandler(sm SupportManager, signer crypto.LocalSigner, verifier *Verifier, conf Config) BatchHandler {
+	if conf.CommitTimeout <= 0 {
+		conf.CommitTimeout = defaultCommitTimeout
+	}
+	if conf.MaxBatchMessages <= 0 {
+		conf.MaxBatchMessages = defaultMaxBatchMessages
+	}
+	clk := clock.Real()
 	return &handlerImpl{
-		sm: sm,
+		sm:            sm,
+		signer:        signer,
+		verifier:      verifier,
+		priorities:    conf.Priorities,
+		receipts:      conf.Receipts,
+		limiter:       newRateLimiter(conf.RateLimits, clk),
+		quotas:        newQuotas(clk),
+		commitTimeout: conf.CommitTimeout,
+		maxBatch:      conf.MaxBatchMessages,
+		memory:        conf.Memory,
+		pipeline:      conf.Pipeline,
+		latency:       conf.Latency,
+		tracer:        conf.Tracer,
+		status:        conf.Status,
+		audit:         conf.Audit,
+		metrics:       newHandlerMetrics(conf.Metrics),
+		clock:         clk,
 	}
 }
 
+// admission is the decision to admit a received message for ordering, to
+// reject it with the status for the reason, described to the client by info,
+// or to answer it with the receipt of its earlier acceptance if it is a
+// duplicate
+type admission struct {
+	received   *cb.Envelope
+	connection string
+	decoded    *filter.Message
+	decodeErr  error
+	size       int64
+	// memory accounts for the size of the message while it is held
+	memory     *memory.Accountant
+	processed  *filter.Message
+	support    Support
+	chainID    string
+	status     cb.Status
+	reason     string
+	info       string
+	retryAfter time.Duration
+	pressure   *ab.Backpressure
+	receipt    *ab.BroadcastResponse
+	txHash     string
+	txLogger   *flogging.FieldLogger
+	receivedAt time.Time
+	filteredAt time.Time
+	// trace is the span context the client sent with the stream, if any
+	trace *tracing.SpanContext
+	// ctx is the context of the stream the message was received on
+	ctx context.Context
+	// committed receives the status of the message once it is committed, if
+	// its client awaits it, until stopWatching is called
+	committed    <-chan *ab.TransactionStatusResponse
+	stopWatching func()
+	// quotaKey identifies the slot of the quota of the creator of the
+	// message it holds in flight, if any, until it is released, or until
+	// the message is committed once it is enqueued
+	quotas   *quotas
+	quotaKey string
+	// releaseQuota releases the slot once the message is committed, so that
+	// it is free by the time the commit is answered
+	releaseQuota func()
+
+	// err is the error which ended the stream, in place of a message
+	err  error
+	done chan struct{}
+}
+
 // Handle starts a service thread for a given gRPC connection and services the broadcast connection
 func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
-	logger.Debugf("Starting new broadcast loop")
+	streamLogger := flogging.WithFields(logger, flogging.Fields{flogging.ClientField: comm.ClientIdentity(srv.Context())})
+	streamLogger.Debugf("Starting new broadcast loop")
+	bh.metrics.openStreams.With("Broadcast").Add(1)
+	defer bh.metrics.openStreams.With("Broadcast").Add(-1)
+	connection, trace := remoteAddress(srv.Context()), traceParent(srv.Context())
+	if bh.verifier != nil {
+		return bh.handleParallel(srv, connection, trace, streamLogger)
+	}
 	for {
 		msg, err := srv.Recv()
-		if err == io.EOF {
-			logger.Debugf("Received EOF, hangup")
-			return nil
-		}
 		if err != nil {
-			logger.Warningf("Error reading from stream: %s", err)
+			return streamEnded(streamLogger, err)
+		}
+		adm := &admission{received: msg, connection: connection, trace: trace, ctx: srv.Context(), receivedAt: time.Now()}
+		if !adm.hold(srv, bh.memory) {
+			return srv.Context().Err()
+		}
+		bh.admit(adm, streamLogger)
+		done, err := bh.complete(srv, adm)
+		adm.release()
+		if done {
 			return err
 		}
+	}
+}
 
-		payload, err := utils.UnmarshalPayload(msg.Payload)
-		if err != nil {
-			logger.Warningf("Received malformed message, dropping connection: %s", err)
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
+// handleParallel receives the messages of the stream while the verifier
+// filters those already received, completing them in order. At most as many
+// messages as the verifier has workers are pending for each stream.
+func (bh *handlerImpl) handleParallel(srv ab.AtomicBroadcast_BroadcastServer, connection string, trace *tracing.SpanContext, streamLogger *flogging.FieldLogger) error {
+	pending := make(chan *admission, bh.verifier.Size())
+	stop := make(chan struct{})
+	defer func() {
+		close(stop)
+		// Release the messages received but not completed once the reader
+		// stops, which it does when the stream ends, and once the verifier
+		// is done admitting them
+		go func() {
+			for adm := range pending {
+				<-adm.done
+				adm.release()
+			}
+		}()
+	}()
+
+	go func() {
+		defer close(pending)
+		for {
+			adm := &admission{connection: connection, trace: trace, ctx: srv.Context(), done: make(chan struct{})}
+			msg, err := srv.Recv()
+			if err == nil {
+				adm.received, adm.receivedAt = msg, time.Now()
+				if !adm.hold(srv, bh.memory) {
+					err = srv.Context().Err()
+				}
+			}
+			if err != nil {
+				adm.err = err
+				close(adm.done)
+			} else {
+				bh.verifier.submit(bh.class(adm), func() {
+					bh.admit(adm, streamLogger)
+					close(adm.done)
+				})
+			}
+			select {
+			case pending <- adm:
+			case <-stop:
+				<-adm.done
+				adm.release()
+				return
+			}
+			if err != nil {
+				return
+			}
 		}
+	}()
 
-		if payload.Header == nil {
-			logger.Warningf("Received malformed message, with missing header, dropping connection")
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
+	for adm := range pending {
+		<-adm.done
+		if adm.err != nil {
+			return streamEnded(streamLogger, adm.err)
+		}
+		done, err := bh.complete(srv, adm)
+		adm.release()
+		if done {
+			return err
 		}
+	}
+	return nil
+}
 
-		chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
-		if err != nil {
-			logger.Warningf("Received malformed message (bad channel header), dropping connection: %s", err)
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
+// hold accounts for the received message with the accountant until it is
+// released, waiting while the orderer holds more pending messages than its
+// limit. It returns false if the stream ends first.
+func (adm *admission) hold(srv ab.AtomicBroadcast_BroadcastServer, accountant *memory.Accountant) bool {
+	size := envelopeSize(adm.received)
+	if !accountant.Acquire(memory.HolderBroadcast, size, srv.Context().Done()) {
+		return false
+	}
+	adm.size, adm.memory = size, accountant
+	return true
+}
+
+// envelopeSize returns the bytes the envelope is accounted for while pending
+func envelopeSize(env *cb.Envelope) int64 {
+	return int64(len(env.Payload) + len(env.Signature))
+}
+
+// release releases the message held by the admission, and its slot of the
+// quota of its creator, if any
+func (adm *admission) release() {
+	adm.memory.Release(memory.HolderBroadcast, adm.size)
+	adm.size = 0
+	if adm.quotas != nil {
+		adm.quotas.release(adm.quotaKey)
+		adm.quotas, adm.quotaKey = nil, ""
+	}
+}
+
+// receivedHash returns the hash of the message as it was received, which is
+// computed once for the admission and its response
+func (adm *admission) receivedHash() ([]byte, error) {
+	if adm.decoded != nil {
+		if txHash := adm.decoded.Hash(); txHash != nil {
+			return txHash, nil
 		}
+	}
+	return pool.Hash(adm.received)
+}
 
-		if chdr.Type == int32(cb.HeaderType_CONFIG_UPDATE) {
-			logger.Debugf("Preprocessing CONFIG_UPDATE")
-			msg, err = bh.sm.Process(msg)
-			if err != nil {
-				logger.Warningf("Rejecting CONFIG_UPDATE because: %s", err)
-				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
-			}
+// decode decodes the received message, once
+func (adm *admission) decode() (*filter.Message, error) {
+	if adm.decoded == nil && adm.decodeErr == nil {
+		adm.decoded, adm.decodeErr = filter.NewMessage(adm.received)
+	}
+	return adm.decoded, adm.decodeErr
+}
 
-			err = proto.Unmarshal(msg.Payload, payload)
-			if err != nil || payload.Header == nil {
-				logger.Criticalf("Generated bad transaction after CONFIG_UPDATE processing")
-				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_INTERNAL_SERVER_ERROR})
-			}
+// principal returns the principal of the bearer token the client of the
+// stream authenticated with, if any
+func (adm *admission) principal() (*interceptor.Principal, bool) {
+	if adm.ctx == nil {
+		return nil, false
+	}
+	return interceptor.PrincipalFromContext(adm.ctx)
+}
 
-			chdr, err = utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
-			if err != nil {
-				logger.Criticalf("Generated bad transaction after CONFIG_UPDATE processing (bad channel header): %s", err)
-				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_INTERNAL_SERVER_ERROR})
+// class returns the priority class the verifier queues the received message
+// by. A message which is malformed, or which requests a class beyond the
+// entitlement of its creator, is queued as NORMAL, to be rejected once it is
+// admitted.
+func (bh *handlerImpl) class(adm *admission) ab.PriorityClass {
+	msg, err := adm.decode()
+	if err != nil {
+		return ab.PriorityClass_NORMAL
+	}
+	class, ok := bh.priorities.Admitted(msg)
+	if !ok {
+		return ab.PriorityClass_NORMAL
+	}
+	return class
+}
+
+// remoteAddress returns the address of the client of the stream, which
+// identifies its connection, or an empty string if it is unknown
+func remoteAddress(ctx context.Context) string {
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		return pr.Addr.String()
+	}
+	return ""
+}
+
+// traceParent returns the span context the client sent with the stream, or
+// nil if it sent none
+func traceParent(ctx context.Context) *tracing.SpanContext {
+	if sc, ok := tracing.FromIncomingContext(ctx); ok {
+		return &sc
+	}
+	return nil
+}
+
+// streamEnded returns the error with which the stream ends after receiving
+// err, which is nil if the client hung up
+func streamEnded(streamLogger *flogging.FieldLogger, err error) error {
+	if err == io.EOF {
+		streamLogger.Debugf("Received EOF, hangup")
+		return nil
+	}
+	streamLogger.Warningf("Error reading from stream: %s", err)
+	return err
+}
+
+// admit decides whether the received message is admitted for ordering,
+// filtering it against the current config of its chain. Deciding only takes
+// from the limits and quotas of the client, which are safe for concurrent
+// use, so the messages of a stream may be admitted concurrently. A message
+// admitted holds a slot of the quota of its creator until it is released.
+func (bh *handlerImpl) admit(adm *admission, streamLogger *flogging.FieldLogger) {
+	start := time.Now()
+	bh.pipeline.Observe(pipeline.StageIngest, start.Sub(adm.receivedAt))
+	defer bh.pipeline.Since(pipeline.StageFilter, start)
+
+	adm.status = cb.Status_BAD_REQUEST
+	msg := adm.received
+
+	processed, err := adm.decode()
+	if err != nil {
+		streamLogger.Warningf("Received malformed message, dropping connection: %s", err)
+		adm.reason, adm.info = "malformed", fmt.Sprintf("malformed message: %s", err)
+		return
+	}
+	adm.chainID = processed.ChannelHeader.ChannelId
+	chainLogger := streamLogger.With(flogging.Fields{flogging.ChannelField: adm.chainID})
+
+	// Only the connection is limited before the message is authenticated, so
+	// that a client cannot exhaust the bucket of an identity it does not hold
+	if ok, wait := bh.limiter.allow(adm.connection, nil); !ok {
+		chainLogger.Warningf("Rejecting broadcast because the connection exceeded its rate limit, it may retry in %s", wait)
+		adm.status, adm.reason, adm.retryAfter = cb.Status_SERVICE_UNAVAILABLE, "rate_limited", wait
+		adm.info = fmt.Sprintf("rate limit exceeded, retry in %s", wait)
+		return
+	}
+
+	// A client authenticated by a bearer token only submits the messages
+	// created by the identities of the MSP its token vouches for
+	if principal, ok := adm.principal(); ok {
+		if err := principal.Creates(processed.SignatureHeader.Creator); err != nil {
+			chainLogger.Warningf("Rejecting broadcast because: %s", err)
+			adm.status, adm.reason, adm.info = cb.Status_FORBIDDEN, "forbidden", err.Error()
+			return
+		}
+	}
+
+	configUpdate := processed.Type() == cb.HeaderType_CONFIG_UPDATE
+	if configUpdate {
+		if support, ok := bh.sm.GetChain(adm.chainID); ok {
+			if authorized, info := bh.authorizeWriter(support, processed); !authorized {
+				chainLogger.Warningf("Rejecting CONFIG_UPDATE because: %s", info)
+				adm.status, adm.reason, adm.info = cb.Status_FORBIDDEN, "forbidden", info
+				return
 			}
+		}
+	}
 
-			if chdr.ChannelId == "" {
-				logger.Criticalf("Generated bad transaction after CONFIG_UPDATE processing (empty channel ID)")
-				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_INTERNAL_SERVER_ERROR})
+	// A duplicate is only answered with its receipt once its connection is
+	// within its rate limit and its client is a writer of the chain, so that
+	// receipts are not handed to clients which could not have sent the message
+	if txHash, err := adm.receivedHash(); err == nil {
+		if receipt := bh.receipts.Duplicate(txHash); receipt != nil {
+			if support, ok := bh.sm.GetChain(adm.chainID); ok && !configUpdate {
+				if authorized, info := bh.authorizeWriter(support, processed); !authorized {
+					chainLogger.Warningf("Rejecting duplicate broadcast because: %s", info)
+					adm.status, adm.reason, adm.info = cb.Status_FORBIDDEN, "forbidden", info
+					return
+				}
 			}
+			chainLogger.Debugf("Answering message %x accepted earlier with its receipt", txHash)
+			adm.status, adm.receipt = cb.Status_SUCCESS, receipt
+			return
+		}
+	}
+
+	if configUpdate {
+		chainLogger.Debugf("Preprocessing CONFIG_UPDATE")
+		msg, err = bh.sm.Process(msg)
+		if err != nil {
+			chainLogger.Warningf("Rejecting CONFIG_UPDATE because: %s", err)
+			adm.reason, adm.info = "config_update", fmt.Sprintf("config update rejected: %s", err)
+			return
 		}
 
-		support, ok := bh.sm.GetChain(chdr.ChannelId)
-		if !ok {
-			logger.Warningf("Rejecting broadcast because channel %s was not found", chdr.ChannelId)
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_NOT_FOUND})
+		processed, err = filter.NewMessage(msg)
+		if err != nil {
+			chainLogger.Criticalf("Generated bad transaction after CONFIG_UPDATE processing: %s", err)
+			adm.status, adm.reason, adm.info = cb.Status_INTERNAL_SERVER_ERROR, "internal", "config update processing produced a bad transaction"
+			return
 		}
 
-		logger.Debugf("[channel: %s] Broadcast is filtering message of type %s", chdr.ChannelId, cb.HeaderType_name[chdr.Type])
+		if processed.ChannelHeader.ChannelId == "" {
+			chainLogger.Criticalf("Generated bad transaction after CONFIG_UPDATE processing (empty channel ID)")
+			adm.status, adm.reason, adm.info = cb.Status_INTERNAL_SERVER_ERROR, "internal", "config update processing produced a bad transaction"
+			return
+		}
+	}
+	chdr := processed.ChannelHeader
 
-		// Normal transaction for existing chain
-		_, filterErr := support.Filters().Apply(msg)
+	adm.txHash = string(processed.Hash())
+	adm.txLogger = streamLogger.With(flogging.Fields{
+		flogging.ChannelField: chdr.ChannelId,
+		flogging.TxHashField:  hex.EncodeToString([]byte(adm.txHash)),
+	})
 
-		if filterErr != nil {
-			logger.Warningf("[channel: %s] Rejecting broadcast message because of filter error: %s", chdr.ChannelId, filterErr)
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
+	support, ok := bh.sm.GetChain(chdr.ChannelId)
+	if !ok {
+		adm.txLogger.Warningf("Rejecting broadcast because channel was not found")
+		adm.status, adm.reason = cb.Status_NOT_FOUND, "channel_not_found"
+		adm.info = fmt.Sprintf("channel %s not found", chdr.ChannelId)
+		return
+	}
+
+	adm.txLogger.Debugf("Broadcast is filtering message of type %s", cb.HeaderType_name[chdr.Type])
+
+	// Normal transaction for existing chain
+	_, filterErr := support.Filters().Apply(processed)
+
+	if filterErr != nil {
+		adm.txLogger.Warningf("Rejecting broadcast message because of filter error: %s", filterErr)
+		adm.reason, adm.info = "filtered", filterErr.Error()
+		if rejection, ok := filterErr.(*filter.Rejection); ok {
+			adm.status, adm.reason = rejection.Status, rejection.Reason
+			adm.info = fmt.Sprintf("rejected by filter %s", strings.TrimPrefix(rejection.Rule, "*"))
+			if rejection.Info != "" {
+				adm.info += ": " + rejection.Info
+			}
 		}
+		return
+	}
+
+	// The identity is charged once the filters, among which the signature
+	// filter, have authenticated it as a writer of the chain
+	if ok, wait := bh.limiter.allow("", adm.decoded.SignatureHeader.Creator); !ok {
+		adm.txLogger.Warningf("Rejecting broadcast because the client exceeded its rate limit, it may retry in %s", wait)
+		adm.status, adm.reason, adm.retryAfter = cb.Status_SERVICE_UNAVAILABLE, "rate_limited", wait
+		adm.info = fmt.Sprintf("rate limit exceeded, retry in %s", wait)
+		return
+	}
+
+	quotaKey, ok, wait, info := bh.quotas.acquire(chdr.ChannelId, adm.decoded.SignatureHeader.Creator, support.BroadcastQuota())
+	if !ok {
+		adm.txLogger.Warningf("Rejecting broadcast because the client exceeded its quota: %s", info)
+		adm.status, adm.reason, adm.retryAfter, adm.info = cb.Status_SERVICE_UNAVAILABLE, "quota_exceeded", wait, info
+		return
+	}
+
+	adm.processed, adm.support = processed, support
+	adm.quotas, adm.quotaKey = bh.quotas, quotaKey
+	adm.status, adm.filteredAt = cb.Status_SUCCESS, time.Now()
+}
+
+// authorizeWriter returns whether the creator of the message satisfies the
+// Writers policy of the chain, or else why not. The filters of the chains
+// authorize the messages they order, but a CONFIG_UPDATE is only ordered once
+// it is processed into a config message signed by the orderer, so that its
+// client must be authorized before it is processed.
+func (bh *handlerImpl) authorizeWriter(support Support, msg *filter.Message) (bool, string) {
+	writers := sigfilter.New(policies.ChannelWriters, support.PolicyManager(), bh.audit).(filter.ExplainingRule)
+	action, _, info := writers.Explain(msg)
+	return action != filter.Reject, info
+}
+
+// complete enqueues an admitted message and responds to it, returning true
+// with the error to end the stream with if it should end
+func (bh *handlerImpl) complete(srv ab.AtomicBroadcast_BroadcastServer, adm *admission) (bool, error) {
+	resp, accepted := bh.respond(adm)
+	resp = bh.awaitCommit(adm, resp)
+	if err := srv.Send(resp); err != nil {
+		logger.Warningf("Error sending to stream: %s", err)
+		return true, err
+	}
+	if !accepted {
+		return true, bh.streamError(srv.Context(), adm)
+	}
+	return false, nil
+}
 
-		if !support.Enqueue(msg) {
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE})
+// respond enqueues the message if it was admitted, returning the response to
+// it and whether it was accepted, either now or, if it is a duplicate, earlier
+func (bh *handlerImpl) respond(adm *admission) (*ab.BroadcastResponse, bool) {
+	bh.metrics.receivedMessages.With(bh.channelLabel(adm.chainID)).Add(1)
+	if adm.status != cb.Status_SUCCESS {
+		return bh.reject(adm), false
+	}
+	if adm.receipt != nil {
+		bh.metrics.duplicateMessages.With(adm.chainID).Add(1)
+		return adm.receipt, true
+	}
+	chdr := adm.processed.ChannelHeader
+
+	start := time.Now()
+	bh.latency.Filtered(chdr.ChannelId, adm.txHash, adm.receivedAt, adm.filteredAt)
+	bh.tracer.Received(adm.trace, chdr.ChannelId, adm.txHash, adm.receivedAt, adm.filteredAt)
+	adm.watchCommit()
+	// The slot of the quota of the creator, if any, is held until the
+	// message leaves the queue of the chain and is committed
+	var inFlight <-chan *ab.TransactionStatusResponse
+	var stopInFlight func()
+	if adm.quotaKey != "" {
+		inFlight, stopInFlight = adm.support.WatchCommit([]byte(adm.txHash))
+	}
+	enqueued := adm.support.Enqueue(adm.processed)
+	enqueueTime := time.Since(start)
+	bh.metrics.enqueueDuration.With(chdr.ChannelId).Observe(enqueueTime.Seconds())
+	bh.pipeline.Observe(pipeline.StageQueueWait, enqueueTime)
+	if !enqueued {
+		bh.latency.Forget(adm.txHash)
+		bh.tracer.Forget(adm.txHash)
+		adm.unwatchCommit()
+		if stopInFlight != nil {
+			stopInFlight()
 		}
+		adm.status, adm.reason, adm.info = cb.Status_SERVICE_UNAVAILABLE, "unavailable", fmt.Sprintf("channel %s is not accepting messages", chdr.ChannelId)
+		if pressure := adm.support.Pressure(); pressure.Overloaded {
+			adm.reason, adm.retryAfter, adm.pressure = "overloaded", pressure.RetryAfter(), backpressureInfo(pressure)
+			adm.info = fmt.Sprintf("channel %s is overloaded, retry in %s", chdr.ChannelId, adm.retryAfter)
+		}
+		return bh.reject(adm), false
+	}
+
+	if stopInFlight != nil {
+		adm.releaseQuota = adm.quotas.releaseOnCommit(adm.quotaKey, inFlight, stopInFlight, bh.commitTimeout)
+		adm.quotas, adm.quotaKey = nil, ""
+	}
 
-		if logger.IsEnabledFor(logging.DEBUG) {
-			logger.Debugf("[channel: %s] Broadcast has successfully enqueued message of type %s", chdr.ChannelId, cb.HeaderType_name[chdr.Type])
+	enqueuedAt := time.Now()
+	bh.latency.Enqueued(adm.txHash, enqueuedAt)
+	bh.tracer.Enqueued(adm.txHash, start, enqueuedAt)
+	bh.metrics.enqueuedMessages.With(chdr.ChannelId, cb.HeaderType(chdr.Type).String()).Add(1)
+
+	if adm.txLogger.IsEnabledFor(logging.DEBUG) {
+		adm.txLogger.Debugf("Broadcast has successfully enqueued message of type %s", cb.HeaderType_name[chdr.Type])
+	}
+
+	resp := bh.response(adm, cb.Status_SUCCESS)
+	if err := bh.receipts.Record(resp); err != nil {
+		adm.txLogger.Errorf("Failed to record receipt: %s", err)
+	}
+	return resp, true
+}
+
+// backpressureInfo returns the load of an overloaded chain as told to clients
+func backpressureInfo(pressure backpressure.Status) *ab.Backpressure {
+	return &ab.Backpressure{
+		QueueDepth:         uint32(pressure.QueueDepth),
+		MaxQueueDepth:      uint32(pressure.Config.MaxQueueDepth),
+		AppendLatencyMs:    int64(pressure.AppendLatency / time.Millisecond),
+		MaxAppendLatencyMs: int64(pressure.Config.MaxAppendLatency / time.Millisecond),
+	}
+}
+
+// channelLabel returns the label of the channel in the metrics, which is
+// empty for channels which do not exist, so that clients cannot create
+// arbitrary numbers of series
+func (bh *handlerImpl) channelLabel(chainID string) string {
+	if chainID != "" {
+		if _, ok := bh.sm.GetChain(chainID); ok {
+			return chainID
 		}
+	}
+	return ""
+}
 
-		err = srv.Send(&ab.BroadcastResponse{Status: cb.Status_SUCCESS})
-		if err != nil {
-			logger.Warningf("[channel: %s] Error sending to stream: %s", chdr.ChannelId, err)
-			return err
+// reject counts the rejection of the message for the reason of the admission
+// and returns the response with its status and info. The client is told to wait for
+// the retry delay of the admission, if positive, before sending the message
+// again.
+func (bh *handlerImpl) reject(adm *admission) *ab.BroadcastResponse {
+	bh.metrics.rejectedMessages.With(bh.channelLabel(adm.chainID), adm.reason).Add(1)
+	resp := bh.response(adm, adm.status)
+	resp.Backpressure, resp.Info = adm.pressure, adm.info
+	if adm.retryAfter > 0 {
+		resp.RetryDelayMs = rpcstatus.Retry(adm.retryAfter).RetryDelayMs
+	}
+	return resp
+}
+
+// streamError returns the error with which the stream of the context ends
+// once the message of the admission is rejected
+func (bh *handlerImpl) streamError(ctx context.Context, adm *admission) error {
+	if adm.retryAfter > 0 {
+		return bh.status.StreamErrorAfter(ctx, adm.status, adm.reason, adm.chainID, adm.retryAfter)
+	}
+	return bh.status.StreamError(ctx, adm.status, adm.reason, adm.chainID, nil)
+}
+
+// response returns the response with the given status to the message of the
+// admission as it was received, signed if the handler has a signer. A
+// response which cannot be signed is sent unsigned rather than misreporting
+// the status.
+func (bh *handlerImpl) response(adm *admission, status cb.Status) *ab.BroadcastResponse {
+	resp := &ab.BroadcastResponse{Status: status}
+	if bh.signer != nil {
+		if err := bh.sign(resp, adm); err != nil {
+			logger.Errorf("Failed to sign broadcast response: %s", err)
+			resp = &ab.BroadcastResponse{Status: status}
 		}
 	}
+	return resp
+}
+
+func (bh *handlerImpl) sign(resp *ab.BroadcastResponse, adm *admission) error {
+	txHash, err := adm.receivedHash()
+	if err != nil {
+		return err
+	}
+	ack, err := proto.Marshal(&ab.BroadcastAcknowledgment{
+		Status:    resp.Status,
+		ChannelId: adm.chainID,
+		TxHash:    txHash,
+		Timestamp: util.CreateUtcTimestamp(),
+	})
+	if err != nil {
+		return err
+	}
+	shdr, err := bh.signer.NewSignatureHeader()
+	if err != nil {
+		return err
+	}
+	resp.Acknowledgment = ack
+	resp.SignatureHeader = utils.MarshalOrPanic(shdr)
+	resp.Signature, err = bh.signer.Sign(util.ConcatenateBytes(resp.Acknowledgment, resp.SignatureHeader))
+	return err
 }
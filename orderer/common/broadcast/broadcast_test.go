@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package broadcast
+package broadcast_test
 
 import (
 	"fmt"
@@ -22,14 +22,34 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/receipts"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	"github.com/hyperledger/fabric/orderer/common/sizefilter"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
+	mockbroadcast "github.com/hyperledger/fabric/orderer/mocks/broadcast"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 func init() {
@@ -40,6 +60,7 @@ var systemChain = "systemChain"
 
 type mockB struct {
 	grpc.ServerStream
+	ctx      context.Context
 	recvChan chan *cb.Envelope
 	sendChan chan *ab.BroadcastResponse
 }
@@ -51,6 +72,13 @@ func newMockB() *mockB {
 	}
 }
 
+func (m *mockB) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
 func (m *mockB) Send(br *ab.BroadcastResponse) error {
 	m.sendChan <- br
 	return nil
@@ -68,6 +96,10 @@ type erroneousRecvMockB struct {
 	grpc.ServerStream
 }
 
+func (m *erroneousRecvMockB) Context() context.Context {
+	return context.Background()
+}
+
 func (m *erroneousRecvMockB) Send(br *ab.BroadcastResponse) error {
 	return nil
 }
@@ -83,6 +115,10 @@ type erroneousSendMockB struct {
 	recvVal *cb.Envelope
 }
 
+func (m *erroneousSendMockB) Context() context.Context {
+	return context.Background()
+}
+
 func (m *erroneousSendMockB) Send(br *ab.BroadcastResponse) error {
 	// The point here is to simulate an error other than EOF.
 	// We don't bother to create a new custom error type.
@@ -97,41 +133,10 @@ var RejectRule = filter.Rule(rejectRule{})
 
 type rejectRule struct{}
 
-func (r rejectRule) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
+func (r rejectRule) Apply(message *filter.Message) (filter.Action, filter.Committer) {
 	return filter.Reject, nil
 }
 
-type mockSupportManager struct {
-	chains     map[string]*mockSupport
-	ProcessVal *cb.Envelope
-}
-
-func (mm *mockSupportManager) GetChain(chainID string) (Support, bool) {
-	chain, ok := mm.chains[chainID]
-	return chain, ok
-}
-
-func (mm *mockSupportManager) Process(configTx *cb.Envelope) (*cb.Envelope, error) {
-	if mm.ProcessVal == nil {
-		return nil, fmt.Errorf("Nil result implies error")
-	}
-	return mm.ProcessVal, nil
-}
-
-type mockSupport struct {
-	filters       *filter.RuleSet
-	rejectEnqueue bool
-}
-
-func (ms *mockSupport) Filters() *filter.RuleSet {
-	return ms.filters
-}
-
-// Enqueue sends a message for ordering
-func (ms *mockSupport) Enqueue(env *cb.Envelope) bool {
-	return !ms.rejectEnqueue
-}
-
 func makeConfigMessage(chainID string) *cb.Envelope {
 	payload := &cb.Payload{
 		Data: utils.MarshalOrPanic(&cb.ConfigEnvelope{}),
@@ -161,24 +166,20 @@ func makeMessage(chainID string, data []byte) *cb.Envelope {
 	}
 }
 
-func getMockSupportManager() (*mockSupportManager, *mockSupport) {
+func getMockSupportManager() (*mockbroadcast.SupportManager, *mockbroadcast.Support) {
 	filters := filter.NewRuleSet([]filter.Rule{
 		filter.EmptyRejectRule,
 		filter.AcceptRule,
 	})
-	mm := &mockSupportManager{
-		chains: make(map[string]*mockSupport),
-	}
-	mSysChain := &mockSupport{
-		filters: filters,
+	mSysChain := &mockbroadcast.Support{
+		FiltersVal: filters,
 	}
-	mm.chains[string(systemChain)] = mSysChain
-	return mm, mSysChain
+	return mockbroadcast.NewSupportManager(systemChain, mSysChain), mSysChain
 }
 
 func TestEnqueueFailure(t *testing.T) {
 	mm, mSysChain := getMockSupportManager()
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	done := make(chan struct{})
@@ -195,7 +196,7 @@ func TestEnqueueFailure(t *testing.T) {
 		}
 	}
 
-	mSysChain.rejectEnqueue = true
+	mSysChain.RejectEnqueue = true
 	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
 	reply := <-m.sendChan
 	if reply.Status != cb.Status_SERVICE_UNAVAILABLE {
@@ -209,9 +210,42 @@ func TestEnqueueFailure(t *testing.T) {
 	}
 }
 
+func TestPendingBytesLimit(t *testing.T) {
+	accountant := memory.NewAccountant(1, nil)
+	accountant.Hold(memory.HolderBlockCutter, 1)
+
+	mm, _ := getMockSupportManager()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{Memory: accountant})
+	m := newMockB()
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	select {
+	case <-m.sendChan:
+		t.Fatalf("The message should be held back while the limit is exceeded")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	accountant.Release(memory.HolderBlockCutter, 1)
+	select {
+	case reply := <-m.sendChan:
+		assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	case <-time.After(time.Second):
+		t.Fatalf("The message should be admitted once the pending bytes are released")
+	}
+
+	close(m.recvChan)
+	<-done
+	assert.Equal(t, int64(0), accountant.Held(), "The message should be released once enqueued")
+}
+
 func TestEmptyEnvelope(t *testing.T) {
 	mm, _ := getMockSupportManager()
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	done := make(chan struct{})
@@ -235,7 +269,7 @@ func TestEmptyEnvelope(t *testing.T) {
 
 func TestBadChannelId(t *testing.T) {
 	mm, _ := getMockSupportManager()
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	done := make(chan struct{})
@@ -249,6 +283,7 @@ func TestBadChannelId(t *testing.T) {
 	if reply.Status != cb.Status_NOT_FOUND {
 		t.Fatalf("Should have rejected message to a chain which does not exist")
 	}
+	assert.Equal(t, "channel Wrong chain not found", reply.Info)
 
 	select {
 	case <-done:
@@ -257,10 +292,168 @@ func TestBadChannelId(t *testing.T) {
 	}
 }
 
+func TestStreamErrors(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{Status: rpcstatus.Config{StreamErrors: true, RetryDelay: time.Second}})
+	handle := func(msg *cb.Envelope) (cb.Status, error) {
+		m := newMockB()
+		defer close(m.recvChan)
+		errs := make(chan error)
+		go func() {
+			errs <- bh.Handle(m)
+		}()
+		m.recvChan <- msg
+		reply := <-m.sendChan
+		return reply.Status, <-errs
+	}
+
+	status, err := handle(makeMessage("Wrong chain", []byte("Some bytes")))
+	assert.Equal(t, cb.Status_NOT_FOUND, status, "The status should be sent before the stream ends")
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	assert.Equal(t, []proto.Message{&ab.ErrorInfo{Status: cb.Status_NOT_FOUND, Reason: "channel_not_found", ChannelId: "Wrong chain"}}, rpcstatus.Details(err))
+
+	mSysChain.RejectEnqueue = true
+	status, err = handle(makeMessage(systemChain, []byte("Some bytes")))
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, status)
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Equal(t, []proto.Message{
+		&ab.ErrorInfo{Status: cb.Status_SERVICE_UNAVAILABLE, Reason: "unavailable", ChannelId: systemChain},
+		&ab.RetryInfo{RetryDelayMs: 1000},
+	}, rpcstatus.Details(err))
+}
+
+func TestTooLarge(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	mSysChain.FiltersVal = filter.NewRuleSet([]filter.Rule{
+		sizefilter.MaxBytesRule(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{AbsoluteMaxBytes: 16}}),
+		filter.AcceptRule,
+	})
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeMessage(systemChain, make([]byte, 32))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_REQUEST_ENTITY_TOO_LARGE, reply.Status, "Messages over the limit of the chain should be rejected as too large")
+	assert.Regexp(t, "^rejected by filter sizefilter.maxBytesRule: [0-9]+ byte message payload exceeds maximum allowed 16 bytes$", reply.Info, "The rejection should tell which filter rejected the message and why")
+}
+
+func TestOverloaded(t *testing.T) {
+
+	mm, mSysChain := getMockSupportManager()
+	mSysChain.RejectEnqueue = true
+	mSysChain.PressureVal = backpressure.Status{
+		Overloaded:    true,
+		QueueDepth:    10,
+		AppendLatency: 2 * time.Second,
+		Config:        backpressure.Config{MaxQueueDepth: 10, MaxAppendLatency: 5 * time.Second},
+	}
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{Status: rpcstatus.Config{StreamErrors: true}})
+	m := newMockB()
+	defer close(m.recvChan)
+	errs := make(chan error)
+	go func() {
+		errs <- bh.Handle(m)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+	assert.Equal(t, int64(2000), reply.RetryDelayMs, "Clients should be told to retry once the ledger appends a block")
+	assert.Equal(t, &ab.Backpressure{QueueDepth: 10, MaxQueueDepth: 10, AppendLatencyMs: 2000, MaxAppendLatencyMs: 5000}, reply.Backpressure)
+	assert.Equal(t, []proto.Message{
+		&ab.ErrorInfo{Status: cb.Status_SERVICE_UNAVAILABLE, Reason: "overloaded", ChannelId: systemChain},
+		&ab.RetryInfo{RetryDelayMs: 2000},
+	}, rpcstatus.Details(<-errs))
+}
+
+func TestRateLimited(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{
+		RateLimits: broadcast.RateLimits{PerIdentity: 0.001, Burst: 1},
+		Status:     rpcstatus.Config{StreamErrors: true},
+	})
+	m := newMockB()
+	defer close(m.recvChan)
+	errs := make(chan error)
+	go func() {
+		errs <- bh.Handle(m)
+	}()
+
+	msg := makeMessage(systemChain, []byte("Some bytes"))
+	payload := utils.UnmarshalPayloadOrPanic(msg.Payload)
+	payload.Header.SignatureHeader = utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte("creator")})
+	msg.Payload = utils.MarshalOrPanic(payload)
+
+	m.recvChan <- msg
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	assert.Zero(t, reply.RetryDelayMs)
+
+	m.recvChan <- msg
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status, "The identity should be limited to its burst")
+	assert.InDelta(t, 1000000, reply.RetryDelayMs, 1000, "The client should be told when it may retry")
+	err := <-errs
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	details := rpcstatus.Details(err)
+	assert.Len(t, details, 2)
+	assert.Equal(t, &ab.ErrorInfo{Status: cb.Status_SERVICE_UNAVAILABLE, Reason: "rate_limited", ChannelId: systemChain}, details[0])
+	assert.Equal(t, &ab.RetryInfo{RetryDelayMs: reply.RetryDelayMs}, details[1])
+}
+
+func TestRateLimitedAfterAuthentication(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	accept := mSysChain.FiltersVal
+	mSysChain.FiltersVal = filter.NewRuleSet([]filter.Rule{RejectRule})
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{
+		RateLimits: broadcast.RateLimits{PerIdentity: 0.001, Burst: 1},
+	})
+	msg := makeMessage(systemChain, []byte("Some bytes"))
+	payload := utils.UnmarshalPayloadOrPanic(msg.Payload)
+	payload.Header.SignatureHeader = utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte("creator")})
+	msg.Payload = utils.MarshalOrPanic(payload)
+	send := func() *ab.BroadcastResponse {
+		m := newMockB()
+		defer close(m.recvChan)
+		go bh.Handle(m)
+		m.recvChan <- msg
+		return <-m.sendChan
+	}
+
+	assert.Equal(t, cb.Status_BAD_REQUEST, send().Status)
+	mSysChain.FiltersVal = accept
+	assert.Equal(t, cb.Status_SUCCESS, send().Status, "A message which was not authenticated should not be charged to its identity")
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, send().Status)
+}
+
+func TestTokenPrincipal(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
+	msg := makeMessage(systemChain, []byte("Some bytes"))
+	payload := utils.UnmarshalPayloadOrPanic(msg.Payload)
+	payload.Header.SignatureHeader = utils.MarshalOrPanic(&cb.SignatureHeader{
+		Creator: utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: "SampleOrg"}),
+	})
+	msg.Payload = utils.MarshalOrPanic(payload)
+	send := func(mspID string) *ab.BroadcastResponse {
+		m := newMockB()
+		m.ctx = interceptor.WithPrincipal(context.Background(), &interceptor.Principal{Subject: "app1", MSPID: mspID})
+		defer close(m.recvChan)
+		go bh.Handle(m)
+		m.recvChan <- msg
+		return <-m.sendChan
+	}
+
+	assert.Equal(t, cb.Status_SUCCESS, send("SampleOrg").Status)
+	assert.Equal(t, cb.Status_FORBIDDEN, send("OtherOrg").Status, "A message created by another MSP than that of the token should be forbidden")
+}
+
 func TestGoodConfigUpdate(t *testing.T) {
 	mm, _ := getMockSupportManager()
 	mm.ProcessVal = &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: systemChain})}})}
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -271,9 +464,229 @@ func TestGoodConfigUpdate(t *testing.T) {
 	assert.Equal(t, cb.Status_SUCCESS, reply.Status, "Should have allowed a good CONFIG_UPDATE")
 }
 
+type mockExporter struct {
+	spans chan []*tracing.Span
+}
+
+func (me *mockExporter) Export(spans []*tracing.Span) error {
+	me.spans <- spans
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	exporter := &mockExporter{spans: make(chan []*tracing.Span, 1)}
+	tracer := tracing.NewTracer(tracing.Config{}, exporter)
+
+	mm, mSysChain := getMockSupportManager()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{Tracer: tracer})
+	m := newMockB()
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	m.ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(tracing.Header, traceparent))
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	enqueued := mSysChain.Enqueued()
+	if !assert.Len(t, enqueued, 1) {
+		return
+	}
+
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(enqueued[0].Envelope)}
+	tracer.Appended(block, time.Now(), time.Now())
+	select {
+	case spans := <-exporter.spans:
+		for _, span := range spans {
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID, "The spans should join the trace of the client")
+			if span.Name == tracing.SpanTransaction {
+				assert.Equal(t, "00f067aa0ba902b7", span.ParentID)
+				assert.Equal(t, systemChain, span.Attributes["channel"])
+			}
+		}
+	default:
+		t.Fatalf("The spans of the transaction of a sampled client span should have been exported")
+	}
+}
+
+func makeCreatedMessage(chainID string, creator []byte, extension *ab.OrdererHeaderExtension) *cb.Envelope {
+	chdr := utils.MakeChannelHeader(cb.HeaderType_MESSAGE, 0, chainID, 0)
+	chdr.OrdererExtension = utils.MarshalOrPanic(extension)
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{Creator: creator, Nonce: utils.CreateNonceOrPanic()}),
+	})}
+}
+
+func TestBroadcastQuota(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	mSysChain.BroadcastQuotaVal = &ab.BroadcastQuota{MaxInFlight: 1}
+	mSysChain.TxIndex = txstatus.NewIndex(txstatus.Config{})
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
+
+	// A message whose commit is awaited holds its slot until it is answered
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+	m.recvChan <- makeCreatedMessage(systemChain, []byte("alice"), &ab.OrdererHeaderExtension{AwaitCommit: true})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(mSysChain.Enqueued()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	other := newMockB()
+	go bh.Handle(other)
+	other.recvChan <- makeCreatedMessage(systemChain, []byte("alice"), &ab.OrdererHeaderExtension{})
+	reply := <-other.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status, "The quota of the identity should be shared by its streams")
+	assert.Contains(t, reply.Info, "in flight")
+
+	commitEnqueued(t, mSysChain, 1, 1)
+	assert.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).Status)
+
+	other = newMockB()
+	defer close(other.recvChan)
+	go bh.Handle(other)
+	other.recvChan <- makeCreatedMessage(systemChain, []byte("alice"), &ab.OrdererHeaderExtension{})
+	assert.Equal(t, cb.Status_SUCCESS, (<-other.sendChan).Status, "The slot should be released once the message is committed")
+
+	// A message whose commit is not awaited still holds its slot until it
+	// is committed, after it was answered
+	third := newMockB()
+	go bh.Handle(third)
+	third.recvChan <- makeCreatedMessage(systemChain, []byte("alice"), &ab.OrdererHeaderExtension{})
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, (<-third.sendChan).Status, "The slot should be held until the message leaves the queue")
+
+	commitEnqueued(t, mSysChain, 2, 2)
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		third = newMockB()
+		go bh.Handle(third)
+		third.recvChan <- makeCreatedMessage(systemChain, []byte("alice"), &ab.OrdererHeaderExtension{})
+		status := (<-third.sendChan).Status
+		close(third.recvChan)
+		if status == cb.Status_SUCCESS || time.Now().After(deadline) {
+			assert.Equal(t, cb.Status_SUCCESS, status, "The slot should be released once the message is committed")
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConfigUpdateByNonWriter(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	mm.ProcessVal = &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: systemChain})}})}
+	mSysChain.PolicyManagerVal = &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: fmt.Errorf("not a writer")}}
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeConfigMessage(systemChain)
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_FORBIDDEN, reply.Status, "Should have rejected a CONFIG_UPDATE whose creator is not a writer of the channel")
+	assert.Equal(t, "the message does not satisfy policy /Channel/Writers: not a writer", reply.Info)
+	assert.Empty(t, mSysChain.Enqueued(), "The CONFIG_UPDATE should not have been processed")
+
+	m = newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+	m.recvChan <- makeConfigMessage("New Chain")
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status, "Channel creation requests should be authorized by the consortium, not by the writers of the system channel")
+}
+
+func TestSignedResponses(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	bh := broadcast.NewHandlerImpl(mm, mockcrypto.FakeLocalSigner, broadcast.Config{})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	msg := makeMessage(systemChain, []byte("Some bytes"))
+	m.recvChan <- msg
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+
+	ack := &ab.BroadcastAcknowledgment{}
+	assert.NoError(t, proto.Unmarshal(reply.Acknowledgment, ack))
+	assert.Equal(t, cb.Status_SUCCESS, ack.Status)
+	assert.Equal(t, systemChain, ack.ChannelId)
+	assert.Equal(t, util.ComputeSHA256(utils.MarshalOrPanic(msg)), ack.TxHash)
+	assert.NotNil(t, ack.Timestamp)
+
+	shdr, err := utils.GetSignatureHeader(reply.SignatureHeader)
+	assert.NoError(t, err)
+	assert.Equal(t, mockcrypto.FakeLocalSigner.Identity, shdr.Creator)
+	assert.Equal(t, util.ConcatenateBytes(reply.Acknowledgment, reply.SignatureHeader), reply.Signature,
+		"The mock signer should have signed the acknowledgment and signature header")
+
+	mSysChain.RejectEnqueue = true
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+	assert.NoError(t, proto.Unmarshal(reply.Acknowledgment, ack))
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ack.Status, "Rejections should also be acknowledged")
+}
+
+// counterValue returns the value of the counter of the registry for the
+// label values so far
+func counterValue(registry *metrics.Registry, name string, labelValues ...string) float64 {
+	for _, f := range registry.Gather() {
+		if f.Name != name {
+			continue
+		}
+		for _, s := range f.Series {
+			if assert.ObjectsAreEqual(labelValues, s.LabelValues) {
+				return s.Value
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetrics(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	registry := metrics.NewRegistry()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{Metrics: registry})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	<-m.sendChan
+	assert.Equal(t, float64(1), counterValue(registry, "orderer_broadcast_enqueued_total", systemChain, "MESSAGE"))
+
+	m.recvChan <- makeMessage("Wrong chain", []byte("Some bytes"))
+	<-m.sendChan
+	assert.Equal(t, float64(1), counterValue(registry, "orderer_broadcast_rejected_total", "", "channel_not_found"),
+		"Rejections for unknown channels should be counted without the channel")
+}
+
+func TestReceivedMetrics(t *testing.T) {
+	filters := filter.NewRuleSet([]filter.Rule{RejectRule})
+	mm := mockbroadcast.NewSupportManager(systemChain, &mockbroadcast.Support{FiltersVal: filters})
+	// The handler has its own registry, as the streams of the handlers of
+	// other tests may be open
+	registry := metrics.NewRegistry()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{Metrics: registry})
+	m := newMockB()
+	defer close(m.recvChan)
+	done := make(chan error)
+	go func() {
+		done <- bh.Handle(m)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	<-m.sendChan
+	<-done
+	assert.Equal(t, float64(1), counterValue(registry, "orderer_broadcast_received_total", systemChain))
+	assert.Equal(t, float64(0), counterValue(registry, "orderer_broadcast_streams", "Broadcast"), "The stream should no longer be counted once it ended")
+}
+
 func TestBadConfigUpdate(t *testing.T) {
 	mm, _ := getMockSupportManager()
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -284,7 +697,7 @@ func TestBadConfigUpdate(t *testing.T) {
 }
 
 func TestGracefulShutdown(t *testing.T) {
-	bh := NewHandlerImpl(nil)
+	bh := broadcast.NewHandlerImpl(nil, nil, broadcast.Config{})
 	m := newMockB()
 	close(m.recvChan)
 	assert.NoError(t, bh.Handle(m), "Should exit normally upon EOF")
@@ -292,11 +705,9 @@ func TestGracefulShutdown(t *testing.T) {
 
 func TestRejected(t *testing.T) {
 	filters := filter.NewRuleSet([]filter.Rule{RejectRule})
-	mm := &mockSupportManager{
-		chains: map[string]*mockSupport{string(systemChain): {filters: filters}},
-	}
+	mm := mockbroadcast.NewSupportManager(systemChain, &mockbroadcast.Support{FiltersVal: filters})
 	mm.ProcessVal = &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: systemChain})}})}
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -306,24 +717,25 @@ func TestRejected(t *testing.T) {
 	m.recvChan <- makeConfigMessage(newChannelId)
 	reply := <-m.sendChan
 	assert.Equal(t, cb.Status_BAD_REQUEST, reply.Status, "Should have rejected CONFIG_UPDATE")
+	assert.Equal(t, "rejected by filter broadcast_test.rejectRule", reply.Info, "A filter which does not explain its rejections should still be named")
 }
 
 func TestBadStreamRecv(t *testing.T) {
-	bh := NewHandlerImpl(nil)
+	bh := broadcast.NewHandlerImpl(nil, nil, broadcast.Config{})
 	assert.Error(t, bh.Handle(&erroneousRecvMockB{}), "Should catch unexpected stream error")
 }
 
 func TestBadStreamSend(t *testing.T) {
 	mm, _ := getMockSupportManager()
 	mm.ProcessVal = &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: systemChain})}})}
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := &erroneousSendMockB{recvVal: makeConfigMessage("New Chain")}
 	assert.Error(t, bh.Handle(m), "Should catch unexpected stream error")
 }
 
 func TestMalformedEnvelope(t *testing.T) {
 	mm, _ := getMockSupportManager()
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -335,7 +747,7 @@ func TestMalformedEnvelope(t *testing.T) {
 
 func TestMissingHeader(t *testing.T) {
 	mm, _ := getMockSupportManager()
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -347,7 +759,7 @@ func TestMissingHeader(t *testing.T) {
 
 func TestBadChannelHeader(t *testing.T) {
 	mm, _ := getMockSupportManager()
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -360,7 +772,7 @@ func TestBadChannelHeader(t *testing.T) {
 func TestBadPayloadAfterProcessing(t *testing.T) {
 	mm, _ := getMockSupportManager()
 	mm.ProcessVal = &cb.Envelope{Payload: []byte("foo")}
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -373,7 +785,7 @@ func TestBadPayloadAfterProcessing(t *testing.T) {
 func TestNilHeaderAfterProcessing(t *testing.T) {
 	mm, _ := getMockSupportManager()
 	mm.ProcessVal = &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{})}
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -386,7 +798,7 @@ func TestNilHeaderAfterProcessing(t *testing.T) {
 func TestBadChannelHeaderAfterProcessing(t *testing.T) {
 	mm, _ := getMockSupportManager()
 	mm.ProcessVal = &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{ChannelHeader: []byte("foo")}})}
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -399,7 +811,7 @@ func TestBadChannelHeaderAfterProcessing(t *testing.T) {
 func TestEmptyChannelIDAfterProcessing(t *testing.T) {
 	mm, _ := getMockSupportManager()
 	mm.ProcessVal = &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{})}})}
-	bh := NewHandlerImpl(mm)
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
 	m := newMockB()
 	defer close(m.recvChan)
 	go bh.Handle(m)
@@ -408,3 +820,40 @@ func TestEmptyChannelIDAfterProcessing(t *testing.T) {
 	reply := <-m.sendChan
 	assert.Equal(t, cb.Status_INTERNAL_SERVER_ERROR, reply.Status, "Should respond with internal server error")
 }
+
+func TestReceipts(t *testing.T) {
+	log, err := receipts.Open(receipts.Config{Size: 10, Window: time.Minute})
+	assert.NoError(t, err)
+
+	mm, mSysChain := getMockSupportManager()
+	registry := metrics.NewRegistry()
+	bh := broadcast.NewHandlerImpl(mm, mockcrypto.FakeLocalSigner, broadcast.Config{Receipts: log, Metrics: registry})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	msg := makeMessage(systemChain, []byte("Some bytes"))
+	m.recvChan <- msg
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	assert.Equal(t, reply, log.Lookup(util.ComputeSHA256(utils.MarshalOrPanic(msg))), "The response should have been kept as a receipt")
+
+	m.recvChan <- msg
+	assert.Equal(t, reply, <-m.sendChan, "A duplicate should be answered with its receipt")
+	assert.Len(t, mSysChain.Enqueued(), 1, "A duplicate should not be enqueued again")
+	assert.Equal(t, float64(1), counterValue(registry, "orderer_broadcast_duplicates_total", systemChain))
+
+	mSysChain.RejectEnqueue = true
+	rejected := makeMessage(systemChain, []byte("Other bytes"))
+	m.recvChan <- rejected
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, (<-m.sendChan).Status)
+	assert.Nil(t, log.Lookup(util.ComputeSHA256(utils.MarshalOrPanic(rejected))), "Rejections should not be receipts")
+
+	mSysChain.PolicyManagerVal = &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: fmt.Errorf("not a writer")}}
+	m = newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- msg
+	assert.Equal(t, cb.Status_FORBIDDEN, (<-m.sendChan).Status, "A duplicate from a client which is not a writer should not be answered with its receipt")
+}
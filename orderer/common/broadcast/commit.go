@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// defaultCommitTimeout bounds the wait for the commit of a message if the
+// handler is not configured otherwise
+const defaultCommitTimeout = 30 * time.Second
+
+// watchCommit starts watching for the commit of the admitted message, if its
+// client awaits it. It must be called before the message is enqueued, so
+// that its commit cannot be missed.
+func (adm *admission) watchCommit() {
+	if !adm.processed.Extension.GetAwaitCommit() {
+		return
+	}
+	adm.committed, adm.stopWatching = adm.support.WatchCommit([]byte(adm.txHash))
+}
+
+// unwatchCommit stops watching for the commit of the message, if watched
+func (adm *admission) unwatchCommit() {
+	if adm.stopWatching != nil {
+		adm.stopWatching()
+		adm.committed, adm.stopWatching = nil, nil
+	}
+}
+
+// awaitCommit returns the response to the enqueued message once it is
+// committed, locating it on the chain, if its client awaits it. If the
+// message is not committed within the commit timeout, the client is answered
+// with SERVICE_UNAVAILABLE, and should query the status of the message
+// rather than send it again, as it may still be committed.
+func (bh *handlerImpl) awaitCommit(adm *admission, resp *ab.BroadcastResponse) *ab.BroadcastResponse {
+	if adm.stopWatching == nil {
+		return resp
+	}
+	defer adm.unwatchCommit()
+
+	var done <-chan struct{}
+	if adm.ctx != nil {
+		done = adm.ctx.Done()
+	}
+	timer := bh.clock.NewTimer(bh.commitTimeout)
+	defer timer.Stop()
+	select {
+	case status := <-adm.committed:
+		bh.metrics.commitWaits.With(adm.chainID, "committed").Add(1)
+		if adm.releaseQuota != nil {
+			adm.releaseQuota()
+		}
+		// The response may be kept as a receipt, so it is not modified
+		committedResp := proto.Clone(resp).(*ab.BroadcastResponse)
+		committedResp.Commit = &ab.BroadcastCommit{BlockNumber: status.BlockNumber, TxIndex: status.TxIndex}
+		return committedResp
+	case <-timer.C():
+		bh.metrics.commitWaits.With(adm.chainID, "timeout").Add(1)
+		adm.txLogger.Warningf("Message was enqueued but not committed within %s", bh.commitTimeout)
+		timeoutResp := bh.response(adm, cb.Status_SERVICE_UNAVAILABLE)
+		timeoutResp.Info = fmt.Sprintf("message was enqueued but not committed within %s, query its status rather than sending it again", bh.commitTimeout)
+		return timeoutResp
+	case <-done:
+		// The stream has ended, so the response will not be sent
+		return resp
+	}
+}
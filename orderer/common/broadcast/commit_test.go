@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
+	mockbroadcast "github.com/hyperledger/fabric/orderer/mocks/broadcast"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeAwaitedMessage(chainID string, data []byte) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Data: data,
+		Header: &cb.Header{
+			ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+				ChannelId:        chainID,
+				OrdererExtension: utils.MarshalOrPanic(&ab.OrdererHeaderExtension{AwaitCommit: true}),
+			}),
+		},
+	})}
+}
+
+// commitEnqueued appends a block holding the messages enqueued on the chain
+// once there are count of them
+func commitEnqueued(t *testing.T, support *mockbroadcast.Support, count int, number uint64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(support.Enqueued()) < count {
+		if time.Now().After(deadline) {
+			t.Errorf("Expected %d messages to be enqueued", count)
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	block := cb.NewBlock(number, nil)
+	for _, msg := range support.Enqueued() {
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(msg.Envelope))
+	}
+	support.TxIndex.Appended(block)
+}
+
+func TestAwaitCommit(t *testing.T) {
+	mm, support := getMockSupportManager()
+	support.TxIndex = txstatus.NewIndex(txstatus.Config{})
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeAwaitedMessage(systemChain, []byte("awaited"))
+	go commitEnqueued(t, support, 1, 5)
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	assert.Equal(t, &ab.BroadcastCommit{BlockNumber: 5}, reply.Commit, "The response should locate the committed message")
+
+	m.recvChan <- makeMessage(systemChain, []byte("not awaited"))
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	assert.Nil(t, reply.Commit, "A message whose commit is not awaited should be answered once enqueued")
+}
+
+func TestAwaitCommitTimeout(t *testing.T) {
+	mm, _ := getMockSupportManager()
+	bh := broadcast.NewHandlerImpl(mm, nil, broadcast.Config{CommitTimeout: 10 * time.Millisecond})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeAwaitedMessage(systemChain, []byte("never committed"))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+	assert.Contains(t, reply.Info, "not committed within 10ms")
+
+	m.recvChan <- makeMessage(systemChain, []byte("next"))
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status, "The stream should remain open after a commit timeout")
+}
+
+func TestBatchAwaitCommit(t *testing.T) {
+	mm, support := getMockSupportManager()
+	support.TxIndex = txstatus.NewIndex(txstatus.Config{Size: 10})
+	bh := broadcast.NewParallelHandler(mm, nil, nil, broadcast.Config{})
+	m := newMockBatchB()
+	defer close(m.recvChan)
+	go bh.HandleBatch(m)
+
+	m.recvChan <- &ab.BroadcastBatchRequest{Envelopes: []*cb.Envelope{
+		makeAwaitedMessage(systemChain, []byte("0")),
+		makeMessage(systemChain, []byte("1")),
+		makeAwaitedMessage(systemChain, []byte("2")),
+	}}
+	go commitEnqueued(t, support, 3, 2)
+	resp := <-m.sendChan
+	assert.Equal(t, []cb.Status{cb.Status_SUCCESS, cb.Status_SUCCESS, cb.Status_SUCCESS}, statuses(resp))
+	assert.Equal(t, &ab.BroadcastCommit{BlockNumber: 2}, resp.Responses[0].Commit)
+	assert.Nil(t, resp.Responses[1].Commit)
+	assert.Equal(t, &ab.BroadcastCommit{BlockNumber: 2, TxIndex: 2}, resp.Responses[2].Commit,
+		"The messages of a batch should all be enqueued before their commits are awaited")
+}
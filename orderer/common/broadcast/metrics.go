@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import "github.com/hyperledger/fabric/orderer/common/metrics"
+
+// handlerMetrics are the metrics updated by the handlers
+type handlerMetrics struct {
+	receivedMessages  metrics.Counter
+	enqueuedMessages  metrics.Counter
+	rejectedMessages  metrics.Counter
+	duplicateMessages metrics.Counter
+	enqueueDuration   metrics.Histogram
+	openStreams       metrics.Gauge
+	batchSize         metrics.Histogram
+	commitWaits       metrics.Counter
+}
+
+// newHandlerMetrics creates the metrics of a handler with the provider, or in
+// the default registry if it is nil
+func newHandlerMetrics(provider metrics.Provider) *handlerMetrics {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &handlerMetrics{
+		receivedMessages: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "broadcast",
+			Name:       "received_total",
+			Help:       "The number of messages received, whether enqueued or rejected.",
+			LabelNames: []string{"channel"},
+		}),
+		enqueuedMessages: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "broadcast",
+			Name:       "enqueued_total",
+			Help:       "The number of messages enqueued for ordering.",
+			LabelNames: []string{"channel", "type"},
+		}),
+		rejectedMessages: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "broadcast",
+			Name:       "rejected_total",
+			Help:       "The number of messages rejected, by reason.",
+			LabelNames: []string{"channel", "reason"},
+		}),
+		duplicateMessages: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "broadcast",
+			Name:       "duplicates_total",
+			Help:       "The number of messages answered with the receipt of their earlier acceptance instead of being enqueued again.",
+			LabelNames: []string{"channel"},
+		}),
+		enqueueDuration: provider.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "broadcast",
+			Name:       "enqueue_duration_seconds",
+			Help:       "The time taken to enqueue a message for ordering.",
+			LabelNames: []string{"channel"},
+		}}),
+		openStreams: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "broadcast",
+			Name:       "streams",
+			Help:       "The number of open broadcast streams, by RPC.",
+			LabelNames: []string{"rpc"},
+		}),
+		batchSize: provider.NewHistogram(metrics.HistogramOpts{
+			Opts: metrics.Opts{
+				Namespace: "orderer",
+				Subsystem: "broadcast",
+				Name:      "batch_size",
+				Help:      "The number of messages in each batch received by BroadcastBatch.",
+			},
+			Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+		}),
+		commitWaits: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "broadcast",
+			Name:       "commit_waits_total",
+			Help:       "The number of messages whose commit clients awaited, by whether it was committed in time.",
+			LabelNames: []string{"channel", "outcome"},
+		}),
+	}
+}
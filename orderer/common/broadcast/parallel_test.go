@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	mockbroadcast "github.com/hyperledger/fabric/orderer/mocks/broadcast"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowRule delays the earlier messages, whose data is their index, the
+// longest, so that they are filtered after the later ones, and rejects the
+// message whose index is reject
+type slowRule struct {
+	reject int
+}
+
+func (sr slowRule) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	index, _ := strconv.Atoi(string(message.Payload.Data))
+	time.Sleep(time.Duration(10-index) * 5 * time.Millisecond)
+	if index == sr.reject {
+		return filter.Reject, nil
+	}
+	return filter.Forward, nil
+}
+
+// enqueuedData returns the data of the messages enqueued on the support
+func enqueuedData(support *mockbroadcast.Support) []string {
+	var data []string
+	for _, msg := range support.Enqueued() {
+		data = append(data, string(msg.Payload.Data))
+	}
+	return data
+}
+
+func runParallel(t *testing.T, reject int, accountant *memory.Accountant) (*mockbroadcast.Support, []cb.Status) {
+	support := &mockbroadcast.Support{FiltersVal: filter.NewRuleSet([]filter.Rule{slowRule{reject: reject}, filter.AcceptRule})}
+	verifier := broadcast.NewVerifier(4)
+	defer verifier.Stop()
+	bh := broadcast.NewParallelHandler(mockbroadcast.NewSupportManager(systemChain, support), nil, verifier, broadcast.Config{Memory: accountant})
+
+	m := newMockB()
+	done := make(chan error)
+	go func() {
+		done <- bh.Handle(m)
+	}()
+	go func() {
+		for i := 0; i < 10; i++ {
+			select {
+			case m.recvChan <- makeMessage(systemChain, []byte(fmt.Sprintf("%d", i))):
+			case <-time.After(time.Second):
+				return
+			}
+		}
+		close(m.recvChan)
+	}()
+
+	var statuses []cb.Status
+	for {
+		select {
+		case reply := <-m.sendChan:
+			statuses = append(statuses, reply.Status)
+		case err := <-done:
+			require.NoError(t, err)
+			return support, statuses
+		case <-time.After(5 * time.Second):
+			t.Fatal("The stream should have ended")
+		}
+	}
+}
+
+func TestParallelHandlerPreservesOrder(t *testing.T) {
+	support, statuses := runParallel(t, -1, nil)
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, enqueuedData(support))
+	assert.Len(t, statuses, 10)
+	for _, status := range statuses {
+		assert.Equal(t, cb.Status_SUCCESS, status)
+	}
+}
+
+func TestParallelHandlerRejects(t *testing.T) {
+	accountant := memory.NewAccountant(0, nil)
+	support, statuses := runParallel(t, 3, accountant)
+	assert.Equal(t, []string{"0", "1", "2"}, enqueuedData(support), "No message should be enqueued after a rejection")
+	assert.Equal(t, []cb.Status{cb.Status_SUCCESS, cb.Status_SUCCESS, cb.Status_SUCCESS, cb.Status_BAD_REQUEST}, statuses)
+
+	// The messages received after the rejection are released once the
+	// stream ends
+	for i := 0; accountant.Held() != 0 && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, int64(0), accountant.Held())
+}
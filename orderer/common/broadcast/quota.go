@@ -0,0 +1,147 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// quotas enforces the broadcast quota the config of each chain sets on each
+// identity, across all its connections, so that a client opening many
+// streams does not multiply its share of the queue of the chain. Unlike the
+// rate limits, which are local configuration, the quota of a chain is agreed
+// by its config.
+type quotas struct {
+	clock clock.Clock
+
+	lock       sync.Mutex
+	identities map[string]*identityQuota
+	lastPrune  time.Time
+}
+
+// identityQuota is the use of the quota of a chain by an identity
+type identityQuota struct {
+	inFlight uint32
+	// bucket is nil until the chain limits the rate of the identity, which
+	// may submit bursts of up to burst messages
+	bucket *bucket
+	burst  float64
+}
+
+func newQuotas(clk clock.Clock) *quotas {
+	return &quotas{clock: clk, identities: make(map[string]*identityQuota)}
+}
+
+// acquire takes a slot in flight, and a token at the rate of the quota, for
+// the message created by the identity on the chain, returning the key by
+// which the slot is released. A message is in flight until it is committed,
+// or until it is rejected. If the identity has exhausted its quota, it
+// returns false with why, and how long until it may retry if it exceeds the
+// rate. A chain without a quota, or an identity which is unknown, is not
+// limited.
+func (q *quotas) acquire(chainID string, creator []byte, quota *ab.BroadcastQuota) (string, bool, time.Duration, string) {
+	if quota.GetMaxInFlight() == 0 && quota.GetRate() <= 0 || len(creator) == 0 {
+		return "", true, 0, ""
+	}
+	fingerprint := sha256.Sum256(creator)
+	key := chainID + "/" + string(fingerprint[:])
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	now := q.clock.Now()
+	if now.Sub(q.lastPrune) > pruneInterval {
+		q.prune(now)
+	}
+
+	iq, ok := q.identities[key]
+	if !ok {
+		iq = &identityQuota{}
+		q.identities[key] = iq
+	}
+	if max := quota.GetMaxInFlight(); max > 0 && iq.inFlight >= max {
+		return "", false, 0, fmt.Sprintf("identity has %d messages in flight on channel %s, the most its quota allows", iq.inFlight, chainID)
+	}
+	if rate := quota.GetRate(); rate > 0 {
+		// The rate and burst follow the config of the chain as it changes
+		iq.burst = float64(quota.GetBurst())
+		if iq.burst < 1 {
+			iq.burst = 1
+		}
+		if iq.bucket == nil {
+			iq.bucket = &bucket{tokens: iq.burst, last: now}
+		}
+		b := iq.bucket
+		b.rate = rate
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > iq.burst {
+			b.tokens = iq.burst
+		}
+		b.last = now
+		if b.tokens < 1 {
+			wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+			return "", false, wait, fmt.Sprintf("identity exceeded its quota of %g messages per second on channel %s, retry in %s", rate, chainID, wait)
+		}
+		b.tokens--
+	}
+	iq.inFlight++
+	return key, true, 0, ""
+}
+
+// release releases the slot in flight acquired with the key, if any
+func (q *quotas) release(key string) {
+	if key == "" {
+		return
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if iq, ok := q.identities[key]; ok && iq.inFlight > 0 {
+		iq.inFlight--
+	}
+}
+
+// releaseOnCommit releases the slot acquired with the key once the enqueued
+// message holding it is committed, as told by committed, or after the
+// timeout, as the consenter may drop a message without committing it. stop
+// stops watching for the commit. The function returned releases the slot at
+// once, for callers which learn of the commit first, and may be called any
+// number of times.
+func (q *quotas) releaseOnCommit(key string, committed <-chan *ab.TransactionStatusResponse, stop func(), timeout time.Duration) func() {
+	var once sync.Once
+	release := func() { once.Do(func() { q.release(key) }) }
+	timer := q.clock.NewTimer(timeout)
+	go func() {
+		defer release()
+		defer stop()
+		defer timer.Stop()
+		select {
+		case <-committed:
+		case <-timer.C():
+		}
+	}()
+	return release
+}
+
+// prune discards the identities with nothing in flight whose buckets, if
+// any, have refilled. The lock must be held.
+func (q *quotas) prune(now time.Time) {
+	for key, iq := range q.identities {
+		if iq.inFlight > 0 {
+			continue
+		}
+		if b := iq.bucket; b != nil && b.tokens+now.Sub(b.last).Seconds()*b.rate < iq.burst {
+			continue
+		}
+		delete(q.identities, key)
+	}
+	q.lastPrune = now
+}
@@ -0,0 +1,134 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaInFlight(t *testing.T) {
+	q := newQuotas(clock.Real())
+	quota := &ab.BroadcastQuota{MaxInFlight: 2}
+
+	key, ok, _, _ := q.acquire("foo", []byte("alice"), nil)
+	assert.True(t, ok, "A chain without a quota should not limit identities")
+	assert.Empty(t, key)
+
+	var keys []string
+	for i := 0; i < 2; i++ {
+		key, ok, _, _ := q.acquire("foo", []byte("alice"), quota)
+		assert.True(t, ok)
+		keys = append(keys, key)
+	}
+	_, ok, wait, info := q.acquire("foo", []byte("alice"), quota)
+	assert.False(t, ok, "The identity should be limited to its messages in flight")
+	assert.Zero(t, wait)
+	assert.Contains(t, info, "2 messages in flight")
+
+	_, ok, _, _ = q.acquire("bar", []byte("alice"), quota)
+	assert.True(t, ok, "The quota of each chain should be separate")
+	_, ok, _, _ = q.acquire("foo", []byte("bob"), quota)
+	assert.True(t, ok, "Other identities should not be limited")
+	_, ok, _, _ = q.acquire("foo", nil, quota)
+	assert.True(t, ok, "Unknown identities should not be limited")
+
+	q.release(keys[0])
+	_, ok, _, _ = q.acquire("foo", []byte("alice"), quota)
+	assert.True(t, ok, "A released slot should be available again")
+	q.release("")
+}
+
+func TestQuotaReleaseOnCommit(t *testing.T) {
+	wall := clock.NewManual(time.Unix(0, 0))
+	q := newQuotas(wall)
+	quota := &ab.BroadcastQuota{MaxInFlight: 1}
+	inFlight := func() uint32 {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		var n uint32
+		for _, iq := range q.identities {
+			n += iq.inFlight
+		}
+		return n
+	}
+	waitReleased := func() {
+		deadline := time.Now().Add(5 * time.Second)
+		for inFlight() != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Zero(t, inFlight(), "The slot should have been released")
+	}
+
+	// The slot is released once the message is committed
+	key, ok, _, _ := q.acquire("foo", []byte("alice"), quota)
+	assert.True(t, ok)
+	committed := make(chan *ab.TransactionStatusResponse, 1)
+	stopped := make(chan struct{})
+	q.releaseOnCommit(key, committed, func() { close(stopped) }, time.Minute)
+	_, ok, _, _ = q.acquire("foo", []byte("alice"), quota)
+	assert.False(t, ok, "The slot should be held until the message is committed")
+	committed <- &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED}
+	waitReleased()
+	<-stopped
+
+	// or once the timeout passes if it never is
+	key, ok, _, _ = q.acquire("foo", []byte("alice"), quota)
+	assert.True(t, ok)
+	q.releaseOnCommit(key, nil, func() {}, time.Minute)
+	wall.BlockUntil(1)
+	assert.Equal(t, uint32(1), inFlight())
+	wall.Advance(time.Minute)
+	waitReleased()
+
+	// or at once by a caller which learns of the commit first, without the
+	// slot being released twice
+	key, ok, _, _ = q.acquire("foo", []byte("alice"), quota)
+	assert.True(t, ok)
+	stopped = make(chan struct{})
+	release := q.releaseOnCommit(key, committed, func() { close(stopped) }, time.Minute)
+	release()
+	assert.Zero(t, inFlight())
+	key, ok, _, _ = q.acquire("foo", []byte("alice"), quota)
+	assert.True(t, ok)
+	committed <- &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED}
+	<-stopped
+	assert.Equal(t, uint32(1), inFlight(), "The slot acquired since should still be held")
+	q.release(key)
+}
+
+func TestQuotaRate(t *testing.T) {
+	wall := clock.NewManual(time.Unix(0, 0))
+	q := newQuotas(wall)
+	quota := &ab.BroadcastQuota{Rate: 2, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		key, ok, _, _ := q.acquire("foo", []byte("alice"), quota)
+		assert.True(t, ok, "Bursts should be allowed")
+		q.release(key)
+	}
+	_, ok, wait, info := q.acquire("foo", []byte("alice"), quota)
+	assert.False(t, ok, "The identity should be limited to the rate of the quota")
+	assert.Equal(t, 500*time.Millisecond, wait)
+	assert.Contains(t, info, "2 messages per second")
+
+	wall.Advance(500 * time.Millisecond)
+	key, ok, _, _ := q.acquire("foo", []byte("alice"), quota)
+	assert.True(t, ok, "The bucket should refill over time")
+	q.release(key)
+
+	wall.Advance(time.Hour)
+	q.acquire("foo", nil, quota)
+	q.lock.Lock()
+	q.prune(wall.Now())
+	assert.Empty(t, q.identities, "Identities with nothing in flight and refilled buckets should be pruned")
+	q.lock.Unlock()
+}
@@ -0,0 +1,134 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+)
+
+// pruneInterval is how often the buckets which have refilled, and so no
+// longer carry any state, are discarded
+const pruneInterval = time.Minute
+
+// RateLimits are the sustained rates, in messages per second, at which a
+// single connection, and a single identity across all its connections, may
+// broadcast messages, each of which may send bursts of up to Burst messages.
+// A rate of 0 means no limit.
+type RateLimits struct {
+	PerConnection float64
+	PerIdentity   float64
+	Burst         uint32
+}
+
+// rateLimiter holds a token bucket for each connection and each identity
+// broadcasting messages, so that a single client cannot starve the others of
+// the queues of the chains. A nil rateLimiter enforces no limit.
+type rateLimiter struct {
+	limits RateLimits
+	burst  float64
+	clock  clock.Clock
+
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a rateLimiter enforcing the limits, or returns nil
+// if no limit is set
+func newRateLimiter(limits RateLimits, clk clock.Clock) *rateLimiter {
+	if limits.PerConnection <= 0 && limits.PerIdentity <= 0 {
+		return nil
+	}
+	burst := float64(limits.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limits:  limits,
+		burst:   burst,
+		clock:   clk,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from the buckets of the connection, identified by the
+// address of the client, and of the identity which created the message,
+// reporting whether both had one, or else how long until they do. No token
+// is taken unless both have one, and a connection or identity which is
+// unknown is not limited.
+func (rl *rateLimiter) allow(connection string, creator []byte) (bool, time.Duration) {
+	if rl == nil {
+		return true, 0
+	}
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	now := rl.clock.Now()
+	if now.Sub(rl.lastPrune) > pruneInterval {
+		rl.prune(now)
+	}
+
+	var buckets []*bucket
+	if rl.limits.PerConnection > 0 && connection != "" {
+		buckets = append(buckets, rl.refill("connection/"+connection, rl.limits.PerConnection, now))
+	}
+	if rl.limits.PerIdentity > 0 && len(creator) > 0 {
+		fingerprint := sha256.Sum256(creator)
+		buckets = append(buckets, rl.refill("identity/"+string(fingerprint[:]), rl.limits.PerIdentity, now))
+	}
+
+	var wait time.Duration
+	for _, b := range buckets {
+		if b.tokens < 1 {
+			if w := time.Duration((1 - b.tokens) / b.rate * float64(time.Second)); w > wait {
+				wait = w
+			}
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return true, 0
+}
+
+// refill returns the bucket with the key, refilled at the rate since it was
+// last used
+func (rl *rateLimiter) refill(key string, rate float64, now time.Time) *bucket {
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{rate: rate, tokens: rl.burst, last: now}
+		rl.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > rl.burst {
+		b.tokens = rl.burst
+	}
+	b.last = now
+	return b
+}
+
+func (rl *rateLimiter) prune(now time.Time) {
+	for key, b := range rl.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastPrune = now
+}
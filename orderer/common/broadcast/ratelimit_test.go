@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	assert.Nil(t, newRateLimiter(RateLimits{Burst: 10}, clock.Real()), "No limiter should be created without a rate")
+	ok, _ := (*rateLimiter)(nil).allow("127.0.0.1:5000", []byte("alice"))
+	assert.True(t, ok, "A nil limiter should allow every message")
+
+	wall := clock.NewManual(time.Unix(0, 0))
+	rl := newRateLimiter(RateLimits{PerConnection: 2, PerIdentity: 1, Burst: 2}, wall)
+
+	for i := 0; i < 2; i++ {
+		ok, _ := rl.allow("127.0.0.1:5000", []byte("alice"))
+		assert.True(t, ok, "Bursts should be allowed")
+	}
+	ok, wait := rl.allow("127.0.0.1:5000", []byte("alice"))
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait, "The wait should be that of the slowest bucket")
+
+	ok, wait = rl.allow("127.0.0.1:5001", []byte("alice"))
+	assert.False(t, ok, "The identity should be limited across connections")
+	assert.Equal(t, time.Second, wait)
+	ok, _ = rl.allow("127.0.0.1:5001", []byte("bob"))
+	assert.True(t, ok, "Other identities on other connections should not be limited")
+
+	ok, wait = rl.allow("127.0.0.1:5000", []byte("bob"))
+	assert.False(t, ok, "The connection should be limited regardless of the identity")
+	assert.Equal(t, 500*time.Millisecond, wait)
+	ok, _ = rl.allow("127.0.0.1:5001", []byte("bob"))
+	assert.True(t, ok, "No token should be taken from the identity when the connection is limited")
+
+	wall.Advance(time.Second)
+	ok, _ = rl.allow("127.0.0.1:5000", []byte("alice"))
+	assert.True(t, ok, "Buckets should refill over time")
+
+	ok, _ = rl.allow("", nil)
+	assert.True(t, ok, "Unknown connections and identities should not be limited")
+
+	wall.Advance(time.Hour)
+	rl.allow("", nil)
+	assert.Empty(t, rl.buckets, "Refilled buckets should be pruned")
+}
@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Redirector provides the orderers which messages are redirected to
+type Redirector interface {
+	// Endpoints returns the addresses of the orderers of the channel
+	Endpoints(chainID string) []string
+}
+
+type redirectHandler struct {
+	redirector Redirector
+	status     rpcstatus.Config
+	metrics    *handlerMetrics
+}
+
+// NewRedirectHandler constructs a Handler for nodes which do not order
+// messages. It responds to each message with TEMPORARY_REDIRECT and the
+// endpoints of the orderers of its channel, which clients should broadcast to
+// instead. If streams end with errors, as configured by status, the stream
+// ends after the first redirect with an error carrying the endpoints. The
+// redirects are counted with the metrics created by provider, or in the
+// default registry if it is nil.
+func NewRedirectHandler(redirector Redirector, status rpcstatus.Config, provider metrics.Provider) Handler {
+	return &redirectHandler{redirector: redirector, status: status, metrics: newHandlerMetrics(provider)}
+}
+
+func (rh *redirectHandler) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
+	streamLogger := flogging.WithFields(logger, flogging.Fields{flogging.ClientField: comm.ClientIdentity(srv.Context())})
+	streamLogger.Debugf("Starting new redirecting broadcast loop")
+	for {
+		msg, err := srv.Recv()
+		if err == io.EOF {
+			streamLogger.Debugf("Received EOF, hangup")
+			return nil
+		}
+		if err != nil {
+			streamLogger.Warningf("Error reading from stream: %s", err)
+			return err
+		}
+
+		chdr, err := channelHeader(msg)
+		if err != nil {
+			streamLogger.Warningf("Received malformed message, dropping connection: %s", err)
+			rh.metrics.rejectedMessages.With("", "malformed").Add(1)
+			if err := srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST}); err != nil {
+				return err
+			}
+			return rh.status.StreamError(srv.Context(), cb.Status_BAD_REQUEST, "malformed", "", nil)
+		}
+
+		endpoints := rh.redirector.Endpoints(chdr.ChannelId)
+		streamLogger.Debugf("Redirecting broadcast for channel %s to %v", chdr.ChannelId, endpoints)
+		// Redirects are counted without the channel, which may not exist
+		rh.metrics.rejectedMessages.With("", "redirect").Add(1)
+		if err := srv.Send(&ab.BroadcastResponse{Status: cb.Status_TEMPORARY_REDIRECT, Endpoints: endpoints}); err != nil {
+			streamLogger.Warningf("Error sending to stream: %s", err)
+			return err
+		}
+		// Clients which understand the error the stream ends with are
+		// redirected once, rather than for each message
+		if err := rh.status.StreamError(srv.Context(), cb.Status_TEMPORARY_REDIRECT, "redirect", chdr.ChannelId, endpoints); err != nil {
+			return err
+		}
+	}
+}
+
+func channelHeader(msg *cb.Envelope) (*cb.ChannelHeader, error) {
+	payload, err := utils.UnmarshalPayload(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("missing header")
+	}
+	return utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type mockRedirector map[string][]string
+
+func (mr mockRedirector) Endpoints(chainID string) []string {
+	return mr[chainID]
+}
+
+func TestRedirect(t *testing.T) {
+	registry := metrics.NewRegistry()
+	bh := broadcast.NewRedirectHandler(mockRedirector{systemChain: {"orderer0:7050", "orderer1:7050"}}, rpcstatus.Config{}, registry)
+	m := newMockB()
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_TEMPORARY_REDIRECT, reply.Status)
+	assert.Equal(t, []string{"orderer0:7050", "orderer1:7050"}, reply.Endpoints)
+	assert.Equal(t, float64(1), counterValue(registry, "orderer_broadcast_rejected_total", "", "redirect"))
+
+	m.recvChan <- makeMessage("Wrong chain", []byte("Some bytes"))
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_TEMPORARY_REDIRECT, reply.Status, "The stream should stay open after a redirect")
+	assert.Empty(t, reply.Endpoints)
+
+	close(m.recvChan)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Should have terminated the stream on EOF")
+	}
+}
+
+func TestRedirectMalformed(t *testing.T) {
+	bh := broadcast.NewRedirectHandler(mockRedirector{}, rpcstatus.Config{}, nil)
+	m := newMockB()
+	defer close(m.recvChan)
+	done := make(chan struct{})
+	go func() {
+		bh.Handle(m)
+		close(done)
+	}()
+
+	m.recvChan <- &cb.Envelope{}
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_BAD_REQUEST, reply.Status)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Should have terminated the stream")
+	}
+}
+
+func TestRedirectStreamError(t *testing.T) {
+	bh := broadcast.NewRedirectHandler(mockRedirector{systemChain: {"orderer0:7050"}}, rpcstatus.Config{StreamErrors: true}, nil)
+	m := newMockB()
+	defer close(m.recvChan)
+	errs := make(chan error)
+	go func() {
+		errs <- bh.Handle(m)
+	}()
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_TEMPORARY_REDIRECT, reply.Status)
+	err := <-errs
+	assert.Equal(t, codes.Unavailable, grpc.Code(err), "The stream should end after the redirect")
+	assert.Contains(t, rpcstatus.Details(err), &ab.RedirectInfo{Endpoints: []string{"orderer0:7050"}})
+}
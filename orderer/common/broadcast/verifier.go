@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// Verifier is a bounded pool of workers, shared by the broadcast streams,
+// which checks messages against the filters of their chain, and so verifies
+// their signatures, in parallel. Each stream still enqueues its messages and
+// responds to them in the order in which they were received. Messages of a
+// higher priority class are verified ahead of those queued across the other
+// streams.
+type Verifier struct {
+	size int
+	wg   sync.WaitGroup
+	once sync.Once
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	queues  [priority.Ranks][]func()
+	queued  int
+	stopped bool
+}
+
+// NewVerifier starts a Verifier with the given number of workers, or with
+// GOMAXPROCS workers if workers is not positive
+func NewVerifier(workers int) *Verifier {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	v := &Verifier{size: workers}
+	v.cond = sync.NewCond(&v.lock)
+	v.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go v.run()
+	}
+	return v
+}
+
+func (v *Verifier) run() {
+	defer v.wg.Done()
+	for {
+		v.lock.Lock()
+		for v.queued == 0 && !v.stopped {
+			v.cond.Wait()
+		}
+		if v.stopped {
+			v.lock.Unlock()
+			return
+		}
+		f := v.next()
+		v.lock.Unlock()
+		f()
+	}
+}
+
+// next dequeues the oldest function of the highest class queued, with the
+// lock held
+func (v *Verifier) next() func() {
+	for rank := len(v.queues) - 1; rank >= 0; rank-- {
+		if len(v.queues[rank]) == 0 {
+			continue
+		}
+		f := v.queues[rank][0]
+		v.queues[rank][0] = nil
+		v.queues[rank] = v.queues[rank][1:]
+		v.queued--
+		v.cond.Broadcast()
+		return f
+	}
+	return nil
+}
+
+// Size returns the number of messages which may be queued for verification
+// before submitting another blocks
+func (v *Verifier) Size() int {
+	return v.size
+}
+
+// submit queues the function to be run by a worker on behalf of a message of
+// the class, blocking while the queue is full unless the class is urgent.
+// Once the Verifier is stopped the function is run by the caller, as a stream
+// may still be receiving a message after its handler returned.
+func (v *Verifier) submit(class ab.PriorityClass, f func()) {
+	rank := priority.Rank(class)
+	if rank < 0 {
+		rank = priority.Rank(ab.PriorityClass_NORMAL)
+	}
+	v.lock.Lock()
+	for v.queued >= v.size && !priority.Urgent(class) && !v.stopped {
+		v.cond.Wait()
+	}
+	if v.stopped {
+		v.lock.Unlock()
+		f()
+		return
+	}
+	v.queues[rank] = append(v.queues[rank], f)
+	v.queued++
+	v.cond.Broadcast()
+	v.lock.Unlock()
+}
+
+// Stop stops the workers once they have completed the work queued for them
+func (v *Verifier) Stop() {
+	v.once.Do(func() {
+		v.lock.Lock()
+		v.stopped = true
+		v.cond.Broadcast()
+		v.lock.Unlock()
+		v.wg.Wait()
+		for {
+			v.lock.Lock()
+			f := v.next()
+			v.lock.Unlock()
+			if f == nil {
+				return
+			}
+			f()
+		}
+	})
+}
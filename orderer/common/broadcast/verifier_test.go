@@ -0,0 +1,93 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifier(t *testing.T) {
+	v := NewVerifier(0)
+	assert.Equal(t, runtime.GOMAXPROCS(0), v.Size())
+	v.Stop()
+
+	v = NewVerifier(2)
+	assert.Equal(t, 2, v.Size())
+	var lock sync.Mutex
+	ran := 0
+	for i := 0; i < 10; i++ {
+		v.submit(ab.PriorityClass_NORMAL, func() {
+			lock.Lock()
+			ran++
+			lock.Unlock()
+		})
+	}
+	v.Stop()
+	v.Stop()
+	assert.Equal(t, 10, ran, "Stop should wait for the queued work")
+}
+
+func TestVerifierPriority(t *testing.T) {
+	v := NewVerifier(1)
+	defer v.Stop()
+
+	started, blocked := make(chan struct{}), make(chan struct{})
+	v.submit(ab.PriorityClass_NORMAL, func() {
+		close(started)
+		<-blocked
+	})
+	<-started
+
+	var lock sync.Mutex
+	var ran []ab.PriorityClass
+	var wg sync.WaitGroup
+	for _, class := range []ab.PriorityClass{ab.PriorityClass_NORMAL, ab.PriorityClass_HIGH, ab.PriorityClass_CONTROL} {
+		class := class
+		wg.Add(1)
+		v.submit(class, func() {
+			defer wg.Done()
+			lock.Lock()
+			ran = append(ran, class)
+			lock.Unlock()
+		})
+	}
+	close(blocked)
+	wg.Wait()
+	assert.Equal(t, []ab.PriorityClass{ab.PriorityClass_CONTROL, ab.PriorityClass_HIGH, ab.PriorityClass_NORMAL}, ran,
+		"Urgent messages should be queued beyond the size of the queue, and verified first")
+}
+
+func TestAdmissionClass(t *testing.T) {
+	bh := &handlerImpl{priorities: priority.Config{Entitlements: map[string]ab.PriorityClass{"ops": ab.PriorityClass_CONTROL}}}
+	envelope := func(mspID string, class ab.PriorityClass) *cb.Envelope {
+		chdr := utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0)
+		chdr.OrdererExtension = utils.MarshalOrPanic(&ab.OrdererHeaderExtension{Priority: class})
+		creator := utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: mspID})
+		return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{Creator: creator}),
+		})}
+	}
+
+	adm := &admission{received: envelope("ops", ab.PriorityClass_CONTROL)}
+	assert.Equal(t, ab.PriorityClass_CONTROL, bh.class(adm))
+	decoded, err := adm.decode()
+	assert.NoError(t, err)
+	assert.Equal(t, adm.decoded, decoded, "The message should be decoded once")
+
+	assert.Equal(t, ab.PriorityClass_NORMAL, bh.class(&admission{received: envelope("app", ab.PriorityClass_CONTROL)}),
+		"A message beyond the entitlement of its creator should be queued as NORMAL")
+	assert.Equal(t, ab.PriorityClass_NORMAL, bh.class(&admission{received: &cb.Envelope{Payload: []byte("garbage")}}))
+}
@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package chaos injects controlled failures into the chains named in its
+// configuration: ledger appends which fail, consenters which stall and
+// filters which time out. It lets the recovery and backpressure of the
+// orderer be exercised in test and staging networks, and must never be
+// configured in production.
+package chaos
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/chaos")
+
+// Faults are the failures injected into a chain. The zero value injects none.
+type Faults struct {
+	// AppendErrorAt, if non-zero, is the number of the block whose append
+	// to the ledger fails
+	AppendErrorAt uint64
+	// ConsenterStall delays the hand off of each message to the consenter
+	ConsenterStall time.Duration
+	// FilterTimeout, if positive, holds each message in the filters for
+	// this long, after which it is rejected as if a filter had timed out
+	FilterTimeout time.Duration
+}
+
+// For returns the faults injected into the chain, of those by chain ID
+func For(faults map[string]Faults, chainID string) Faults {
+	f := faults[chainID]
+	if f != (Faults{}) {
+		logger.Warningf("Injecting faults into chain %s: %+v", chainID, f)
+	}
+	return f
+}
+
+// Ledger returns the ledger of the chain, failing the append of block
+// AppendErrorAt
+func (f Faults) Ledger(rw ledger.ReadWriter) ledger.ReadWriter {
+	if f.AppendErrorAt == 0 {
+		return rw
+	}
+	return &faultyLedger{ReadWriter: rw, errorAt: f.AppendErrorAt}
+}
+
+type faultyLedger struct {
+	ledger.ReadWriter
+	errorAt uint64
+}
+
+func (fl *faultyLedger) Append(block *cb.Block) error {
+	if block.Header.Number == fl.errorAt {
+		return fmt.Errorf("injected failure appending block %d", block.Header.Number)
+	}
+	return fl.ReadWriter.Append(block)
+}
+
+// Stall stalls the hand off of a message to the consenter for
+// ConsenterStall
+func (f Faults) Stall() {
+	if f.ConsenterStall > 0 {
+		time.Sleep(f.ConsenterStall)
+	}
+}
+
+// Filters returns the rules, preceded by one timing out after FilterTimeout
+// if it is positive
+func (f Faults) Filters(rules []filter.Rule) []filter.Rule {
+	if f.FilterTimeout <= 0 {
+		return rules
+	}
+	return append([]filter.Rule{timeoutRule(f.FilterTimeout)}, rules...)
+}
+
+type timeoutRule time.Duration
+
+func (tr timeoutRule) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	time.Sleep(time.Duration(tr))
+	return filter.Reject, nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFor(t *testing.T) {
+	assert.Equal(t, Faults{}, For(nil, "foo"), "No faults should be injected by default")
+
+	faults := map[string]Faults{"foo": {AppendErrorAt: 2}}
+	assert.Equal(t, Faults{AppendErrorAt: 2}, For(faults, "foo"))
+	assert.Equal(t, Faults{}, For(faults, "bar"))
+}
+
+func TestLedger(t *testing.T) {
+	rw, err := ramledger.New(10).GetOrCreate("foo")
+	require.NoError(t, err)
+	assert.Equal(t, rw, Faults{}.Ledger(rw), "The ledger should not be wrapped without faults")
+
+	fl := Faults{AppendErrorAt: 1}.Ledger(rw)
+	genesis := cb.NewBlock(0, nil)
+	require.NoError(t, fl.Append(genesis))
+	next := cb.NewBlock(1, genesis.Header.Hash())
+	assert.Error(t, fl.Append(next))
+	assert.Equal(t, uint64(1), fl.Height(), "The failed block should not be appended")
+}
+
+func TestStall(t *testing.T) {
+	start := time.Now()
+	Faults{ConsenterStall: 20 * time.Millisecond}.Stall()
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+func TestFilters(t *testing.T) {
+	rules := []filter.Rule{filter.AcceptRule}
+	assert.Equal(t, rules, Faults{}.Filters(rules))
+
+	rs := filter.NewRuleSet(Faults{FilterTimeout: 20 * time.Millisecond}.Filters(rules))
+	start := time.Now()
+	_, err := rs.Apply(&filter.Message{Envelope: &cb.Envelope{}})
+	assert.Error(t, err, "The message should be rejected once the filter times out")
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package clock abstracts the passage of time, so that the timers of the
+// orderer may be driven by tests and simulations rather than by the wall
+// clock.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and fires timers
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer creates a Timer which fires once d has passed
+	NewTimer(d time.Duration) Timer
+	// After returns a channel on which the time is sent once d has passed
+	After(d time.Duration) <-chan time.Time
+}
+
+// Timer fires once, as a time.Timer does
+type Timer interface {
+	// C returns the channel on which the time is sent when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, and returns false if it has
+	// already fired or been stopped
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (rt realTimer) C() <-chan time.Time {
+	return rt.Timer.C
+}
+
+// Real returns the Clock of the wall clock
+func Real() Clock {
+	return realClock{}
+}
+
+// Manual is a Clock whose time only passes when it is advanced
+type Manual struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*manualTimer
+}
+
+type manualTimer struct {
+	clock    *Manual
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (mt *manualTimer) C() <-chan time.Time {
+	return mt.c
+}
+
+func (mt *manualTimer) Stop() bool {
+	m := mt.clock
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i, w := range m.waiters {
+		if w == mt {
+			m.waiters = append(m.waiters[:i], m.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// NewManual creates a Manual clock set to start
+func NewManual(start time.Time) *Manual {
+	m := &Manual{now: start}
+	m.cond = sync.NewCond(&m.lock)
+	return m
+}
+
+// Now returns the time the clock was last set or advanced to
+func (m *Manual) Now() time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.now
+}
+
+// NewTimer creates a Timer which fires once the clock has been advanced by d
+func (m *Manual) NewTimer(d time.Duration) Timer {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	mt := &manualTimer{clock: m, deadline: m.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		mt.c <- m.now
+		return mt
+	}
+	m.waiters = append(m.waiters, mt)
+	m.cond.Broadcast()
+	return mt
+}
+
+// After returns a channel on which the time is sent once the clock has been
+// advanced by d
+func (m *Manual) After(d time.Duration) <-chan time.Time {
+	return m.NewTimer(d).C()
+}
+
+// Advance moves the clock forward by d, firing the timers which expire by then
+func (m *Manual) Advance(d time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.now = m.now.Add(d)
+	pending := m.waiters[:0]
+	for _, w := range m.waiters {
+		if w.deadline.After(m.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- m.now
+	}
+	m.waiters = pending
+}
+
+// BlockUntil waits until at least n timers are pending, so that the clock is
+// not advanced before the timers which it is meant to fire are started
+func (m *Manual) BlockUntil(n int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for len(m.waiters) < n {
+		m.cond.Wait()
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fired(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestReal(t *testing.T) {
+	before := time.Now()
+	assert.False(t, Real().Now().Before(before))
+	select {
+	case <-Real().After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Fatal("The timer should have fired")
+	}
+}
+
+func TestManual(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	m := NewManual(start)
+	assert.Equal(t, start, m.Now())
+
+	assert.True(t, fired(m.After(0)), "An expired timer should fire at once")
+
+	short := m.After(time.Second)
+	long := m.After(3 * time.Second)
+	m.Advance(500 * time.Millisecond)
+	assert.False(t, fired(short))
+
+	m.Advance(500 * time.Millisecond)
+	assert.True(t, fired(short))
+	assert.False(t, fired(long))
+	assert.Equal(t, start.Add(time.Second), m.Now())
+
+	m.Advance(time.Hour)
+	assert.True(t, fired(long))
+}
+
+func TestBlockUntil(t *testing.T) {
+	m := NewManual(time.Unix(0, 0))
+	started := make(chan (<-chan time.Time))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		started <- m.After(time.Second)
+	}()
+
+	m.BlockUntil(1)
+	m.Advance(time.Second)
+	assert.True(t, fired(<-started), "The clock should only be advanced once the timer is started")
+}
+
+func TestTimerStop(t *testing.T) {
+	m := NewManual(time.Unix(0, 0))
+	stopped := m.NewTimer(time.Second)
+	running := m.NewTimer(time.Second)
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop(), "A timer should only be stopped once")
+
+	m.Advance(time.Second)
+	assert.False(t, fired(stopped.C()), "A stopped timer should not fire")
+	assert.True(t, fired(running.C()))
+	assert.False(t, running.Stop(), "A fired timer should not be stoppable")
+
+	timer := Real().NewTimer(time.Hour)
+	assert.True(t, timer.Stop())
+	assert.False(t, fired(timer.C()))
+}
@@ -0,0 +1,206 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package compat serves version 2 of the AtomicBroadcast API on top of the
+// version 1 handlers, translating the responses of each stream, so that both
+// versions are served by the same code. The requests of both versions share
+// their encoding: the payload of a version 2 Deliver request, signed by the
+// client, is read as is by the version 1 handler, while the fields only
+// defined by version 2 are honored when translating the responses.
+package compat
+
+import (
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	abv2 "github.com/hyperledger/fabric/protos/orderer/v2"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Server serves version 2 of the AtomicBroadcast API with a version 1
+// server
+type Server struct {
+	v1 ab.AtomicBroadcastServer
+}
+
+// NewServer creates a version 2 AtomicBroadcast server translating for the
+// version 1 server
+func NewServer(v1 ab.AtomicBroadcastServer) *Server {
+	return &Server{v1: v1}
+}
+
+// Broadcast serves the stream with the version 1 Broadcast handler. The
+// failure status of a message carries the details of the error with which
+// the stream then ends.
+func (s *Server) Broadcast(srv abv2.AtomicBroadcast_BroadcastServer) error {
+	stream := &broadcastStream{
+		ServerStream: srv,
+		srv:          srv,
+		ctx:          rpcstatus.WithStreamErrors(srv.Context()),
+	}
+	err := s.v1.Broadcast(stream)
+	return stream.flush(err)
+}
+
+// Deliver serves the stream with the version 1 Deliver handler. Blocks are
+// always delivered in batches, and the failure status of a request carries
+// the details of the error with which the stream then ends.
+func (s *Server) Deliver(srv abv2.AtomicBroadcast_DeliverServer) error {
+	stream := &deliverStream{
+		ServerStream: srv,
+		srv:          srv,
+		ctx:          rpcstatus.WithStreamErrors(srv.Context()),
+	}
+	err := s.v1.Deliver(stream)
+	return stream.flush(err)
+}
+
+// status returns the version 2 status, with the details of the error, if any
+func status(s cb.Status, endpoints []string, retryDelayMs int64, err error) *abv2.Status {
+	st := &abv2.Status{Status: s, Endpoints: endpoints, RetryDelayMs: retryDelayMs}
+	for _, detail := range rpcstatus.Details(err) {
+		switch d := detail.(type) {
+		case *ab.ErrorInfo:
+			st.Reason, st.ChannelId = d.Reason, d.ChannelId
+		case *ab.RetryInfo:
+			st.RetryDelayMs = d.RetryDelayMs
+		case *ab.RedirectInfo:
+			st.Endpoints = d.Endpoints
+		}
+	}
+	return st
+}
+
+// broadcastStream presents a version 2 Broadcast stream to the version 1
+// handler. A failure response is held until the handler returns the error
+// describing it, which always follows, as the handlers end the stream after
+// a failure.
+type broadcastStream struct {
+	grpc.ServerStream
+	srv  abv2.AtomicBroadcast_BroadcastServer
+	ctx  context.Context
+	held *ab.BroadcastResponse
+}
+
+func (bs *broadcastStream) Context() context.Context {
+	return bs.ctx
+}
+
+func (bs *broadcastStream) Recv() (*cb.Envelope, error) {
+	return bs.srv.Recv()
+}
+
+func (bs *broadcastStream) Send(resp *ab.BroadcastResponse) error {
+	if err := bs.flush(nil); err != nil {
+		return err
+	}
+	if resp.Status != cb.Status_SUCCESS {
+		bs.held = resp
+		return nil
+	}
+	return bs.srv.Send(translateBroadcast(resp, nil))
+}
+
+// flush sends the held response, if any, with the details of the error the
+// handler returned, which is returned unless sending fails
+func (bs *broadcastStream) flush(err error) error {
+	if bs.held == nil {
+		return err
+	}
+	resp := bs.held
+	bs.held = nil
+	if sendErr := bs.srv.Send(translateBroadcast(resp, err)); sendErr != nil {
+		return sendErr
+	}
+	return err
+}
+
+func translateBroadcast(resp *ab.BroadcastResponse, err error) *abv2.BroadcastResponse {
+	return &abv2.BroadcastResponse{
+		Status:          status(resp.Status, resp.Endpoints, resp.RetryDelayMs, err),
+		Acknowledgment:  resp.Acknowledgment,
+		SignatureHeader: resp.SignatureHeader,
+		Signature:       resp.Signature,
+	}
+}
+
+// deliverStream presents a version 2 Deliver stream to the version 1
+// handler, holding failure responses as broadcastStream does
+type deliverStream struct {
+	grpc.ServerStream
+	srv     abv2.AtomicBroadcast_DeliverServer
+	ctx     context.Context
+	content abv2.SeekInfo_ContentType
+	held    *ab.DeliverResponse
+}
+
+func (ds *deliverStream) Context() context.Context {
+	return ds.ctx
+}
+
+// Recv receives the next request, noting the content it requests. Requests
+// which cannot be decoded are left for the handler to reject.
+func (ds *deliverStream) Recv() (*cb.Envelope, error) {
+	env, err := ds.srv.Recv()
+	if err != nil {
+		return nil, err
+	}
+	ds.content = abv2.SeekInfo_BLOCK
+	if payload, err := utils.UnmarshalPayload(env.Payload); err == nil {
+		seekInfo := &abv2.SeekInfo{}
+		if pool.Unmarshal(payload.Data, seekInfo) == nil {
+			ds.content = seekInfo.Content
+		}
+	}
+	return env, nil
+}
+
+func (ds *deliverStream) Send(resp *ab.DeliverResponse) error {
+	if err := ds.flush(nil); err != nil {
+		return err
+	}
+	switch t := resp.Type.(type) {
+	case *ab.DeliverResponse_Block:
+		return ds.sendBlocks([]*cb.Block{t.Block})
+	case *ab.DeliverResponse_Blocks:
+		return ds.sendBlocks(t.Blocks.Blocks)
+	case *ab.DeliverResponse_Status:
+		if t.Status != cb.Status_SUCCESS {
+			ds.held = resp
+			return nil
+		}
+		return ds.srv.Send(&abv2.DeliverResponse{Type: &abv2.DeliverResponse_Status{Status: status(t.Status, nil, 0, nil)}})
+	}
+	return nil
+}
+
+func (ds *deliverStream) sendBlocks(blocks []*cb.Block) error {
+	if ds.content == abv2.SeekInfo_HEADERS {
+		headers := make([]*cb.Block, len(blocks))
+		for i, block := range blocks {
+			headers[i] = &cb.Block{Header: block.Header, Metadata: block.Metadata}
+		}
+		blocks = headers
+	}
+	return ds.srv.Send(&abv2.DeliverResponse{Type: &abv2.DeliverResponse_Blocks{Blocks: &ab.Blocks{Blocks: blocks}}})
+}
+
+// flush sends the held response, if any, with the details of the error the
+// handler returned, which is returned unless sending fails
+func (ds *deliverStream) flush(err error) error {
+	if ds.held == nil {
+		return err
+	}
+	st := ds.held.GetStatus()
+	ds.held = nil
+	if sendErr := ds.srv.Send(&abv2.DeliverResponse{Type: &abv2.DeliverResponse_Status{Status: status(st, nil, 0, err)}}); sendErr != nil {
+		return sendErr
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compat
+
+import (
+	"io"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	abv2 "github.com/hyperledger/fabric/protos/orderer/v2"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// mockV1 serves each stream with its handler
+type mockV1 struct {
+	broadcast func(ab.AtomicBroadcast_BroadcastServer) error
+	deliver   func(ab.AtomicBroadcast_DeliverServer) error
+}
+
+func (m *mockV1) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	return m.broadcast(srv)
+}
+
+func (m *mockV1) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	return m.deliver(srv)
+}
+
+type mockStream struct {
+	grpc.ServerStream
+	requests []*cb.Envelope
+}
+
+func (ms *mockStream) Context() context.Context {
+	return context.Background()
+}
+
+func (ms *mockStream) Recv() (*cb.Envelope, error) {
+	if len(ms.requests) == 0 {
+		return nil, io.EOF
+	}
+	env := ms.requests[0]
+	ms.requests = ms.requests[1:]
+	return env, nil
+}
+
+type mockBroadcastStream struct {
+	mockStream
+	sent []*abv2.BroadcastResponse
+}
+
+func (ms *mockBroadcastStream) Send(resp *abv2.BroadcastResponse) error {
+	ms.sent = append(ms.sent, resp)
+	return nil
+}
+
+type mockDeliverStream struct {
+	mockStream
+	sent []*abv2.DeliverResponse
+}
+
+func (ms *mockDeliverStream) Send(resp *abv2.DeliverResponse) error {
+	ms.sent = append(ms.sent, resp)
+	return nil
+}
+
+func seekRequest(seekInfo *abv2.SeekInfo) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Data: utils.MarshalOrPanic(seekInfo)})}
+}
+
+func TestBroadcast(t *testing.T) {
+	s := NewServer(&mockV1{broadcast: func(srv ab.AtomicBroadcast_BroadcastServer) error {
+		for {
+			if _, err := srv.Recv(); err != nil {
+				return nil
+			}
+			if err := srv.Send(&ab.BroadcastResponse{Status: cb.Status_SUCCESS, Acknowledgment: []byte("ack")}); err != nil {
+				return err
+			}
+			if err := srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE}); err != nil {
+				return err
+			}
+			return rpcstatus.Config{}.StreamError(srv.Context(), cb.Status_SERVICE_UNAVAILABLE, "unavailable", "foo", nil)
+		}
+	}})
+
+	stream := &mockBroadcastStream{mockStream: mockStream{requests: []*cb.Envelope{{}}}}
+	err := s.Broadcast(stream)
+	assert.Equal(t, codes.Unavailable, grpc.Code(err), "Version 2 streams should end with the error describing the failure")
+	require.Len(t, stream.sent, 2)
+	assert.Equal(t, &abv2.BroadcastResponse{Status: &abv2.Status{Status: cb.Status_SUCCESS}, Acknowledgment: []byte("ack")}, stream.sent[0])
+	assert.Equal(t, &abv2.Status{Status: cb.Status_SERVICE_UNAVAILABLE, Reason: "unavailable", ChannelId: "foo"}, stream.sent[1].Status,
+		"The failure status should carry the details of the error")
+}
+
+func TestBroadcastRedirect(t *testing.T) {
+	s := NewServer(&mockV1{broadcast: func(srv ab.AtomicBroadcast_BroadcastServer) error {
+		endpoints := []string{"orderer0:7050"}
+		srv.Send(&ab.BroadcastResponse{Status: cb.Status_TEMPORARY_REDIRECT, Endpoints: endpoints})
+		return rpcstatus.Config{}.StreamError(srv.Context(), cb.Status_TEMPORARY_REDIRECT, "redirect", "", endpoints)
+	}})
+
+	stream := &mockBroadcastStream{}
+	s.Broadcast(stream)
+	require.Len(t, stream.sent, 1)
+	assert.Equal(t, []string{"orderer0:7050"}, stream.sent[0].Status.Endpoints)
+	assert.Equal(t, "redirect", stream.sent[0].Status.Reason)
+}
+
+func TestBroadcastRetryDelay(t *testing.T) {
+	s := NewServer(&mockV1{broadcast: func(srv ab.AtomicBroadcast_BroadcastServer) error {
+		srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, RetryDelayMs: 250})
+		return nil
+	}})
+
+	stream := &mockBroadcastStream{}
+	s.Broadcast(stream)
+	require.Len(t, stream.sent, 1)
+	assert.Equal(t, int64(250), stream.sent[0].Status.RetryDelayMs, "The retry delay of the response should be kept without error details")
+}
+
+func TestDeliver(t *testing.T) {
+	blocks := []*cb.Block{cb.NewBlock(0, nil), cb.NewBlock(1, nil)}
+	for _, block := range blocks {
+		block.Data.Data = [][]byte{[]byte("tx")}
+	}
+	s := NewServer(&mockV1{deliver: func(srv ab.AtomicBroadcast_DeliverServer) error {
+		for {
+			if _, err := srv.Recv(); err != nil {
+				return nil
+			}
+			srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Block{Block: blocks[0]}})
+			srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Blocks{Blocks: &ab.Blocks{Blocks: blocks}}})
+			srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_SUCCESS}})
+		}
+	}})
+
+	stream := &mockDeliverStream{mockStream: mockStream{requests: []*cb.Envelope{
+		seekRequest(&abv2.SeekInfo{}),
+		seekRequest(&abv2.SeekInfo{Content: abv2.SeekInfo_HEADERS}),
+	}}}
+	require.NoError(t, s.Deliver(stream))
+	require.Len(t, stream.sent, 6)
+	assert.Equal(t, []*cb.Block{blocks[0]}, stream.sent[0].GetBlocks().Blocks, "A single block should be delivered as a batch")
+	assert.Equal(t, blocks, stream.sent[1].GetBlocks().Blocks)
+	assert.Equal(t, cb.Status_SUCCESS, stream.sent[2].GetStatus().Status)
+	for _, block := range stream.sent[4].GetBlocks().Blocks {
+		assert.Nil(t, block.Data, "Only the headers should be delivered")
+		assert.NotNil(t, block.Header)
+	}
+	assert.NotNil(t, blocks[0].Data, "The blocks of the ledger should not be modified")
+}
+
+func TestDeliverFailure(t *testing.T) {
+	s := NewServer(&mockV1{deliver: func(srv ab.AtomicBroadcast_DeliverServer) error {
+		srv.Recv()
+		srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_NOT_FOUND}})
+		return rpcstatus.Config{}.StreamError(srv.Context(), cb.Status_NOT_FOUND, "channel_not_found", "foo", nil)
+	}})
+
+	stream := &mockDeliverStream{mockStream: mockStream{requests: []*cb.Envelope{{Payload: []byte("garbage")}}}}
+	err := s.Deliver(stream)
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	require.Len(t, stream.sent, 1)
+	assert.Equal(t, &abv2.Status{Status: cb.Status_NOT_FOUND, Reason: "channel_not_found", ChannelId: "foo"}, stream.sent[0].GetStatus())
+}
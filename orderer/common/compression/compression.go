@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package compression implements the algorithms by which the blocks of a
+// deliver stream may be compressed, for the orderer which compresses them and
+// the clients which decompress them.
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// DefaultMaxSize is the bound of decompressed data when none is given, the
+// default maximum size of the messages the orderer sends
+const DefaultMaxSize = 100 * 1024 * 1024
+
+// gzipWriters reuse the compression state of gzip, which is costly to
+// allocate for every response
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+
+// Parse returns the algorithm of a name, such as gzip or snappy
+func Parse(name string) (ab.Compression, error) {
+	algorithm, ok := ab.Compression_value[strings.ToUpper(name)]
+	if !ok || ab.Compression(algorithm) == ab.Compression_NONE {
+		return ab.Compression_NONE, fmt.Errorf("unknown compression algorithm %q", name)
+	}
+	return ab.Compression(algorithm), nil
+}
+
+// ParseAll returns the algorithms of the names
+func ParseAll(names []string) ([]ab.Compression, error) {
+	var algorithms []ab.Compression
+	for _, name := range names {
+		algorithm, err := Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		algorithms = append(algorithms, algorithm)
+	}
+	return algorithms, nil
+}
+
+// Compress returns the data compressed with the algorithm
+func Compress(algorithm ab.Compression, data []byte) ([]byte, error) {
+	switch algorithm {
+	case ab.Compression_GZIP:
+		buf := &bytes.Buffer{}
+		w := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(w)
+		w.Reset(buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ab.Compression_SNAPPY:
+		return snappy.Encode(nil, data), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %s", algorithm)
+	}
+}
+
+// Decompress returns the data compressed with the algorithm, failing rather
+// than decompressing more than maxSize bytes, or DefaultMaxSize if maxSize is
+// not positive, so that a small response cannot exhaust the memory of the
+// client
+func Decompress(algorithm ab.Compression, data []byte, maxSize int) ([]byte, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	switch algorithm {
+	case ab.Compression_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		decompressed, err := ioutil.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(decompressed) > maxSize {
+			return nil, fmt.Errorf("decompressed data exceeds %d bytes", maxSize)
+		}
+		return decompressed, nil
+	case ab.Compression_SNAPPY:
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxSize {
+			return nil, fmt.Errorf("decompressed data exceeds %d bytes", maxSize)
+		}
+		return snappy.Decode(nil, data)
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %s", algorithm)
+	}
+}
+
+// Negotiate returns the first of the algorithms accepted by a client which is
+// offered, or NONE if none of them is
+func Negotiate(accepted, offered []ab.Compression) ab.Compression {
+	for _, a := range accepted {
+		for _, o := range offered {
+			if a == o && a != ab.Compression_NONE {
+				return a
+			}
+		}
+	}
+	return ab.Compression_NONE
+}
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	algorithm, err := Parse("gzip")
+	require.NoError(t, err)
+	assert.Equal(t, ab.Compression_GZIP, algorithm)
+
+	algorithms, err := ParseAll([]string{"snappy", "GZIP"})
+	require.NoError(t, err)
+	assert.Equal(t, []ab.Compression{ab.Compression_SNAPPY, ab.Compression_GZIP}, algorithms)
+
+	_, err = Parse("none")
+	assert.Error(t, err)
+	_, err = ParseAll([]string{"gzip", "lz4"})
+	assert.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("block data "), 1000)
+	for _, algorithm := range []ab.Compression{ab.Compression_GZIP, ab.Compression_SNAPPY} {
+		compressed, err := Compress(algorithm, data)
+		require.NoError(t, err, algorithm)
+		assert.True(t, len(compressed) < len(data), algorithm)
+
+		decompressed, err := Decompress(algorithm, compressed, 0)
+		require.NoError(t, err, algorithm)
+		assert.Equal(t, data, decompressed, algorithm)
+
+		_, err = Decompress(algorithm, compressed, len(data)-1)
+		assert.EqualError(t, err, fmt.Sprintf("decompressed data exceeds %d bytes", len(data)-1), algorithm.String())
+		decompressed, err = Decompress(algorithm, compressed, len(data))
+		require.NoError(t, err, algorithm)
+		assert.Equal(t, data, decompressed, algorithm)
+	}
+
+	// The pooled gzip writers are reset between uses
+	first, err := Compress(ab.Compression_GZIP, []byte("first"))
+	require.NoError(t, err)
+	decompressed, err := Decompress(ab.Compression_GZIP, first, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), decompressed)
+
+	_, err = Compress(ab.Compression_NONE, data)
+	assert.Error(t, err)
+	_, err = Decompress(ab.Compression_GZIP, []byte("not gzip"), 0)
+	assert.Error(t, err)
+}
+
+func TestNegotiate(t *testing.T) {
+	offered := []ab.Compression{ab.Compression_GZIP, ab.Compression_SNAPPY}
+	assert.Equal(t, ab.Compression_SNAPPY, Negotiate([]ab.Compression{ab.Compression_SNAPPY, ab.Compression_GZIP}, offered))
+	assert.Equal(t, ab.Compression_GZIP, Negotiate([]ab.Compression{ab.Compression(9), ab.Compression_GZIP}, offered))
+	assert.Equal(t, ab.Compression_NONE, Negotiate(nil, offered))
+	assert.Equal(t, ab.Compression_NONE, Negotiate([]ab.Compression{ab.Compression_GZIP}, nil))
+}
@@ -23,7 +23,6 @@ import (
 	"github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
-	"github.com/hyperledger/fabric/protos/utils"
 )
 
 type configFilter struct {
@@ -53,38 +52,30 @@ func (cc *configCommitter) Isolated() bool {
 	return true
 }
 
-// Apply applies the rule to the given Envelope, replying with the Action to take for the message
-func (cf *configFilter) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
-	msgData, err := utils.UnmarshalPayload(message.Payload)
-	if err != nil {
-		return filter.Forward, nil
-	}
-
-	if msgData.Header == nil /* || msgData.Header.ChannelHeader == nil */ {
-		return filter.Forward, nil
-	}
-
-	chdr, err := utils.UnmarshalChannelHeader(msgData.Header.ChannelHeader)
-	if err != nil {
-		return filter.Forward, nil
-	}
+// Apply applies the rule to the given Message, replying with the Action to take for the message
+func (cf *configFilter) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := cf.Explain(message)
+	return action, committer
+}
 
-	if chdr.Type != int32(cb.HeaderType_CONFIG) {
-		return filter.Forward, nil
+// Explain applies the rule as Apply does, telling why the message is rejected
+func (cf *configFilter) Explain(message *filter.Message) (filter.Action, filter.Committer, string) {
+	if message.Type() != cb.HeaderType_CONFIG {
+		return filter.Forward, nil, ""
 	}
 
-	configEnvelope, err := configtx.UnmarshalConfigEnvelope(msgData.Data)
+	configEnvelope, err := configtx.UnmarshalConfigEnvelope(message.Payload.Data)
 	if err != nil {
-		return filter.Reject, nil
+		return filter.Reject, nil, fmt.Sprintf("malformed config envelope: %s", err)
 	}
 
 	err = cf.configManager.Validate(configEnvelope)
 	if err != nil {
-		return filter.Reject, nil
+		return filter.Reject, nil, fmt.Sprintf("config validation failed: %s", err)
 	}
 
 	return filter.Accept, &configCommitter{
 		manager:        cf.configManager,
 		configEnvelope: configEnvelope,
-	}
+	}, ""
 }
@@ -20,6 +20,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/configtx"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
 	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -29,47 +32,27 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestForwardOpaquePayload(t *testing.T) {
-	cf := NewFilter(&mockconfigtx.Manager{})
-	result, _ := cf.Apply(&cb.Envelope{
-		Payload: []byte("Opaque"),
-	})
-	assert.EqualValues(t, filter.Forward, result, "Should have forwarded opaque message")
-}
-
-func TestForwardNilHeader(t *testing.T) {
-	cf := NewFilter(&mockconfigtx.Manager{})
-	result, _ := cf.Apply(&cb.Envelope{
-		Payload: utils.MarshalOrPanic(&cb.Payload{
-			Header: nil,
-		}),
-	})
-	assert.EqualValues(t, filter.Forward, result, "Should have forwarded message with nil header")
-}
-
-func TestForwardBadHeader(t *testing.T) {
-	cf := NewFilter(&mockconfigtx.Manager{})
-	result, _ := cf.Apply(&cb.Envelope{
-		Payload: utils.MarshalOrPanic(&cb.Payload{
-			Header: &cb.Header{ChannelHeader: []byte("Hello, world!")},
-		}),
-	})
-	assert.EqualValues(t, filter.Forward, result, "Should have forwarded message with bad header")
+func makeMessage(env *cb.Envelope) *filter.Message {
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		panic(err)
+	}
+	return msg
 }
 
 func TestForwardNonConfig(t *testing.T) {
 	cf := NewFilter(&mockconfigtx.Manager{})
-	result, _ := cf.Apply(&cb.Envelope{
+	result, _ := cf.Apply(makeMessage(&cb.Envelope{
 		Payload: utils.MarshalOrPanic(&cb.Payload{
 			Header: &cb.Header{ChannelHeader: []byte{}},
 		}),
-	})
+	}))
 	assert.EqualValues(t, filter.Forward, result, "Should have forwarded message with non-config message")
 }
 
 func TestRejectMalformedData(t *testing.T) {
 	cf := NewFilter(&mockconfigtx.Manager{})
-	result, _ := cf.Apply(&cb.Envelope{
+	result, _ := cf.Apply(makeMessage(&cb.Envelope{
 		Payload: utils.MarshalOrPanic(&cb.Payload{
 			Header: &cb.Header{
 				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
@@ -78,7 +61,7 @@ func TestRejectMalformedData(t *testing.T) {
 			},
 			Data: []byte("Hello, world!"),
 		}),
-	})
+	}))
 	assert.EqualValues(t, filter.Reject, result, "Should have rejected message with malformed payload data")
 }
 
@@ -107,7 +90,7 @@ func TestAcceptGoodConfig(t *testing.T) {
 	configEnvelope := &cb.Envelope{
 		Payload: configBytes,
 	}
-	result, committer := cf.Apply(configEnvelope)
+	result, committer := cf.Apply(makeMessage(configEnvelope))
 	assert.EqualValues(t, filter.Accept, result, "Should have indicated a good config message causes a reconfig")
 	assert.True(t, committer.Isolated(), "Config transactions should be isolated to their own block")
 
@@ -140,7 +123,7 @@ func TestPanicApplyingValidatedConfig(t *testing.T) {
 	configEnvelope := &cb.Envelope{
 		Payload: configBytes,
 	}
-	result, committer := cf.Apply(configEnvelope)
+	result, committer := cf.Apply(makeMessage(configEnvelope))
 
 	assert.EqualValues(t, filter.Accept, result, "Should have indicated a good config message causes a reconfig")
 	assert.True(t, committer.Isolated(), "Config transactions should be isolated to their own block")
@@ -151,9 +134,65 @@ func TestRejectBadConfig(t *testing.T) {
 	cf := NewFilter(&mockconfigtx.Manager{ValidateVal: fmt.Errorf("Error")})
 	config, _ := proto.Marshal(&cb.ConfigEnvelope{})
 	configBytes, _ := proto.Marshal(&cb.Payload{Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG)})}, Data: config})
-	result, _ := cf.Apply(&cb.Envelope{
+	result, _ := cf.Apply(makeMessage(&cb.Envelope{
 		Payload: configBytes,
-	})
+	}))
+
+	assert.EqualValues(t, filter.Reject, result, "Should have rejected bad config message")
+}
+
+func TestExplainBadConfig(t *testing.T) {
+	cf := NewFilter(&mockconfigtx.Manager{ValidateVal: fmt.Errorf("Error")}).(filter.ExplainingRule)
+	config, _ := proto.Marshal(&cb.ConfigEnvelope{})
+	configBytes, _ := proto.Marshal(&cb.Payload{Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG)})}, Data: config})
+	result, _, info := cf.Explain(makeMessage(&cb.Envelope{
+		Payload: configBytes,
+	}))
 
 	assert.EqualValues(t, filter.Reject, result, "Should have rejected bad config message")
+	assert.Equal(t, "config validation failed: Error", info, "Should have told why the config was rejected")
+}
+
+// FuzzConfigFilter filters config messages carrying arbitrary config
+// envelopes against the config of a real chain
+func FuzzConfigFilter(f *testing.F) {
+	genesis := provisional.New(genesisconfig.Load(genesisconfig.SampleInsecureProfile)).GenesisBlock()
+	configTx, err := utils.ExtractEnvelope(genesis, 0)
+	if err != nil {
+		f.Fatal(err)
+	}
+	manager, err := configtx.NewManagerImpl(configTx, configtx.NewInitializer(), nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+	cf := NewFilter(manager)
+
+	f.Add(utils.MarshalOrPanic(manager.ConfigEnvelope()))
+	f.Add(utils.MarshalOrPanic(&cb.ConfigEnvelope{
+		Config: &cb.Config{Sequence: 1},
+		LastUpdate: &cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{
+					ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+						Type: int32(cb.HeaderType_CONFIG_UPDATE),
+					}),
+				},
+				Data: utils.MarshalOrPanic(&cb.ConfigUpdateEnvelope{
+					ConfigUpdate: utils.MarshalOrPanic(&cb.ConfigUpdate{
+						ChannelId: manager.ChainID(),
+						ReadSet:   cb.NewConfigGroup(),
+						WriteSet:  cb.NewConfigGroup(),
+					}),
+				}),
+			}),
+		},
+	}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cf.Apply(makeMessage(&cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG)})},
+				Data:   data,
+			}),
+		}))
+	})
 }
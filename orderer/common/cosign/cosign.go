@@ -0,0 +1,353 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cosign has the blocks of a channel signed by as many of its
+// orderers as its block validation policy requires. The orderer writing a
+// block signs it, and if its signature alone does not satisfy the policy,
+// asks the other orderers of the channel for theirs, which each only gives
+// once it has produced the same header itself, as the orderers of a Kafka
+// based channel all do. Verify checks the signatures of a block on behalf of
+// its consumers.
+package cosign
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var logger = logging.MustGetLogger("orderer/common/cosign")
+
+const (
+	// defaultTimeout bounds the collection of the signatures of a block if
+	// no timeout is configured
+	defaultTimeout = 5 * time.Second
+	// window is the number of recent headers of each chain remembered
+	window = 256
+)
+
+// Sign returns the signature of the signer on the header of a block, as it
+// is found in the SIGNATURES metadata of the block
+func Sign(signer crypto.LocalSigner, header *cb.BlockHeader) (*cb.MetadataSignature, error) {
+	sigHeader, err := signer.NewSignatureHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signature header: %s", err)
+	}
+	signature := &cb.MetadataSignature{SignatureHeader: utils.MarshalOrPanic(sigHeader)}
+	// The value of the SIGNATURES metadata is always empty, the signatures
+	// are only about the header
+	signature.Signature, err = signer.Sign(util.ConcatenateBytes(nil, signature.SignatureHeader, header.Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign header: %s", err)
+	}
+	return signature, nil
+}
+
+// SignedData returns the signatures of the block, from its SIGNATURES
+// metadata, for the evaluation of its block validation policy
+func SignedData(block *cb.Block) ([]*cb.SignedData, error) {
+	metadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return nil, fmt.Errorf("malformed signatures of block %d: %s", block.GetHeader().GetNumber(), err)
+	}
+	var signedData []*cb.SignedData
+	for _, signature := range metadata.Signatures {
+		sigHeader, err := utils.GetSignatureHeader(signature.SignatureHeader)
+		if err != nil {
+			return nil, fmt.Errorf("malformed signature header of block %d: %s", block.Header.Number, err)
+		}
+		signedData = append(signedData, &cb.SignedData{
+			Identity:  sigHeader.Creator,
+			Data:      util.ConcatenateBytes(metadata.Value, signature.SignatureHeader, block.Header.Bytes()),
+			Signature: signature.Signature,
+		})
+	}
+	return signedData, nil
+}
+
+// Verify returns nil if the block holds the data its header commits to and
+// its signatures satisfy the block validation policy of its channel, as
+// returned by the policy manager of the channel for
+// policies.BlockValidation
+func Verify(block *cb.Block, policy policies.Policy) error {
+	if block.GetHeader() == nil || block.Data == nil {
+		return fmt.Errorf("block is missing its header or data")
+	}
+	if !bytes.Equal(block.Data.Hash(), block.Header.DataHash) {
+		return fmt.Errorf("data hash of block %d does not match its data", block.Header.Number)
+	}
+	signedData, err := SignedData(block)
+	if err != nil {
+		return err
+	}
+	if err := policy.Evaluate(signedData); err != nil {
+		return fmt.Errorf("signatures of block %d do not satisfy the block validation policy: %s", block.Header.Number, err)
+	}
+	return nil
+}
+
+// IdentityValidator validates the identities of the orderers of a chain, as
+// identity.Validator does
+type IdentityValidator interface {
+	// Validate deserializes the identity and validates it against the MSPs
+	// of the chain, returning the identity if it is valid
+	Validate(serializedIdentity []byte) (msp.Identity, error)
+}
+
+// Config is the configuration of the collection of the signatures of other
+// orderers
+type Config struct {
+	// Enabled is whether orderers cosign blocks, which they otherwise
+	// neither collect nor give signatures for
+	Enabled bool
+	// TLS, if set, is the configuration of the TLS connections to the other
+	// orderers, which are otherwise insecure
+	TLS *tls.Config
+	// Timeout bounds the time spent collecting the signatures of a block,
+	// and that spent by the orderer waiting to produce a block it is asked
+	// to sign, five seconds if zero
+	Timeout time.Duration
+	// Self holds the addresses of the orderer itself, which it does not ask
+	// for its signature
+	Self []string
+}
+
+// Cosigner remembers the headers of the blocks produced by the orderer, for
+// which it gives its signature, and collects the signatures of the other
+// orderers on them. A nil Cosigner, of an orderer not cosigning blocks, does
+// neither.
+type Cosigner struct {
+	config Config
+
+	lock     sync.Mutex
+	produced map[string]map[uint64][]byte
+	changed  chan struct{}
+	conns    map[string]*grpc.ClientConn
+}
+
+// New creates a Cosigner, or returns nil if cosigning is not enabled
+func New(config Config) *Cosigner {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	return &Cosigner{
+		config:   config,
+		produced: make(map[string]map[uint64][]byte),
+		changed:  make(chan struct{}),
+		conns:    make(map[string]*grpc.ClientConn),
+	}
+}
+
+// Produced records the header of a block produced by the orderer
+func (c *Cosigner) Produced(chainID string, header *cb.BlockHeader) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	headers, ok := c.produced[chainID]
+	if !ok {
+		headers = make(map[uint64][]byte)
+		c.produced[chainID] = headers
+	}
+	headers[header.Number] = header.Bytes()
+	if header.Number >= window {
+		delete(headers, header.Number-window)
+	}
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// header returns the header of the block of the chain produced by the
+// orderer, waiting for it to be produced until the context ends
+func (c *Cosigner) header(ctx context.Context, chainID string, number uint64) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("cosigning is not enabled")
+	}
+	for {
+		c.lock.Lock()
+		header, ok := c.produced[chainID][number]
+		changed := c.changed
+		c.lock.Unlock()
+		if ok {
+			return header, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// timeout returns the bound of the time spent collecting, or giving, the
+// signatures of a block
+func (c *Cosigner) timeout() time.Duration {
+	if c == nil {
+		return defaultTimeout
+	}
+	return c.config.Timeout
+}
+
+// Collect adds the signatures of the orderers at the addresses to the block,
+// asking each in parallel but the orderer itself, until the signatures of the
+// block satisfy the policy. Each signature is only added once the validator
+// has validated the identity of its creator, and that identity has verified
+// it, so that an orderer cannot spoil the block with a signature which is not
+// its own. The block is left with the signatures collected if they never
+// satisfy the policy.
+func (c *Cosigner) Collect(chainID string, block *cb.Block, policy policies.Policy, validator IdentityValidator, addresses []string) error {
+	if c == nil {
+		return nil
+	}
+	addresses = c.others(addresses)
+	metadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return fmt.Errorf("malformed signatures of block %d: %s", block.Header.Number, err)
+	}
+	signedData, err := SignedData(block)
+	if err != nil {
+		return err
+	}
+	if policy.Evaluate(signedData) == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	signatures := make(chan *cb.MetadataSignature, len(addresses))
+	for _, address := range addresses {
+		go func(address string) {
+			signature, err := c.request(ctx, address, &ab.CosignRequest{ChannelId: chainID, Header: block.Header})
+			if err != nil {
+				logger.Warningf("[channel: %s] Orderer %s did not cosign block %d: %s", chainID, address, block.Header.Number, err)
+			}
+			signatures <- signature
+		}(address)
+	}
+
+	signers := make(map[string]bool)
+	for _, sd := range signedData {
+		signers[string(sd.Identity)] = true
+	}
+	for range addresses {
+		signature := <-signatures
+		if signature == nil {
+			continue
+		}
+		sigHeader, err := utils.GetSignatureHeader(signature.SignatureHeader)
+		if err != nil || signers[string(sigHeader.Creator)] {
+			continue
+		}
+		sd := &cb.SignedData{
+			Identity:  sigHeader.Creator,
+			Data:      util.ConcatenateBytes(metadata.Value, signature.SignatureHeader, block.Header.Bytes()),
+			Signature: signature.Signature,
+		}
+		if err := verifySignature(validator, sd); err != nil {
+			logger.Warningf("[channel: %s] Discarding signature of block %d: %s", chainID, block.Header.Number, err)
+			continue
+		}
+		signers[string(sigHeader.Creator)] = true
+		metadata.Signatures = append(metadata.Signatures, signature)
+		block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+		signedData = append(signedData, sd)
+		if policy.Evaluate(signedData) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("the %d signatures collected for block %d do not satisfy the block validation policy", len(metadata.Signatures), block.Header.Number)
+}
+
+// verifySignature checks that the signature is that of its creator, whose
+// identity must be valid
+func verifySignature(validator IdentityValidator, sd *cb.SignedData) error {
+	id, err := validator.Validate(sd.Identity)
+	if err != nil {
+		return err
+	}
+	if err := id.Verify(sd.Data, sd.Signature); err != nil {
+		return fmt.Errorf("signature of %s does not verify: %s", id.GetMSPIdentifier(), err)
+	}
+	return nil
+}
+
+// others returns the addresses which are not those of the orderer itself
+func (c *Cosigner) others(addresses []string) []string {
+	var others []string
+	for _, address := range addresses {
+		self := false
+		for _, s := range c.config.Self {
+			if address == s {
+				self = true
+				break
+			}
+		}
+		if !self {
+			others = append(others, address)
+		}
+	}
+	return others
+}
+
+// request asks the orderer at the address for its signature
+func (c *Cosigner) request(ctx context.Context, address string, req *ab.CosignRequest) (*cb.MetadataSignature, error) {
+	conn, err := c.conn(address)
+	if err != nil {
+		return nil, err
+	}
+	return ab.NewBlockCosignerClient(conn).Cosign(ctx, req)
+}
+
+// conn returns the connection to the orderer at the address, which is
+// established in the background on first use
+func (c *Cosigner) conn(address string) (*grpc.ClientConn, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if conn, ok := c.conns[address]; ok {
+		return conn, nil
+	}
+	opt := grpc.WithInsecure()
+	if c.config.TLS != nil {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(c.config.TLS))
+	}
+	conn, err := grpc.Dial(address, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %s", address, err)
+	}
+	c.conns[address] = conn
+	return conn, nil
+}
+
+// Close closes the connections to the other orderers
+func (c *Cosigner) Close() {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for address, conn := range c.conns {
+		conn.Close()
+		delete(c.conns, address)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cosign
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// signedBy is a policy satisfied by the valid signatures of the mock signers
+// of Required of the identities
+type signedBy struct {
+	Required   int
+	Identities []string
+}
+
+func (sb signedBy) Evaluate(signatureSet []*cb.SignedData) error {
+	signers := make(map[string]bool)
+	for _, sd := range signatureSet {
+		if !bytes.Equal(sd.Signature, sd.Data) {
+			continue
+		}
+		for _, identity := range sb.Identities {
+			if string(sd.Identity) == identity {
+				signers[identity] = true
+			}
+		}
+	}
+	if len(signers) < sb.Required {
+		return fmt.Errorf("signed by %d of the %d identities required", len(signers), sb.Required)
+	}
+	return nil
+}
+
+// acceptAny is a policy satisfied by the signatures of two identities,
+// whether they verify or not
+type acceptAny struct{}
+
+func (acceptAny) Evaluate(signatureSet []*cb.SignedData) error {
+	if len(signatureSet) < 2 {
+		return fmt.Errorf("signed by %d identities", len(signatureSet))
+	}
+	return nil
+}
+
+func signer(identity string) *mockcrypto.LocalSigner {
+	return &mockcrypto.LocalSigner{Identity: []byte(identity)}
+}
+
+// newBlock creates a block signed by the identity
+func newBlock(t *testing.T, number uint64, identity string) *cb.Block {
+	block := cb.NewBlock(number, []byte("previous"))
+	block.Data.Data = [][]byte{[]byte("tx")}
+	block.Header.DataHash = block.Data.Hash()
+	signature, err := Sign(signer(identity), block.Header)
+	require.NoError(t, err)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&cb.Metadata{
+		Signatures: []*cb.MetadataSignature{signature},
+	})
+	return block
+}
+
+func TestVerify(t *testing.T) {
+	block := newBlock(t, 1, "orderer1")
+	assert.NoError(t, Verify(block, signedBy{1, []string{"orderer1"}}))
+	assert.Error(t, Verify(block, signedBy{1, []string{"orderer2"}}))
+	assert.Error(t, Verify(block, signedBy{2, []string{"orderer1", "orderer2"}}))
+
+	signedData, err := SignedData(block)
+	require.NoError(t, err)
+	require.Len(t, signedData, 1)
+	assert.Equal(t, []byte("orderer1"), signedData[0].Identity)
+
+	block.Data.Data = [][]byte{[]byte("tampered")}
+	assert.EqualError(t, Verify(block, signedBy{1, []string{"orderer1"}}), "data hash of block 1 does not match its data")
+
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = []byte("garbage")
+	_, err = SignedData(block)
+	assert.Error(t, err)
+}
+
+// badSigner signs as the identity with signatures which do not verify
+type badSigner struct {
+	*mockcrypto.LocalSigner
+}
+
+func (bs badSigner) Sign(msg []byte) ([]byte, error) {
+	return []byte("bogus"), nil
+}
+
+func testCertPool(t *testing.T) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(testCert(t, "Org1"))
+	return pool
+}
+
+func testKeyPair(t *testing.T, name string) tls.Certificate {
+	dir := filepath.Join("..", "..", "..", "core", "comm", "testdata", "certs")
+	keyPair, err := tls.LoadX509KeyPair(filepath.Join(dir, name+"-cert.pem"), filepath.Join(dir, name+"-key.pem"))
+	require.NoError(t, err)
+	return keyPair
+}
+
+// clientTLS returns the TLS configuration of the connections of an orderer
+// to the others. The test certificates carry no subject alternative names,
+// so only the client certificate is verified.
+func clientTLS(t *testing.T) *tls.Config {
+	return &tls.Config{
+		Certificates:       []tls.Certificate{testKeyPair(t, "Org1-client1")},
+		InsecureSkipVerify: true,
+	}
+}
+
+// startOrderer serves the BlockCosigner service of an orderer signing with
+// the signer, returning its address
+func startOrderer(t *testing.T, signer crypto.LocalSigner, cosigner *Cosigner, support Support) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{testKeyPair(t, "Org1-server1")},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    testCertPool(t),
+	})))
+	ab.RegisterBlockCosignerServer(server, NewServer(cosigner, support, signer))
+	go server.Serve(lis)
+	return lis.Addr().String()
+}
+
+// emptyChain returns the support of an orderer whose ledger of the chain foo
+// has yet to be written to
+func emptyChain(t *testing.T) Support {
+	rl, err := ramledger.New(10).GetOrCreate("foo")
+	require.NoError(t, err)
+	return mockSupport{"foo": {reader: rl, policy: &mockpolicies.Policy{}}}
+}
+
+func validator() IdentityValidator {
+	return identity.NewValidator(mockMSPs{}, identity.DefaultCacheSize)
+}
+
+func TestCollect(t *testing.T) {
+	block := newBlock(t, 1, "orderer1")
+	policy := signedBy{2, []string{"orderer1", "orderer2", "orderer3"}}
+
+	cosigner2 := New(Config{Enabled: true})
+	cosigner3 := New(Config{Enabled: true})
+	addresses := []string{
+		startOrderer(t, signer("orderer2"), cosigner2, emptyChain(t)),
+		startOrderer(t, signer("orderer3"), cosigner3, emptyChain(t)),
+	}
+
+	collector := New(Config{Enabled: true, Timeout: 5 * time.Second, TLS: clientTLS(t)})
+	defer collector.Close()
+	// Only the third orderer has produced the block yet
+	cosigner3.Produced("foo", block.Header)
+	require.NoError(t, collector.Collect("foo", block, policy, validator(), addresses))
+	assert.NoError(t, Verify(block, policy))
+	signedData, err := SignedData(block)
+	require.NoError(t, err)
+	assert.Len(t, signedData, 2, "Signatures should only be collected until the policy is satisfied")
+
+	other := newBlock(t, 2, "orderer1")
+	cosigner2.Produced("foo", cb.NewBlock(2, []byte("fork")).Header)
+	collector = New(Config{Enabled: true, Timeout: 100 * time.Millisecond, TLS: clientTLS(t)})
+	defer collector.Close()
+	assert.Error(t, collector.Collect("foo", other, policy, validator(), addresses), "Orderers should not cosign blocks they did not produce")
+	assert.Error(t, Verify(other, policy))
+
+	assert.NoError(t, (*Cosigner)(nil).Collect("foo", other, policy, validator(), addresses), "A nil cosigner should not collect")
+	assert.Nil(t, New(Config{}), "Cosigning should be disabled by default")
+}
+
+func TestCollectSkipsSelf(t *testing.T) {
+	block := newBlock(t, 1, "orderer1")
+	policy := signedBy{2, []string{"orderer1", "orderer2"}}
+	cosigner2 := New(Config{Enabled: true})
+	cosigner2.Produced("foo", block.Header)
+	address := startOrderer(t, signer("orderer2"), cosigner2, emptyChain(t))
+
+	// The orderer at the address is taken for the collector itself
+	collector := New(Config{Enabled: true, Timeout: 100 * time.Millisecond, Self: []string{address}})
+	defer collector.Close()
+	assert.Error(t, collector.Collect("foo", block, policy, validator(), []string{address}), "The orderer should not ask itself for its signature")
+	assert.Empty(t, collector.conns, "The orderer should not connect to itself")
+}
+
+func TestCollectVerifiesSignatures(t *testing.T) {
+	block := newBlock(t, 1, "orderer1")
+	// The policy counts the signature of orderer2 regardless of whether it
+	// verifies, so that the collector alone must discard it
+	policy := acceptAny{}
+	cosigner2 := New(Config{Enabled: true})
+	cosigner2.Produced("foo", block.Header)
+	address := startOrderer(t, badSigner{signer("orderer2")}, cosigner2, emptyChain(t))
+
+	collector := New(Config{Enabled: true, Timeout: 5 * time.Second, TLS: clientTLS(t)})
+	defer collector.Close()
+	assert.Error(t, collector.Collect("foo", block, policy, validator(), []string{address}))
+	signedData, err := SignedData(block)
+	require.NoError(t, err)
+	assert.Len(t, signedData, 1, "A signature which does not verify should not be added to the block")
+}
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New(Config{}), "Blocks should not be cosigned unless enabled")
+	cosigner := New(Config{Enabled: true})
+	require.NotNil(t, cosigner)
+	assert.Equal(t, defaultTimeout, cosigner.config.Timeout)
+}
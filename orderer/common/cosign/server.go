@@ -0,0 +1,133 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cosign
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ChainSupport is the subset of the resources of a chain the server uses
+type ChainSupport interface {
+	// Reader returns the ledger of the chain
+	Reader() ledger.Reader
+
+	// PolicyManager returns the policy manager of the chain, whose block
+	// validation policy the orderers asking for signatures must satisfy
+	PolicyManager() policies.Manager
+
+	// IdentityValidator returns the validator for identities issued by the
+	// MSPs of the chain
+	IdentityValidator() *identity.Validator
+}
+
+// Support looks up the chains of the orderer
+type Support interface {
+	// GetChain returns the chain with the ID, if the orderer has it
+	GetChain(chainID string) (ChainSupport, bool)
+}
+
+// Server implements the BlockCosigner service, signing the headers the
+// orderer produced itself
+type Server struct {
+	cosigner *Cosigner
+	support  Support
+	signer   crypto.LocalSigner
+}
+
+// NewServer creates a BlockCosigner server signing with the signer the
+// headers recorded by the cosigner, or found on the ledgers of the chains
+func NewServer(cosigner *Cosigner, support Support, signer crypto.LocalSigner) *Server {
+	return &Server{cosigner: cosigner, support: support, signer: signer}
+}
+
+// Cosign signs the header of the request for an orderer of the channel once
+// the orderer has produced the same header. It waits for the header until
+// the request is cancelled or the timeout of the cosigner expires, and only
+// for the headers of blocks within the window of recent headers remembered
+// beyond the height of the ledger.
+func (s *Server) Cosign(ctx context.Context, req *ab.CosignRequest) (*cb.MetadataSignature, error) {
+	if req.Header == nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "the header to sign is required")
+	}
+	chain, ok := s.support.GetChain(req.ChannelId)
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "channel %s not found", req.ChannelId)
+	}
+	if err := s.authorize(ctx, req.ChannelId, chain); err != nil {
+		return nil, err
+	}
+
+	var produced []byte
+	reader := chain.Reader()
+	if block := s.written(reader, req.Header.Number); block != nil {
+		produced = block.Header.Bytes()
+	} else {
+		if height := reader.Height(); req.Header.Number >= height+window {
+			return nil, grpc.Errorf(codes.OutOfRange, "block %d of channel %s is too far beyond the height %d of the ledger", req.Header.Number, req.ChannelId, height)
+		}
+		ctx, cancel := context.WithTimeout(ctx, s.cosigner.timeout())
+		defer cancel()
+		var err error
+		if produced, err = s.cosigner.header(ctx, req.ChannelId, req.Header.Number); err != nil {
+			return nil, grpc.Errorf(codes.Unavailable, "block %d of channel %s was not produced: %s", req.Header.Number, req.ChannelId, err)
+		}
+	}
+	if !bytes.Equal(produced, req.Header.Bytes()) {
+		logger.Warningf("[channel: %s] Refusing to cosign block %d, which differs from the block produced", req.ChannelId, req.Header.Number)
+		return nil, grpc.Errorf(codes.FailedPrecondition, "block %d of channel %s differs from the block produced", req.Header.Number, req.ChannelId)
+	}
+
+	signature, err := Sign(s.signer, req.Header)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "%s", err)
+	}
+	return signature, nil
+}
+
+// authorize checks that the client is an orderer of the chain, whose TLS
+// client certificate is issued by one of the MSPs of the chain to an identity
+// satisfying its block validation policy
+func (s *Server) authorize(ctx context.Context, chainID string, chain ChainSupport) error {
+	cert := comm.ExtractCertificateFromContext(ctx)
+	if cert == nil {
+		return grpc.Errorf(codes.Unauthenticated, "cosign requests require the TLS client certificate of an orderer")
+	}
+	serializedIdentity, _, err := chain.IdentityValidator().Certificate(cert)
+	if err == nil {
+		policy, _ := chain.PolicyManager().GetPolicy(policies.BlockValidation)
+		if evaluator, ok := policy.(policies.IdentityEvaluator); ok {
+			err = evaluator.EvaluateIdentities([][]byte{serializedIdentity})
+		} else {
+			err = fmt.Errorf("policy %s cannot evaluate identities", policies.BlockValidation)
+		}
+	}
+	if err != nil {
+		logger.Warningf("[channel: %s] Refusing to cosign for %s: %s", chainID, cert.Subject.CommonName, err)
+		return grpc.Errorf(codes.PermissionDenied, "not authorized to request the signatures of channel %s", chainID)
+	}
+	return nil
+}
+
+// written returns the block if it is already on the ledger
+func (s *Server) written(reader ledger.Reader, number uint64) *cb.Block {
+	if number >= reader.Height() {
+		return nil
+	}
+	return ledger.GetBlock(reader, number)
+}
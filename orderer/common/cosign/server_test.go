@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cosign
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// mockIdentity is a valid identity of the orderer MSP, whose signatures are
+// those of the mock signers, equal to the data they sign
+type mockIdentity struct {
+	msp.Identity
+}
+
+func (mi *mockIdentity) GetMSPIdentifier() string {
+	return "OrdererOrg"
+}
+
+func (mi *mockIdentity) Validate() error {
+	return nil
+}
+
+func (mi *mockIdentity) Verify(msg []byte, sig []byte) error {
+	if string(msg) != string(sig) {
+		return fmt.Errorf("bad signature")
+	}
+	return nil
+}
+
+type mockManager struct {
+	msp.MSPManager
+}
+
+func (mm *mockManager) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	return &mockIdentity{}, nil
+}
+
+func (mm *mockManager) GetMSPs() (map[string]msp.MSP, error) {
+	return map[string]msp.MSP{"OrdererOrg": nil}, nil
+}
+
+type mockMSPs struct{}
+
+func (mm mockMSPs) MSPManager() msp.MSPManager {
+	return &mockManager{}
+}
+
+func (mm mockMSPs) Sequence() uint64 {
+	return 0
+}
+
+type mockChain struct {
+	reader ledger.Reader
+	policy *mockpolicies.Policy
+}
+
+func (mc *mockChain) Reader() ledger.Reader {
+	return mc.reader
+}
+
+func (mc *mockChain) PolicyManager() policies.Manager {
+	return &mockpolicies.Manager{Policy: mc.policy}
+}
+
+func (mc *mockChain) IdentityValidator() *identity.Validator {
+	return identity.NewValidator(mockMSPs{}, identity.DefaultCacheSize)
+}
+
+func testCert(t *testing.T, name string) *x509.Certificate {
+	certPEM, err := ioutil.ReadFile(filepath.Join("..", "..", "..", "core", "comm", "testdata", "certs", name+"-cert.pem"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+// ordererContext returns the context of a request of an orderer
+// authenticated by its TLS client certificate
+func ordererContext(t *testing.T) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 7050},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{testCert(t, "Org1-client1")}}},
+	})
+}
+
+type mockSupport map[string]*mockChain
+
+func (ms mockSupport) GetChain(chainID string) (ChainSupport, bool) {
+	chain, ok := ms[chainID]
+	if !ok {
+		return nil, false
+	}
+	return chain, true
+}
+
+func TestCosign(t *testing.T) {
+	rl, err := ramledger.New(10).GetOrCreate("foo")
+	require.NoError(t, err)
+	genesis := cb.NewBlock(0, nil)
+	require.NoError(t, rl.Append(genesis))
+	cosigner := New(Config{Enabled: true, Timeout: 100 * time.Millisecond})
+	chain := &mockChain{reader: rl, policy: &mockpolicies.Policy{}}
+	s := NewServer(cosigner, mockSupport{"foo": chain}, signer("orderer2"))
+	orderer := ordererContext(t)
+
+	_, err = s.Cosign(orderer, &ab.CosignRequest{ChannelId: "foo"})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	_, err = s.Cosign(orderer, &ab.CosignRequest{ChannelId: "bar", Header: genesis.Header})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+
+	_, err = s.Cosign(context.Background(), &ab.CosignRequest{ChannelId: "foo", Header: genesis.Header})
+	assert.Equal(t, codes.Unauthenticated, grpc.Code(err), "Clients without a TLS client certificate should not be given signatures")
+	chain.policy.Err = fmt.Errorf("not an orderer")
+	_, err = s.Cosign(orderer, &ab.CosignRequest{ChannelId: "foo", Header: genesis.Header})
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err), "Clients which do not satisfy the block validation policy should not be given signatures")
+	chain.policy.Err = nil
+
+	signature, err := s.Cosign(orderer, &ab.CosignRequest{ChannelId: "foo", Header: genesis.Header})
+	require.NoError(t, err, "Blocks on the ledger should be cosigned")
+	block := cb.NewBlock(0, nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&cb.Metadata{Signatures: []*cb.MetadataSignature{signature}})
+	signedData, err := SignedData(block)
+	require.NoError(t, err)
+	assert.NoError(t, signedBy{1, []string{"orderer2"}}.Evaluate(signedData))
+
+	_, err = s.Cosign(orderer, &ab.CosignRequest{ChannelId: "foo", Header: cb.NewBlock(0, []byte("fork")).Header})
+	assert.Equal(t, codes.FailedPrecondition, grpc.Code(err))
+
+	next := cb.NewBlock(1, genesis.Header.Hash())
+	_, err = s.Cosign(orderer, &ab.CosignRequest{ChannelId: "foo", Header: next.Header})
+	assert.Equal(t, codes.Unavailable, grpc.Code(err), "Blocks not produced before the timeout should not be cosigned")
+
+	_, err = s.Cosign(orderer, &ab.CosignRequest{ChannelId: "foo", Header: cb.NewBlock(window+1, nil).Header})
+	assert.Equal(t, codes.OutOfRange, grpc.Code(err), "Blocks too far beyond the ledger should not be waited for")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cosigner.Produced("foo", next.Header)
+	}()
+	_, err = s.Cosign(orderer, &ab.CosignRequest{ChannelId: "foo", Header: next.Header})
+	assert.NoError(t, err, "Blocks should be cosigned once produced")
+}
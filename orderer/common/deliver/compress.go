@@ -0,0 +1,31 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// sendCompressedBlocksReply sends the blocks as a single response, compressed
+// with the algorithm. It returns the size of the response, which is what the
+// stream is charged for.
+func sendCompressedBlocksReply(srv ab.AtomicBroadcast_DeliverServer, algorithm ab.Compression, blocks []*cb.Block) (int, error) {
+	data, err := proto.Marshal(&ab.Blocks{Blocks: blocks})
+	if err != nil {
+		return 0, err
+	}
+	if data, err = compression.Compress(algorithm, data); err != nil {
+		return 0, err
+	}
+	resp := &ab.DeliverResponse{
+		Type: &ab.DeliverResponse_CompressedBlocks{CompressedBlocks: &ab.CompressedBlocks{Compression: algorithm, Data: data}},
+	}
+	return proto.Size(resp), srv.Send(resp)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedBlocks(t *testing.T) {
+	ds := initializeDeliverHandler(Config{Compression: []ab.Compression{ab.Compression_GZIP, ab.Compression_SNAPPY}})
+
+	m := newMockD()
+	defer close(m.recvChan)
+	go ds.Handle(m)
+
+	// The first algorithm accepted by the client which is offered is chosen
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{
+		Start:       seekSpecified(0),
+		Stop:        seekSpecified(ledgerSize - 1),
+		Behavior:    ab.SeekInfo_BLOCK_UNTIL_READY,
+		Compression: []ab.Compression{ab.Compression(9), ab.Compression_SNAPPY, ab.Compression_GZIP},
+	})
+	for i := 0; i < ledgerSize; i++ {
+		select {
+		case deliverReply := <-m.sendChan:
+			compressed := deliverReply.GetCompressedBlocks()
+			require.NotNil(t, compressed, "Expected compressed blocks, got %v", deliverReply)
+			assert.Equal(t, ab.Compression_SNAPPY, compressed.Compression)
+			data, err := compression.Decompress(compressed.Compression, compressed.Data, 0)
+			require.NoError(t, err)
+			blocks := &ab.Blocks{}
+			require.NoError(t, proto.Unmarshal(data, blocks))
+			require.Len(t, blocks.Blocks, 1)
+			assert.Equal(t, uint64(i), blocks.Blocks[0].Header.Number)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for block %d", i)
+		}
+	}
+	assert.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).GetStatus())
+
+	// Clients which accept none of the algorithms offered, and filtered
+	// blocks, are sent uncompressed blocks
+	for _, seekInfo := range []*ab.SeekInfo{
+		{Start: seekSpecified(0), Stop: seekSpecified(0), Compression: []ab.Compression{ab.Compression(9)}},
+		{Start: seekSpecified(0), Stop: seekSpecified(0), Compression: []ab.Compression{ab.Compression_GZIP}, Filtered: true},
+	} {
+		m.recvChan <- makeSeek(systemChainID, seekInfo)
+		select {
+		case deliverReply := <-m.sendChan:
+			assert.Nil(t, deliverReply.GetCompressedBlocks())
+			assert.True(t, deliverReply.GetBlock() != nil || deliverReply.GetFilteredBlock() != nil, "Expected a block, got %v", deliverReply)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for block")
+		}
+		assert.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).GetStatus())
+	}
+}
+
+func TestCompressionDisabled(t *testing.T) {
+	ds := initializeDeliverHandler(Config{})
+
+	m := newMockD()
+	defer close(m.recvChan)
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(0), Stop: seekSpecified(0), Compression: []ab.Compression{ab.Compression_GZIP}})
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.NotNil(t, deliverReply.GetBlock(), "Compression should be disabled by default, got %v", deliverReply)
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for block")
+	}
+}
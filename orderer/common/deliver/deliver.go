@@ -17,18 +17,34 @@ limitations under the License.
 package deliver
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
 	"io"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/compression"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
 	"github.com/hyperledger/fabric/orderer/common/sigfilter"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/op/go-logging"
-
-	"github.com/golang/protobuf/proto"
-	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
 )
 
 var logger = logging.MustGetLogger("orderer/common/deliver")
@@ -56,91 +72,281 @@ type Support interface {
 
 	// Errored returns a channel which closes when the backing consenter has errored
 	Errored() <-chan struct{}
+
+	// IdentityValidator returns the validator for identities issued by the
+	// MSPs of the chain, which identifies the clients of the gateway
+	IdentityValidator() *identity.Validator
+}
+
+// Config is the configuration of the handlers created by NewHandlerImpl. Its
+// zero value neither batches, compresses, limits nor times the blocks sent,
+// sends no heartbeats, ends failed streams with an OK status, and records no
+// request denied.
+type Config struct {
+	// RevalidationInterval, if positive, is how often the authorization of a
+	// stream is re-evaluated, whether it is waiting for or sending blocks
+	RevalidationInterval time.Duration
+	// MaxBatchBytes, if positive, bounds the responses in which the blocks
+	// already available are sent to the clients which accept batches
+	MaxBatchBytes int
+	// RequireTLSBinding is whether seek requests must be bound to the TLS
+	// client certificate of their stream
+	RequireTLSBinding bool
+	// Limits are the limits on the number of streams and their bandwidth
+	Limits Limits
+	// HeartbeatInterval, if positive, is how long a stream waits for blocks
+	// before sending a heartbeat to the clients which asked for them
+	HeartbeatInterval time.Duration
+	// Compression are the algorithms by which the blocks of the streams are
+	// offered to be compressed
+	Compression []ab.Compression
+	// Pipeline, if set, times the sends of blocks
+	Pipeline *pipeline.Timers
+	// Status configures the errors with which failed streams end
+	Status rpcstatus.Config
+	// Audit, if set, records the requests denied
+	Audit *audit.Trail
+	// Metrics, if set, creates the metrics of the handler instead of the
+	// default registry
+	Metrics metrics.Provider
 }
 
 type deliverServer struct {
-	sm SupportManager
+	sm                   SupportManager
+	revalidationInterval time.Duration
+	maxBatchBytes        int
+	requireTLSBinding    bool
+	limits               Limits
+	streams              *streamLimiter
+	heartbeatInterval    time.Duration
+	compression          []ab.Compression
+	pipeline             *pipeline.Timers
+	status               rpcstatus.Config
+	audit                *audit.Trail
+	metrics              *handlerMetrics
+	clock                clock.Clock
 }
 
-// NewHandlerImpl creates an implementation of the Handler interface
-func NewHandlerImpl(sm SupportManager) Handler {
+// NewHandlerImpl creates an implementation of the Handler interface. The
+// authorization of a stream is re-evaluated whenever the channel config
+// changes and, if the configured revalidation interval is positive, at that
+// interval while the stream waits for or is sent blocks, so that clients whose
+// certificates have been revoked or have expired since they connected are
+// disconnected. The handler enforces the configured limits on the number of
+// streams and their bandwidth, sends heartbeats at the configured interval,
+// and offers to compress blocks by the configured algorithms.
+func NewHandlerImpl(sm SupportManager, conf Config) Handler {
 	return &deliverServer{
-		sm: sm,
+		sm:                   sm,
+		revalidationInterval: conf.RevalidationInterval,
+		maxBatchBytes:        conf.MaxBatchBytes,
+		requireTLSBinding:    conf.RequireTLSBinding,
+		limits:               conf.Limits,
+		streams:              newStreamLimiter(conf.Limits),
+		heartbeatInterval:    conf.HeartbeatInterval,
+		compression:          conf.Compression,
+		pipeline:             conf.Pipeline,
+		status:               conf.Status,
+		audit:                conf.Audit,
+		metrics:              newHandlerMetrics(conf.Metrics),
+		clock:                clock.Real(),
+	}
+}
+
+// authorized evaluates the channel readers policy, which validates the
+// requester's identity against the CRLs of the current channel config, and
+// the validator of the chain tells why an invalid identity is rejected. The
+// requests of a client authenticated by a bearer token must be signed by an
+// identity of the MSP of its token. The requests of the gateway, which are
+// not signed, are authorized with the identity of the TLS client certificate
+// of the gateway client.
+func (ds *deliverServer) authorized(ctx context.Context, chain Support, msg *filter.Message) bool {
+	if cert, ok := ctx.Value(gatewayCertKey{}).(*x509.Certificate); ok {
+		return ds.certificateAuthorized(chain, cert)
+	}
+	if principal, ok := interceptor.PrincipalFromContext(ctx); ok {
+		if err := principal.Creates(msg.SignatureHeader.Creator); err != nil {
+			logger.Warningf("Rejecting deliver request of %s: %s", principal, err)
+			ds.audit.Record(audit.Event{
+				Type:    audit.AccessDenied,
+				Subject: principal.String(),
+				Action:  policies.ChannelReaders,
+				Detail:  err.Error(),
+			})
+			return false
+		}
 	}
+	sf := sigfilter.NewValidating(policies.ChannelReaders, chain.PolicyManager(), chain.IdentityValidator(), ds.audit)
+	result, _ := sf.Apply(msg)
+	return result == filter.Forward
+}
+
+// certificateAuthorized evaluates the channel readers policy with the
+// identity of the certificate, as issued by one of the MSPs of the chain
+func (ds *deliverServer) certificateAuthorized(chain Support, cert *x509.Certificate) bool {
+	var mspID string
+	var serializedIdentity []byte
+	var id msp.Identity
+	err := fmt.Errorf("the client presented no certificate")
+	if cert != nil {
+		serializedIdentity, id, err = chain.IdentityValidator().Certificate(cert)
+	}
+	if err == nil {
+		mspID = id.GetMSPIdentifier()
+		policy, _ := chain.PolicyManager().GetPolicy(policies.ChannelReaders)
+		if evaluator, ok := policy.(policies.IdentityEvaluator); ok {
+			err = evaluator.EvaluateIdentities([][]byte{serializedIdentity})
+		} else {
+			err = fmt.Errorf("policy %s cannot evaluate identities", policies.ChannelReaders)
+		}
+	}
+	if err == nil {
+		return true
+	}
+
+	logger.Warningf("Rejecting gateway request which does not satisfy policy %s: %s", policies.ChannelReaders, err)
+	ds.audit.Record(audit.Event{
+		Type:    audit.AccessDenied,
+		Subject: mspID,
+		Action:  policies.ChannelReaders,
+		Detail:  err.Error(),
+	})
+	return false
+}
+
+// boundToStream returns whether the seek request may be served on the
+// stream. A request whose channel header carries the hash of a TLS client
+// certificate is bound to the streams established with that certificate, so
+// that a request captured from one connection cannot be replayed on another.
+// Requests which are not bound are served unless the handler requires it.
+func (ds *deliverServer) boundToStream(ctx context.Context, chdr *cb.ChannelHeader) bool {
+	if len(chdr.TlsCertHash) == 0 {
+		return !ds.requireTLSBinding
+	}
+	cert := comm.ExtractCertificateFromContext(ctx)
+	if cert == nil {
+		return false
+	}
+	hash := sha256.Sum256(cert.Raw)
+	return bytes.Equal(hash[:], chdr.TlsCertHash)
 }
 
 func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
-	logger.Debugf("Starting new deliver loop")
+	streamLogger := flogging.WithFields(logger, flogging.Fields{flogging.ClientField: comm.ClientIdentity(srv.Context())})
+	streamLogger.Debugf("Starting new deliver loop")
+
+	release, reason := ds.streams.acquire(srv.Context())
+	if reason != "" {
+		streamLogger.Warningf("Rejecting deliver stream: %s", reason)
+		return ds.sendFailure(srv, cb.Status_SERVICE_UNAVAILABLE, reason, "")
+	}
+	defer release()
+	pacer := newPacer(ds.limits.BytesPerSecond, ds.clock)
+
+	// revalidate fires once per revalidation interval, and is rearmed each
+	// time the stream is revalidated
+	var revalidate <-chan time.Time
+	rearm := func() {}
+	if ds.revalidationInterval > 0 {
+		rearm = func() { revalidate = ds.clock.After(ds.revalidationInterval) }
+		rearm()
+	}
+	// endSeek counts the end of the seek request being served, if any
+	endSeek := func() {}
+	defer func() { endSeek() }()
+
 	for {
-		logger.Debugf("Attempting to read seek info message")
+		streamLogger.Debugf("Attempting to read seek info message")
 		envelope, err := srv.Recv()
 		if err == io.EOF {
-			logger.Debugf("Received EOF, hangup")
+			streamLogger.Debugf("Received EOF, hangup")
+			ds.metrics.streamsEnded.With("", "client_closed").Add(1)
 			return nil
 		}
 
 		if err != nil {
-			logger.Warningf("Error reading from stream: %s", err)
+			streamLogger.Warningf("Error reading from stream: %s", err)
+			ds.metrics.streamsEnded.With("", "recv_error").Add(1)
 			return err
 		}
 
-		payload, err := utils.UnmarshalPayload(envelope.Payload)
+		msg, err := filter.NewMessage(envelope)
 		if err != nil {
-			logger.Warningf("Received an envelope with no payload: %s", err)
-			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+			streamLogger.Warningf("Received malformed envelope: %s", err)
+			return ds.sendFailure(srv, cb.Status_BAD_REQUEST, "malformed", "")
 		}
+		payload, chdr := msg.Payload, msg.ChannelHeader
 
-		if payload.Header == nil {
-			logger.Warningf("Malformed envelope received with bad header")
-			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
-		}
-
-		chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
-		if err != nil {
-			logger.Warningf("Failed to unmarshal channel header: %s", err)
-			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
-		}
+		chainLogger := streamLogger.With(flogging.Fields{flogging.ChannelField: chdr.ChannelId})
 
 		chain, ok := ds.sm.GetChain(chdr.ChannelId)
 		if !ok {
 			// Note, we log this at DEBUG because SDKs will poll waiting for channels to be created
 			// So we would expect our log to be somewhat flooded with these
-			logger.Debugf("Rejecting deliver because channel %s not found", chdr.ChannelId)
-			return sendStatusReply(srv, cb.Status_NOT_FOUND)
+			chainLogger.Debugf("Rejecting deliver because channel not found")
+			return ds.sendFailure(srv, cb.Status_NOT_FOUND, "channel_not_found", chdr.ChannelId)
 		}
 
 		erroredChan := chain.Errored()
 		select {
 		case <-erroredChan:
-			logger.Warningf("[channel: %s] Rejecting deliver request because of consenter error", chdr.ChannelId)
-			return sendStatusReply(srv, cb.Status_SERVICE_UNAVAILABLE)
+			chainLogger.Warningf("Rejecting deliver request because of consenter error")
+			return ds.sendFailure(srv, cb.Status_SERVICE_UNAVAILABLE, "unavailable", chdr.ChannelId)
 		default:
 
 		}
 
 		lastConfigSequence := chain.Sequence()
 
-		sf := sigfilter.New(policies.ChannelReaders, chain.PolicyManager())
-		result, _ := sf.Apply(envelope)
-		if result != filter.Forward {
-			logger.Warningf("[channel: %s] Received unauthorized deliver request", chdr.ChannelId)
-			return sendStatusReply(srv, cb.Status_FORBIDDEN)
+		if !ds.boundToStream(srv.Context(), chdr) {
+			chainLogger.Warningf("Received deliver request which is not bound to the TLS client certificate of the stream")
+			return ds.sendFailure(srv, cb.Status_FORBIDDEN, "tls_binding", chdr.ChannelId)
+		}
+
+		if !ds.authorized(srv.Context(), chain, msg) {
+			chainLogger.Warningf("Received unauthorized deliver request")
+			return ds.sendFailure(srv, cb.Status_FORBIDDEN, "forbidden", chdr.ChannelId)
 		}
 
 		seekInfo := &ab.SeekInfo{}
-		if err = proto.Unmarshal(payload.Data, seekInfo); err != nil {
-			logger.Warningf("[channel: %s] Received a signed deliver request with malformed seekInfo payload: %s", chdr.ChannelId, err)
-			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+		if err = pool.Unmarshal(payload.Data, seekInfo); err != nil {
+			chainLogger.Warningf("Received a signed deliver request with malformed seekInfo payload: %s", err)
+			return ds.sendFailure(srv, cb.Status_BAD_REQUEST, "malformed_seek_info", chdr.ChannelId)
 		}
 
 		if seekInfo.Start == nil || seekInfo.Stop == nil {
-			logger.Warningf("[channel: %s] Received seekInfo message with missing start or stop %v, %v", chdr.ChannelId, seekInfo.Start, seekInfo.Stop)
-			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+			chainLogger.Warningf("Received seekInfo message with missing start or stop %v, %v", seekInfo.Start, seekInfo.Stop)
+			return ds.sendFailure(srv, cb.Status_BAD_REQUEST, "malformed_seek_info", chdr.ChannelId)
 		}
 
-		logger.Debugf("[channel: %s] Received seekInfo (%p) %v", chdr.ChannelId, seekInfo, seekInfo)
+		// A behavior unknown to the orderer is refused rather than taken to
+		// mean failing fast, which the client may not expect
+		if _, ok := ab.SeekInfo_SeekBehavior_name[int32(seekInfo.Behavior)]; !ok {
+			chainLogger.Warningf("Received seekInfo message with unknown behavior %d", seekInfo.Behavior)
+			return ds.sendFailure(srv, cb.Status_BAD_REQUEST, "invalid_seek_behavior", chdr.ChannelId)
+		}
+
+		chainLogger.Debugf("Received seekInfo (%p) %v", seekInfo, seekInfo)
+
+		start := seekInfo.Start
+		if resume, ok := start.Type.(*ab.SeekPosition_Resume); ok {
+			var status cb.Status
+			var reason string
+			if start, status, reason = resumePosition(chain, chdr.ChannelId, resume.Resume.GetToken()); status != cb.Status_SUCCESS {
+				chainLogger.Warningf("Received deliver request with resume token which cannot be resumed: %s", reason)
+				return ds.sendFailure(srv, status, reason, chdr.ChannelId)
+			}
+		}
+		if _, ok := seekInfo.Stop.Type.(*ab.SeekPosition_Resume); ok {
+			chainLogger.Warningf("Received seekInfo message with resume token as stop position")
+			return ds.sendFailure(srv, cb.Status_BAD_REQUEST, "malformed_seek_info", chdr.ChannelId)
+		}
 
-		cursor, number := chain.Reader().Iterator(seekInfo.Start)
+		cursor, number := chain.Reader().Iterator(start)
+		if reason := notFoundReason(seekInfo.Start); reason != "" && isNotFound(cursor) {
+			chainLogger.Debugf("Rejecting deliver because start position %v was not found", seekInfo.Start)
+			return ds.sendFailure(srv, cb.Status_NOT_FOUND, reason, chdr.ChannelId)
+		}
 		var stopNum uint64
 		switch stop := seekInfo.Stop.Type.(type) {
 		case *ab.SeekPosition_Oldest:
@@ -150,65 +356,208 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 		case *ab.SeekPosition_Specified:
 			stopNum = stop.Specified.Number
 			if stopNum < number {
-				logger.Warningf("[channel: %s] Received invalid seekInfo message: start number %d greater than stop number %d", chdr.ChannelId, number, stopNum)
-				return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+				chainLogger.Warningf("Received invalid seekInfo message: start number %d greater than stop number %d", number, stopNum)
+				return ds.sendFailure(srv, cb.Status_BAD_REQUEST, "invalid_seek_range", chdr.ChannelId)
+			}
+		case *ab.SeekPosition_TxId, *ab.SeekPosition_Hash:
+			var stopCursor ledger.Iterator
+			stopCursor, stopNum = chain.Reader().Iterator(seekInfo.Stop)
+			if isNotFound(stopCursor) {
+				chainLogger.Debugf("Rejecting deliver because stop position %v was not found", stop)
+				return ds.sendFailure(srv, cb.Status_NOT_FOUND, notFoundReason(seekInfo.Stop), chdr.ChannelId)
+			}
+			if stopNum < number {
+				chainLogger.Warningf("Received invalid seekInfo message: start number %d greater than stop number %d", number, stopNum)
+				return ds.sendFailure(srv, cb.Status_BAD_REQUEST, "invalid_seek_range", chdr.ChannelId)
 			}
 		}
 
+		// Filtered blocks are small, so they are never compressed
+		var algorithm ab.Compression
+		if !seekInfo.Filtered {
+			algorithm = compression.Negotiate(seekInfo.Compression, ds.compression)
+		}
+
+		endSeek = ds.serving(chdr.ChannelId)
+		// sinceToken is the number of blocks delivered since the last resume
+		// token was sent
+		var sinceToken uint32
+		// idle fires once the stream has waited for the heartbeat interval,
+		// across the revalidations of the wait
+		var idle *heartbeat
+		// revalidated reports whether the client is still authorized, once the
+		// revalidation interval has elapsed
+		revalidated := func() bool {
+			rearm()
+			lastConfigSequence = chain.Sequence()
+			return ds.authorized(srv.Context(), chain, msg)
+		}
 		for {
 			if seekInfo.Behavior == ab.SeekInfo_BLOCK_UNTIL_READY {
+				if idle == nil {
+					idle = ds.newHeartbeat(seekInfo)
+				}
 				select {
 				case <-erroredChan:
-					logger.Warningf("[channel: %s] Aborting deliver request because of consenter error", chdr.ChannelId)
-					return sendStatusReply(srv, cb.Status_SERVICE_UNAVAILABLE)
+					chainLogger.Warningf("Aborting deliver request because of consenter error")
+					return ds.sendFailure(srv, cb.Status_SERVICE_UNAVAILABLE, "unavailable", chdr.ChannelId)
+				case <-revalidate:
+					if !revalidated() {
+						chainLogger.Warningf("Client authorization revoked for deliver request")
+						return ds.sendFailure(srv, cb.Status_FORBIDDEN, "forbidden", chdr.ChannelId)
+					}
+					continue
+				case <-idle.C():
+					idle = nil
+					if err := sendHeartbeat(srv, chain.Reader().Height()); err != nil {
+						chainLogger.Warningf("Error sending to stream: %s", err)
+						ds.metrics.streamsEnded.With(chdr.ChannelId, "send_error").Add(1)
+						return err
+					}
+					continue
 				case <-cursor.ReadyChan():
+					idle.stop()
+					idle = nil
 				}
 			} else {
 				select {
 				case <-cursor.ReadyChan():
 				default:
-					return sendStatusReply(srv, cb.Status_NOT_FOUND)
+					return ds.sendFailure(srv, cb.Status_NOT_FOUND, "not_ready", chdr.ChannelId)
 				}
 			}
 
+			// A stream which is never idle, such as one catching up, is
+			// revalidated between blocks
+			select {
+			case <-revalidate:
+				if !revalidated() {
+					chainLogger.Warningf("Client authorization revoked for deliver request")
+					return ds.sendFailure(srv, cb.Status_FORBIDDEN, "forbidden", chdr.ChannelId)
+				}
+			default:
+			}
+
 			currentConfigSequence := chain.Sequence()
 			if currentConfigSequence > lastConfigSequence {
 				lastConfigSequence = currentConfigSequence
-				sf := sigfilter.New(policies.ChannelReaders, chain.PolicyManager())
-				result, _ := sf.Apply(envelope)
-				if result != filter.Forward {
-					logger.Warningf("[channel: %s] Client authorization revoked for deliver request", chdr.ChannelId)
-					return sendStatusReply(srv, cb.Status_FORBIDDEN)
+				if !ds.authorized(srv.Context(), chain, msg) {
+					chainLogger.Warningf("Client authorization revoked for deliver request")
+					return ds.sendFailure(srv, cb.Status_FORBIDDEN, "forbidden", chdr.ChannelId)
 				}
 			}
 
 			block, status := cursor.Next()
 			if status != cb.Status_SUCCESS {
-				logger.Errorf("[channel: %s] Error reading from channel, cause was: %v", chdr.ChannelId, status)
-				return sendStatusReply(srv, status)
+				chainLogger.Errorf("Error reading from channel, cause was: %v", status)
+				return ds.sendFailure(srv, status, "ledger_error", chdr.ChannelId)
 			}
 
-			logger.Debugf("[channel: %s] Delivering block for (%p)", chdr.ChannelId, seekInfo)
+			chainLogger.Debugf("Delivering block for (%p)", seekInfo)
+
+			blocks := []*cb.Block{block}
+			if seekInfo.MaxBatchSize > 1 && ds.maxBatchBytes > 0 {
+				blocks, status = ds.batch(chain, cursor, block, stopNum, seekInfo.MaxBatchSize, lastConfigSequence)
+			}
 
-			if err := sendBlockReply(srv, block); err != nil {
-				logger.Warningf("[channel: %s] Error sending to stream: %s", chdr.ChannelId, err)
+			var size int
+			sendStart := time.Now()
+			if seekInfo.Filtered {
+				size, err = sendFilteredBlocksReply(srv, chdr.ChannelId, blocks)
+			} else if algorithm != ab.Compression_NONE {
+				size, err = sendCompressedBlocksReply(srv, algorithm, blocks)
+			} else {
+				size, err = sendBlocksReply(srv, blocks)
+			}
+			ds.pipeline.Since(pipeline.StageDeliverSend, sendStart)
+			if err != nil {
+				chainLogger.Warningf("Error sending to stream: %s", err)
+				ds.metrics.streamsEnded.With(chdr.ChannelId, "send_error").Add(1)
 				return err
 			}
+			ds.countSent(chain, chdr.ChannelId, blocks, size)
+			pacer.pace(srv.Context(), size)
+
+			if seekInfo.ResumeInterval > 0 {
+				sinceToken += uint32(len(blocks))
+				if sinceToken >= seekInfo.ResumeInterval {
+					sinceToken = 0
+					if err := sendResumeToken(srv, chdr.ChannelId, blocks[len(blocks)-1]); err != nil {
+						chainLogger.Warningf("Error sending to stream: %s", err)
+						ds.metrics.streamsEnded.With(chdr.ChannelId, "send_error").Add(1)
+						return err
+					}
+				}
+			}
+
+			if status != cb.Status_SUCCESS {
+				chainLogger.Errorf("Error reading from channel, cause was: %v", status)
+				return ds.sendFailure(srv, status, "ledger_error", chdr.ChannelId)
+			}
 
-			if stopNum == block.Header.Number {
+			if stopNum == blocks[len(blocks)-1].Header.Number {
 				break
 			}
 		}
 
+		endSeek()
 		if err := sendStatusReply(srv, cb.Status_SUCCESS); err != nil {
-			logger.Warningf("[channel: %s] Error sending to stream: %s", chdr.ChannelId, err)
+			chainLogger.Warningf("Error sending to stream: %s", err)
+			ds.metrics.streamsEnded.With(chdr.ChannelId, "send_error").Add(1)
 			return err
 		}
 
-		logger.Debugf("[channel: %s] Done delivering for (%p), waiting for new SeekInfo", chdr.ChannelId, seekInfo)
+		chainLogger.Debugf("Done delivering for (%p), waiting for new SeekInfo", seekInfo)
+	}
+}
+
+// batch returns the first block followed by those which are already
+// available, up to maxSize blocks and until the batch reaches the
+// maxBatchBytes of the server, which the last block may exceed. Batching stops
+// at the stop block and when the config of the chain changes, as the client
+// must then be authorized again before it is sent further blocks.
+func (ds *deliverServer) batch(chain Support, cursor ledger.Iterator, first *cb.Block, stopNum uint64, maxSize uint32, sequence uint64) ([]*cb.Block, cb.Status) {
+	blocks := []*cb.Block{first}
+	size := proto.Size(first)
+	for uint32(len(blocks)) < maxSize && size < ds.maxBatchBytes && blocks[len(blocks)-1].Header.Number != stopNum {
+		select {
+		case <-cursor.ReadyChan():
+		default:
+			return blocks, cb.Status_SUCCESS
+		}
+		if chain.Sequence() != sequence {
+			return blocks, cb.Status_SUCCESS
+		}
+		block, status := cursor.Next()
+		if status != cb.Status_SUCCESS {
+			return blocks, status
+		}
+		size += proto.Size(block)
+		blocks = append(blocks, block)
+	}
+	return blocks, cb.Status_SUCCESS
+}
+
+// notFoundReason returns the reason a seek to the position fails if it is
+// not found, or the empty string if the position is not looked up
+func notFoundReason(position *ab.SeekPosition) string {
+	switch position.Type.(type) {
+	case *ab.SeekPosition_TxId:
+		return "tx_not_found"
+	case *ab.SeekPosition_Hash:
+		return "block_not_found"
+	default:
+		return ""
 	}
 }
 
+// isNotFound returns whether the ledger returned the iterator because the
+// position sought was not found
+func isNotFound(it ledger.Iterator) bool {
+	_, ok := it.(*ledger.NotFoundErrorIterator)
+	return ok
+}
+
 func sendStatusReply(srv ab.AtomicBroadcast_DeliverServer, status cb.Status) error {
 	return srv.Send(&ab.DeliverResponse{
 		Type: &ab.DeliverResponse_Status{Status: status},
@@ -216,8 +565,44 @@ func sendStatusReply(srv ab.AtomicBroadcast_DeliverServer, status cb.Status) err
 
 }
 
-func sendBlockReply(srv ab.AtomicBroadcast_DeliverServer, block *cb.Block) error {
-	return srv.Send(&ab.DeliverResponse{
-		Type: &ab.DeliverResponse_Block{Block: block},
-	})
+// sendFailure sends the failure status of a request to the channel, for the
+// reason, returning the error with which the stream then ends
+func (ds *deliverServer) sendFailure(srv ab.AtomicBroadcast_DeliverServer, status cb.Status, reason, chainID string) error {
+	ds.metrics.streamsEnded.With(ds.channelLabel(chainID), reason).Add(1)
+	if err := sendStatusReply(srv, status); err != nil {
+		return err
+	}
+	return ds.status.StreamError(srv.Context(), status, reason, chainID, nil)
+}
+
+// sendBlocksReply sends a single block as a block response, and several as a
+// batch, so that clients which do not accept batches are never sent one. It
+// returns the size of the response.
+func sendBlocksReply(srv ab.AtomicBroadcast_DeliverServer, blocks []*cb.Block) (int, error) {
+	resp := &ab.DeliverResponse{
+		Type: &ab.DeliverResponse_Blocks{Blocks: &ab.Blocks{Blocks: blocks}},
+	}
+	if len(blocks) == 1 {
+		resp = &ab.DeliverResponse{
+			Type: &ab.DeliverResponse_Block{Block: blocks[0]},
+		}
+	}
+	return proto.Size(resp), srv.Send(resp)
+}
+
+// sendFilteredBlocksReply sends each block as a filtered block response.
+// Filtered blocks are small, so they are never batched. It returns the size
+// of the responses.
+func sendFilteredBlocksReply(srv ab.AtomicBroadcast_DeliverServer, chainID string, blocks []*cb.Block) (int, error) {
+	var sent int
+	for _, block := range blocks {
+		resp := &ab.DeliverResponse{
+			Type: &ab.DeliverResponse_FilteredBlock{FilteredBlock: filterBlock(chainID, block)},
+		}
+		if err := srv.Send(resp); err != nil {
+			return sent, err
+		}
+		sent += proto.Size(resp)
+	}
+	return sent, nil
 }
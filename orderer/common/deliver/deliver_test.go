@@ -17,22 +17,38 @@ limitations under the License.
 package deliver
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	mockmsp "github.com/hyperledger/fabric/common/mocks/msp"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 )
 
 var genesisBlock = cb.NewBlock(0, nil)
@@ -49,6 +65,7 @@ type mockD struct {
 	grpc.ServerStream
 	recvChan chan *cb.Envelope
 	sendChan chan *ab.DeliverResponse
+	ctx      context.Context
 }
 
 func newMockD() *mockD {
@@ -58,6 +75,13 @@ func newMockD() *mockD {
 	}
 }
 
+func (m *mockD) Context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
 func (m *mockD) Send(br *ab.DeliverResponse) error {
 	m.sendChan <- br
 	return nil
@@ -75,6 +99,10 @@ type erroneousRecvMockD struct {
 	grpc.ServerStream
 }
 
+func (m *erroneousRecvMockD) Context() context.Context {
+	return context.Background()
+}
+
 func (m *erroneousRecvMockD) Send(br *ab.DeliverResponse) error {
 	return nil
 }
@@ -90,6 +118,10 @@ type erroneousSendMockD struct {
 	recvVal *cb.Envelope
 }
 
+func (m *erroneousSendMockD) Context() context.Context {
+	return context.Background()
+}
+
 func (m *erroneousSendMockD) Send(br *ab.DeliverResponse) error {
 	// The point here is to simulate an error other than EOF.
 	// We don't bother to create a new custom error type.
@@ -114,6 +146,7 @@ type mockSupport struct {
 	policyManager *mockpolicies.Manager
 	erroredChan   chan struct{}
 	configSeq     uint64
+	validator     *identity.Validator
 }
 
 func (mcs *mockSupport) Errored() <-chan struct{} {
@@ -132,6 +165,17 @@ func (mcs *mockSupport) Reader() ledger.Reader {
 	return mcs.ledger
 }
 
+// MSPManager returns a manager of a single MSP, which accepts any identity
+func (mcs *mockSupport) MSPManager() msp.MSPManager {
+	mgr := msp.NewMSPManager()
+	mgr.Setup([]msp.MSP{mockmsp.NewNoopMsp()})
+	return mgr
+}
+
+func (mcs *mockSupport) IdentityValidator() *identity.Validator {
+	return mcs.validator
+}
+
 func NewRAMLedger() ledger.ReadWriter {
 	rlf := ramledger.New(ledgerSize + 1)
 	rl, _ := rlf.GetOrCreate(provisional.TestChainID)
@@ -139,14 +183,14 @@ func NewRAMLedger() ledger.ReadWriter {
 	return rl
 }
 
-func initializeDeliverHandler() Handler {
+func initializeDeliverHandler(conf Config) Handler {
 	mm := newMockMultichainManager()
 	for i := 1; i < ledgerSize; i++ {
 		l := mm.chains[systemChainID].ledger
 		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
 	}
 
-	return NewHandlerImpl(mm)
+	return NewHandlerImpl(mm, conf)
 }
 
 func newMockMultichainManager() *mockSupportManager {
@@ -154,11 +198,13 @@ func newMockMultichainManager() *mockSupportManager {
 	mm := &mockSupportManager{
 		chains: make(map[string]*mockSupport),
 	}
-	mm.chains[systemChainID] = &mockSupport{
+	cs := &mockSupport{
 		ledger:        rl,
 		policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}},
 		erroredChan:   make(chan struct{}),
 	}
+	cs.validator = identity.NewValidator(cs, identity.DefaultCacheSize)
+	mm.chains[systemChainID] = cs
 	return mm
 }
 
@@ -187,7 +233,7 @@ func TestWholeChainSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
@@ -219,7 +265,7 @@ func TestNewestSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekNewest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
@@ -241,7 +287,7 @@ func TestSpecificSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	specifiedStart := uint64(3)
@@ -268,6 +314,30 @@ func TestSpecificSeek(t *testing.T) {
 	}
 }
 
+func TestTokenPrincipalSeek(t *testing.T) {
+	seek := makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(uint64(0)), Stop: seekSpecified(uint64(0)), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+	payload := utils.UnmarshalPayloadOrPanic(seek.Payload)
+	payload.Header.SignatureHeader = utils.MarshalOrPanic(&cb.SignatureHeader{
+		Creator: utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: "SampleOrg"}),
+	})
+	seek.Payload = utils.MarshalOrPanic(payload)
+
+	for mspID, status := range map[string]cb.Status{"SampleOrg": cb.Status_SUCCESS, "OtherOrg": cb.Status_FORBIDDEN} {
+		m := newMockD()
+		m.ctx = interceptor.WithPrincipal(context.Background(), &interceptor.Principal{Subject: "app1", MSPID: mspID})
+		ds := initializeDeliverHandler(Config{})
+		go ds.Handle(m)
+		m.recvChan <- seek
+
+		reply := <-m.sendChan
+		if reply.GetBlock() != nil {
+			reply = <-m.sendChan
+		}
+		assert.Equal(t, status, reply.GetStatus(), "The token of MSP %s", mspID)
+		close(m.recvChan)
+	}
+}
+
 func TestUnauthorizedSeek(t *testing.T) {
 	mm := newMockMultichainManager()
 	for i := 1; i < ledgerSize; i++ {
@@ -278,7 +348,7 @@ func TestUnauthorizedSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, Config{})
 
 	go ds.Handle(m)
 
@@ -303,7 +373,7 @@ func TestRevokedAuthorizationSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, Config{})
 
 	go ds.Handle(m)
 
@@ -334,7 +404,7 @@ func TestOutOfBoundSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(uint64(3 * ledgerSize)), Stop: seekSpecified(uint64(3 * ledgerSize)), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
@@ -353,7 +423,7 @@ func TestFailFastSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(uint64(ledgerSize - 1)), Stop: seekSpecified(ledgerSize), Behavior: ab.SeekInfo_FAIL_IF_NOT_READY})
@@ -377,6 +447,128 @@ func TestFailFastSeek(t *testing.T) {
 	}
 }
 
+func TestUnknownSeekBehavior(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	ds := initializeDeliverHandler(Config{})
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(0), Stop: seekSpecified(0), Behavior: ab.SeekInfo_SeekBehavior(7)})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		if deliverReply.GetStatus() != cb.Status_BAD_REQUEST {
+			t.Fatalf("Expected the unknown behavior to be refused, got %v", deliverReply)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the reply")
+	}
+}
+
+// revocablePolicy is satisfied until it is revoked, which may happen
+// concurrently with its evaluation
+type revocablePolicy struct {
+	revoked int32
+}
+
+func (rp *revocablePolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	if atomic.LoadInt32(&rp.revoked) != 0 {
+		return fmt.Errorf("certificate revoked")
+	}
+	return nil
+}
+
+func TestRevokedWhileIdleSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+	policy := &revocablePolicy{}
+	mm.chains[systemChainID].policyManager.PolicyMap = map[string]policies.Policy{policies.ChannelReaders: policy}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm, Config{RevalidationInterval: 10 * time.Millisecond}).(*deliverServer)
+	clk := clock.NewManual(time.Now())
+	ds.clock = clk
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekNewest, Stop: seekSpecified(ledgerSize), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.NotNil(t, deliverReply.GetBlock(), "First should succeed")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get first block")
+	}
+
+	select {
+	case <-m.sendChan:
+		t.Fatalf("Should not have delivered an error or second block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The stream is revalidated every interval, although no block is written
+	// and the config does not change
+	clk.BlockUntil(1)
+	clk.Advance(10 * time.Millisecond)
+	clk.BlockUntil(1)
+	atomic.StoreInt32(&policy.revoked, 1)
+	clk.Advance(10 * time.Millisecond)
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_FORBIDDEN, deliverReply.GetStatus(), "Idle stream should have been closed once revoked")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the stream to be revalidated")
+	}
+}
+
+func TestRevokedWhileSending(t *testing.T) {
+	mm := newMockMultichainManager()
+	for i := 1; i < ledgerSize; i++ {
+		l := mm.chains[systemChainID].ledger
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+	}
+	policy := &revocablePolicy{}
+	mm.chains[systemChainID].policyManager.PolicyMap = map[string]policies.Policy{policies.ChannelReaders: policy}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm, Config{RevalidationInterval: 10 * time.Millisecond}).(*deliverServer)
+	clk := clock.NewManual(time.Now())
+	ds.clock = clk
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekSpecified(ledgerSize - 1), Behavior: ab.SeekInfo_FAIL_IF_NOT_READY})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.NotNil(t, deliverReply.GetBlock(), "First should succeed")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get first block")
+	}
+
+	// The stream is revalidated before the next block is read, or, if it
+	// has already been read, the block after
+	atomic.StoreInt32(&policy.revoked, 1)
+	clk.Advance(10 * time.Millisecond)
+
+	for blocks := 1; ; blocks++ {
+		select {
+		case deliverReply := <-m.sendChan:
+			if deliverReply.GetBlock() != nil {
+				assert.True(t, blocks < 2, "At most one more block should have been sent once revoked")
+				continue
+			}
+			assert.Equal(t, cb.Status_FORBIDDEN, deliverReply.GetStatus(), "Stream should have been closed between blocks once revoked")
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the stream to be revalidated")
+		}
+		break
+	}
+}
+
 func TestBlockingSeek(t *testing.T) {
 	mm := newMockMultichainManager()
 	for i := 1; i < ledgerSize; i++ {
@@ -386,7 +578,7 @@ func TestBlockingSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, Config{})
 
 	go ds.Handle(m)
 
@@ -440,7 +632,7 @@ func TestErroredSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, Config{})
 
 	go ds.Handle(m)
 
@@ -464,7 +656,7 @@ func TestErroredBlockingSeek(t *testing.T) {
 
 	m := newMockD()
 	defer close(m.recvChan)
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, Config{})
 
 	go ds.Handle(m)
 
@@ -489,7 +681,7 @@ func TestErroredBlockingSeek(t *testing.T) {
 
 func TestSGracefulShutdown(t *testing.T) {
 	m := newMockD()
-	ds := NewHandlerImpl(nil)
+	ds := NewHandlerImpl(nil, Config{})
 
 	close(m.recvChan)
 	assert.NoError(t, ds.Handle(m), "Expected no error for hangup")
@@ -499,7 +691,7 @@ func TestReversedSeqSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	specifiedStart := uint64(7)
@@ -517,13 +709,13 @@ func TestReversedSeqSeek(t *testing.T) {
 }
 
 func TestBadStreamRecv(t *testing.T) {
-	bh := NewHandlerImpl(nil)
+	bh := NewHandlerImpl(nil, Config{})
 	assert.Error(t, bh.Handle(&erroneousRecvMockD{}), "Should catch unexpected stream error")
 }
 
 func TestBadStreamSend(t *testing.T) {
 	m := &erroneousSendMockD{recvVal: makeSeek(systemChainID, &ab.SeekInfo{Start: seekNewest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})}
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	assert.Error(t, ds.Handle(m), "Should catch unexpected stream error")
 }
 
@@ -531,7 +723,7 @@ func TestOldestSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekOldest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
@@ -549,7 +741,7 @@ func TestNoPayloadSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- &cb.Envelope{Payload: []byte("Foo")}
@@ -566,7 +758,7 @@ func TestNilPayloadHeaderSeek(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{})}
@@ -583,7 +775,7 @@ func TestBadChannelHeader(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
@@ -606,7 +798,7 @@ func TestChainNotFound(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := NewHandlerImpl(mm)
+	ds := NewHandlerImpl(mm, Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekNewest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
@@ -619,11 +811,39 @@ func TestChainNotFound(t *testing.T) {
 	}
 }
 
+func TestStreamErrors(t *testing.T) {
+	mm := newMockMultichainManager()
+	mm.chains[systemChainID].policyManager.Policy.Err = fmt.Errorf("Fail to evaluate policy")
+	ds := NewHandlerImpl(mm, Config{Status: rpcstatus.Config{StreamErrors: true}})
+
+	for _, tc := range []struct {
+		chainID string
+		status  cb.Status
+		code    codes.Code
+		reason  string
+	}{
+		{"missing", cb.Status_NOT_FOUND, codes.NotFound, "channel_not_found"},
+		{systemChainID, cb.Status_FORBIDDEN, codes.PermissionDenied, "forbidden"},
+	} {
+		m := newMockD()
+		errs := make(chan error)
+		go func() {
+			errs <- ds.Handle(m)
+		}()
+		m.recvChan <- makeSeek(tc.chainID, &ab.SeekInfo{Start: seekNewest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+		assert.Equal(t, tc.status, (<-m.sendChan).GetStatus(), "The status should be sent before the stream ends")
+		err := <-errs
+		assert.Equal(t, tc.code, grpc.Code(err))
+		assert.Equal(t, []proto.Message{&ab.ErrorInfo{Status: tc.status, Reason: tc.reason, ChannelId: tc.chainID}}, rpcstatus.Details(err))
+		close(m.recvChan)
+	}
+}
+
 func TestBadSeekInfoPayload(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- &cb.Envelope{
@@ -650,7 +870,7 @@ func TestMissingSeekPosition(t *testing.T) {
 	m := newMockD()
 	defer close(m.recvChan)
 
-	ds := initializeDeliverHandler()
+	ds := initializeDeliverHandler(Config{})
 	go ds.Handle(m)
 
 	m.recvChan <- &cb.Envelope{
@@ -672,3 +892,296 @@ func TestMissingSeekPosition(t *testing.T) {
 		t.Fatalf("Timed out waiting to get all blocks")
 	}
 }
+
+func TestBatchedSeek(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		maxBatchBytes int
+		seekInfo      *ab.SeekInfo
+		expected      [][]uint64
+	}{
+		{
+			name:          "Batches",
+			maxBatchBytes: 1024 * 1024,
+			seekInfo:      &ab.SeekInfo{Start: seekOldest, Stop: seekNewest, MaxBatchSize: 4},
+			expected:      [][]uint64{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9}},
+		},
+		{
+			name:          "StopsAtStop",
+			maxBatchBytes: 1024 * 1024,
+			seekInfo:      &ab.SeekInfo{Start: seekSpecified(3), Stop: seekSpecified(5), MaxBatchSize: 10},
+			expected:      [][]uint64{{3, 4, 5}},
+		},
+		{
+			name:          "ByteLimit",
+			maxBatchBytes: 1,
+			seekInfo:      &ab.SeekInfo{Start: seekSpecified(3), Stop: seekSpecified(5), MaxBatchSize: 10},
+			expected:      [][]uint64{{3}, {4}, {5}},
+		},
+		{
+			name:          "NotAccepted",
+			maxBatchBytes: 1024 * 1024,
+			seekInfo:      &ab.SeekInfo{Start: seekSpecified(3), Stop: seekSpecified(5)},
+			expected:      [][]uint64{{3}, {4}, {5}},
+		},
+		{
+			name:          "Disabled",
+			maxBatchBytes: 0,
+			seekInfo:      &ab.SeekInfo{Start: seekSpecified(3), Stop: seekSpecified(5), MaxBatchSize: 10},
+			expected:      [][]uint64{{3}, {4}, {5}},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			mm := newMockMultichainManager()
+			for i := 1; i < ledgerSize; i++ {
+				l := mm.chains[systemChainID].ledger
+				l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+			}
+
+			m := newMockD()
+			defer close(m.recvChan)
+			ds := NewHandlerImpl(mm, Config{MaxBatchBytes: testCase.maxBatchBytes})
+			go ds.Handle(m)
+
+			m.recvChan <- makeSeek(systemChainID, testCase.seekInfo)
+
+			var received [][]uint64
+			for {
+				select {
+				case deliverReply := <-m.sendChan:
+					switch reply := deliverReply.Type.(type) {
+					case *ab.DeliverResponse_Block:
+						received = append(received, []uint64{reply.Block.Header.Number})
+						continue
+					case *ab.DeliverResponse_Blocks:
+						assert.True(t, len(reply.Blocks.Blocks) > 1, "A batch should hold several blocks")
+						var numbers []uint64
+						for _, block := range reply.Blocks.Blocks {
+							numbers = append(numbers, block.Header.Number)
+						}
+						received = append(received, numbers)
+						continue
+					}
+					assert.Equal(t, cb.Status_SUCCESS, deliverReply.GetStatus())
+					assert.Equal(t, testCase.expected, received)
+				case <-time.After(time.Second):
+					t.Fatalf("Timed out waiting to get all blocks")
+				}
+				return
+			}
+		})
+	}
+}
+
+// changingSupport changes the config sequence after it has been read calls
+// times, revoking the policy with the new config
+type changingSupport struct {
+	*mockSupport
+	policy *revocablePolicy
+	reads  int32
+	calls  int32
+}
+
+func (cs *changingSupport) Sequence() uint64 {
+	if atomic.AddInt32(&cs.reads, 1) > cs.calls {
+		atomic.StoreInt32(&cs.policy.revoked, 1)
+		return 1
+	}
+	return 0
+}
+
+func TestBatchedSeekConfigChange(t *testing.T) {
+	mm := newMockMultichainManager()
+	for i := 1; i < ledgerSize; i++ {
+		l := mm.chains[systemChainID].ledger
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+	}
+	// The sequence is read when the request is received, before the first
+	// block and before each block batched with it, so that it changes before
+	// the third block is batched
+	policy := &revocablePolicy{}
+	support := &changingSupport{mockSupport: mm.chains[systemChainID], policy: policy, calls: 3}
+	support.policyManager.PolicyMap = map[string]policies.Policy{policies.ChannelReaders: policy}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(&changingSupportManager{support: support}, Config{MaxBatchBytes: 1024 * 1024})
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekNewest, MaxBatchSize: 4})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Len(t, deliverReply.GetBlocks().GetBlocks(), 2, "Batching should stop when the config changes")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the first batch")
+	}
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_FORBIDDEN, deliverReply.GetStatus(), "The client should be authorized again before further blocks are sent")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the client to be rejected")
+	}
+}
+
+type changingSupportManager struct {
+	support *changingSupport
+}
+
+func (cm *changingSupportManager) GetChain(chainID string) (Support, bool) {
+	return cm.support, chainID == systemChainID
+}
+
+func TestTxIDSeek(t *testing.T) {
+	seekTxID := func(txID string) *ab.SeekPosition {
+		return &ab.SeekPosition{Type: &ab.SeekPosition_TxId{TxId: &ab.SeekTxID{TxId: txID}}}
+	}
+	mm := newMockMultichainManager()
+	for i := 1; i < ledgerSize; i++ {
+		l := mm.chains[systemChainID].ledger
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{makeTx(fmt.Sprintf("tx%d", i), cb.HeaderType_ENDORSER_TRANSACTION)}))
+	}
+
+	t.Run("Found", func(t *testing.T) {
+		m := newMockD()
+		defer close(m.recvChan)
+		go NewHandlerImpl(mm, Config{}).Handle(m)
+
+		m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekTxID("tx3"), Stop: seekTxID("tx5")})
+		for number := uint64(3); number <= 5; number++ {
+			select {
+			case deliverReply := <-m.sendChan:
+				if !assert.NotNil(t, deliverReply.GetBlock(), "Expected a block, got %v", deliverReply) {
+					return
+				}
+				assert.Equal(t, number, deliverReply.GetBlock().Header.Number)
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting for block %d", number)
+			}
+		}
+		assert.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).GetStatus())
+	})
+
+	for _, testCase := range []struct {
+		name     string
+		seekInfo *ab.SeekInfo
+		status   cb.Status
+	}{
+		{"StartNotFound", &ab.SeekInfo{Start: seekTxID("unknown"), Stop: seekNewest}, cb.Status_NOT_FOUND},
+		{"StopNotFound", &ab.SeekInfo{Start: seekOldest, Stop: seekTxID("unknown")}, cb.Status_NOT_FOUND},
+		{"StopBeforeStart", &ab.SeekInfo{Start: seekTxID("tx5"), Stop: seekTxID("tx3")}, cb.Status_BAD_REQUEST},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			m := newMockD()
+			defer close(m.recvChan)
+			go NewHandlerImpl(mm, Config{}).Handle(m)
+
+			m.recvChan <- makeSeek(systemChainID, testCase.seekInfo)
+			select {
+			case deliverReply := <-m.sendChan:
+				assert.Equal(t, testCase.status, deliverReply.GetStatus())
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting for the status")
+			}
+		})
+	}
+}
+
+func TestHashSeek(t *testing.T) {
+	seekHash := func(hash []byte) *ab.SeekPosition {
+		return &ab.SeekPosition{Type: &ab.SeekPosition_Hash{Hash: &ab.SeekBlockHash{Hash: hash}}}
+	}
+	mm := newMockMultichainManager()
+	l := mm.chains[systemChainID].ledger
+	for i := 1; i < ledgerSize; i++ {
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+	}
+
+	t.Run("Found", func(t *testing.T) {
+		m := newMockD()
+		defer close(m.recvChan)
+		go NewHandlerImpl(mm, Config{}).Handle(m)
+
+		m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekHash(ledger.GetBlock(l, 7).Header.Hash()), Stop: seekNewest})
+		for number := uint64(7); number < ledgerSize; number++ {
+			select {
+			case deliverReply := <-m.sendChan:
+				if !assert.NotNil(t, deliverReply.GetBlock(), "Expected a block, got %v", deliverReply) {
+					return
+				}
+				assert.Equal(t, number, deliverReply.GetBlock().Header.Number)
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting for block %d", number)
+			}
+		}
+		assert.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).GetStatus())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		m := newMockD()
+		defer close(m.recvChan)
+		go NewHandlerImpl(mm, Config{}).Handle(m)
+
+		m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekHash([]byte("unknown")), Stop: seekNewest})
+		select {
+		case deliverReply := <-m.sendChan:
+			assert.Equal(t, cb.Status_NOT_FOUND, deliverReply.GetStatus())
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the status")
+		}
+	})
+}
+
+func TestTLSBinding(t *testing.T) {
+	cert := &x509.Certificate{Raw: []byte("client certificate")}
+	certHash := sha256.Sum256(cert.Raw)
+	tlsContext := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+	makeBoundSeek := func(tlsCertHash []byte) *cb.Envelope {
+		return &cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{
+					ChannelHeader:   utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: systemChainID, TlsCertHash: tlsCertHash}),
+					SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{}),
+				},
+				Data: utils.MarshalOrPanic(&ab.SeekInfo{Start: seekNewest, Stop: seekNewest}),
+			}),
+		}
+	}
+
+	for _, testCase := range []struct {
+		name        string
+		ctx         context.Context
+		tlsCertHash []byte
+		required    bool
+		status      cb.Status
+	}{
+		{"Bound", tlsContext, certHash[:], false, cb.Status_SUCCESS},
+		{"BoundRequired", tlsContext, certHash[:], true, cb.Status_SUCCESS},
+		{"Unbound", tlsContext, nil, false, cb.Status_SUCCESS},
+		{"UnboundRequired", tlsContext, nil, true, cb.Status_FORBIDDEN},
+		{"OtherCertificate", tlsContext, []byte("other hash"), false, cb.Status_FORBIDDEN},
+		{"NoCertificate", context.Background(), certHash[:], false, cb.Status_FORBIDDEN},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			m := newMockD()
+			m.ctx = testCase.ctx
+			defer close(m.recvChan)
+			go NewHandlerImpl(newMockMultichainManager(), Config{RequireTLSBinding: testCase.required}).Handle(m)
+
+			m.recvChan <- makeBoundSeek(testCase.tlsCertHash)
+			select {
+			case deliverReply := <-m.sendChan:
+				if testCase.status == cb.Status_SUCCESS {
+					assert.NotNil(t, deliverReply.GetBlock(), "Expected a block, got %v", deliverReply)
+				} else {
+					assert.Equal(t, testCase.status, deliverReply.GetStatus())
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting for the response")
+			}
+		})
+	}
+}
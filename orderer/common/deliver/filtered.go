@@ -0,0 +1,64 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// filterBlock returns the filtered form of the block of the chain, which
+// carries the ID, type and validation code of each transaction. The
+// transactions which cannot be decoded are reported with an empty ID, so
+// that the index of each transaction is preserved.
+func filterBlock(chainID string, block *cb.Block) *ab.FilteredBlock {
+	data := block.GetData().GetData()
+	filtered := &ab.FilteredBlock{
+		ChannelId:            chainID,
+		Number:               block.GetHeader().GetNumber(),
+		FilteredTransactions: make([]*ab.FilteredTransaction, len(data)),
+	}
+
+	// The TRANSACTIONS_FILTER slot holds one validation code per transaction,
+	// if the block was validated
+	var codes []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		codes = metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	for i, envBytes := range data {
+		tx := &ab.FilteredTransaction{}
+		if len(codes) == len(data) {
+			tx.ValidationCode = uint32(codes[i])
+		}
+		if chdr, err := channelHeader(envBytes); err == nil {
+			tx.TxId, tx.Type = chdr.TxId, cb.HeaderType(chdr.Type)
+		} else {
+			logger.Debugf("Filtering malformed transaction %d of block %d: %s", i, filtered.Number, err)
+		}
+		filtered.FilteredTransactions[i] = tx
+	}
+	return filtered
+}
+
+// channelHeader decodes the channel header of the marshaled envelope
+func channelHeader(envBytes []byte) (*cb.ChannelHeader, error) {
+	env := &cb.Envelope{}
+	if err := pool.Unmarshal(envBytes, env); err != nil {
+		return nil, err
+	}
+	payload := &cb.Payload{}
+	if err := pool.Unmarshal(env.Payload, payload); err != nil {
+		return nil, err
+	}
+	chdr := &cb.ChannelHeader{}
+	if err := pool.Unmarshal(payload.GetHeader().GetChannelHeader(), chdr); err != nil {
+		return nil, err
+	}
+	return chdr, nil
+}
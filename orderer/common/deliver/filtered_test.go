@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTx(txID string, headerType cb.HeaderType) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+					Type:      int32(headerType),
+					ChannelId: systemChainID,
+					TxId:      txID,
+				}),
+			},
+			Data: []byte(txID),
+		}),
+	}
+}
+
+func TestFilterBlock(t *testing.T) {
+	block := cb.NewBlock(7, nil)
+	block.Data.Data = [][]byte{
+		utils.MarshalOrPanic(makeTx("tx1", cb.HeaderType_ENDORSER_TRANSACTION)),
+		[]byte("malformed"),
+		utils.MarshalOrPanic(makeTx("tx3", cb.HeaderType_CONFIG)),
+	}
+
+	t.Run("Unvalidated", func(t *testing.T) {
+		filtered := filterBlock(systemChainID, block)
+		assert.Equal(t, &ab.FilteredBlock{
+			ChannelId: systemChainID,
+			Number:    7,
+			FilteredTransactions: []*ab.FilteredTransaction{
+				{TxId: "tx1", Type: cb.HeaderType_ENDORSER_TRANSACTION},
+				{},
+				{TxId: "tx3", Type: cb.HeaderType_CONFIG},
+			},
+		}, filtered)
+	})
+
+	t.Run("Validated", func(t *testing.T) {
+		block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{0, 10, 11}
+		defer func() { block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = nil }()
+		filtered := filterBlock(systemChainID, block)
+		var codes []uint32
+		for _, tx := range filtered.FilteredTransactions {
+			codes = append(codes, tx.ValidationCode)
+		}
+		assert.Equal(t, []uint32{0, 10, 11}, codes)
+	})
+}
+
+func TestFilteredSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+	for i := 1; i < ledgerSize; i++ {
+		l := mm.chains[systemChainID].ledger
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{makeTx(fmt.Sprintf("tx%d", i), cb.HeaderType_ENDORSER_TRANSACTION)}))
+	}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	// Filtered blocks are not batched, even for clients which accept batches
+	ds := NewHandlerImpl(mm, Config{MaxBatchBytes: 1024 * 1024})
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(3), Stop: seekSpecified(5), MaxBatchSize: 10, Filtered: true})
+
+	for number := uint64(3); number <= 5; number++ {
+		select {
+		case deliverReply := <-m.sendChan:
+			filtered := deliverReply.GetFilteredBlock()
+			if !assert.NotNil(t, filtered, "Expected a filtered block, got %v", deliverReply) {
+				return
+			}
+			assert.Equal(t, systemChainID, filtered.ChannelId)
+			assert.Equal(t, number, filtered.Number)
+			assert.Equal(t, []*ab.FilteredTransaction{{TxId: fmt.Sprintf("tx%d", number), Type: cb.HeaderType_ENDORSER_TRANSACTION}}, filtered.FilteredTransactions)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for filtered block %d", number)
+		}
+	}
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_SUCCESS, deliverReply.GetStatus())
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the status")
+	}
+}
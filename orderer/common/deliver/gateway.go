@@ -0,0 +1,223 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Service is the Deliver service read by a gateway
+type Service interface {
+	Deliver(srv ab.AtomicBroadcast_DeliverServer) error
+}
+
+// Gateway serves the blocks of the chains over HTTP, for tools which have no
+// gRPC stack. GET /channels/{channel}/blocks/{number} answers with the block,
+// encoded as JSON, where the number may also be "oldest" or "newest", and
+// with the filtered block if the query sets filtered=true. Each request is
+// translated to an unsigned seek request bound to the TLS client certificate
+// of the client, which is authorized against the channel readers policy with
+// the identity of that certificate, so the gateway must be served with TLS
+// client authentication. A request which is refused is answered with the
+// HTTP status of the Deliver status, and the JSON encoded ErrorInfo
+// describing why.
+type Gateway struct {
+	service Service
+}
+
+// NewGateway creates a Gateway reading the blocks from the service
+func NewGateway(service Service) *Gateway {
+	return &Gateway{service: service}
+}
+
+// ServeHTTP implements the http.Handler interface
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// The path is /channels/{channel}/blocks/{number}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "channels" || parts[1] == "" || parts[2] != "blocks" {
+		http.NotFound(w, r)
+		return
+	}
+	chainID := parts[1]
+	position, ok := gatewayPosition(parts[3])
+	if !ok {
+		writeGatewayError(w, &ab.ErrorInfo{Status: cb.Status_BAD_REQUEST, Reason: "invalid_block_number", ChannelId: chainID})
+		return
+	}
+	seekInfo := &ab.SeekInfo{
+		Start:    position,
+		Stop:     position,
+		Behavior: ab.SeekInfo_FAIL_IF_NOT_READY,
+		Filtered: r.URL.Query().Get("filtered") == "true",
+	}
+
+	ctx := gatewayContext(r)
+	env, err := seek(ctx, chainID, seekInfo)
+	if err != nil {
+		logger.Errorf("Failed to create seek request of gateway client %s: %s", r.RemoteAddr, err)
+		writeGatewayError(w, &ab.ErrorInfo{Status: cb.Status_INTERNAL_SERVER_ERROR, Reason: "seek_failed", ChannelId: chainID})
+		return
+	}
+	stream := &gatewayStream{ctx: rpcstatus.WithStreamErrors(ctx), seek: env}
+	err = g.service.Deliver(stream)
+
+	if stream.reply != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if err := (&jsonpb.Marshaler{}).Marshal(w, stream.reply); err != nil {
+			logger.Warningf("Failed to write block to gateway client %s: %s", r.RemoteAddr, err)
+		}
+		return
+	}
+	var info *ab.ErrorInfo
+	for _, detail := range rpcstatus.Details(err) {
+		switch detail := detail.(type) {
+		case *ab.ErrorInfo:
+			info = detail
+		case *ab.RetryInfo:
+			w.Header().Set("Retry-After", strconv.FormatInt((detail.RetryDelayMs+999)/1000, 10))
+		}
+	}
+	if info == nil {
+		// The stream ended without a block or a reason
+		status := stream.status
+		if status == cb.Status_UNKNOWN || status == cb.Status_SUCCESS {
+			status = cb.Status_INTERNAL_SERVER_ERROR
+		}
+		info = &ab.ErrorInfo{Status: status, ChannelId: chainID}
+	}
+	writeGatewayError(w, info)
+}
+
+// seek returns the unsigned seek request for the client of the context,
+// bound to its TLS client certificate if it presented one
+func seek(ctx context.Context, chainID string, seekInfo *ab.SeekInfo) (*cb.Envelope, error) {
+	chdr := utils.MakeChannelHeader(cb.HeaderType_DELIVER_SEEK_INFO, 0, chainID, 0)
+	if pr, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := pr.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			hash := sha256.Sum256(tlsInfo.State.PeerCertificates[0].Raw)
+			chdr.TlsCertHash = hash[:]
+		}
+	}
+	data, err := proto.Marshal(seekInfo)
+	if err != nil {
+		return nil, err
+	}
+	payload := utils.MarshalOrPanic(&cb.Payload{Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{}), Data: data})
+	return &cb.Envelope{Payload: payload}, nil
+}
+
+// gatewayPosition parses the block number of a request
+func gatewayPosition(number string) (*ab.SeekPosition, bool) {
+	switch number {
+	case "oldest":
+		return &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}}, true
+	case "newest":
+		return &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}, true
+	}
+	n, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: n}}}, true
+}
+
+// gatewayCertKey is the key of the TLS client certificate of a gateway
+// request in its context, by which the request is authorized. Every gateway
+// request carries it, nil if the client presented no certificate, so that
+// its unsigned seek is never authorized by its signature.
+type gatewayCertKey struct{}
+
+// gatewayContext returns the context of the request, carrying its client as
+// the peer of a gRPC stream would, so that the client is identified and
+// limited as those of the Deliver service are, along with its certificate
+func gatewayContext(r *http.Request) context.Context {
+	var cert *x509.Certificate
+	pr := &peer.Peer{}
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		pr.Addr = addr
+	}
+	if r.TLS != nil {
+		pr.AuthInfo = credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: r.TLS.PeerCertificates}}
+		if len(r.TLS.PeerCertificates) > 0 {
+			cert = r.TLS.PeerCertificates[0]
+		}
+	}
+	return peer.NewContext(context.WithValue(r.Context(), gatewayCertKey{}, cert), pr)
+}
+
+// writeGatewayError answers with the HTTP status of the Deliver status, and
+// the ErrorInfo describing it
+func writeGatewayError(w http.ResponseWriter, info *ab.ErrorInfo) {
+	buf := &bytes.Buffer{}
+	if err := (&jsonpb.Marshaler{}).Marshal(buf, info); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(info.Status))
+	w.Write(buf.Bytes())
+}
+
+// gatewayStream is the Deliver stream of a gateway request, over which the
+// seek request is received and the block is sent
+type gatewayStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	seek   *cb.Envelope
+	reply  proto.Message
+	status cb.Status
+}
+
+func (gs *gatewayStream) Context() context.Context {
+	return gs.ctx
+}
+
+// Recv returns the seek request, and ends the stream once it was answered
+func (gs *gatewayStream) Recv() (*cb.Envelope, error) {
+	if gs.seek == nil {
+		return nil, io.EOF
+	}
+	seek := gs.seek
+	gs.seek = nil
+	return seek, nil
+}
+
+func (gs *gatewayStream) Send(resp *ab.DeliverResponse) error {
+	switch t := resp.Type.(type) {
+	case *ab.DeliverResponse_Block:
+		gs.reply = t.Block
+	case *ab.DeliverResponse_FilteredBlock:
+		gs.reply = t.FilteredBlock
+	case *ab.DeliverResponse_Status:
+		gs.status = t.Status
+	}
+	return nil
+}
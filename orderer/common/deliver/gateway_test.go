@@ -0,0 +1,163 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handlerService serves the Deliver service with a handler
+type handlerService struct {
+	Handler
+}
+
+func (hs handlerService) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	return hs.Handle(srv)
+}
+
+// newGatewayManager returns a manager of the system chain, holding
+// ledgerSize blocks
+func newGatewayManager() *mockSupportManager {
+	mm := newMockMultichainManager()
+	for i := 1; i < ledgerSize; i++ {
+		l := mm.chains[systemChainID].ledger
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+	}
+	return mm
+}
+
+func newTestGateway(mm *mockSupportManager, conf Config) *Gateway {
+	return NewGateway(handlerService{NewHandlerImpl(mm, conf)})
+}
+
+// gatewayGet gets the target as a client authenticated by its TLS
+// certificate
+func gatewayGet(g *Gateway, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Raw: []byte("client certificate")}}}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	return w
+}
+
+func gatewayErrorInfo(t *testing.T, w *httptest.ResponseRecorder) *ab.ErrorInfo {
+	info := &ab.ErrorInfo{}
+	require.NoError(t, jsonpb.Unmarshal(w.Body, info))
+	return info
+}
+
+func TestGatewayBlocks(t *testing.T) {
+	g := newTestGateway(newGatewayManager(), Config{})
+
+	for target, number := range map[string]uint64{
+		"/channels/" + systemChainID + "/blocks/3":      3,
+		"/channels/" + systemChainID + "/blocks/oldest": 0,
+		"/channels/" + systemChainID + "/blocks/newest": ledgerSize - 1,
+	} {
+		w := gatewayGet(g, target)
+		require.Equal(t, http.StatusOK, w.Code, target)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		block := &cb.Block{}
+		require.NoError(t, jsonpb.Unmarshal(w.Body, block))
+		assert.Equal(t, number, block.Header.Number, target)
+	}
+
+	w := gatewayGet(g, fmt.Sprintf("/channels/%s/blocks/2?filtered=true", systemChainID))
+	require.Equal(t, http.StatusOK, w.Code)
+	filtered := &ab.FilteredBlock{}
+	require.NoError(t, jsonpb.Unmarshal(w.Body, filtered))
+	assert.Equal(t, uint64(2), filtered.Number)
+	assert.Equal(t, systemChainID, filtered.ChannelId)
+}
+
+func TestGatewayRejected(t *testing.T) {
+	status := rpcstatus.Config{RetryDelay: 1500 * time.Millisecond}
+	mm := newGatewayManager()
+	g := newTestGateway(mm, Config{Status: status})
+
+	t.Run("NotReady", func(t *testing.T) {
+		w := gatewayGet(g, fmt.Sprintf("/channels/%s/blocks/%d", systemChainID, ledgerSize))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		info := gatewayErrorInfo(t, w)
+		assert.Equal(t, cb.Status_NOT_FOUND, info.Status)
+		assert.Equal(t, "not_ready", info.Reason)
+		assert.Equal(t, systemChainID, info.ChannelId)
+	})
+
+	t.Run("UnknownChannel", func(t *testing.T) {
+		w := gatewayGet(g, "/channels/unknown/blocks/0")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, cb.Status_NOT_FOUND, gatewayErrorInfo(t, w).Status)
+	})
+
+	t.Run("InvalidNumber", func(t *testing.T) {
+		w := gatewayGet(g, "/channels/"+systemChainID+"/blocks/latest")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_block_number", gatewayErrorInfo(t, w).Reason)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mm.chains[systemChainID].policyManager.Policy.Err = fmt.Errorf("Fail to evaluate policy")
+		defer func() { mm.chains[systemChainID].policyManager.Policy.Err = nil }()
+		w := gatewayGet(g, "/channels/"+systemChainID+"/blocks/0")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "forbidden", gatewayErrorInfo(t, w).Reason)
+	})
+
+	t.Run("NoCertificate", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/channels/"+systemChainID+"/blocks/0", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "forbidden", gatewayErrorInfo(t, w).Reason)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		limited := newTestGateway(mm, Config{Limits: Limits{MaxStreams: 1}, Status: status})
+		// The only slot is held by another stream
+		release, _ := limited.service.(handlerService).Handler.(*deliverServer).streams.acquire(clientContext("10.0.0.1", nil))
+		defer release()
+		w := gatewayGet(limited, "/channels/"+systemChainID+"/blocks/0")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		assert.Equal(t, "too_many_streams", gatewayErrorInfo(t, w).Reason)
+	})
+
+	t.Run("BadRequests", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, gatewayGet(g, "/channels/"+systemChainID+"/blocks").Code)
+		assert.Equal(t, http.StatusNotFound, gatewayGet(g, "/channels//blocks/0").Code)
+
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/channels/"+systemChainID+"/blocks/0", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestGatewayTLSBinding(t *testing.T) {
+	g := newTestGateway(newGatewayManager(), Config{RequireTLSBinding: true})
+
+	// A client without a certificate cannot bind its seek
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/channels/"+systemChainID+"/blocks/0", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "tls_binding", gatewayErrorInfo(t, w).Reason)
+
+	assert.Equal(t, http.StatusOK, gatewayGet(g, "/channels/"+systemChainID+"/blocks/0").Code)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// heartbeat fires once a stream has waited for blocks for the heartbeat
+// interval. A nil heartbeat never fires.
+type heartbeat struct {
+	timer clock.Timer
+}
+
+// newHeartbeat starts the heartbeat of a wait for blocks, or returns nil if
+// the client did not ask for heartbeats or they are disabled
+func (ds *deliverServer) newHeartbeat(seekInfo *ab.SeekInfo) *heartbeat {
+	if !seekInfo.Heartbeats || ds.heartbeatInterval <= 0 {
+		return nil
+	}
+	return &heartbeat{timer: ds.clock.NewTimer(ds.heartbeatInterval)}
+}
+
+// C returns the channel on which the heartbeat fires
+func (h *heartbeat) C() <-chan time.Time {
+	if h == nil {
+		return nil
+	}
+	return h.timer.C()
+}
+
+// stop stops the heartbeat of a wait which ended
+func (h *heartbeat) stop() {
+	if h != nil {
+		h.timer.Stop()
+	}
+}
+
+// sendHeartbeat tells the client the stream is waiting for the block at the
+// height of the chain
+func sendHeartbeat(srv ab.AtomicBroadcast_DeliverServer, height uint64) error {
+	return srv.Send(&ab.DeliverResponse{
+		Type: &ab.DeliverResponse_Heartbeat{Heartbeat: &ab.Heartbeat{Height: height}},
+	})
+}
@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeat(t *testing.T) {
+	mm := newMockMultichainManager()
+	ds := NewHandlerImpl(mm, Config{HeartbeatInterval: time.Second}).(*deliverServer)
+	clk := clock.NewManual(time.Now())
+	ds.clock = clk
+
+	m := newMockD()
+	defer close(m.recvChan)
+	go ds.Handle(m)
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(1), Stop: seekSpecified(1), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, Heartbeats: true})
+
+	// A heartbeat is sent every interval while the stream waits
+	for i := 0; i < 2; i++ {
+		clk.BlockUntil(1)
+		clk.Advance(time.Second)
+		select {
+		case deliverReply := <-m.sendChan:
+			require.NotNil(t, deliverReply.GetHeartbeat(), "Expected a heartbeat, got %v", deliverReply)
+			assert.Equal(t, uint64(1), deliverReply.GetHeartbeat().Height)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for heartbeat")
+		}
+	}
+
+	l := mm.chains[systemChainID].ledger
+	l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{{Payload: []byte("1")}}))
+	select {
+	case deliverReply := <-m.sendChan:
+		require.NotNil(t, deliverReply.GetBlock(), "Expected a block, got %v", deliverReply)
+		assert.Equal(t, uint64(1), deliverReply.GetBlock().Header.Number)
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for block")
+	}
+	assert.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).GetStatus())
+}
+
+func TestHeartbeatDisabled(t *testing.T) {
+	ds := NewHandlerImpl(newMockMultichainManager(), Config{}).(*deliverServer)
+	ds.clock = clock.NewManual(time.Now())
+	assert.Nil(t, ds.newHeartbeat(&ab.SeekInfo{Heartbeats: true}), "Heartbeats should be disabled by default")
+
+	ds.heartbeatInterval = time.Second
+	assert.Nil(t, ds.newHeartbeat(&ab.SeekInfo{}), "Heartbeats should only be sent to the clients asking for them")
+	assert.NotNil(t, ds.newHeartbeat(&ab.SeekInfo{Heartbeats: true}))
+
+	// A nil heartbeat never fires
+	var h *heartbeat
+	assert.Nil(t, h.C())
+	h.stop()
+}
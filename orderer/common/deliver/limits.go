@@ -0,0 +1,143 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"crypto/sha256"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+)
+
+// Limits are the limits on the Deliver streams served by a handler, so that
+// many clients replaying whole ledgers cannot exhaust the orderer. A limit of
+// 0 means no limit.
+type Limits struct {
+	// MaxStreams is the number of streams served at once
+	MaxStreams int
+	// MaxStreamsPerClient is the number of streams served at once to a
+	// single client, identified by its TLS client certificate, or by its
+	// address if it presented none
+	MaxStreamsPerClient int
+	// BytesPerSecond is the rate at which the blocks of each stream are sent,
+	// in bursts of up to a second's worth
+	BytesPerSecond int
+}
+
+// streamLimiter counts the streams served, in total and to each client. A nil
+// streamLimiter enforces no limit.
+type streamLimiter struct {
+	limits Limits
+
+	lock      sync.Mutex
+	total     int
+	perClient map[string]int
+}
+
+// newStreamLimiter creates a streamLimiter enforcing the limits, or returns
+// nil if no limit on the number of streams is set
+func newStreamLimiter(limits Limits) *streamLimiter {
+	if limits.MaxStreams <= 0 && limits.MaxStreamsPerClient <= 0 {
+		return nil
+	}
+	return &streamLimiter{limits: limits, perClient: make(map[string]int)}
+}
+
+// acquire takes a slot for a stream of the client of the context, returning
+// the function which releases it, or the reason the stream is refused
+func (sl *streamLimiter) acquire(ctx context.Context) (func(), string) {
+	if sl == nil {
+		return func() {}, ""
+	}
+	client := clientKey(ctx)
+
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+	if sl.limits.MaxStreams > 0 && sl.total >= sl.limits.MaxStreams {
+		return nil, "too_many_streams"
+	}
+	if sl.limits.MaxStreamsPerClient > 0 && sl.perClient[client] >= sl.limits.MaxStreamsPerClient {
+		return nil, "too_many_client_streams"
+	}
+	sl.total++
+	sl.perClient[client]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sl.lock.Lock()
+			defer sl.lock.Unlock()
+			sl.total--
+			if sl.perClient[client]--; sl.perClient[client] == 0 {
+				delete(sl.perClient, client)
+			}
+		})
+	}, ""
+}
+
+// clientKey identifies the client of the context by the fingerprint of its
+// TLS client certificate, or by its host if it presented none
+func clientKey(ctx context.Context) string {
+	if cert := comm.ExtractCertificateFromContext(ctx); cert != nil {
+		fingerprint := sha256.Sum256(cert.Raw)
+		return "cert/" + string(fingerprint[:])
+	}
+	pr, ok := peer.FromContext(ctx)
+	if !ok || pr.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(pr.Addr.String())
+	if err != nil {
+		host = pr.Addr.String()
+	}
+	return "ip/" + host
+}
+
+// pacer paces the blocks sent on a stream to a rate in bytes per second,
+// allowing bursts of up to a second's worth. A nil pacer does not pace.
+type pacer struct {
+	rate   float64
+	clock  clock.Clock
+	tokens float64
+	last   time.Time
+}
+
+// newPacer creates a pacer for the rate, or returns nil if it is not positive
+func newPacer(bytesPerSecond int, clk clock.Clock) *pacer {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	rate := float64(bytesPerSecond)
+	return &pacer{rate: rate, clock: clk, tokens: rate, last: clk.Now()}
+}
+
+// pace accounts for the bytes sent, waiting until the stream is back within
+// its rate or the stream ends
+func (p *pacer) pace(ctx context.Context, sent int) {
+	if p == nil {
+		return
+	}
+	now := p.clock.Now()
+	p.tokens += now.Sub(p.last).Seconds() * p.rate
+	if p.tokens > p.rate {
+		p.tokens = p.rate
+	}
+	p.last = now
+	p.tokens -= float64(sent)
+	if p.tokens >= 0 {
+		return
+	}
+	select {
+	case <-p.clock.After(time.Duration(-p.tokens / p.rate * float64(time.Second))):
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func clientContext(address string, cert *x509.Certificate) context.Context {
+	pr := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(address), Port: 7050}}
+	if cert != nil {
+		pr.AuthInfo = credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+	}
+	return peer.NewContext(context.Background(), pr)
+}
+
+func TestStreamLimiter(t *testing.T) {
+	t.Run("Unlimited", func(t *testing.T) {
+		assert.Nil(t, newStreamLimiter(Limits{BytesPerSecond: 100}))
+		var sl *streamLimiter
+		release, reason := sl.acquire(context.Background())
+		assert.Empty(t, reason)
+		release()
+	})
+
+	t.Run("Total", func(t *testing.T) {
+		sl := newStreamLimiter(Limits{MaxStreams: 2})
+		release1, _ := sl.acquire(clientContext("10.0.0.1", nil))
+		_, reason := sl.acquire(clientContext("10.0.0.2", nil))
+		assert.Empty(t, reason)
+		_, reason = sl.acquire(clientContext("10.0.0.3", nil))
+		assert.Equal(t, "too_many_streams", reason)
+
+		// Releasing twice frees a single slot
+		release1()
+		release1()
+		_, reason = sl.acquire(clientContext("10.0.0.3", nil))
+		assert.Empty(t, reason)
+		_, reason = sl.acquire(clientContext("10.0.0.4", nil))
+		assert.Equal(t, "too_many_streams", reason)
+	})
+
+	t.Run("PerClient", func(t *testing.T) {
+		sl := newStreamLimiter(Limits{MaxStreamsPerClient: 1})
+		cert := &x509.Certificate{Raw: []byte("client certificate")}
+		release, reason := sl.acquire(clientContext("10.0.0.1", cert))
+		assert.Empty(t, reason)
+
+		// The client is identified by its certificate rather than its address
+		_, reason = sl.acquire(clientContext("10.0.0.2", cert))
+		assert.Equal(t, "too_many_client_streams", reason)
+		_, reason = sl.acquire(clientContext("10.0.0.1", nil))
+		assert.Empty(t, reason)
+		_, reason = sl.acquire(clientContext("10.0.0.1", nil))
+		assert.Equal(t, "too_many_client_streams", reason)
+
+		release()
+		_, reason = sl.acquire(clientContext("10.0.0.2", cert))
+		assert.Empty(t, reason)
+		assert.Len(t, sl.perClient, 2)
+	})
+}
+
+func TestPacer(t *testing.T) {
+	assert.Nil(t, newPacer(0, clock.Real()))
+
+	clk := clock.NewManual(time.Now())
+	p := newPacer(1000, clk)
+
+	// A second's worth is sent without waiting
+	p.pace(context.Background(), 1000)
+
+	// Beyond it, the stream waits for the excess to be paid back
+	done := make(chan struct{})
+	go func() {
+		p.pace(context.Background(), 500)
+		close(done)
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(499 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatalf("Pacer returned before the excess was paid back")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Advance(time.Millisecond)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Pacer did not return once the excess was paid back")
+	}
+
+	// A stream which ends stops waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.pace(ctx, 10000)
+}
+
+func TestStreamLimits(t *testing.T) {
+	ds := NewHandlerImpl(newMockMultichainManager(), Config{Limits: Limits{MaxStreams: 1}})
+
+	m1 := newMockD()
+	go ds.Handle(m1)
+	m1.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekNewest, Stop: seekNewest})
+	<-m1.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, (<-m1.sendChan).GetStatus())
+
+	// The first stream holds the only slot until it ends
+	m2 := newMockD()
+	go ds.Handle(m2)
+	select {
+	case deliverReply := <-m2.sendChan:
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, deliverReply.GetStatus())
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the stream to be refused")
+	}
+
+	close(m1.recvChan)
+	sl := ds.(*deliverServer).streams
+	for i := 0; ; i++ {
+		sl.lock.Lock()
+		total := sl.total
+		sl.lock.Unlock()
+		if total == 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("The slot of the first stream was not released")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	m3 := newMockD()
+	defer close(m3.recvChan)
+	go ds.Handle(m3)
+	m3.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekNewest, Stop: seekNewest})
+	assert.NotNil(t, (<-m3.sendChan).GetBlock())
+	assert.Equal(t, cb.Status_SUCCESS, (<-m3.sendChan).GetStatus())
+}
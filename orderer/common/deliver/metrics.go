@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// handlerMetrics are the metrics updated by the handlers
+type handlerMetrics struct {
+	activeStreams metrics.Gauge
+	blocksSent    metrics.Counter
+	bytesSent     metrics.Counter
+	clientLag     metrics.Histogram
+	streamsEnded  metrics.Counter
+}
+
+// newHandlerMetrics creates the metrics of a handler with the provider, or in
+// the default registry if it is nil
+func newHandlerMetrics(provider metrics.Provider) *handlerMetrics {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &handlerMetrics{
+		activeStreams: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "deliver",
+			Name:       "streams",
+			Help:       "The number of deliver streams serving a seek request, by channel.",
+			LabelNames: []string{"channel"},
+		}),
+		blocksSent: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "deliver",
+			Name:       "blocks_sent_total",
+			Help:       "The number of blocks sent on deliver streams.",
+			LabelNames: []string{"channel"},
+		}),
+		bytesSent: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "deliver",
+			Name:       "bytes_sent_total",
+			Help:       "The size of the blocks sent on deliver streams.",
+			LabelNames: []string{"channel"},
+		}),
+		clientLag: provider.NewHistogram(metrics.HistogramOpts{
+			Opts: metrics.Opts{
+				Namespace:  "orderer",
+				Subsystem:  "deliver",
+				Name:       "lag_blocks",
+				Help:       "The number of blocks of the chain beyond the last block sent to a stream, observed at each response.",
+				LabelNames: []string{"channel"},
+			},
+			Buckets: []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 10000},
+		}),
+		streamsEnded: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "deliver",
+			Name:       "streams_ended_total",
+			Help:       "The number of deliver streams ended, by channel and reason. The channel is empty for streams which ended between seek requests.",
+			LabelNames: []string{"channel", "reason"},
+		}),
+	}
+}
+
+// channelLabel returns the label of the channel in the metrics, which is
+// empty for channels which do not exist, so that clients cannot create
+// arbitrary numbers of series
+func (ds *deliverServer) channelLabel(chainID string) string {
+	if chainID != "" {
+		if _, ok := ds.sm.GetChain(chainID); ok {
+			return chainID
+		}
+	}
+	return ""
+}
+
+// serving counts the stream as serving a seek request to the chain, and
+// returns the function which counts the end of the request
+func (ds *deliverServer) serving(chainID string) func() {
+	ds.metrics.activeStreams.With(chainID).Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ds.metrics.activeStreams.With(chainID).Add(-1)
+		})
+	}
+}
+
+// countSent counts the blocks sent to a stream of the chain, in a response
+// of the given size, and how far the stream lags behind the chain
+func (ds *deliverServer) countSent(chain Support, chainID string, blocks []*cb.Block, size int) {
+	ds.metrics.blocksSent.With(chainID).Add(float64(len(blocks)))
+	ds.metrics.bytesSent.With(chainID).Add(float64(size))
+	next := blocks[len(blocks)-1].Header.Number + 1
+	if height := chain.Reader().Height(); height > next {
+		ds.metrics.clientLag.With(chainID).Observe(float64(height - next))
+	} else {
+		ds.metrics.clientLag.With(chainID).Observe(0)
+	}
+}
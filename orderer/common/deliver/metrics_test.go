@@ -0,0 +1,118 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gathered returns the series of the metric of the registry with the label
+// values
+func gathered(registry *metrics.Registry, name string, labelValues ...string) metrics.Series {
+	for _, f := range registry.Gather() {
+		if f.Name != name {
+			continue
+		}
+		for _, s := range f.Series {
+			if fmt.Sprint(s.LabelValues) == fmt.Sprint(labelValues) {
+				return s
+			}
+		}
+	}
+	return metrics.Series{}
+}
+
+func TestMetricsSent(t *testing.T) {
+	mm := newGatewayManager()
+	registry := metrics.NewRegistry()
+	ds := NewHandlerImpl(mm, Config{Metrics: registry})
+
+	m := newMockD()
+	defer close(m.recvChan)
+	go ds.Handle(m)
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekSpecified(4), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+	for i := 0; i < 5; i++ {
+		require.NotNil(t, (<-m.sendChan).GetBlock())
+	}
+	require.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).GetStatus())
+
+	assert.Equal(t, float64(5), gathered(registry, "orderer_deliver_blocks_sent_total", systemChainID).Value)
+	assert.True(t, gathered(registry, "orderer_deliver_bytes_sent_total", systemChainID).Value > 0, "Expected the bytes sent to be counted")
+	lag := gathered(registry, "orderer_deliver_lag_blocks", systemChainID)
+	assert.Equal(t, uint64(5), lag.Count)
+	// Blocks 0 to 4 of 10 lag by 9 to 5 blocks
+	assert.Equal(t, float64(9+8+7+6+5), lag.Sum)
+}
+
+func TestMetricsActiveStreams(t *testing.T) {
+	mm := newGatewayManager()
+	registry := metrics.NewRegistry()
+	ds := NewHandlerImpl(mm, Config{Metrics: registry})
+
+	m := newMockD()
+	go ds.Handle(m)
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(ledgerSize), Stop: seekSpecified(ledgerSize), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+	for i := 0; gathered(registry, "orderer_deliver_streams", systemChainID).Value != 1; i++ {
+		if i == 100 {
+			t.Fatalf("The stream waiting for a block was not counted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	l := mm.chains[systemChainID].ledger
+	l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{{Payload: []byte("next")}}))
+	require.NotNil(t, (<-m.sendChan).GetBlock())
+	require.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).GetStatus())
+	assert.Equal(t, float64(0), gathered(registry, "orderer_deliver_streams", systemChainID).Value, "The stream should no longer be counted once its request is served")
+
+	close(m.recvChan)
+	for i := 0; gathered(registry, "orderer_deliver_streams_ended_total", "", "client_closed").Value != 1; i++ {
+		if i == 100 {
+			t.Fatalf("The stream closed by its client was not counted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMetricsStreamsEnded(t *testing.T) {
+	mm := newGatewayManager()
+	registry := metrics.NewRegistry()
+	ds := NewHandlerImpl(mm, Config{Metrics: registry})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mm.chains[systemChainID].policyManager.Policy.Err = fmt.Errorf("Fail to evaluate policy")
+		defer func() { mm.chains[systemChainID].policyManager.Policy.Err = nil }()
+
+		m := newMockD()
+		defer close(m.recvChan)
+		go ds.Handle(m)
+		m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekOldest})
+		assert.Equal(t, cb.Status_FORBIDDEN, (<-m.sendChan).GetStatus())
+		assert.Equal(t, float64(1), gathered(registry, "orderer_deliver_streams_ended_total", systemChainID, "forbidden").Value)
+	})
+
+	t.Run("UnknownChannel", func(t *testing.T) {
+		m := newMockD()
+		defer close(m.recvChan)
+		go ds.Handle(m)
+		m.recvChan <- makeSeek("unknown", &ab.SeekInfo{Start: seekOldest, Stop: seekOldest})
+		assert.Equal(t, cb.Status_NOT_FOUND, (<-m.sendChan).GetStatus())
+		// Channels which do not exist are not labelled, so that clients
+		// cannot create series
+		assert.Equal(t, float64(1), gathered(registry, "orderer_deliver_streams_ended_total", "", "channel_not_found").Value)
+		assert.Equal(t, float64(0), gathered(registry, "orderer_deliver_streams_ended_total", "unknown", "channel_not_found").Value)
+	})
+}
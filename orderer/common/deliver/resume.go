@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// resumeToken returns the token recording that the block of the chain was
+// the last delivered, so that the stream resumes after it
+func resumeToken(chainID string, block *cb.Block) ([]byte, error) {
+	return proto.Marshal(&ab.ResumePoint{
+		ChannelId:    chainID,
+		Number:       block.Header.Number + 1,
+		PreviousHash: block.Header.Hash(),
+	})
+}
+
+// resumePosition returns the position at which the stream recorded by the
+// token resumes, after checking the token was issued for the chain and that
+// the ledger of the chain still holds the last block delivered. Otherwise it
+// returns the status and reason with which the request fails.
+func resumePosition(chain Support, chainID string, token []byte) (*ab.SeekPosition, cb.Status, string) {
+	point := &ab.ResumePoint{}
+	if err := pool.Unmarshal(token, point); err != nil || point.Number == 0 {
+		return nil, cb.Status_BAD_REQUEST, "invalid_resume_token"
+	}
+	if point.ChannelId != chainID {
+		return nil, cb.Status_BAD_REQUEST, "invalid_resume_token"
+	}
+	previous := ledger.GetBlock(chain.Reader(), point.Number-1)
+	if previous == nil || !bytes.Equal(previous.Header.Hash(), point.PreviousHash) {
+		return nil, cb.Status_NOT_FOUND, "resume_point_not_found"
+	}
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: point.Number}}}, cb.Status_SUCCESS, ""
+}
+
+// sendResumeToken sends the token recording that the block was the last
+// delivered on the stream
+func sendResumeToken(srv ab.AtomicBroadcast_DeliverServer, chainID string, block *cb.Block) error {
+	token, err := resumeToken(chainID, block)
+	if err != nil {
+		return err
+	}
+	return srv.Send(&ab.DeliverResponse{
+		Type: &ab.DeliverResponse_ResumeToken{ResumeToken: &ab.ResumeToken{Token: token}},
+	})
+}
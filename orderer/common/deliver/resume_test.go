@@ -0,0 +1,134 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func seekResume(token []byte) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Resume{Resume: &ab.SeekResume{Token: token}}}
+}
+
+// receive returns the responses sent on the stream up to and including the
+// status
+func receive(t *testing.T, m *mockD) []*ab.DeliverResponse {
+	var replies []*ab.DeliverResponse
+	for {
+		select {
+		case deliverReply := <-m.sendChan:
+			replies = append(replies, deliverReply)
+			if _, ok := deliverReply.Type.(*ab.DeliverResponse_Status); ok {
+				return replies
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the status")
+		}
+	}
+}
+
+func TestResumeToken(t *testing.T) {
+	mm := newMockMultichainManager()
+	for i := 1; i < ledgerSize; i++ {
+		l := mm.chains[systemChainID].ledger
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+	}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	go NewHandlerImpl(mm, Config{}).Handle(m)
+
+	// A token is sent after every third block
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekSpecified(7), ResumeInterval: 3})
+	var delivered []string
+	var tokens [][]byte
+	for _, reply := range receive(t, m) {
+		switch r := reply.Type.(type) {
+		case *ab.DeliverResponse_Block:
+			delivered = append(delivered, fmt.Sprintf("block %d", r.Block.Header.Number))
+		case *ab.DeliverResponse_ResumeToken:
+			delivered = append(delivered, "token")
+			tokens = append(tokens, r.ResumeToken.Token)
+		case *ab.DeliverResponse_Status:
+			assert.Equal(t, cb.Status_SUCCESS, r.Status)
+		}
+	}
+	assert.Equal(t, []string{"block 0", "block 1", "block 2", "token", "block 3", "block 4", "block 5", "token", "block 6", "block 7"}, delivered)
+
+	// The stream resumes after the last block delivered before the token
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekResume(tokens[1]), Stop: seekNewest})
+	replies := receive(t, m)
+	if assert.Len(t, replies, 5) {
+		for i, number := range []uint64{6, 7, 8, 9} {
+			assert.Equal(t, number, replies[i].GetBlock().GetHeader().GetNumber())
+		}
+	}
+}
+
+func TestResumeTokenRejected(t *testing.T) {
+	mm := newMockMultichainManager()
+	l := mm.chains[systemChainID].ledger
+	for i := 1; i < ledgerSize; i++ {
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte(fmt.Sprintf("%d", i))}}))
+	}
+	valid, err := resumeToken(systemChainID, ledger.GetBlock(l, 3))
+	assert.NoError(t, err)
+
+	for _, testCase := range []struct {
+		name     string
+		seekInfo *ab.SeekInfo
+		status   cb.Status
+	}{
+		{
+			name:     "Malformed",
+			seekInfo: &ab.SeekInfo{Start: seekResume([]byte("garbage")), Stop: seekNewest},
+			status:   cb.Status_BAD_REQUEST,
+		},
+		{
+			name:     "OtherChannel",
+			seekInfo: &ab.SeekInfo{Start: seekResume(utils.MarshalOrPanic(&ab.ResumePoint{ChannelId: "other", Number: 4, PreviousHash: ledger.GetBlock(l, 3).Header.Hash()})), Stop: seekNewest},
+			status:   cb.Status_BAD_REQUEST,
+		},
+		{
+			name:     "HashMismatch",
+			seekInfo: &ab.SeekInfo{Start: seekResume(utils.MarshalOrPanic(&ab.ResumePoint{ChannelId: systemChainID, Number: 4, PreviousHash: []byte("other")})), Stop: seekNewest},
+			status:   cb.Status_NOT_FOUND,
+		},
+		{
+			name:     "BeyondLedger",
+			seekInfo: &ab.SeekInfo{Start: seekResume(utils.MarshalOrPanic(&ab.ResumePoint{ChannelId: systemChainID, Number: 2 * ledgerSize, PreviousHash: []byte("other")})), Stop: seekNewest},
+			status:   cb.Status_NOT_FOUND,
+		},
+		{
+			name:     "AsStop",
+			seekInfo: &ab.SeekInfo{Start: seekOldest, Stop: seekResume(valid)},
+			status:   cb.Status_BAD_REQUEST,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			m := newMockD()
+			defer close(m.recvChan)
+			go NewHandlerImpl(mm, Config{}).Handle(m)
+
+			m.recvChan <- makeSeek(systemChainID, testCase.seekInfo)
+			select {
+			case deliverReply := <-m.sendChan:
+				assert.Equal(t, testCase.status, deliverReply.GetStatus())
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting for the status")
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package events publishes the blocks committed by the orderer to external
+// systems, such as message buses and webhooks, so that they may react to
+// ordering without running Deliver clients.
+package events
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/events")
+
+// Payload formats of the published messages
+const (
+	// FormatBlock is the marshaled block
+	FormatBlock = "block"
+	// FormatFiltered is a JSON summary of the block and its transactions
+	FormatFiltered = "filtered"
+	// FormatHeader is a JSON summary of the header of the block
+	FormatHeader = "header"
+)
+
+// Content types of the published payloads
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// Message is the event published for a block
+type Message struct {
+	ChannelID   string
+	Number      uint64
+	ContentType string
+	Payload     []byte
+}
+
+// Sink is a destination of the published messages
+type Sink interface {
+	// Name identifies the sink, it must be unique amongst the sinks of a
+	// publisher and stable across restarts, as it keys the progress of the
+	// sink
+	Name() string
+	// Publish delivers the message, returning once it has been accepted by
+	// the destination. Messages are retried until Publish succeeds.
+	Publish(msg *Message) error
+}
+
+// Header is the payload of FormatHeader
+type Header struct {
+	Channel          string `json:"channel"`
+	Number           uint64 `json:"number"`
+	Hash             string `json:"hash"`
+	PreviousHash     string `json:"previous_hash"`
+	DataHash         string `json:"data_hash"`
+	TransactionCount int    `json:"transaction_count"`
+}
+
+// Filtered is the payload of FormatFiltered
+type Filtered struct {
+	Header
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Transaction summarizes a transaction of a filtered block
+type Transaction struct {
+	TxID      string     `json:"txid,omitempty"`
+	Type      string     `json:"type"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// ValidFormat reports whether the format is one of the payload formats
+func ValidFormat(format string) bool {
+	switch format {
+	case FormatBlock, FormatFiltered, FormatHeader:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewMessage returns the message published for a block of the channel in
+// the payload format
+func NewMessage(chainID string, block *cb.Block, format string) (*Message, error) {
+	msg := &Message{ChannelID: chainID, Number: block.GetHeader().GetNumber(), ContentType: ContentTypeJSON}
+	var err error
+	switch format {
+	case FormatBlock:
+		msg.ContentType = ContentTypeProtobuf
+		msg.Payload, err = utils.Marshal(block)
+	case FormatHeader:
+		msg.Payload, err = json.Marshal(newHeader(chainID, block))
+	case FormatFiltered:
+		msg.Payload, err = json.Marshal(newFiltered(chainID, block))
+	default:
+		err = fmt.Errorf("unknown payload format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func newHeader(chainID string, block *cb.Block) Header {
+	return Header{
+		Channel:          chainID,
+		Number:           block.Header.Number,
+		Hash:             hex.EncodeToString(block.Header.Hash()),
+		PreviousHash:     hex.EncodeToString(block.Header.PreviousHash),
+		DataHash:         hex.EncodeToString(block.Header.DataHash),
+		TransactionCount: len(block.GetData().GetData()),
+	}
+}
+
+func newFiltered(chainID string, block *cb.Block) Filtered {
+	filtered := Filtered{Header: newHeader(chainID, block), Transactions: []Transaction{}}
+	for _, data := range block.GetData().GetData() {
+		filtered.Transactions = append(filtered.Transactions, summarize(data))
+	}
+	return filtered
+}
+
+// summarize returns the summary of a transaction, which is of an unknown type
+// if it cannot be decoded
+func summarize(data []byte) Transaction {
+	tx := Transaction{Type: "UNKNOWN"}
+	env, err := utils.UnmarshalEnvelope(data)
+	if err != nil {
+		return tx
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil || payload.Header == nil {
+		return tx
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return tx
+	}
+	tx.TxID = chdr.TxId
+	tx.Type = cb.HeaderType(chdr.Type).String()
+	if chdr.Timestamp != nil {
+		timestamp := time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos)).UTC()
+		tx.Timestamp = &timestamp
+	}
+	return tx
+}
@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package events
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlock() *cb.Block {
+	chdr := utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0)
+	chdr.TxId = "tx1"
+	chdr.Timestamp = &timestamp.Timestamp{Seconds: 1500000000}
+	env := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{}),
+	})}
+	block := cb.NewBlock(3, []byte("previous"))
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env), []byte("garbage")}
+	block.Header.DataHash = block.Data.Hash()
+	return block
+}
+
+func TestNewMessage(t *testing.T) {
+	block := testBlock()
+
+	msg, err := NewMessage("foo", block, FormatBlock)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", msg.ChannelID)
+	assert.Equal(t, uint64(3), msg.Number)
+	assert.Equal(t, ContentTypeProtobuf, msg.ContentType)
+	decoded := &cb.Block{}
+	require.NoError(t, proto.Unmarshal(msg.Payload, decoded))
+	assert.True(t, proto.Equal(block, decoded))
+
+	msg, err = NewMessage("foo", block, FormatHeader)
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeJSON, msg.ContentType)
+	var header Header
+	require.NoError(t, json.Unmarshal(msg.Payload, &header))
+	assert.Equal(t, Header{
+		Channel:          "foo",
+		Number:           3,
+		Hash:             hex.EncodeToString(block.Header.Hash()),
+		PreviousHash:     hex.EncodeToString([]byte("previous")),
+		DataHash:         hex.EncodeToString(block.Header.DataHash),
+		TransactionCount: 2,
+	}, header)
+
+	msg, err = NewMessage("foo", block, FormatFiltered)
+	require.NoError(t, err)
+	var filtered Filtered
+	require.NoError(t, json.Unmarshal(msg.Payload, &filtered))
+	assert.Equal(t, header, filtered.Header)
+	require.Len(t, filtered.Transactions, 2)
+	assert.Equal(t, "tx1", filtered.Transactions[0].TxID)
+	assert.Equal(t, "ENDORSER_TRANSACTION", filtered.Transactions[0].Type)
+	require.NotNil(t, filtered.Transactions[0].Timestamp)
+	assert.Equal(t, int64(1500000000), filtered.Transactions[0].Timestamp.Unix())
+	assert.Equal(t, Transaction{Type: "UNKNOWN"}, filtered.Transactions[1])
+
+	_, err = NewMessage("foo", block, "bogus")
+	assert.EqualError(t, err, "unknown payload format bogus")
+}
+
+func TestValidFormat(t *testing.T) {
+	for _, format := range []string{FormatBlock, FormatFiltered, FormatHeader} {
+		assert.True(t, ValidFormat(format), format)
+	}
+	assert.False(t, ValidFormat(""))
+}
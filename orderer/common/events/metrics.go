@@ -0,0 +1,57 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package events
+
+import (
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+)
+
+// publisherMetrics are the metrics updated by the publisher
+type publisherMetrics struct {
+	published  metrics.Counter
+	retries    metrics.Counter
+	dropped    metrics.Counter
+	readErrors metrics.Counter
+}
+
+// newPublisherMetrics creates the metrics of a publisher with the provider,
+// or in the default registry if it is nil
+func newPublisherMetrics(provider metrics.Provider) *publisherMetrics {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &publisherMetrics{
+		published: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "events",
+			Name:       "published_total",
+			Help:       "The number of messages accepted by each sink, by sink and channel.",
+			LabelNames: []string{"sink", "channel"},
+		}),
+		retries: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "events",
+			Name:       "retries_total",
+			Help:       "The number of times a message was retried after a sink failed to accept it, by sink and channel.",
+			LabelNames: []string{"sink", "channel"},
+		}),
+		dropped: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "events",
+			Name:       "dropped_total",
+			Help:       "The number of blocks which were not published, because their message could not be created or the sink did not accept it within the maximum retries, by sink and channel.",
+			LabelNames: []string{"sink", "channel"},
+		}),
+		readErrors: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "events",
+			Name:       "read_errors_total",
+			Help:       "The number of failures to read a block from the ledger of a channel being published, by sink and channel.",
+			LabelNames: []string{"sink", "channel"},
+		}),
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+const (
+	defaultRetryInitial = time.Second
+	defaultRetryMax     = time.Minute
+	defaultPollInterval = 5 * time.Second
+)
+
+// Support provides the ledgers of the channels whose blocks are published
+type Support interface {
+	// ChannelIDs returns the IDs of the channels of the orderer
+	ChannelIDs() []string
+	// Reader returns the ledger of the channel
+	Reader(chainID string) (ledger.Reader, bool)
+}
+
+// Config is the configuration of a Publisher
+type Config struct {
+	// Format is the payload format of the messages
+	Format string
+	// Sinks are the destinations of the messages, each is published to
+	// independently so that a failing sink does not hold up the others
+	Sinks []Sink
+	// Progress records the blocks each sink has published, those of
+	// channels it holds no progress for are published from the newest block
+	// if FromNewest is set and from the oldest otherwise
+	Progress   *Progress
+	FromNewest bool
+	// RetryInitial is the delay before a message which failed to publish, or
+	// a block which could not be read, is retried, doubling on each failure
+	// up to RetryMax
+	RetryInitial time.Duration
+	RetryMax     time.Duration
+	// MaxRetries, if positive, is the number of times a message is retried
+	// before it is dropped, so that a message the sink keeps rejecting does
+	// not hold up the channel forever. Messages are retried until they are
+	// accepted otherwise.
+	MaxRetries int
+	// PollInterval is how often new channels are looked for
+	PollInterval time.Duration
+	// Clock times the retries and polls, the wall clock if nil
+	Clock clock.Clock
+	// Metrics, if set, creates the metrics of the publisher instead of the
+	// default registry
+	Metrics metrics.Provider
+}
+
+// Publisher publishes each block of every channel to the sinks, in order,
+// with at-least-once semantics: a message is retried until the sink accepts
+// it, or until MaxRetries if it is set, and the progress of the sink is only
+// recorded afterwards, so that the messages which were not known to be
+// accepted are published again after a restart. The blocks which are
+// dropped, and the failures to read the ledgers, which are retried, are
+// logged and counted in the metrics.
+type Publisher struct {
+	support Support
+	config  Config
+	metrics *publisherMetrics
+
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	mutex    sync.Mutex
+	followed map[string]bool
+}
+
+// NewPublisher creates a publisher of the blocks of the channels of support
+func NewPublisher(support Support, config Config) (*Publisher, error) {
+	if !ValidFormat(config.Format) {
+		return nil, fmt.Errorf("unknown payload format %s", config.Format)
+	}
+	names := make(map[string]bool)
+	for _, sink := range config.Sinks {
+		if names[sink.Name()] {
+			return nil, fmt.Errorf("duplicate sink %s", sink.Name())
+		}
+		names[sink.Name()] = true
+	}
+	if config.Progress == nil {
+		config.Progress = NewMemoryProgress()
+	}
+	if config.RetryInitial <= 0 {
+		config.RetryInitial = defaultRetryInitial
+	}
+	if config.RetryMax < config.RetryInitial {
+		config.RetryMax = defaultRetryMax
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real()
+	}
+	return &Publisher{
+		support:  support,
+		config:   config,
+		metrics:  newPublisherMetrics(config.Metrics),
+		stop:     make(chan struct{}),
+		followed: make(map[string]bool),
+	}, nil
+}
+
+// Start publishes the blocks of the existing channels, and those of new
+// channels as they are found, until Stop is called
+func (p *Publisher) Start() {
+	p.followChannels()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			timer := p.config.Clock.NewTimer(p.config.PollInterval)
+			select {
+			case <-timer.C():
+				p.followChannels()
+			case <-p.stop:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops publishing, abandoning the messages being retried, and waits
+// for the publication of the messages in flight to complete
+func (p *Publisher) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// followChannels starts publishing the blocks of the channels which are not
+// yet followed
+func (p *Publisher) followChannels() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, chainID := range p.support.ChannelIDs() {
+		if p.followed[chainID] {
+			continue
+		}
+		reader, ok := p.support.Reader(chainID)
+		if !ok {
+			continue
+		}
+		p.followed[chainID] = true
+		for _, sink := range p.config.Sinks {
+			p.wg.Add(1)
+			go func(sink Sink, chainID string) {
+				defer p.wg.Done()
+				p.publish(sink, chainID, reader)
+			}(sink, chainID)
+		}
+	}
+}
+
+// publish publishes the blocks of the channel to the sink, from the block
+// following the last one it published
+func (p *Publisher) publish(sink Sink, chainID string, reader ledger.Reader) {
+	var start *ab.SeekPosition
+	switch next, ok := p.config.Progress.Next(sink.Name(), chainID); {
+	case ok:
+		start = &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: next}}}
+	case p.config.FromNewest:
+		start = &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}
+	default:
+		start = &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}}
+	}
+	it, number := reader.Iterator(start)
+	logger.Debugf("Publishing the blocks of channel %s to %s from block %d", chainID, sink.Name(), number)
+
+	readDelay := p.config.RetryInitial
+	for {
+		select {
+		case <-it.ReadyChan():
+		case <-p.stop:
+			return
+		}
+		block, status := it.Next()
+		if status != cb.Status_SUCCESS {
+			p.metrics.readErrors.With(sink.Name(), chainID).Add(1)
+			logger.Errorf("Could not read block %d of channel %s to publish to %s, retrying in %s: %s", number, chainID, sink.Name(), readDelay, status)
+			if !p.wait(readDelay) {
+				return
+			}
+			readDelay = p.backoff(readDelay)
+			// The iterator does not recover from a failure, resume from the
+			// block which could not be read with a new one
+			it, number = reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: number}}})
+			continue
+		}
+		readDelay = p.config.RetryInitial
+
+		msg, err := NewMessage(chainID, block, p.config.Format)
+		if err != nil {
+			p.metrics.dropped.With(sink.Name(), chainID).Add(1)
+			logger.Errorf("Dropped block %d of channel %s for %s, could not create its message: %s", block.Header.Number, chainID, sink.Name(), err)
+		} else if !p.publishMessage(sink, msg) {
+			return
+		}
+		if err := p.config.Progress.Published(sink.Name(), chainID, block.Header.Number); err != nil {
+			logger.Warningf("Could not record the publication of block %d of channel %s to %s: %s", block.Header.Number, chainID, sink.Name(), err)
+		}
+		number = block.Header.Number + 1
+	}
+}
+
+// publishMessage publishes the message to the sink, retrying until it
+// succeeds, its retries are exhausted and it is dropped, or the publisher is
+// stopped, in which case it returns false
+func (p *Publisher) publishMessage(sink Sink, msg *Message) bool {
+	delay := p.config.RetryInitial
+	for retries := 0; ; retries++ {
+		err := sink.Publish(msg)
+		if err == nil {
+			p.metrics.published.With(sink.Name(), msg.ChannelID).Add(1)
+			return true
+		}
+		if p.config.MaxRetries > 0 && retries >= p.config.MaxRetries {
+			p.metrics.dropped.With(sink.Name(), msg.ChannelID).Add(1)
+			logger.Errorf("Dropped block %d of channel %s for %s after %d retries: %s", msg.Number, msg.ChannelID, sink.Name(), retries, err)
+			return true
+		}
+		p.metrics.retries.With(sink.Name(), msg.ChannelID).Add(1)
+		logger.Warningf("Could not publish block %d of channel %s to %s, retrying in %s: %s", msg.Number, msg.ChannelID, sink.Name(), delay, err)
+		if !p.wait(delay) {
+			return false
+		}
+		delay = p.backoff(delay)
+	}
+}
+
+// wait waits for the delay, returning false if the publisher is stopped
+// first
+func (p *Publisher) wait(delay time.Duration) bool {
+	select {
+	case <-p.config.Clock.After(delay):
+		return true
+	case <-p.stop:
+		return false
+	}
+}
+
+// backoff returns the delay following the delay of a failed attempt, twice
+// as long up to RetryMax
+func (p *Publisher) backoff(delay time.Duration) time.Duration {
+	if delay *= 2; delay > p.config.RetryMax {
+		delay = p.config.RetryMax
+	}
+	return delay
+}
+
+// Progress records, for each sink and channel, the number of the next block
+// to publish. It is persisted to a file, if it has one, after each update.
+type Progress struct {
+	path string
+
+	mutex sync.Mutex
+	next  map[string]uint64
+}
+
+// NewMemoryProgress returns a progress which is not persisted
+func NewMemoryProgress() *Progress {
+	return &Progress{next: make(map[string]uint64)}
+}
+
+// LoadProgress returns the progress persisted at path, which is empty if the
+// file does not exist
+func LoadProgress(path string) (*Progress, error) {
+	p := &Progress{path: path, next: make(map[string]uint64)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &p.next); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", path, err)
+	}
+	return p, nil
+}
+
+func progressKey(sinkName, chainID string) string {
+	return sinkName + "/" + chainID
+}
+
+// Next returns the number of the next block of the channel to publish to the
+// sink, if any was published
+func (p *Progress) Next(sinkName, chainID string) (uint64, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	next, ok := p.next[progressKey(sinkName, chainID)]
+	return next, ok
+}
+
+// Published records that the block of the channel was published to the sink
+func (p *Progress) Published(sinkName, chainID string, number uint64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.next[progressKey(sinkName, chainID)] = number + 1
+	if p.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(p.next)
+	if err != nil {
+		return err
+	}
+	// Replace the file atomically so that a crash leaves either version
+	tmp, err := ioutil.TempFile(filepath.Dir(p.path), filepath.Base(p.path))
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p.path)
+}
@@ -0,0 +1,361 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package events
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSupport struct {
+	lf ledger.Factory
+	// readErrors, if set, is the number of reads of the ledgers which fail
+	readErrors *int32
+}
+
+func (ms *mockSupport) ChannelIDs() []string {
+	return ms.lf.ChainIDs()
+}
+
+func (ms *mockSupport) Reader(chainID string) (ledger.Reader, bool) {
+	rl, err := ms.lf.GetOrCreate(chainID)
+	if err != nil {
+		return nil, false
+	}
+	if ms.readErrors != nil {
+		return &failingReader{Reader: rl, failures: ms.readErrors}, true
+	}
+	return rl, true
+}
+
+// failingReader is a ledger whose iterators fail to read the first failures
+// blocks
+type failingReader struct {
+	ledger.Reader
+	failures *int32
+}
+
+func (fr *failingReader) Iterator(start *ab.SeekPosition) (ledger.Iterator, uint64) {
+	it, number := fr.Reader.Iterator(start)
+	return &failingIterator{Iterator: it, failures: fr.failures}, number
+}
+
+type failingIterator struct {
+	ledger.Iterator
+	failures *int32
+	failed   bool
+}
+
+func (fi *failingIterator) Next() (*cb.Block, cb.Status) {
+	// An iterator which failed keeps failing, as those of the ledgers do
+	if fi.failed || atomic.AddInt32(fi.failures, -1) >= 0 {
+		fi.failed = true
+		return nil, cb.Status_SERVICE_UNAVAILABLE
+	}
+	return fi.Iterator.Next()
+}
+
+// recordingSink records the messages it accepts, failing the first failures
+// attempts
+type recordingSink struct {
+	name     string
+	failures int
+
+	mutex    sync.Mutex
+	attempts int
+	messages []*Message
+}
+
+func (rs *recordingSink) Name() string {
+	return rs.name
+}
+
+func (rs *recordingSink) Publish(msg *Message) error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.attempts++
+	if rs.attempts <= rs.failures {
+		return fmt.Errorf("sink unavailable")
+	}
+	rs.messages = append(rs.messages, msg)
+	return nil
+}
+
+func (rs *recordingSink) published() []string {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	var published []string
+	for _, msg := range rs.messages {
+		published = append(published, fmt.Sprintf("%s/%d", msg.ChannelID, msg.Number))
+	}
+	return published
+}
+
+// eventually waits for the condition to hold
+func eventually(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func appendBlocks(t *testing.T, lf ledger.Factory, chainID string, count int) {
+	rl, err := lf.GetOrCreate(chainID)
+	require.NoError(t, err)
+	for i := 0; i < count; i++ {
+		require.NoError(t, rl.Append(ledger.CreateNextBlock(rl, []*cb.Envelope{{Payload: []byte(fmt.Sprintf("%s %d", chainID, i))}})))
+	}
+}
+
+func TestNewPublisherValidation(t *testing.T) {
+	_, err := NewPublisher(&mockSupport{}, Config{Format: "bogus"})
+	assert.EqualError(t, err, "unknown payload format bogus")
+
+	_, err = NewPublisher(&mockSupport{}, Config{Format: FormatHeader, Sinks: []Sink{&recordingSink{name: "a"}, &recordingSink{name: "a"}}})
+	assert.EqualError(t, err, "duplicate sink a")
+
+	p, err := NewPublisher(&mockSupport{}, Config{Format: FormatHeader})
+	require.NoError(t, err)
+	assert.NotNil(t, p.config.Progress)
+	assert.Equal(t, defaultRetryInitial, p.config.RetryInitial)
+	assert.Equal(t, defaultRetryMax, p.config.RetryMax)
+	assert.Equal(t, defaultPollInterval, p.config.PollInterval)
+}
+
+func TestPublisher(t *testing.T) {
+	lf := ramledger.New(100)
+	appendBlocks(t, lf, "foo", 3)
+
+	healthy := &recordingSink{name: "healthy"}
+	flaky := &recordingSink{name: "flaky", failures: 2}
+	progress := NewMemoryProgress()
+	p, err := NewPublisher(&mockSupport{lf: lf}, Config{
+		Format:       FormatHeader,
+		Sinks:        []Sink{healthy, flaky},
+		Progress:     progress,
+		RetryInitial: time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	p.Start()
+	defer p.Stop()
+
+	expected := []string{"foo/0", "foo/1", "foo/2"}
+	eventually(t, func() bool { return len(flaky.published()) == 3 })
+	assert.Equal(t, expected, healthy.published())
+	assert.Equal(t, expected, flaky.published(), "Failed messages should be retried in order")
+
+	appendBlocks(t, lf, "foo", 1)
+	appendBlocks(t, lf, "bar", 1)
+	eventually(t, func() bool { return len(healthy.published()) == 5 })
+	// The channels are published independently, so the new block of foo
+	// and the first block of bar may be published in either order
+	assert.Contains(t, healthy.published()[3:], "foo/3")
+	assert.Contains(t, healthy.published(), "bar/0", "Blocks of new channels should be published")
+
+	eventually(t, func() bool {
+		next, _ := progress.Next("flaky", "bar")
+		return next == 1
+	})
+	next, ok := progress.Next("healthy", "foo")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), next)
+}
+
+func TestPublisherResumes(t *testing.T) {
+	lf := ramledger.New(100)
+	appendBlocks(t, lf, "foo", 3)
+
+	progress := NewMemoryProgress()
+	require.NoError(t, progress.Published("sink", "foo", 1))
+	sink := &recordingSink{name: "sink"}
+	p, err := NewPublisher(&mockSupport{lf: lf}, Config{Format: FormatBlock, Sinks: []Sink{sink}, Progress: progress})
+	require.NoError(t, err)
+	p.Start()
+	defer p.Stop()
+
+	eventually(t, func() bool { return len(sink.published()) == 1 })
+	assert.Equal(t, []string{"foo/2"}, sink.published())
+}
+
+func TestPublisherFromNewest(t *testing.T) {
+	lf := ramledger.New(100)
+	appendBlocks(t, lf, "foo", 3)
+
+	sink := &recordingSink{name: "sink"}
+	p, err := NewPublisher(&mockSupport{lf: lf}, Config{Format: FormatBlock, Sinks: []Sink{sink}, FromNewest: true})
+	require.NoError(t, err)
+	p.Start()
+	defer p.Stop()
+
+	eventually(t, func() bool { return len(sink.published()) == 1 })
+	assert.Equal(t, []string{"foo/2"}, sink.published())
+}
+
+func TestPublisherRetryBackoff(t *testing.T) {
+	lf := ramledger.New(100)
+	appendBlocks(t, lf, "foo", 1)
+	sink := &recordingSink{name: "sink", failures: 2}
+	clk := clock.NewManual(time.Unix(0, 0))
+	p, err := NewPublisher(&mockSupport{lf: lf}, Config{
+		Format:       FormatBlock,
+		Sinks:        []Sink{sink},
+		RetryInitial: time.Second,
+		RetryMax:     2 * time.Second,
+		PollInterval: time.Hour,
+		Clock:        clk,
+	})
+	require.NoError(t, err)
+	p.Start()
+	defer p.Stop()
+
+	// The poll timer and the first retry
+	clk.BlockUntil(2)
+	clk.Advance(time.Second)
+	// The poll timer and the second retry, after twice the delay
+	clk.BlockUntil(2)
+	clk.Advance(time.Second)
+	assert.Empty(t, sink.published())
+	clk.Advance(time.Second)
+	eventually(t, func() bool { return len(sink.published()) == 1 })
+}
+
+// gathered returns the value of the metric of the registry with the label
+// values
+func gathered(registry *metrics.Registry, name string, labelValues ...string) float64 {
+	for _, f := range registry.Gather() {
+		if f.Name != name {
+			continue
+		}
+		for _, s := range f.Series {
+			if fmt.Sprint(s.LabelValues) == fmt.Sprint(labelValues) {
+				return s.Value
+			}
+		}
+	}
+	return 0
+}
+
+func TestPublisherMaxRetries(t *testing.T) {
+	lf := ramledger.New(100)
+	appendBlocks(t, lf, "foo", 2)
+	// The first block is rejected on its first attempt and its retry, and
+	// the second on its first attempt only
+	sink := &recordingSink{name: "sink", failures: 3}
+	registry := metrics.NewRegistry()
+	progress := NewMemoryProgress()
+	p, err := NewPublisher(&mockSupport{lf: lf}, Config{
+		Format:       FormatBlock,
+		Sinks:        []Sink{sink},
+		Progress:     progress,
+		RetryInitial: time.Millisecond,
+		MaxRetries:   1,
+		Metrics:      registry,
+	})
+	require.NoError(t, err)
+	p.Start()
+	defer p.Stop()
+
+	eventually(t, func() bool { return len(sink.published()) == 1 })
+	assert.Equal(t, []string{"foo/1"}, sink.published(), "The first block should have been dropped")
+	eventually(t, func() bool {
+		next, _ := progress.Next("sink", "foo")
+		return next == 2
+	})
+	assert.Equal(t, float64(1), gathered(registry, "orderer_events_dropped_total", "sink", "foo"))
+	assert.Equal(t, float64(2), gathered(registry, "orderer_events_retries_total", "sink", "foo"))
+	assert.Equal(t, float64(1), gathered(registry, "orderer_events_published_total", "sink", "foo"))
+}
+
+func TestPublisherReadErrors(t *testing.T) {
+	lf := ramledger.New(100)
+	appendBlocks(t, lf, "foo", 2)
+	readErrors := int32(2)
+	sink := &recordingSink{name: "sink"}
+	registry := metrics.NewRegistry()
+	p, err := NewPublisher(&mockSupport{lf: lf, readErrors: &readErrors}, Config{
+		Format:       FormatBlock,
+		Sinks:        []Sink{sink},
+		RetryInitial: time.Millisecond,
+		Metrics:      registry,
+	})
+	require.NoError(t, err)
+	p.Start()
+	defer p.Stop()
+
+	eventually(t, func() bool { return len(sink.published()) == 2 })
+	assert.Equal(t, []string{"foo/0", "foo/1"}, sink.published(), "Publication should resume from the block which could not be read")
+	assert.Equal(t, float64(2), gathered(registry, "orderer_events_read_errors_total", "sink", "foo"))
+
+	appendBlocks(t, lf, "foo", 1)
+	eventually(t, func() bool { return len(sink.published()) == 3 })
+}
+
+func TestPublisherStopsRetrying(t *testing.T) {
+	lf := ramledger.New(100)
+	sink := &recordingSink{name: "sink", failures: 1000}
+	p, err := NewPublisher(&mockSupport{lf: lf}, Config{Format: FormatBlock, Sinks: []Sink{sink}, RetryInitial: time.Hour})
+	require.NoError(t, err)
+	appendBlocks(t, lf, "foo", 1)
+	p.Start()
+
+	stopped := make(chan struct{})
+	go func() {
+		p.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop should abandon the messages being retried")
+	}
+}
+
+func TestProgressPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress.json")
+
+	progress, err := LoadProgress(path)
+	require.NoError(t, err)
+	_, ok := progress.Next("sink", "foo")
+	assert.False(t, ok)
+	require.NoError(t, progress.Published("sink", "foo", 7))
+
+	progress, err = LoadProgress(path)
+	require.NoError(t, err)
+	next, ok := progress.Next("sink", "foo")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(8), next)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "No temporary file should be left behind")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("garbage"), 0600))
+	_, err = LoadProgress(path)
+	assert.Error(t, err)
+}
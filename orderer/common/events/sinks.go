@@ -0,0 +1,216 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// Headers of the requests of the WebhookSink
+const (
+	HeaderChannel = "X-Fabric-Channel"
+	HeaderNumber  = "X-Fabric-Block-Number"
+)
+
+// WebhookSink posts each message to a URL, which must respond with a 2xx
+// status for the message to be accepted
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookSink returns a sink posting to url, each request bounded by the
+// timeout
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: timeout}}
+}
+
+func (ws *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (ws *WebhookSink) Publish(msg *Message) error {
+	req, err := http.NewRequest(http.MethodPost, ws.URL, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", msg.ContentType)
+	req.Header.Set(HeaderChannel, msg.ChannelID)
+	req.Header.Set(HeaderNumber, strconv.FormatUint(msg.Number, 10))
+	resp, err := ws.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// KafkaSink produces each message to a Kafka topic, keyed by channel so that
+// the messages of a channel stay in order on a single partition
+type KafkaSink struct {
+	topic    string
+	producer sarama.SyncProducer
+}
+
+// NewKafkaSink connects to the brokers, producing to the topic once every
+// in-sync replica has the message
+func NewKafkaSink(brokers []string, topic string, version sarama.KafkaVersion) (*KafkaSink, error) {
+	config := sarama.NewConfig()
+	config.Version = version
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaSink{topic: topic, producer: producer}, nil
+}
+
+func (ks *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (ks *KafkaSink) Publish(msg *Message) error {
+	_, _, err := ks.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: ks.topic,
+		Key:   sarama.StringEncoder(msg.ChannelID),
+		Value: sarama.ByteEncoder(msg.Payload),
+	})
+	return err
+}
+
+// Close closes the producer
+func (ks *KafkaSink) Close() error {
+	return ks.producer.Close()
+}
+
+// NATSSink publishes each message to the subject prefix followed by the
+// channel ID on a NATS server, speaking the text protocol of core NATS. A
+// message is accepted once the server has answered a PING sent after it,
+// which it does only after processing the message.
+type NATSSink struct {
+	address string
+	subject string
+	timeout time.Duration
+
+	mutex  sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSSink returns a sink publishing to the server at address, each
+// message bounded by the timeout. It connects on first use.
+func NewNATSSink(address, subject string, timeout time.Duration) *NATSSink {
+	return &NATSSink{address: address, subject: subject, timeout: timeout}
+}
+
+func (ns *NATSSink) Name() string {
+	return "nats"
+}
+
+func (ns *NATSSink) Publish(msg *Message) error {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+
+	if ns.conn == nil {
+		if err := ns.connect(); err != nil {
+			return err
+		}
+	}
+	if err := ns.publish(msg); err != nil {
+		// The state of the connection is unknown, start afresh
+		ns.conn.Close()
+		ns.conn = nil
+		return err
+	}
+	return nil
+}
+
+// connect opens the connection, reading the INFO of the server and sending
+// CONNECT
+func (ns *NATSSink) connect() error {
+	conn, err := net.DialTimeout("tcp", ns.address, ns.timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(ns.timeout))
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("could not read INFO from %s: %s", ns.address, err)
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting from %s: %q", ns.address, strings.TrimSpace(line))
+	}
+	options, _ := json.Marshal(map[string]interface{}{"verbose": false, "pedantic": false, "name": "fabric-orderer"})
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", options); err != nil {
+		conn.Close()
+		return err
+	}
+	ns.conn, ns.reader = conn, reader
+	return nil
+}
+
+func (ns *NATSSink) publish(msg *Message) error {
+	ns.conn.SetDeadline(time.Now().Add(ns.timeout))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "PUB %s.%s %d\r\n", ns.subject, msg.ChannelID, len(msg.Payload))
+	buf.Write(msg.Payload)
+	buf.WriteString("\r\nPING\r\n")
+	if _, err := ns.conn.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	for {
+		line, err := ns.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := ns.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS server responded %s", line)
+		}
+	}
+}
+
+// Close closes the connection to the server
+func (ns *NATSSink) Close() error {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	if ns.conn == nil {
+		return nil
+	}
+	err := ns.conn.Close()
+	ns.conn = nil
+	return err
+}
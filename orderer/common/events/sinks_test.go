@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package events
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMessage = &Message{ChannelID: "foo", Number: 7, ContentType: ContentTypeJSON, Payload: []byte(`{"number":7}`)}
+
+func TestWebhookSink(t *testing.T) {
+	status := http.StatusOK
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, time.Second)
+	assert.Equal(t, "webhook", sink.Name())
+	require.NoError(t, sink.Publish(testMessage))
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, ContentTypeJSON, received.Header.Get("Content-Type"))
+	assert.Equal(t, "foo", received.Header.Get(HeaderChannel))
+	assert.Equal(t, "7", received.Header.Get(HeaderNumber))
+	assert.Equal(t, testMessage.Payload, body)
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, sink.Publish(testMessage), "webhook responded with 503 Service Unavailable")
+
+	server.Close()
+	assert.Error(t, sink.Publish(testMessage))
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	sink := &KafkaSink{topic: "blocks", producer: producer}
+	assert.Equal(t, "kafka", sink.Name())
+
+	producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+		if string(val) != string(testMessage.Payload) {
+			return fmt.Errorf("unexpected value %s", val)
+		}
+		return nil
+	})
+	require.NoError(t, sink.Publish(testMessage))
+
+	producer.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+	assert.Equal(t, sarama.ErrNotEnoughReplicas, sink.Publish(testMessage))
+	require.NoError(t, sink.Close())
+}
+
+// natsServer accepts a single connection at a time, speaking enough of the
+// NATS protocol for the sink, and records the messages published. Unless ok
+// is set, it closes the connection instead of answering PING.
+type natsServer struct {
+	listener  net.Listener
+	published chan string
+	connects  chan string
+	ok        chan bool
+}
+
+func newNATSServer(t *testing.T) *natsServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ns := &natsServer{listener: lis, published: make(chan string, 10), connects: make(chan string, 10), ok: make(chan bool, 10)}
+	go ns.serve()
+	return ns
+}
+
+func (ns *natsServer) serve() {
+	for {
+		conn, err := ns.listener.Accept()
+		if err != nil {
+			return
+		}
+		ns.handle(conn)
+	}
+}
+
+func (ns *natsServer) handle(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"test\"}\r\n")
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "CONNECT":
+			ns.connects <- strings.TrimSpace(strings.TrimPrefix(line, "CONNECT"))
+		case "PUB":
+			size, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			ns.published <- fields[1] + " " + string(payload[:size])
+		case "PING":
+			if !<-ns.ok {
+				return
+			}
+			fmt.Fprintf(conn, "PONG\r\n")
+		}
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	server := newNATSServer(t)
+	defer server.listener.Close()
+
+	sink := NewNATSSink(server.listener.Addr().String(), "fabric.blocks", time.Second)
+	assert.Equal(t, "nats", sink.Name())
+
+	server.ok <- true
+	require.NoError(t, sink.Publish(testMessage))
+	assert.Contains(t, <-server.connects, `"verbose":false`)
+	assert.Equal(t, `fabric.blocks.foo {"number":7}`, <-server.published)
+
+	// The server fails before acknowledging, the sink reconnects next time
+	server.ok <- false
+	assert.Error(t, sink.Publish(testMessage))
+	<-server.published
+	server.ok <- true
+	require.NoError(t, sink.Publish(testMessage))
+	<-server.connects
+	assert.Equal(t, `fabric.blocks.foo {"number":7}`, <-server.published)
+	require.NoError(t, sink.Close())
+
+	server.listener.Close()
+	assert.Error(t, sink.Publish(testMessage))
+}
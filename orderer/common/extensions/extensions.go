@@ -0,0 +1,147 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package extensions carries the extensions of messages through ordering.
+// Clients attach extensions, such as tracing IDs or compliance tags, opaque
+// to the orderer, in the orderer extension of the channel header of their
+// messages. The orderer receiving a message limits the size of its
+// extensions and has them validated, and the block writer records the
+// extensions of the transactions of each block in its EXTENSIONS metadata,
+// so that consumers find them without decoding the transactions.
+//
+// Limits and validation are local configuration, so they are only enforced
+// when a message is received.
+package extensions
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/extensions")
+
+// Validator returns an error if the extensions of a message of the channel
+// are not acceptable
+type Validator func(channelID string, extensions []byte) error
+
+// Config holds the limits enforced on the extensions of messages
+type Config struct {
+	// MaxBytes is the size of the largest extensions accepted, extensions
+	// are rejected altogether if it is zero
+	MaxBytes uint32
+	// Validator, if set, also validates the extensions of the messages which
+	// carry any
+	Validator Validator
+}
+
+// Of returns the extensions the message carries in its orderer extension
+func Of(msg *filter.Message) []byte {
+	return msg.Extension.GetExtensions()
+}
+
+// Check returns an error if the extensions of the message exceed the limits
+// or are not valid
+func (c Config) Check(msg *filter.Message) error {
+	extensions := Of(msg)
+	if len(extensions) == 0 {
+		return nil
+	}
+	if uint32(len(extensions)) > c.MaxBytes {
+		return fmt.Errorf("%d bytes of extensions exceed the maximum allowed %d bytes", len(extensions), c.MaxBytes)
+	}
+	if c.Validator != nil {
+		if err := c.Validator(msg.ChannelHeader.ChannelId, extensions); err != nil {
+			return fmt.Errorf("invalid extensions: %s", err)
+		}
+	}
+	return nil
+}
+
+// Rule rejects messages whose extensions exceed the limits or are not valid.
+// The limits are only checked when a message is received, as they may differ
+// between orderers.
+func Rule(config Config) filter.OrderingRule {
+	return &rule{config: config}
+}
+
+type rule struct {
+	config Config
+}
+
+func (r *rule) Apply(msg *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := r.Explain(msg)
+	return action, committer
+}
+
+func (r *rule) Explain(msg *filter.Message) (filter.Action, filter.Committer, string) {
+	if err := r.config.Check(msg); err != nil {
+		logger.Warningf("Rejecting message of channel %s: %s", msg.ChannelHeader.ChannelId, err)
+		return filter.Reject, nil, err.Error()
+	}
+	return filter.Forward, nil, ""
+}
+
+func (r *rule) ApplyOrdering(msg *filter.Message) (filter.Action, filter.Committer) {
+	return filter.Forward, nil
+}
+
+// Collect returns the value of the EXTENSIONS metadata of the block, holding
+// the extensions of its transactions, or nil if none carries any.
+// Transactions which cannot be decoded carry none.
+func Collect(block *cb.Block) []byte {
+	data := block.GetData().GetData()
+	txExtensions := &cb.TransactionExtensions{Transactions: make([][]byte, len(data))}
+	found := false
+	for i, envBytes := range data {
+		env := &cb.Envelope{}
+		if err := pool.Unmarshal(envBytes, env); err != nil {
+			continue
+		}
+		msg, err := filter.NewMessage(env)
+		if err != nil {
+			continue
+		}
+		if extensions := Of(msg); len(extensions) > 0 {
+			txExtensions.Transactions[i] = extensions
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return utils.MarshalOrPanic(txExtensions)
+}
+
+// FromBlock returns the extensions of the transactions of the block, by
+// their positions in the block, as recorded in its EXTENSIONS metadata. It
+// returns nil if no transaction of the block carries any.
+func FromBlock(block *cb.Block) ([][]byte, error) {
+	if block.GetMetadata() == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_EXTENSIONS) {
+		return nil, nil
+	}
+	data := block.Metadata.Metadata[cb.BlockMetadataIndex_EXTENSIONS]
+	if len(data) == 0 {
+		return nil, nil
+	}
+	md := &cb.Metadata{}
+	if err := proto.Unmarshal(data, md); err != nil {
+		return nil, fmt.Errorf("malformed extensions metadata: %s", err)
+	}
+	txExtensions := &cb.TransactionExtensions{}
+	if err := proto.Unmarshal(md.Value, txExtensions); err != nil {
+		return nil, fmt.Errorf("malformed transaction extensions: %s", err)
+	}
+	if len(txExtensions.Transactions) != len(block.GetData().GetData()) {
+		return nil, fmt.Errorf("extensions of %d transactions recorded for a block of %d", len(txExtensions.Transactions), len(block.GetData().GetData()))
+	}
+	return txExtensions.Transactions, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeEnvelope(extensions []byte) *cb.Envelope {
+	chdr := utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0)
+	if extensions != nil {
+		chdr.OrdererExtension = utils.MarshalOrPanic(&ab.OrdererHeaderExtension{Extensions: extensions})
+	}
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{}),
+	})}
+}
+
+func makeMessage(t *testing.T, extensions []byte) *filter.Message {
+	msg, err := filter.NewMessage(makeEnvelope(extensions))
+	require.NoError(t, err)
+	return msg
+}
+
+func TestCheck(t *testing.T) {
+	config := Config{MaxBytes: 4}
+	assert.NoError(t, config.Check(makeMessage(t, nil)))
+	assert.NoError(t, config.Check(makeMessage(t, []byte("1234"))))
+	assert.Error(t, config.Check(makeMessage(t, []byte("12345"))))
+	assert.Error(t, Config{}.Check(makeMessage(t, []byte("1"))), "Extensions should be rejected if no size is allowed")
+	assert.NoError(t, Config{}.Check(makeMessage(t, nil)), "Messages without extensions should always be accepted")
+
+	config.Validator = func(channelID string, extensions []byte) error {
+		if string(extensions) != "ok" {
+			return fmt.Errorf("unknown tag %s on channel %s", extensions, channelID)
+		}
+		return nil
+	}
+	assert.NoError(t, config.Check(makeMessage(t, []byte("ok"))))
+	assert.EqualError(t, config.Check(makeMessage(t, []byte("bad"))), "invalid extensions: unknown tag bad on channel foo")
+}
+
+func TestRule(t *testing.T) {
+	rule := Rule(Config{MaxBytes: 4})
+	action, _ := rule.Apply(makeMessage(t, []byte("1234")))
+	assert.EqualValues(t, filter.Forward, action)
+	action, _ = rule.Apply(makeMessage(t, []byte("12345")))
+	assert.EqualValues(t, filter.Reject, action)
+	action, _ = rule.ApplyOrdering(makeMessage(t, []byte("12345")))
+	assert.EqualValues(t, filter.Forward, action, "Limits should not be enforced on messages ordered by other orderers")
+}
+
+func TestCollect(t *testing.T) {
+	block := cb.NewBlock(3, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(makeEnvelope(nil)), []byte("garbage")}
+	assert.Nil(t, Collect(block), "Nothing should be recorded if no transaction carries extensions")
+
+	block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(makeEnvelope([]byte("trace"))))
+	block.Metadata.Metadata[cb.BlockMetadataIndex_EXTENSIONS] = utils.MarshalOrPanic(&cb.Metadata{Value: Collect(block)})
+	txExtensions, err := FromBlock(block)
+	require.NoError(t, err)
+	require.Len(t, txExtensions, 3)
+	assert.Empty(t, txExtensions[0])
+	assert.Empty(t, txExtensions[1])
+	assert.Equal(t, []byte("trace"), txExtensions[2])
+
+	block.Data.Data = block.Data.Data[:2]
+	_, err = FromBlock(block)
+	assert.Error(t, err, "The extensions recorded should match the transactions of the block")
+
+	txExtensions, err = FromBlock(&cb.Block{Metadata: &cb.BlockMetadata{Metadata: [][]byte{{}, {}, {}, {}, {}}}})
+	assert.NoError(t, err)
+	assert.Nil(t, txExtensions, "Blocks predating the EXTENSIONS metadata should have no extensions")
+}
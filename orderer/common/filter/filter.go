@@ -18,10 +18,20 @@ package filter
 
 import (
 	"fmt"
+	"strings"
 
-	ab "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	cb "github.com/hyperledger/fabric/protos/common"
 )
 
+var rejectedMessages = metrics.NewCounter(metrics.Opts{
+	Namespace:  "orderer",
+	Subsystem:  "filter",
+	Name:       "rejected_total",
+	Help:       "The number of messages rejected by each rule, when received (broadcast) or ordered (ordering).",
+	LabelNames: []string{"rule", "phase"},
+})
+
 // Action is used to express the output of a rule
 type Action int
 
@@ -34,11 +44,60 @@ const (
 	Forward
 )
 
-// Rule defines a filter function which accepts, rejects, or forwards (to the next rule) an Envelope
+// Rule defines a filter function which accepts, rejects, or forwards (to the next rule) a Message
 type Rule interface {
-	// Apply applies the rule to the given Envelope, replying with the Action to take for the message
+	// Apply applies the rule to the given Message, replying with the Action to take for the message
 	// If the filter Accepts a message, it should provide a committer to use when writing the message to the chain
-	Apply(message *ab.Envelope) (Action, Committer)
+	Apply(message *Message) (Action, Committer)
+}
+
+// OrderingRule is a Rule which keeps track of the messages being ordered, for
+// example to detect duplicates. Apply checks a message without side effects
+// when it is received, while ApplyOrdering is applied in its place when the
+// message is ordered, so that only ordered messages are tracked.
+type OrderingRule interface {
+	Rule
+
+	// ApplyOrdering applies the rule to a Message which is being ordered
+	ApplyOrdering(message *Message) (Action, Committer)
+}
+
+// StatusRule is a Rule which tells clients why it rejects messages with a
+// status more specific than BAD_REQUEST
+type StatusRule interface {
+	Rule
+
+	// RejectStatus returns the status with which the messages the rule
+	// rejects are answered, and a short machine readable reason
+	RejectStatus() (cb.Status, string)
+}
+
+// ExplainingRule is a Rule which tells clients why it rejects messages. A
+// RuleSet applying it to a received message calls Explain in place of Apply.
+type ExplainingRule interface {
+	Rule
+
+	// Explain applies the rule as Apply does, also returning, when it
+	// rejects the message, a human readable description of why
+	Explain(message *Message) (Action, Committer, string)
+}
+
+// Rejection is the error returned by a RuleSet which rejects a message, with
+// the status and reason of the rule which rejected it, which are
+// BAD_REQUEST and "filtered" unless it is a StatusRule, and why it did if it
+// is an ExplainingRule
+type Rejection struct {
+	Rule   string
+	Status cb.Status
+	Reason string
+	Info   string
+}
+
+func (r *Rejection) Error() string {
+	if r.Info != "" {
+		return fmt.Sprintf("Rejected by rule: %s: %s", r.Rule, r.Info)
+	}
+	return fmt.Sprintf("Rejected by rule: %s", r.Rule)
 }
 
 // Committer is returned by postfiltering and should be invoked once the message has been written to the blockchain
@@ -63,11 +122,16 @@ var EmptyRejectRule = Rule(emptyRejectRule{})
 
 type emptyRejectRule struct{}
 
-func (a emptyRejectRule) Apply(message *ab.Envelope) (Action, Committer) {
-	if message.Payload == nil {
-		return Reject, nil
+func (a emptyRejectRule) Apply(message *Message) (Action, Committer) {
+	action, committer, _ := a.Explain(message)
+	return action, committer
+}
+
+func (a emptyRejectRule) Explain(message *Message) (Action, Committer, string) {
+	if message.Envelope.Payload == nil {
+		return Reject, nil, "empty payload"
 	}
-	return Forward, nil
+	return Forward, nil, ""
 }
 
 // AcceptRule always returns Accept as a result for Apply
@@ -75,7 +139,7 @@ var AcceptRule = Rule(acceptRule{})
 
 type acceptRule struct{}
 
-func (a acceptRule) Apply(message *ab.Envelope) (Action, Committer) {
+func (a acceptRule) Apply(message *Message) (Action, Committer) {
 	return Accept, NoopCommitter
 }
 
@@ -92,14 +156,45 @@ func NewRuleSet(rules []Rule) *RuleSet {
 }
 
 // Apply applies the rules given for this set in order, returning the committer, nil on valid, or nil, err on invalid
-func (rs *RuleSet) Apply(message *ab.Envelope) (Committer, error) {
+func (rs *RuleSet) Apply(message *Message) (Committer, error) {
+	return rs.apply(message, false)
+}
+
+// ApplyOrdering applies the rules as Apply does, to a message which is being ordered, so that any
+// OrderingRule may track it
+func (rs *RuleSet) ApplyOrdering(message *Message) (Committer, error) {
+	return rs.apply(message, true)
+}
+
+func (rs *RuleSet) apply(message *Message, ordering bool) (Committer, error) {
 	for _, rule := range rs.rules {
-		action, committer := rule.Apply(message)
+		var action Action
+		var committer Committer
+		var info string
+		orderingRule, isOrderingRule := rule.(OrderingRule)
+		explainingRule, isExplainingRule := rule.(ExplainingRule)
+		switch {
+		case ordering && isOrderingRule:
+			action, committer = orderingRule.ApplyOrdering(message)
+		case !ordering && isExplainingRule:
+			action, committer, info = explainingRule.Explain(message)
+		default:
+			action, committer = rule.Apply(message)
+		}
 		switch action {
 		case Accept:
 			return committer, nil
 		case Reject:
-			return nil, fmt.Errorf("Rejected by rule: %T", rule)
+			phase := "broadcast"
+			if ordering {
+				phase = "ordering"
+			}
+			rejectedMessages.With(strings.TrimPrefix(fmt.Sprintf("%T", rule), "*"), phase).Add(1)
+			rejection := &Rejection{Rule: fmt.Sprintf("%T", rule), Status: cb.Status_BAD_REQUEST, Reason: "filtered", Info: info}
+			if statusRule, ok := rule.(StatusRule); ok {
+				rejection.Status, rejection.Reason = statusRule.RejectStatus()
+			}
+			return nil, rejection
 		default:
 		}
 	}
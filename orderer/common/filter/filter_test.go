@@ -27,7 +27,7 @@ var RejectRule = Rule(rejectRule{})
 
 type rejectRule struct{}
 
-func (r rejectRule) Apply(message *cb.Envelope) (Action, Committer) {
+func (r rejectRule) Apply(message *Message) (Action, Committer) {
 	return Reject, nil
 }
 
@@ -35,7 +35,7 @@ var ForwardRule = Rule(forwardRule{})
 
 type forwardRule struct{}
 
-func (r forwardRule) Apply(message *cb.Envelope) (Action, Committer) {
+func (r forwardRule) Apply(message *Message) (Action, Committer) {
 	return Forward, nil
 }
 
@@ -45,11 +45,11 @@ func TestNoopCommitter(t *testing.T) {
 }
 
 func TestEmptyRejectRule(t *testing.T) {
-	result, _ := EmptyRejectRule.Apply(&cb.Envelope{})
+	result, _ := EmptyRejectRule.Apply(&Message{Envelope: &cb.Envelope{}})
 	if result != Reject {
 		t.Fatalf("Should have rejected")
 	}
-	result, _ = EmptyRejectRule.Apply(&cb.Envelope{Payload: []byte("fakedata")})
+	result, _ = EmptyRejectRule.Apply(&Message{Envelope: &cb.Envelope{Payload: []byte("fakedata")}})
 	if result != Forward {
 		t.Fatalf("Should have forwarded")
 	}
@@ -57,7 +57,7 @@ func TestEmptyRejectRule(t *testing.T) {
 
 func TestAcceptReject(t *testing.T) {
 	rs := NewRuleSet([]Rule{AcceptRule, RejectRule})
-	_, err := rs.Apply(&cb.Envelope{})
+	_, err := rs.Apply(&Message{})
 	if err != nil {
 		t.Fatalf("Should have accepted: %s", err)
 	}
@@ -65,15 +65,50 @@ func TestAcceptReject(t *testing.T) {
 
 func TestRejectAccept(t *testing.T) {
 	rs := NewRuleSet([]Rule{RejectRule, AcceptRule})
-	_, err := rs.Apply(&cb.Envelope{})
+	_, err := rs.Apply(&Message{})
 	if err == nil {
 		t.Fatalf("Should have rejected")
 	}
 }
 
+type statusRule struct {
+	rejectRule
+}
+
+func (r statusRule) RejectStatus() (cb.Status, string) {
+	return cb.Status_FORBIDDEN, "forbidden"
+}
+
+func TestRejection(t *testing.T) {
+	_, err := NewRuleSet([]Rule{RejectRule}).Apply(&Message{})
+	assert.Equal(t, &Rejection{Rule: "filter.rejectRule", Status: cb.Status_BAD_REQUEST, Reason: "filtered"}, err)
+	assert.EqualError(t, err, "Rejected by rule: filter.rejectRule")
+
+	_, err = NewRuleSet([]Rule{statusRule{}}).Apply(&Message{})
+	assert.Equal(t, &Rejection{Rule: "filter.statusRule", Status: cb.Status_FORBIDDEN, Reason: "forbidden"}, err, "Rules should be able to tell why they reject messages")
+}
+
+type explainingRule struct {
+	rejectRule
+}
+
+func (r explainingRule) Apply(message *Message) (Action, Committer) {
+	panic("Explain should be called in place of Apply")
+}
+
+func (r explainingRule) Explain(message *Message) (Action, Committer, string) {
+	return Reject, nil, "it is a test"
+}
+
+func TestExplainingRule(t *testing.T) {
+	_, err := NewRuleSet([]Rule{explainingRule{}}).Apply(&Message{})
+	assert.Equal(t, &Rejection{Rule: "filter.explainingRule", Status: cb.Status_BAD_REQUEST, Reason: "filtered", Info: "it is a test"}, err)
+	assert.EqualError(t, err, "Rejected by rule: filter.explainingRule: it is a test")
+}
+
 func TestForwardAccept(t *testing.T) {
 	rs := NewRuleSet([]Rule{ForwardRule, AcceptRule})
-	_, err := rs.Apply(&cb.Envelope{})
+	_, err := rs.Apply(&Message{})
 	if err != nil {
 		t.Fatalf("Should have accepted: %s ", err)
 	}
@@ -81,7 +116,7 @@ func TestForwardAccept(t *testing.T) {
 
 func TestForward(t *testing.T) {
 	rs := NewRuleSet([]Rule{ForwardRule})
-	_, err := rs.Apply(&cb.Envelope{})
+	_, err := rs.Apply(&Message{})
 	if err == nil {
 		t.Fatalf("Should have rejected")
 	}
@@ -89,8 +124,34 @@ func TestForward(t *testing.T) {
 
 func TestNoRule(t *testing.T) {
 	rs := NewRuleSet([]Rule{})
-	_, err := rs.Apply(&cb.Envelope{})
+	_, err := rs.Apply(&Message{})
 	if err == nil {
 		t.Fatalf("Should have rejected")
 	}
 }
+
+type countingRule struct {
+	ordered int
+}
+
+func (r *countingRule) Apply(message *Message) (Action, Committer) {
+	return Forward, nil
+}
+
+func (r *countingRule) ApplyOrdering(message *Message) (Action, Committer) {
+	r.ordered++
+	return Forward, nil
+}
+
+func TestApplyOrdering(t *testing.T) {
+	cr := &countingRule{}
+	rs := NewRuleSet([]Rule{ForwardRule, cr, AcceptRule})
+
+	_, err := rs.Apply(&Message{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cr.ordered, "Messages should only be tracked when ordered")
+
+	_, err = rs.ApplyOrdering(&Message{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cr.ordered)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// Message is an Envelope together with its payload and headers. It is decoded
+// once when the message is received, and carried through filtering and
+// ordering so that the rules and the block cutter need not decode it again.
+// A Message must not be modified once it has been created, nor copied.
+type Message struct {
+	Envelope        *cb.Envelope
+	Payload         *cb.Payload
+	ChannelHeader   *cb.ChannelHeader
+	SignatureHeader *cb.SignatureHeader
+	// Extension is decoded from the orderer extension of the channel header,
+	// it is empty when the message requests no treatment
+	Extension *ab.OrdererHeaderExtension
+
+	hashOnce sync.Once
+	hash     []byte
+}
+
+// NewMessage decodes the envelope, returning an error if it is malformed
+func NewMessage(env *cb.Envelope) (*Message, error) {
+	if env == nil {
+		return nil, fmt.Errorf("missing envelope")
+	}
+	payload := &cb.Payload{}
+	if err := pool.Unmarshal(env.Payload, payload); err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("missing header")
+	}
+	chdr := &cb.ChannelHeader{}
+	if err := pool.Unmarshal(payload.Header.ChannelHeader, chdr); err != nil {
+		return nil, fmt.Errorf("bad channel header: %s", err)
+	}
+	shdr := &cb.SignatureHeader{}
+	if err := pool.Unmarshal(payload.Header.SignatureHeader, shdr); err != nil {
+		return nil, fmt.Errorf("bad signature header: %s", err)
+	}
+	ext := &ab.OrdererHeaderExtension{}
+	if err := pool.Unmarshal(chdr.OrdererExtension, ext); err != nil {
+		return nil, fmt.Errorf("bad orderer extension: %s", err)
+	}
+	return &Message{
+		Envelope:        env,
+		Payload:         payload,
+		ChannelHeader:   chdr,
+		SignatureHeader: shdr,
+		Extension:       ext,
+	}, nil
+}
+
+// Type returns the header type of the message
+func (m *Message) Type() cb.HeaderType {
+	return cb.HeaderType(m.ChannelHeader.Type)
+}
+
+// SignedData returns the signed data of the message, as Envelope.AsSignedData
+// does, without decoding the envelope again
+func (m *Message) SignedData() []*cb.SignedData {
+	return []*cb.SignedData{{
+		Data:      m.Envelope.Payload,
+		Identity:  m.SignatureHeader.Creator,
+		Signature: m.Envelope.Signature,
+	}}
+}
+
+// Hash returns the SHA256 hash of the encoded envelope, which identifies the
+// message to the receipts, the transaction status index, the latency tracker
+// and the tracer. It is computed once, when first asked for, and is nil if
+// the envelope cannot be encoded.
+func (m *Message) Hash() []byte {
+	m.hashOnce.Do(func() {
+		m.hash, _ = pool.Hash(m.Envelope)
+	})
+	return m.hash
+}
+
+// Size returns the size of the message, as counted against the batch size
+func (m *Message) Size() uint32 {
+	return uint32(len(m.Envelope.Payload) + len(m.Envelope.Signature))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMessage(t *testing.T) {
+	env := &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: utils.MakePayloadHeader(
+				utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0),
+				&cb.SignatureHeader{Creator: []byte("creator"), Nonce: []byte("nonce")},
+			),
+			Data: []byte("data"),
+		}),
+		Signature: []byte("signature"),
+	}
+
+	msg, err := NewMessage(env)
+	require.NoError(t, err)
+	assert.Equal(t, env, msg.Envelope)
+	assert.Equal(t, []byte("data"), msg.Payload.Data)
+	assert.Equal(t, "foo", msg.ChannelHeader.ChannelId)
+	assert.Equal(t, cb.HeaderType_ENDORSER_TRANSACTION, msg.Type())
+	assert.Equal(t, []byte("nonce"), msg.SignatureHeader.Nonce)
+	assert.Equal(t, uint32(len(env.Payload)+len(env.Signature)), msg.Size())
+	assert.Equal(t, ab.PriorityClass_NORMAL, msg.Extension.Priority, "A message without an orderer extension should request nothing")
+
+	signedData, err := env.AsSignedData()
+	require.NoError(t, err)
+	assert.Equal(t, signedData, msg.SignedData())
+
+	hash := msg.Hash()
+	assert.Equal(t, util.ComputeSHA256(utils.MarshalOrPanic(env)), hash, "The hash should be that of the encoded envelope")
+	assert.True(t, &hash[0] == &msg.Hash()[0], "The hash should only be computed once")
+}
+
+func TestNewMessageMalformed(t *testing.T) {
+	for name, env := range map[string]*cb.Envelope{
+		"nil envelope":       nil,
+		"bad payload":        {Payload: []byte("garbage")},
+		"missing header":     {Payload: utils.MarshalOrPanic(&cb.Payload{})},
+		"bad channel header": {Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{ChannelHeader: []byte("garbage")}})},
+		"bad signature header": {Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{
+			ChannelHeader:   utils.MarshalOrPanic(&cb.ChannelHeader{}),
+			SignatureHeader: []byte("garbage"),
+		}})},
+		"bad orderer extension": {Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{
+			ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{OrdererExtension: []byte("garbage")}),
+		}})},
+	} {
+		_, err := NewMessage(env)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNewMessageExtension(t *testing.T) {
+	chdr := utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0)
+	chdr.OrdererExtension = utils.MarshalOrPanic(&ab.OrdererHeaderExtension{Priority: ab.PriorityClass_HIGH})
+	msg, err := NewMessage(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{}),
+	})})
+	require.NoError(t, err)
+	assert.Equal(t, ab.PriorityClass_HIGH, msg.Extension.Priority)
+}
+
+func FuzzNewMessage(f *testing.F) {
+	f.Add(utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(
+			utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0),
+			&cb.SignatureHeader{Creator: []byte("creator"), Nonce: []byte("nonce")},
+		),
+		Data: []byte("data"),
+	}), []byte("signature"))
+	f.Add([]byte("garbage"), []byte(nil))
+	f.Fuzz(func(t *testing.T, payload, signature []byte) {
+		msg, err := NewMessage(&cb.Envelope{Payload: payload, Signature: signature})
+		if err != nil {
+			return
+		}
+		msg.Type()
+		msg.SignedData()
+		msg.Size()
+	})
+}
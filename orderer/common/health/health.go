@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var logger = logging.MustGetLogger("orderer/common/health")
+
+// Checker reports whether a component is healthy, returning a non-nil error
+// describing the problem if it is not
+type Checker func() error
+
+// Server tracks the readiness of the process and the health of its services,
+// and implements the grpc.health.v1.Health service on top of them. Until
+// SetReady is called every service, including the overall server status
+// reported for the empty service name, is NOT_SERVING. The checks of its
+// subsystems are served as HTTP probes.
+type Server struct {
+	lock     sync.RWMutex
+	ready    bool
+	checkers map[string]Checker
+	checks   map[string]subsystemCheck
+}
+
+// NewServer creates a Server which is not yet ready and has no services or
+// checks
+func NewServer() *Server {
+	return &Server{
+		checkers: make(map[string]Checker),
+		checks:   make(map[string]subsystemCheck),
+	}
+}
+
+// Register adds a service whose health is determined by the given Checker,
+// replacing any Checker previously registered for the service
+func (s *Server) Register(service string, checker Checker) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.checkers[service] = checker
+}
+
+// SetReady marks the process as having completed its startup
+func (s *Server) SetReady() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	logger.Infof("Startup complete, reporting as ready")
+	s.ready = true
+}
+
+// Ready returns whether SetReady has been called
+func (s *Server) Ready() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.ready
+}
+
+// Services returns the names of the registered services in sorted order
+func (s *Server) Services() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	services := make([]string, 0, len(s.checkers))
+	for service := range s.checkers {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// Status returns nil if the named service is ready and healthy, or the
+// empty service name is given and all services are. It returns an error
+// describing the first problem found otherwise.
+func (s *Server) Status(service string) error {
+	s.lock.RLock()
+	ready := s.ready
+	checker, ok := s.checkers[service]
+	s.lock.RUnlock()
+
+	if service != "" && !ok {
+		return fmt.Errorf("unknown service %s", service)
+	}
+	if !ready {
+		return fmt.Errorf("startup has not completed")
+	}
+	if service != "" {
+		return checker()
+	}
+
+	for _, service := range s.Services() {
+		if err := s.Status(service); err != nil {
+			return fmt.Errorf("service %s is unhealthy: %s", service, err)
+		}
+	}
+	return nil
+}
+
+// Check implements the Check method of the grpc.health.v1.Health service
+func (s *Server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.lock.RLock()
+	_, ok := s.checkers[req.Service]
+	s.lock.RUnlock()
+	if req.Service != "" && !ok {
+		return nil, grpc.Errorf(codes.NotFound, "unknown service %s", req.Service)
+	}
+
+	if err := s.Status(req.Service); err != nil {
+		logger.Debugf("Reporting %q as not serving: %s", req.Service, err)
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
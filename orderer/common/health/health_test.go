@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func check(t *testing.T, s *Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	assert.NoError(t, err)
+	return resp.Status
+}
+
+func TestNotReady(t *testing.T) {
+	s := NewServer()
+	s.Register("good", func() error { return nil })
+
+	assert.False(t, s.Ready())
+	assert.EqualError(t, s.Status(""), "startup has not completed")
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, s, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, s, "good"))
+
+	s.SetReady()
+	assert.True(t, s.Ready())
+	assert.NoError(t, s.Status(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, s, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, s, "good"))
+}
+
+func TestServiceStatus(t *testing.T) {
+	s := NewServer()
+	s.SetReady()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, s, ""), "No services should be healthy")
+
+	var failure error
+	s.Register("good", func() error { return nil })
+	s.Register("flaky", func() error { return failure })
+	assert.Equal(t, []string{"flaky", "good"}, s.Services())
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, s, "flaky"))
+
+	failure = fmt.Errorf("consenter unavailable")
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, s, "flaky"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, s, "good"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, s, ""))
+	assert.EqualError(t, s.Status(""), "service flaky is unhealthy: consenter unavailable")
+
+	failure = nil
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, s, ""))
+}
+
+func TestUnknownService(t *testing.T) {
+	s := NewServer()
+	_, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	assert.EqualError(t, s.Status("missing"), "unknown service missing")
+}
@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Probe identifies the HTTP probes with which orchestrators track the
+// process
+type Probe int
+
+const (
+	// Liveness checks fail when the process should be restarted
+	Liveness Probe = iota
+	// Readiness checks fail when the process should not be sent requests,
+	// and so do before startup completes
+	Readiness
+)
+
+func (p Probe) String() string {
+	switch p {
+	case Liveness:
+		return "liveness"
+	case Readiness:
+		return "readiness"
+	default:
+		return fmt.Sprintf("probe %d", int(p))
+	}
+}
+
+// startupCheck is the name of the readiness check which fails until SetReady
+// is called
+const startupCheck = "startup"
+
+type subsystemCheck struct {
+	probe   Probe
+	checker Checker
+}
+
+// Result is the outcome of a check run by a probe
+type Result struct {
+	Name string
+	Err  error
+}
+
+// RegisterCheck adds a check of a subsystem to the probe, replacing any
+// check previously registered under the name
+func (s *Server) RegisterCheck(name string, probe Probe, checker Checker) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.checks[name] = subsystemCheck{probe: probe, checker: checker}
+}
+
+// Run runs the checks of the probe in the order of their names, returning
+// their results and whether all passed
+func (s *Server) Run(probe Probe) ([]Result, bool) {
+	s.lock.RLock()
+	var results []Result
+	checkers := make(map[string]Checker)
+	for name, c := range s.checks {
+		if c.probe == probe {
+			results = append(results, Result{Name: name})
+			checkers[name] = c.checker
+		}
+	}
+	ready := s.ready
+	s.lock.RUnlock()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	passed := true
+	if probe == Readiness {
+		startup := Result{Name: startupCheck}
+		if !ready {
+			startup.Err = fmt.Errorf("startup has not completed")
+			passed = false
+		}
+		results = append([]Result{startup}, results...)
+	}
+	for i := range results {
+		checker, ok := checkers[results[i].Name]
+		if !ok {
+			continue
+		}
+		if err := checker(); err != nil {
+			results[i].Err = err
+			passed = false
+		}
+	}
+	return results, passed
+}
+
+// Handler returns the HTTP handler of the probe, which answers with 200 if
+// all its checks pass and 503 otherwise, listing the outcome of each check
+func (s *Server) Handler(probe Probe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results, passed := s.Run(probe)
+		var buf bytes.Buffer
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(&buf, "[-]%s failed: %s\n", result.Name, result.Err)
+				continue
+			}
+			fmt.Fprintf(&buf, "[+]%s ok\n", result.Name)
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if !passed {
+			logger.Debugf("Failing the %s probe:\n%s", probe, buf.String())
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(&buf, "%s check failed\n", probe)
+		} else {
+			fmt.Fprintf(&buf, "%s check passed\n", probe)
+		}
+		w.Write(buf.Bytes())
+	})
+}
+
+// Threshold returns a Checker which only reports the failure of the checker
+// once it has failed the given number of times in a row, so that a
+// transient failure does not restart or de-route the process
+func Threshold(checker Checker, failures int) Checker {
+	if failures <= 1 {
+		return checker
+	}
+	var (
+		lock        sync.Mutex
+		consecutive int
+	)
+	return func() error {
+		err := checker()
+		lock.Lock()
+		defer lock.Unlock()
+		if err == nil {
+			consecutive = 0
+			return nil
+		}
+		consecutive++
+		if consecutive < failures {
+			logger.Debugf("Ignoring failure %d of %d: %s", consecutive, failures, err)
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, s *Server, p Probe) (int, string) {
+	rec := httptest.NewRecorder()
+	s.Handler(p).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return rec.Code, string(body)
+}
+
+func TestProbes(t *testing.T) {
+	s := NewServer()
+	var ledgerErr, consenterErr error
+	s.RegisterCheck("ledger", Liveness, func() error { return ledgerErr })
+	s.RegisterCheck("consenter", Readiness, func() error { return consenterErr })
+	s.RegisterCheck("config", Readiness, func() error { return nil })
+
+	code, body := probe(t, s, Liveness)
+	assert.Equal(t, http.StatusOK, code, "Liveness should not depend on startup")
+	assert.Equal(t, "[+]ledger ok\nliveness check passed\n", body)
+	code, body = probe(t, s, Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "[-]startup failed: startup has not completed\n[+]config ok\n[+]consenter ok\nreadiness check failed\n", body)
+
+	s.SetReady()
+	code, _ = probe(t, s, Readiness)
+	assert.Equal(t, http.StatusOK, code)
+
+	consenterErr = fmt.Errorf("disconnected")
+	code, body = probe(t, s, Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]consenter failed: disconnected\n")
+	code, _ = probe(t, s, Liveness)
+	assert.Equal(t, http.StatusOK, code, "Readiness checks should not fail the liveness probe")
+
+	ledgerErr = fmt.Errorf("read-only file system")
+	code, body = probe(t, s, Liveness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "[-]ledger failed: read-only file system\nliveness check failed\n", body)
+
+	results, passed := s.Run(Liveness)
+	assert.False(t, passed)
+	assert.Equal(t, []Result{{Name: "ledger", Err: ledgerErr}}, results)
+}
+
+func TestThreshold(t *testing.T) {
+	var err error
+	checker := Threshold(func() error { return err }, 3)
+	err = fmt.Errorf("failure")
+	assert.NoError(t, checker())
+	assert.NoError(t, checker())
+	assert.Equal(t, err, checker(), "The third failure in a row should be reported")
+	assert.Equal(t, err, checker())
+
+	err = nil
+	assert.NoError(t, checker())
+	err = fmt.Errorf("failure")
+	assert.NoError(t, checker(), "A success should reset the count of failures")
+
+	assert.Equal(t, err, Threshold(func() error { return err }, 0)(), "A threshold below one should report every failure")
+}
@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package hlc stamps blocks, and optionally their transactions, with the time
+// they are ordered according to a hybrid logical clock, recorded in the
+// TIMESTAMP metadata of each block. The physical time of the clock follows
+// the wall clock, but never goes backwards, and its logical counter orders
+// the events stamped within the same physical time. A chain resumes its clock
+// from the timestamp of its last block, so that the blocks of a chain are
+// stamped in order even across restarts, and when another orderer, whose wall
+// clock lags, takes over writing the chain.
+package hlc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Config is the configuration of the timestamps of the blocks of each chain
+type Config struct {
+	// Transactions is whether each transaction of a block is stamped in
+	// addition to the block
+	Transactions bool
+}
+
+// Timestamp is a time of a hybrid logical clock
+type Timestamp struct {
+	// Physical is the physical time, in nanoseconds since the Unix epoch
+	Physical int64
+	// Logical orders the timestamps of the same physical time
+	Logical uint32
+}
+
+// Before returns whether t is earlier than u
+func (t Timestamp) Before(u Timestamp) bool {
+	return t.Physical < u.Physical || (t.Physical == u.Physical && t.Logical < u.Logical)
+}
+
+// Time returns the physical time of the timestamp
+func (t Timestamp) Time() time.Time {
+	return time.Unix(0, t.Physical).UTC()
+}
+
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%s+%d", t.Time().Format(time.RFC3339Nano), t.Logical)
+}
+
+// Proto returns the timestamp as a protobuf message
+func (t Timestamp) Proto() *cb.HybridTimestamp {
+	return &cb.HybridTimestamp{Physical: t.Physical, Logical: t.Logical}
+}
+
+// FromProto returns the timestamp of the protobuf message, which is zero if
+// the message is nil
+func FromProto(ts *cb.HybridTimestamp) Timestamp {
+	return Timestamp{Physical: ts.GetPhysical(), Logical: ts.GetLogical()}
+}
+
+// Clock is a hybrid logical clock, whose timestamps always increase
+type Clock struct {
+	clock clock.Clock
+
+	lock sync.Mutex
+	last Timestamp
+}
+
+// NewClock creates a Clock which stamps events later than last, following
+// the physical time of clock
+func NewClock(clock clock.Clock, last Timestamp) *Clock {
+	return &Clock{clock: clock, last: last}
+}
+
+// Now returns a timestamp later than any returned before
+func (c *Clock) Now() Timestamp {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.tick()
+}
+
+// tick advances the clock, with the lock held
+func (c *Clock) tick() Timestamp {
+	if physical := c.clock.Now().UnixNano(); physical > c.last.Physical {
+		c.last = Timestamp{Physical: physical}
+	} else {
+		c.last.Logical++
+	}
+	return c.last
+}
+
+// Last returns the latest timestamp returned
+func (c *Clock) Last() Timestamp {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.last
+}
+
+// Stamp returns the encoded timestamps of the block, to be recorded in its
+// TIMESTAMP metadata, stamping each of its transactions in order, after the
+// block, if transactions is set
+func (c *Clock) Stamp(block *cb.Block, transactions bool) []byte {
+	c.lock.Lock()
+	ts := &cb.OrderingTimestamps{Block: c.tick().Proto()}
+	if transactions {
+		ts.Transactions = make([]*cb.HybridTimestamp, len(block.GetData().GetData()))
+		for i := range ts.Transactions {
+			ts.Transactions[i] = c.tick().Proto()
+		}
+	}
+	c.lock.Unlock()
+
+	return utils.MarshalOrPanic(ts)
+}
+
+// FromBlock returns the timestamps of the block, which are nil if it is not
+// stamped, as the blocks written before timestamps were introduced are not
+func FromBlock(block *cb.Block) (*cb.OrderingTimestamps, error) {
+	if block.GetMetadata() == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_TIMESTAMP) {
+		return nil, nil
+	}
+	data := block.Metadata.Metadata[cb.BlockMetadataIndex_TIMESTAMP]
+	if len(data) == 0 {
+		return nil, nil
+	}
+	md := &cb.Metadata{}
+	if err := proto.Unmarshal(data, md); err != nil {
+		return nil, fmt.Errorf("malformed timestamp metadata: %s", err)
+	}
+	ts := &cb.OrderingTimestamps{}
+	if err := proto.Unmarshal(md.Value, ts); err != nil {
+		return nil, fmt.Errorf("malformed ordering timestamps: %s", err)
+	}
+	return ts, nil
+}
+
+// Latest returns the latest timestamp of the block, that of its last
+// transaction if its transactions are stamped, which is zero if it is not
+// stamped
+func Latest(block *cb.Block) (Timestamp, error) {
+	ts, err := FromBlock(block)
+	if err != nil || ts == nil {
+		return Timestamp{}, err
+	}
+	if n := len(ts.Transactions); n > 0 {
+		return FromProto(ts.Transactions[n-1]), nil
+	}
+	return FromProto(ts.Block), nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hlc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock(t *testing.T) {
+	wall := clock.NewManual(time.Unix(10, 0))
+	c := NewClock(wall, Timestamp{})
+
+	first := c.Now()
+	assert.Equal(t, Timestamp{Physical: 10e9}, first)
+	second := c.Now()
+	assert.Equal(t, Timestamp{Physical: 10e9, Logical: 1}, second, "Timestamps of the same physical time should be ordered logically")
+	assert.True(t, first.Before(second))
+
+	wall.Advance(time.Second)
+	third := c.Now()
+	assert.Equal(t, Timestamp{Physical: 11e9}, third)
+	assert.True(t, second.Before(third))
+	assert.False(t, third.Before(third))
+	assert.Equal(t, third, c.Last())
+}
+
+func TestClockResumes(t *testing.T) {
+	last := Timestamp{Physical: 20e9, Logical: 7}
+	c := NewClock(clock.NewManual(time.Unix(10, 0)), last)
+	assert.Equal(t, Timestamp{Physical: 20e9, Logical: 8}, c.Now(), "The clock should not go back when the wall clock lags")
+}
+
+func TestStamp(t *testing.T) {
+	c := NewClock(clock.NewManual(time.Unix(10, 0)), Timestamp{})
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{[]byte("foo"), []byte("bar")}
+
+	ts := &cb.OrderingTimestamps{}
+	require.NoError(t, proto.Unmarshal(c.Stamp(block, false), ts))
+	assert.Equal(t, &cb.OrderingTimestamps{Block: &cb.HybridTimestamp{Physical: 10e9}}, ts)
+
+	value := c.Stamp(block, true)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TIMESTAMP] = utils.MarshalOrPanic(&cb.Metadata{Value: value})
+	ts, err := FromBlock(block)
+	require.NoError(t, err)
+	assert.Equal(t, &cb.OrderingTimestamps{
+		Block:        &cb.HybridTimestamp{Physical: 10e9, Logical: 1},
+		Transactions: []*cb.HybridTimestamp{{Physical: 10e9, Logical: 2}, {Physical: 10e9, Logical: 3}},
+	}, ts)
+	latest, err := Latest(block)
+	require.NoError(t, err)
+	assert.Equal(t, Timestamp{Physical: 10e9, Logical: 3}, latest)
+}
+
+func TestFromBlock(t *testing.T) {
+	ts, err := FromBlock(&cb.Block{Metadata: &cb.BlockMetadata{Metadata: [][]byte{{}, {}, {}, {}}}})
+	assert.NoError(t, err)
+	assert.Nil(t, ts, "Blocks written before timestamps were introduced should not be stamped")
+
+	latest, err := Latest(cb.NewBlock(0, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, Timestamp{}, latest)
+
+	block := cb.NewBlock(0, nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TIMESTAMP] = []byte("garbage")
+	_, err = FromBlock(block)
+	assert.Error(t, err)
+	_, err = Latest(block)
+	assert.Error(t, err)
+}
+
+func TestTimestampString(t *testing.T) {
+	assert.Equal(t, "1970-01-01T00:00:10.5Z+2", Timestamp{Physical: 10.5e9, Logical: 2}.String())
+	assert.Equal(t, Timestamp{Physical: 3, Logical: 4}, FromProto(Timestamp{Physical: 3, Logical: 4}.Proto()))
+	assert.Equal(t, Timestamp{}, FromProto(nil))
+}
@@ -0,0 +1,183 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/identity")
+
+// DefaultCacheSize is the number of validation results retained per channel
+const DefaultCacheSize = 1000
+
+// Support provides the MSPs of a channel along with the sequence number of
+// the config they were defined by
+type Support interface {
+	// MSPManager returns the msp.MSPManager for the chain
+	MSPManager() msp.MSPManager
+
+	// Sequence returns the current config sequence number
+	Sequence() uint64
+}
+
+type result struct {
+	identity msp.Identity
+	err      error
+}
+
+// Validator deserializes identities and validates them against the root and
+// intermediate CAs, revocation lists and OU restrictions of the channel's
+// MSPs. Results are cached until the channel config changes, so that the
+// same creator is only validated once per config sequence.
+type Validator struct {
+	support Support
+	size    int
+
+	lock     sync.Mutex
+	sequence uint64
+	results  map[string]*result
+	order    []string
+}
+
+// NewValidator creates a Validator which retains up to size results
+func NewValidator(support Support, size int) *Validator {
+	return &Validator{
+		support: support,
+		size:    size,
+		results: make(map[string]*result),
+	}
+}
+
+// Validate deserializes the identity and validates it against the channel
+// MSPs, returning the identity if it is valid
+func (v *Validator) Validate(serializedIdentity []byte) (msp.Identity, error) {
+	key := string(serializedIdentity)
+	sequence := v.support.Sequence()
+
+	v.lock.Lock()
+	if sequence != v.sequence {
+		logger.Debugf("Config sequence changed from %d to %d, discarding cached identities", v.sequence, sequence)
+		v.sequence = sequence
+		v.results = make(map[string]*result)
+		v.order = nil
+	}
+	r, ok := v.results[key]
+	v.lock.Unlock()
+	if ok {
+		return r.identity, r.err
+	}
+
+	r = v.validate(serializedIdentity)
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if sequence != v.sequence {
+		// The config changed while validating, do not cache a stale result
+		return r.identity, r.err
+	}
+	if _, ok := v.results[key]; !ok {
+		if len(v.order) >= v.size && len(v.order) > 0 {
+			delete(v.results, v.order[0])
+			v.order = v.order[1:]
+		}
+		v.results[key] = r
+		v.order = append(v.order, key)
+	}
+	return r.identity, r.err
+}
+
+func (v *Validator) validate(serializedIdentity []byte) *result {
+	id, err := v.support.MSPManager().DeserializeIdentity(serializedIdentity)
+	if err != nil {
+		return &result{err: fmt.Errorf("failed to deserialize identity: %s", err)}
+	}
+	if err := id.Validate(); err != nil {
+		return &result{err: fmt.Errorf("identity of MSP %s is not valid: %s", id.GetMSPIdentifier(), err)}
+	}
+	return &result{identity: id}
+}
+
+// Creator validates the creator from the signature header of the envelope
+func (v *Validator) Creator(env *cb.Envelope) (msp.Identity, error) {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("missing header")
+	}
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, err
+	}
+	if len(shdr.Creator) == 0 {
+		return nil, fmt.Errorf("missing creator")
+	}
+	return v.Validate(shdr.Creator)
+}
+
+// Certificate validates the identity of a client authenticated by its TLS
+// certificate, which does not tell its MSP. The certificate is validated as
+// the identity of each of the channel's MSPs in turn, and the serialized
+// identity of the first MSP which accepts it is returned, so that it can be
+// evaluated by the policies of the channel.
+func (v *Validator) Certificate(cert *x509.Certificate) ([]byte, msp.Identity, error) {
+	msps, err := v.support.MSPManager().GetMSPs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the MSPs of the channel: %s", err)
+	}
+	ids := make([]string, 0, len(msps))
+	for id := range msps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	for _, mspID := range ids {
+		serializedIdentity := utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: mspID, IdBytes: certPEM})
+		if id, err := v.Validate(serializedIdentity); err == nil {
+			return serializedIdentity, id, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("certificate %s is not issued by any MSP of the channel", cert.Subject.CommonName)
+}
+
+// HasRole checks that the identity has the given role within its MSP
+func HasRole(id msp.Identity, role mspproto.MSPRole_MSPRoleType) error {
+	principal := &mspproto.MSPPrincipal{
+		PrincipalClassification: mspproto.MSPPrincipal_ROLE,
+		Principal: utils.MarshalOrPanic(&mspproto.MSPRole{
+			MspIdentifier: id.GetMSPIdentifier(),
+			Role:          role,
+		}),
+	}
+	if err := id.SatisfiesPrincipal(principal); err != nil {
+		return fmt.Errorf("identity of MSP %s does not have role %s: %s", id.GetMSPIdentifier(), role, err)
+	}
+	return nil
+}
+
+// HasOU checks that the identity belongs to the named organizational unit
+func HasOU(id msp.Identity, ou string) error {
+	for _, identifier := range id.GetOrganizationalUnits() {
+		if identifier.OrganizationalUnitIdentifier == ou {
+			return nil
+		}
+	}
+	return fmt.Errorf("identity of MSP %s is not a member of organizational unit %s", id.GetMSPIdentifier(), ou)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockIdentity struct {
+	msp.Identity
+	name  string
+	valid bool
+	admin bool
+	ous   []string
+}
+
+func (mi *mockIdentity) GetMSPIdentifier() string {
+	return "SampleOrg"
+}
+
+func (mi *mockIdentity) Validate() error {
+	if !mi.valid {
+		return fmt.Errorf("certificate %s is revoked", mi.name)
+	}
+	return nil
+}
+
+func (mi *mockIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	var ous []*msp.OUIdentifier
+	for _, ou := range mi.ous {
+		ous = append(ous, &msp.OUIdentifier{OrganizationalUnitIdentifier: ou})
+	}
+	return ous
+}
+
+func (mi *mockIdentity) SatisfiesPrincipal(principal *mspproto.MSPPrincipal) error {
+	role := &mspproto.MSPRole{}
+	if err := proto.Unmarshal(principal.Principal, role); err != nil {
+		return err
+	}
+	if role.Role == mspproto.MSPRole_ADMIN && !mi.admin {
+		return fmt.Errorf("not an admin")
+	}
+	return nil
+}
+
+type mockManager struct {
+	msp.MSPManager
+	support *mockSupport
+}
+
+func (mm *mockManager) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	mm.support.deserialized++
+	id, ok := mm.support.identities[string(serializedIdentity)]
+	if !ok {
+		return nil, fmt.Errorf("unknown MSP")
+	}
+	return id, nil
+}
+
+func (mm *mockManager) GetMSPs() (map[string]msp.MSP, error) {
+	return map[string]msp.MSP{"OtherOrg": nil, "SampleOrg": nil}, nil
+}
+
+type mockSupport struct {
+	sequence     uint64
+	identities   map[string]*mockIdentity
+	deserialized int
+}
+
+func (ms *mockSupport) MSPManager() msp.MSPManager {
+	return &mockManager{support: ms}
+}
+
+func (ms *mockSupport) Sequence() uint64 {
+	return ms.sequence
+}
+
+func newMockSupport() *mockSupport {
+	return &mockSupport{identities: map[string]*mockIdentity{
+		"member":  {name: "member", valid: true, ous: []string{"client"}},
+		"admin":   {name: "admin", valid: true, admin: true},
+		"revoked": {name: "revoked"},
+	}}
+}
+
+func TestValidate(t *testing.T) {
+	support := newMockSupport()
+	v := NewValidator(support, DefaultCacheSize)
+
+	id, err := v.Validate([]byte("member"))
+	assert.NoError(t, err)
+	assert.Equal(t, "SampleOrg", id.GetMSPIdentifier())
+
+	_, err = v.Validate([]byte("revoked"))
+	assert.EqualError(t, err, "identity of MSP SampleOrg is not valid: certificate revoked is revoked")
+
+	_, err = v.Validate([]byte("garbage"))
+	assert.EqualError(t, err, "failed to deserialize identity: unknown MSP")
+	assert.Equal(t, 3, support.deserialized)
+
+	_, err = v.Validate([]byte("member"))
+	assert.NoError(t, err)
+	_, err = v.Validate([]byte("revoked"))
+	assert.Error(t, err)
+	assert.Equal(t, 3, support.deserialized, "Results, including failures, should be cached")
+
+	support.sequence++
+	support.identities["revoked"].valid = true
+	_, err = v.Validate([]byte("revoked"))
+	assert.NoError(t, err, "A config change should discard cached results")
+	assert.Equal(t, 4, support.deserialized)
+}
+
+func TestCacheSize(t *testing.T) {
+	support := newMockSupport()
+	v := NewValidator(support, 2)
+
+	for _, name := range []string{"member", "admin", "revoked"} {
+		v.Validate([]byte(name))
+	}
+	assert.Len(t, v.results, 2)
+	assert.Equal(t, []string{"admin", "revoked"}, v.order)
+
+	v.Validate([]byte("member"))
+	assert.Equal(t, 4, support.deserialized, "The oldest result should have been evicted")
+}
+
+func TestCreator(t *testing.T) {
+	v := NewValidator(newMockSupport(), DefaultCacheSize)
+	envelope := func(creator []byte) *cb.Envelope {
+		return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: creator})},
+		})}
+	}
+
+	id, err := v.Creator(envelope([]byte("admin")))
+	assert.NoError(t, err)
+	assert.NotNil(t, id)
+
+	_, err = v.Creator(envelope(nil))
+	assert.EqualError(t, err, "missing creator")
+
+	_, err = v.Creator(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{})})
+	assert.EqualError(t, err, "missing header")
+
+	_, err = v.Creator(&cb.Envelope{Payload: []byte("garbage")})
+	assert.Error(t, err)
+}
+
+func TestConstraints(t *testing.T) {
+	support := newMockSupport()
+
+	assert.NoError(t, HasRole(support.identities["admin"], mspproto.MSPRole_ADMIN))
+	assert.Error(t, HasRole(support.identities["member"], mspproto.MSPRole_ADMIN))
+	assert.NoError(t, HasRole(support.identities["member"], mspproto.MSPRole_MEMBER))
+
+	assert.NoError(t, HasOU(support.identities["member"], "client"))
+	assert.EqualError(t, HasOU(support.identities["admin"], "client"), "identity of MSP SampleOrg is not a member of organizational unit client")
+}
+
+func TestCertificate(t *testing.T) {
+	support := newMockSupport()
+	v := NewValidator(support, DefaultCacheSize)
+	cert := &x509.Certificate{Raw: []byte("client"), Subject: pkix.Name{CommonName: "client"}}
+	serialized := utils.MarshalOrPanic(&mspproto.SerializedIdentity{
+		Mspid:   "SampleOrg",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+	})
+
+	_, _, err := v.Certificate(cert)
+	assert.EqualError(t, err, "certificate client is not issued by any MSP of the channel")
+
+	support.identities[string(serialized)] = &mockIdentity{name: "client", valid: true}
+	support.sequence++
+	identity, id, err := v.Certificate(cert)
+	assert.NoError(t, err)
+	assert.Equal(t, serialized, identity)
+	assert.Equal(t, "SampleOrg", id.GetMSPIdentifier())
+}
@@ -0,0 +1,191 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"crypto/x509"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+)
+
+var logger = logging.MustGetLogger("orderer/common/interceptor")
+
+// rpcMetrics holds the per-RPC counters, keyed by full method name and
+// metric, and is published at /debug/vars
+var rpcMetrics = expvar.NewMap("orderer_grpc")
+
+// UnaryInterceptors returns the standard set of interceptors for unary RPCs,
+// outermost first
+func UnaryInterceptors() []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{UnaryAuth, UnaryLogging, UnaryMetrics, UnaryRecovery}
+}
+
+// StreamInterceptors returns the standard set of interceptors for streaming
+// RPCs, outermost first
+func StreamInterceptors() []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{StreamAuth, StreamLogging, StreamMetrics, StreamRecovery}
+}
+
+// Identity describes the remote client of an RPC
+type Identity struct {
+	// Address is the network address of the client
+	Address string
+	// Certificate is the verified TLS client certificate, or nil if none
+	// was presented
+	Certificate *x509.Certificate
+	// Token holds the claims of the verified bearer token, or nil if none
+	// was presented
+	Token *TokenClaims
+}
+
+// String returns the common name of the client certificate or the subject of
+// the bearer token, if any, along with the client address
+func (id *Identity) String() string {
+	switch {
+	case id.Certificate != nil:
+		return fmt.Sprintf("%s@%s", id.Certificate.Subject.CommonName, id.Address)
+	case id.Token != nil:
+		return fmt.Sprintf("%s(%s)@%s", id.Token.Subject, id.Token.MSPID, id.Address)
+	default:
+		return id.Address
+	}
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the Identity of the client which was stored in
+// the context by the auth interceptors
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+func withIdentity(ctx context.Context) context.Context {
+	id := &Identity{Certificate: comm.ExtractCertificateFromContext(ctx)}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		id.Address = pr.Addr.String()
+	}
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+func identityString(ctx context.Context) string {
+	if id, ok := IdentityFromContext(ctx); ok {
+		return id.String()
+	}
+	return "unknown"
+}
+
+// contextStream overrides the context of a grpc.ServerStream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (cs *contextStream) Context() context.Context {
+	return cs.ctx
+}
+
+// UnaryAuth stores the Identity of the client in the request context
+func UnaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(withIdentity(ctx), req)
+}
+
+// StreamAuth stores the Identity of the client in the stream context
+func StreamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: withIdentity(ss.Context())})
+}
+
+// UnaryLogging logs each unary RPC along with the client identity, outcome
+// and duration
+func UnaryLogging(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	logger.Debugf("%s call from %s", info.FullMethod, identityString(ctx))
+	resp, err := handler(ctx, req)
+	logCompletion(info.FullMethod, identityString(ctx), start, err)
+	return resp, err
+}
+
+// StreamLogging logs each streaming RPC along with the client identity,
+// outcome and duration
+func StreamLogging(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	logger.Debugf("%s stream from %s", info.FullMethod, identityString(ss.Context()))
+	err := handler(srv, ss)
+	logCompletion(info.FullMethod, identityString(ss.Context()), start, err)
+	return err
+}
+
+func logCompletion(method, identity string, start time.Time, err error) {
+	if err != nil {
+		logger.Warningf("%s from %s failed after %s: %s", method, identity, time.Since(start), err)
+		return
+	}
+	logger.Debugf("%s from %s completed after %s", method, identity, time.Since(start))
+}
+
+// UnaryMetrics counts the calls, errors and time spent for each unary RPC
+func UnaryMetrics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	rpcMetrics.Add(info.FullMethod+".calls", 1)
+	resp, err := handler(ctx, req)
+	recordCompletion(info.FullMethod, start, err)
+	return resp, err
+}
+
+// StreamMetrics counts the streams, open streams, errors and time spent for
+// each streaming RPC
+func StreamMetrics(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	rpcMetrics.Add(info.FullMethod+".calls", 1)
+	rpcMetrics.Add(info.FullMethod+".active", 1)
+	defer rpcMetrics.Add(info.FullMethod+".active", -1)
+	err := handler(srv, ss)
+	recordCompletion(info.FullMethod, start, err)
+	return err
+}
+
+func recordCompletion(method string, start time.Time, err error) {
+	if err != nil {
+		rpcMetrics.Add(method+".errors", 1)
+	}
+	rpcMetrics.AddFloat(method+".seconds", time.Since(start).Seconds())
+}
+
+// UnaryRecovery converts a panic in the RPC handler into an INTERNAL error
+func UnaryRecovery(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// StreamRecovery converts a panic in the stream handler into an INTERNAL
+// error
+func StreamRecovery(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recoveredError(method string, r interface{}) error {
+	logger.Criticalf("%s handler triggered panic: %s\n%s", method, r, debug.Stack())
+	return grpc.Errorf(codes.Internal, "%s handler triggered panic: %s", method, r)
+}
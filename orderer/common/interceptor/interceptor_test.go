@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"expvar"
+	"net"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type mockStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ms *mockStream) Context() context.Context {
+	return ms.ctx
+}
+
+func peerContext(cert *x509.Certificate) context.Context {
+	pr := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 7050}}
+	if cert != nil {
+		pr.AuthInfo = credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+	}
+	return peer.NewContext(context.Background(), pr)
+}
+
+func metric(key string) string {
+	v := rpcMetrics.Get(key)
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func TestIdentity(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "client1"}}
+
+	t.Run("Unary", func(t *testing.T) {
+		var id *Identity
+		_, err := UnaryAuth(peerContext(cert), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			var ok bool
+			id, ok = IdentityFromContext(ctx)
+			assert.True(t, ok)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, cert, id.Certificate)
+		assert.Equal(t, "client1@127.0.0.1:7050", id.String())
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		var id *Identity
+		err := StreamAuth(nil, &mockStream{ctx: peerContext(nil)}, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+			var ok bool
+			id, ok = IdentityFromContext(ss.Context())
+			assert.True(t, ok)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, id.Certificate)
+		assert.Equal(t, "127.0.0.1:7050", id.String())
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, ok := IdentityFromContext(context.Background())
+		assert.False(t, ok)
+		assert.Equal(t, "unknown", identityString(context.Background()))
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	panicking := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("unary failure")
+	}
+	_, err := UnaryRecovery(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Unary"}, panicking)
+	assert.Equal(t, codes.Internal, grpc.Code(err))
+
+	err = StreamRecovery(nil, &mockStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/test/Stream"},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			panic("stream failure")
+		})
+	assert.Equal(t, codes.Internal, grpc.Code(err))
+	assert.Contains(t, err.Error(), "stream failure")
+
+	err = StreamRecovery(nil, &mockStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/test/Stream"},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			return errors.New("plain failure")
+		})
+	assert.EqualError(t, err, "plain failure", "Errors should be passed through unchanged")
+}
+
+func TestMetrics(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Metrics/Stream"}
+	err := StreamMetrics(nil, &mockStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		assert.Equal(t, "1", metric(info.FullMethod+".active"), "Stream should be counted as active while open")
+		return errors.New("stream failure")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "1", metric(info.FullMethod+".calls"))
+	assert.Equal(t, "0", metric(info.FullMethod+".active"))
+	assert.Equal(t, "1", metric(info.FullMethod+".errors"))
+	assert.NotEmpty(t, metric(info.FullMethod+".seconds"))
+
+	unaryInfo := &grpc.UnaryServerInfo{FullMethod: "/test.Metrics/Unary"}
+	for i := 0; i < 2; i++ {
+		_, err = UnaryMetrics(context.Background(), nil, unaryInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "2", metric(unaryInfo.FullMethod+".calls"))
+	assert.Equal(t, "", metric(unaryInfo.FullMethod+".errors"))
+
+	assert.NotNil(t, expvar.Get("orderer_grpc"), "Metrics should be published")
+}
+
+func TestChain(t *testing.T) {
+	// The standard chain should recover panics and still log and count them
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Chain/Stream"}
+	chain := comm.ChainStreamServerInterceptors(StreamInterceptors()...)
+	err := chain(nil, &mockStream{ctx: peerContext(nil)}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		_, ok := IdentityFromContext(ss.Context())
+		assert.True(t, ok)
+		panic("chain failure")
+	})
+	assert.Equal(t, codes.Internal, grpc.Code(err))
+	assert.Equal(t, "1", metric(info.FullMethod+".errors"))
+
+	unaryChain := comm.ChainUnaryServerInterceptors(UnaryInterceptors()...)
+	resp, err := unaryChain(peerContext(nil), "request", &grpc.UnaryServerInfo{FullMethod: "/test.Chain/Unary"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "request", resp)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// StreamLimits are hard ceilings, per second, on the messages and bytes a
+// client may send on a single stream. A limit of 0 means no limit.
+type StreamLimits struct {
+	MessageRate float64
+	ByteRate    float64
+}
+
+// StreamLimiter terminates streams on which the client sends messages faster
+// than the ceilings allow, so that a single client cannot keep the server
+// busy parsing messages regardless of how quickly they are processed.
+//
+// Each stream may send up to one second's worth of traffic at once. A message
+// is accepted as long as the stream is within its ceilings when the message
+// arrives, so a single message may exceed the byte ceiling, after which the
+// stream must pause in proportion to its size.
+type StreamLimiter struct {
+	limits StreamLimits
+	now    func() time.Time
+}
+
+// NewStreamLimiter creates a StreamLimiter enforcing the given limits, it
+// returns nil if no limit is set
+func NewStreamLimiter(limits StreamLimits) *StreamLimiter {
+	if limits == (StreamLimits{}) {
+		return nil
+	}
+	return &StreamLimiter{limits: limits, now: time.Now}
+}
+
+// Stream enforces the ceilings on the messages received on the stream
+func (sl *StreamLimiter) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	now := sl.now()
+	return handler(srv, &limitedStream{
+		ServerStream: ss,
+		method:       info.FullMethod,
+		now:          sl.now,
+		messages:     newCeiling(sl.limits.MessageRate, now),
+		bytes:        newCeiling(sl.limits.ByteRate, now),
+	})
+}
+
+// ceiling tracks the balance of a stream against a rate limit, which is
+// refilled continuously up to one second's worth
+type ceiling struct {
+	rate    float64
+	balance float64
+	last    time.Time
+}
+
+func newCeiling(rate float64, now time.Time) *ceiling {
+	return &ceiling{rate: rate, balance: rate, last: now}
+}
+
+// spend deducts amount from the balance, reporting whether the balance was
+// positive, and so the stream within the ceiling, beforehand
+func (c *ceiling) spend(amount float64, now time.Time) bool {
+	if c.rate == 0 {
+		return true
+	}
+	c.balance += now.Sub(c.last).Seconds() * c.rate
+	if c.balance > c.rate {
+		c.balance = c.rate
+	}
+	c.last = now
+	within := c.balance > 0
+	c.balance -= amount
+	return within
+}
+
+type limitedStream struct {
+	grpc.ServerStream
+	method   string
+	now      func() time.Time
+	messages *ceiling
+	bytes    *ceiling
+}
+
+func (ls *limitedStream) RecvMsg(m interface{}) error {
+	if err := ls.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	var size int
+	if pm, ok := m.(proto.Message); ok {
+		size = proto.Size(pm)
+	}
+	now := ls.now()
+	if !ls.messages.spend(1, now) {
+		return ls.exceeded("message")
+	}
+	if !ls.bytes.spend(float64(size), now) {
+		return ls.exceeded("byte")
+	}
+	return nil
+}
+
+func (ls *limitedStream) exceeded(limit string) error {
+	logger.Warningf("Terminating %s stream from %s, %s rate ceiling exceeded", ls.method, identityString(ls.Context()), limit)
+	rpcMetrics.Add(ls.method+".stream_limited", 1)
+	return grpc.Errorf(codes.ResourceExhausted, "stream %s rate ceiling exceeded", limit)
+}
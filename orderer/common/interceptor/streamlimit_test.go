@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// payloadStream receives envelopes with payloads of the given size
+type payloadStream struct {
+	mockStream
+	size int
+}
+
+func (ps *payloadStream) RecvMsg(m interface{}) error {
+	m.(*cb.Envelope).Payload = make([]byte, ps.size)
+	return nil
+}
+
+var limitedInfo = &grpc.StreamServerInfo{FullMethod: "/test.Limit/Stream"}
+
+// receive runs a handler which receives up to n messages, advancing the clock
+// by interval before each, and returns the number received and the error
+// which terminated the stream, if any
+func receive(sl *StreamLimiter, c *clock, size, n int, interval time.Duration) (int, error) {
+	received := 0
+	err := sl.Stream(nil, &payloadStream{mockStream: mockStream{ctx: withIdentity(peerContext(nil))}, size: size}, limitedInfo,
+		func(srv interface{}, ss grpc.ServerStream) error {
+			for received < n {
+				c.now = c.now.Add(interval)
+				if err := ss.RecvMsg(&cb.Envelope{}); err != nil {
+					return err
+				}
+				received++
+			}
+			return nil
+		})
+	return received, err
+}
+
+func newTestStreamLimiter(limits StreamLimits) (*StreamLimiter, *clock) {
+	c := &clock{now: time.Unix(1000, 0)}
+	sl := NewStreamLimiter(limits)
+	sl.now = c.Now
+	return sl, c
+}
+
+func TestNewStreamLimiter(t *testing.T) {
+	assert.Nil(t, NewStreamLimiter(StreamLimits{}), "No limiter is needed without limits")
+}
+
+func TestStreamMessageCeiling(t *testing.T) {
+	sl, c := newTestStreamLimiter(StreamLimits{MessageRate: 10})
+
+	received, err := receive(sl, c, 10, 100, 100*time.Millisecond)
+	assert.NoError(t, err, "Messages within the ceiling should be accepted")
+	assert.Equal(t, 100, received)
+
+	received, err = receive(sl, c, 10, 100, 0)
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	assert.Equal(t, 10, received, "One second's worth of messages should be accepted at once")
+	assert.Equal(t, "1", metric(limitedInfo.FullMethod+".stream_limited"))
+}
+
+func TestStreamByteCeiling(t *testing.T) {
+	sl, c := newTestStreamLimiter(StreamLimits{ByteRate: 1000})
+
+	received, err := receive(sl, c, 2000, 1, 0)
+	assert.NoError(t, err, "A single message may exceed the byte ceiling")
+	assert.Equal(t, 1, received)
+
+	received, err = receive(sl, c, 2000, 10, 0)
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	assert.Equal(t, 1, received, "The stream should be terminated after a large message without a pause")
+
+	received, err = receive(sl, c, 2000, 10, 3*time.Second)
+	assert.NoError(t, err, "Pausing in proportion to the message size should be accepted")
+	assert.Equal(t, 10, received)
+}
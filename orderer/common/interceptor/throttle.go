@@ -0,0 +1,160 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// pruneInterval is how often buckets which have refilled, and so no longer
+// carry any state, are discarded
+const pruneInterval = time.Minute
+
+// Rates are the sustained rates, per second, at which a single client may
+// open streams and issue unary RPCs. A rate of 0 means no limit.
+type Rates struct {
+	StreamRate float64
+	RPCRate    float64
+}
+
+// Throttle limits the rate at which streams are opened and unary RPCs are
+// issued, separately for each client certificate and each source IP, using a
+// token bucket per client which allows bursts of up to burst requests.
+type Throttle struct {
+	perIdentity Rates
+	perIP       Rates
+	burst       float64
+
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// NewThrottle creates a Throttle enforcing the given limits, it returns nil
+// if no limit is set
+func NewThrottle(perIdentity, perIP Rates, burst uint32) *Throttle {
+	if perIdentity == (Rates{}) && perIP == (Rates{}) {
+		return nil
+	}
+	if burst == 0 {
+		burst = 1
+	}
+	return &Throttle{
+		perIdentity: perIdentity,
+		perIP:       perIP,
+		burst:       float64(burst),
+		buckets:     make(map[string]*bucket),
+		now:         time.Now,
+	}
+}
+
+// Unary rejects unary RPCs from clients which exceed their RPC rate
+func (t *Throttle) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := t.admit(ctx, info.FullMethod, "rpc", t.perIdentity.RPCRate, t.perIP.RPCRate); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream rejects streams from clients which exceed their stream rate
+func (t *Throttle) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := t.admit(ss.Context(), info.FullMethod, "stream", t.perIdentity.StreamRate, t.perIP.StreamRate); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (t *Throttle) admit(ctx context.Context, method, kind string, identityRate, ipRate float64) error {
+	id, ok := IdentityFromContext(ctx)
+	if !ok {
+		id, _ = IdentityFromContext(withIdentity(ctx))
+	}
+
+	if identityRate > 0 && id.Certificate != nil {
+		fingerprint := sha256.Sum256(id.Certificate.Raw)
+		if ok, wait := t.allow("identity/"+kind+"/"+hex.EncodeToString(fingerprint[:]), identityRate); !ok {
+			return t.reject(method, id, "client certificate", wait)
+		}
+	}
+
+	if ipRate > 0 && id.Address != "" {
+		host, _, err := net.SplitHostPort(id.Address)
+		if err != nil {
+			host = id.Address
+		}
+		if ok, wait := t.allow("ip/"+kind+"/"+host, ipRate); !ok {
+			return t.reject(method, id, "source address", wait)
+		}
+	}
+
+	return nil
+}
+
+// reject returns the error rejecting the request, which tells the client to
+// retry once the wait for the next token is over
+func (t *Throttle) reject(method string, id *Identity, limited string, wait time.Duration) error {
+	logger.Warningf("Throttling %s from %s, %s rate limit exceeded", method, id, limited)
+	rpcMetrics.Add(method+".throttled", 1)
+	return rpcstatus.Error(codes.ResourceExhausted, fmt.Sprintf("%s rate limit exceeded", limited), rpcstatus.Retry(wait))
+}
+
+// allow takes a token from the bucket with the given key, refilling it at
+// the given rate, and reports whether one was available, or else how long
+// until one is
+func (t *Throttle) allow(key string, rate float64) (bool, time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	if now.Sub(t.lastPrune) > pruneInterval {
+		t.prune(now)
+	}
+
+	b, ok := t.buckets[key]
+	if !ok {
+		b = &bucket{rate: rate, tokens: t.burst, last: now}
+		t.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > t.burst {
+		b.tokens = t.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (t *Throttle) prune(now time.Time) {
+	for key, b := range t.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= t.burst {
+			delete(t.buckets, key)
+		}
+	}
+	t.lastPrune = now
+}
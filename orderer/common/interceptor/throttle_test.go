@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestThrottle(perIdentity, perIP Rates, burst uint32) (*Throttle, *clock) {
+	c := &clock{now: time.Unix(1000, 0)}
+	t := NewThrottle(perIdentity, perIP, burst)
+	t.now = c.Now
+	return t, c
+}
+
+func addressContext(cert *x509.Certificate, address string) context.Context {
+	ctx := peerContext(cert)
+	pr, _ := peer.FromContext(ctx)
+	addr, _ := net.ResolveTCPAddr("tcp", address)
+	pr.Addr = addr
+	return withIdentity(ctx)
+}
+
+var unaryInfo = &grpc.UnaryServerInfo{FullMethod: "/test.Throttle/Unary"}
+
+func unary(t *Throttle, ctx context.Context) error {
+	_, err := t.Unary(ctx, nil, unaryInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func stream(t *Throttle, ctx context.Context) error {
+	return t.Stream(nil, &mockStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/test.Throttle/Stream"},
+		func(srv interface{}, ss grpc.ServerStream) error { return nil })
+}
+
+func TestNewThrottle(t *testing.T) {
+	assert.Nil(t, NewThrottle(Rates{}, Rates{}, 10), "No throttle is needed without limits")
+	assert.Equal(t, float64(1), NewThrottle(Rates{RPCRate: 1}, Rates{}, 0).burst, "Burst should allow at least one request")
+}
+
+func TestThrottlePerIdentity(t *testing.T) {
+	th, c := newTestThrottle(Rates{RPCRate: 1}, Rates{}, 2)
+	client1 := addressContext(&x509.Certificate{Raw: []byte("client1"), Subject: pkix.Name{CommonName: "client1"}}, "10.0.0.1:1000")
+	client2 := addressContext(&x509.Certificate{Raw: []byte("client2"), Subject: pkix.Name{CommonName: "client2"}}, "10.0.0.1:1001")
+
+	assert.NoError(t, unary(th, client1))
+	assert.NoError(t, unary(th, client1))
+	err := unary(th, client1)
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(err), "Burst should be exhausted")
+	assert.Equal(t, []proto.Message{&ab.RetryInfo{RetryDelayMs: 1000}}, rpcstatus.Details(err), "Clients should be told when a token is available")
+	assert.Equal(t, "1", metric(unaryInfo.FullMethod+".throttled"))
+
+	assert.NoError(t, unary(th, client2), "Other identities should not be affected")
+	assert.NoError(t, stream(th, client1), "Streams should not be limited")
+
+	c.now = c.now.Add(time.Second)
+	assert.NoError(t, unary(th, client1), "Bucket should refill at the configured rate")
+	assert.Error(t, unary(th, client1))
+
+	assert.NoError(t, unary(th, addressContext(nil, "10.0.0.1:1002")), "Clients without certificates are not limited per identity")
+}
+
+func TestThrottlePerIP(t *testing.T) {
+	th, _ := newTestThrottle(Rates{}, Rates{StreamRate: 1}, 1)
+	cert := &x509.Certificate{Raw: []byte("client1")}
+
+	assert.NoError(t, stream(th, addressContext(cert, "10.0.0.1:1000")))
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(stream(th, addressContext(nil, "10.0.0.1:1001"))),
+		"Connections from the same host should share a limit")
+	assert.NoError(t, stream(th, addressContext(cert, "10.0.0.2:1000")))
+	assert.NoError(t, unary(th, addressContext(cert, "10.0.0.1:1000")), "Unary RPCs should not be limited")
+}
+
+func TestThrottlePrune(t *testing.T) {
+	th, c := newTestThrottle(Rates{}, Rates{RPCRate: 1}, 1)
+	assert.NoError(t, unary(th, addressContext(nil, "10.0.0.1:1000")))
+	assert.Len(t, th.buckets, 1)
+
+	c.now = c.now.Add(2 * pruneInterval)
+	assert.NoError(t, unary(th, addressContext(nil, "10.0.0.2:1000")))
+	assert.Len(t, th.buckets, 1, "The refilled bucket should have been discarded")
+}
@@ -0,0 +1,288 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// authorizationHeader is the metadata key carrying bearer tokens
+const authorizationHeader = "authorization"
+
+// AdminRole is the role claimed by the tokens of administrators
+const AdminRole = "admin"
+
+// TokenClaims are the claims of a bearer token which identify the client
+type TokenClaims struct {
+	// Subject names the client
+	Subject string `json:"sub"`
+	// MSPID is the MSP the client is a member of
+	MSPID string `json:"msp"`
+	// Role is the role of the client, AdminRole for administrators
+	Role string `json:"role,omitempty"`
+	// ExpiresAt is the time, in seconds since the epoch, after which the
+	// token must not be accepted
+	ExpiresAt int64 `json:"exp"`
+	// NotBefore is the time, in seconds since the epoch, before which the
+	// token must not be accepted
+	NotBefore int64 `json:"nbf,omitempty"`
+}
+
+// Principal is the client a verified bearer token vouches for. It is stored
+// in the context of the RPCs of the client by the TokenAuthenticator, so that
+// the services authorize the requests of the client as the principal.
+type Principal struct {
+	// Subject names the client
+	Subject string
+	// MSPID is the MSP the client is a member of
+	MSPID string
+	// Admin is whether the client is an administrator
+	Admin bool
+}
+
+// String returns the subject and MSP of the principal
+func (p *Principal) String() string {
+	return fmt.Sprintf("%s(%s)", p.Subject, p.MSPID)
+}
+
+// Creates checks that the serialized identity, the creator of a message
+// submitted by the client, is of the MSP of the principal, so that a client
+// authenticated by a token cannot act with the identities of another MSP.
+func (p *Principal) Creates(creator []byte) error {
+	sid := &mspproto.SerializedIdentity{}
+	if err := proto.Unmarshal(creator, sid); err != nil {
+		return fmt.Errorf("malformed creator: %s", err)
+	}
+	if sid.Mspid != p.MSPID {
+		return fmt.Errorf("creator of MSP %s does not match the token of %s", sid.Mspid, p)
+	}
+	return nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the context which stores the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the Principal of the bearer token the client
+// authenticated with, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+}
+
+// TokenAuthenticator verifies bearer tokens, JSON Web Tokens signed with
+// ES256 or RS256 by the key of one of a set of trusted issuer certificates,
+// attaches their claims to the Identity of the client and stores the
+// Principal they vouch for in the context.
+type TokenAuthenticator struct {
+	keys     []crypto.PublicKey
+	required bool
+	trail    *audit.Trail
+	now      func() time.Time
+}
+
+// NewTokenAuthenticator creates a TokenAuthenticator trusting the PEM encoded
+// issuer certificates. If required is set, clients which present neither a
+// token nor a TLS client certificate are rejected. Failed authentications
+// are recorded in the audit trail, unless it is nil.
+func NewTokenAuthenticator(issuerCerts [][]byte, required bool, trail *audit.Trail) (*TokenAuthenticator, error) {
+	ta := &TokenAuthenticator{required: required, trail: trail, now: time.Now}
+	for _, issuerCert := range issuerCerts {
+		block, _ := pem.Decode(issuerCert)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM data found in token issuer certificate")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token issuer certificate: %s", err)
+		}
+		ta.keys = append(ta.keys, cert.PublicKey)
+	}
+	if len(ta.keys) == 0 {
+		return nil, fmt.Errorf("at least one token issuer certificate is required")
+	}
+	return ta, nil
+}
+
+// Requiring returns an authenticator trusting the same issuers, which
+// rejects the clients presenting neither a token nor a TLS client
+// certificate if required is set, so that the listeners of a server may
+// differ in whether they require authentication. A nil TokenAuthenticator
+// returns nil.
+func (ta *TokenAuthenticator) Requiring(required bool) *TokenAuthenticator {
+	if ta == nil {
+		return nil
+	}
+	requiring := *ta
+	requiring.required = required
+	return &requiring
+}
+
+// Unary authenticates the bearer token of a unary RPC, if any
+func (ta *TokenAuthenticator) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := ta.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream authenticates the bearer token of a stream, if any
+func (ta *TokenAuthenticator) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := ta.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func (ta *TokenAuthenticator) authenticate(ctx context.Context) (context.Context, error) {
+	id, ok := IdentityFromContext(ctx)
+	if !ok {
+		ctx = withIdentity(ctx)
+		id, _ = IdentityFromContext(ctx)
+	}
+
+	token, present := bearerToken(ctx)
+	if !present {
+		if ta.required && id.Certificate == nil {
+			ta.trail.Record(audit.Event{
+				Type:   audit.AuthenticationFailure,
+				Remote: id.Address,
+				Detail: "no bearer token or TLS client certificate presented",
+			})
+			return nil, grpc.Errorf(codes.Unauthenticated, "a bearer token or TLS client certificate is required")
+		}
+		return ctx, nil
+	}
+
+	claims, err := ta.Verify(token)
+	if err != nil {
+		logger.Warningf("Rejecting bearer token from %s: %s", id, err)
+		ta.trail.Record(audit.Event{
+			Type:   audit.AuthenticationFailure,
+			Remote: id.Address,
+			Detail: fmt.Sprintf("invalid bearer token: %s", err),
+		})
+		return nil, grpc.Errorf(codes.Unauthenticated, "invalid bearer token: %s", err)
+	}
+	id.Token = claims
+	return WithPrincipal(ctx, &Principal{
+		Subject: claims.Subject,
+		MSPID:   claims.MSPID,
+		Admin:   claims.Role == AdminRole,
+	}), nil
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, value := range md[authorizationHeader] {
+		if strings.HasPrefix(value, "Bearer ") {
+			return strings.TrimPrefix(value, "Bearer "), true
+		}
+	}
+	return "", false
+}
+
+// Verify checks the signature and validity period of the token, returning
+// its claims
+func (ta *TokenAuthenticator) Verify(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	header := &tokenHeader{}
+	if err := decodeSegment(parts[0], header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %s", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %s", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ta.verifySignature(header.Algorithm, digest[:], signature) {
+		return nil, fmt.Errorf("token signature is not from a trusted issuer")
+	}
+
+	claims := &TokenClaims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %s", err)
+	}
+	now := ta.now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return nil, fmt.Errorf("token has expired")
+	}
+	if now < claims.NotBefore {
+		return nil, fmt.Errorf("token is not yet valid")
+	}
+	if claims.Subject == "" || claims.MSPID == "" {
+		return nil, fmt.Errorf("token must name a subject and MSP")
+	}
+	return claims, nil
+}
+
+func (ta *TokenAuthenticator) verifySignature(algorithm string, digest, signature []byte) bool {
+	for _, key := range ta.keys {
+		switch key := key.(type) {
+		case *ecdsa.PublicKey:
+			if algorithm != "ES256" || len(signature) != 64 {
+				continue
+			}
+			r := new(big.Int).SetBytes(signature[:32])
+			s := new(big.Int).SetBytes(signature[32:])
+			if ecdsa.Verify(key, digest, r, s) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if algorithm != "RS256" {
+				continue
+			}
+			if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interceptor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func issuerCert(t *testing.T, pub, priv interface{}) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "issuer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, claims *TokenClaims) string {
+	signed := encodeSegment(t, &tokenHeader{Algorithm: "ES256"}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	assert.NoError(t, err)
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims *TokenClaims) string {
+	signed := encodeSegment(t, &tokenHeader{Algorithm: "RS256"}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() *TokenClaims {
+	return &TokenClaims{Subject: "app1", MSPID: "SampleOrg", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+}
+
+func tokenContext(token string) context.Context {
+	return metadata.NewIncomingContext(peerContext(nil), metadata.Pairs(authorizationHeader, "Bearer "+token))
+}
+
+func TestNewTokenAuthenticator(t *testing.T) {
+	_, err := NewTokenAuthenticator(nil, false, nil)
+	assert.EqualError(t, err, "at least one token issuer certificate is required")
+
+	_, err = NewTokenAuthenticator([][]byte{[]byte("garbage")}, false, nil)
+	assert.EqualError(t, err, "no PEM data found in token issuer certificate")
+}
+
+func TestVerifyToken(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	untrusted, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	ta, err := NewTokenAuthenticator([][]byte{
+		issuerCert(t, &ecKey.PublicKey, ecKey),
+		issuerCert(t, &rsaKey.PublicKey, rsaKey),
+	}, false, nil)
+	assert.NoError(t, err)
+
+	claims, err := ta.Verify(signES256(t, ecKey, validClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, "app1", claims.Subject)
+
+	_, err = ta.Verify(signRS256(t, rsaKey, validClaims()))
+	assert.NoError(t, err)
+
+	_, err = ta.Verify(signES256(t, untrusted, validClaims()))
+	assert.EqualError(t, err, "token signature is not from a trusted issuer")
+
+	expired := validClaims()
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	_, err = ta.Verify(signES256(t, ecKey, expired))
+	assert.EqualError(t, err, "token has expired")
+
+	early := validClaims()
+	early.NotBefore = time.Now().Add(time.Minute).Unix()
+	_, err = ta.Verify(signES256(t, ecKey, early))
+	assert.EqualError(t, err, "token is not yet valid")
+
+	anonymous := validClaims()
+	anonymous.MSPID = ""
+	_, err = ta.Verify(signES256(t, ecKey, anonymous))
+	assert.EqualError(t, err, "token must name a subject and MSP")
+
+	_, err = ta.Verify("not-a-token")
+	assert.EqualError(t, err, "malformed token")
+}
+
+func TestTokenInterceptors(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	ta, err := NewTokenAuthenticator([][]byte{issuerCert(t, &key.PublicKey, key)}, true, nil)
+	assert.NoError(t, err)
+	token := signES256(t, key, validClaims())
+
+	t.Run("Unary", func(t *testing.T) {
+		_, err := ta.Unary(tokenContext(token), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			id, ok := IdentityFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "app1(SampleOrg)@127.0.0.1:7050", id.String())
+			principal, ok := PrincipalFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, &Principal{Subject: "app1", MSPID: "SampleOrg"}, principal)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		err := ta.Stream(nil, &mockStream{ctx: withIdentity(tokenContext(token))}, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+			id, _ := IdentityFromContext(ss.Context())
+			assert.NotNil(t, id.Token)
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		claims := validClaims()
+		claims.Role = AdminRole
+		_, err := ta.Unary(tokenContext(signES256(t, key, claims)), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			principal, _ := PrincipalFromContext(ctx)
+			assert.True(t, principal.Admin)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ta.Unary(tokenContext("a.b.c"), nil, &grpc.UnaryServerInfo{}, nil)
+		assert.Equal(t, codes.Unauthenticated, grpc.Code(err))
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		_, err := ta.Unary(peerContext(nil), nil, &grpc.UnaryServerInfo{}, nil)
+		assert.Equal(t, codes.Unauthenticated, grpc.Code(err))
+
+		_, err = ta.Unary(peerContext(&x509.Certificate{}), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		assert.NoError(t, err, "A TLS client certificate should be accepted in place of a token")
+
+		_, err = ta.Requiring(false).Unary(peerContext(nil), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		assert.NoError(t, err, "An authenticator which does not require tokens should accept anonymous clients")
+		assert.Nil(t, (*TokenAuthenticator)(nil).Requiring(true))
+	})
+}
+
+func TestPrincipalCreates(t *testing.T) {
+	principal := &Principal{Subject: "app1", MSPID: "SampleOrg"}
+	assert.NoError(t, principal.Creates(utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: "SampleOrg"})))
+	assert.EqualError(t, principal.Creates(utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: "OtherOrg"})), "creator of MSP OtherOrg does not match the token of app1(SampleOrg)")
+	assert.Error(t, principal.Creates([]byte("garbage")))
+}
@@ -0,0 +1,238 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package jointoken lets the admins of a channel distribute its bootstrap
+// material in a controlled manner. An admin has an orderer mint a signed join
+// token, expiring after a bounded validity, which it hands to a new orderer
+// or consumer. Its bearer presents the token to any orderer of the channel to
+// fetch the latest config block of the channel, from which it may start
+// serving or following the channel, without being a member of the channel.
+package jointoken
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/common/sigfilter"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var logger = logging.MustGetLogger("orderer/common/jointoken")
+
+// DefaultMaxValidity is how long a join token is valid at most unless
+// configured otherwise
+const DefaultMaxValidity = 24 * time.Hour
+
+// channelOrdererAdmins is the label for the channel's orderer admins policy
+const channelOrdererAdmins = policies.PathSeparator + policies.ChannelPrefix + policies.PathSeparator + policies.OrdererPrefix + policies.PathSeparator + "Admins"
+
+// mintPolicies are the policies of which the signer of a request to mint a
+// token must satisfy one. The system channel has no application admins.
+var mintPolicies = []string{policies.ChannelApplicationAdmins, channelOrdererAdmins}
+
+// ChainSupport provides the resources of a chain needed to grant its config
+// block
+type ChainSupport interface {
+	// PolicyManager returns the current policy manager as specified by the chain config
+	PolicyManager() policies.Manager
+
+	// Reader returns the chain Reader for the chain
+	Reader() ledger.Reader
+}
+
+// Support provides the chains whose config blocks may be granted
+type Support interface {
+	// GetChain gets the chain support for a given ChannelId
+	GetChain(chainID string) (ChainSupport, bool)
+}
+
+// Server implements the ChannelJoin service
+type Server struct {
+	support     Support
+	signer      crypto.LocalSigner
+	maxValidity time.Duration
+	trail       *audit.Trail
+	now         func() time.Time
+}
+
+// NewServer creates a ChannelJoin service which signs the tokens it mints
+// with signer, valid for at most maxValidity, or DefaultMaxValidity if it is
+// not positive. The tokens minted, and the requests denied, are recorded in
+// the audit trail, unless it is nil.
+func NewServer(support Support, signer crypto.LocalSigner, maxValidity time.Duration, trail *audit.Trail) *Server {
+	if maxValidity <= 0 {
+		maxValidity = DefaultMaxValidity
+	}
+	return &Server{support: support, signer: signer, maxValidity: maxValidity, trail: trail, now: time.Now}
+}
+
+// MintJoinToken returns a token granting the config block of the channel of
+// the request, which must be signed by an admin of the channel
+func (s *Server) MintJoinToken(ctx context.Context, env *cb.Envelope) (*ab.JoinToken, error) {
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "malformed request: %s", err)
+	}
+	chainID := msg.ChannelHeader.ChannelId
+	chain, ok := s.support.GetChain(chainID)
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "channel %s not found", chainID)
+	}
+	if !authorized(msg, chain.PolicyManager(), s.trail) {
+		logger.Warningf("Rejecting request from %s to mint a join token for channel %s, not signed by an admin", comm.ClientIdentity(ctx), chainID)
+		s.trail.Record(audit.Event{
+			Type:    audit.AccessDenied,
+			Subject: comm.ClientIdentity(ctx),
+			Action:  "MintJoinToken",
+			Detail:  fmt.Sprintf("not an admin of channel %s", chainID),
+		})
+		return nil, grpc.Errorf(codes.PermissionDenied, "not authorized to mint join tokens for channel %s", chainID)
+	}
+
+	req := &ab.JoinTokenRequest{}
+	if err := pool.Unmarshal(msg.Payload.Data, req); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "malformed join token request: %s", err)
+	}
+	now := s.now()
+	expires := now.Add(s.maxValidity)
+	if req.Expires != nil {
+		requested := time.Unix(req.Expires.Seconds, int64(req.Expires.Nanos))
+		if !requested.After(now) {
+			return nil, grpc.Errorf(codes.InvalidArgument, "requested expiry %s is in the past", requested.UTC())
+		}
+		if requested.Before(expires) {
+			expires = requested
+		}
+	}
+
+	nonce, err := crypto.GetRandomNonce()
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "failed to create nonce: %s", err)
+	}
+	content := utils.MarshalOrPanic(&ab.JoinTokenContent{
+		ChannelId: chainID,
+		Expires:   &timestamp.Timestamp{Seconds: expires.Unix(), Nanos: int32(expires.Nanosecond())},
+		Nonce:     nonce,
+		IssuedTo:  msg.SignatureHeader.Creator,
+	})
+	token, err := s.sign(content)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "failed to sign join token: %s", err)
+	}
+
+	logger.Infof("Minted join token for channel %s requested by %s, expiring at %s", chainID, comm.ClientIdentity(ctx), expires.UTC())
+	s.trail.Record(audit.Event{
+		Type:    audit.AdminAction,
+		Subject: comm.ClientIdentity(ctx),
+		Action:  "MintJoinToken",
+		Detail:  fmt.Sprintf("channel %s, expires %s", chainID, expires.UTC().Format(time.RFC3339)),
+	})
+	return token, nil
+}
+
+// authorized returns whether the message is signed by an admin of the channel
+func authorized(msg *filter.Message, pm policies.Manager, trail *audit.Trail) bool {
+	for _, policy := range mintPolicies {
+		if result, _ := sigfilter.New(policy, pm, trail).Apply(msg); result == filter.Forward {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) sign(content []byte) (*ab.JoinToken, error) {
+	header, err := s.signer.NewSignatureHeader()
+	if err != nil {
+		return nil, err
+	}
+	token := &ab.JoinToken{Content: content, SignatureHeader: utils.MarshalOrPanic(header)}
+	token.Signature, err = s.signer.Sign(util.ConcatenateBytes(token.Content, token.SignatureHeader))
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetConfigBlock returns the latest config block of the channel of the
+// token, which must have been signed by an orderer of the channel and not
+// have expired
+func (s *Server) GetConfigBlock(ctx context.Context, token *ab.JoinToken) (*cb.Block, error) {
+	content := &ab.JoinTokenContent{}
+	if err := pool.Unmarshal(token.Content, content); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "malformed join token: %s", err)
+	}
+	chain, ok := s.support.GetChain(content.ChannelId)
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "channel %s not found", content.ChannelId)
+	}
+	if err := verify(token, chain.PolicyManager()); err != nil {
+		logger.Warningf("Rejecting join token for channel %s presented by %s: %s", content.ChannelId, comm.ClientIdentity(ctx), err)
+		return nil, grpc.Errorf(codes.PermissionDenied, "invalid join token: %s", err)
+	}
+	if content.Expires == nil || !s.now().Before(time.Unix(content.Expires.Seconds, int64(content.Expires.Nanos))) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "join token for channel %s has expired", content.ChannelId)
+	}
+
+	block, err := configBlock(chain.Reader())
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "failed to read the config block of channel %s: %s", content.ChannelId, err)
+	}
+	logger.Infof("Granted config block %d of channel %s to %s", block.Header.Number, content.ChannelId, comm.ClientIdentity(ctx))
+	return block, nil
+}
+
+// verify verifies that the token was signed by an orderer of the channel, as
+// its blocks are
+func verify(token *ab.JoinToken, pm policies.Manager) error {
+	header := &cb.SignatureHeader{}
+	if err := pool.Unmarshal(token.SignatureHeader, header); err != nil {
+		return fmt.Errorf("malformed signature header: %s", err)
+	}
+	policy, ok := pm.GetPolicy(policies.BlockValidation)
+	if !ok {
+		return fmt.Errorf("no block validation policy")
+	}
+	return policy.Evaluate([]*cb.SignedData{{
+		Data:      util.ConcatenateBytes(token.Content, token.SignatureHeader),
+		Identity:  header.Creator,
+		Signature: token.Signature,
+	}})
+}
+
+// configBlock returns the latest config block of the chain
+func configBlock(reader ledger.Reader) (*cb.Block, error) {
+	if reader.Height() == 0 {
+		return nil, fmt.Errorf("empty ledger")
+	}
+	lastBlock := ledger.GetBlock(reader, reader.Height()-1)
+	if lastBlock == nil {
+		return nil, fmt.Errorf("last block not found")
+	}
+	index, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	block := ledger.GetBlock(reader, index)
+	if block == nil {
+		return nil, fmt.Errorf("config block %d not found", index)
+	}
+	return block, nil
+}
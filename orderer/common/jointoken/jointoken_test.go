@@ -0,0 +1,173 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jointoken
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var ordererIdentity = []byte("orderer")
+
+// signedBy is a policy satisfied by the signatures of the mock signer with
+// the identity
+type signedBy []byte
+
+func (sb signedBy) Evaluate(signatureSet []*cb.SignedData) error {
+	for _, sd := range signatureSet {
+		if bytes.Equal(sd.Identity, sb) && bytes.Equal(sd.Signature, sd.Data) {
+			return nil
+		}
+	}
+	return fmt.Errorf("not signed by %s", sb)
+}
+
+type mockChain struct {
+	policyManager *mockpolicies.Manager
+	reader        ledger.ReadWriter
+}
+
+func (mc *mockChain) PolicyManager() policies.Manager {
+	return mc.policyManager
+}
+
+func (mc *mockChain) Reader() ledger.Reader {
+	return mc.reader
+}
+
+type mockSupport map[string]*mockChain
+
+func (ms mockSupport) GetChain(chainID string) (ChainSupport, bool) {
+	chain, ok := ms[chainID]
+	return chain, ok
+}
+
+func lastConfig(index uint64) []byte {
+	return utils.MarshalOrPanic(&cb.Metadata{Value: utils.MarshalOrPanic(&cb.LastConfig{Index: index})})
+}
+
+// newTestServer creates a server for the channel foo, whose config block is
+// the second of its three blocks, of which admin is the application admin
+func newTestServer(t *testing.T) *Server {
+	rl, err := ramledger.New(10).GetOrCreate("foo")
+	require.NoError(t, err)
+	var previousHash []byte
+	for number, lastConfigIndex := range []uint64{0, 1, 1} {
+		block := cb.NewBlock(uint64(number), previousHash)
+		block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = lastConfig(lastConfigIndex)
+		require.NoError(t, rl.Append(block))
+		previousHash = block.Header.Hash()
+	}
+
+	chain := &mockChain{
+		policyManager: &mockpolicies.Manager{
+			Policy: &mockpolicies.Policy{Err: fmt.Errorf("denied")},
+			PolicyMap: map[string]policies.Policy{
+				policies.ChannelApplicationAdmins: signedBy("admin"),
+				policies.BlockValidation:          signedBy(ordererIdentity),
+			},
+		},
+		reader: rl,
+	}
+	return NewServer(mockSupport{"foo": chain}, &mockcrypto.LocalSigner{Identity: ordererIdentity}, time.Hour, nil)
+}
+
+func makeRequest(chainID string, signer []byte, req *ab.JoinTokenRequest) *cb.Envelope {
+	payload := utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(
+			utils.MakeChannelHeader(cb.HeaderType_MESSAGE, 0, chainID, 0),
+			&cb.SignatureHeader{Creator: signer},
+		),
+		Data: utils.MarshalOrPanic(req),
+	})
+	return &cb.Envelope{Payload: payload, Signature: payload}
+}
+
+func expiry(t time.Time) *timestamp.Timestamp {
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+func TestMintJoinToken(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	_, err := s.MintJoinToken(context.Background(), &cb.Envelope{Payload: []byte("garbage")})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	_, err = s.MintJoinToken(context.Background(), makeRequest("bar", []byte("admin"), &ab.JoinTokenRequest{}))
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	_, err = s.MintJoinToken(context.Background(), makeRequest("foo", []byte("reader"), &ab.JoinTokenRequest{}))
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	_, err = s.MintJoinToken(context.Background(), makeRequest("foo", []byte("admin"), &ab.JoinTokenRequest{Expires: expiry(now)}))
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err), "A token should not expire before it is minted")
+
+	for _, tc := range []struct {
+		requested *timestamp.Timestamp
+		expected  time.Time
+	}{
+		{nil, now.Add(time.Hour)},
+		{expiry(now.Add(time.Minute)), now.Add(time.Minute)},
+		{expiry(now.Add(48 * time.Hour)), now.Add(time.Hour)},
+	} {
+		token, err := s.MintJoinToken(context.Background(), makeRequest("foo", []byte("admin"), &ab.JoinTokenRequest{Expires: tc.requested}))
+		require.NoError(t, err)
+		content := &ab.JoinTokenContent{}
+		require.NoError(t, proto.Unmarshal(token.Content, content))
+		assert.Equal(t, "foo", content.ChannelId)
+		assert.Equal(t, []byte("admin"), content.IssuedTo)
+		assert.Equal(t, expiry(tc.expected), content.Expires)
+		assert.NotEmpty(t, content.Nonce)
+	}
+}
+
+func TestGetConfigBlock(t *testing.T) {
+	s := newTestServer(t)
+	token, err := s.MintJoinToken(context.Background(), makeRequest("foo", []byte("admin"), &ab.JoinTokenRequest{}))
+	require.NoError(t, err)
+
+	block, err := s.GetConfigBlock(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), block.Header.Number, "The latest config block should be granted")
+
+	forged := *token
+	forged.Signature = []byte("forged")
+	_, err = s.GetConfigBlock(context.Background(), &forged)
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err))
+
+	other := NewServer(s.support, &mockcrypto.LocalSigner{Identity: []byte("intruder")}, 0, nil)
+	token, err = other.MintJoinToken(context.Background(), makeRequest("foo", []byte("admin"), &ab.JoinTokenRequest{}))
+	require.NoError(t, err)
+	_, err = s.GetConfigBlock(context.Background(), token)
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err), "Tokens should be signed by an orderer of the channel")
+
+	_, err = s.GetConfigBlock(context.Background(), &ab.JoinToken{Content: []byte("garbage")})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err))
+
+	token, err = s.MintJoinToken(context.Background(), makeRequest("foo", []byte("admin"), &ab.JoinTokenRequest{}))
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = s.GetConfigBlock(context.Background(), token)
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err), "Expired tokens should be rejected")
+}
@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package latency follows transactions from their receipt by Broadcast to
+// the append of the block containing them, and records how long each stage
+// took. Transactions are identified by the hash of the marshaled envelope, so
+// that they may be recognized in a block after passing through a consenter
+// which re-encodes them, as Kafka does.
+package latency
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// The stages of a transaction, each measured from the end of the previous
+const (
+	// StageFilter is the time from receipt to passing the broadcast filters
+	StageFilter = "filter"
+	// StageEnqueue is the time taken to hand the transaction to the consenter
+	StageEnqueue = "enqueue"
+	// StageConsensus is the time from enqueue until the block containing the
+	// transaction is committed by the consenter
+	StageConsensus = "consensus"
+	// StageAppend is the time from commit until the block is appended to the
+	// ledger
+	StageAppend = "append"
+)
+
+// DefaultExpiry is how long a transaction is followed before it is assumed to
+// have been dropped by the consenter
+const DefaultExpiry = 5 * time.Minute
+
+// DefaultMaxPending is the most transactions followed at once, beyond which
+// those received are not followed until some have been appended or expired
+const DefaultMaxPending = 100000
+
+// trackerMetrics are the metrics updated by a tracker
+type trackerMetrics struct {
+	stageDuration metrics.Histogram
+	totalDuration metrics.Histogram
+	untracked     metrics.Counter
+}
+
+// newTrackerMetrics creates the metrics of a tracker with the provider, or in
+// the default registry if it is nil
+func newTrackerMetrics(provider metrics.Provider) *trackerMetrics {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &trackerMetrics{
+		stageDuration: provider.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "transaction",
+			Name:       "stage_duration_seconds",
+			Help:       "The time taken by each stage of ordering a transaction.",
+			LabelNames: []string{"channel", "stage"},
+		}}),
+		totalDuration: provider.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "transaction",
+			Name:       "latency_seconds",
+			Help:       "The time from the receipt of a transaction to the append of the block containing it.",
+			LabelNames: []string{"channel"},
+		}}),
+		untracked: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "transaction",
+			Name:       "untracked_total",
+			Help:       "The number of transactions not followed because too many were pending.",
+			LabelNames: []string{"channel"},
+		}),
+	}
+}
+
+type timeline struct {
+	chainID  string
+	received time.Time
+	filtered time.Time
+	enqueued time.Time
+}
+
+// Tracker holds the timeline of each transaction which has been received but
+// not yet appended, keyed by the hash of its envelope. A nil Tracker, of an
+// orderer which does not follow its transactions, follows nothing.
+type Tracker struct {
+	expiry     time.Duration
+	maxPending int
+	timers     *pipeline.Timers
+	metrics    *trackerMetrics
+
+	lock      sync.Mutex
+	pending   map[string]*timeline
+	lastSweep time.Time
+}
+
+// NewTracker creates a Tracker which forgets transactions not appended within
+// expiry of their receipt, follows at most maxPending transactions at once,
+// or any number if it is not positive, and observes the consensus stage of the
+// pipeline with the timers. The durations are recorded with the metrics
+// created by provider, or in the default registry if it is nil.
+func NewTracker(expiry time.Duration, maxPending int, timers *pipeline.Timers, provider metrics.Provider) *Tracker {
+	return &Tracker{
+		expiry:     expiry,
+		maxPending: maxPending,
+		timers:     timers,
+		metrics:    newTrackerMetrics(provider),
+		pending:    make(map[string]*timeline),
+		lastSweep:  time.Now(),
+	}
+}
+
+// Filtered starts following the transaction with the key, which was received
+// and passed the filters at the given times, unless too many are pending
+func (t *Tracker) Filtered(chainID string, key string, received, filtered time.Time) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.sweep(filtered)
+	if _, ok := t.pending[key]; !ok && t.maxPending > 0 && len(t.pending) >= t.maxPending {
+		t.metrics.untracked.With(chainID).Add(1)
+		return
+	}
+	t.pending[key] = &timeline{chainID: chainID, received: received, filtered: filtered}
+}
+
+// Enqueued records that the transaction was handed to the consenter
+func (t *Tracker) Enqueued(key string, enqueued time.Time) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if tl, ok := t.pending[key]; ok {
+		tl.enqueued = enqueued
+	}
+}
+
+// Forget stops following a transaction which will not be ordered, because it
+// could not be enqueued or was rejected by the consenter
+func (t *Tracker) Forget(key string) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.pending, key)
+}
+
+// Appended records the durations of each transaction of the block, which was
+// committed by the consenter and appended to the ledger at the given times.
+// Transactions which were not received by this orderer are ignored.
+func (t *Tracker) Appended(block *cb.Block, committed, appended time.Time) {
+	if t == nil || block.GetData() == nil {
+		return
+	}
+	keys := make([]string, len(block.Data.Data))
+	for i, data := range block.Data.Data {
+		keys[i] = string(util.ComputeSHA256(data))
+	}
+
+	t.lock.Lock()
+	timelines := make([]*timeline, 0, len(keys))
+	for _, key := range keys {
+		if tl, ok := t.pending[key]; ok {
+			timelines = append(timelines, tl)
+			delete(t.pending, key)
+		}
+	}
+	t.lock.Unlock()
+
+	for _, tl := range timelines {
+		if tl.enqueued.IsZero() {
+			// The block was cut before Enqueue returned
+			tl.enqueued = committed
+		}
+		t.metrics.stageDuration.With(tl.chainID, StageFilter).Observe(tl.filtered.Sub(tl.received).Seconds())
+		t.metrics.stageDuration.With(tl.chainID, StageEnqueue).Observe(tl.enqueued.Sub(tl.filtered).Seconds())
+		t.metrics.stageDuration.With(tl.chainID, StageConsensus).Observe(committed.Sub(tl.enqueued).Seconds())
+		t.timers.Observe(pipeline.StageConsensus, committed.Sub(tl.enqueued))
+		t.metrics.stageDuration.With(tl.chainID, StageAppend).Observe(appended.Sub(committed).Seconds())
+		t.metrics.totalDuration.With(tl.chainID).Observe(appended.Sub(tl.received).Seconds())
+	}
+}
+
+// sweep forgets expired transactions, at most once per expiry, so that those
+// dropped by the consenter do not accumulate
+func (t *Tracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.expiry {
+		return
+	}
+	t.lastSweep = now
+	for key, tl := range t.pending {
+		if now.Sub(tl.received) > t.expiry {
+			delete(t.pending, key)
+		}
+	}
+}
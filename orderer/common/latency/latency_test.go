@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func series(registry *metrics.Registry, name string, labelValues ...string) *metrics.Series {
+	for _, f := range registry.Gather() {
+		if f.Name != name {
+			continue
+		}
+		for i, s := range f.Series {
+			if assert.ObjectsAreEqual(labelValues, s.LabelValues) {
+				return &f.Series[i]
+			}
+		}
+	}
+	return nil
+}
+
+// key returns the key of the transaction, the hash of its envelope
+func key(env *cb.Envelope) string {
+	return string(util.ComputeSHA256(utils.MarshalOrPanic(env)))
+}
+
+func TestAppended(t *testing.T) {
+	timers := pipeline.NewTimers()
+	registry := metrics.NewRegistry()
+	tracker := NewTracker(DefaultExpiry, DefaultMaxPending, timers, registry)
+	env := &cb.Envelope{Payload: []byte("tx1")}
+	other := &cb.Envelope{Payload: []byte("tx2")}
+
+	received := time.Now()
+	tracker.Filtered("latencychain", key(env), received, received.Add(time.Millisecond))
+	tracker.Enqueued(key(env), received.Add(2*time.Millisecond))
+
+	block := cb.NewBlock(1, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env), utils.MarshalOrPanic(other)}
+	tracker.Appended(block, received.Add(time.Second), received.Add(2*time.Second))
+	assert.Empty(t, tracker.pending)
+
+	for _, stage := range []string{StageFilter, StageEnqueue, StageConsensus, StageAppend} {
+		s := series(registry, "orderer_transaction_stage_duration_seconds", "latencychain", stage)
+		if assert.NotNil(t, s, stage) {
+			assert.Equal(t, uint64(1), s.Count, stage)
+		}
+	}
+	s := series(registry, "orderer_transaction_latency_seconds", "latencychain")
+	if assert.NotNil(t, s) {
+		assert.Equal(t, uint64(1), s.Count)
+		assert.Equal(t, 2.0, s.Sum)
+	}
+	assert.Equal(t, int64(1), timers.Sample(false)[3].Count, "The consensus stage of the pipeline should be observed")
+}
+
+func TestForget(t *testing.T) {
+	tracker := NewTracker(DefaultExpiry, DefaultMaxPending, nil, nil)
+	now := time.Now()
+	key := key(&cb.Envelope{Payload: []byte("tx")})
+	tracker.Filtered("foo", key, now, now)
+	tracker.Forget(key)
+	assert.Empty(t, tracker.pending)
+}
+
+func TestMaxPending(t *testing.T) {
+	registry := metrics.NewRegistry()
+	tracker := NewTracker(DefaultExpiry, 2, nil, registry)
+	now := time.Now()
+	tracker.Filtered("foo", key(&cb.Envelope{Payload: []byte("tx1")}), now, now)
+	tracker.Filtered("foo", key(&cb.Envelope{Payload: []byte("tx2")}), now, now)
+	tracker.Filtered("foo", key(&cb.Envelope{Payload: []byte("tx3")}), now, now)
+	assert.Len(t, tracker.pending, 2, "The third transaction should not be followed")
+	_, ok := tracker.pending[key(&cb.Envelope{Payload: []byte("tx3")})]
+	assert.False(t, ok)
+	s := series(registry, "orderer_transaction_untracked_total", "foo")
+	if assert.NotNil(t, s) {
+		assert.Equal(t, 1.0, s.Value)
+	}
+
+	tracker.Forget(key(&cb.Envelope{Payload: []byte("tx1")}))
+	tracker.Filtered("foo", key(&cb.Envelope{Payload: []byte("tx3")}), now, now)
+	_, ok = tracker.pending[key(&cb.Envelope{Payload: []byte("tx3")})]
+	assert.True(t, ok, "The transaction should be followed once there is room")
+}
+
+func TestExpiry(t *testing.T) {
+	tracker := NewTracker(time.Minute, DefaultMaxPending, nil, nil)
+	now := time.Now()
+	tracker.Filtered("foo", key(&cb.Envelope{Payload: []byte("tx1")}), now, now)
+	tracker.Filtered("foo", key(&cb.Envelope{Payload: []byte("tx2")}), now.Add(30*time.Second), now.Add(30*time.Second))
+	assert.Len(t, tracker.pending, 2)
+
+	tracker.Filtered("foo", key(&cb.Envelope{Payload: []byte("tx3")}), now.Add(90*time.Second), now.Add(90*time.Second))
+	assert.Len(t, tracker.pending, 2, "The first transaction should have expired")
+	_, ok := tracker.pending[key(&cb.Envelope{Payload: []byte("tx1")})]
+	assert.False(t, ok)
+}
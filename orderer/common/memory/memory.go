@@ -0,0 +1,140 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package memory accounts for the bytes of the messages held by the orderer
+// between their receipt by Broadcast and the cut of the batch containing
+// them, across every chain. Broadcast stops receiving messages while the
+// total exceeds a global cap, pushing back on clients through gRPC flow
+// control, rather than the orderer running out of memory under overload.
+package memory
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+)
+
+// The holders of the accounted bytes
+const (
+	// HolderBroadcast holds the messages received by Broadcast until they
+	// are enqueued or rejected
+	HolderBroadcast = "broadcast"
+	// HolderBlockCutter holds the messages of the pending batches
+	HolderBlockCutter = "blockcutter"
+)
+
+// Accountant tracks the bytes held across the orderer against a limit. A nil
+// Accountant, of an orderer which does not account for memory, holds nothing
+// and never holds messages back.
+type Accountant struct {
+	lock  sync.Mutex
+	limit int64
+	held  int64
+	// freed is closed, and replaced, whenever held bytes are released
+	freed        chan struct{}
+	pendingBytes metrics.Gauge
+}
+
+// NewAccountant creates an Accountant which holds messages back while more
+// than limit bytes are held, or never if limit is not positive. The bytes
+// held are recorded with the metrics created by provider, or in the default
+// registry if it is nil.
+func NewAccountant(limit int64, provider metrics.Provider) *Accountant {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &Accountant{
+		limit: limit,
+		freed: make(chan struct{}),
+		pendingBytes: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "memory",
+			Name:       "pending_bytes",
+			Help:       "The bytes of the messages held pending ordering, by holder.",
+			LabelNames: []string{"holder"},
+		}),
+	}
+}
+
+// SetLimit replaces the limit of the Accountant
+func (a *Accountant) SetLimit(limit int64) {
+	if a == nil {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.limit = limit
+	a.notify()
+}
+
+// Acquire holds size bytes for the holder once fewer bytes than the limit are
+// held, so that a single message larger than the limit is still admitted. It
+// returns false, holding nothing, if cancel is closed first.
+func (a *Accountant) Acquire(holder string, size int64, cancel <-chan struct{}) bool {
+	if a == nil {
+		return true
+	}
+	for {
+		a.lock.Lock()
+		if a.limit <= 0 || a.held < a.limit {
+			a.hold(holder, size)
+			a.lock.Unlock()
+			return true
+		}
+		freed := a.freed
+		a.lock.Unlock()
+
+		select {
+		case <-freed:
+		case <-cancel:
+			return false
+		}
+	}
+}
+
+// Hold holds size bytes for the holder without regard to the limit, for the
+// holders which must not wait, such as the consenters
+func (a *Accountant) Hold(holder string, size int64) {
+	if a == nil {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.hold(holder, size)
+}
+
+// Release releases size bytes held by the holder
+func (a *Accountant) Release(holder string, size int64) {
+	if a == nil || size == 0 {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.held -= size
+	a.pendingBytes.With(holder).Add(float64(-size))
+	a.notify()
+}
+
+// Held returns the number of bytes held
+func (a *Accountant) Held() int64 {
+	if a == nil {
+		return 0
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.held
+}
+
+func (a *Accountant) hold(holder string, size int64) {
+	a.held += size
+	a.pendingBytes.With(holder).Add(float64(size))
+}
+
+// notify wakes the callers waiting to acquire
+func (a *Accountant) notify() {
+	close(a.freed)
+	a.freed = make(chan struct{})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnlimited(t *testing.T) {
+	a := NewAccountant(0, nil)
+	assert.True(t, a.Acquire(HolderBroadcast, 100, nil))
+	assert.True(t, a.Acquire(HolderBroadcast, 100, nil))
+	assert.Equal(t, int64(200), a.Held())
+	a.Release(HolderBroadcast, 200)
+	assert.Equal(t, int64(0), a.Held())
+}
+
+func TestAcquireWaits(t *testing.T) {
+	a := NewAccountant(100, nil)
+	assert.True(t, a.Acquire(HolderBroadcast, 150, nil), "A message larger than the limit should be admitted alone")
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- a.Acquire(HolderBroadcast, 10, nil)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Acquire should wait while the limit is exceeded")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.Hold(HolderBlockCutter, 150)
+	a.Release(HolderBroadcast, 150)
+	select {
+	case <-acquired:
+		t.Fatal("Acquire should wait while the bytes held by other holders exceed the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.Release(HolderBlockCutter, 100)
+	select {
+	case ok := <-acquired:
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("Acquire should complete once the bytes held are below the limit")
+	}
+	assert.Equal(t, int64(60), a.Held())
+}
+
+func TestAcquireCancel(t *testing.T) {
+	a := NewAccountant(100, nil)
+	a.Hold(HolderBlockCutter, 100)
+
+	cancel := make(chan struct{})
+	acquired := make(chan bool)
+	go func() {
+		acquired <- a.Acquire(HolderBroadcast, 10, cancel)
+	}()
+	close(cancel)
+
+	select {
+	case ok := <-acquired:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("Acquire should return once cancelled")
+	}
+	assert.Equal(t, int64(100), a.Held(), "A cancelled acquire should hold nothing")
+}
+
+func TestSetLimit(t *testing.T) {
+	a := NewAccountant(100, nil)
+	a.Hold(HolderBlockCutter, 100)
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- a.Acquire(HolderBroadcast, 10, nil)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Acquire should wait while the limit is exceeded")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.SetLimit(0)
+	select {
+	case ok := <-acquired:
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("Acquire should complete once the limit is removed")
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"strings"
+
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/metrics")
+
+// Counter is a monotonically increasing value, such as the number of
+// messages processed
+type Counter interface {
+	// With returns the Counter for the given values of the next labels
+	With(labelValues ...string) Counter
+	// Add increases the value, delta must not be negative
+	Add(delta float64)
+}
+
+// Gauge is a value which may go up and down, such as a queue depth
+type Gauge interface {
+	// With returns the Gauge for the given values of the next labels
+	With(labelValues ...string) Gauge
+	// Add changes the value by delta
+	Add(delta float64)
+	// Set replaces the value
+	Set(value float64)
+}
+
+// Histogram counts observations, such as latencies, in buckets
+type Histogram interface {
+	// With returns the Histogram for the given values of the next labels
+	With(labelValues ...string) Histogram
+	// Observe records a single observation
+	Observe(value float64)
+}
+
+// Opts describes a metric. The name of the metric is formed by joining the
+// non-empty Namespace, Subsystem and Name with underscores. A value must be
+// given, with With, for each of the LabelNames before the metric is updated.
+type Opts struct {
+	Namespace  string
+	Subsystem  string
+	Name       string
+	Help       string
+	LabelNames []string
+}
+
+// FullName returns the name of the metric
+func (o Opts) FullName() string {
+	var parts []string
+	for _, part := range []string{o.Namespace, o.Subsystem, o.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// HistogramOpts describes a histogram. Buckets are the upper bounds of the
+// buckets in increasing order, DefaultBuckets are used if it is empty.
+type HistogramOpts struct {
+	Opts
+	Buckets []float64
+}
+
+// DefaultBuckets are suitable for latencies in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Provider creates metrics
+type Provider interface {
+	NewCounter(opts Opts) Counter
+	NewGauge(opts Opts) Gauge
+	NewHistogram(opts HistogramOpts) Histogram
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the Registry of the metrics created by NewCounter,
+// NewGauge and NewHistogram
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// NewCounter creates a Counter in the default registry
+func NewCounter(opts Opts) Counter {
+	return defaultRegistry.NewCounter(opts)
+}
+
+// NewGauge creates a Gauge in the default registry
+func NewGauge(opts Opts) Gauge {
+	return defaultRegistry.NewGauge(opts)
+}
+
+// NewHistogram creates a Histogram in the default registry
+func NewHistogram(opts HistogramOpts) Histogram {
+	return defaultRegistry.NewHistogram(opts)
+}
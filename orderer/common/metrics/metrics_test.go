@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullName(t *testing.T) {
+	assert.Equal(t, "orderer_broadcast_enqueued_total", Opts{Namespace: "orderer", Subsystem: "broadcast", Name: "enqueued_total"}.FullName())
+	assert.Equal(t, "orderer_height", Opts{Namespace: "orderer", Name: "height"}.FullName())
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter(Opts{Name: "messages_total", LabelNames: []string{"channel", "status"}})
+	c.With("foo", "SUCCESS").Add(1)
+	c.With("foo").With("SUCCESS").Add(2)
+	c.With("bar", "BAD_REQUEST").Add(1)
+	r.NewCounter(Opts{Name: "messages_total", LabelNames: []string{"channel", "status"}}).With("bar", "BAD_REQUEST").Add(1)
+
+	g := r.NewGauge(Opts{Name: "height"})
+	g.Set(10)
+	g.Add(-2)
+
+	h := r.NewHistogram(HistogramOpts{Opts: Opts{Name: "latency_seconds"}, Buckets: []float64{1, 2}})
+	h.Observe(0.5)
+	h.Observe(1.5)
+	h.Observe(3)
+
+	families := r.Gather()
+	assert.Len(t, families, 3)
+	assert.Equal(t, "height", families[0].Name)
+	assert.Equal(t, float64(8), families[0].Series[0].Value)
+	assert.Equal(t, "latency_seconds", families[1].Name)
+	assert.Equal(t, []uint64{1, 1}, families[1].Series[0].BucketCounts)
+	assert.Equal(t, uint64(3), families[1].Series[0].Count)
+	assert.Equal(t, 5.0, families[1].Series[0].Sum)
+	assert.Equal(t, "messages_total", families[2].Name)
+	assert.Equal(t, []Series{
+		{LabelValues: []string{"bar", "BAD_REQUEST"}, Value: 2},
+		{LabelValues: []string{"foo", "SUCCESS"}, Value: 3},
+	}, families[2].Series)
+
+	assert.Panics(t, func() { c.With("foo").Add(1) }, "All labels must have values")
+	assert.Panics(t, func() { r.NewGauge(Opts{Name: "messages_total"}) }, "A name may only be used for one type of metric")
+}
+
+func TestOnGather(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge(Opts{Name: "gathers"})
+	var gathers float64
+	r.OnGather(func() {
+		gathers++
+		g.Set(gathers)
+	})
+
+	r.Gather()
+	assert.Equal(t, float64(2), r.Gather()[0].Series[0].Value)
+}
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter(Opts{Name: "unused_total"})
+	r.NewCounter(Opts{Name: "messages_total", Help: "Messages\nprocessed", LabelNames: []string{"channel"}}).With(`a"b`).Add(1)
+	r.NewHistogram(HistogramOpts{Opts: Opts{Name: "latency_seconds"}, Buckets: []float64{0.5, 1}}).Observe(0.75)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WritePrometheus(buf, r.Gather()))
+	assert.Equal(t, `# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.5"} 0
+latency_seconds_bucket{le="1"} 1
+latency_seconds_bucket{le="+Inf"} 1
+latency_seconds_sum 0.75
+latency_seconds_count 1
+# HELP messages_total Messages\nprocessed
+# TYPE messages_total counter
+messages_total{channel="a\"b"} 1
+`, buf.String())
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge(Opts{Name: "height", LabelNames: []string{"channel"}}).With("foo").Set(3)
+
+	recorder := httptest.NewRecorder()
+	PrometheusHandler(r).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, PrometheusContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "# TYPE height gauge\nheight{channel=\"foo\"} 3\n", recorder.Body.String())
+}
@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes the families in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if len(f.Series) == 0 {
+			continue
+		}
+		if f.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, helpReplacer.Replace(f.Help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Series {
+			if f.Type != HistogramType {
+				fmt.Fprintf(bw, "%s%s %s\n", f.Name, labels(f.LabelNames, s.LabelValues), formatFloat(s.Value))
+				continue
+			}
+			bucketNames := with(f.LabelNames, []string{"le"})
+			var cumulative uint64
+			for i, bound := range f.Buckets {
+				cumulative += s.BucketCounts[i]
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", f.Name, labels(bucketNames, with(s.LabelValues, []string{formatFloat(bound)})), cumulative)
+			}
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", f.Name, labels(bucketNames, with(s.LabelValues, []string{"+Inf"})), s.Count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", f.Name, labels(f.LabelNames, s.LabelValues), formatFloat(s.Sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", f.Name, labels(f.LabelNames, s.LabelValues), s.Count)
+		}
+	}
+	return bw.Flush()
+}
+
+// PrometheusHandler serves the metrics of the registry in the Prometheus text
+// exposition format
+func PrometheusHandler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		if err := WritePrometheus(w, r.Gather()); err != nil {
+			logger.Warningf("Failed to write metrics: %s", err)
+		}
+	})
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	valueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func labels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, valueReplacer.Replace(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
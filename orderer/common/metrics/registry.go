@@ -0,0 +1,244 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type is the type of a metric
+type Type string
+
+// The types of metric
+const (
+	CounterType   Type = "counter"
+	GaugeType     Type = "gauge"
+	HistogramType Type = "histogram"
+)
+
+// Family is a snapshot of a metric and the values of each of its label
+// combinations
+type Family struct {
+	Name       string
+	Help       string
+	Type       Type
+	LabelNames []string
+	// Buckets are the upper bounds of the buckets of a histogram
+	Buckets []float64
+	Series  []Series
+}
+
+// Series is a snapshot of the value of a metric for one combination of label
+// values. Value is set for counters and gauges, BucketCounts, which are not
+// cumulative, Sum and Count for histograms.
+type Series struct {
+	LabelValues  []string
+	Value        float64
+	BucketCounts []uint64
+	Sum          float64
+	Count        uint64
+}
+
+// Registry is a Provider which keeps the current value of each metric, so
+// that they may be exposed or reported. Creating a metric with the name of an
+// existing one returns a metric updating the same values.
+type Registry struct {
+	lock     sync.Mutex
+	families map[string]*family
+	hooks    []func()
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+type family struct {
+	name       string
+	help       string
+	kind       Type
+	labelNames []string
+	buckets    []float64
+
+	lock   sync.Mutex
+	series map[string]*Series
+}
+
+func (r *Registry) family(opts Opts, kind Type, buckets []float64) *family {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	name := opts.FullName()
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind || len(f.labelNames) != len(opts.LabelNames) {
+			panic(fmt.Sprintf("metric %s was already created as a %s with labels %v", name, f.kind, f.labelNames))
+		}
+		return f
+	}
+	f := &family{
+		name:       name,
+		help:       opts.Help,
+		kind:       kind,
+		labelNames: opts.LabelNames,
+		buckets:    buckets,
+		series:     make(map[string]*Series),
+	}
+	r.families[name] = f
+	return f
+}
+
+// update applies fn to the series for the label values
+func (f *family) update(labelValues []string, fn func(s *Series)) {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metric %s requires values for labels %v, got %v", f.name, f.labelNames, labelValues))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &Series{LabelValues: labelValues}
+		if f.kind == HistogramType {
+			s.BucketCounts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+func (f *family) snapshot() Family {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	snapshot := Family{
+		Name:       f.name,
+		Help:       f.help,
+		Type:       f.kind,
+		LabelNames: f.labelNames,
+		Buckets:    f.buckets,
+	}
+	for _, s := range f.series {
+		copied := *s
+		copied.BucketCounts = append([]uint64(nil), s.BucketCounts...)
+		snapshot.Series = append(snapshot.Series, copied)
+	}
+	sort.Slice(snapshot.Series, func(i, j int) bool {
+		return strings.Join(snapshot.Series[i].LabelValues, "\xff") < strings.Join(snapshot.Series[j].LabelValues, "\xff")
+	})
+	return snapshot
+}
+
+// OnGather registers fn to be called at the start of each Gather, to update
+// metrics which are computed on demand, such as the time since an event
+func (r *Registry) OnGather(fn func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+// Gather returns a snapshot of every metric, ordered by name
+func (r *Registry) Gather() []Family {
+	r.lock.Lock()
+	hooks := append([]func(){}, r.hooks...)
+	r.lock.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+
+	r.lock.Lock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.lock.Unlock()
+
+	snapshots := make([]Family, len(families))
+	for i, f := range families {
+		snapshots[i] = f.snapshot()
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	return snapshots
+}
+
+// NewCounter creates a Counter
+func (r *Registry) NewCounter(opts Opts) Counter {
+	return &counter{family: r.family(opts, CounterType, nil)}
+}
+
+// NewGauge creates a Gauge
+func (r *Registry) NewGauge(opts Opts) Gauge {
+	return &gauge{family: r.family(opts, GaugeType, nil)}
+}
+
+// NewHistogram creates a Histogram
+func (r *Registry) NewHistogram(opts HistogramOpts) Histogram {
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	return &histogram{family: r.family(opts.Opts, HistogramType, buckets)}
+}
+
+func with(labelValues, more []string) []string {
+	return append(append([]string(nil), labelValues...), more...)
+}
+
+type counter struct {
+	*family
+	labelValues []string
+}
+
+func (c *counter) With(labelValues ...string) Counter {
+	return &counter{family: c.family, labelValues: with(c.labelValues, labelValues)}
+}
+
+func (c *counter) Add(delta float64) {
+	c.update(c.labelValues, func(s *Series) { s.Value += delta })
+}
+
+type gauge struct {
+	*family
+	labelValues []string
+}
+
+func (g *gauge) With(labelValues ...string) Gauge {
+	return &gauge{family: g.family, labelValues: with(g.labelValues, labelValues)}
+}
+
+func (g *gauge) Add(delta float64) {
+	g.update(g.labelValues, func(s *Series) { s.Value += delta })
+}
+
+func (g *gauge) Set(value float64) {
+	g.update(g.labelValues, func(s *Series) { s.Value = value })
+}
+
+type histogram struct {
+	*family
+	labelValues []string
+}
+
+func (h *histogram) With(labelValues ...string) Histogram {
+	return &histogram{family: h.family, labelValues: with(h.labelValues, labelValues)}
+}
+
+func (h *histogram) Observe(value float64) {
+	h.update(h.labelValues, func(s *Series) {
+		for i, bound := range h.buckets {
+			if value <= bound {
+				s.BucketCounts[i]++
+				break
+			}
+		}
+		s.Sum += value
+		s.Count++
+	})
+}
@@ -0,0 +1,175 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPacketSize keeps each datagram within the payload of a single ethernet
+// frame, as recommended by StatsD
+const maxPacketSize = 1432
+
+// StatsD pushes the metrics of a registry to a StatsD collector. Counters are
+// sent as the increase since the previous flush, gauges as their current
+// value and histograms as counters of the increase of their _count and _sum.
+// Plain StatsD has no labels, so label values are appended to the name of
+// the metric; DogStatsD collectors receive them as tags.
+type StatsD struct {
+	registry  *Registry
+	writer    io.Writer
+	prefix    string
+	dogStatsD bool
+
+	lock sync.Mutex
+	// last holds the value of each counter at the previous flush
+	last map[string]float64
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewStatsD creates a StatsD reporter sending datagrams over UDP to address.
+// A non-empty prefix is joined to the name of each metric with a dot.
+func NewStatsD(registry *Registry, address, prefix string, dogStatsD bool) (*StatsD, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach StatsD collector at %s: %s", address, err)
+	}
+	return newStatsD(registry, conn, prefix, dogStatsD), nil
+}
+
+func newStatsD(registry *Registry, writer io.Writer, prefix string, dogStatsD bool) *StatsD {
+	return &StatsD{
+		registry:  registry,
+		writer:    writer,
+		prefix:    prefix,
+		dogStatsD: dogStatsD,
+		last:      make(map[string]float64),
+	}
+}
+
+// Start flushes the metrics every interval until Stop is called
+func (s *StatsD) Start(interval time.Duration) {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					logger.Warningf("Failed to send metrics to StatsD: %s", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic flush and sends the metrics one last time
+func (s *StatsD) Stop() {
+	close(s.stop)
+	<-s.done
+	if err := s.Flush(); err != nil {
+		logger.Warningf("Failed to send metrics to StatsD: %s", err)
+	}
+}
+
+// Flush sends the current value of every metric
+func (s *StatsD) Flush() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var lines []string
+	for _, f := range s.registry.Gather() {
+		for _, series := range f.Series {
+			name, tags := s.name(f.Name, f.LabelNames, series.LabelValues)
+			switch f.Type {
+			case CounterType:
+				lines = s.appendDelta(lines, name, tags, series.Value)
+			case GaugeType:
+				lines = append(lines, fmt.Sprintf("%s:%s|g%s", name, formatFloat(series.Value), tags))
+			case HistogramType:
+				lines = s.appendDelta(lines, name+"_count", tags, float64(series.Count))
+				lines = s.appendDelta(lines, name+"_sum", tags, series.Sum)
+			}
+		}
+	}
+	return s.send(lines)
+}
+
+func (s *StatsD) appendDelta(lines []string, name, tags string, value float64) []string {
+	key := name + tags
+	delta := value - s.last[key]
+	s.last[key] = value
+	if delta == 0 {
+		return lines
+	}
+	return append(lines, fmt.Sprintf("%s:%s|c%s", name, formatFloat(delta), tags))
+}
+
+// send writes the lines in as few datagrams as fit within maxPacketSize
+func (s *StatsD) send(lines []string) error {
+	var packet bytes.Buffer
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxPacketSize {
+			if _, err := s.writer.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() == 0 {
+		return nil
+	}
+	_, err := s.writer.Write(packet.Bytes())
+	return err
+}
+
+var (
+	// nameReplacer removes the characters of the StatsD protocol, and dots
+	// which would split a label value into levels of the hierarchy
+	nameReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", ".", "_", " ", "_", "\n", "_")
+	tagReplacer  = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", " ", "_", "\n", "_")
+)
+
+// name returns the StatsD name of the series and, for DogStatsD, its tags
+func (s *StatsD) name(name string, labelNames, labelValues []string) (string, string) {
+	if s.prefix != "" {
+		name = s.prefix + "." + name
+	}
+	if s.dogStatsD {
+		if len(labelNames) == 0 {
+			return name, ""
+		}
+		tags := make([]string, len(labelNames))
+		for i, labelName := range labelNames {
+			tags[i] = labelName + ":" + tagReplacer.Replace(labelValues[i])
+		}
+		return name, "|#" + strings.Join(tags, ",")
+	}
+	for _, value := range labelValues {
+		if value == "" {
+			value = "none"
+		}
+		name += "." + nameReplacer.Replace(value)
+	}
+	return name, ""
+}
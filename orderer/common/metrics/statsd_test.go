@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type packetRecorder struct {
+	packets []string
+}
+
+func (p *packetRecorder) Write(b []byte) (int, error) {
+	p.packets = append(p.packets, string(b))
+	return len(b), nil
+}
+
+func TestStatsDFlush(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter(Opts{Name: "messages_total", LabelNames: []string{"channel", "status"}})
+	g := r.NewGauge(Opts{Name: "height", LabelNames: []string{"channel"}})
+	h := r.NewHistogram(HistogramOpts{Opts: Opts{Name: "latency_seconds"}, Buckets: []float64{1}})
+
+	c.With("foo.bar", "SUCCESS").Add(2)
+	g.With("").Set(5)
+	h.Observe(0.5)
+
+	recorder := &packetRecorder{}
+	s := newStatsD(r, recorder, "orderer1", false)
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, []string{strings.Join([]string{
+		"orderer1.height.none:5|g",
+		"orderer1.latency_seconds_count:1|c",
+		"orderer1.latency_seconds_sum:0.5|c",
+		"orderer1.messages_total.foo_bar.SUCCESS:2|c",
+	}, "\n")}, recorder.packets)
+
+	c.With("foo.bar", "SUCCESS").Add(1)
+	recorder.packets = nil
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, []string{strings.Join([]string{
+		"orderer1.height.none:5|g",
+		"orderer1.messages_total.foo_bar.SUCCESS:1|c",
+	}, "\n")}, recorder.packets, "Counters should be sent as the increase since the last flush")
+}
+
+func TestDogStatsDTags(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter(Opts{Name: "messages_total", LabelNames: []string{"channel", "status"}}).With("foo,bar", "SUCCESS").Add(1)
+	r.NewGauge(Opts{Name: "height"}).Set(1)
+
+	recorder := &packetRecorder{}
+	assert.NoError(t, newStatsD(r, recorder, "", true).Flush())
+	assert.Equal(t, []string{"height:1|g\nmessages_total:1|c|#channel:foo_bar,status:SUCCESS"}, recorder.packets)
+}
+
+func TestStatsDPacketSize(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge(Opts{Name: "height", LabelNames: []string{"channel"}})
+	for i := 0; i < 200; i++ {
+		g.With(strings.Repeat("x", i)).Set(1)
+	}
+
+	recorder := &packetRecorder{}
+	assert.NoError(t, newStatsD(r, recorder, "", false).Flush())
+	assert.True(t, len(recorder.packets) > 1)
+	var lines int
+	for _, packet := range recorder.packets {
+		assert.True(t, len(packet) <= maxPacketSize)
+		lines += len(strings.Split(packet, "\n"))
+	}
+	assert.Equal(t, 200, lines)
+}
+
+func TestStatsDStart(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	r := NewRegistry()
+	r.NewGauge(Opts{Name: "height"}).Set(7)
+	s, err := NewStatsD(r, conn.LocalAddr().String(), "orderer", false)
+	assert.NoError(t, err)
+	s.Start(10 * time.Millisecond)
+	defer s.Stop()
+
+	buf := make([]byte, maxPacketSize)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "orderer.height:7|g", string(buf[:n]))
+}
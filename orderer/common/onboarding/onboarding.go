@@ -0,0 +1,424 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package onboarding stages the addition of a new organization to a
+// consortium, and to the application channels it joins. It computes the
+// config updates adding the organization, on the system channel and on each
+// channel, and tracks the collection of the signatures of the admins whose
+// policies govern them, until they suffice for the update to be broadcast.
+// There is a single proposal to add an organization to a channel, identified
+// as <channel ID>/<MSP ID>, which must be discarded before the organization is
+// staged again with different material. Proposals are kept in memory only, so
+// they are lost on restart, and should be discarded once submitted.
+package onboarding
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	mspconfig "github.com/hyperledger/fabric/common/config/msp"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/tools/configtxlator/update"
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/onboarding")
+
+const (
+	msgVersion = int32(0)
+	epoch      = 0
+)
+
+// ChainSupport provides the config of a chain
+type ChainSupport interface {
+	// ConfigEnvelope returns the envelope of the current config
+	ConfigEnvelope() *cb.ConfigEnvelope
+
+	// ProposeConfigUpdate applies a CONFIG_UPDATE to the current config,
+	// failing unless its signatures satisfy the modification policies
+	ProposeConfigUpdate(env *cb.Envelope) (*cb.ConfigEnvelope, error)
+}
+
+// Support provides the chains to which organizations are added
+type Support interface {
+	// GetChain gets the chain support for a given ChannelId
+	GetChain(chainID string) (ChainSupport, bool)
+
+	// SystemChannelID returns the channel ID for the system channel
+	SystemChannelID() string
+}
+
+// NotFoundError is returned for unknown proposals, channels, consortiums and
+// organizations
+type NotFoundError struct {
+	what string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.what + " not found"
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &NotFoundError{what: fmt.Sprintf(format, args...)}
+}
+
+// proposal is a config update adding an organization to a channel
+type proposal struct {
+	id           string
+	channelID    string
+	mspID        string
+	configUpdate []byte
+	signatures   []*cb.ConfigSignature
+}
+
+// Registry keeps the organizations staged and the proposals to add them
+type Registry struct {
+	support Support
+	signer  crypto.LocalSigner
+
+	lock      sync.Mutex
+	staged    map[string]*mspprotos.MSPConfig
+	proposals map[string]*proposal
+}
+
+// NewRegistry creates an empty Registry, whose config update transactions
+// are signed by signer once ready
+func NewRegistry(support Support, signer crypto.LocalSigner) *Registry {
+	return &Registry{
+		support:   support,
+		signer:    signer,
+		staged:    make(map[string]*mspprotos.MSPConfig),
+		proposals: make(map[string]*proposal),
+	}
+}
+
+// StageOrganization registers the MSP material of an organization to be added
+// to the consortium, returning the proposal to add it to the consortium on the
+// system channel
+func (r *Registry) StageOrganization(consortium string, mspConfig *mspprotos.MSPConfig) (*ab.ConfigUpdateProposal, error) {
+	mspID, err := mspIdentifier(mspConfig)
+	if err != nil {
+		return nil, err
+	}
+	chainID := r.support.SystemChannelID()
+	original, updated, err := r.config(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	consortiums := updated.ChannelGroup.Groups[config.ConsortiumsGroupKey]
+	group, ok := consortiums.GetGroups()[consortium]
+	if !ok {
+		return nil, notFound("consortium %s", consortium)
+	}
+	if _, ok := group.Groups[mspID]; ok {
+		return nil, fmt.Errorf("organization %s is already a member of consortium %s", mspID, consortium)
+	}
+	addOrganization(group, mspID, mspConfig)
+
+	p, err := newProposal(chainID, mspID, original, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	if p, ok = r.add(p); ok {
+		r.staged[mspID] = mspConfig
+	}
+	r.lock.Unlock()
+
+	logger.Infof("Staged organization %s for consortium %s in proposal %s", mspID, consortium, p.id)
+	return r.status(p), nil
+}
+
+// ProposeChannelMembership returns the proposals to add the organization to
+// each of the application channels. The organization must be staged or be a
+// member of a consortium on the system channel.
+func (r *Registry) ProposeChannelMembership(mspID string, channelIDs []string) ([]*ab.ConfigUpdateProposal, error) {
+	if len(channelIDs) == 0 {
+		return nil, fmt.Errorf("at least one channel is required")
+	}
+	mspConfig, err := r.organization(mspID)
+	if err != nil {
+		return nil, err
+	}
+
+	// All the proposals are computed before any is kept, so that a request
+	// naming an unsuitable channel has no effect
+	proposals := make([]*proposal, len(channelIDs))
+	for i, chainID := range channelIDs {
+		original, updated, err := r.config(chainID)
+		if err != nil {
+			return nil, err
+		}
+		application, ok := updated.ChannelGroup.Groups[config.ApplicationGroupKey]
+		if !ok {
+			return nil, fmt.Errorf("channel %s has no application organizations", chainID)
+		}
+		if _, ok := application.Groups[mspID]; ok {
+			return nil, fmt.Errorf("organization %s is already a member of channel %s", mspID, chainID)
+		}
+		addOrganization(application, mspID, mspConfig)
+
+		if proposals[i], err = newProposal(chainID, mspID, original, updated); err != nil {
+			return nil, err
+		}
+	}
+
+	r.lock.Lock()
+	for i, p := range proposals {
+		proposals[i], _ = r.add(p)
+	}
+	r.lock.Unlock()
+
+	result := make([]*ab.ConfigUpdateProposal, len(proposals))
+	for i, p := range proposals {
+		logger.Infof("Proposed adding organization %s to channel %s in proposal %s", mspID, p.channelID, p.id)
+		result[i] = r.status(p)
+	}
+	return result, nil
+}
+
+// AddSignatures adds signatures of the config update of the proposal,
+// replacing any earlier signature by the same signer
+func (r *Registry) AddSignatures(proposalID string, signatures []*cb.ConfigSignature) (*ab.ConfigUpdateProposal, error) {
+	creators := make([][]byte, len(signatures))
+	for i, signature := range signatures {
+		header, err := utils.GetSignatureHeader(signature.GetSignatureHeader())
+		if err != nil {
+			return nil, fmt.Errorf("malformed signature header: %s", err)
+		}
+		if len(header.Creator) == 0 || len(signature.Signature) == 0 {
+			return nil, fmt.Errorf("signature %d has no creator or no signature", i)
+		}
+		creators[i] = header.Creator
+	}
+
+	r.lock.Lock()
+	p, ok := r.proposals[proposalID]
+	if !ok {
+		r.lock.Unlock()
+		return nil, notFound("proposal %s", proposalID)
+	}
+	for i, signature := range signatures {
+		p.signatures = addSignature(p.signatures, signature, creators[i])
+	}
+	snapshot := *p
+	r.lock.Unlock()
+
+	logger.Debugf("Proposal %s has %d signatures", proposalID, len(snapshot.signatures))
+	return r.status(&snapshot), nil
+}
+
+// addSignature returns the signatures with signature added, in place of any
+// by the same creator
+func addSignature(signatures []*cb.ConfigSignature, signature *cb.ConfigSignature, creator []byte) []*cb.ConfigSignature {
+	result := make([]*cb.ConfigSignature, 0, len(signatures)+1)
+	for _, existing := range signatures {
+		// The signatures kept were validated when added
+		header, _ := utils.GetSignatureHeader(existing.SignatureHeader)
+		if !bytes.Equal(header.Creator, creator) {
+			result = append(result, existing)
+		}
+	}
+	return append(result, signature)
+}
+
+// Get returns the signature collection progress of the proposal
+func (r *Registry) Get(proposalID string) (*ab.ConfigUpdateProposal, error) {
+	r.lock.Lock()
+	p, ok := r.proposals[proposalID]
+	if !ok {
+		r.lock.Unlock()
+		return nil, notFound("proposal %s", proposalID)
+	}
+	snapshot := *p
+	r.lock.Unlock()
+	return r.status(&snapshot), nil
+}
+
+// List returns the signature collection progress of all proposals, by
+// channel
+func (r *Registry) List() []*ab.ConfigUpdateProposal {
+	r.lock.Lock()
+	snapshots := make([]proposal, 0, len(r.proposals))
+	for _, p := range r.proposals {
+		snapshots = append(snapshots, *p)
+	}
+	r.lock.Unlock()
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].channelID != snapshots[j].channelID {
+			return snapshots[i].channelID < snapshots[j].channelID
+		}
+		return snapshots[i].id < snapshots[j].id
+	})
+	result := make([]*ab.ConfigUpdateProposal, len(snapshots))
+	for i := range snapshots {
+		result[i] = r.status(&snapshots[i])
+	}
+	return result
+}
+
+// Discard forgets the proposal
+func (r *Registry) Discard(proposalID string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.proposals[proposalID]; !ok {
+		return notFound("proposal %s", proposalID)
+	}
+	delete(r.proposals, proposalID)
+	logger.Infof("Discarded proposal %s", proposalID)
+	return nil
+}
+
+// add keeps the proposal, with the lock held, unless one to add the same
+// organization to the channel is already kept, which is returned instead
+// along with the signatures it collected. It returns whether p was kept.
+func (r *Registry) add(p *proposal) (*proposal, bool) {
+	if existing, ok := r.proposals[p.id]; ok {
+		snapshot := *existing
+		return &snapshot, false
+	}
+	r.proposals[p.id] = p
+	return p, true
+}
+
+// organization returns the MSP material of the organization, staged or
+// member of a consortium on the system channel
+func (r *Registry) organization(mspID string) (*mspprotos.MSPConfig, error) {
+	r.lock.Lock()
+	mspConfig, ok := r.staged[mspID]
+	r.lock.Unlock()
+	if ok {
+		return mspConfig, nil
+	}
+
+	chain, ok := r.support.GetChain(r.support.SystemChannelID())
+	if !ok {
+		return nil, notFound("system channel")
+	}
+	consortiums := chain.ConfigEnvelope().GetConfig().GetChannelGroup().GetGroups()[config.ConsortiumsGroupKey]
+	for _, consortium := range consortiums.GetGroups() {
+		if org, ok := consortium.Groups[mspID]; ok && org.Values[mspconfig.MSPKey] != nil {
+			mspConfig := &mspprotos.MSPConfig{}
+			if err := proto.Unmarshal(org.Values[mspconfig.MSPKey].Value, mspConfig); err != nil {
+				return nil, fmt.Errorf("malformed MSP of organization %s: %s", mspID, err)
+			}
+			return mspConfig, nil
+		}
+	}
+	return nil, notFound("organization %s", mspID)
+}
+
+// config returns the current config of the chain, along with a copy to be
+// updated
+func (r *Registry) config(chainID string) (original, updated *cb.Config, err error) {
+	chain, ok := r.support.GetChain(chainID)
+	if !ok {
+		return nil, nil, notFound("channel %s", chainID)
+	}
+	original = chain.ConfigEnvelope().GetConfig()
+	if original.GetChannelGroup() == nil {
+		return nil, nil, fmt.Errorf("channel %s has no config", chainID)
+	}
+	return original, proto.Clone(original).(*cb.Config), nil
+}
+
+// status returns the signature collection progress of the proposal, which is
+// ready once the config update transaction carrying its signatures would be
+// accepted by its channel
+func (r *Registry) status(p *proposal) *ab.ConfigUpdateProposal {
+	status := &ab.ConfigUpdateProposal{
+		ProposalId:   p.id,
+		ChannelId:    p.channelID,
+		MspId:        p.mspID,
+		ConfigUpdate: p.configUpdate,
+		Signatures:   p.signatures,
+	}
+	chain, ok := r.support.GetChain(p.channelID)
+	if !ok {
+		status.Pending = fmt.Sprintf("channel %s not found", p.channelID)
+		return status
+	}
+
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, p.channelID, r.signer, &cb.ConfigUpdateEnvelope{
+		ConfigUpdate: p.configUpdate,
+		Signatures:   p.signatures,
+	}, msgVersion, epoch)
+	if err != nil {
+		status.Pending = fmt.Sprintf("failed to sign the config update transaction: %s", err)
+		return status
+	}
+	if _, err := chain.ProposeConfigUpdate(env); err != nil {
+		status.Pending = err.Error()
+		return status
+	}
+	status.Ready, status.Envelope = true, env
+	return status
+}
+
+// newProposal computes the config update from the original config of the
+// channel to the updated one, which adds the organization
+func newProposal(chainID, mspID string, original, updated *cb.Config) (*proposal, error) {
+	configUpdate, err := update.Compute(original, updated)
+	if err != nil {
+		return nil, err
+	}
+	configUpdate.ChannelId = chainID
+	return &proposal{
+		id:           chainID + "/" + mspID,
+		channelID:    chainID,
+		mspID:        mspID,
+		configUpdate: utils.MarshalOrPanic(configUpdate),
+	}, nil
+}
+
+// mspIdentifier validates the MSP material, returning its identifier
+func mspIdentifier(mspConfig *mspprotos.MSPConfig) (string, error) {
+	if mspConfig == nil {
+		return "", fmt.Errorf("an MSP config is required")
+	}
+	if mspConfig.Type != int32(msp.FABRIC) {
+		return "", fmt.Errorf("unsupported MSP type %d", mspConfig.Type)
+	}
+	mspInst, err := msp.NewBccspMsp()
+	if err != nil {
+		return "", err
+	}
+	if err := mspInst.Setup(mspConfig); err != nil {
+		return "", fmt.Errorf("invalid MSP config: %s", err)
+	}
+	return mspInst.GetIdentifier()
+}
+
+// addOrganization adds the config group of an organization with the MSP,
+// named after its MSP ID, to the parent group. Its policies are those
+// configtxgen generates, and its admins may modify it.
+func addOrganization(parent *cb.ConfigGroup, mspID string, mspConfig *mspprotos.MSPConfig) {
+	group := mspconfig.TemplateGroupMSP([]string{mspID}, mspConfig).Groups[mspID]
+	group.ModPolicy = mspconfig.AdminsPolicyKey
+	for _, value := range group.Values {
+		value.ModPolicy = mspconfig.AdminsPolicyKey
+	}
+	for _, policy := range group.Policies {
+		policy.ModPolicy = mspconfig.AdminsPolicyKey
+	}
+	// A copied group without members has no map of groups
+	if parent.Groups == nil {
+		parent.Groups = make(map[string]*cb.ConfigGroup)
+	}
+	parent.Groups[mspID] = group
+}
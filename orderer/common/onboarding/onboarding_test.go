@@ -0,0 +1,218 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package onboarding
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/config"
+	"github.com/hyperledger/fabric/common/configtx"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/common/localmsp"
+	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
+	"github.com/hyperledger/fabric/common/util"
+	coreconfig "github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/msp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := mspmgmt.LoadDevMsp(); err != nil {
+		os.Exit(-1)
+	}
+	os.Exit(m.Run())
+}
+
+const (
+	systemChainID = provisional.TestChainID
+	newOrg        = "NewOrgMSP"
+)
+
+type mockSupport map[string]ChainSupport
+
+func (ms mockSupport) GetChain(chainID string) (ChainSupport, bool) {
+	cs, ok := ms[chainID]
+	return cs, ok
+}
+
+func (ms mockSupport) SystemChannelID() string {
+	return systemChainID
+}
+
+// newTestRegistry creates a Registry for a system channel with the sample
+// consortium, an application channel of the sample organization and an
+// application channel without any organization
+func newTestRegistry(t *testing.T) (*Registry, mockSupport) {
+	genesis := provisional.New(genesisconfig.Load(genesisconfig.SampleSingleMSPSoloProfile)).GenesisBlock()
+	configTx, err := utils.ExtractEnvelope(genesis, 0)
+	require.NoError(t, err)
+	system, err := configtx.NewManagerImpl(configTx, configtx.NewInitializer(), nil)
+	require.NoError(t, err)
+
+	application := func(orgs ...string) *mockconfigtx.Manager {
+		group := cb.NewConfigGroup()
+		for _, org := range orgs {
+			group.Groups[org] = cb.NewConfigGroup()
+		}
+		channel := cb.NewConfigGroup()
+		channel.Groups[config.ApplicationGroupKey] = group
+		return &mockconfigtx.Manager{
+			ConfigEnvelopeVal:        &cb.ConfigEnvelope{Config: &cb.Config{ChannelGroup: channel}},
+			ProposeConfigUpdateError: fmt.Errorf("signatures do not satisfy policy"),
+		}
+	}
+
+	support := mockSupport{
+		systemChainID: system,
+		"app":         application(genesisconfig.SampleOrgName),
+		"empty":       application(),
+	}
+	return NewRegistry(support, localmsp.NewSigner()), support
+}
+
+func newOrgConfig(t *testing.T) *mspprotos.MSPConfig {
+	dir, err := coreconfig.GetDevMspDir()
+	require.NoError(t, err)
+	mspConfig, err := msp.GetVerifyingMspConfig(dir, newOrg)
+	require.NoError(t, err)
+	return mspConfig
+}
+
+// sign returns the signature of the config update by the local MSP, an admin
+// of the sample organization
+func sign(configUpdate []byte) *cb.ConfigSignature {
+	signer := localmsp.NewSigner()
+	sig := &cb.ConfigSignature{SignatureHeader: utils.MarshalOrPanic(utils.NewSignatureHeaderOrPanic(signer))}
+	sig.Signature = utils.SignOrPanic(signer, util.ConcatenateBytes(sig.SignatureHeader, configUpdate))
+	return sig
+}
+
+func TestStageOrganization(t *testing.T) {
+	r, support := newTestRegistry(t)
+
+	_, err := r.StageOrganization(genesisconfig.SampleConsortiumName, nil)
+	assert.Error(t, err)
+	_, err = r.StageOrganization(genesisconfig.SampleConsortiumName, &mspprotos.MSPConfig{Config: []byte("garbage")})
+	assert.Error(t, err)
+	_, err = r.StageOrganization("missing", newOrgConfig(t))
+	assert.IsType(t, &NotFoundError{}, err)
+
+	p, err := r.StageOrganization(genesisconfig.SampleConsortiumName, newOrgConfig(t))
+	require.NoError(t, err)
+	assert.Equal(t, systemChainID, p.ChannelId)
+	assert.Equal(t, newOrg, p.MspId)
+	assert.False(t, p.Ready, "An unsigned proposal should not be ready")
+	assert.NotEmpty(t, p.Pending)
+	assert.Nil(t, p.Envelope)
+
+	configUpdate, err := configtx.UnmarshalConfigUpdate(p.ConfigUpdate)
+	require.NoError(t, err)
+	assert.Equal(t, systemChainID, configUpdate.ChannelId)
+	org := configUpdate.WriteSet.Groups[config.ConsortiumsGroupKey].Groups[genesisconfig.SampleConsortiumName].Groups[newOrg]
+	require.NotNil(t, org)
+	assert.Equal(t, "Admins", org.ModPolicy)
+
+	p, err = r.AddSignatures(p.ProposalId, []*cb.ConfigSignature{sign(p.ConfigUpdate)})
+	require.NoError(t, err)
+	assert.True(t, p.Ready, "Proposal should be ready once signed by the orderer admin: %s", p.Pending)
+	assert.Empty(t, p.Pending)
+	require.NotNil(t, p.Envelope)
+	_, err = support[systemChainID].ProposeConfigUpdate(p.Envelope)
+	assert.NoError(t, err)
+
+	p, err = r.AddSignatures(p.ProposalId, []*cb.ConfigSignature{sign(p.ConfigUpdate)})
+	require.NoError(t, err)
+	assert.Len(t, p.Signatures, 1, "A signature should replace the earlier one by the same signer")
+
+	again, err := r.StageOrganization(genesisconfig.SampleConsortiumName, newOrgConfig(t))
+	require.NoError(t, err)
+	assert.Equal(t, p.ProposalId, again.ProposalId, "Staging again should return the same proposal")
+	assert.Len(t, again.Signatures, 1, "Staging again should keep the signatures collected")
+}
+
+func TestAddSignatures(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	p, err := r.StageOrganization(genesisconfig.SampleConsortiumName, newOrgConfig(t))
+	require.NoError(t, err)
+
+	_, err = r.AddSignatures("missing", []*cb.ConfigSignature{sign(p.ConfigUpdate)})
+	assert.IsType(t, &NotFoundError{}, err)
+	_, err = r.AddSignatures(p.ProposalId, []*cb.ConfigSignature{{SignatureHeader: []byte("garbage")}})
+	assert.Error(t, err)
+	_, err = r.AddSignatures(p.ProposalId, []*cb.ConfigSignature{{SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{})}})
+	assert.Error(t, err)
+
+	p, err = r.Get(p.ProposalId)
+	require.NoError(t, err)
+	assert.Empty(t, p.Signatures, "Rejected signatures should not be added")
+}
+
+func TestProposeChannelMembership(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	_, err := r.ProposeChannelMembership(newOrg, []string{"app"})
+	assert.IsType(t, &NotFoundError{}, err, "The organization should be staged first")
+	_, err = r.StageOrganization(genesisconfig.SampleConsortiumName, newOrgConfig(t))
+	require.NoError(t, err)
+
+	_, err = r.ProposeChannelMembership(newOrg, nil)
+	assert.Error(t, err)
+	_, err = r.ProposeChannelMembership(newOrg, []string{"app", "missing"})
+	assert.IsType(t, &NotFoundError{}, err)
+	_, err = r.ProposeChannelMembership(newOrg, []string{"app", systemChainID})
+	assert.EqualError(t, err, "channel "+systemChainID+" has no application organizations")
+	assert.Len(t, r.List(), 1, "A failed request should not add any proposal")
+
+	proposals, err := r.ProposeChannelMembership(newOrg, []string{"app", "empty"})
+	require.NoError(t, err)
+	require.Len(t, proposals, 2)
+	for i, chainID := range []string{"app", "empty"} {
+		assert.Equal(t, chainID, proposals[i].ChannelId)
+		assert.False(t, proposals[i].Ready)
+		assert.Equal(t, "signatures do not satisfy policy", proposals[i].Pending)
+		configUpdate, err := configtx.UnmarshalConfigUpdate(proposals[i].ConfigUpdate)
+		require.NoError(t, err)
+		assert.NotNil(t, configUpdate.WriteSet.Groups[config.ApplicationGroupKey].Groups[newOrg])
+	}
+
+	// The sample organization is not staged, but is a member of the sample
+	// consortium
+	_, err = r.ProposeChannelMembership(genesisconfig.SampleOrgName, []string{"app"})
+	assert.EqualError(t, err, "organization SampleOrg is already a member of channel app")
+	proposals, err = r.ProposeChannelMembership(genesisconfig.SampleOrgName, []string{"empty"})
+	require.NoError(t, err)
+	assert.Equal(t, genesisconfig.SampleOrgName, proposals[0].MspId)
+}
+
+func TestListAndDiscard(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	assert.Empty(t, r.List())
+
+	staged, err := r.StageOrganization(genesisconfig.SampleConsortiumName, newOrgConfig(t))
+	require.NoError(t, err)
+	proposals, err := r.ProposeChannelMembership(newOrg, []string{"app"})
+	require.NoError(t, err)
+
+	list := r.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, proposals[0].ProposalId, list[0].ProposalId, "Proposals should be listed by channel")
+	assert.Equal(t, staged.ProposalId, list[1].ProposalId)
+
+	assert.NoError(t, r.Discard(staged.ProposalId))
+	assert.IsType(t, &NotFoundError{}, r.Discard(staged.ProposalId))
+	_, err = r.Get(staged.ProposalId)
+	assert.IsType(t, &NotFoundError{}, err)
+	assert.Len(t, r.List(), 1)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/operations")
+
+// TLS contains the settings for serving the operations endpoints over TLS
+type TLS struct {
+	Enabled            bool
+	CertFile           string
+	KeyFile            string
+	ClientCertRequired bool
+	ClientRootCAs      []string
+}
+
+// Config builds the server side tls.Config described by the settings, or
+// returns nil if TLS is not enabled
+func (t TLS) Config() (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load operations TLS key pair: %s", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if !t.ClientCertRequired {
+		return tlsConfig, nil
+	}
+
+	clientRoots := x509.NewCertPool()
+	for _, clientRoot := range t.ClientRootCAs {
+		pem, err := ioutil.ReadFile(clientRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load operations ClientRootCAs file '%s' (%s)", clientRoot, err)
+		}
+		if !clientRoots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in operations ClientRootCAs file '%s'", clientRoot)
+		}
+	}
+	tlsConfig.ClientCAs = clientRoots
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
+
+// System serves the operational endpoints of the orderer, the pprof profiles
+// under /debug/pprof/ and the expvar counters under /debug/vars, on a
+// listener separate from the one used for client traffic
+type System struct {
+	lock      sync.Mutex
+	address   string
+	tlsConfig *tls.Config
+	mux       *http.ServeMux
+	server    *http.Server
+	listener  net.Listener
+}
+
+// NewSystem creates a System which will listen on the given address, over
+// TLS if tlsConfig is not nil
+func NewSystem(address string, tlsConfig *tls.Config) *System {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return &System{
+		address:   address,
+		tlsConfig: tlsConfig,
+		mux:       mux,
+		server:    &http.Server{Handler: mux},
+	}
+}
+
+// Handle registers an additional endpoint, which is served at once if the
+// system has already started
+func (s *System) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start begins serving the operations endpoints in the background
+func (s *System) Start() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %s", s.address, err)
+	}
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+	s.listener = listener
+
+	logger.Infof("Serving operations endpoints on %s", listener.Addr())
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Operations server failed: %s", err)
+		}
+	}()
+	return nil
+}
+
+// Stop closes the listener and any open connections
+func (s *System) Stop() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// Addr returns the address the operations endpoints are served on, once
+// started
+func (s *System) Addr() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listener == nil {
+		return s.address
+	}
+	return s.listener.Addr().String()
+}
@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCertificates writes a CA certificate along with a server and a client
+// certificate it issued to dir, returning the file names
+func writeCertificates(t *testing.T, dir string) (caFile, certFile, keyFile string, client tls.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	template := func(serial int64, cn string) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+	}
+	caTemplate := template(1, "ca")
+	caTemplate.IsCA = true
+	caTemplate.BasicConstraintsValid = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &key.PublicKey, key)
+	assert.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	assert.NoError(t, err)
+
+	issue := func(tmpl *x509.Certificate) []byte {
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, key)
+		assert.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	serverTemplate := template(2, "server")
+	serverTemplate.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	serverTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	clientTemplate := template(3, "client")
+	clientTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	caFile = filepath.Join(dir, "ca.pem")
+	certFile = filepath.Join(dir, "server.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	assert.NoError(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0600))
+	assert.NoError(t, ioutil.WriteFile(certFile, issue(serverTemplate), 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+
+	client, err = tls.X509KeyPair(issue(clientTemplate), keyPEM)
+	assert.NoError(t, err)
+	return caFile, certFile, keyFile, client
+}
+
+func get(client *http.Client, url string) (int, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestEndpoints(t *testing.T) {
+	s := NewSystem("127.0.0.1:0", nil)
+	s.Handle("/extra", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	for _, path := range []string{"/debug/vars", "/debug/pprof/", "/debug/pprof/cmdline", "/extra"} {
+		code, err := get(http.DefaultClient, "http://"+s.Addr()+path)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code, path)
+	}
+
+	code, err := get(http.DefaultClient, "http://"+s.Addr()+"/missing")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestStartFailure(t *testing.T) {
+	s := NewSystem("127.0.0.1:0", nil)
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	assert.Error(t, NewSystem(s.Addr(), nil).Start(), "The address should already be in use")
+	assert.NoError(t, NewSystem("127.0.0.1:0", nil).Stop(), "Stopping an unstarted system should be a no-op")
+}
+
+func TestTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "operations")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caFile, certFile, keyFile, clientCert := writeCertificates(t, dir)
+
+	tlsConfig, err := TLS{}.Config()
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig, "Disabled TLS should produce no config")
+
+	_, err = TLS{Enabled: true, CertFile: "missing", KeyFile: keyFile}.Config()
+	assert.Error(t, err)
+
+	_, err = TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientCertRequired: true, ClientRootCAs: []string{keyFile}}.Config()
+	assert.Error(t, err, "A key is not a root certificate")
+
+	tlsConfig, err = TLS{
+		Enabled:            true,
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+		ClientCertRequired: true,
+		ClientRootCAs:      []string{caFile},
+	}.Config()
+	assert.NoError(t, err)
+
+	s := NewSystem("127.0.0.1:0", tlsConfig)
+	assert.NoError(t, s.Start())
+	defer s.Stop()
+
+	caPEM, err := ioutil.ReadFile(caFile)
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+	}
+
+	_, err = get(client(), "https://"+s.Addr()+"/debug/vars")
+	assert.Error(t, err, "Clients without a certificate should be rejected")
+
+	code, err := get(client(clientCert), "https://"+s.Addr()+"/debug/vars")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package pipeline times each stage of the ordering pipeline, from the
+// receipt of a message by Broadcast to the send of the block containing it by
+// Deliver. The timers cost a few atomic operations per observation, so they
+// are always enabled, and are sampled from the operations endpoint rather than
+// by attaching a profiler to the orderer.
+package pipeline
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// The stages of the pipeline
+const (
+	// StageIngest is the time from the receipt of a message until it is
+	// filtered, including the wait for memory and for a verifier
+	StageIngest = "ingest"
+	// StageFilter is the time taken to filter a message
+	StageFilter = "filter"
+	// StageQueueWait is the time taken to hand a message to the consenter
+	StageQueueWait = "queue_wait"
+	// StageConsensus is the time from the hand off of a message until the
+	// block containing it is committed by the consenter
+	StageConsensus = "consensus"
+	// StageAppend is the time taken to append a block to the ledger
+	StageAppend = "append"
+	// StageDeliverSend is the time taken to send blocks to a Deliver client
+	StageDeliverSend = "deliver_send"
+)
+
+// Stages lists the stages of the pipeline in order
+var Stages = []string{StageIngest, StageFilter, StageQueueWait, StageConsensus, StageAppend, StageDeliverSend}
+
+type timer struct {
+	count int64
+	total int64
+	max   int64
+}
+
+// Timers accumulates the durations observed for each stage. Nil Timers, of an
+// orderer which does not time its pipeline, observe nothing.
+type Timers struct {
+	timers map[string]*timer
+}
+
+// NewTimers creates Timers for the stages of the pipeline
+func NewTimers() *Timers {
+	t := &Timers{timers: make(map[string]*timer, len(Stages))}
+	for _, stage := range Stages {
+		t.timers[stage] = &timer{}
+	}
+	return t
+}
+
+// Observe records that the stage took d, ignoring unknown stages
+func (t *Timers) Observe(stage string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	tm, ok := t.timers[stage]
+	if !ok {
+		return
+	}
+	atomic.AddInt64(&tm.count, 1)
+	atomic.AddInt64(&tm.total, int64(d))
+	for {
+		max := atomic.LoadInt64(&tm.max)
+		if int64(d) <= max || atomic.CompareAndSwapInt64(&tm.max, max, int64(d)) {
+			return
+		}
+	}
+}
+
+// Since records that the stage took the time since start
+func (t *Timers) Since(stage string, start time.Time) {
+	if t == nil {
+		return
+	}
+	t.Observe(stage, time.Since(start))
+}
+
+// Sample is the accumulated timing of a stage
+type Sample struct {
+	Stage        string  `json:"stage"`
+	Count        int64   `json:"count"`
+	TotalSeconds float64 `json:"total_seconds"`
+	MeanSeconds  float64 `json:"mean_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+}
+
+// Sample returns the timing of each stage, in the order of the pipeline, and
+// starts accumulating afresh if reset is true
+func (t *Timers) Sample(reset bool) []Sample {
+	samples := make([]Sample, 0, len(Stages))
+	for _, stage := range Stages {
+		tm := t.timers[stage]
+		var count, total, max int64
+		if reset {
+			count, total, max = atomic.SwapInt64(&tm.count, 0), atomic.SwapInt64(&tm.total, 0), atomic.SwapInt64(&tm.max, 0)
+		} else {
+			count, total, max = atomic.LoadInt64(&tm.count), atomic.LoadInt64(&tm.total), atomic.LoadInt64(&tm.max)
+		}
+		sample := Sample{
+			Stage:        stage,
+			Count:        count,
+			TotalSeconds: time.Duration(total).Seconds(),
+			MaxSeconds:   time.Duration(max).Seconds(),
+		}
+		if count > 0 {
+			sample.MeanSeconds = sample.TotalSeconds / float64(count)
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// Handler serves the samples of the Timers as JSON, resetting them if the
+// reset query parameter is true
+func Handler(t *Timers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reset := r.URL.Query().Get("reset") == "true"
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(t.Sample(reset)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pipeline
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserve(t *testing.T) {
+	timers := NewTimers()
+	timers.Observe(StageAppend, time.Second)
+	timers.Observe(StageAppend, 3*time.Second)
+	timers.Observe("unknown", time.Second)
+
+	samples := timers.Sample(false)
+	require.Len(t, samples, len(Stages))
+	for i, sample := range samples {
+		assert.Equal(t, Stages[i], sample.Stage)
+		if sample.Stage != StageAppend {
+			assert.Equal(t, Sample{Stage: sample.Stage}, sample)
+		}
+	}
+	assert.Equal(t, Sample{Stage: StageAppend, Count: 2, TotalSeconds: 4, MeanSeconds: 2, MaxSeconds: 3}, samples[4])
+
+	assert.Equal(t, samples, timers.Sample(true), "Sampling should not change the timers unless reset")
+	assert.Equal(t, Sample{Stage: StageAppend}, timers.Sample(false)[4], "The timers should be reset")
+}
+
+func TestHandler(t *testing.T) {
+	timers := NewTimers()
+	timers.Observe(StageFilter, time.Millisecond)
+
+	var samples []Sample
+	w := httptest.NewRecorder()
+	Handler(timers).ServeHTTP(w, httptest.NewRequest("GET", "/debug/stages", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &samples))
+	assert.Equal(t, int64(1), samples[1].Count)
+
+	w = httptest.NewRecorder()
+	Handler(timers).ServeHTTP(w, httptest.NewRequest("GET", "/debug/stages?reset=true", nil))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &samples))
+	assert.Equal(t, int64(1), samples[1].Count)
+	assert.Equal(t, int64(0), timers.Sample(false)[1].Count)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package pool reuses the buffers which the broadcast and deliver paths would
+// otherwise allocate to encode and decode every message, reducing the garbage
+// produced at high ingest rates. Decoded messages and responses are not
+// pooled, since they are handed to the consenters and to gRPC, which may
+// reference them after they are sent.
+package pool
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// MaxBufferSize is the capacity beyond which an encoding buffer is not
+// returned to the pool, so that an occasional large message does not keep its
+// buffer alive indefinitely
+const MaxBufferSize = 1024 * 1024
+
+var (
+	// encoders hold buffers whose storage is reused across messages
+	encoders = sync.Pool{New: func() interface{} { return proto.NewBuffer(nil) }}
+	// decoders only read the slices they are given, and never own storage
+	decoders = sync.Pool{New: func() interface{} { return proto.NewBuffer(nil) }}
+)
+
+// GetBuffer returns an empty buffer to encode messages into, which should be
+// returned with PutBuffer once its contents are no longer referenced
+func GetBuffer() *proto.Buffer {
+	return encoders.Get().(*proto.Buffer)
+}
+
+// PutBuffer returns a buffer obtained with GetBuffer to the pool
+func PutBuffer(buf *proto.Buffer) {
+	if cap(buf.Bytes()) > MaxBufferSize {
+		return
+	}
+	buf.Reset()
+	encoders.Put(buf)
+}
+
+// Unmarshal decodes the data into the message, as proto.Unmarshal does,
+// without allocating a decoding buffer. Byte fields of the message are copied
+// rather than aliasing the data.
+func Unmarshal(data []byte, msg proto.Message) error {
+	msg.Reset()
+	if u, ok := msg.(proto.Unmarshaler); ok {
+		return u.Unmarshal(data)
+	}
+	buf := decoders.Get().(*proto.Buffer)
+	buf.SetBuf(data)
+	err := buf.Unmarshal(msg)
+	buf.SetBuf(nil)
+	decoders.Put(buf)
+	return err
+}
+
+// Hash returns the SHA256 hash of the encoded message, encoding it into a
+// pooled buffer rather than allocating one for the encoding
+func Hash(msg proto.Message) ([]byte, error) {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	if err := buf.Marshal(msg); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:], nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pool
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnvelope is about the size of a typical endorser transaction
+var testEnvelope = &cb.Envelope{
+	Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(
+			utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0),
+			&cb.SignatureHeader{Creator: make([]byte, 800), Nonce: make([]byte, 24)},
+		),
+		Data: make([]byte, 2048),
+	}),
+	Signature: make([]byte, 72),
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := utils.MarshalOrPanic(testEnvelope)
+	env := &cb.Envelope{Payload: []byte("stale")}
+	require.NoError(t, Unmarshal(data, env))
+	assert.True(t, proto.Equal(testEnvelope, env))
+
+	data[len(data)-1] ^= 0xff
+	assert.Equal(t, testEnvelope.Signature, env.Signature, "The message should not alias the data")
+
+	assert.Error(t, Unmarshal([]byte("garbage"), env))
+}
+
+func TestHash(t *testing.T) {
+	hash, err := Hash(testEnvelope)
+	require.NoError(t, err)
+	expected := sha256.Sum256(utils.MarshalOrPanic(testEnvelope))
+	assert.Equal(t, expected[:], hash)
+
+	hash, err = Hash(&cb.Envelope{})
+	require.NoError(t, err)
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, empty[:], hash)
+}
+
+func TestPutBuffer(t *testing.T) {
+	buf := GetBuffer()
+	require.NoError(t, buf.Marshal(testEnvelope))
+	PutBuffer(buf)
+	assert.Empty(t, buf.Bytes(), "Buffers should be reset when they are returned")
+}
+
+// The benchmarks compare the pooled functions with their unpooled
+// equivalents, in parallel as broadcast streams call them; run with
+// -benchmem to compare the allocations per message
+
+func BenchmarkHash(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Hash(testEnvelope)
+		}
+	})
+}
+
+func BenchmarkHashUnpooled(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			sha256.Sum256(utils.MarshalOrPanic(testEnvelope))
+		}
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data := testEnvelope.Payload
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Unmarshal(data, &cb.Payload{})
+		}
+	})
+}
+
+func BenchmarkUnmarshalUnpooled(b *testing.B) {
+	data := testEnvelope.Payload
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			proto.Unmarshal(data, &cb.Payload{})
+		}
+	})
+}
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package priority admits messages at the priority class they request in the
+// orderer extension of their channel header, according to the entitlements of
+// their creators. Broadcast verifies and enqueues urgent messages, those of
+// the HIGH and CONTROL classes, ahead of the others, and the block cutter
+// orders them ahead of the pending messages and cuts them without waiting for
+// the batch to fill, so that control-plane transactions keep their latency
+// while the orderer is under load.
+//
+// Entitlements are local configuration, so they are only enforced when a
+// message is received. Once a message is admitted for ordering, the class it
+// requested is honored by every orderer alike.
+package priority
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/priority")
+
+// Config holds the entitlements of the creators of messages
+type Config struct {
+	// Entitlements are the highest classes the creators of each MSP may
+	// request, by MSP ID
+	Entitlements map[string]ab.PriorityClass
+	// DefaultEntitlement is the highest class the creators of the MSPs not
+	// in Entitlements may request
+	DefaultEntitlement ab.PriorityClass
+}
+
+// ParseClass returns the class with the given name
+func ParseClass(name string) (ab.PriorityClass, error) {
+	class, ok := ab.PriorityClass_value[name]
+	if !ok {
+		return ab.PriorityClass_NORMAL, fmt.Errorf("unknown priority class %s", name)
+	}
+	return ab.PriorityClass(class), nil
+}
+
+// Rank returns the rank of the class, the messages of a higher rank being
+// ordered ahead of those of a lower rank, or -1 if the class is unknown
+func Rank(class ab.PriorityClass) int {
+	switch class {
+	case ab.PriorityClass_LOW:
+		return 0
+	case ab.PriorityClass_NORMAL:
+		return 1
+	case ab.PriorityClass_HIGH:
+		return 2
+	case ab.PriorityClass_CONTROL:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// Ranks is the number of ranks of the known classes
+const Ranks = 4
+
+// Urgent returns whether the messages of the class are ordered without
+// waiting for their batch to fill
+func Urgent(class ab.PriorityClass) bool {
+	return Rank(class) >= Rank(ab.PriorityClass_HIGH)
+}
+
+// Requested returns the class the message requests in its orderer extension
+func Requested(msg *filter.Message) ab.PriorityClass {
+	return msg.Extension.GetPriority()
+}
+
+// Of returns the class of the message. Config messages are control-plane
+// transactions, which are authorized by the policies of their channel rather
+// than by entitlements, and so are always CONTROL.
+func Of(msg *filter.Message) ab.PriorityClass {
+	if isConfig(msg) {
+		return ab.PriorityClass_CONTROL
+	}
+	return Requested(msg)
+}
+
+func isConfig(msg *filter.Message) bool {
+	switch cb.HeaderType(msg.ChannelHeader.GetType()) {
+	case cb.HeaderType_CONFIG, cb.HeaderType_CONFIG_UPDATE, cb.HeaderType_ORDERER_TRANSACTION:
+		return true
+	}
+	return false
+}
+
+// Entitlement returns the highest class the creator may request
+func (c Config) Entitlement(creator []byte) ab.PriorityClass {
+	if len(c.Entitlements) > 0 {
+		sid := &mspproto.SerializedIdentity{}
+		if err := pool.Unmarshal(creator, sid); err == nil {
+			if class, ok := c.Entitlements[sid.Mspid]; ok {
+				return class
+			}
+		}
+	}
+	return c.DefaultEntitlement
+}
+
+// Admitted returns the class the message is admitted at, and false if it
+// requests a class beyond the entitlement of its creator, or one which is
+// unknown
+func (c Config) Admitted(msg *filter.Message) (ab.PriorityClass, bool) {
+	requested := Of(msg)
+	if isConfig(msg) {
+		return requested, true
+	}
+	if Rank(requested) < 0 || Rank(requested) > Rank(c.Entitlement(msg.SignatureHeader.Creator)) {
+		return requested, false
+	}
+	return requested, true
+}
+
+// Rule rejects messages which request a class beyond the entitlement of their
+// creator. Entitlements are only checked when a message is received, as they
+// may differ between orderers.
+func Rule(config Config) filter.OrderingRule {
+	return &rule{config: config}
+}
+
+type rule struct {
+	config Config
+}
+
+func (r *rule) Apply(msg *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := r.Explain(msg)
+	return action, committer
+}
+
+func (r *rule) Explain(msg *filter.Message) (filter.Action, filter.Committer, string) {
+	if class, ok := r.config.Admitted(msg); !ok {
+		logger.Warningf("Rejecting message of channel %s requesting priority class %s beyond the entitlement of its creator", msg.ChannelHeader.ChannelId, class)
+		return filter.Reject, nil, fmt.Sprintf("priority class %s is beyond the entitlement of the creator", class)
+	}
+	return filter.Forward, nil, ""
+}
+
+func (r *rule) ApplyOrdering(msg *filter.Message) (filter.Action, filter.Committer) {
+	return filter.Forward, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package priority
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeMessage(t *testing.T, headerType cb.HeaderType, mspID string, class ab.PriorityClass) *filter.Message {
+	chdr := utils.MakeChannelHeader(headerType, 0, "foo", 0)
+	chdr.OrdererExtension = utils.MarshalOrPanic(&ab.OrdererHeaderExtension{Priority: class})
+	creator := utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: mspID, IdBytes: []byte("cert")})
+	msg, err := filter.NewMessage(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{Creator: creator}),
+	})})
+	require.NoError(t, err)
+	return msg
+}
+
+func TestParseClass(t *testing.T) {
+	class, err := ParseClass("CONTROL")
+	assert.NoError(t, err)
+	assert.Equal(t, ab.PriorityClass_CONTROL, class)
+
+	_, err = ParseClass("URGENT")
+	assert.Error(t, err)
+}
+
+func TestRank(t *testing.T) {
+	assert.True(t, Rank(ab.PriorityClass_LOW) < Rank(ab.PriorityClass_NORMAL))
+	assert.True(t, Rank(ab.PriorityClass_NORMAL) < Rank(ab.PriorityClass_HIGH))
+	assert.True(t, Rank(ab.PriorityClass_HIGH) < Rank(ab.PriorityClass_CONTROL))
+	assert.Equal(t, Ranks-1, Rank(ab.PriorityClass_CONTROL))
+	assert.Equal(t, -1, Rank(ab.PriorityClass(42)))
+
+	assert.False(t, Urgent(ab.PriorityClass_NORMAL))
+	assert.True(t, Urgent(ab.PriorityClass_HIGH))
+	assert.True(t, Urgent(ab.PriorityClass_CONTROL))
+}
+
+func TestAdmitted(t *testing.T) {
+	config := Config{Entitlements: map[string]ab.PriorityClass{"ops": ab.PriorityClass_CONTROL, "app": ab.PriorityClass_HIGH}}
+
+	for _, tc := range []struct {
+		name       string
+		headerType cb.HeaderType
+		mspID      string
+		requested  ab.PriorityClass
+		admitted   bool
+	}{
+		{"normal by default", cb.HeaderType_ENDORSER_TRANSACTION, "other", ab.PriorityClass_NORMAL, true},
+		{"low by default", cb.HeaderType_ENDORSER_TRANSACTION, "other", ab.PriorityClass_LOW, true},
+		{"high by default", cb.HeaderType_ENDORSER_TRANSACTION, "other", ab.PriorityClass_HIGH, false},
+		{"high when entitled", cb.HeaderType_ENDORSER_TRANSACTION, "app", ab.PriorityClass_HIGH, true},
+		{"control beyond entitlement", cb.HeaderType_ENDORSER_TRANSACTION, "app", ab.PriorityClass_CONTROL, false},
+		{"control when entitled", cb.HeaderType_ENDORSER_TRANSACTION, "ops", ab.PriorityClass_CONTROL, true},
+		{"unknown class", cb.HeaderType_ENDORSER_TRANSACTION, "ops", ab.PriorityClass(42), false},
+		{"config update", cb.HeaderType_CONFIG_UPDATE, "other", ab.PriorityClass_NORMAL, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, admitted := config.Admitted(makeMessage(t, tc.headerType, tc.mspID, tc.requested))
+			assert.Equal(t, tc.admitted, admitted)
+		})
+	}
+
+	configUpdate := makeMessage(t, cb.HeaderType_CONFIG_UPDATE, "other", ab.PriorityClass_LOW)
+	class, _ := config.Admitted(configUpdate)
+	assert.Equal(t, ab.PriorityClass_CONTROL, class, "Config messages should always be control-plane transactions")
+	assert.Equal(t, ab.PriorityClass_CONTROL, Of(configUpdate))
+	assert.Equal(t, ab.PriorityClass_LOW, Requested(configUpdate))
+}
+
+func TestEntitlementMalformedCreator(t *testing.T) {
+	config := Config{
+		Entitlements:       map[string]ab.PriorityClass{"ops": ab.PriorityClass_CONTROL},
+		DefaultEntitlement: ab.PriorityClass_LOW,
+	}
+	assert.Equal(t, ab.PriorityClass_LOW, config.Entitlement([]byte("garbage")))
+}
+
+func TestRule(t *testing.T) {
+	r := Rule(Config{DefaultEntitlement: ab.PriorityClass_NORMAL})
+
+	action, _ := r.Apply(makeMessage(t, cb.HeaderType_ENDORSER_TRANSACTION, "app", ab.PriorityClass_NORMAL))
+	assert.EqualValues(t, filter.Forward, action)
+
+	urgent := makeMessage(t, cb.HeaderType_ENDORSER_TRANSACTION, "app", ab.PriorityClass_HIGH)
+	action, _ = r.Apply(urgent)
+	assert.EqualValues(t, filter.Reject, action)
+	action, _ = r.ApplyOrdering(urgent)
+	assert.EqualValues(t, filter.Forward, action, "Entitlements should not be enforced once a message is ordered")
+}
@@ -0,0 +1,277 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package receipts keeps the signed acknowledgments of the messages which the
+// orderer accepted for ordering, so that clients which lost the response to a
+// broadcast, such as when they or the orderer crashed, may ask whether it was
+// accepted rather than submitting it again blindly. A message submitted again
+// within the dedup window of its acceptance is answered with its receipt
+// instead of being ordered twice.
+package receipts
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/receipts")
+
+// Config is the configuration of a Log
+type Config struct {
+	// File is the path of the file the receipts are persisted to, which
+	// keeps them in memory only if empty
+	File string
+	// Size is the number of the most recent receipts kept
+	Size int
+	// Window is how long after its acceptance a message submitted again is
+	// answered with its receipt instead of being ordered again
+	Window time.Duration
+}
+
+// Log is a bounded log of receipts, by the hash of the message as it was
+// received. A nil Log keeps nothing.
+type Log struct {
+	config Config
+	now    func() time.Time
+
+	lock     sync.Mutex
+	receipts map[string]*ab.BroadcastResponse
+	order    []string
+	file     *os.File
+	// written is the number of receipts in the file, which is rewritten
+	// with only those kept once it holds twice as many
+	written int
+}
+
+// Open opens the Log, reading the receipts persisted to its file, if any
+func Open(config Config) (*Log, error) {
+	l := &Log{
+		config:   config,
+		now:      time.Now,
+		receipts: make(map[string]*ab.BroadcastResponse),
+	}
+	if config.File == "" {
+		return l, nil
+	}
+
+	if err := l.read(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := l.rewrite(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// read adds the receipts persisted to the file. A malformed line, which is
+// typically a receipt whose write was interrupted by a crash, is skipped.
+func (l *Log) read() error {
+	file, err := os.Open(l.config.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		resp, txHash, err := decode(scanner.Bytes())
+		if err != nil {
+			logger.Warningf("Skipping malformed receipt on line %d of %s: %s", line, l.config.File, err)
+			continue
+		}
+		l.add(txHash, resp)
+	}
+	return scanner.Err()
+}
+
+// rewrite replaces the file with one holding only the receipts kept, and
+// opens it for appending
+func (l *Log) rewrite() error {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+
+	tmp := l.config.File + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	for _, key := range l.order {
+		data, err := encode(l.receipts[key])
+		if err != nil {
+			file.Close()
+			return err
+		}
+		w.Write(data)
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	file.Close()
+	if err := os.Rename(tmp, l.config.File); err != nil {
+		return err
+	}
+
+	l.file, err = os.OpenFile(l.config.File, os.O_WRONLY|os.O_APPEND, 0600)
+	l.written = len(l.order)
+	return err
+}
+
+func encode(resp *ab.BroadcastResponse) ([]byte, error) {
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	line := make([]byte, base64.StdEncoding.EncodedLen(len(data))+1)
+	base64.StdEncoding.Encode(line, data)
+	line[len(line)-1] = '\n'
+	return line, nil
+}
+
+func decode(line []byte) (*ab.BroadcastResponse, []byte, error) {
+	data, err := base64.StdEncoding.DecodeString(string(line))
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := &ab.BroadcastResponse{}
+	if err := proto.Unmarshal(data, resp); err != nil {
+		return nil, nil, err
+	}
+	ack, err := Acknowledgment(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, ack.TxHash, nil
+}
+
+// Acknowledgment returns the acknowledgment signed in the receipt
+func Acknowledgment(resp *ab.BroadcastResponse) (*ab.BroadcastAcknowledgment, error) {
+	ack := &ab.BroadcastAcknowledgment{}
+	if err := proto.Unmarshal(resp.Acknowledgment, ack); err != nil {
+		return nil, fmt.Errorf("malformed acknowledgment: %s", err)
+	}
+	if len(ack.TxHash) == 0 {
+		return nil, fmt.Errorf("acknowledgment has no transaction hash")
+	}
+	return ack, nil
+}
+
+// add keeps the receipt, forgetting the oldest beyond Size, with the lock
+// held. It returns false if the receipt is not kept.
+func (l *Log) add(txHash []byte, resp *ab.BroadcastResponse) bool {
+	if l.config.Size <= 0 {
+		return false
+	}
+	key := string(txHash)
+	if _, ok := l.receipts[key]; !ok {
+		l.order = append(l.order, key)
+	}
+	l.receipts[key] = resp
+	for len(l.order) > l.config.Size {
+		delete(l.receipts, l.order[0])
+		l.order[0] = ""
+		l.order = l.order[1:]
+	}
+	return true
+}
+
+// Record keeps the successful response to a message as its receipt, returning
+// once it is persisted. Responses without a signed acknowledgment, which the
+// orderer sends if it cannot sign them, are not receipts and are ignored.
+func (l *Log) Record(resp *ab.BroadcastResponse) error {
+	if l == nil || resp.Status != cb.Status_SUCCESS || len(resp.Acknowledgment) == 0 {
+		return nil
+	}
+	ack, err := Acknowledgment(resp)
+	if err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !l.add(ack.TxHash, resp) || l.file == nil {
+		return nil
+	}
+	if l.written >= 2*l.config.Size {
+		return l.rewrite()
+	}
+	data, err := encode(resp)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(data); err != nil {
+		return err
+	}
+	l.written++
+	return l.file.Sync()
+}
+
+// Lookup returns the receipt of the message with the hash, or nil if it is
+// not known to have been accepted
+func (l *Log) Lookup(txHash []byte) *ab.BroadcastResponse {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.receipts[string(txHash)]
+}
+
+// Duplicate returns the receipt of the message with the hash if it was
+// accepted within the dedup window, or nil if it should be ordered
+func (l *Log) Duplicate(txHash []byte) *ab.BroadcastResponse {
+	if l == nil || l.config.Window <= 0 {
+		return nil
+	}
+	resp := l.Lookup(txHash)
+	if resp == nil {
+		return nil
+	}
+	ack, err := Acknowledgment(resp)
+	if err != nil || ack.Timestamp == nil {
+		return nil
+	}
+	accepted := time.Unix(ack.Timestamp.Seconds, int64(ack.Timestamp.Nanos))
+	if l.now().Sub(accepted) > l.config.Window {
+		return nil
+	}
+	return resp
+}
+
+// Close closes the file of the Log
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package receipts
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var accepted = time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func makeReceipt(txHash string, status cb.Status) *ab.BroadcastResponse {
+	return &ab.BroadcastResponse{
+		Status: status,
+		Acknowledgment: utils.MarshalOrPanic(&ab.BroadcastAcknowledgment{
+			Status:    status,
+			ChannelId: "foo",
+			TxHash:    []byte(txHash),
+			Timestamp: &timestamp.Timestamp{Seconds: accepted.Unix()},
+		}),
+		SignatureHeader: []byte("header"),
+		Signature:       []byte("signature"),
+	}
+}
+
+func TestLog(t *testing.T) {
+	l, err := Open(Config{Size: 2})
+	require.NoError(t, err)
+
+	for _, txHash := range []string{"a", "b", "c"} {
+		assert.NoError(t, l.Record(makeReceipt(txHash, cb.Status_SUCCESS)))
+	}
+	assert.Nil(t, l.Lookup([]byte("a")), "The oldest receipts should be forgotten")
+	assert.True(t, proto.Equal(makeReceipt("c", cb.Status_SUCCESS), l.Lookup([]byte("c"))))
+
+	assert.NoError(t, l.Record(makeReceipt("d", cb.Status_SERVICE_UNAVAILABLE)))
+	assert.Nil(t, l.Lookup([]byte("d")), "Rejections should not be kept")
+	assert.NoError(t, l.Record(&ab.BroadcastResponse{Status: cb.Status_SUCCESS}))
+	assert.Error(t, l.Record(&ab.BroadcastResponse{Status: cb.Status_SUCCESS, Acknowledgment: []byte("garbage")}))
+}
+
+func TestDuplicate(t *testing.T) {
+	l, err := Open(Config{Size: 10, Window: time.Minute})
+	require.NoError(t, err)
+	l.now = func() time.Time { return accepted.Add(30 * time.Second) }
+
+	assert.NoError(t, l.Record(makeReceipt("a", cb.Status_SUCCESS)))
+	assert.NotNil(t, l.Duplicate([]byte("a")))
+	assert.Nil(t, l.Duplicate([]byte("b")))
+
+	l.now = func() time.Time { return accepted.Add(2 * time.Minute) }
+	assert.Nil(t, l.Duplicate([]byte("a")), "Messages should be ordered again once the window has passed")
+	assert.NotNil(t, l.Lookup([]byte("a")), "Receipts should be kept beyond the window")
+
+	l.config.Window = 0
+	l.now = time.Now
+	assert.Nil(t, l.Duplicate([]byte("a")), "A zero window should disable deduplication")
+}
+
+func TestPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "receipts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "receipts")
+
+	l, err := Open(Config{File: path, Size: 2})
+	require.NoError(t, err)
+	for _, txHash := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, l.Record(makeReceipt(txHash, cb.Status_SUCCESS)))
+	}
+	assert.Equal(t, 2, l.written, "The file should have been rewritten with only the receipts kept")
+	assert.NoError(t, l.Close())
+
+	// A receipt interrupted by a crash is skipped
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("Zm9v"))
+	require.NoError(t, err)
+	file.Close()
+
+	l, err = Open(Config{File: path, Size: 2})
+	require.NoError(t, err)
+	defer l.Close()
+	assert.Nil(t, l.Lookup([]byte("c")))
+	assert.NotNil(t, l.Lookup([]byte("d")))
+	assert.NotNil(t, l.Lookup([]byte("e")))
+	assert.Equal(t, 2, l.written)
+}
+
+func TestNilLog(t *testing.T) {
+	var l *Log
+	assert.NoError(t, l.Record(makeReceipt("a", cb.Status_SUCCESS)))
+	assert.Nil(t, l.Lookup([]byte("a")))
+	assert.Nil(t, l.Duplicate([]byte("a")))
+	assert.NoError(t, l.Close())
+}
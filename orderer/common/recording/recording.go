@@ -0,0 +1,164 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package recording records the messages enqueued on a chain, along with the
+// time each was enqueued, and replays them through the block cutter of a
+// chain as the solo consenter would, with time advancing by the recorded
+// timestamps rather than the clock, so that the blocks cut from a recording
+// are the same from one run, and one version of the orderer, to the next.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/recording")
+
+// Record is a message enqueued on a chain
+type Record struct {
+	Time time.Time `json:"time"`
+	// Envelope is the message as it was received
+	Envelope []byte `json:"envelope"`
+}
+
+// Recorder appends the messages enqueued on a chain to a file, one JSON
+// encoded Record per line
+type Recorder struct {
+	lock sync.Mutex
+	w    io.WriteCloser
+	enc  *json.Encoder
+}
+
+// NewRecorder creates a Recorder which writes to w
+func NewRecorder(w io.WriteCloser) *Recorder {
+	return &Recorder{w: w, enc: json.NewEncoder(w)}
+}
+
+// Record records the envelope as enqueued at the given time
+func (r *Recorder) Record(env *cb.Envelope, enqueued time.Time) error {
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.enc.Encode(&Record{Time: enqueued, Envelope: data})
+}
+
+// Close closes the underlying writer
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.w.Close()
+}
+
+// Read reads the records written by a Recorder
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	for scanner.Scan() {
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("malformed record %d: %s", len(records), err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// Config names the chains whose messages are recorded, to the file named
+// after each chain in Directory
+type Config struct {
+	Directory string
+	Chains    []string
+}
+
+// Open returns a Recorder appending to the recording of the chain if the
+// chain is recorded, as configured, and nil otherwise
+func Open(config Config, chainID string) (*Recorder, error) {
+	for _, recorded := range config.Chains {
+		if recorded != chainID {
+			continue
+		}
+		path := filepath.Join(config.Directory, chainID+".rec")
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("could not open recording of chain %s: %s", chainID, err)
+		}
+		logger.Infof("Recording the messages of chain %s to %s", chainID, path)
+		return NewRecorder(f), nil
+	}
+	return nil, nil
+}
+
+// Chain is the part of a chain through which records are replayed
+type Chain interface {
+	BlockCutter() blockcutter.Receiver
+	SharedConfig() config.Orderer
+	CreateNextBlock(messages []*cb.Envelope) *cb.Block
+	WriteBlock(block *cb.Block, committers []filter.Committer, encodedMetadataValue []byte) *cb.Block
+}
+
+// Replay orders the records on the chain as the solo consenter would, cutting
+// a batch once the batch timeout has passed since its first message as of the
+// time of the next record, and cutting the last pending batch once the
+// records run out. It returns the blocks written.
+func Replay(records []Record, chain Chain) ([]*cb.Block, error) {
+	var blocks []*cb.Block
+	var deadline time.Time
+	for i, record := range records {
+		if !deadline.IsZero() && !record.Time.Before(deadline) {
+			deadline = time.Time{}
+			blocks = cut(chain, blocks)
+		}
+
+		env := &cb.Envelope{}
+		if err := proto.Unmarshal(record.Envelope, env); err != nil {
+			return blocks, fmt.Errorf("malformed envelope in record %d: %s", i, err)
+		}
+		msg, err := filter.NewMessage(env)
+		if err != nil {
+			return blocks, fmt.Errorf("malformed envelope in record %d: %s", i, err)
+		}
+
+		batches, committers, ok, _ := chain.BlockCutter().Ordered(msg)
+		if ok && len(batches) == 0 && deadline.IsZero() {
+			deadline = record.Time.Add(chain.SharedConfig().BatchTimeout())
+			continue
+		}
+		for j, batch := range batches {
+			blocks = append(blocks, chain.WriteBlock(chain.CreateNextBlock(batch), committers[j], nil))
+		}
+		if len(batches) > 0 {
+			deadline = time.Time{}
+		}
+	}
+	return cut(chain, blocks), nil
+}
+
+// cut writes the pending batch of the chain, if any, as the batch timer does
+func cut(chain Chain, blocks []*cb.Block) []*cb.Block {
+	batch, committers := chain.BlockCutter().Cut()
+	if len(batch) == 0 {
+		return blocks
+	}
+	return append(blocks, chain.WriteBlock(chain.CreateNextBlock(batch), committers, nil))
+}
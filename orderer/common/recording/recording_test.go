@@ -0,0 +1,174 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recording
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/config"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func makeEnvelope(i int) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(
+			utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0),
+			&cb.SignatureHeader{},
+		),
+		Data: []byte(fmt.Sprintf("%d", i)),
+	})}
+}
+
+func TestRecordAndRead(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := NewRecorder(nopCloser{buf})
+	start := time.Unix(1500000000, 0).UTC()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, recorder.Record(makeEnvelope(i), start.Add(time.Duration(i)*time.Second)))
+	}
+	require.NoError(t, recorder.Close())
+
+	records, err := Read(buf)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for i, record := range records {
+		assert.True(t, start.Add(time.Duration(i)*time.Second).Equal(record.Time))
+		assert.Equal(t, utils.MarshalOrPanic(makeEnvelope(i)), record.Envelope)
+	}
+
+	_, err = Read(bytes.NewBufferString("garbage\n"))
+	assert.Error(t, err)
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	recorder, err := Open(Config{}, "foo")
+	assert.NoError(t, err)
+	assert.Nil(t, recorder, "Chains should not be recorded by default")
+
+	config := Config{Directory: dir, Chains: []string{"foo"}}
+	recorder, err = Open(config, "bar")
+	assert.NoError(t, err)
+	assert.Nil(t, recorder, "Only the configured chains should be recorded")
+
+	for i := 0; i < 2; i++ {
+		recorder, err = Open(config, "foo")
+		require.NoError(t, err)
+		require.NotNil(t, recorder)
+		require.NoError(t, recorder.Record(makeEnvelope(i), time.Now()))
+		require.NoError(t, recorder.Close())
+	}
+	f, err := os.Open(filepath.Join(dir, "foo.rec"))
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := Read(f)
+	require.NoError(t, err)
+	assert.Len(t, records, 2, "The recording should be appended to")
+
+	_, err = Open(Config{Directory: filepath.Join(dir, "missing"), Chains: []string{"foo"}}, "foo")
+	assert.Error(t, err)
+}
+
+// batchChain records the batches cut through a real block cutter
+type batchChain struct {
+	cutter  blockcutter.Receiver
+	config  *mockconfig.Orderer
+	batches [][]*cb.Envelope
+}
+
+func newBatchChain() *batchChain {
+	config := &mockconfig.Orderer{
+		BatchSizeVal:    &ab.BatchSize{MaxMessageCount: 3, AbsoluteMaxBytes: 1000, PreferredMaxBytes: 1000},
+		BatchTimeoutVal: time.Second,
+	}
+	return &batchChain{
+		cutter: blockcutter.NewReceiverImpl("foo", config, filter.NewRuleSet([]filter.Rule{filter.AcceptRule}), nil, nil, nil, nil),
+		config: config,
+	}
+}
+
+func (bc *batchChain) BlockCutter() blockcutter.Receiver {
+	return bc.cutter
+}
+
+func (bc *batchChain) SharedConfig() config.Orderer {
+	return bc.config
+}
+
+func (bc *batchChain) CreateNextBlock(messages []*cb.Envelope) *cb.Block {
+	bc.batches = append(bc.batches, messages)
+	block := cb.NewBlock(uint64(len(bc.batches)), nil)
+	for _, msg := range messages {
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(msg))
+	}
+	return block
+}
+
+func (bc *batchChain) WriteBlock(block *cb.Block, committers []filter.Committer, encodedMetadataValue []byte) *cb.Block {
+	return block
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	at := func(offset time.Duration, i int) Record {
+		return Record{Time: start.Add(offset), Envelope: utils.MarshalOrPanic(makeEnvelope(i))}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		records []Record
+		batches []int
+	}{
+		{"Empty", nil, nil},
+		{"BatchSize", []Record{at(0, 0), at(1, 1), at(2, 2), at(3, 3)}, []int{3, 1}},
+		{"BatchTimeout", []Record{at(0, 0), at(500*time.Millisecond, 1), at(time.Second, 2), at(1100*time.Millisecond, 3)}, []int{2, 2}},
+		{"TimeoutFromFirstMessage", []Record{at(0, 0), at(900*time.Millisecond, 1), at(1800*time.Millisecond, 2)}, []int{2, 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := newBatchChain()
+			blocks, err := Replay(tc.records, chain)
+			require.NoError(t, err)
+			require.Len(t, blocks, len(tc.batches))
+
+			i := 0
+			for j, batch := range chain.batches {
+				require.Len(t, batch, tc.batches[j])
+				for _, env := range batch {
+					assert.Equal(t, makeEnvelope(i), env, "The messages should be ordered as recorded")
+					i++
+				}
+			}
+		})
+	}
+
+	_, err := Replay([]Record{{Envelope: []byte("garbage")}}, newBatchChain())
+	assert.Error(t, err)
+}
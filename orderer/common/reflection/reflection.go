@@ -0,0 +1,240 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package reflection implements the gRPC server reflection service, with
+// which generic gRPC tooling lists the services of a server and fetches the
+// descriptors of the protobuf files defining them, and so their messages,
+// rather than needing the files of the orderer.
+package reflection
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	rpb "github.com/hyperledger/fabric/protos/reflection"
+	"github.com/op/go-logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var logger = logging.MustGetLogger("orderer/common/reflection")
+
+// wellKnownPrefix is the directory of the well-known types, which the Go
+// protobuf library registers under their import path instead
+const wellKnownPrefix = "google/protobuf/"
+
+// Server implements the ServerReflection service of a gRPC server. The
+// descriptors are indexed on first use, once the services of the server
+// have been registered.
+type Server struct {
+	server *grpc.Server
+
+	once    sync.Once
+	files   map[string][]byte
+	deps    map[string][]string
+	symbols map[string]string
+}
+
+// Register registers the reflection service on the gRPC server
+func Register(s *grpc.Server) {
+	rpb.RegisterServerReflectionServer(s, NewServer(s))
+}
+
+// NewServer creates a reflection service describing the services of s
+func NewServer(s *grpc.Server) *Server {
+	return &Server{server: s}
+}
+
+// ServerReflectionInfo answers each request on the stream in turn
+func (s *Server) ServerReflectionInfo(stream rpb.ServerReflection_ServerReflectionInfoServer) error {
+	s.once.Do(s.index)
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.answer(req)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) answer(req *rpb.ServerReflectionRequest) *rpb.ServerReflectionResponse {
+	resp := &rpb.ServerReflectionResponse{ValidHost: req.Host, OriginalRequest: req}
+	switch r := req.MessageRequest.(type) {
+	case *rpb.ServerReflectionRequest_FileByFilename:
+		s.file(resp, r.FileByFilename)
+	case *rpb.ServerReflectionRequest_FileContainingSymbol:
+		filename, ok := s.symbols[r.FileContainingSymbol]
+		if !ok {
+			fail(resp, codes.NotFound, "symbol %s not found", r.FileContainingSymbol)
+			break
+		}
+		s.file(resp, filename)
+	case *rpb.ServerReflectionRequest_ListServices:
+		s.services(resp)
+	case *rpb.ServerReflectionRequest_FileContainingExtension, *rpb.ServerReflectionRequest_AllExtensionNumbersOfType:
+		// The protobuf files of the orderer define no extensions
+		fail(resp, codes.NotFound, "extensions are not supported")
+	default:
+		fail(resp, codes.InvalidArgument, "invalid request")
+	}
+	return resp
+}
+
+// file answers with the descriptor of the file followed by those of the
+// files it depends on, transitively
+func (s *Server) file(resp *rpb.ServerReflectionResponse, filename string) {
+	if _, ok := s.files[filename]; !ok {
+		fail(resp, codes.NotFound, "file %s not found", filename)
+		return
+	}
+	fdr := &rpb.FileDescriptorResponse{}
+	seen := map[string]bool{}
+	var add func(string)
+	add = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		fdr.FileDescriptorProto = append(fdr.FileDescriptorProto, s.files[name])
+		for _, dep := range s.deps[name] {
+			add(dep)
+		}
+	}
+	add(filename)
+	resp.MessageResponse = &rpb.ServerReflectionResponse_FileDescriptorResponse{FileDescriptorResponse: fdr}
+}
+
+// services answers with the names of the services of the server
+func (s *Server) services(resp *rpb.ServerReflectionResponse) {
+	var names []string
+	for name := range s.server.GetServiceInfo() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lsr := &rpb.ListServiceResponse{}
+	for _, name := range names {
+		lsr.Service = append(lsr.Service, &rpb.ServiceResponse{Name: name})
+	}
+	resp.MessageResponse = &rpb.ServerReflectionResponse_ListServicesResponse{ListServicesResponse: lsr}
+}
+
+// fail answers with an error
+func fail(resp *rpb.ServerReflectionResponse, code codes.Code, format string, args ...interface{}) {
+	resp.MessageResponse = &rpb.ServerReflectionResponse_ErrorResponse{ErrorResponse: &rpb.ErrorResponse{
+		ErrorCode:    int32(code),
+		ErrorMessage: fmt.Sprintf(format, args...),
+	}}
+}
+
+// index loads the files defining the services of the server, and those they
+// depend on, indexing the symbols they define
+func (s *Server) index() {
+	s.files = map[string][]byte{}
+	s.deps = map[string][]string{}
+	s.symbols = map[string]string{}
+	for name, info := range s.server.GetServiceInfo() {
+		filename, ok := info.Metadata.(string)
+		if !ok {
+			continue
+		}
+		if err := s.load(filename); err != nil {
+			logger.Warningf("Service %s will not be described: %s", name, err)
+		}
+	}
+}
+
+func (s *Server) load(filename string) error {
+	if _, ok := s.files[filename]; ok {
+		return nil
+	}
+	fd, err := decodeFile(filename)
+	if err != nil {
+		return err
+	}
+	// The file is described by the name it is imported by
+	fd.Name = proto.String(filename)
+	raw, err := proto.Marshal(fd)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the descriptor of %s: %s", filename, err)
+	}
+	s.files[filename] = raw
+	s.deps[filename] = fd.Dependency
+
+	prefix := ""
+	if fd.GetPackage() != "" {
+		prefix = fd.GetPackage() + "."
+	}
+	for _, service := range fd.Service {
+		s.symbols[prefix+service.GetName()] = filename
+		for _, method := range service.Method {
+			s.symbols[prefix+service.GetName()+"."+method.GetName()] = filename
+		}
+	}
+	for _, message := range fd.MessageType {
+		s.indexMessage(prefix, message, filename)
+	}
+	for _, enum := range fd.EnumType {
+		s.symbols[prefix+enum.GetName()] = filename
+	}
+
+	for _, dep := range fd.Dependency {
+		if err := s.load(dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) indexMessage(prefix string, message *dpb.DescriptorProto, filename string) {
+	name := prefix + message.GetName()
+	s.symbols[name] = filename
+	for _, nested := range message.NestedType {
+		s.indexMessage(name+".", nested, filename)
+	}
+	for _, enum := range message.EnumType {
+		s.symbols[name+"."+enum.GetName()] = filename
+	}
+}
+
+// decodeFile returns the descriptor of the file registered with the Go
+// protobuf library
+func decodeFile(filename string) (*dpb.FileDescriptorProto, error) {
+	gz := proto.FileDescriptor(filename)
+	if gz == nil && strings.HasPrefix(filename, wellKnownPrefix) {
+		base := strings.TrimSuffix(path.Base(filename), ".proto")
+		gz = proto.FileDescriptor("github.com/golang/protobuf/ptypes/" + base + "/" + base + ".proto")
+	}
+	if gz == nil {
+		return nil, fmt.Errorf("file %s is not registered", filename)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, fmt.Errorf("malformed descriptor of %s: %s", filename, err)
+	}
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("malformed descriptor of %s: %s", filename, err)
+	}
+	fd := &dpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(raw, fd); err != nil {
+		return nil, fmt.Errorf("malformed descriptor of %s: %s", filename, err)
+	}
+	return fd, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reflection
+
+import (
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	rpb "github.com/hyperledger/fabric/protos/reflection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// unimplemented implements none of the methods of the ChannelJoin service
+type unimplemented struct {
+	ab.ChannelJoinServer
+}
+
+// newTestClient serves the ChannelJoin service, which is not implemented,
+// and the reflection service, returning a reflection stream to the server
+func newTestClient(t *testing.T) (rpb.ServerReflection_ServerReflectionInfoClient, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	ab.RegisterChannelJoinServer(s, unimplemented{})
+	Register(s)
+	go s.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	return stream, func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func ask(t *testing.T, stream rpb.ServerReflection_ServerReflectionInfoClient, req *rpb.ServerReflectionRequest) *rpb.ServerReflectionResponse {
+	require.NoError(t, stream.Send(req))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, req, resp.OriginalRequest)
+	return resp
+}
+
+// files returns the names of the files in the response, and the descriptor
+// of the first
+func files(t *testing.T, resp *rpb.ServerReflectionResponse) ([]string, *dpb.FileDescriptorProto) {
+	fdr := resp.GetFileDescriptorResponse()
+	require.NotNil(t, fdr, "Expected file descriptors, got %v", resp.MessageResponse)
+	var names []string
+	var first *dpb.FileDescriptorProto
+	for _, raw := range fdr.FileDescriptorProto {
+		fd := &dpb.FileDescriptorProto{}
+		require.NoError(t, proto.Unmarshal(raw, fd))
+		if first == nil {
+			first = fd
+		}
+		names = append(names, fd.GetName())
+	}
+	return names, first
+}
+
+func TestListServices(t *testing.T) {
+	stream, stop := newTestClient(t)
+	defer stop()
+
+	resp := ask(t, stream, &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}})
+	assert.Equal(t, []*rpb.ServiceResponse{
+		{Name: "grpc.reflection.v1alpha.ServerReflection"},
+		{Name: "orderer.ChannelJoin"},
+	}, resp.GetListServicesResponse().GetService())
+}
+
+func TestFileContainingSymbol(t *testing.T) {
+	stream, stop := newTestClient(t)
+	defer stop()
+
+	for _, symbol := range []string{"orderer.ChannelJoin", "orderer.ChannelJoin.MintJoinToken", "orderer.JoinToken", "common.Envelope", "common.Status"} {
+		names, _ := files(t, ask(t, stream, &rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+		}))
+		assert.NotEmpty(t, names, "Symbol %s", symbol)
+	}
+
+	names, fd := files(t, ask(t, stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "orderer.JoinToken"},
+	}))
+	assert.Equal(t, "orderer/jointoken.proto", fd.GetName())
+	assert.Contains(t, names, "common/common.proto", "Dependencies should follow the file")
+	assert.Contains(t, names, "google/protobuf/timestamp.proto", "Well-known types should be described by their import path")
+
+	resp := ask(t, stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "orderer.Missing"},
+	})
+	assert.Equal(t, int32(codes.NotFound), resp.GetErrorResponse().GetErrorCode())
+}
+
+func TestFileByFilename(t *testing.T) {
+	stream, stop := newTestClient(t)
+	defer stop()
+
+	names, fd := files(t, ask(t, stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: "common/common.proto"},
+	}))
+	assert.Equal(t, "common/common.proto", fd.GetName())
+	assert.Equal(t, "common", fd.GetPackage())
+	assert.Equal(t, len(fd.Dependency)+1, len(names))
+
+	resp := ask(t, stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: "missing.proto"},
+	})
+	assert.Equal(t, int32(codes.NotFound), resp.GetErrorResponse().GetErrorCode())
+
+	resp = ask(t, stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_AllExtensionNumbersOfType{AllExtensionNumbersOfType: "common.Envelope"},
+	})
+	assert.NotNil(t, resp.GetErrorResponse())
+}
@@ -0,0 +1,272 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replayfilter
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/replayfilter")
+
+// DefaultWindowSize is the number of recently ordered messages of a chain
+// which are tracked to detect replays
+const DefaultWindowSize = 10000
+
+// Window is the extent of the recently ordered messages of a chain which are
+// tracked to detect replays
+type Window struct {
+	// Size is the number of messages tracked, DefaultWindowSize if it is not
+	// positive
+	Size int
+	// TTL, if positive, is how long a message is tracked, by the timestamps
+	// of the channel headers of the messages ordered since. Messages whose
+	// timestamp is older than the TTL, which could replay messages no longer
+	// tracked, are rejected, as are messages received with a timestamp more
+	// than the TTL away from the clock of the orderer.
+	TTL time.Duration
+}
+
+// withDefault returns the window, of DefaultWindowSize if its size is not
+// positive
+func (w Window) withDefault() Window {
+	if w.Size <= 0 {
+		w.Size = DefaultWindowSize
+	}
+	return w
+}
+
+// Filter rejects messages whose epoch is not the current epoch of the chain,
+// and replays of any of the most recently ordered messages, identified by
+// the creator and nonce of their signature header.
+//
+// Messages are only tracked once they are ordered, and expire by the
+// timestamps of the messages ordered after them, so that the state of the
+// filter depends only on the order of messages, and so is the same on every
+// orderer of a chain.
+type Filter struct {
+	epoch uint64
+	clock clock.Clock
+
+	lock   sync.Mutex
+	window Window
+	seen   map[[sha256.Size]byte]struct{}
+	order  []entry
+	latest time.Time
+}
+
+type entry struct {
+	key   [sha256.Size]byte
+	stamp time.Time
+}
+
+// New creates a Filter for a chain at the given epoch which tracks up to
+// size messages
+func New(epoch uint64, size int) *Filter {
+	return NewWindow(epoch, Window{Size: size}, clock.Real())
+}
+
+// NewWindow creates a Filter for a chain at the given epoch which tracks the
+// messages in the window, checking the timestamps of the messages it receives
+// against the clock
+func NewWindow(epoch uint64, window Window, clk clock.Clock) *Filter {
+	return &Filter{
+		epoch:  epoch,
+		window: window.withDefault(),
+		clock:  clk,
+		seen:   make(map[[sha256.Size]byte]struct{}),
+	}
+}
+
+// SetWindow replaces the window of the filter, evicting the messages which
+// no longer fit in it. As it only depends on the messages ordered, every
+// orderer of a chain which sets the window at the same point of the chain
+// tracks the same messages.
+func (f *Filter) SetWindow(window Window) {
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.window = window.withDefault()
+	f.evict()
+}
+
+// Restore tracks the most recently ordered messages of the chain, so that
+// replays of messages ordered before a restart are detected
+func (f *Filter) Restore(reader ledger.Reader) {
+	f.lock.Lock()
+	size := f.window.Size
+	f.lock.Unlock()
+	var entries []entry
+	for number := reader.Height(); number > 0 && len(entries) < size; number-- {
+		block := ledger.GetBlock(reader, number-1)
+		if block == nil || block.Data == nil {
+			continue
+		}
+		for i := len(block.Data.Data) - 1; i >= 0 && len(entries) < size; i-- {
+			env, err := utils.UnmarshalEnvelope(block.Data.Data[i])
+			if err != nil {
+				continue
+			}
+			msg, err := filter.NewMessage(env)
+			if err != nil {
+				continue
+			}
+			key, tracked, err := f.key(msg)
+			if err != nil || !tracked {
+				continue
+			}
+			entries = append(entries, entry{key: key, stamp: stamp(msg)})
+		}
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i := len(entries) - 1; i >= 0; i-- {
+		f.track(entries[i])
+	}
+	logger.Debugf("Restored %d recently ordered messages", len(f.order))
+}
+
+// stamp returns the timestamp of the channel header of the message, or the
+// zero time if it has none
+func stamp(msg *filter.Message) time.Time {
+	ts := msg.ChannelHeader.Timestamp
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos))
+}
+
+// key returns the key under which the message is tracked, and whether it
+// should be tracked at all. Config and orderer transactions, which are
+// accepted by earlier filters and are protected against replay by the config
+// sequence, and messages without a creator, which cannot satisfy a signature
+// policy, are not tracked.
+func (f *Filter) key(msg *filter.Message) ([sha256.Size]byte, bool, error) {
+	var key [sha256.Size]byte
+	switch msg.Type() {
+	case cb.HeaderType_CONFIG, cb.HeaderType_ORDERER_TRANSACTION:
+		return key, false, nil
+	}
+	if epoch := msg.ChannelHeader.Epoch; epoch != f.epoch {
+		return key, false, fmt.Errorf("epoch %d is not the current epoch %d", epoch, f.epoch)
+	}
+	shdr := msg.SignatureHeader
+	if len(shdr.Creator) == 0 {
+		return key, false, nil
+	}
+	if len(shdr.Nonce) == 0 {
+		return key, false, fmt.Errorf("missing nonce")
+	}
+
+	h := sha256.New()
+	h.Write(shdr.Creator)
+	h.Write(shdr.Nonce)
+	copy(key[:], h.Sum(nil))
+	return key, true, nil
+}
+
+// Apply rejects messages at the wrong epoch and replays of recently ordered
+// messages, without tracking the message
+func (f *Filter) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := f.apply(message, false)
+	return action, committer
+}
+
+// Explain rejects messages as Apply does, telling why
+func (f *Filter) Explain(message *filter.Message) (filter.Action, filter.Committer, string) {
+	return f.apply(message, false)
+}
+
+// ApplyOrdering rejects messages as Apply does, tracking those which are
+// forwarded
+func (f *Filter) ApplyOrdering(message *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := f.apply(message, true)
+	return action, committer
+}
+
+func (f *Filter) apply(message *filter.Message, ordering bool) (filter.Action, filter.Committer, string) {
+	key, tracked, err := f.key(message)
+	if err != nil {
+		logger.Warningf("Rejecting message: %s", err)
+		return filter.Reject, nil, err.Error()
+	}
+	if !tracked {
+		return filter.Forward, nil, ""
+	}
+
+	ts := stamp(message)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if ttl := f.window.TTL; ttl > 0 && !ordering {
+		// The clock of the orderer is only consulted on receipt, as it
+		// differs between orderers
+		if now := f.clock.Now(); ts.Before(now.Add(-ttl)) || ts.After(now.Add(ttl)) {
+			logger.Warningf("Rejecting message with timestamp %s more than %s away from %s", ts, ttl, now)
+			return filter.Reject, nil, fmt.Sprintf("timestamp %s is more than %s away from %s", ts, ttl, now)
+		}
+	}
+	if f.expired(ts) {
+		logger.Warningf("Rejecting message with timestamp %s which is no longer tracked", ts)
+		return filter.Reject, nil, fmt.Sprintf("timestamp %s is too old to be checked for replays", ts)
+	}
+	if _, ok := f.seen[key]; ok {
+		logger.Warningf("Rejecting replay of a recently ordered message")
+		return filter.Reject, nil, "replay of a recently ordered message"
+	}
+	if ordering {
+		f.track(entry{key: key, stamp: ts})
+	}
+	return filter.Forward, nil, ""
+}
+
+// expired returns whether messages with the timestamp are older than the
+// TTL, by the latest timestamp ordered. The lock must be held.
+func (f *Filter) expired(ts time.Time) bool {
+	return f.window.TTL > 0 && ts.Before(f.latest.Add(-f.window.TTL))
+}
+
+// track adds the entry to the window, evicting the oldest entries if it is
+// full or they have expired. The lock must be held.
+func (f *Filter) track(e entry) {
+	if _, ok := f.seen[e.key]; ok {
+		return
+	}
+	if e.stamp.After(f.latest) {
+		f.latest = e.stamp
+	}
+	for len(f.order) > 0 && (len(f.order) >= f.window.Size || f.expired(f.order[0].stamp)) {
+		f.evictOldest()
+	}
+	f.seen[e.key] = struct{}{}
+	f.order = append(f.order, e)
+}
+
+// evict evicts the oldest entries while the window is overfull or they have
+// expired. The lock must be held.
+func (f *Filter) evict() {
+	for len(f.order) > 0 && (len(f.order) > f.window.Size || f.expired(f.order[0].stamp)) {
+		f.evictOldest()
+	}
+}
+
+// evictOldest evicts the oldest entry. The lock must be held.
+func (f *Filter) evictOldest() {
+	delete(f.seen, f.order[0].key)
+	f.order = f.order[1:]
+}
@@ -0,0 +1,202 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replayfilter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeEnvelope(txType cb.HeaderType, epoch uint64, creator, nonce string) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+					Type:      int32(txType),
+					ChannelId: "testchain",
+					Epoch:     epoch,
+				}),
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{
+					Creator: []byte(creator),
+					Nonce:   []byte(nonce),
+				}),
+			},
+		}),
+	}
+}
+
+func message(env *cb.Envelope) *filter.Message {
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		panic(err)
+	}
+	return msg
+}
+
+func makeTx(creator, nonce string) *cb.Envelope {
+	return makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 0, creator, nonce)
+}
+
+func TestReplay(t *testing.T) {
+	f := New(0, DefaultWindowSize)
+
+	action, _ := f.Apply(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Forward, action)
+	action, _ = f.Apply(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Forward, action, "Messages should not be tracked until they are ordered")
+
+	action, _ = f.ApplyOrdering(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Forward, action)
+	action, _ = f.Apply(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Reject, action, "A replay should be rejected on receipt")
+	action, _ = f.ApplyOrdering(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Reject, action, "A replay should be rejected when ordered")
+
+	action, _ = f.Apply(message(makeTx("alice", "2")))
+	assert.EqualValues(t, filter.Forward, action)
+	action, _ = f.Apply(message(makeTx("bob", "1")))
+	assert.EqualValues(t, filter.Forward, action, "The same nonce from another creator is not a replay")
+}
+
+func TestExplain(t *testing.T) {
+	f := New(1, DefaultWindowSize)
+
+	_, _, info := f.Explain(message(makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 0, "alice", "1")))
+	assert.Equal(t, "epoch 0 is not the current epoch 1", info)
+
+	f.ApplyOrdering(message(makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 1, "alice", "1")))
+	action, _, info := f.Explain(message(makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 1, "alice", "1")))
+	assert.EqualValues(t, filter.Reject, action)
+	assert.Equal(t, "replay of a recently ordered message", info)
+
+	action, _, info = f.Explain(message(makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 1, "alice", "2")))
+	assert.EqualValues(t, filter.Forward, action)
+	assert.Empty(t, info, "Forwarded messages need no explanation")
+}
+
+func TestWindow(t *testing.T) {
+	f := New(0, 2)
+	for _, nonce := range []string{"1", "2", "3"} {
+		f.ApplyOrdering(message(makeTx("alice", nonce)))
+	}
+
+	action, _ := f.Apply(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Forward, action, "The oldest message should have been evicted")
+	action, _ = f.Apply(message(makeTx("alice", "2")))
+	assert.EqualValues(t, filter.Reject, action)
+}
+
+func TestInvalid(t *testing.T) {
+	f := New(1, DefaultWindowSize)
+
+	action, _ := f.Apply(message(makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 0, "alice", "1")))
+	assert.EqualValues(t, filter.Reject, action, "Messages at another epoch should be rejected")
+	action, _ = f.Apply(message(makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 1, "alice", "")))
+	assert.EqualValues(t, filter.Reject, action, "Signed messages without a nonce should be rejected")
+
+	action, _ = f.Apply(message(makeEnvelope(cb.HeaderType_ENDORSER_TRANSACTION, 1, "", "")))
+	assert.EqualValues(t, filter.Forward, action, "Messages without a creator are left to the signature filter")
+	for i := 0; i < 2; i++ {
+		action, _ = f.ApplyOrdering(message(makeEnvelope(cb.HeaderType_CONFIG, 0, "orderer", "1")))
+		assert.EqualValues(t, filter.Forward, action, "Config transactions should not be tracked")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	rl, _ := ramledger.New(10).GetOrCreate("testchain")
+	rl.Append(cb.NewBlock(0, nil))
+	rl.Append(ledger.CreateNextBlock(rl, []*cb.Envelope{makeTx("alice", "1"), makeTx("alice", "2")}))
+	rl.Append(ledger.CreateNextBlock(rl, []*cb.Envelope{makeEnvelope(cb.HeaderType_CONFIG, 0, "orderer", "1"), makeTx("alice", "3")}))
+
+	f := New(0, 2)
+	f.Restore(rl)
+	assert.Equal(t, 2, len(f.seen))
+
+	action, _ := f.Apply(message(makeTx("alice", "3")))
+	assert.EqualValues(t, filter.Reject, action)
+	action, _ = f.Apply(message(makeTx("alice", "2")))
+	assert.EqualValues(t, filter.Reject, action)
+	action, _ = f.Apply(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Forward, action, "Only the most recent messages should be restored")
+
+	f.ApplyOrdering(message(makeTx("alice", "4")))
+	action, _ = f.Apply(message(makeTx("alice", "2")))
+	assert.EqualValues(t, filter.Forward, action, "Restored messages should be evicted in the order they were ordered")
+}
+
+func makeStampedTx(creator, nonce string, ts time.Time) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+					Type:      int32(cb.HeaderType_ENDORSER_TRANSACTION),
+					ChannelId: "testchain",
+					Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())},
+				}),
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{
+					Creator: []byte(creator),
+					Nonce:   []byte(nonce),
+				}),
+			},
+		}),
+	}
+}
+
+func TestTTL(t *testing.T) {
+	start := time.Unix(1000000, 0)
+	clk := clock.NewManual(start)
+	f := NewWindow(0, Window{Size: DefaultWindowSize, TTL: time.Minute}, clk)
+
+	action, _ := f.Apply(message(makeStampedTx("alice", "1", start.Add(-2*time.Minute))))
+	assert.EqualValues(t, filter.Reject, action, "A message timestamped before the TTL should be rejected on receipt")
+	action, _ = f.Apply(message(makeStampedTx("alice", "1", start.Add(2*time.Minute))))
+	assert.EqualValues(t, filter.Reject, action, "A message timestamped after the TTL should be rejected on receipt")
+	action, _ = f.Apply(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Reject, action, "A message without a timestamp should be rejected")
+
+	action, _ = f.ApplyOrdering(message(makeStampedTx("alice", "1", start)))
+	assert.EqualValues(t, filter.Forward, action)
+	action, _ = f.ApplyOrdering(message(makeStampedTx("alice", "2", start.Add(30*time.Second))))
+	assert.EqualValues(t, filter.Forward, action)
+	assert.Len(t, f.order, 2)
+
+	action, _ = f.ApplyOrdering(message(makeStampedTx("alice", "3", start.Add(90*time.Second))))
+	assert.EqualValues(t, filter.Forward, action, "The clock of the orderer should not be consulted when ordering")
+	assert.Len(t, f.order, 2, "The first message should have expired")
+
+	action, _ = f.ApplyOrdering(message(makeStampedTx("alice", "1", start)))
+	assert.EqualValues(t, filter.Reject, action, "A replay of an expired message should be rejected for its timestamp")
+	action, _ = f.ApplyOrdering(message(makeStampedTx("alice", "2", start.Add(30*time.Second))))
+	assert.EqualValues(t, filter.Reject, action, "A replay of a tracked message should be rejected")
+}
+
+func TestSetWindow(t *testing.T) {
+	f := New(0, 0)
+	assert.Equal(t, DefaultWindowSize, f.window.Size, "A window of no size should be of the default size")
+	for _, nonce := range []string{"1", "2", "3"} {
+		f.ApplyOrdering(message(makeTx("alice", nonce)))
+	}
+
+	f.SetWindow(Window{Size: 2})
+	assert.Len(t, f.order, 2, "The messages beyond the new window should be evicted")
+	action, _ := f.Apply(message(makeTx("alice", "1")))
+	assert.EqualValues(t, filter.Forward, action, "The oldest message should have been evicted")
+	action, _ = f.Apply(message(makeTx("alice", "3")))
+	assert.EqualValues(t, filter.Reject, action)
+
+	var nilFilter *Filter
+	nilFilter.SetWindow(Window{Size: 1})
+}
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package rpcstatus builds the gRPC errors with which the orderer fails
+// calls, carrying a google.rpc.Status whose details describe the failure,
+// such as the reason a request was rejected, when it may be retried and
+// which orderers it should be sent to instead, so that generic gRPC tooling
+// and SDKs can handle the failure without parsing messages.
+package rpcstatus
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"golang.org/x/net/context"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// typeURLPrefix prefixes the names of the types of the details
+const typeURLPrefix = "type.googleapis.com/"
+
+// Config is the configuration of the errors with which streams end. Its zero
+// value ends streams with an OK status.
+type Config struct {
+	// StreamErrors is whether Broadcast and Deliver streams end with an
+	// error describing the failure of a request after its failure status
+	// was sent, rather than with an OK status
+	StreamErrors bool
+	// RetryDelay is the delay after which clients are told they may retry
+	// requests which failed because the service was unavailable, which are
+	// not given a delay if it is not positive
+	RetryDelay time.Duration
+}
+
+// Error returns an error with the code and message, whose status carries
+// the details
+func Error(code codes.Code, msg string, details ...proto.Message) error {
+	st := &spb.Status{Code: int32(code), Message: msg}
+	for _, detail := range details {
+		value, err := proto.Marshal(detail)
+		if err != nil {
+			// The details are generated messages, which always marshal
+			panic(fmt.Sprintf("failed to marshal error detail: %s", err))
+		}
+		st.Details = append(st.Details, &any.Any{TypeUrl: typeURLPrefix + proto.MessageName(detail), Value: value})
+	}
+	return status.ErrorProto(st)
+}
+
+// Details returns the details of the status of the error, skipping those of
+// unknown types
+func Details(err error) []proto.Message {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	var details []proto.Message
+	for _, detail := range st.Proto().GetDetails() {
+		if len(detail.TypeUrl) <= len(typeURLPrefix) {
+			continue
+		}
+		t := proto.MessageType(detail.TypeUrl[len(typeURLPrefix):])
+		if t == nil {
+			continue
+		}
+		msg, ok := reflect.New(t.Elem()).Interface().(proto.Message)
+		if !ok || proto.Unmarshal(detail.Value, msg) != nil {
+			continue
+		}
+		details = append(details, msg)
+	}
+	return details
+}
+
+// Code returns the gRPC code corresponding to the status of a response
+func Code(s cb.Status) codes.Code {
+	switch s {
+	case cb.Status_SUCCESS:
+		return codes.OK
+	case cb.Status_BAD_REQUEST, cb.Status_REQUEST_ENTITY_TOO_LARGE:
+		return codes.InvalidArgument
+	case cb.Status_FORBIDDEN:
+		return codes.PermissionDenied
+	case cb.Status_NOT_FOUND:
+		return codes.NotFound
+	case cb.Status_SERVICE_UNAVAILABLE, cb.Status_TEMPORARY_REDIRECT:
+		return codes.Unavailable
+	case cb.Status_INTERNAL_SERVER_ERROR:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
+
+// streamErrorsKey is the key of the context value marking streams which end
+// with errors regardless of the configuration
+type streamErrorsKey struct{}
+
+// WithStreamErrors returns a context of a stream which ends with an error
+// describing the failure of a request even if streams are configured to end
+// with an OK status
+func WithStreamErrors(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamErrorsKey{}, true)
+}
+
+// StreamError returns the error with which the stream of the context ends
+// after the client was sent the failure status of its request to the
+// channel, for the reason, or nil if the stream ends with an OK status.
+// Unavailable services come with the configured retry delay, and redirects
+// with the endpoints.
+func (c Config) StreamError(ctx context.Context, s cb.Status, reason, chainID string, endpoints []string) error {
+	return c.streamError(ctx, s, reason, chainID, endpoints, c.RetryDelay)
+}
+
+// StreamErrorAfter returns the error with which the stream of the context
+// ends, as StreamError does, telling the client it may retry after the delay
+// rather than the configured one
+func (c Config) StreamErrorAfter(ctx context.Context, s cb.Status, reason, chainID string, delay time.Duration) error {
+	return c.streamError(ctx, s, reason, chainID, nil, delay)
+}
+
+func (c Config) streamError(ctx context.Context, s cb.Status, reason, chainID string, endpoints []string, retryDelay time.Duration) error {
+	if marked, _ := ctx.Value(streamErrorsKey{}).(bool); !c.StreamErrors && !marked {
+		return nil
+	}
+	details := []proto.Message{&ab.ErrorInfo{Status: s, Reason: reason, ChannelId: chainID}}
+	if s == cb.Status_SERVICE_UNAVAILABLE && retryDelay > 0 {
+		details = append(details, Retry(retryDelay))
+	}
+	if len(endpoints) > 0 {
+		details = append(details, &ab.RedirectInfo{Endpoints: endpoints})
+	}
+	msg := fmt.Sprintf("request rejected with status %s: %s", s, reason)
+	if chainID != "" {
+		msg = fmt.Sprintf("request for channel %s rejected with status %s: %s", chainID, s, reason)
+	}
+	return Error(Code(s), msg, details...)
+}
+
+// Retry returns the detail telling the client it may retry after the delay,
+// rounded up to the millisecond
+func Retry(delay time.Duration) *ab.RetryInfo {
+	return &ab.RetryInfo{RetryDelayMs: int64((delay + time.Millisecond - 1) / time.Millisecond)}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rpcstatus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestError(t *testing.T) {
+	err := Error(codes.ResourceExhausted, "slow down", Retry(1500*time.Microsecond), &ab.RedirectInfo{Endpoints: []string{"a:7050"}})
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	assert.Equal(t, "slow down", grpc.ErrorDesc(err))
+	assert.Equal(t, []proto.Message{&ab.RetryInfo{RetryDelayMs: 2}, &ab.RedirectInfo{Endpoints: []string{"a:7050"}}}, Details(err))
+
+	assert.Empty(t, Details(fmt.Errorf("not a status")))
+	assert.Empty(t, Details(grpc.Errorf(codes.Internal, "no details")))
+}
+
+func TestStreamError(t *testing.T) {
+	assert.NoError(t, Config{}.StreamError(context.Background(), cb.Status_NOT_FOUND, "channel_not_found", "foo", nil), "Streams should end with OK unless configured")
+	err := Config{}.StreamError(WithStreamErrors(context.Background()), cb.Status_NOT_FOUND, "channel_not_found", "foo", nil)
+	assert.Equal(t, codes.NotFound, grpc.Code(err), "Marked streams should end with errors regardless of the configuration")
+
+	config := Config{StreamErrors: true, RetryDelay: time.Second}
+	err = config.StreamError(context.Background(), cb.Status_NOT_FOUND, "channel_not_found", "foo", nil)
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	assert.Equal(t, []proto.Message{&ab.ErrorInfo{Status: cb.Status_NOT_FOUND, Reason: "channel_not_found", ChannelId: "foo"}}, Details(err))
+
+	err = config.StreamError(context.Background(), cb.Status_SERVICE_UNAVAILABLE, "unavailable", "foo", nil)
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Contains(t, Details(err), &ab.RetryInfo{RetryDelayMs: 1000})
+
+	err = config.StreamError(context.Background(), cb.Status_TEMPORARY_REDIRECT, "redirect", "", []string{"a:7050", "b:7050"})
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Contains(t, Details(err), &ab.RedirectInfo{Endpoints: []string{"a:7050", "b:7050"}})
+	assert.NotContains(t, Details(err), &ab.RetryInfo{RetryDelayMs: 1000})
+
+	err = config.StreamErrorAfter(context.Background(), cb.Status_SERVICE_UNAVAILABLE, "rate_limited", "foo", 1500*time.Microsecond)
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Contains(t, Details(err), &ab.RetryInfo{RetryDelayMs: 2}, "The given delay should replace the configured one")
+}
+
+func TestCode(t *testing.T) {
+	for status, code := range map[cb.Status]codes.Code{
+		cb.Status_SUCCESS:                  codes.OK,
+		cb.Status_BAD_REQUEST:              codes.InvalidArgument,
+		cb.Status_REQUEST_ENTITY_TOO_LARGE: codes.InvalidArgument,
+		cb.Status_FORBIDDEN:                codes.PermissionDenied,
+		cb.Status_NOT_FOUND:                codes.NotFound,
+		cb.Status_SERVICE_UNAVAILABLE:      codes.Unavailable,
+		cb.Status_INTERNAL_SERVER_ERROR:    codes.Internal,
+	} {
+		assert.Equal(t, code, Code(status), "Status %s", status)
+	}
+}
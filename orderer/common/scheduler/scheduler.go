@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package scheduler shares the capacity of the orderer to produce blocks
+// fairly among its chains. Each block is granted one of a fixed number of
+// slots before it is signed and appended to the ledger, and while the slots
+// are taken, the waiting chains are served in proportion to their weights, by
+// start-time fair queuing on the bytes of their blocks, so that a high volume
+// chain can not starve the others of the ledger.
+package scheduler
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+)
+
+// Config is the configuration of a Scheduler
+type Config struct {
+	// Slots is the number of blocks written at once across all chains, the
+	// chains are not scheduled if it is not positive
+	Slots int
+	// Weights are the shares of the slots of the chains, by chain ID. A
+	// chain with twice the weight of another is granted twice the bytes
+	// while both are waiting.
+	Weights map[string]int
+	// DefaultWeight is the weight of the chains not in Weights, 1 if it is
+	// not positive
+	DefaultWeight int
+	// Metrics, if set, creates the metrics of the scheduler instead of the
+	// default registry
+	Metrics metrics.Provider
+}
+
+type request struct {
+	start float64
+	seq   uint64
+	ready chan struct{}
+}
+
+type queue []*request
+
+func (q queue) Len() int { return len(q) }
+func (q queue) Less(i, j int) bool {
+	if q[i].start != q[j].start {
+		return q[i].start < q[j].start
+	}
+	return q[i].seq < q[j].seq
+}
+func (q queue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(*request)) }
+func (q *queue) Pop() interface{} {
+	old := *q
+	r := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return r
+}
+
+// Scheduler grants the slots to write blocks to the chains
+type Scheduler struct {
+	config       Config
+	waitDuration metrics.Histogram
+
+	lock    sync.Mutex
+	busy    int
+	virtual float64
+	finish  map[string]float64
+	waiting queue
+	seq     uint64
+}
+
+// New creates a Scheduler
+func New(config Config) *Scheduler {
+	provider := config.Metrics
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	return &Scheduler{
+		config: config,
+		waitDuration: provider.NewHistogram(metrics.HistogramOpts{
+			Opts: metrics.Opts{
+				Namespace:  "orderer",
+				Subsystem:  "scheduler",
+				Name:       "wait_seconds",
+				Help:       "The time a block waited for a slot to be written.",
+				LabelNames: []string{"channel"},
+			},
+		}),
+		finish: make(map[string]float64),
+	}
+}
+
+func (s *Scheduler) weight(chainID string) float64 {
+	if weight, ok := s.config.Weights[chainID]; ok && weight > 0 {
+		return float64(weight)
+	}
+	if s.config.DefaultWeight > 0 {
+		return float64(s.config.DefaultWeight)
+	}
+	return 1
+}
+
+// Acquire waits for a slot to write a block of size bytes on the chain. Each
+// call must be followed by a call to Release once the block is written.
+func (s *Scheduler) Acquire(chainID string, size int) {
+	if s == nil || s.config.Slots <= 0 {
+		return
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	s.lock.Lock()
+	// A chain which was idle starts from the virtual time of the chains
+	// being served, rather than being credited for the time it was idle
+	start := s.finish[chainID]
+	if start < s.virtual {
+		start = s.virtual
+	}
+	s.finish[chainID] = start + float64(size)/s.weight(chainID)
+	if s.busy < s.config.Slots && len(s.waiting) == 0 {
+		s.busy++
+		s.virtual = start
+		s.lock.Unlock()
+		s.waitDuration.With(chainID).Observe(0)
+		return
+	}
+	s.seq++
+	r := &request{start: start, seq: s.seq, ready: make(chan struct{})}
+	heap.Push(&s.waiting, r)
+	s.lock.Unlock()
+
+	waitStart := time.Now()
+	<-r.ready
+	s.waitDuration.With(chainID).Observe(time.Since(waitStart).Seconds())
+}
+
+// Release frees the slot acquired to write a block, granting it to the
+// waiting block with the earliest start time, if any
+func (s *Scheduler) Release() {
+	if s == nil || s.config.Slots <= 0 {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.waiting) == 0 {
+		s.busy--
+		return
+	}
+	r := heap.Pop(&s.waiting).(*request)
+	s.virtual = r.start
+	close(r.ready)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitFor waits until n blocks are waiting for a slot
+func waitFor(t *testing.T, s *Scheduler, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.lock.Lock()
+		waiting := len(s.waiting)
+		s.lock.Unlock()
+		if waiting == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d blocks waiting rather than %d", waiting, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var s *Scheduler
+	s.Acquire("foo", 1)
+	s.Release()
+
+	s = New(Config{})
+	for i := 0; i < 10; i++ {
+		s.Acquire("foo", 1)
+	}
+	assert.Equal(t, 0, s.busy, "Blocks should not wait without slots")
+}
+
+func TestWeightedOrder(t *testing.T) {
+	s := New(Config{Slots: 1, Weights: map[string]int{"heavy": 2}})
+	s.Acquire("holder", 1)
+
+	var lock sync.Mutex
+	var granted []string
+	var wg sync.WaitGroup
+	request := func(chainID string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Acquire(chainID, 2)
+			lock.Lock()
+			granted = append(granted, chainID)
+			lock.Unlock()
+			s.Release()
+		}()
+	}
+	for i, chainID := range []string{"heavy", "heavy", "heavy", "light", "light", "light"} {
+		request(chainID)
+		waitFor(t, s, i+1)
+	}
+
+	s.Release()
+	wg.Wait()
+	assert.Equal(t, []string{"heavy", "light", "heavy", "heavy", "light", "light"}, granted,
+		"The chains should be granted slots in proportion to their weights")
+	assert.Equal(t, 0, s.busy)
+}
+
+func TestIdleChainNotCredited(t *testing.T) {
+	s := New(Config{Slots: 1})
+	for i := 0; i < 5; i++ {
+		s.Acquire("busy", 10)
+		s.Release()
+	}
+	s.Acquire("busy", 10)
+
+	var lock sync.Mutex
+	var granted []string
+	var wg sync.WaitGroup
+	for i, chainID := range []string{"busy", "busy", "busy", "idle", "idle", "idle"} {
+		wg.Add(1)
+		go func(chainID string) {
+			defer wg.Done()
+			s.Acquire(chainID, 10)
+			lock.Lock()
+			granted = append(granted, chainID)
+			lock.Unlock()
+			s.Release()
+		}(chainID)
+		waitFor(t, s, i+1)
+	}
+
+	s.Release()
+	wg.Wait()
+	assert.Equal(t, []string{"idle", "busy", "idle", "busy", "idle", "busy"}, granted,
+		"A chain which was idle should share the slots rather than catch up on the time it was idle")
+}
+
+func TestSlots(t *testing.T) {
+	s := New(Config{Slots: 2})
+	s.Acquire("foo", 1)
+	s.Acquire("bar", 1)
+
+	acquired := make(chan struct{})
+	go func() {
+		s.Acquire("baz", 1)
+		close(acquired)
+	}()
+	waitFor(t, s, 1)
+	select {
+	case <-acquired:
+		t.Fatal("A block should wait while the slots are taken")
+	default:
+	}
+	s.Release()
+	<-acquired
+	s.Release()
+	s.Release()
+	assert.Equal(t, 0, s.busy)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package sdnotify reports the lifecycle of the process to the service
+// manager which started it, following the sd_notify protocol of systemd: the
+// process reports when it is ready, reloading and stopping, and pings the
+// watchdog of the service manager, which restarts the process if the pings
+// stop. On Windows, the same lifecycle is reported to the service control
+// manager by a service control handler instead.
+package sdnotify
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/sdnotify")
+
+// The states reported to the service manager
+const (
+	Ready     = "READY=1"
+	Reloading = "RELOADING=1"
+	Stopping  = "STOPPING=1"
+	Watchdog  = "WATCHDOG=1"
+)
+
+// Notifier sends notifications to the service manager. A nil Notifier, of a
+// process not started by a service manager, sends none.
+type Notifier struct {
+	addr     *net.UnixAddr
+	watchdog time.Duration
+	// states, if set, receives the notifications instead of the socket of
+	// the service manager, for the service control handler of a Windows
+	// service
+	states chan string
+}
+
+// NewNotifier creates a Notifier for the service manager named by the
+// environment of the process, NOTIFY_SOCKET, and WATCHDOG_USEC if its
+// watchdog is enabled for the process. It returns nil if the process was not
+// started by a service manager.
+func NewNotifier() *Notifier {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// Sockets in the abstract namespace are named with a leading @
+	name := socket
+	if name[0] == '@' {
+		name = "\x00" + name[1:]
+	}
+	n := &Notifier{addr: &net.UnixAddr{Name: name, Net: "unixgram"}}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return n
+	}
+	if usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64); err == nil && usec > 0 {
+		n.watchdog = time.Duration(usec) * time.Microsecond
+	}
+	return n
+}
+
+// Notify sends the state, one or more newline separated assignments, to the
+// service manager
+func (n *Notifier) Notify(state string) error {
+	if n == nil {
+		return nil
+	}
+	if n.states != nil {
+		select {
+		case n.states <- state:
+			return nil
+		default:
+			return fmt.Errorf("the service control handler is not accepting notifications")
+		}
+	}
+	conn, err := net.DialUnix(n.addr.Net, nil, n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %s", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to notify the service manager: %s", err)
+	}
+	return nil
+}
+
+// Report sends the state to the service manager along with a human readable
+// status, logging rather than returning a failure, which the process
+// survives
+func (n *Notifier) Report(state, status string) {
+	if n == nil {
+		return
+	}
+	if err := n.Notify(fmt.Sprintf("%s\nSTATUS=%s", state, status)); err != nil {
+		logger.Warningf("Failed to report %s: %s", state, err)
+	}
+}
+
+// WatchdogInterval returns the interval within which the service manager
+// expects watchdog pings, which is zero if its watchdog is disabled
+func (n *Notifier) WatchdogInterval() time.Duration {
+	if n == nil {
+		return 0
+	}
+	return n.watchdog
+}
+
+// RunWatchdog pings the watchdog of the service manager at half its
+// interval, as long as alive reports the process alive, until stop is
+// closed. It returns at once if the watchdog is disabled.
+func (n *Notifier) RunWatchdog(alive func() bool, stop <-chan struct{}) {
+	interval := n.WatchdogInterval() / 2
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !alive() {
+				logger.Warningf("Not pinging the watchdog of the service manager, the process is not alive")
+				continue
+			}
+			if err := n.Notify(Watchdog); err != nil {
+				logger.Warningf("Failed to ping the watchdog: %s", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sdnotify
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listen creates the socket of a service manager and points the environment
+// at it
+func listen(t *testing.T) (*net.UnixConn, func()) {
+	dir, err := ioutil.TempDir("", "sdnotify")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	os.Setenv("NOTIFY_SOCKET", path)
+	return conn, func() {
+		os.Unsetenv("NOTIFY_SOCKET")
+		os.Unsetenv("WATCHDOG_USEC")
+		os.Unsetenv("WATCHDOG_PID")
+		conn.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func receive(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestNoServiceManager(t *testing.T) {
+	os.Unsetenv("NOTIFY_SOCKET")
+	n := NewNotifier()
+	assert.Nil(t, n)
+	assert.NoError(t, n.Notify(Ready), "A nil notifier should send nothing")
+	n.Report(Stopping, "stopping")
+	assert.Zero(t, n.WatchdogInterval())
+	n.RunWatchdog(func() bool { return true }, nil)
+}
+
+func TestNotify(t *testing.T) {
+	conn, cleanup := listen(t)
+	defer cleanup()
+
+	n := NewNotifier()
+	require.NotNil(t, n)
+	assert.Zero(t, n.WatchdogInterval())
+	n.Report(Ready, "serving")
+	assert.Equal(t, "READY=1\nSTATUS=serving", receive(t, conn))
+}
+
+func TestWatchdog(t *testing.T) {
+	conn, cleanup := listen(t)
+	defer cleanup()
+
+	os.Setenv("WATCHDOG_PID", "1")
+	assert.Zero(t, NewNotifier().WatchdogInterval(), "The watchdog of another process should be ignored")
+
+	os.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	os.Setenv("WATCHDOG_USEC", "20000")
+	n := NewNotifier()
+	assert.Equal(t, 20*time.Millisecond, n.WatchdogInterval())
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		n.RunWatchdog(func() bool { return true }, stop)
+		close(done)
+	}()
+	assert.Equal(t, Watchdog, receive(t, conn))
+	close(stop)
+	<-done
+
+	stop = make(chan struct{})
+	defer close(stop)
+	go n.RunWatchdog(func() bool { return false }, stop)
+	conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	_, err := conn.Read(make([]byte, 1024))
+	assert.Error(t, err, "The watchdog should not be pinged unless the process is alive")
+}
+
+func TestNotifyStates(t *testing.T) {
+	n := &Notifier{states: make(chan string, 1)}
+	n.Report(Ready, "serving")
+	assert.Equal(t, "READY=1\nSTATUS=serving", <-n.states)
+
+	assert.NoError(t, n.Notify(Stopping))
+	assert.EqualError(t, n.Notify(Stopping), "the service control handler is not accepting notifications")
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sdnotify
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// IsWindowsService reports whether the process was started by the Windows
+// service control manager, which it never is on other platforms
+func IsWindowsService() bool {
+	return false
+}
+
+// RunWindowsService fails, as Windows services only run on Windows
+func RunWindowsService(name string, run func(n *Notifier, stop <-chan struct{})) error {
+	return fmt.Errorf("Windows services are not supported on %s", runtime.GOOS)
+}
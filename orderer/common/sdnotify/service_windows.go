@@ -0,0 +1,93 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sdnotify
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// accepted are the requests of the service control manager which a running
+// service handles
+const accepted = svc.AcceptStop | svc.AcceptShutdown
+
+// IsWindowsService reports whether the process was started by the Windows
+// service control manager
+func IsWindowsService() bool {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		logger.Warningf("Failed to determine whether the process is a Windows service: %s", err)
+		return false
+	}
+	return !interactive
+}
+
+// RunWindowsService runs the process as the Windows service name, calling run
+// with the Notifier through which it reports its lifecycle, which the service
+// control handler translates into the states of the service, and a channel
+// closed once the service control manager asks the service to stop, whether
+// at the request of an administrator or because the system shuts down. It
+// returns once run has.
+func RunWindowsService(name string, run func(n *Notifier, stop <-chan struct{})) error {
+	return svc.Run(name, &handler{run: run})
+}
+
+// handler is the service control handler of the process
+type handler struct {
+	run func(n *Notifier, stop <-chan struct{})
+}
+
+// Execute runs the process, reporting the states it notifies and relaying
+// the requests of the service control manager until it returns
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	status := svc.Status{State: svc.StartPending}
+	changes <- status
+
+	n := &Notifier{states: make(chan string, 16)}
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.run(n, stop)
+	}()
+
+	for {
+		select {
+		case state := <-n.states:
+			// The state is the first of the notified assignments, which may
+			// be followed by a STATUS
+			switch strings.SplitN(state, "\n", 2)[0] {
+			case Ready:
+				status = svc.Status{State: svc.Running, Accepts: accepted}
+			case Stopping:
+				status = svc.Status{State: svc.StopPending}
+			default:
+				continue
+			}
+			changes <- status
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				changes <- status
+			case svc.Stop, svc.Shutdown:
+				status = svc.Status{State: svc.StopPending}
+				changes <- status
+				stopOnce.Do(func() { close(stop) })
+			default:
+				logger.Warningf("Ignoring unexpected request %d of the service control manager", request.Cmd)
+			}
+		case <-done:
+			return false, 0
+		}
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sdnotify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows/svc"
+)
+
+func receiveStatus(t *testing.T, changes <-chan svc.Status) svc.Status {
+	select {
+	case status := <-changes:
+		return status
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the status of the service")
+		return svc.Status{}
+	}
+}
+
+func TestServiceHandler(t *testing.T) {
+	requests := make(chan svc.ChangeRequest)
+	changes := make(chan svc.Status, 10)
+	h := &handler{run: func(n *Notifier, stop <-chan struct{}) {
+		n.Report(Ready, "serving")
+		<-stop
+		n.Report(Stopping, "draining")
+	}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Execute(nil, requests, changes)
+	}()
+
+	assert.Equal(t, svc.StartPending, receiveStatus(t, changes).State)
+	status := receiveStatus(t, changes)
+	assert.Equal(t, svc.Running, status.State, "The service should run once the process is ready")
+	assert.Equal(t, accepted, status.Accepts)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Interrogate}
+	assert.Equal(t, svc.Running, receiveStatus(t, changes).State)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Stop}
+	assert.Equal(t, svc.StopPending, receiveStatus(t, changes).State)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("The handler should return once the process stops")
+	}
+}
@@ -17,9 +17,15 @@ limitations under the License.
 package sigfilter
 
 import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/common/audit"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/identity"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
 
 	"github.com/op/go-logging"
 )
@@ -29,47 +35,94 @@ var logger = logging.MustGetLogger("orderer/common/sigfilter")
 type sigFilter struct {
 	policySource  string
 	policyManager policies.Manager
+	validator     *identity.Validator
+	trail         *audit.Trail
 }
 
 // New creates a new signature filter, at every evaluation, the policySource is called
 // just before evaluation to get the policy name to use when evaluating the filter
 // In general, both the policy name and the policy itself are mutable, this is why
 // not only the policy is retrieved at each invocation, but also the name of which
-// policy to retrieve
-func New(policySource string, policyManager policies.Manager) filter.Rule {
+// policy to retrieve. The messages denied are recorded in the audit trail,
+// unless it is nil.
+func New(policySource string, policyManager policies.Manager, trail *audit.Trail) filter.Rule {
 	return &sigFilter{
 		policySource:  policySource,
 		policyManager: policyManager,
+		trail:         trail,
+	}
+}
+
+// NewValidating creates a signature filter as New does, which validates the
+// creator of each message it rejects with the validator of the channel, so
+// that the rejections of a creator whose identity is not valid, such as one
+// revoked by the channel config, tell why rather than only that the policy
+// is not satisfied.
+func NewValidating(policySource string, policyManager policies.Manager, validator *identity.Validator, trail *audit.Trail) filter.Rule {
+	return &sigFilter{
+		policySource:  policySource,
+		policyManager: policyManager,
+		validator:     validator,
+		trail:         trail,
 	}
 }
 
 // Apply applies the policy given, resulting in Reject or Forward, never Accept and always with nil Committer
-func (sf *sigFilter) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
-	signedData, err := message.AsSignedData()
+func (sf *sigFilter) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := sf.Explain(message)
+	return action, committer
+}
 
-	if err != nil {
-		if logger.IsEnabledFor(logging.DEBUG) {
-			logger.Debugf("Rejecting because of err: %s", err)
-		}
-		return filter.Reject, nil
-	}
+// Explain applies the policy as Apply does, telling why the message is rejected
+func (sf *sigFilter) Explain(message *filter.Message) (filter.Action, filter.Committer, string) {
+	signedData := message.SignedData()
 
 	policy, ok := sf.policyManager.GetPolicy(sf.policySource)
 	if !ok {
-		if logger.IsEnabledFor(logging.DEBUG) {
-			logger.Debugf("Could not find policy %s", sf.policySource)
-		}
-		return filter.Reject, nil
+		logger.Warningf("Rejecting message because policy %s could not be found", sf.policySource)
+		return filter.Reject, nil, fmt.Sprintf("policy %s could not be found", sf.policySource)
 	}
 
-	err = policy.Evaluate(signedData)
+	err := policy.Evaluate(signedData)
 
 	if err == nil {
 		if logger.IsEnabledFor(logging.DEBUG) {
-			logger.Debugf("Forwarding validly signed message for policy %s", policy)
+			logger.Debugf("Forwarding validly signed message for policy %s", sf.policySource)
+		}
+		return filter.Forward, nil, ""
+	}
+
+	if creator := message.SignatureHeader.Creator; sf.validator != nil && len(creator) > 0 {
+		if _, invalid := sf.validator.Validate(creator); invalid != nil {
+			err = invalid
 		}
-		return filter.Forward, nil
 	}
+	logger.Warningf("Rejecting message which does not satisfy policy %s: %s", sf.policySource, err)
+	sf.trail.Record(audit.Event{
+		Type:    audit.AccessDenied,
+		Subject: creatorMSP(signedData),
+		Action:  sf.policySource,
+		Detail:  err.Error(),
+	})
+	return filter.Reject, nil, fmt.Sprintf("the message does not satisfy policy %s: %s", sf.policySource, err)
+}
+
+// RejectStatus answers the messages the filter rejects with FORBIDDEN, so that
+// clients can tell a message which is not signed by an authorized creator from
+// one which is malformed
+func (sf *sigFilter) RejectStatus() (cb.Status, string) {
+	return cb.Status_FORBIDDEN, "forbidden"
+}
 
-	return filter.Reject, nil
+// creatorMSP returns the MSP of the creator of the message, if it can be
+// determined
+func creatorMSP(signedData []*cb.SignedData) string {
+	if len(signedData) == 0 {
+		return ""
+	}
+	sid := &mspproto.SerializedIdentity{}
+	if err := proto.Unmarshal(signedData[0].Identity, sid); err != nil {
+		return ""
+	}
+	return sid.Mspid
 }
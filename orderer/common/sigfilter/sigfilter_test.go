@@ -21,8 +21,13 @@ import (
 	"testing"
 
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/audit"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/identity"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
 	"github.com/hyperledger/fabric/protos/utils"
 
 	"github.com/op/go-logging"
@@ -32,28 +37,92 @@ func init() {
 	logging.SetLevel(logging.DEBUG, "")
 }
 
-func makeEnvelope() *cb.Envelope {
-	return &cb.Envelope{
+func makeMessage(env *cb.Envelope) *filter.Message {
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		panic(err)
+	}
+	return msg
+}
+
+func makeEnvelope() *filter.Message {
+	return makeMessage(&cb.Envelope{
 		Payload: utils.MarshalOrPanic(&cb.Payload{
 			Header: &cb.Header{
 				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{}),
 			},
 		}),
-	}
+	})
 }
 
 func TestAccept(t *testing.T) {
 	mpm := &mockpolicies.Manager{Policy: &mockpolicies.Policy{}}
-	sf := New("foo", mpm)
+	sf := New("foo", mpm, nil)
 	result, _ := sf.Apply(makeEnvelope())
 	if result != filter.Forward {
 		t.Fatalf("Should have accepted envelope")
 	}
 }
 
+type mockIdentity struct {
+	msp.Identity
+}
+
+func (mi *mockIdentity) GetMSPIdentifier() string {
+	return "SampleOrg"
+}
+
+func (mi *mockIdentity) Validate() error {
+	return fmt.Errorf("certificate is revoked")
+}
+
+type mockManager struct {
+	msp.MSPManager
+}
+
+func (mm *mockManager) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	return &mockIdentity{}, nil
+}
+
+type mockSupport struct{}
+
+func (ms *mockSupport) MSPManager() msp.MSPManager {
+	return &mockManager{}
+}
+
+func (ms *mockSupport) Sequence() uint64 {
+	return 0
+}
+
+func TestInvalidCreator(t *testing.T) {
+	validator := identity.NewValidator(&mockSupport{}, identity.DefaultCacheSize)
+	msg := makeMessage(&cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte("creator")}),
+			},
+		}),
+	})
+
+	mpm := &mockpolicies.Manager{Policy: &mockpolicies.Policy{}}
+	result, _ := NewValidating("foo", mpm, validator, nil).Apply(msg)
+	if result != filter.Forward {
+		t.Fatalf("Should have left the message to the policy")
+	}
+
+	mpm = &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: fmt.Errorf("nope")}}
+	result, _, info := NewValidating("foo", mpm, validator, nil).(filter.ExplainingRule).Explain(msg)
+	if result != filter.Reject {
+		t.Fatalf("Should have rejected the message of an invalid creator")
+	}
+	if info != "the message does not satisfy policy foo: identity of MSP SampleOrg is not valid: certificate is revoked" {
+		t.Fatalf("Unexpected reason: %s", info)
+	}
+}
+
 func TestMissingPolicy(t *testing.T) {
 	mpm := &mockpolicies.Manager{}
-	sf := New("foo", mpm)
+	sf := New("foo", mpm, nil)
 	result, _ := sf.Apply(makeEnvelope())
 	if result != filter.Reject {
 		t.Fatalf("Should have rejected when missing policy")
@@ -61,19 +130,88 @@ func TestMissingPolicy(t *testing.T) {
 }
 
 func TestEmptyPayload(t *testing.T) {
-	mpm := &mockpolicies.Manager{Policy: &mockpolicies.Policy{}}
-	sf := New("foo", mpm)
-	result, _ := sf.Apply(&cb.Envelope{})
-	if result != filter.Reject {
+	// Messages with empty payloads are rejected before they are filtered
+	if _, err := filter.NewMessage(&cb.Envelope{}); err == nil {
 		t.Fatalf("Should have rejected when payload empty")
 	}
 }
 
 func TestErrorOnPolicy(t *testing.T) {
 	mpm := &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: fmt.Errorf("Error")}}
-	sf := New("foo", mpm)
+	sf := New("foo", mpm, nil)
 	result, _ := sf.Apply(makeEnvelope())
 	if result != filter.Reject {
 		t.Fatalf("Should have rejected when policy evaluated to err")
 	}
 }
+
+func TestExplain(t *testing.T) {
+	sf := New("foo", &mockpolicies.Manager{}, nil).(filter.ExplainingRule)
+	if _, _, info := sf.Explain(makeEnvelope()); info != "policy foo could not be found" {
+		t.Fatalf("Should have explained the missing policy, got %q", info)
+	}
+
+	sf = New("foo", &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: fmt.Errorf("Error")}}, nil).(filter.ExplainingRule)
+	if _, _, info := sf.Explain(makeEnvelope()); info != "the message does not satisfy policy foo: Error" {
+		t.Fatalf("Should have explained the policy error, got %q", info)
+	}
+}
+
+func TestPolicyResolvedOnEachApply(t *testing.T) {
+	mpm := &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{"foo": &mockpolicies.Policy{}}}
+	sf := New("foo", mpm, nil)
+	if result, _ := sf.Apply(makeEnvelope()); result != filter.Forward {
+		t.Fatalf("Should have accepted envelope")
+	}
+
+	mpm.PolicyMap["foo"] = &mockpolicies.Policy{Err: fmt.Errorf("Error")}
+	if result, _ := sf.Apply(makeEnvelope()); result != filter.Reject {
+		t.Fatalf("Should have rejected envelope after the policy changed")
+	}
+}
+
+type auditSink struct {
+	events []audit.Event
+}
+
+func (as *auditSink) Write(entry *audit.Entry) error {
+	as.events = append(as.events, entry.Event)
+	return nil
+}
+
+func TestDenialAudited(t *testing.T) {
+	sink := &auditSink{}
+	trail := audit.NewTrail(nil, sink)
+
+	env := &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{
+					Creator: utils.MarshalOrPanic(&mspproto.SerializedIdentity{Mspid: "SampleOrg"}),
+				}),
+			},
+		}),
+	}
+	mpm := &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: fmt.Errorf("Error")}}
+	New("foo", mpm, trail).Apply(makeMessage(env))
+
+	if len(sink.events) != 1 {
+		t.Fatalf("Expected the denial to be audited, got %d events", len(sink.events))
+	}
+	if event := sink.events[0]; event.Type != audit.AccessDenied || event.Subject != "SampleOrg" || event.Action != "foo" {
+		t.Fatalf("Unexpected audit event %+v", event)
+	}
+}
+
+func TestRejectStatus(t *testing.T) {
+	mpm := &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: fmt.Errorf("Error")}}
+	rs := filter.NewRuleSet([]filter.Rule{New("foo", mpm, nil)})
+	_, err := rs.Apply(makeEnvelope())
+	rejection, ok := err.(*filter.Rejection)
+	if !ok {
+		t.Fatalf("Expected a rejection, got %v", err)
+	}
+	if rejection.Status != cb.Status_FORBIDDEN || rejection.Reason != "forbidden" {
+		t.Fatalf("Unexpected rejection %+v", rejection)
+	}
+}
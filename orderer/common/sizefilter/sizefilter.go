@@ -17,6 +17,8 @@ limitations under the License.
 package sizefilter
 
 import (
+	"fmt"
+
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -30,7 +32,8 @@ type Support interface {
 	BatchSize() *ab.BatchSize
 }
 
-// MaxBytesRule rejects messages larger than maxBytes
+// MaxBytesRule rejects messages larger than the AbsoluteMaxBytes of the batch
+// size of the chain, as currently configured, with REQUEST_ENTITY_TOO_LARGE
 func MaxBytesRule(support Support) filter.Rule {
 	return &maxBytesRule{support: support}
 }
@@ -39,15 +42,21 @@ type maxBytesRule struct {
 	support Support
 }
 
-func (r *maxBytesRule) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
+func (r *maxBytesRule) Apply(message *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := r.Explain(message)
+	return action, committer
+}
+
+func (r *maxBytesRule) Explain(message *filter.Message) (filter.Action, filter.Committer, string) {
 	maxBytes := r.support.BatchSize().AbsoluteMaxBytes
-	if size := messageByteSize(message); size > maxBytes {
-		logger.Warningf("%d byte message payload exceeds maximum allowed %d bytes", size, maxBytes)
-		return filter.Reject, nil
+	if size := message.Size(); size > maxBytes {
+		info := fmt.Sprintf("%d byte message payload exceeds maximum allowed %d bytes", size, maxBytes)
+		logger.Warning(info)
+		return filter.Reject, nil, info
 	}
-	return filter.Forward, nil
+	return filter.Forward, nil, ""
 }
 
-func messageByteSize(message *cb.Envelope) uint32 {
-	return uint32(len(message.Payload) + len(message.Signature))
+func (r *maxBytesRule) RejectStatus() (cb.Status, string) {
+	return cb.Status_REQUEST_ENTITY_TOO_LARGE, "too_large"
 }
@@ -48,17 +48,29 @@ func TestMaxBytesRule(t *testing.T) {
 		if err == nil {
 			t.Fatalf("Should have rejected")
 		}
+		rejection, ok := err.(*filter.Rejection)
+		if !ok || rejection.Status != cb.Status_REQUEST_ENTITY_TOO_LARGE {
+			t.Fatalf("Should have rejected as too large, got %v", err)
+		}
+	})
+	t.Run("Reconfigured", func(t *testing.T) {
+		support := &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{AbsoluteMaxBytes: maxBytes}}
+		rule := MaxBytesRule(support)
+		support.BatchSizeVal = &ab.BatchSize{AbsoluteMaxBytes: maxBytes - 1}
+		if action, _ := rule.Apply(makeMessage(make([]byte, dataSize))); action != filter.Reject {
+			t.Fatalf("Should have rejected under the current config of the chain")
+		}
 	})
 }
 
 func calcMessageBytesForPayloadDataSize(dataSize uint32) uint32 {
-	return messageByteSize(makeMessage(make([]byte, dataSize)))
+	return makeMessage(make([]byte, dataSize)).Size()
 }
 
-func makeMessage(data []byte) *cb.Envelope {
+func makeMessage(data []byte) *filter.Message {
 	data, err := proto.Marshal(&cb.Payload{Data: data})
 	if err != nil {
 		panic(err)
 	}
-	return &cb.Envelope{Payload: data}
+	return &filter.Message{Envelope: &cb.Envelope{Payload: data}}
 }
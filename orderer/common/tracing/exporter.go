@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// LogExporter logs each span, as JSON, at INFO level
+type LogExporter struct{}
+
+// Export implements the Exporter interface
+func (LogExporter) Export(spans []*Span) error {
+	for _, span := range spans {
+		line, err := json.Marshal(span)
+		if err != nil {
+			return err
+		}
+		logger.Infof("%s", line)
+	}
+	return nil
+}
+
+// FileExporter appends each span, as a line of JSON, to a file
+type FileExporter struct {
+	lock   sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewFileExporter opens the file, creating it if it does not exist, to
+// append the spans exported to it
+func NewFileExporter(path string) (*FileExporter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %s", err)
+	}
+	return &FileExporter{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Export implements the Exporter interface. The spans of a transaction are
+// written together, and flushed to the file.
+func (fe *FileExporter) Export(spans []*Span) error {
+	fe.lock.Lock()
+	defer fe.lock.Unlock()
+	encoder := json.NewEncoder(fe.writer)
+	for _, span := range spans {
+		if err := encoder.Encode(span); err != nil {
+			return err
+		}
+	}
+	return fe.writer.Flush()
+}
+
+// Close flushes and closes the file
+func (fe *FileExporter) Close() error {
+	fe.lock.Lock()
+	defer fe.lock.Unlock()
+	if err := fe.writer.Flush(); err != nil {
+		fe.file.Close()
+		return err
+	}
+	return fe.file.Close()
+}
@@ -0,0 +1,293 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"encoding/hex"
+	"io"
+	mrand "math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/tracing")
+
+// The names of the spans of a transaction. The transaction span lasts from
+// the receipt of the transaction to the append of the block containing it,
+// and is the parent of the spans of each stage.
+const (
+	// SpanTransaction is the span of the whole transaction
+	SpanTransaction = "orderer.transaction"
+	// SpanFilter is the time from receipt to passing the broadcast filters
+	SpanFilter = "orderer.broadcast.filter"
+	// SpanEnqueue is the time taken to hand the transaction to the consenter
+	SpanEnqueue = "orderer.broadcast.enqueue"
+	// SpanBlockCutter is the time from enqueue until the block cutter cuts
+	// the batch containing the transaction
+	SpanBlockCutter = "orderer.blockcutter"
+	// SpanConsensus is the time from the cut of the batch until the block is
+	// committed by the consenter
+	SpanConsensus = "orderer.consensus"
+	// SpanLedgerAppend is the time taken to append the block to the ledger
+	SpanLedgerAppend = "orderer.ledger.append"
+)
+
+// DefaultExpiry is how long a transaction is traced before it is assumed to
+// have been dropped by the consenter
+const DefaultExpiry = 5 * time.Minute
+
+// Span is a finished span, as exported
+type Span struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Exporter exports the spans of each traced transaction once the block
+// containing it is appended
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Config holds the sampling of the transactions traced
+type Config struct {
+	// SampleRate is the fraction of the transactions received without a
+	// trace context which start a trace. Transactions received with one are
+	// traced if the client sampled its span.
+	SampleRate float64
+	// Expiry is how long a transaction is traced before it is forgotten,
+	// DefaultExpiry if it is not positive
+	Expiry time.Duration
+}
+
+type trace struct {
+	context   SpanContext
+	parent    *SpanID
+	chainID   string
+	txHash    string
+	received  time.Time
+	filtered  time.Time
+	enqueue   time.Time
+	enqueued  time.Time
+	batched   time.Time
+	committed time.Time
+}
+
+// Tracer holds the traces of the sampled transactions which have been
+// received but not yet appended. A nil Tracer traces nothing.
+type Tracer struct {
+	config   Config
+	exporter Exporter
+
+	lock      sync.Mutex
+	pending   map[string]*trace
+	lastSweep time.Time
+}
+
+// NewTracer creates a Tracer exporting its spans to the exporter
+func NewTracer(config Config, exporter Exporter) *Tracer {
+	if config.Expiry <= 0 {
+		config.Expiry = DefaultExpiry
+	}
+	return &Tracer{
+		config:    config,
+		exporter:  exporter,
+		pending:   make(map[string]*trace),
+		lastSweep: time.Now(),
+	}
+}
+
+// Received starts tracing the transaction with the key, which was received
+// and passed the filters at the given times, if it is sampled. A transaction
+// received with the span context of its client joins its trace, and is
+// sampled if the client's span is.
+func (t *Tracer) Received(parent *SpanContext, chainID string, key string, received, filtered time.Time) {
+	if t == nil {
+		return
+	}
+	tr := &trace{chainID: chainID, txHash: key, received: received, filtered: filtered}
+	if parent != nil {
+		if !parent.Sampled {
+			return
+		}
+		parentID := parent.SpanID
+		tr.context.TraceID, tr.parent = parent.TraceID, &parentID
+	} else {
+		if t.config.SampleRate <= 0 || mrand.Float64() >= t.config.SampleRate {
+			return
+		}
+		tr.context.TraceID = newTraceID()
+	}
+	tr.context.SpanID, tr.context.Sampled = newSpanID(), true
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.sweep(filtered)
+	t.pending[key] = tr
+}
+
+// Enqueued records that the transaction was handed to the consenter over the
+// given times
+func (t *Tracer) Enqueued(key string, start, end time.Time) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if tr, ok := t.pending[key]; ok {
+		tr.enqueue, tr.enqueued = start, end
+	}
+}
+
+// Forget stops tracing a transaction which will not be ordered
+func (t *Tracer) Forget(key string) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.pending, key)
+}
+
+// Batched records that the block cutter cut the batch at the given time.
+// The transactions of the batch are only hashed while some are traced.
+func (t *Tracer) Batched(batch []*filter.Message, at time.Time) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	tracing := len(t.pending) > 0
+	t.lock.Unlock()
+	if !tracing {
+		return
+	}
+
+	keys := make([]string, 0, len(batch))
+	for _, msg := range batch {
+		if hash := msg.Hash(); hash != nil {
+			keys = append(keys, string(hash))
+		}
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, key := range keys {
+		if tr, ok := t.pending[key]; ok && tr.batched.IsZero() {
+			tr.batched = at
+		}
+	}
+}
+
+// Appended exports the spans of each traced transaction of the block, which
+// was committed by the consenter and appended to the ledger at the given
+// times
+func (t *Tracer) Appended(block *cb.Block, committed, appended time.Time) {
+	if t == nil || block.GetData() == nil {
+		return
+	}
+	t.lock.Lock()
+	if len(t.pending) == 0 {
+		t.lock.Unlock()
+		return
+	}
+	var traces []*trace
+	for _, data := range block.Data.Data {
+		key := string(util.ComputeSHA256(data))
+		if tr, ok := t.pending[key]; ok {
+			traces = append(traces, tr)
+			delete(t.pending, key)
+		}
+	}
+	t.lock.Unlock()
+
+	for _, tr := range traces {
+		tr.committed = committed
+		if err := t.exporter.Export(tr.spans(block.GetHeader().GetNumber(), appended)); err != nil {
+			logger.Warningf("Failed to export the spans of transaction %x: %s", tr.txHash, err)
+		}
+	}
+}
+
+// spans returns the spans of the transaction, which was appended in the
+// block at the given time
+func (tr *trace) spans(blockNumber uint64, appended time.Time) []*Span {
+	// A transaction may be cut into a batch, or even committed, before
+	// Enqueue returns
+	if tr.enqueue.IsZero() {
+		tr.enqueue = tr.filtered
+	}
+	if tr.enqueued.IsZero() || tr.enqueued.After(tr.committed) {
+		tr.enqueued = tr.committed
+	}
+	if tr.batched.IsZero() || tr.batched.Before(tr.enqueue) {
+		tr.batched = tr.enqueued
+	}
+
+	traceID := hex.EncodeToString(tr.context.TraceID[:])
+	rootID := hex.EncodeToString(tr.context.SpanID[:])
+	root := &Span{
+		TraceID: traceID,
+		SpanID:  rootID,
+		Name:    SpanTransaction,
+		Start:   tr.received,
+		End:     appended,
+		Attributes: map[string]string{
+			"channel":      tr.chainID,
+			"tx_hash":      hex.EncodeToString([]byte(tr.txHash)),
+			"block_number": strconv.FormatUint(blockNumber, 10),
+		},
+	}
+	if tr.parent != nil {
+		root.ParentID = hex.EncodeToString(tr.parent[:])
+	}
+	child := func(name string, start, end time.Time) *Span {
+		id := newSpanID()
+		return &Span{TraceID: traceID, SpanID: hex.EncodeToString(id[:]), ParentID: rootID, Name: name, Start: start, End: end}
+	}
+	return []*Span{
+		root,
+		child(SpanFilter, tr.received, tr.filtered),
+		child(SpanEnqueue, tr.enqueue, tr.enqueued),
+		child(SpanBlockCutter, tr.enqueue, tr.batched),
+		child(SpanConsensus, tr.batched, tr.committed),
+		child(SpanLedgerAppend, tr.committed, appended),
+	}
+}
+
+// sweep forgets expired transactions, at most once per expiry, so that those
+// dropped by the consenter do not accumulate. The lock must be held.
+func (t *Tracer) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.config.Expiry {
+		return
+	}
+	t.lastSweep = now
+	for key, tr := range t.pending {
+		if now.Sub(tr.received) > t.config.Expiry {
+			delete(t.pending, key)
+		}
+	}
+}
+
+// Close closes the exporter of the tracer, if it is an io.Closer
+func (t *Tracer) Close() error {
+	if t == nil {
+		return nil
+	}
+	if closer, ok := t.exporter.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockExporter struct {
+	spans [][]*Span
+}
+
+func (me *mockExporter) Export(spans []*Span) error {
+	me.spans = append(me.spans, spans)
+	return nil
+}
+
+func key(t *testing.T, env *cb.Envelope) string {
+	hash, err := pool.Hash(env)
+	assert.NoError(t, err)
+	return string(hash)
+}
+
+func byName(spans []*Span) map[string]*Span {
+	named := make(map[string]*Span)
+	for _, span := range spans {
+		named[span.Name] = span
+	}
+	return named
+}
+
+func TestAppended(t *testing.T) {
+	exporter := &mockExporter{}
+	tracer := NewTracer(Config{}, exporter)
+	env := &cb.Envelope{Payload: []byte("tx1")}
+	other := &cb.Envelope{Payload: []byte("tx2")}
+	parent, err := Parse(sampleParent)
+	assert.NoError(t, err)
+
+	received := time.Now()
+	tracer.Received(&parent, "tracingchain", key(t, env), received, received.Add(time.Millisecond))
+	tracer.Received(nil, "tracingchain", key(t, other), received, received.Add(time.Millisecond))
+	assert.Len(t, tracer.pending, 1, "Only the transaction of a sampled client span should be traced with no sample rate")
+	tracer.Enqueued(key(t, env), received.Add(time.Millisecond), received.Add(2*time.Millisecond))
+	tracer.Batched([]*filter.Message{{Envelope: env}, {Envelope: other}}, received.Add(3*time.Millisecond))
+
+	block := cb.NewBlock(7, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env), utils.MarshalOrPanic(other)}
+	tracer.Appended(block, received.Add(time.Second), received.Add(2*time.Second))
+	assert.Empty(t, tracer.pending)
+
+	if !assert.Len(t, exporter.spans, 1) {
+		return
+	}
+	spans := byName(exporter.spans[0])
+	assert.Len(t, spans, 6)
+	root := spans[SpanTransaction]
+	if !assert.NotNil(t, root) {
+		return
+	}
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", root.TraceID, "The spans should join the trace of the client")
+	assert.Equal(t, "00f067aa0ba902b7", root.ParentID)
+	assert.Equal(t, "tracingchain", root.Attributes["channel"])
+	assert.Equal(t, "7", root.Attributes["block_number"])
+	assert.Equal(t, received, root.Start)
+	assert.Equal(t, received.Add(2*time.Second), root.End)
+	for _, name := range []string{SpanFilter, SpanEnqueue, SpanBlockCutter, SpanConsensus, SpanLedgerAppend} {
+		span := spans[name]
+		if assert.NotNil(t, span, name) {
+			assert.Equal(t, root.TraceID, span.TraceID, name)
+			assert.Equal(t, root.SpanID, span.ParentID, name)
+		}
+	}
+	assert.Equal(t, received.Add(3*time.Millisecond), spans[SpanBlockCutter].End)
+	assert.Equal(t, received.Add(3*time.Millisecond), spans[SpanConsensus].Start)
+	assert.Equal(t, received.Add(time.Second), spans[SpanLedgerAppend].Start)
+}
+
+func TestSampling(t *testing.T) {
+	exporter := &mockExporter{}
+	tracer := NewTracer(Config{SampleRate: 1}, exporter)
+	env := &cb.Envelope{Payload: []byte("tx1")}
+	unsampled, err := Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	tracer.Received(&unsampled, "tracingchain", key(t, env), now, now)
+	assert.Empty(t, tracer.pending, "The decision of the client not to sample its span should be followed")
+
+	tracer.Received(nil, "tracingchain", key(t, env), now, now)
+	assert.Len(t, tracer.pending, 1, "Every transaction should be traced at a sample rate of 1")
+	tracer.Forget(key(t, env))
+	assert.Empty(t, tracer.pending)
+}
+
+func TestExpiry(t *testing.T) {
+	tracer := NewTracer(Config{SampleRate: 1, Expiry: time.Minute}, &mockExporter{})
+	old := time.Now().Add(-time.Hour)
+	tracer.Received(nil, "tracingchain", "old", old, old)
+	tracer.Received(nil, "tracingchain", "new", time.Now(), time.Now().Add(2*time.Minute))
+	assert.Len(t, tracer.pending, 1, "The expired transaction should have been forgotten")
+	assert.Contains(t, tracer.pending, "new")
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	env := &cb.Envelope{Payload: []byte("tx1")}
+	now := time.Now()
+	tracer.Received(nil, "tracingchain", key(t, env), now, now)
+	tracer.Enqueued(key(t, env), now, now)
+	tracer.Batched([]*filter.Message{{Envelope: env}}, now)
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+	tracer.Appended(block, now, now)
+	tracer.Forget(key(t, env))
+	assert.NoError(t, tracer.Close())
+}
+
+func TestFileExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spans")
+
+	exporter, err := NewFileExporter(path)
+	assert.NoError(t, err)
+	tracer := NewTracer(Config{SampleRate: 1}, exporter)
+	env := &cb.Envelope{Payload: []byte("tx1")}
+	now := time.Now()
+	tracer.Received(nil, "tracingchain", key(t, env), now, now)
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+	tracer.Appended(block, now, now)
+	assert.NoError(t, tracer.Close())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	var spans []*Span
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		span := &Span{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), span))
+		spans = append(spans, span)
+	}
+	assert.Len(t, spans, 6, "Each span should be written on its own line")
+	assert.NotNil(t, byName(spans)[SpanTransaction])
+
+	_, err = NewFileExporter(filepath.Join(dir, "missing", "spans"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tracing follows sampled transactions through the orderer, from
+// their receipt by Broadcast, through the consenter and the block cutter, to
+// the append of the block containing them, and exports a span for each
+// stage. Clients continue their traces into the orderer by sending the
+// context of their span in the traceparent gRPC metadata, in the format of
+// the W3C Trace Context, in which case the spans of the orderer join their
+// trace.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the gRPC metadata key carrying the context of the span of the
+// client
+const Header = "traceparent"
+
+// version is the only version of the traceparent format supported
+const version = "00"
+
+// sampledFlag is set in the trace flags of the context of a sampled span
+const sampledFlag = 0x01
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within its trace
+type SpanID [8]byte
+
+// SpanContext is the context of a span propagated to the spans it causes
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Sampled is whether the spans of the trace are recorded
+	Sampled bool
+}
+
+// String returns the context in the traceparent format
+func (sc SpanContext) String() string {
+	var flags byte
+	if sc.Sampled {
+		flags |= sampledFlag
+	}
+	return fmt.Sprintf("%s-%s-%s-%02x", version, hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// Parse parses a context in the traceparent format
+func Parse(traceparent string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 {
+		return SpanContext{}, fmt.Errorf("traceparent %q does not have 4 fields", traceparent)
+	}
+	if parts[0] != version {
+		return SpanContext{}, fmt.Errorf("unsupported traceparent version %q", parts[0])
+	}
+	var sc SpanContext
+	if err := decode(sc.TraceID[:], parts[1]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace ID: %s", err)
+	}
+	if err := decode(sc.SpanID[:], parts[2]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid span ID: %s", err)
+	}
+	var flags [1]byte
+	if err := decode(flags[:], parts[3]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace flags: %s", err)
+	}
+	if sc.TraceID == (TraceID{}) || sc.SpanID == (SpanID{}) {
+		return SpanContext{}, fmt.Errorf("traceparent %q has an all zero ID", traceparent)
+	}
+	sc.Sampled = flags[0]&sampledFlag != 0
+	return sc, nil
+}
+
+// decode decodes the hex string into dst, which it must fill exactly
+func decode(dst []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dst)) {
+		return fmt.Errorf("%q is not %d hex digits", s, hex.EncodedLen(len(dst)))
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// FromIncomingContext returns the context of the span of the client of the
+// RPC, if it sent a valid one
+func FromIncomingContext(ctx context.Context) (SpanContext, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[Header]) == 0 {
+		return SpanContext{}, false
+	}
+	sc, err := Parse(md[Header][0])
+	if err != nil {
+		logger.Debugf("Ignoring trace context of client: %s", err)
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// NewOutgoingContext returns a context whose RPCs carry the span context, so
+// that the spans of the server join its trace
+func NewOutgoingContext(ctx context.Context, sc SpanContext) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md[Header] = []string{sc.String()}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	random(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	random(id[:])
+	return id
+}
+
+func random(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("failed to read random bytes: %s", err))
+	}
+}
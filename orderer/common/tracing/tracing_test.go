@@ -0,0 +1,76 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+const sampleParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParse(t *testing.T) {
+	sc, err := Parse(sampleParent)
+	assert.NoError(t, err)
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, byte(0x4b), sc.TraceID[0])
+	assert.Equal(t, byte(0xb7), sc.SpanID[7])
+	assert.Equal(t, sampleParent, sc.String())
+
+	sc, err = Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	assert.NoError(t, err)
+	assert.False(t, sc.Sampled)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, traceparent := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	} {
+		_, err := Parse(traceparent)
+		assert.Error(t, err, traceparent)
+	}
+}
+
+func TestIncomingContext(t *testing.T) {
+	_, ok := FromIncomingContext(context.Background())
+	assert.False(t, ok, "No span context without metadata")
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "garbage"))
+	_, ok = FromIncomingContext(ctx)
+	assert.False(t, ok, "An invalid span context should be ignored")
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, sampleParent))
+	sc, ok := FromIncomingContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, sampleParent, sc.String())
+}
+
+func TestOutgoingContext(t *testing.T) {
+	sc, err := Parse(sampleParent)
+	assert.NoError(t, err)
+
+	parent := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("other", "value"))
+	ctx := NewOutgoingContext(parent, sc)
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{sampleParent}, md[Header])
+	assert.Equal(t, []string{"value"}, md["other"])
+	md, _ = metadata.FromOutgoingContext(parent)
+	assert.Empty(t, md[Header], "The metadata of the parent context should not be modified")
+
+	md, _ = metadata.FromOutgoingContext(NewOutgoingContext(context.Background(), sc))
+	assert.Equal(t, []string{sampleParent}, md[Header])
+}
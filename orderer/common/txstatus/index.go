@@ -0,0 +1,312 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package txstatus answers clients asking for the fate of a transaction they
+// submitted: whether it is pending on this orderer, committed in a block, or
+// unknown. Each chain indexes the transaction IDs and envelope hashes of its
+// most recently committed transactions, restored from the ledger on startup,
+// along with the transactions this orderer enqueued which are not committed
+// yet. Transactions submitted to several chains as a group, tagged with a
+// group ID in the orderer extension of their channel headers, are also
+// indexed by group, so that their inclusion on each chain may be queried.
+package txstatus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// Config is the configuration of the Index of each chain
+type Config struct {
+	// Size is the number of the most recently committed transactions indexed
+	Size int
+	// PendingExpiry is how long an enqueued transaction is reported as
+	// pending before it is assumed to have been dropped by the consenter
+	PendingExpiry time.Duration
+}
+
+// location is the position of a committed transaction on the chain
+type location struct {
+	blockNumber uint64
+	txIndex     uint32
+}
+
+// entry is a committed transaction, indexed by each of its keys and by its
+// group, if any
+type entry struct {
+	keys  []string
+	txID  string
+	group string
+	loc   location
+}
+
+// Index tracks the fate of the transactions of a chain, by transaction ID
+// and by envelope hash. A nil Index tracks nothing.
+type Index struct {
+	config Config
+
+	lock      sync.Mutex
+	pending   map[string]time.Time
+	committed map[string]location
+	groups    map[string][]entry
+	// order holds the committed transactions, oldest first, so that the
+	// oldest are forgotten once more than Size are indexed
+	order     []entry
+	lastSweep time.Time
+	// watchers are the channels notified of the commit of the transactions
+	// with each envelope hash
+	watchers map[string][]chan *ab.TransactionStatusResponse
+}
+
+// NewIndex creates an empty Index
+func NewIndex(config Config) *Index {
+	return &Index{
+		config:    config,
+		pending:   make(map[string]time.Time),
+		committed: make(map[string]location),
+		groups:    make(map[string][]entry),
+		lastSweep: time.Now(),
+		watchers:  make(map[string][]chan *ab.TransactionStatusResponse),
+	}
+}
+
+func txIDKey(txID string) string {
+	return "id:" + txID
+}
+
+func txHashKey(txHash []byte) string {
+	return "hash:" + string(txHash)
+}
+
+// Restore indexes the most recently committed transactions of the chain, so
+// that those committed before a restart are known
+func (i *Index) Restore(reader ledger.Reader) {
+	if i == nil {
+		return
+	}
+	var blocks []*cb.Block
+	txs := 0
+	for number := reader.Height(); number > 0 && txs < i.config.Size; number-- {
+		block := ledger.GetBlock(reader, number-1)
+		if block == nil {
+			continue
+		}
+		blocks = append(blocks, block)
+		txs += len(block.GetData().GetData())
+	}
+	for j := len(blocks) - 1; j >= 0; j-- {
+		i.Appended(blocks[j])
+	}
+}
+
+// Enqueued records that the message was enqueued for ordering by this
+// orderer
+func (i *Index) Enqueued(msg *filter.Message) {
+	if i == nil || i.config.PendingExpiry <= 0 {
+		return
+	}
+	now := time.Now()
+	hash := msg.Hash()
+	if hash == nil {
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.sweep(now)
+	i.pending[txHashKey(hash)] = now
+	if txID := msg.ChannelHeader.GetTxId(); txID != "" {
+		i.pending[txIDKey(txID)] = now
+	}
+}
+
+// Watch returns a channel which receives the COMMITTED status of the
+// transaction with the envelope hash once a block containing it is appended,
+// and a function to call once done watching. The transaction is watched
+// whether or not committed transactions are indexed, and the channel of a
+// nil Index never receives.
+func (i *Index) Watch(txHash []byte) (<-chan *ab.TransactionStatusResponse, func()) {
+	if i == nil {
+		return nil, func() {}
+	}
+	key := txHashKey(txHash)
+	c := make(chan *ab.TransactionStatusResponse, 1)
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.watchers[key] = append(i.watchers[key], c)
+	return c, func() {
+		i.lock.Lock()
+		defer i.lock.Unlock()
+		watchers := i.watchers[key]
+		for j := range watchers {
+			if watchers[j] == c {
+				watchers = append(watchers[:j], watchers[j+1:]...)
+				break
+			}
+		}
+		if len(watchers) == 0 {
+			delete(i.watchers, key)
+		} else {
+			i.watchers[key] = watchers
+		}
+	}
+}
+
+// notify sends the status of the transaction committed at the location to
+// the watchers of the key, which stop watching it. The lock must be held.
+func (i *Index) notify(key string, loc location) {
+	for _, c := range i.watchers[key] {
+		c <- &ab.TransactionStatusResponse{
+			State:       ab.TransactionStatusResponse_COMMITTED,
+			BlockNumber: loc.blockNumber,
+			TxIndex:     loc.txIndex,
+		}
+	}
+	delete(i.watchers, key)
+}
+
+// Appended indexes the transactions of the block appended to the ledger
+func (i *Index) Appended(block *cb.Block) {
+	if i == nil || block.GetData() == nil {
+		return
+	}
+	if i.config.Size <= 0 {
+		i.lock.Lock()
+		defer i.lock.Unlock()
+		if len(i.watchers) == 0 {
+			return
+		}
+		for j, data := range block.Data.Data {
+			i.notify(txHashKey(util.ComputeSHA256(data)), location{blockNumber: block.Header.Number, txIndex: uint32(j)})
+		}
+		return
+	}
+	entries := make([]entry, len(block.Data.Data))
+	for j, data := range block.Data.Data {
+		entries[j].loc = location{blockNumber: block.Header.Number, txIndex: uint32(j)}
+		entries[j].keys = append(entries[j].keys, txHashKey(util.ComputeSHA256(data)))
+		if msg := decode(data); msg != nil {
+			entries[j].txID, entries[j].group = msg.ChannelHeader.TxId, msg.Extension.GroupId
+		}
+		if entries[j].txID != "" {
+			entries[j].keys = append(entries[j].keys, txIDKey(entries[j].txID))
+		}
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, e := range entries {
+		for _, key := range e.keys {
+			delete(i.pending, key)
+			i.committed[key] = e.loc
+		}
+		i.notify(e.keys[0], e.loc)
+		if e.group != "" {
+			i.groups[e.group] = append(i.groups[e.group], e)
+		}
+	}
+	i.order = append(i.order, entries...)
+	for len(i.order) > i.config.Size {
+		oldest := i.order[0]
+		for _, key := range oldest.keys {
+			// A later transaction with the same ID remains indexed
+			if i.committed[key] == oldest.loc {
+				delete(i.committed, key)
+			}
+		}
+		// The transactions of a group are indexed in order, so the oldest
+		// is the first of its group
+		if oldest.group != "" {
+			if group := i.groups[oldest.group][1:]; len(group) > 0 {
+				i.groups[oldest.group] = group
+			} else {
+				delete(i.groups, oldest.group)
+			}
+		}
+		i.order[0] = entry{}
+		i.order = i.order[1:]
+	}
+}
+
+// decode decodes the marshaled envelope, returning nil if it is malformed
+func decode(data []byte) *filter.Message {
+	env := &cb.Envelope{}
+	if err := pool.Unmarshal(data, env); err != nil {
+		return nil
+	}
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		return nil
+	}
+	return msg
+}
+
+// Status returns the fate of the transaction with the ID, or the envelope
+// hash if the ID is empty
+func (i *Index) Status(req *ab.TransactionStatusRequest) *ab.TransactionStatusResponse {
+	if i == nil {
+		return &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_UNKNOWN}
+	}
+	key := txHashKey(req.TxHash)
+	if req.TxId != "" {
+		key = txIDKey(req.TxId)
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if loc, ok := i.committed[key]; ok {
+		return &ab.TransactionStatusResponse{
+			State:       ab.TransactionStatusResponse_COMMITTED,
+			BlockNumber: loc.blockNumber,
+			TxIndex:     loc.txIndex,
+		}
+	}
+	if enqueued, ok := i.pending[key]; ok && time.Since(enqueued) <= i.config.PendingExpiry {
+		return &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_PENDING}
+	}
+	return &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_UNKNOWN}
+}
+
+// Group returns the inclusions of the committed transactions of the group,
+// in order
+func (i *Index) Group(groupID string) []*ab.TransactionGroupResponse_Inclusion {
+	if i == nil || groupID == "" {
+		return nil
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	group := i.groups[groupID]
+	inclusions := make([]*ab.TransactionGroupResponse_Inclusion, len(group))
+	for j, e := range group {
+		inclusions[j] = &ab.TransactionGroupResponse_Inclusion{
+			BlockNumber: e.loc.blockNumber,
+			TxIndex:     e.loc.txIndex,
+			TxId:        e.txID,
+		}
+	}
+	return inclusions
+}
+
+// sweep forgets expired pending transactions, at most once per expiry, so
+// that those dropped by the consenter do not accumulate
+func (i *Index) sweep(now time.Time) {
+	if now.Sub(i.lastSweep) < i.config.PendingExpiry {
+		return
+	}
+	i.lastSweep = now
+	for key, enqueued := range i.pending {
+		if now.Sub(enqueued) > i.config.PendingExpiry {
+			delete(i.pending, key)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txstatus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTx(t *testing.T, txID string) *filter.Message {
+	return makeGroupTx(t, txID, "")
+}
+
+func makeGroupTx(t *testing.T, txID, groupID string) *filter.Message {
+	chdr := utils.MakeChannelHeader(cb.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0)
+	chdr.TxId = txID
+	if groupID != "" {
+		chdr.OrdererExtension = utils.MarshalOrPanic(&ab.OrdererHeaderExtension{GroupId: groupID})
+	}
+	msg, err := filter.NewMessage(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(chdr, &cb.SignatureHeader{}),
+		Data:   []byte(txID),
+	})})
+	require.NoError(t, err)
+	return msg
+}
+
+func makeBlock(number uint64, msgs ...*filter.Message) *cb.Block {
+	block := cb.NewBlock(number, nil)
+	for _, msg := range msgs {
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(msg.Envelope))
+	}
+	return block
+}
+
+func byID(txID string) *ab.TransactionStatusRequest {
+	return &ab.TransactionStatusRequest{TxId: txID}
+}
+
+func TestIndex(t *testing.T) {
+	i := NewIndex(Config{Size: 10, PendingExpiry: time.Minute})
+	foo, bar := makeTx(t, "foo"), makeTx(t, "bar")
+
+	assert.Equal(t, ab.TransactionStatusResponse_UNKNOWN, i.Status(byID("foo")).State)
+
+	i.Enqueued(foo)
+	i.Enqueued(bar)
+	assert.Equal(t, ab.TransactionStatusResponse_PENDING, i.Status(byID("foo")).State)
+	hash, err := pool.Hash(foo.Envelope)
+	require.NoError(t, err)
+	assert.Equal(t, ab.TransactionStatusResponse_PENDING, i.Status(&ab.TransactionStatusRequest{TxHash: hash}).State)
+
+	i.Appended(makeBlock(3, bar, foo))
+	assert.Equal(t, &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED, BlockNumber: 3, TxIndex: 1}, i.Status(byID("foo")))
+	assert.Equal(t, &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED, BlockNumber: 3, TxIndex: 1},
+		i.Status(&ab.TransactionStatusRequest{TxHash: hash}), "A transaction should be found by the hash of its envelope")
+	assert.Empty(t, i.pending, "Committed transactions should no longer be pending")
+}
+
+func TestIndexSize(t *testing.T) {
+	i := NewIndex(Config{Size: 2})
+	i.Appended(makeBlock(1, makeTx(t, "a"), makeTx(t, "b")))
+	i.Appended(makeBlock(2, makeTx(t, "c"), makeTx(t, "a")))
+
+	assert.Equal(t, ab.TransactionStatusResponse_UNKNOWN, i.Status(byID("b")).State, "The oldest transactions should be forgotten")
+	assert.Equal(t, &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED, BlockNumber: 2, TxIndex: 1}, i.Status(byID("a")),
+		"A transaction ID committed again should remain indexed at its latest position")
+	assert.Len(t, i.committed, 4)
+}
+
+func TestIndexGroup(t *testing.T) {
+	i := NewIndex(Config{Size: 3})
+	i.Appended(makeBlock(1, makeGroupTx(t, "a", "group"), makeTx(t, "b")))
+	i.Appended(makeBlock(2, makeGroupTx(t, "c", "group")))
+	assert.Equal(t, []*ab.TransactionGroupResponse_Inclusion{
+		{BlockNumber: 1, TxId: "a"},
+		{BlockNumber: 2, TxId: "c"},
+	}, i.Group("group"))
+	assert.Empty(t, i.Group("other"))
+	assert.Empty(t, i.Group(""))
+
+	i.Appended(makeBlock(3, makeTx(t, "d")))
+	assert.Equal(t, []*ab.TransactionGroupResponse_Inclusion{{BlockNumber: 2, TxId: "c"}}, i.Group("group"),
+		"The oldest transactions of a group should be forgotten with the others")
+	i.Appended(makeBlock(4, makeTx(t, "e"), makeTx(t, "f")))
+	assert.Empty(t, i.groups, "Groups should be forgotten once all of their transactions are")
+}
+
+func TestIndexPendingExpiry(t *testing.T) {
+	i := NewIndex(Config{Size: 10, PendingExpiry: time.Millisecond})
+	i.Enqueued(makeTx(t, "foo"))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, ab.TransactionStatusResponse_UNKNOWN, i.Status(byID("foo")).State, "Pending transactions should expire")
+
+	i.Enqueued(makeTx(t, "bar"))
+	assert.Len(t, i.pending, 2, "The bar transaction should be pending by ID and hash after the sweep")
+
+	i = NewIndex(Config{Size: 10})
+	i.Enqueued(makeTx(t, "foo"))
+	assert.Empty(t, i.pending, "Nothing should be pending without an expiry")
+}
+
+func TestIndexRestore(t *testing.T) {
+	rw, err := ramledger.New(10).GetOrCreate("foo")
+	require.NoError(t, err)
+	require.NoError(t, rw.Append(cb.NewBlock(0, nil)))
+	for _, txID := range []string{"a", "b", "c"} {
+		block := ledger.CreateNextBlock(rw, []*cb.Envelope{makeTx(t, txID).Envelope})
+		require.NoError(t, rw.Append(block))
+	}
+
+	i := NewIndex(Config{Size: 2})
+	i.Restore(rw)
+	assert.Equal(t, ab.TransactionStatusResponse_UNKNOWN, i.Status(byID("a")).State)
+	assert.Equal(t, &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED, BlockNumber: 2}, i.Status(byID("b")))
+	assert.Equal(t, &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED, BlockNumber: 3}, i.Status(byID("c")))
+}
+
+func TestWatch(t *testing.T) {
+	for _, size := range []int{10, 0} {
+		i := NewIndex(Config{Size: size})
+		foo, bar := makeTx(t, "foo"), makeTx(t, "bar")
+		fooHash, err := pool.Hash(foo.Envelope)
+		require.NoError(t, err)
+		barHash, err := pool.Hash(bar.Envelope)
+		require.NoError(t, err)
+
+		fooCommitted, stopFoo := i.Watch(fooHash)
+		barCommitted, stopBar := i.Watch(barHash)
+		stopBar()
+		i.Appended(makeBlock(4, bar, foo))
+		select {
+		case status := <-fooCommitted:
+			assert.Equal(t, &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED, BlockNumber: 4, TxIndex: 1}, status, "size %d", size)
+		default:
+			t.Fatalf("The commit of the watched transaction should have been notified with an index of size %d", size)
+		}
+		stopFoo()
+		select {
+		case <-barCommitted:
+			t.Fatalf("The commit of a transaction no longer watched should not have been notified")
+		default:
+		}
+		assert.Empty(t, i.watchers)
+	}
+}
+
+func TestNilIndex(t *testing.T) {
+	var i *Index
+	i.Enqueued(makeTx(t, "foo"))
+	i.Appended(makeBlock(1, makeTx(t, "foo")))
+	assert.Equal(t, ab.TransactionStatusResponse_UNKNOWN, i.Status(byID("foo")).State)
+	committed, stop := i.Watch([]byte("foo"))
+	assert.Nil(t, committed)
+	stop()
+}
@@ -0,0 +1,175 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txstatus
+
+import (
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/pool"
+	"github.com/hyperledger/fabric/orderer/common/receipts"
+	"github.com/hyperledger/fabric/orderer/common/sigfilter"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var logger = logging.MustGetLogger("orderer/common/txstatus")
+
+// ChainSupport provides the resources of a chain needed to report the status
+// of its transactions
+type ChainSupport interface {
+	// PolicyManager returns the current policy manager as specified by the chain config
+	PolicyManager() policies.Manager
+
+	// TxIndex returns the index of the transactions of the chain
+	TxIndex() *Index
+}
+
+// Support provides the chains whose transactions may be queried
+type Support interface {
+	// GetChain gets the chain support for a given ChannelId
+	GetChain(chainID string) (ChainSupport, bool)
+
+	// ChannelIDs returns the IDs of all chains, in sorted order
+	ChannelIDs() []string
+}
+
+// Server implements the TransactionStatus service
+type Server struct {
+	support  Support
+	receipts *receipts.Log
+	trail    *audit.Trail
+}
+
+// NewServer creates a TransactionStatus service for the chains of support,
+// which returns the receipts kept in the receipts log, if any, and records the
+// requests denied in the audit trail
+func NewServer(support Support, receipts *receipts.Log, trail *audit.Trail) *Server {
+	return &Server{support: support, receipts: receipts, trail: trail}
+}
+
+// query is an authorized request concerning a chain
+type query struct {
+	chain   ChainSupport
+	chainID string
+	req     *ab.TransactionStatusRequest
+	logger  *flogging.FieldLogger
+}
+
+// GetTransactionStatus returns the fate of the transaction identified by the
+// request, which must be signed by a reader of its channel
+func (s *Server) GetTransactionStatus(ctx context.Context, env *cb.Envelope) (*ab.TransactionStatusResponse, error) {
+	q, err := s.authorize(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	if q.req.TxId == "" && len(q.req.TxHash) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "either a transaction ID or hash is required")
+	}
+
+	status := q.chain.TxIndex().Status(q.req)
+	q.logger.Debugf("Transaction %s is %s", q.req.TxId, status.State)
+	return status, nil
+}
+
+// GetReceipt returns the receipt of the transaction with the hash of the
+// request, which must be signed by a reader of its channel
+func (s *Server) GetReceipt(ctx context.Context, env *cb.Envelope) (*ab.BroadcastResponse, error) {
+	q, err := s.authorize(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.req.TxHash) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "a transaction hash is required")
+	}
+
+	// Receipts are kept for every channel alike, so those of the other
+	// channels are not disclosed to the readers of this one
+	if receipt := s.receipts.Lookup(q.req.TxHash); receipt != nil {
+		if ack, err := receipts.Acknowledgment(receipt); err == nil && ack.ChannelId == q.chainID {
+			return receipt, nil
+		}
+	}
+	q.logger.Debugf("No receipt for transaction %x", q.req.TxHash)
+	return nil, grpc.Errorf(codes.NotFound, "no receipt for transaction %x", q.req.TxHash)
+}
+
+// GetTransactionGroup returns the inclusions of the transactions of the group
+// of the request on each chain of which the signer of the request is a reader
+func (s *Server) GetTransactionGroup(ctx context.Context, env *cb.Envelope) (*ab.TransactionGroupResponse, error) {
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "malformed request: %s", err)
+	}
+	req := &ab.TransactionGroupRequest{}
+	if err := pool.Unmarshal(msg.Payload.Data, req); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "malformed transaction group request: %s", err)
+	}
+	if req.GroupId == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "a group ID is required")
+	}
+
+	resp := &ab.TransactionGroupResponse{}
+	for _, chainID := range s.support.ChannelIDs() {
+		chain, ok := s.support.GetChain(chainID)
+		if !ok {
+			continue
+		}
+		inclusions := chain.TxIndex().Group(req.GroupId)
+		if len(inclusions) == 0 {
+			continue
+		}
+		// Only the chains the requester may read are disclosed, so that
+		// those it may not are indistinguishable from those not included
+		if result, _ := sigfilter.New(policies.ChannelReaders, chain.PolicyManager(), s.trail).Apply(msg); result != filter.Forward {
+			continue
+		}
+		for _, inclusion := range inclusions {
+			inclusion.ChannelId = chainID
+		}
+		resp.Inclusions = append(resp.Inclusions, inclusions...)
+	}
+	logger.Debugf("Group %s has %d transactions on the channels readable by %s", req.GroupId, len(resp.Inclusions), comm.ClientIdentity(ctx))
+	return resp, nil
+}
+
+// authorize decodes the request, which must be signed by a reader of its
+// channel
+func (s *Server) authorize(ctx context.Context, env *cb.Envelope) (*query, error) {
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "malformed request: %s", err)
+	}
+	chainID := msg.ChannelHeader.ChannelId
+	chainLogger := flogging.WithFields(logger, flogging.Fields{
+		flogging.ClientField:  comm.ClientIdentity(ctx),
+		flogging.ChannelField: chainID,
+	})
+
+	chain, ok := s.support.GetChain(chainID)
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "channel %s not found", chainID)
+	}
+
+	sf := sigfilter.New(policies.ChannelReaders, chain.PolicyManager(), s.trail)
+	if result, _ := sf.Apply(msg); result != filter.Forward {
+		chainLogger.Warningf("Received unauthorized transaction status request")
+		return nil, grpc.Errorf(codes.PermissionDenied, "not authorized to read channel %s", chainID)
+	}
+
+	req := &ab.TransactionStatusRequest{}
+	if err := pool.Unmarshal(msg.Payload.Data, req); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "malformed transaction status request: %s", err)
+	}
+	return &query{chain: chain, chainID: chainID, req: req, logger: chainLogger}, nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txstatus
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/common/receipts"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type mockChain struct {
+	policyManager *mockpolicies.Manager
+	index         *Index
+}
+
+func (mc *mockChain) PolicyManager() policies.Manager {
+	return mc.policyManager
+}
+
+func (mc *mockChain) TxIndex() *Index {
+	return mc.index
+}
+
+type mockSupport map[string]*mockChain
+
+func (ms mockSupport) GetChain(chainID string) (ChainSupport, bool) {
+	chain, ok := ms[chainID]
+	return chain, ok
+}
+
+func (ms mockSupport) ChannelIDs() []string {
+	var chainIDs []string
+	for chainID := range ms {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	return chainIDs
+}
+
+func makeRequest(chainID string, req *ab.TransactionStatusRequest) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(
+			utils.MakeChannelHeader(cb.HeaderType_DELIVER_SEEK_INFO, 0, chainID, 0),
+			&cb.SignatureHeader{},
+		),
+		Data: utils.MarshalOrPanic(req),
+	})}
+}
+
+func newTestServer(t *testing.T, log *receipts.Log) (*Server, *mockChain) {
+	chain := &mockChain{
+		policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}},
+		index:         NewIndex(Config{Size: 10}),
+	}
+	chain.index.Appended(makeBlock(5, makeTx(t, "foo")))
+	return NewServer(mockSupport{"foo": chain}, log, nil), chain
+}
+
+func TestGetTransactionStatus(t *testing.T) {
+	s, _ := newTestServer(t, nil)
+
+	status, err := s.GetTransactionStatus(context.Background(), makeRequest("foo", byID("foo")))
+	assert.NoError(t, err)
+	assert.Equal(t, &ab.TransactionStatusResponse{State: ab.TransactionStatusResponse_COMMITTED, BlockNumber: 5}, status)
+
+	status, err = s.GetTransactionStatus(context.Background(), makeRequest("foo", byID("bar")))
+	assert.NoError(t, err)
+	assert.Equal(t, ab.TransactionStatusResponse_UNKNOWN, status.State)
+}
+
+func TestGetTransactionStatusErrors(t *testing.T) {
+	s, chain := newTestServer(t, nil)
+
+	_, err := s.GetTransactionStatus(context.Background(), &cb.Envelope{Payload: []byte("garbage")})
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err), "Malformed envelopes should be rejected")
+
+	_, err = s.GetTransactionStatus(context.Background(), makeRequest("bar", byID("foo")))
+	assert.Equal(t, codes.NotFound, grpc.Code(err), "Unknown channels should not be found")
+
+	_, err = s.GetTransactionStatus(context.Background(), makeRequest("foo", &ab.TransactionStatusRequest{}))
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err), "Requests should identify a transaction")
+
+	chain.policyManager.Policy.Err = fmt.Errorf("denied")
+	_, err = s.GetTransactionStatus(context.Background(), makeRequest("foo", byID("foo")))
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err), "Requests not signed by a reader should be denied")
+}
+
+func TestGetReceipt(t *testing.T) {
+	log, err := receipts.Open(receipts.Config{Size: 10})
+	require.NoError(t, err)
+	receipt := func(chainID, txHash string) *ab.BroadcastResponse {
+		return &ab.BroadcastResponse{
+			Status: cb.Status_SUCCESS,
+			Acknowledgment: utils.MarshalOrPanic(&ab.BroadcastAcknowledgment{
+				Status:    cb.Status_SUCCESS,
+				ChannelId: chainID,
+				TxHash:    []byte(txHash),
+			}),
+		}
+	}
+	require.NoError(t, log.Record(receipt("foo", "a")))
+	require.NoError(t, log.Record(receipt("bar", "b")))
+	s, _ := newTestServer(t, log)
+
+	resp, err := s.GetReceipt(context.Background(), makeRequest("foo", &ab.TransactionStatusRequest{TxHash: []byte("a")}))
+	assert.NoError(t, err)
+	assert.Equal(t, receipt("foo", "a"), resp)
+
+	_, err = s.GetReceipt(context.Background(), makeRequest("foo", &ab.TransactionStatusRequest{TxHash: []byte("b")}))
+	assert.Equal(t, codes.NotFound, grpc.Code(err), "The receipts of other channels should not be disclosed")
+
+	_, err = s.GetReceipt(context.Background(), makeRequest("foo", &ab.TransactionStatusRequest{TxHash: []byte("c")}))
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+
+	_, err = s.GetReceipt(context.Background(), makeRequest("foo", byID("foo")))
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err), "Receipts should be requested by hash")
+}
+
+func TestGetTransactionGroup(t *testing.T) {
+	newChain := func() *mockChain {
+		return &mockChain{
+			policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}},
+			index:         NewIndex(Config{Size: 10}),
+		}
+	}
+	foo, bar, baz := newChain(), newChain(), newChain()
+	foo.index.Appended(makeBlock(1, makeTx(t, "a"), makeGroupTx(t, "b", "group")))
+	bar.index.Appended(makeBlock(4, makeGroupTx(t, "c", "group")))
+	baz.index.Appended(makeBlock(2, makeGroupTx(t, "d", "group")))
+	baz.policyManager.Policy.Err = fmt.Errorf("denied")
+	s := NewServer(mockSupport{"foo": foo, "bar": bar, "baz": baz}, nil, nil)
+
+	request := func(groupID string) *cb.Envelope {
+		return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: utils.MakePayloadHeader(utils.MakeChannelHeader(cb.HeaderType_DELIVER_SEEK_INFO, 0, "", 0), &cb.SignatureHeader{}),
+			Data:   utils.MarshalOrPanic(&ab.TransactionGroupRequest{GroupId: groupID}),
+		})}
+	}
+
+	resp, err := s.GetTransactionGroup(context.Background(), request("group"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*ab.TransactionGroupResponse_Inclusion{
+		{ChannelId: "bar", BlockNumber: 4, TxId: "c"},
+		{ChannelId: "foo", BlockNumber: 1, TxIndex: 1, TxId: "b"},
+	}, resp.Inclusions, "The inclusions on the channels the requester may not read should not be disclosed")
+
+	resp, err = s.GetTransactionGroup(context.Background(), request("other"))
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Inclusions)
+
+	_, err = s.GetTransactionGroup(context.Background(), request(""))
+	assert.Equal(t, codes.InvalidArgument, grpc.Code(err), "Requests should identify a group")
+}
@@ -0,0 +1,462 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package follower replicates the chains of an ordering service into a local
+// ledger, read-only, for nodes which serve Deliver without ordering.
+package follower
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/client"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/cosign"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+var logger = logging.MustGetLogger("orderer/follower")
+
+const defaultRetryInterval = 5 * time.Second
+
+// maxBatchSize is the number of blocks the follower accepts in a response,
+// which it receives while catching up with the chains
+const maxBatchSize = 64
+
+// Source delivers the blocks of the chains being followed, as the orderer
+// client does
+type Source interface {
+	Deliver(ctx context.Context, channelID string, seekInfo *ab.SeekInfo, handle func(*cb.Block) error) error
+}
+
+// Config is the configuration of a Follower
+type Config struct {
+	// SystemChainID is the ID of the system channel, whose channel creation
+	// transactions reveal the other channels to follow
+	SystemChainID string
+	// RetryInterval is the delay before the blocks of a chain are requested
+	// again after the source fails
+	RetryInterval time.Duration
+	// Clock times the retries, the wall clock if nil
+	Clock clock.Clock
+}
+
+// Follower follows the system channel of an ordering service, and every
+// channel it creates, appending the blocks delivered by the source to the
+// ledgers of the factory once they are verified to extend the hash chain and
+// to be signed as the block validation policy of their channel requires.
+// The chains which already have blocks are served from where they left off.
+type Follower struct {
+	source Source
+	lf     ledger.Factory
+	config Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mutex  sync.RWMutex
+	chains map[string]*Chain
+}
+
+// New creates a follower replicating the chains delivered by source into the
+// ledgers of lf
+func New(source Source, lf ledger.Factory, config Config) (*Follower, error) {
+	if config.SystemChainID == "" {
+		return nil, fmt.Errorf("the system channel ID is required")
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultRetryInterval
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Follower{
+		source: source,
+		lf:     lf,
+		config: config,
+		ctx:    ctx,
+		cancel: cancel,
+		chains: make(map[string]*Chain),
+	}
+
+	chainIDs := append(lf.ChainIDs(), config.SystemChainID)
+	for _, chainID := range chainIDs {
+		if _, ok := f.chains[chainID]; ok {
+			continue
+		}
+		chain, err := f.newChain(chainID)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		f.chains[chainID] = chain
+	}
+	return f, nil
+}
+
+// newChain loads the chain from its ledger, which may not have any block yet
+func (f *Follower) newChain(chainID string) (*Chain, error) {
+	rl, err := f.lf.GetOrCreate(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get ledger for channel %s: %s", chainID, err)
+	}
+	chain := &Chain{chainID: chainID, ledger: rl, errored: make(chan struct{})}
+	if rl.Height() == 0 {
+		return chain, nil
+	}
+
+	lastBlock := ledger.GetBlock(rl, rl.Height()-1)
+	if lastBlock == nil {
+		return nil, fmt.Errorf("could not read the last block of channel %s", chainID)
+	}
+	index, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+	if err != nil {
+		return nil, fmt.Errorf("channel %s did not have appropriately encoded last config in its latest block: %s", chainID, err)
+	}
+	configBlock := ledger.GetBlock(rl, index)
+	if configBlock == nil {
+		return nil, fmt.Errorf("config block %d of channel %s does not exist", index, chainID)
+	}
+	configTx, err := utils.ExtractEnvelope(configBlock, 0)
+	if err != nil {
+		return nil, fmt.Errorf("config block %d of channel %s does not contain a transaction: %s", index, chainID, err)
+	}
+	configManager, err := configtx.NewManagerImpl(configTx, configtx.NewInitializer(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("config block %d of channel %s is not valid: %s", index, chainID, err)
+	}
+	chain.configManager = configManager
+	chain.lastHash = lastBlock.Header.Hash()
+	return chain, nil
+}
+
+// Start follows the chains until Stop is called
+func (f *Follower) Start() {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	for _, chain := range f.chains {
+		f.follow(chain)
+	}
+}
+
+// Stop stops following the chains, waits for the blocks being appended and
+// closes the ledgers
+func (f *Follower) Stop() {
+	f.cancel()
+	f.wg.Wait()
+	f.mutex.RLock()
+	for _, chain := range f.chains {
+		close(chain.errored)
+	}
+	logger.Infof("Stopped following %d chains", len(f.chains))
+	f.mutex.RUnlock()
+	f.lf.Close()
+}
+
+// follow requests the blocks of the chain from those following its height,
+// until the follower is stopped
+func (f *Follower) follow(chain *Chain) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		for {
+			height := chain.ledger.Height()
+			logger.Debugf("Following channel %s from block %d", chain.chainID, height)
+			err := f.source.Deliver(f.ctx, chain.chainID, &ab.SeekInfo{
+				Start:        client.Specified(height),
+				Stop:         client.Specified(math.MaxUint64),
+				Behavior:     ab.SeekInfo_BLOCK_UNTIL_READY,
+				MaxBatchSize: maxBatchSize,
+			}, func(block *cb.Block) error {
+				return f.commit(chain, block)
+			})
+			if f.ctx.Err() != nil {
+				return
+			}
+			logger.Warningf("Failed to follow channel %s at block %d, retrying in %s: %s", chain.chainID, chain.ledger.Height(), f.config.RetryInterval, err)
+			select {
+			case <-f.config.Clock.After(f.config.RetryInterval):
+			case <-f.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// commit appends the block to the chain, following the channels it creates
+// if the chain is the system channel
+func (f *Follower) commit(chain *Chain, block *cb.Block) error {
+	if err := chain.append(block); err != nil {
+		return err
+	}
+	if chain.chainID != f.config.SystemChainID {
+		return nil
+	}
+	for _, chainID := range createdChannels(block) {
+		if err := f.add(chainID); err != nil {
+			logger.Errorf("Could not follow channel %s created in block %d of the system channel: %s", chainID, block.Header.Number, err)
+		}
+	}
+	return nil
+}
+
+// add starts following the chain, unless it is followed already
+func (f *Follower) add(chainID string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.chains[chainID]; ok {
+		return nil
+	}
+	chain, err := f.newChain(chainID)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Following new channel %s", chainID)
+	f.chains[chainID] = chain
+	f.follow(chain)
+	return nil
+}
+
+// createdChannels returns the IDs of the channels created by the orderer
+// transactions of a block of the system channel
+func createdChannels(block *cb.Block) []string {
+	var chainIDs []string
+	for i := range block.Data.Data {
+		env, err := utils.ExtractEnvelope(block, i)
+		if err != nil {
+			continue
+		}
+		payload, err := utils.UnmarshalPayload(env.Payload)
+		if err != nil || payload.Header == nil {
+			continue
+		}
+		chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		if err != nil || chdr.Type != int32(cb.HeaderType_ORDERER_TRANSACTION) {
+			continue
+		}
+		configTx := &cb.Envelope{}
+		if err := proto.Unmarshal(payload.Data, configTx); err != nil {
+			continue
+		}
+		configPayload, err := utils.UnmarshalPayload(configTx.Payload)
+		if err != nil || configPayload.Header == nil {
+			continue
+		}
+		configChdr, err := utils.UnmarshalChannelHeader(configPayload.Header.ChannelHeader)
+		if err != nil {
+			continue
+		}
+		chainIDs = append(chainIDs, configChdr.ChannelId)
+	}
+	return chainIDs
+}
+
+// ChannelIDs returns the IDs of the chains which have blocks, in sorted order
+func (f *Follower) ChannelIDs() []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	chainIDs := make([]string, 0, len(f.chains))
+	for chainID, chain := range f.chains {
+		if chain.ledger.Height() > 0 {
+			chainIDs = append(chainIDs, chainID)
+		}
+	}
+	sort.Strings(chainIDs)
+	return chainIDs
+}
+
+// GetChain returns the chain, if it has blocks
+func (f *Follower) GetChain(chainID string) (*Chain, bool) {
+	f.mutex.RLock()
+	chain, ok := f.chains[chainID]
+	f.mutex.RUnlock()
+	if !ok || chain.config() == nil {
+		return nil, false
+	}
+	return chain, true
+}
+
+// Reader returns the ledger of the chain, if it has blocks
+func (f *Follower) Reader(chainID string) (ledger.Reader, bool) {
+	chain, ok := f.GetChain(chainID)
+	if !ok {
+		return nil, false
+	}
+	return chain.Reader(), true
+}
+
+// Chain is a chain replicated by the follower, providing the resources to
+// serve Deliver from its ledger under the policies of its latest config
+type Chain struct {
+	chainID string
+	ledger  ledger.ReadWriter
+	errored chan struct{}
+
+	mutex         sync.RWMutex
+	configManager configtxapi.Manager
+	lastHash      []byte
+
+	validatorOnce sync.Once
+	validator     *identity.Validator
+}
+
+// append verifies that the block extends the chain, that it is signed as
+// the block validation policy of the chain requires and that it carries a
+// valid config if it is a config block, before appending it. The config of
+// a later config block must be a valid update of the current config of the
+// chain; only the genesis block, from which the chain starts, is trusted as
+// it is.
+func (c *Chain) append(block *cb.Block) error {
+	if block.Header == nil || block.Data == nil {
+		return fmt.Errorf("block of channel %s is malformed", c.chainID)
+	}
+	if height := c.ledger.Height(); block.Header.Number != height {
+		return fmt.Errorf("expected block %d of channel %s but got block %d", height, c.chainID, block.Header.Number)
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("block %d of channel %s has a data hash which does not match its data", block.Header.Number, c.chainID)
+	}
+	c.mutex.RLock()
+	lastHash := c.lastHash
+	c.mutex.RUnlock()
+	if block.Header.Number > 0 && !bytes.Equal(block.Header.PreviousHash, lastHash) {
+		return fmt.Errorf("block %d of channel %s does not extend the hash chain", block.Header.Number, c.chainID)
+	}
+
+	current := c.config()
+	if current == nil {
+		if block.Header.Number != 0 || !utils.IsConfigBlock(block) {
+			return fmt.Errorf("genesis block of channel %s is not a config block", c.chainID)
+		}
+		configTx, _ := utils.ExtractEnvelope(block, 0)
+		configManager, err := configtx.NewManagerImpl(configTx, configtx.NewInitializer(), nil)
+		if err != nil {
+			return fmt.Errorf("config block %d of channel %s is not valid: %s", block.Header.Number, c.chainID, err)
+		}
+		if err := c.ledger.Append(block); err != nil {
+			return fmt.Errorf("could not append block %d of channel %s: %s", block.Header.Number, c.chainID, err)
+		}
+		logger.Debugf("Appended genesis block of channel %s", c.chainID)
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.lastHash = block.Header.Hash()
+		c.configManager = configManager
+		return nil
+	}
+
+	policy, _ := current.PolicyManager().GetPolicy(policies.BlockValidation)
+	if err := cosign.Verify(block, policy); err != nil {
+		return fmt.Errorf("block %d of channel %s is not valid: %s", block.Header.Number, c.chainID, err)
+	}
+	var configEnv *cb.ConfigEnvelope
+	if utils.IsConfigBlock(block) {
+		var err error
+		if configEnv, err = blockConfig(block); err == nil {
+			err = current.Validate(configEnv)
+		}
+		if err != nil {
+			return fmt.Errorf("config block %d of channel %s is not valid: %s", block.Header.Number, c.chainID, err)
+		}
+	}
+
+	if err := c.ledger.Append(block); err != nil {
+		return fmt.Errorf("could not append block %d of channel %s: %s", block.Header.Number, c.chainID, err)
+	}
+	logger.Debugf("Appended block %d of channel %s", block.Header.Number, c.chainID)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastHash = block.Header.Hash()
+	if configEnv != nil {
+		if err := current.Apply(configEnv); err != nil {
+			// The config was validated, so it could only fail to apply if
+			// the current config changed meanwhile
+			logger.Panicf("Could not apply the config of block %d of channel %s: %s", block.Header.Number, c.chainID, err)
+		}
+	}
+	return nil
+}
+
+// blockConfig returns the config envelope carried by the config block
+func blockConfig(block *cb.Block) (*cb.ConfigEnvelope, error) {
+	configTx, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := utils.UnmarshalPayload(configTx.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return configtx.UnmarshalConfigEnvelope(payload.Data)
+}
+
+func (c *Chain) config() configtxapi.Manager {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.configManager
+}
+
+// ChainID returns the ID of the chain
+func (c *Chain) ChainID() string {
+	return c.chainID
+}
+
+// Sequence returns the sequence number of the latest config of the chain
+func (c *Chain) Sequence() uint64 {
+	return c.config().Sequence()
+}
+
+// PolicyManager returns the policy manager of the latest config of the chain
+func (c *Chain) PolicyManager() policies.Manager {
+	return c.config().PolicyManager()
+}
+
+// MSPManager returns the MSPs of the latest config of the chain
+func (c *Chain) MSPManager() msp.MSPManager {
+	return c.config().MSPManager()
+}
+
+// IdentityValidator returns the validator for identities issued by the MSPs
+// of the chain, creating it on first use
+func (c *Chain) IdentityValidator() *identity.Validator {
+	c.validatorOnce.Do(func() {
+		c.validator = identity.NewValidator(c, identity.DefaultCacheSize)
+	})
+	return c.validator
+}
+
+// OrdererAddresses returns the addresses of the orderers of the chain, as of
+// its latest config
+func (c *Chain) OrdererAddresses() []string {
+	return c.config().ChannelConfig().OrdererAddresses()
+}
+
+// Reader returns the ledger of the chain
+func (c *Chain) Reader() ledger.Reader {
+	return c.ledger
+}
+
+// Errored returns a channel which closes once the follower stops
+func (c *Chain) Errored() <-chan struct{} {
+	return c.errored
+}
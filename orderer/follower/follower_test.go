@@ -0,0 +1,328 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package follower
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/orderer/client"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+const systemChainID = "testchainid"
+
+var profile = genesisconfig.Load(genesisconfig.SampleInsecureProfile)
+
+// upstream serves the blocks of its ledgers, as an ordering service does,
+// failing the first failures requests
+type upstream struct {
+	lf ledger.Factory
+
+	mutex    sync.Mutex
+	failures int
+	requests []string
+}
+
+func (u *upstream) Deliver(ctx context.Context, channelID string, seekInfo *ab.SeekInfo, handle func(*cb.Block) error) error {
+	u.mutex.Lock()
+	u.requests = append(u.requests, fmt.Sprintf("%s/%d", channelID, seekInfo.Start.GetSpecified().Number))
+	failing := len(u.requests) <= u.failures
+	u.mutex.Unlock()
+	if failing {
+		return fmt.Errorf("orderer unavailable")
+	}
+
+	found := false
+	for _, chainID := range u.lf.ChainIDs() {
+		found = found || chainID == channelID
+	}
+	if !found {
+		return &client.StatusError{Status: cb.Status_NOT_FOUND}
+	}
+	rl, _ := u.lf.GetOrCreate(channelID)
+	it, _ := rl.Iterator(seekInfo.Start)
+	for {
+		select {
+		case <-it.ReadyChan():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		block, status := it.Next()
+		if status != cb.Status_SUCCESS {
+			return &client.StatusError{Status: status}
+		}
+		if err := handle(block); err != nil {
+			return err
+		}
+	}
+}
+
+// appendBlock appends a block of the envelopes to the chain, pointing to the
+// last config block as orderers do
+func appendBlock(t *testing.T, lf ledger.Factory, chainID string, lastConfig uint64, envs ...*cb.Envelope) *cb.Block {
+	rl, err := lf.GetOrCreate(chainID)
+	require.NoError(t, err)
+	block := ledger.CreateNextBlock(rl, envs)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+		Value: utils.MarshalOrPanic(&cb.LastConfig{Index: lastConfig}),
+	})
+	require.NoError(t, rl.Append(block))
+	return block
+}
+
+func appendGenesis(t *testing.T, lf ledger.Factory, chainID string) *cb.Envelope {
+	configTx, err := utils.ExtractEnvelope(provisional.New(profile).GenesisBlockForChannel(chainID), 0)
+	require.NoError(t, err)
+	appendBlock(t, lf, chainID, 0, configTx)
+	return configTx
+}
+
+func normalTx(chainID string, i int) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(utils.MakeChannelHeader(cb.HeaderType_MESSAGE, 0, chainID, 0), &cb.SignatureHeader{}),
+		Data:   []byte(fmt.Sprintf("message %d", i)),
+	})}
+}
+
+// eventually waits for the condition to hold
+func eventually(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func height(f *Follower, chainID string) uint64 {
+	rl, ok := f.Reader(chainID)
+	if !ok {
+		return 0
+	}
+	return rl.Height()
+}
+
+func TestNewValidation(t *testing.T) {
+	_, err := New(&upstream{}, ramledger.New(10), Config{})
+	assert.EqualError(t, err, "the system channel ID is required")
+
+	lf := ramledger.New(10)
+	appendBlock(t, lf, systemChainID, 0, normalTx(systemChainID, 0))
+	_, err = New(&upstream{}, lf, Config{SystemChainID: systemChainID})
+	assert.Error(t, err, "A chain whose last config is not a config block cannot be served")
+}
+
+func blockOf(t *testing.T, lf ledger.Factory, chainID string, number uint64) *cb.Block {
+	rl, err := lf.GetOrCreate(chainID)
+	require.NoError(t, err)
+	return ledger.GetBlock(rl, number)
+}
+
+func TestFollower(t *testing.T) {
+	remote := &upstream{lf: ramledger.New(100), failures: 1}
+	appendGenesis(t, remote.lf, systemChainID)
+	appendBlock(t, remote.lf, systemChainID, 0, normalTx(systemChainID, 1))
+
+	local := ramledger.New(100)
+	f, err := New(remote, local, Config{SystemChainID: systemChainID, RetryInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	_, ok := f.GetChain(systemChainID)
+	assert.False(t, ok, "A chain without blocks should not be served")
+	assert.Empty(t, f.ChannelIDs())
+
+	f.Start()
+	defer f.Stop()
+	eventually(t, func() bool { return height(f, systemChainID) == 2 })
+	chain, ok := f.GetChain(systemChainID)
+	require.True(t, ok)
+	assert.Equal(t, systemChainID, chain.ChainID())
+	assert.NotNil(t, chain.PolicyManager())
+	assert.Equal(t, blockOf(t, remote.lf, systemChainID, 1), blockOf(t, local, systemChainID, 1))
+	remote.mutex.Lock()
+	assert.Equal(t, []string{"testchainid/0", "testchainid/0"}, remote.requests, "The request should be retried after the failure")
+	remote.mutex.Unlock()
+
+	// The channel is created in the system channel before its genesis block
+	// is available, it is requested again until it is
+	configTx := appendGenesis(t, ramledger.New(10), "bar")
+	ordererTx, err := utils.CreateSignedEnvelope(cb.HeaderType_ORDERER_TRANSACTION, systemChainID, nil, configTx, 0, 0)
+	require.NoError(t, err)
+	appendBlock(t, remote.lf, systemChainID, 0, ordererTx)
+	eventually(t, func() bool {
+		remote.mutex.Lock()
+		defer remote.mutex.Unlock()
+		return len(remote.requests) > 3
+	})
+	_, ok = f.GetChain("bar")
+	assert.False(t, ok)
+
+	appendBlock(t, remote.lf, "bar", 0, configTx)
+	appendBlock(t, remote.lf, "bar", 0, normalTx("bar", 1))
+	eventually(t, func() bool { return height(f, "bar") == 2 })
+	assert.Equal(t, []string{"bar", systemChainID}, f.ChannelIDs())
+	bar, ok := f.GetChain("bar")
+	require.True(t, ok)
+	assert.Equal(t, uint64(0), bar.Sequence())
+	assert.Equal(t, profile.Orderer.Addresses, bar.OrdererAddresses())
+	assert.Equal(t, blockOf(t, remote.lf, "bar", 1), blockOf(t, local, "bar", 1))
+
+	select {
+	case <-bar.Errored():
+		t.Fatal("The chain should not report an error while followed")
+	default:
+	}
+}
+
+func TestFollowerResumes(t *testing.T) {
+	remote := &upstream{lf: ramledger.New(100)}
+	appendGenesis(t, remote.lf, systemChainID)
+	local := ramledger.New(100)
+	require.NoError(t, mustGet(t, local, systemChainID).Append(blockOf(t, remote.lf, systemChainID, 0)))
+	appendBlock(t, remote.lf, systemChainID, 0, normalTx(systemChainID, 1))
+
+	f, err := New(remote, local, Config{SystemChainID: systemChainID})
+	require.NoError(t, err)
+	_, ok := f.GetChain(systemChainID)
+	assert.True(t, ok, "A chain with blocks should be served before it is followed")
+
+	f.Start()
+	eventually(t, func() bool { return height(f, systemChainID) == 2 })
+	remote.mutex.Lock()
+	assert.Equal(t, "testchainid/1", remote.requests[0], "The chain should be followed from its height")
+	remote.mutex.Unlock()
+
+	chain, _ := f.GetChain(systemChainID)
+	f.Stop()
+	select {
+	case <-chain.Errored():
+	default:
+		t.Fatal("The chains should report an error once the follower stops")
+	}
+}
+
+func TestChainAppendVerifies(t *testing.T) {
+	remote := ramledger.New(100)
+	appendGenesis(t, remote, systemChainID)
+	appendBlock(t, remote, systemChainID, 0, normalTx(systemChainID, 1))
+	local := ramledger.New(100)
+	chain := &Chain{chainID: systemChainID, ledger: mustGet(t, local, systemChainID)}
+
+	require.NoError(t, chain.append(blockOf(t, remote, systemChainID, 0)))
+	assert.NotNil(t, chain.config())
+
+	err := chain.append(blockOf(t, remote, systemChainID, 0))
+	assert.EqualError(t, err, "expected block 1 of channel testchainid but got block 0")
+
+	foreign := proto.Clone(blockOf(t, remote, systemChainID, 1)).(*cb.Block)
+	foreign.Header.PreviousHash = []byte("foreign")
+	err = chain.append(foreign)
+	assert.EqualError(t, err, "block 1 of channel testchainid does not extend the hash chain")
+
+	tampered := proto.Clone(blockOf(t, remote, systemChainID, 1)).(*cb.Block)
+	tampered.Data.Data = [][]byte{[]byte("tampered")}
+	err = chain.append(tampered)
+	assert.EqualError(t, err, "block 1 of channel testchainid has a data hash which does not match its data")
+
+	require.NoError(t, chain.append(blockOf(t, remote, systemChainID, 1)))
+	assert.Equal(t, uint64(2), chain.ledger.Height())
+
+	empty := &Chain{chainID: "bar", ledger: mustGet(t, ramledger.New(10), "bar")}
+	rl := mustGet(t, ramledger.New(10), "bar")
+	err = empty.append(ledger.CreateNextBlock(rl, []*cb.Envelope{normalTx("bar", 0)}))
+	assert.EqualError(t, err, "genesis block of channel bar is not a config block")
+	assert.Equal(t, uint64(0), empty.ledger.Height())
+}
+
+func TestChainAppendPolicies(t *testing.T) {
+	remote := ramledger.New(100)
+	configTx := appendGenesis(t, remote, systemChainID)
+	appendBlock(t, remote, systemChainID, 0, normalTx(systemChainID, 1))
+	local := ramledger.New(100)
+	chain := &Chain{chainID: systemChainID, ledger: mustGet(t, local, systemChainID)}
+	require.NoError(t, chain.append(blockOf(t, remote, systemChainID, 0)))
+
+	policy := &mockpolicies.Policy{Err: fmt.Errorf("not signed by the orderers")}
+	configManager := &mockconfigtx.Manager{
+		Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{
+			PolicyManagerVal: &mockpolicies.Manager{Policy: policy},
+		}},
+		ValidateVal: fmt.Errorf("not authorized by the current config"),
+	}
+	chain.configManager = configManager
+
+	err := chain.append(blockOf(t, remote, systemChainID, 1))
+	assert.EqualError(t, err, "block 1 of channel testchainid is not valid: signatures of block 1 do not satisfy the block validation policy: not signed by the orderers")
+	policy.Err = nil
+	require.NoError(t, chain.append(blockOf(t, remote, systemChainID, 1)))
+
+	appendBlock(t, remote, systemChainID, 2, configTx)
+	err = chain.append(blockOf(t, remote, systemChainID, 2))
+	assert.EqualError(t, err, "config block 2 of channel testchainid is not valid: not authorized by the current config")
+	assert.Nil(t, configManager.AppliedConfigUpdateEnvelope)
+
+	configManager.ValidateVal = nil
+	require.NoError(t, chain.append(blockOf(t, remote, systemChainID, 2)))
+	assert.NotNil(t, configManager.AppliedConfigUpdateEnvelope, "The config should be applied to the current config")
+	assert.Equal(t, uint64(3), chain.ledger.Height())
+}
+
+func mustGet(t *testing.T, lf ledger.Factory, chainID string) ledger.ReadWriter {
+	rl, err := lf.GetOrCreate(chainID)
+	require.NoError(t, err)
+	return rl
+}
+
+func TestCreatedChannels(t *testing.T) {
+	configTx := appendGenesis(t, ramledger.New(10), "bar")
+	ordererTx, err := utils.CreateSignedEnvelope(cb.HeaderType_ORDERER_TRANSACTION, systemChainID, nil, configTx, 0, 0)
+	require.NoError(t, err)
+	block := cb.NewBlock(1, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(normalTx(systemChainID, 0)), []byte("garbage"), utils.MarshalOrPanic(ordererTx)}
+	assert.Equal(t, []string{"bar"}, createdChannels(block))
+}
+
+// FuzzAppend appends arbitrarily encoded blocks, and genesis blocks of
+// arbitrary envelopes, to a chain, as received from an upstream orderer
+func FuzzAppend(f *testing.F) {
+	genesis := provisional.New(profile).GenesisBlock()
+	f.Add(utils.MarshalOrPanic(genesis), genesis.Data.Data[0])
+	f.Add([]byte("garbage"), []byte("garbage"))
+	appendBlock := func(block *cb.Block) {
+		rl, _ := ramledger.New(10).GetOrCreate(systemChainID)
+		chain := &Chain{chainID: systemChainID, ledger: rl}
+		if chain.append(block) == nil {
+			createdChannels(block)
+		}
+	}
+	f.Fuzz(func(t *testing.T, blockBytes, envBytes []byte) {
+		block := &cb.Block{}
+		if err := proto.Unmarshal(blockBytes, block); err == nil {
+			appendBlock(block)
+		}
+
+		block = cb.NewBlock(0, nil)
+		block.Data.Data = [][]byte{envBytes}
+		block.Header.DataHash = block.Data.Hash()
+		appendBlock(block)
+	})
+}
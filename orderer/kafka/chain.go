@@ -13,6 +13,10 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
 	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/hyperledger/fabric/orderer/multichain"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -20,6 +24,17 @@ import (
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
+// Kafka orderers have no leader, the order of messages is decided by the
+// leader of the partition among the brokers, so the availability of the
+// partition consumer is reported instead
+var consumerAvailable = metrics.NewGauge(metrics.Opts{
+	Namespace:  "orderer",
+	Subsystem:  "consensus",
+	Name:       "kafka_consumer_available",
+	Help:       "Whether the consumer of the partition of the channel is available.",
+	LabelNames: []string{"channel"},
+})
+
 // Used for capturing metrics -- see processMessagesToBlocks
 const (
 	indexRecvError = iota
@@ -37,8 +52,7 @@ const (
 
 func newChain(consenter commonConsenter, support multichain.ConsenterSupport, lastOffsetPersisted int64) (*chainImpl, error) {
 	lastCutBlockNumber := getLastCutBlockNumber(support.Height())
-	logger.Infof("[channel: %s] Starting chain with last persisted offset %d and last recorded block %d",
-		support.ChainID(), lastOffsetPersisted, lastCutBlockNumber)
+	flogging.WithChannel(logger, support.ChainID()).Infof("Starting chain with last persisted offset %d and last recorded block %d", lastOffsetPersisted, lastCutBlockNumber)
 
 	errorChan := make(chan struct{})
 	close(errorChan) // We need this closed when starting up
@@ -46,6 +60,7 @@ func newChain(consenter commonConsenter, support multichain.ConsenterSupport, la
 	return &chainImpl{
 		consenter:           consenter,
 		support:             support,
+		clock:               consenter.clock(),
 		channel:             newChannel(support.ChainID(), defaultPartition),
 		lastOffsetPersisted: lastOffsetPersisted,
 		lastCutBlockNumber:  lastCutBlockNumber,
@@ -59,10 +74,13 @@ func newChain(consenter commonConsenter, support multichain.ConsenterSupport, la
 type chainImpl struct {
 	consenter commonConsenter
 	support   multichain.ConsenterSupport
+	// clock drives the batch timer
+	clock clock.Clock
 
 	channel             channel
 	lastOffsetPersisted int64
 	lastCutBlockNumber  uint64
+	negotiator          *negotiator
 
 	producer        sarama.SyncProducer
 	parentConsumer  sarama.Consumer
@@ -79,6 +97,11 @@ type chainImpl struct {
 	startChan chan struct{}
 }
 
+// logger returns a logger attaching the channel to its records
+func (chain *chainImpl) logger() *flogging.FieldLogger {
+	return flogging.WithChannel(logger, chain.support.ChainID())
+}
+
 // Errored returns a channel which will close when a partition consumer error
 // has occurred. Checked by Deliver().
 func (chain *chainImpl) Errored() <-chan struct{} {
@@ -102,43 +125,43 @@ func (chain *chainImpl) Halt() {
 		// This construct is useful because it allows Halt() to be called
 		// multiple times (by a single thread) w/o panicking. Recal that a
 		// receive from a closed channel returns (the zero value) immediately.
-		logger.Warningf("[channel: %s] Halting of chain requested again", chain.support.ChainID())
+		chain.logger().Warningf("Halting of chain requested again")
 	default:
-		logger.Criticalf("[channel: %s] Halting of chain requested", chain.support.ChainID())
+		chain.logger().Criticalf("Halting of chain requested")
 		close(chain.haltChan)
 		chain.closeKafkaObjects() // Also close the producer and the consumer
-		logger.Debugf("[channel: %s] Closed the haltChan", chain.support.ChainID())
+		chain.logger().Debugf("Closed the haltChan")
 	}
 }
 
 // Enqueue accepts a message and returns true on acceptance, or false otheriwse.
 // Implements the multichain.Chain interface. Called by Broadcast().
-func (chain *chainImpl) Enqueue(env *cb.Envelope) bool {
-	logger.Debugf("[channel: %s] Enqueueing envelope...", chain.support.ChainID())
+func (chain *chainImpl) Enqueue(msg *filter.Message) bool {
+	chain.logger().Debugf("Enqueueing envelope...")
 	select {
 	case <-chain.startChan: // The Start phase has completed
 		select {
 		case <-chain.haltChan: // The chain has been halted, stop here
-			logger.Warningf("[channel: %s] Will not enqueue, consenter for this channel has been halted", chain.support.ChainID())
+			chain.logger().Warningf("Will not enqueue, consenter for this channel has been halted")
 			return false
 		default: // The post path
-			marshaledEnv, err := utils.Marshal(env)
+			marshaledEnv, err := utils.Marshal(msg.Envelope)
 			if err != nil {
-				logger.Errorf("[channel: %s] cannot enqueue, unable to marshal envelope = %s", chain.support.ChainID(), err)
+				chain.logger().Errorf("cannot enqueue, unable to marshal envelope = %s", err)
 				return false
 			}
 			// We're good to go
 			payload := utils.MarshalOrPanic(newRegularMessage(marshaledEnv))
 			message := newProducerMessage(chain.channel, payload)
 			if _, _, err := chain.producer.SendMessage(message); err != nil {
-				logger.Errorf("[channel: %s] cannot enqueue envelope = %s", chain.support.ChainID(), err)
+				chain.logger().Errorf("cannot enqueue envelope = %s", err)
 				return false
 			}
-			logger.Debugf("[channel: %s] Envelope enqueued successfully", chain.support.ChainID())
+			chain.logger().Debugf("Envelope enqueued successfully")
 			return true
 		}
 	default: // Not ready yet
-		logger.Warningf("[channel: %s] Will not enqueue, consenter for this channel hasn't started yet", chain.support.ChainID())
+		chain.logger().Warningf("Will not enqueue, consenter for this channel hasn't started yet")
 		return false
 	}
 }
@@ -148,36 +171,37 @@ func startThread(chain *chainImpl) {
 	var err error
 
 	// Set up the producer
-	chain.producer, err = setupProducerForChannel(chain.consenter.retryOptions(), chain.haltChan, chain.support.SharedConfig().KafkaBrokers(), chain.consenter.brokerConfig(), chain.channel)
+	chain.producer, err = setupProducerForChannel(chain.consenter.retryOptions(), chain.clock, chain.haltChan, chain.support.SharedConfig().KafkaBrokers(), chain.consenter.brokerConfig(), chain.channel)
 	if err != nil {
-		logger.Panicf("[channel: %s] Cannot set up producer = %s", chain.channel.topic(), err)
+		chain.logger().Panicf("Cannot set up producer = %s", err)
 	}
-	logger.Infof("[channel: %s] Producer set up successfully", chain.support.ChainID())
+	chain.logger().Infof("Producer set up successfully")
 
 	// Have the producer post the CONNECT message
-	if err = sendConnectMessage(chain.consenter.retryOptions(), chain.haltChan, chain.producer, chain.channel); err != nil {
-		logger.Panicf("[channel: %s] Cannot post CONNECT message = %s", chain.channel.topic(), err)
+	if err = sendConnectMessage(chain.consenter.retryOptions(), chain.clock, chain.haltChan, chain.producer, chain.channel, chain.consenter.cluster().NodeID); err != nil {
+		chain.logger().Panicf("Cannot post CONNECT message = %s", err)
 	}
-	logger.Infof("[channel: %s] CONNECT message posted successfully", chain.channel.topic())
+	chain.logger().Infof("CONNECT message posted successfully")
 
 	// Set up the parent consumer
-	chain.parentConsumer, err = setupParentConsumerForChannel(chain.consenter.retryOptions(), chain.haltChan, chain.support.SharedConfig().KafkaBrokers(), chain.consenter.brokerConfig(), chain.channel)
+	chain.parentConsumer, err = setupParentConsumerForChannel(chain.consenter.retryOptions(), chain.clock, chain.haltChan, chain.support.SharedConfig().KafkaBrokers(), chain.consenter.brokerConfig(), chain.channel)
 	if err != nil {
-		logger.Panicf("[channel: %s] Cannot set up parent consumer = %s", chain.channel.topic(), err)
+		chain.logger().Panicf("Cannot set up parent consumer = %s", err)
 	}
-	logger.Infof("[channel: %s] Parent consumer set up successfully", chain.channel.topic())
+	chain.logger().Infof("Parent consumer set up successfully")
 
 	// Set up the channel consumer
-	chain.channelConsumer, err = setupChannelConsumerForChannel(chain.consenter.retryOptions(), chain.haltChan, chain.parentConsumer, chain.channel, chain.lastOffsetPersisted+1)
+	chain.channelConsumer, err = setupChannelConsumerForChannel(chain.consenter.retryOptions(), chain.clock, chain.haltChan, chain.parentConsumer, chain.channel, chain.lastOffsetPersisted+1)
 	if err != nil {
-		logger.Panicf("[channel: %s] Cannot set up channel consumer = %s", chain.channel.topic(), err)
+		chain.logger().Panicf("Cannot set up channel consumer = %s", err)
 	}
-	logger.Infof("[channel: %s] Channel consumer set up successfully", chain.channel.topic())
+	chain.logger().Infof("Channel consumer set up successfully")
 
 	close(chain.startChan)                // Broadcast requests will now go through
 	chain.errorChan = make(chan struct{}) // Deliver requests will also go through
+	consumerAvailable.With(chain.support.ChainID()).Set(1)
 
-	logger.Infof("[channel: %s] Start phase completed successfully", chain.channel.topic())
+	chain.logger().Infof("Start phase completed successfully")
 
 	chain.processMessagesToBlocks() // Keep up to date with the channel
 }
@@ -196,23 +220,25 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 		default:
 			close(chain.errorChan)
 		}
+		consumerAvailable.With(chain.support.ChainID()).Set(0)
 	}()
 
 	for {
 		select {
 		case <-chain.haltChan:
-			logger.Warningf("[channel: %s] Consenter for channel exiting", chain.support.ChainID())
+			chain.logger().Warningf("Consenter for channel exiting")
 			counts[indexExitChanPass]++
 			return counts, nil
 		case kafkaErr := <-chain.channelConsumer.Errors():
-			logger.Errorf("[channel: %s] Error during consumption: %s", chain.support.ChainID(), kafkaErr)
+			chain.logger().Errorf("Error during consumption: %s", kafkaErr)
 			counts[indexRecvError]++
 			select {
 			case <-chain.errorChan: // If already closed, don't do anything
 			default:
 				close(chain.errorChan)
 			}
-			logger.Warningf("[channel: %s] Closed the errorChan", chain.support.ChainID())
+			consumerAvailable.With(chain.support.ChainID()).Set(0)
+			chain.logger().Warningf("Closed the errorChan")
 			// This covers the edge case where (1) a consumption error has
 			// closed the errorChan and thus rendered the chain unavailable to
 			// deliver clients, (2) we're already at the newest offset, and (3)
@@ -220,42 +246,44 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 			// there is no trigger that can recreate the errorChan again and
 			// mark the chain as available, so we have to force that trigger via
 			// the emission of a CONNECT message. TODO Consider rate limiting
-			go sendConnectMessage(chain.consenter.retryOptions(), chain.haltChan, chain.producer, chain.channel)
+			go sendConnectMessage(chain.consenter.retryOptions(), chain.clock, chain.haltChan, chain.producer, chain.channel, chain.consenter.cluster().NodeID)
 		case in, ok := <-chain.channelConsumer.Messages():
 			if !ok {
-				logger.Criticalf("[channel: %s] Kafka consumer closed.", chain.support.ChainID())
+				chain.logger().Criticalf("Kafka consumer closed.")
 				return counts, nil
 			}
 			select {
 			case <-chain.errorChan: // If this channel was closed...
 				chain.errorChan = make(chan struct{}) // ...make a new one.
-				logger.Infof("[channel: %s] Marked consenter as available again", chain.support.ChainID())
+				consumerAvailable.With(chain.support.ChainID()).Set(1)
+				chain.logger().Infof("Marked consenter as available again")
 			default:
 			}
 			if err := proto.Unmarshal(in.Value, msg); err != nil {
 				// This shouldn't happen, it should be filtered at ingress
-				logger.Criticalf("[channel: %s] Unable to unmarshal consumed message = %s", chain.support.ChainID(), err)
+				chain.logger().Criticalf("Unable to unmarshal consumed message = %s", err)
 				counts[indexUnmarshalError]++
 				continue
 			} else {
-				logger.Debugf("[channel: %s] Successfully unmarshalled consumed message, offset is %d. Inspecting type...", chain.support.ChainID(), in.Offset)
+				chain.logger().Debugf("Successfully unmarshalled consumed message, offset is %d. Inspecting type...", in.Offset)
 				counts[indexRecvPass]++
 			}
 			switch msg.Type.(type) {
 			case *ab.KafkaMessage_Connect:
 				_ = processConnect(chain.support.ChainID())
+				chain.negotiator.advertise(msg.GetConnect(), in.Offset)
 				counts[indexProcessConnectPass]++
 			case *ab.KafkaMessage_TimeToCut:
-				if err := processTimeToCut(msg.GetTimeToCut(), chain.support, &chain.lastCutBlockNumber, &timer, in.Offset); err != nil {
-					logger.Warningf("[channel: %s] %s", chain.support.ChainID(), err)
-					logger.Criticalf("[channel: %s] Consenter for channel exiting", chain.support.ChainID())
+				if err := processTimeToCut(msg.GetTimeToCut(), chain.support, &chain.lastCutBlockNumber, &timer, in.Offset, chain.negotiator); err != nil {
+					chain.logger().Warningf("%s", err)
+					chain.logger().Criticalf("Consenter for channel exiting")
 					counts[indexProcessTimeToCutError]++
 					return counts, err // TODO Revisit whether we should indeed stop processing the chain at this point
 				}
 				counts[indexProcessTimeToCutPass]++
 			case *ab.KafkaMessage_Regular:
-				if err := processRegular(msg.GetRegular(), chain.support, &timer, in.Offset, &chain.lastCutBlockNumber); err != nil {
-					logger.Warningf("[channel: %s] Error when processing incoming message of type REGULAR = %s", chain.support.ChainID(), err)
+				if err := processRegular(msg.GetRegular(), chain.support, chain.clock, &timer, in.Offset, &chain.lastCutBlockNumber, chain.negotiator); err != nil {
+					chain.logger().Warningf("Error when processing incoming message of type REGULAR = %s", err)
 					counts[indexProcessRegularError]++
 				} else {
 					counts[indexProcessRegularPass]++
@@ -263,7 +291,7 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 			}
 		case <-timer:
 			if err := sendTimeToCut(chain.producer, chain.channel, chain.lastCutBlockNumber+1, &timer); err != nil {
-				logger.Errorf("[channel: %s] cannot post time-to-cut message = %s", chain.support.ChainID(), err)
+				chain.logger().Errorf("cannot post time-to-cut message = %s", err)
 				// Do not return though
 				counts[indexSendTimeToCutError]++
 			} else {
@@ -278,26 +306,26 @@ func (chain *chainImpl) closeKafkaObjects() []error {
 
 	err := chain.channelConsumer.Close()
 	if err != nil {
-		logger.Errorf("[channel: %s] could not close channelConsumer cleanly = %s", chain.support.ChainID(), err)
+		chain.logger().Errorf("could not close channelConsumer cleanly = %s", err)
 		errs = append(errs, err)
 	} else {
-		logger.Debugf("[channel: %s] Closed the channel consumer", chain.support.ChainID())
+		chain.logger().Debugf("Closed the channel consumer")
 	}
 
 	err = chain.parentConsumer.Close()
 	if err != nil {
-		logger.Errorf("[channel: %s] could not close parentConsumer cleanly = %s", chain.support.ChainID(), err)
+		chain.logger().Errorf("could not close parentConsumer cleanly = %s", err)
 		errs = append(errs, err)
 	} else {
-		logger.Debugf("[channel: %s] Closed the parent consumer", chain.support.ChainID())
+		chain.logger().Debugf("Closed the parent consumer")
 	}
 
 	err = chain.producer.Close()
 	if err != nil {
-		logger.Errorf("[channel: %s] could not close producer cleanly = %s", chain.support.ChainID(), err)
+		chain.logger().Errorf("could not close producer cleanly = %s", err)
 		errs = append(errs, err)
 	} else {
-		logger.Debugf("[channel: %s] Closed the producer", chain.support.ChainID())
+		chain.logger().Debugf("Closed the producer")
 	}
 
 	return errs
@@ -314,20 +342,27 @@ func getLastOffsetPersisted(metadataValue []byte, chainID string) int64 {
 		// Extract orderer-related metadata from the tip of the ledger first
 		kafkaMetadata := &ab.KafkaMetadata{}
 		if err := proto.Unmarshal(metadataValue, kafkaMetadata); err != nil {
-			logger.Panicf("[channel: %s] Ledger may be corrupted:"+
-				"cannot unmarshal orderer metadata in most recent block", chainID)
+			flogging.WithChannel(logger, chainID).Panicf("Ledger may be corrupted:" +
+				"cannot unmarshal orderer metadata in most recent block")
 		}
 		return kafkaMetadata.LastOffsetPersisted
 	}
 	return (sarama.OffsetOldest - 1) // default
 }
 
-func newConnectMessage() *ab.KafkaMessage {
+// newConnectMessage creates a CONNECT message advertising the newest wire
+// version this orderer supports, unless nodeID is unset
+func newConnectMessage(nodeID string) *ab.KafkaMessage {
+	connect := &ab.KafkaMessageConnect{
+		Payload: nil,
+	}
+	if nodeID != "" {
+		connect.NodeId = nodeID
+		connect.MaxWireVersion = maxWireVersion
+	}
 	return &ab.KafkaMessage{
 		Type: &ab.KafkaMessage_Connect{
-			Connect: &ab.KafkaMessageConnect{
-				Payload: nil,
-			},
+			Connect: connect,
 		},
 	}
 }
@@ -361,21 +396,26 @@ func newProducerMessage(channel channel, pld []byte) *sarama.ProducerMessage {
 }
 
 func processConnect(channelName string) error {
-	logger.Debugf("[channel: %s] It's a connect message - ignoring", channelName)
+	flogging.WithChannel(logger, channelName).Debugf("It's a connect message - ignoring")
 	return nil
 }
 
-func processRegular(regularMessage *ab.KafkaMessageRegular, support multichain.ConsenterSupport, timer *<-chan time.Time, receivedOffset int64, lastCutBlockNumber *uint64) error {
+func processRegular(regularMessage *ab.KafkaMessageRegular, support multichain.ConsenterSupport, clock clock.Clock, timer *<-chan time.Time, receivedOffset int64, lastCutBlockNumber *uint64, negotiator *negotiator) error {
 	env := new(cb.Envelope)
 	if err := proto.Unmarshal(regularMessage.Payload, env); err != nil {
 		// This shouldn't happen, it should be filtered at ingress
 		return fmt.Errorf("unmarshal/%s", err)
 	}
-	batches, committers, ok, pending := support.BlockCutter().Ordered(env)
-	logger.Debugf("[channel: %s] Ordering results: items in batch = %d, ok = %v, pending = %v", support.ChainID(), len(batches), ok, pending)
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		return fmt.Errorf("decode/%s", err)
+	}
+	batches, committers, ok, pending := support.BlockCutter().Ordered(msg)
+	flogging.WithChannel(logger, support.ChainID()).Debugf("Ordering results: items in batch = %d, ok = %v, pending = %v", len(batches), ok, pending)
 	if ok && len(batches) == 0 && *timer == nil {
-		*timer = time.After(support.SharedConfig().BatchTimeout())
-		logger.Debugf("[channel: %s] Just began %s batch timer", support.ChainID(), support.SharedConfig().BatchTimeout().String())
+		timeout := support.BatchTimeout()
+		*timer = clock.After(timeout)
+		flogging.WithChannel(logger, support.ChainID()).Debugf("Just began %s batch timer", timeout.String())
 		return nil
 	}
 
@@ -389,10 +429,10 @@ func processRegular(regularMessage *ab.KafkaMessageRegular, support multichain.C
 	// If !ok, batches == nil, so this will be skipped
 	for i, batch := range batches {
 		block := support.CreateNextBlock(batch)
-		encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: offset})
+		encodedLastOffsetPersisted := negotiator.metadata(offset)
 		support.WriteBlock(block, committers[i], encodedLastOffsetPersisted)
 		*lastCutBlockNumber++
-		logger.Debugf("[channel: %s] Batch filled, just cut block %d - last persisted offset is now %d", support.ChainID(), *lastCutBlockNumber, offset)
+		flogging.WithChannel(logger, support.ChainID()).Debugf("Batch filled, just cut block %d - last persisted offset is now %d", *lastCutBlockNumber, offset)
 		offset++
 	}
 
@@ -402,42 +442,42 @@ func processRegular(regularMessage *ab.KafkaMessageRegular, support multichain.C
 	return nil
 }
 
-func processTimeToCut(ttcMessage *ab.KafkaMessageTimeToCut, support multichain.ConsenterSupport, lastCutBlockNumber *uint64, timer *<-chan time.Time, receivedOffset int64) error {
+func processTimeToCut(ttcMessage *ab.KafkaMessageTimeToCut, support multichain.ConsenterSupport, lastCutBlockNumber *uint64, timer *<-chan time.Time, receivedOffset int64, negotiator *negotiator) error {
 	ttcNumber := ttcMessage.GetBlockNumber()
-	logger.Debugf("[channel: %s] It's a time-to-cut message for block %d", support.ChainID(), ttcNumber)
+	flogging.WithChannel(logger, support.ChainID()).Debugf("It's a time-to-cut message for block %d", ttcNumber)
 	if ttcNumber == *lastCutBlockNumber+1 {
 		*timer = nil
-		logger.Debugf("[channel: %s] Nil'd the timer", support.ChainID())
+		flogging.WithChannel(logger, support.ChainID()).Debugf("Nil'd the timer")
 		batch, committers := support.BlockCutter().Cut()
 		if len(batch) == 0 {
 			return fmt.Errorf("got right time-to-cut message (for block %d),"+
 				" no pending requests though; this might indicate a bug", *lastCutBlockNumber+1)
 		}
 		block := support.CreateNextBlock(batch)
-		encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: receivedOffset})
+		encodedLastOffsetPersisted := negotiator.metadata(receivedOffset)
 		support.WriteBlock(block, committers, encodedLastOffsetPersisted)
 		*lastCutBlockNumber++
-		logger.Debugf("[channel: %s] Proper time-to-cut received, just cut block %d", support.ChainID(), *lastCutBlockNumber)
+		flogging.WithChannel(logger, support.ChainID()).Debugf("Proper time-to-cut received, just cut block %d", *lastCutBlockNumber)
 		return nil
 	} else if ttcNumber > *lastCutBlockNumber+1 {
 		return fmt.Errorf("got larger time-to-cut message (%d) than allowed/expected (%d)"+
 			" - this might indicate a bug", ttcNumber, *lastCutBlockNumber+1)
 	}
-	logger.Debugf("[channel: %s] Ignoring stale time-to-cut-message for block %d", support.ChainID(), ttcNumber)
+	flogging.WithChannel(logger, support.ChainID()).Debugf("Ignoring stale time-to-cut-message for block %d", ttcNumber)
 	return nil
 }
 
 // Post a CONNECT message to the channel using the given retry options. This
 // prevents the panicking that would occur if we were to set up a consumer and
 // seek on a partition that hadn't been written to yet.
-func sendConnectMessage(retryOptions localconfig.Retry, exitChan chan struct{}, producer sarama.SyncProducer, channel channel) error {
-	logger.Infof("[channel: %s] About to post the CONNECT message...", channel.topic())
+func sendConnectMessage(retryOptions localconfig.Retry, clock clock.Clock, exitChan chan struct{}, producer sarama.SyncProducer, channel channel, nodeID string) error {
+	flogging.WithChannel(logger, channel.topic()).Infof("About to post the CONNECT message...")
 
-	payload := utils.MarshalOrPanic(newConnectMessage())
+	payload := utils.MarshalOrPanic(newConnectMessage(nodeID))
 	message := newProducerMessage(channel, payload)
 
 	retryMsg := "Attempting to post the CONNECT message..."
-	postConnect := newRetryProcess(retryOptions, exitChan, channel, retryMsg, func() error {
+	postConnect := newRetryProcess(retryOptions, clock, exitChan, channel, retryMsg, func() error {
 		_, _, err := producer.SendMessage(message)
 		return err
 	})
@@ -446,7 +486,7 @@ func sendConnectMessage(retryOptions localconfig.Retry, exitChan chan struct{},
 }
 
 func sendTimeToCut(producer sarama.SyncProducer, channel channel, timeToCutBlockNumber uint64, timer *<-chan time.Time) error {
-	logger.Debugf("[channel: %s] Time-to-cut block %d timer expired", channel.topic(), timeToCutBlockNumber)
+	flogging.WithChannel(logger, channel.topic()).Debugf("Time-to-cut block %d timer expired", timeToCutBlockNumber)
 	*timer = nil
 	payload := utils.MarshalOrPanic(newTimeToCutMessage(timeToCutBlockNumber))
 	message := newProducerMessage(channel, payload)
@@ -455,14 +495,14 @@ func sendTimeToCut(producer sarama.SyncProducer, channel channel, timeToCutBlock
 }
 
 // Sets up the partition consumer for a channel using the given retry options.
-func setupChannelConsumerForChannel(retryOptions localconfig.Retry, haltChan chan struct{}, parentConsumer sarama.Consumer, channel channel, startFrom int64) (sarama.PartitionConsumer, error) {
+func setupChannelConsumerForChannel(retryOptions localconfig.Retry, clock clock.Clock, haltChan chan struct{}, parentConsumer sarama.Consumer, channel channel, startFrom int64) (sarama.PartitionConsumer, error) {
 	var err error
 	var channelConsumer sarama.PartitionConsumer
 
-	logger.Infof("[channel: %s] Setting up the channel consumer for this channel (start offset: %d)...", channel.topic(), startFrom)
+	flogging.WithChannel(logger, channel.topic()).Infof("Setting up the channel consumer for this channel (start offset: %d)...", startFrom)
 
 	retryMsg := "Connecting to the Kafka cluster"
-	setupChannelConsumer := newRetryProcess(retryOptions, haltChan, channel, retryMsg, func() error {
+	setupChannelConsumer := newRetryProcess(retryOptions, clock, haltChan, channel, retryMsg, func() error {
 		channelConsumer, err = parentConsumer.ConsumePartition(channel.topic(), channel.partition(), startFrom)
 		return err
 	})
@@ -471,14 +511,14 @@ func setupChannelConsumerForChannel(retryOptions localconfig.Retry, haltChan cha
 }
 
 // Sets up the parent consumer for a channel using the given retry options.
-func setupParentConsumerForChannel(retryOptions localconfig.Retry, haltChan chan struct{}, brokers []string, brokerConfig *sarama.Config, channel channel) (sarama.Consumer, error) {
+func setupParentConsumerForChannel(retryOptions localconfig.Retry, clock clock.Clock, haltChan chan struct{}, brokers []string, brokerConfig *sarama.Config, channel channel) (sarama.Consumer, error) {
 	var err error
 	var parentConsumer sarama.Consumer
 
-	logger.Infof("[channel: %s] Setting up the parent consumer for this channel...", channel.topic())
+	flogging.WithChannel(logger, channel.topic()).Infof("Setting up the parent consumer for this channel...")
 
 	retryMsg := "Connecting to the Kafka cluster"
-	setupParentConsumer := newRetryProcess(retryOptions, haltChan, channel, retryMsg, func() error {
+	setupParentConsumer := newRetryProcess(retryOptions, clock, haltChan, channel, retryMsg, func() error {
 		parentConsumer, err = sarama.NewConsumer(brokers, brokerConfig)
 		return err
 	})
@@ -487,14 +527,14 @@ func setupParentConsumerForChannel(retryOptions localconfig.Retry, haltChan chan
 }
 
 // Sets up the writer/producer for a channel using the given retry options.
-func setupProducerForChannel(retryOptions localconfig.Retry, haltChan chan struct{}, brokers []string, brokerConfig *sarama.Config, channel channel) (sarama.SyncProducer, error) {
+func setupProducerForChannel(retryOptions localconfig.Retry, clock clock.Clock, haltChan chan struct{}, brokers []string, brokerConfig *sarama.Config, channel channel) (sarama.SyncProducer, error) {
 	var err error
 	var producer sarama.SyncProducer
 
-	logger.Infof("[channel: %s] Setting up the producer for this channel...", channel.topic())
+	flogging.WithChannel(logger, channel.topic()).Infof("Setting up the producer for this channel...")
 
 	retryMsg := "Connecting to the Kafka cluster"
-	setupProducer := newRetryProcess(retryOptions, haltChan, channel, retryMsg, func() error {
+	setupProducer := newRetryProcess(retryOptions, clock, haltChan, channel, retryMsg, func() error {
 		producer, err = sarama.NewSyncProducer(brokers, brokerConfig)
 		return err
 	})
@@ -13,7 +13,9 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/Shopify/sarama/mocks"
+	"github.com/golang/protobuf/proto"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/orderer/common/clock"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/blockcutter"
 	mockmultichain "github.com/hyperledger/fabric/orderer/mocks/multichain"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -23,9 +25,8 @@ import (
 )
 
 var (
-	extraShortTimeout = 1 * time.Millisecond
-	shortTimeout      = 1 * time.Second
-	longTimeout       = 1 * time.Hour
+	shortTimeout = 1 * time.Second
+	longTimeout  = 1 * time.Hour
 
 	hitBranch = 50 * time.Millisecond
 )
@@ -216,7 +217,7 @@ func TestChain(t *testing.T) {
 				SetMessage(mockChannel.topic(), mockChannel.partition(), newestOffset, message),
 		})
 
-		assert.False(t, chain.Enqueue(newMockEnvelope("fooMessage")), "Expected Enqueue call to return false")
+		assert.False(t, chain.Enqueue(newMockMessage("fooMessage")), "Expected Enqueue call to return false")
 	})
 
 	t.Run("StartWithConsumerForChannelError", func(t *testing.T) {
@@ -275,7 +276,7 @@ func TestChain(t *testing.T) {
 
 		// Enqueue should have access to the post path, and its ProduceRequest
 		// should go by without error
-		assert.True(t, chain.Enqueue(newMockEnvelope("fooMessage")), "Expected Enqueue call to return true")
+		assert.True(t, chain.Enqueue(newMockMessage("fooMessage")), "Expected Enqueue call to return true")
 
 		chain.Halt()
 	})
@@ -308,7 +309,7 @@ func TestChain(t *testing.T) {
 		chain.Halt()
 
 		// haltChan should close access to the post path
-		assert.False(t, chain.Enqueue(newMockEnvelope("fooMessage")), "Expected Enqueue call to return false")
+		assert.False(t, chain.Enqueue(newMockMessage("fooMessage")), "Expected Enqueue call to return false")
 	})
 
 	t.Run("EnqueueError", func(t *testing.T) {
@@ -345,7 +346,7 @@ func TestChain(t *testing.T) {
 				SetError(mockChannel.topic(), mockChannel.partition(), sarama.ErrNotLeaderForPartition),
 		})
 
-		assert.False(t, chain.Enqueue(newMockEnvelope("fooMessage")), "Expected Enqueue call to return false")
+		assert.False(t, chain.Enqueue(newMockMessage("fooMessage")), "Expected Enqueue call to return false")
 	})
 }
 
@@ -367,13 +368,13 @@ func TestSetupProducerForChannel(t *testing.T) {
 		metadataResponse.AddTopicPartition(mockChannel.topic(), mockChannel.partition(), mockBroker.BrokerID(), nil, nil, sarama.ErrNoError)
 		mockBroker.Returns(metadataResponse)
 
-		producer, err := setupProducerForChannel(mockConsenter.retryOptions(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
+		producer, err := setupProducerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
 		assert.NoError(t, err, "Expected the setupProducerForChannel call to return without errors")
 		assert.NoError(t, producer.Close(), "Expected to close the producer without errors")
 	})
 
 	t.Run("WithError", func(t *testing.T) {
-		_, err := setupProducerForChannel(mockConsenter.retryOptions(), haltChan, []string{}, mockBrokerConfig, mockChannel)
+		_, err := setupProducerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{}, mockBrokerConfig, mockChannel)
 		assert.Error(t, err, "Expected the setupProducerForChannel call to return an error")
 	})
 }
@@ -404,29 +405,29 @@ func TestSetupConsumerForChannel(t *testing.T) {
 	haltChan := make(chan struct{})
 
 	t.Run("ProperParent", func(t *testing.T) {
-		parentConsumer, err := setupParentConsumerForChannel(mockConsenter.retryOptions(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
+		parentConsumer, err := setupParentConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
 		assert.NoError(t, err, "Expected the setupParentConsumerForChannel call to return without errors")
 		assert.NoError(t, parentConsumer.Close(), "Expected to close the parentConsumer without errors")
 	})
 
 	t.Run("ProperChannel", func(t *testing.T) {
-		parentConsumer, _ := setupParentConsumerForChannel(mockConsenter.retryOptions(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
+		parentConsumer, _ := setupParentConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
 		defer func() { parentConsumer.Close() }()
-		channelConsumer, err := setupChannelConsumerForChannel(mockConsenter.retryOptions(), haltChan, parentConsumer, mockChannel, newestOffset)
+		channelConsumer, err := setupChannelConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, parentConsumer, mockChannel, newestOffset)
 		assert.NoError(t, err, "Expected the setupChannelConsumerForChannel call to return without errors")
 		assert.NoError(t, channelConsumer.Close(), "Expected to close the channelConsumer without errors")
 	})
 
 	t.Run("WithParentConsumerError", func(t *testing.T) {
 		// Provide an empty brokers list
-		_, err := setupParentConsumerForChannel(mockConsenter.retryOptions(), haltChan, []string{}, mockBrokerConfig, mockChannel)
+		_, err := setupParentConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{}, mockBrokerConfig, mockChannel)
 		assert.Error(t, err, "Expected the setupParentConsumerForChannel call to return an error")
 	})
 
 	t.Run("WithChannelConsumerError", func(t *testing.T) {
 		// Provide an out-of-range offset
-		parentConsumer, _ := setupParentConsumerForChannel(mockConsenter.retryOptions(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
-		_, err := setupChannelConsumerForChannel(mockConsenter.retryOptions(), haltChan, parentConsumer, mockChannel, newestOffset+1)
+		parentConsumer, _ := setupParentConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
+		_, err := setupChannelConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, parentConsumer, mockChannel, newestOffset+1)
 		defer func() { parentConsumer.Close() }()
 		assert.Error(t, err, "Expected the setupChannelConsumerForChannel call to return an error")
 	})
@@ -462,9 +463,9 @@ func TestCloseKafkaObjects(t *testing.T) {
 	haltChan := make(chan struct{})
 
 	t.Run("Proper", func(t *testing.T) {
-		producer, _ := setupProducerForChannel(mockConsenter.retryOptions(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
-		parentConsumer, _ := setupParentConsumerForChannel(mockConsenter.retryOptions(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
-		channelConsumer, _ := setupChannelConsumerForChannel(mockConsenter.retryOptions(), haltChan, parentConsumer, mockChannel, startFrom)
+		producer, _ := setupProducerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
+		parentConsumer, _ := setupParentConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, []string{mockBroker.Addr()}, mockBrokerConfig, mockChannel)
+		channelConsumer, _ := setupChannelConsumerForChannel(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, parentConsumer, mockChannel, startFrom)
 
 		// Set up a chain with just the minimum necessary fields instantiated so
 		// as to test the function
@@ -595,7 +596,7 @@ func TestSendConnectMessage(t *testing.T) {
 		successResponse.AddTopicPartition(mockChannel.topic(), mockChannel.partition(), sarama.ErrNoError)
 		mockBroker.Returns(successResponse)
 
-		assert.NoError(t, sendConnectMessage(mockConsenter.retryOptions(), haltChan, producer, mockChannel), "Expected the sendConnectMessage call to return without errors")
+		assert.NoError(t, sendConnectMessage(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, producer, mockChannel, ""), "Expected the sendConnectMessage call to return without errors")
 	})
 
 	t.Run("WithError", func(t *testing.T) {
@@ -609,7 +610,7 @@ func TestSendConnectMessage(t *testing.T) {
 		failureResponse.AddTopicPartition(mockChannel.topic(), mockChannel.partition(), sarama.ErrNotEnoughReplicas)
 		mockBroker.Returns(failureResponse)
 
-		assert.Error(t, sendConnectMessage(mockConsenter.retryOptions(), haltChan, producer, mockChannel), "Expected the sendConnectMessage call to return an error")
+		assert.Error(t, sendConnectMessage(mockConsenter.retryOptions(), mockConsenter.clock(), haltChan, producer, mockChannel, ""), "Expected the sendConnectMessage call to return an error")
 	})
 }
 
@@ -659,18 +660,8 @@ func TestSendTimeToCut(t *testing.T) {
 }
 
 func TestProcessMessagesToBlocks(t *testing.T) {
-	mockBroker := sarama.NewMockBroker(t, 0)
-	defer func() { mockBroker.Close() }()
-
 	mockChannel := newChannel("mockChannelFoo", defaultPartition)
 
-	metadataResponse := new(sarama.MetadataResponse)
-	metadataResponse.AddBroker(mockBroker.Addr(), mockBroker.BrokerID())
-	metadataResponse.AddTopicPartition(mockChannel.topic(), mockChannel.partition(), mockBroker.BrokerID(), nil, nil, sarama.ErrNoError)
-	mockBroker.Returns(metadataResponse)
-
-	producer, _ := sarama.NewSyncProducer([]string{mockBroker.Addr()}, mockBrokerConfig)
-
 	mockBrokerConfigCopy := *mockBrokerConfig
 	mockBrokerConfigCopy.ChannelBufferSize = 0
 
@@ -710,7 +701,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 		}()
 
 		// This is the wrappedMessage that the for-loop will process
-		mpc.YieldMessage(newMockConsumerMessage(newConnectMessage()))
+		mpc.YieldMessage(newMockConsumerMessage(newConnectMessage("")))
 
 		logger.Debug("Closing haltChan to exit the infinite for-loop")
 		close(haltChan) // Identical to chain.Halt()
@@ -770,6 +761,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 
 		lastCutBlockNumber := uint64(3)
 
+		clk := clock.NewManual(time.Unix(0, 0))
 		mockSupport := &mockmultichain.ConsenterSupport{
 			Blocks:         make(chan *cb.Block), // WriteBlock will post here
 			BlockCutterVal: mockblockcutter.NewReceiver(),
@@ -789,6 +781,8 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			support:            mockSupport,
 			lastCutBlockNumber: lastCutBlockNumber,
 
+			clock: clk,
+
 			errorChan: errorChan,
 			haltChan:  haltChan,
 		}
@@ -825,6 +819,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 
 		lastCutBlockNumber := uint64(3)
 
+		clk := clock.NewManual(time.Unix(0, 0))
 		mockSupport := &mockmultichain.ConsenterSupport{
 			Blocks:         make(chan *cb.Block), // WriteBlock will post here
 			BlockCutterVal: mockblockcutter.NewReceiver(),
@@ -844,6 +839,8 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			support:            mockSupport,
 			lastCutBlockNumber: lastCutBlockNumber,
 
+			clock: clk,
+
 			errorChan: errorChan,
 			haltChan:  haltChan,
 		}
@@ -887,6 +884,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 
 		lastCutBlockNumber := uint64(3)
 
+		clk := clock.NewManual(time.Unix(0, 0))
 		mockSupport := &mockmultichain.ConsenterSupport{
 			Blocks:         make(chan *cb.Block), // WriteBlock will post here
 			BlockCutterVal: mockblockcutter.NewReceiver(),
@@ -906,6 +904,8 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			support:            mockSupport,
 			lastCutBlockNumber: lastCutBlockNumber,
 
+			clock: clk,
+
 			errorChan: errorChan,
 			haltChan:  haltChan,
 		}
@@ -970,6 +970,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 
 		lastCutBlockNumber := uint64(3)
 
+		clk := clock.NewManual(time.Unix(0, 0))
 		mockSupport := &mockmultichain.ConsenterSupport{
 			Blocks:         make(chan *cb.Block), // WriteBlock will post here
 			BlockCutterVal: mockblockcutter.NewReceiver(),
@@ -989,6 +990,8 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			support:            mockSupport,
 			lastCutBlockNumber: lastCutBlockNumber,
 
+			clock: clk,
+
 			errorChan: errorChan,
 			haltChan:  haltChan,
 		}
@@ -1045,27 +1048,33 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 	})
 
 	t.Run("ReceiveRegularAndSendTimeToCut", func(t *testing.T) {
-		t.Skip("Skipping test as it introduces a race condition")
+		lastCutBlockNumber := uint64(3)
 
-		// NB We haven't set a handlermap for the mock broker so we need to set
-		// the ProduceResponse
-		successResponse := new(sarama.ProduceResponse)
-		successResponse.AddTopicPartition(mockChannel.topic(), mockChannel.partition(), sarama.ErrNoError)
-		mockBroker.Returns(successResponse)
+		// The producer reports the time-to-cut message it is asked to post,
+		// so that the chain is only halted once the timer has been handled
+		sent := make(chan uint64, 1)
+		producer := mocks.NewSyncProducer(t, mockBrokerConfig)
+		producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+			msg := new(ab.KafkaMessage)
+			if err := proto.Unmarshal(val, msg); err != nil {
+				return err
+			}
+			sent <- msg.GetTimeToCut().GetBlockNumber()
+			return nil
+		})
 
 		errorChan := make(chan struct{})
 		close(errorChan)
 		haltChan := make(chan struct{})
 
-		lastCutBlockNumber := uint64(3)
-
+		clk := clock.NewManual(time.Unix(0, 0))
 		mockSupport := &mockmultichain.ConsenterSupport{
 			Blocks:         make(chan *cb.Block), // WriteBlock will post here
 			BlockCutterVal: mockblockcutter.NewReceiver(),
 			ChainIDVal:     mockChannel.topic(),
 			HeightVal:      lastCutBlockNumber, // Incremented during the WriteBlock call
 			SharedConfigVal: &mockconfig.Orderer{
-				BatchTimeoutVal: extraShortTimeout, // ATTN
+				BatchTimeoutVal: longTimeout,
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
@@ -1079,6 +1088,8 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			support:            mockSupport,
 			lastCutBlockNumber: lastCutBlockNumber,
 
+			clock: clk,
+
 			errorChan: errorChan,
 			haltChan:  haltChan,
 		}
@@ -1097,14 +1108,16 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 		mockSupport.BlockCutterVal.Block <- struct{}{} // Let the `mockblockcutter.Ordered` call return
 		logger.Debugf("Mock blockcutter's Ordered call has returned")
 
-		// Sleep so that the timer branch is activated before the exitChan one.
-		// TODO This is a race condition, will fix in follow-up changeset
-		time.Sleep(hitBranch)
+		// Expire the batch timer which the REGULAR message started
+		clk.BlockUntil(1)
+		clk.Advance(longTimeout)
+		assert.Equal(t, lastCutBlockNumber+1, <-sent, "Expected a time-to-cut message for the next block")
 
 		logger.Debug("Closing haltChan to exit the infinite for-loop")
 		close(haltChan) // Identical to chain.Halt()
 		logger.Debug("haltChan closed")
 		<-done
+		assert.NoError(t, producer.Close())
 
 		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
 		assert.Equal(t, uint64(1), counts[indexRecvPass], "Expected 1 message received and unmarshaled")
@@ -1114,33 +1127,36 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 	})
 
 	t.Run("ReceiveRegularAndSendTimeToCutError", func(t *testing.T) {
-		// Note that this test is affected by the following parameters:
-		// - Net.ReadTimeout
-		// - Consumer.Retry.Backoff
-		// - Metadata.Retry.Max
+		// Exact same test as ReceiveRegularAndSendTimeToCut. Only difference is
+		// that the producer's attempt to send a TTC fails.
 
-		t.Skip("Skipping test as it introduces a race condition")
+		lastCutBlockNumber := uint64(3)
 
-		// Exact same test as ReceiveRegularAndSendTimeToCut.
-		// Only difference is that the producer's attempt to send a TTC will
-		// fail with an ErrNotEnoughReplicas error.
-		failureResponse := new(sarama.ProduceResponse)
-		failureResponse.AddTopicPartition(mockChannel.topic(), mockChannel.partition(), sarama.ErrNotEnoughReplicas)
-		mockBroker.Returns(failureResponse)
+		// The producer reports the time-to-cut message it is asked to post,
+		// so that the chain is only halted once the timer has been handled
+		sent := make(chan uint64, 1)
+		producer := mocks.NewSyncProducer(t, mockBrokerConfig)
+		producer.ExpectSendMessageWithCheckerFunctionAndFail(func(val []byte) error {
+			msg := new(ab.KafkaMessage)
+			if err := proto.Unmarshal(val, msg); err != nil {
+				return err
+			}
+			sent <- msg.GetTimeToCut().GetBlockNumber()
+			return nil
+		}, sarama.ErrNotEnoughReplicas)
 
 		errorChan := make(chan struct{})
 		close(errorChan)
 		haltChan := make(chan struct{})
 
-		lastCutBlockNumber := uint64(3)
-
+		clk := clock.NewManual(time.Unix(0, 0))
 		mockSupport := &mockmultichain.ConsenterSupport{
 			Blocks:         make(chan *cb.Block), // WriteBlock will post here
 			BlockCutterVal: mockblockcutter.NewReceiver(),
 			ChainIDVal:     mockChannel.topic(),
 			HeightVal:      lastCutBlockNumber, // Incremented during the WriteBlock call
 			SharedConfigVal: &mockconfig.Orderer{
-				BatchTimeoutVal: extraShortTimeout, // ATTN
+				BatchTimeoutVal: longTimeout,
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
@@ -1154,6 +1170,8 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			support:            mockSupport,
 			lastCutBlockNumber: lastCutBlockNumber,
 
+			clock: clk,
+
 			errorChan: errorChan,
 			haltChan:  haltChan,
 		}
@@ -1172,14 +1190,16 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 		mockSupport.BlockCutterVal.Block <- struct{}{} // Let the `mockblockcutter.Ordered` call return
 		logger.Debugf("Mock blockcutter's Ordered call has returned")
 
-		// Sleep so that the timer branch is activated before the exitChan one.
-		// TODO This is a race condition, will fix in follow-up changeset
-		time.Sleep(hitBranch)
+		// Expire the batch timer which the REGULAR message started
+		clk.BlockUntil(1)
+		clk.Advance(longTimeout)
+		assert.Equal(t, lastCutBlockNumber+1, <-sent, "Expected a time-to-cut message for the next block")
 
 		logger.Debug("Closing haltChan to exit the infinite for-loop")
 		close(haltChan) // Identical to chain.Halt()
 		logger.Debug("haltChan closed")
 		<-done
+		assert.NoError(t, producer.Close())
 
 		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
 		assert.Equal(t, uint64(1), counts[indexRecvPass], "Expected 1 message received and unmarshaled")
@@ -1221,7 +1241,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			logger.Debugf("Mock blockcutter's Ordered call has returned")
 		}()
 		// We are "planting" a message directly to the mock blockcutter
-		mockSupport.BlockCutterVal.Ordered(newMockEnvelope("fooMessage"))
+		mockSupport.BlockCutterVal.Ordered(newMockMessage("fooMessage"))
 
 		var counts []uint64
 		done := make(chan struct{})
@@ -9,6 +9,7 @@ package kafka
 import (
 	"github.com/Shopify/sarama"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/clock"
 	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/hyperledger/fabric/orderer/multichain"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -24,13 +25,21 @@ func init() {
 }
 
 // New creates a Kafka-based consenter. Called by orderer's main.go.
-func New(tlsConfig localconfig.TLS, retryOptions localconfig.Retry, kafkaVersion sarama.KafkaVersion) multichain.Consenter {
+func New(tlsConfig localconfig.TLS, retryOptions localconfig.Retry, kafkaVersion sarama.KafkaVersion, cluster localconfig.Cluster) multichain.Consenter {
+	return NewWithClock(tlsConfig, retryOptions, kafkaVersion, cluster, clock.Real())
+}
+
+// NewWithClock creates a Kafka-based consenter whose batch timers and retries
+// are driven by the clock
+func NewWithClock(tlsConfig localconfig.TLS, retryOptions localconfig.Retry, kafkaVersion sarama.KafkaVersion, cluster localconfig.Cluster, clock clock.Clock) multichain.Consenter {
 	brokerConfig := newBrokerConfig(tlsConfig, retryOptions, kafkaVersion, defaultPartition)
 	return &consenterImpl{
 		brokerConfigVal: brokerConfig,
 		tlsConfigVal:    tlsConfig,
 		retryOptionsVal: retryOptions,
-		kafkaVersionVal: kafkaVersion}
+		kafkaVersionVal: kafkaVersion,
+		clusterVal:      cluster,
+		clockVal:        clock}
 }
 
 // consenterImpl holds the implementation of type that satisfies the
@@ -41,6 +50,8 @@ type consenterImpl struct {
 	tlsConfigVal    localconfig.TLS
 	retryOptionsVal localconfig.Retry
 	kafkaVersionVal sarama.KafkaVersion
+	clusterVal      localconfig.Cluster
+	clockVal        clock.Clock
 }
 
 // HandleChain creates/returns a reference to a multichain.Chain object for the
@@ -50,7 +61,12 @@ type consenterImpl struct {
 // existingChains.
 func (consenter *consenterImpl) HandleChain(support multichain.ConsenterSupport, metadata *cb.Metadata) (multichain.Chain, error) {
 	lastOffsetPersisted := getLastOffsetPersisted(metadata.Value, support.ChainID())
-	return newChain(consenter, support, lastOffsetPersisted)
+	chain, err := newChain(consenter, support, lastOffsetPersisted)
+	if err != nil {
+		return nil, err
+	}
+	chain.negotiator = newNegotiator(support.ChainID(), consenter.cluster(), maxWireVersion, getKafkaMetadata(metadata.Value, support.ChainID()))
+	return chain, nil
 }
 
 // commonConsenter allows us to retrieve the configuration options set on the
@@ -60,6 +76,8 @@ func (consenter *consenterImpl) HandleChain(support multichain.ConsenterSupport,
 type commonConsenter interface {
 	brokerConfig() *sarama.Config
 	retryOptions() localconfig.Retry
+	cluster() localconfig.Cluster
+	clock() clock.Clock
 }
 
 func (consenter *consenterImpl) brokerConfig() *sarama.Config {
@@ -70,6 +88,14 @@ func (consenter *consenterImpl) retryOptions() localconfig.Retry {
 	return consenter.retryOptionsVal
 }
 
+func (consenter *consenterImpl) cluster() localconfig.Cluster {
+	return consenter.clusterVal
+}
+
+func (consenter *consenterImpl) clock() clock.Clock {
+	return consenter.clockVal
+}
+
 // closeable allows the shut down of the calling resource.
 type closeable interface {
 	close() error
@@ -18,6 +18,8 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/filter"
 	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/blockcutter"
 	mockmultichain "github.com/hyperledger/fabric/orderer/mocks/multichain"
@@ -59,11 +61,11 @@ func init() {
 }
 
 func TestNew(t *testing.T) {
-	_ = multichain.Consenter(New(mockLocalConfig.General.TLS, mockLocalConfig.Kafka.Retry, mockLocalConfig.Kafka.Version))
+	_ = multichain.Consenter(New(mockLocalConfig.General.TLS, mockLocalConfig.Kafka.Retry, mockLocalConfig.Kafka.Version, mockLocalConfig.Kafka.Cluster))
 }
 
 func TestHandleChain(t *testing.T) {
-	consenter := multichain.Consenter(New(mockLocalConfig.General.TLS, mockLocalConfig.Kafka.Retry, mockLocalConfig.Kafka.Version))
+	consenter := multichain.Consenter(New(mockLocalConfig.General.TLS, mockLocalConfig.Kafka.Retry, mockLocalConfig.Kafka.Version, mockLocalConfig.Kafka.Cluster))
 
 	oldestOffset := int64(0)
 	newestOffset := int64(5)
@@ -124,6 +126,7 @@ func newMockConsenter(brokerConfig *sarama.Config, tlsConfig localconfig.TLS, re
 		tlsConfigVal:    tlsConfig,
 		retryOptionsVal: retryOptions,
 		kafkaVersionVal: kafkaVersion,
+		clockVal:        clock.Real(),
 	}
 }
 
@@ -134,7 +137,18 @@ func newMockConsumerMessage(wrappedMessage *ab.KafkaMessage) *sarama.ConsumerMes
 }
 
 func newMockEnvelope(content string) *cb.Envelope {
-	return &cb.Envelope{Payload: []byte(content)}
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_MESSAGE)})},
+		Data:   []byte(content),
+	})}
+}
+
+func newMockMessage(content string) *filter.Message {
+	msg, err := filter.NewMessage(newMockEnvelope(content))
+	if err != nil {
+		panic(err)
+	}
+	return msg
 }
 
 func newMockLocalConfig(enableTLS bool, retryOptions localconfig.Retry, verboseLog bool) *localconfig.TopLevel {
@@ -164,7 +178,7 @@ func setupTestLogging(logLevel string, verbose bool) {
 }
 
 // Taken from orderer/solo/consensus_test.go
-func syncQueueMessage(message *cb.Envelope, chain *chainImpl, mockBlockcutter *mockblockcutter.Receiver) {
+func syncQueueMessage(message *filter.Message, chain *chainImpl, mockBlockcutter *mockblockcutter.Receiver) {
 	chain.Enqueue(message)
 	mockBlockcutter.Block <- struct{}{} // We'll move past this line (and the function will return) only when the mock blockcutter is about to return
 }
@@ -10,24 +10,28 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/clock"
 	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 )
 
 type retryProcess struct {
 	shortPollingInterval, shortTimeout time.Duration
 	longPollingInterval, longTimeout   time.Duration
+	clock                              clock.Clock
 	exit                               chan struct{}
 	channel                            channel
 	msg                                string
 	fn                                 func() error
 }
 
-func newRetryProcess(retryOptions localconfig.Retry, exit chan struct{}, channel channel, msg string, fn func() error) *retryProcess {
+func newRetryProcess(retryOptions localconfig.Retry, clock clock.Clock, exit chan struct{}, channel channel, msg string, fn func() error) *retryProcess {
 	return &retryProcess{
 		shortPollingInterval: retryOptions.ShortInterval,
 		shortTimeout:         retryOptions.ShortTotal,
 		longPollingInterval:  retryOptions.LongInterval,
 		longTimeout:          retryOptions.LongTotal,
+		clock:                clock,
 		exit:                 exit,
 		channel:              channel,
 		msg:                  msg,
@@ -37,7 +41,7 @@ func newRetryProcess(retryOptions localconfig.Retry, exit chan struct{}, channel
 
 func (rp *retryProcess) retry() error {
 	if err := rp.try(rp.shortPollingInterval, rp.shortTimeout); err != nil {
-		logger.Debugf("[channel: %s] Switching to the long retry interval", rp.channel.topic())
+		flogging.WithChannel(logger, rp.channel.topic()).Debugf("Switching to the long retry interval")
 		return rp.try(rp.longPollingInterval, rp.longTimeout)
 	}
 	return nil
@@ -55,17 +59,17 @@ func (rp *retryProcess) try(interval, total time.Duration) error {
 	var err error
 
 	// If initial operation is successful, we don't bother start retry process
-	logger.Debugf("[channel: %s] "+rp.msg, rp.channel.topic())
+	flogging.WithChannel(logger, rp.channel.topic()).Debugf("%s", rp.msg)
 	if err := rp.fn(); err == nil {
-		logger.Debugf("[channel: %s] Error is nil, breaking the retry loop", rp.channel.topic())
+		flogging.WithChannel(logger, rp.channel.topic()).Debugf("Error is nil, breaking the retry loop")
 		return err
 	}
 
-	tickInterval := time.NewTicker(interval)
-	tickTotal := time.NewTicker(total)
+	tickTotal := rp.clock.NewTimer(total)
 	defer tickTotal.Stop()
-	defer tickInterval.Stop()
-	logger.Debugf("[channel: %s] Retrying every %s for a total of %s", rp.channel.topic(), interval.String(), total.String())
+	tickInterval := rp.clock.NewTimer(interval)
+	defer func() { tickInterval.Stop() }()
+	flogging.WithChannel(logger, rp.channel.topic()).Debugf("Retrying every %s for a total of %s", interval.String(), total.String())
 
 	for {
 		select {
@@ -73,14 +77,15 @@ func (rp *retryProcess) try(interval, total time.Duration) error {
 			exitErr := fmt.Errorf("[channel: %s] process asked to exit", rp.channel.topic())
 			logger.Warning(exitErr.Error()) // Log it at the warning level
 			return exitErr
-		case <-tickTotal.C:
+		case <-tickTotal.C():
 			return err
-		case <-tickInterval.C:
-			logger.Debugf("[channel: %s] "+rp.msg, rp.channel.topic())
+		case <-tickInterval.C():
+			flogging.WithChannel(logger, rp.channel.topic()).Debugf("%s", rp.msg)
 			if err = rp.fn(); err == nil {
-				logger.Debugf("[channel: %s] Error is nil, breaking the retry loop", rp.channel.topic())
+				flogging.WithChannel(logger, rp.channel.topic()).Debugf("Error is nil, breaking the retry loop")
 				return err
 			}
+			tickInterval = rp.clock.NewTimer(interval)
 		}
 	}
 }
@@ -9,7 +9,10 @@ package kafka
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,14 +31,65 @@ func TestRetry(t *testing.T) {
 
 	t.Run("Proper", func(t *testing.T) {
 		exitChan := make(chan struct{})
-		rp = newRetryProcess(mockRetryOptions, exitChan, mockChannel, "foo", noErrorFn)
+		rp = newRetryProcess(mockRetryOptions, clock.Real(), exitChan, mockChannel, "foo", noErrorFn)
 		assert.NoError(t, rp.retry(), "Expected retry to return no errors")
 		assert.Equal(t, true, flag, "Expected flag to be set to true")
 	})
 
 	t.Run("WithError", func(t *testing.T) {
 		exitChan := make(chan struct{})
-		rp = newRetryProcess(mockRetryOptions, exitChan, mockChannel, "foo", errorFn)
+		rp = newRetryProcess(mockRetryOptions, clock.Real(), exitChan, mockChannel, "foo", errorFn)
 		assert.Error(t, rp.retry(), "Expected retry to return an error")
 	})
+
+	// The intervals do not divide the totals, so that no interval and total
+	// timers fire at once
+	retryOptions := localconfig.Retry{
+		ShortInterval: 40 * time.Millisecond,
+		ShortTotal:    100 * time.Millisecond,
+		LongInterval:  70 * time.Millisecond,
+		LongTotal:     100 * time.Millisecond,
+	}
+
+	t.Run("Backoff", func(t *testing.T) {
+		clk := clock.NewManual(time.Unix(0, 0))
+		tries := make(chan struct{}, 10)
+		rp = newRetryProcess(retryOptions, clk, make(chan struct{}), mockChannel, "foo", func() error {
+			tries <- struct{}{}
+			if len(tries) < 3 {
+				return fmt.Errorf("foo")
+			}
+			return nil
+		})
+		result := make(chan error)
+		go func() { result <- rp.retry() }()
+
+		// The operation is retried once per short interval
+		for i := 0; i < 2; i++ {
+			clk.BlockUntil(2)
+			assert.Len(t, tries, i+1, "Expected no retry before the interval passed")
+			clk.Advance(retryOptions.ShortInterval)
+		}
+		assert.NoError(t, <-result)
+		assert.Len(t, tries, 3)
+	})
+
+	t.Run("SwitchToLongInterval", func(t *testing.T) {
+		clk := clock.NewManual(time.Unix(0, 0))
+		tries := make(chan struct{}, 10)
+		rp = newRetryProcess(retryOptions, clk, make(chan struct{}), mockChannel, "foo", func() error {
+			tries <- struct{}{}
+			return fmt.Errorf("foo")
+		})
+		result := make(chan error)
+		go func() { result <- rp.retry() }()
+
+		// Short retries at 0, 40 and 80ms, then long ones at 100 and 170ms
+		for _, d := range []time.Duration{40, 40, 20, 70, 30} {
+			clk.BlockUntil(2)
+			clk.Advance(d * time.Millisecond)
+		}
+		assert.Error(t, <-result)
+		assert.Len(t, tries, 5)
+	})
 }
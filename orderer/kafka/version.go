@@ -0,0 +1,151 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+const (
+	// baseWireVersion is the wire format of the messages posted to the
+	// partition by every orderer, including those which predate negotiation
+	baseWireVersion uint32 = 1
+	// maxWireVersion is the newest wire format this orderer supports
+	maxWireVersion uint32 = 1
+)
+
+var wireVersionGauge = metrics.NewGauge(metrics.Opts{
+	Namespace:  "orderer",
+	Subsystem:  "consensus",
+	Name:       "kafka_wire_version",
+	Help:       "The wire version negotiated by the orderers of the consenter set of the channel.",
+	LabelNames: []string{"channel"},
+})
+
+// negotiator tracks the wire versions advertised in the CONNECT messages of a
+// partition by the members of the consenter set, and activates the newest
+// version every member supports. As every orderer consumes the same messages
+// in the same order, and persists the state of the negotiation in the
+// metadata of each block, a version activates at the same offset on every
+// orderer, and is never deactivated.
+type negotiator struct {
+	chainID    string
+	members    []string
+	max        uint32
+	active     uint32
+	advertised map[string]uint32
+}
+
+// newNegotiator resumes the negotiation from the metadata of the last block
+// of the chain, panicking if a version newer than max, the newest version this
+// orderer supports, is already active
+func newNegotiator(chainID string, cluster localconfig.Cluster, max uint32, metadata *ab.KafkaMetadata) *negotiator {
+	n := &negotiator{
+		chainID:    chainID,
+		members:    cluster.Members,
+		max:        max,
+		active:     metadata.GetWireVersion(),
+		advertised: make(map[string]uint32),
+	}
+	if n.active < baseWireVersion {
+		n.active = baseWireVersion
+	}
+	if n.active > n.max {
+		flogging.WithChannel(logger, chainID).Panicf("Wire version %d is active, but this orderer only supports up to version %d", n.active, n.max)
+	}
+	for _, advertised := range metadata.GetAdvertisedWireVersions() {
+		n.advertised[advertised.NodeId] = advertised.MaxWireVersion
+	}
+	if cluster.NodeID != "" && !contains(cluster.Members, cluster.NodeID) {
+		flogging.WithChannel(logger, chainID).Warningf("Node %s is not one of the cluster members %v, newer wire versions will be activated without it", cluster.NodeID, cluster.Members)
+	}
+	wireVersionGauge.With(chainID).Set(float64(n.active))
+	return n
+}
+
+// version returns the active wire version, which any wire format newer than
+// baseWireVersion must be gated on
+func (n *negotiator) version() uint32 {
+	if n == nil {
+		return baseWireVersion
+	}
+	return n.active
+}
+
+// advertise records the version advertised by a CONNECT message consumed at
+// offset, activating the newest version all members now support
+func (n *negotiator) advertise(connect *ab.KafkaMessageConnect, offset int64) {
+	if n == nil || connect.GetNodeId() == "" || !contains(n.members, connect.GetNodeId()) {
+		return
+	}
+	n.advertised[connect.GetNodeId()] = connect.GetMaxWireVersion()
+
+	supported := connect.GetMaxWireVersion()
+	for _, member := range n.members {
+		version, ok := n.advertised[member]
+		if !ok {
+			version = baseWireVersion
+		}
+		if version < supported {
+			supported = version
+		}
+	}
+	if supported <= n.active {
+		return
+	}
+	if supported > n.max {
+		flogging.WithChannel(logger, n.chainID).Panicf("Every cluster member supports wire version %d as of offset %d, but this orderer only supports up to version %d", supported, offset, n.max)
+	}
+	flogging.WithChannel(logger, n.chainID).Infof("Activating wire version %d at offset %d, every cluster member supports it", supported, offset)
+	n.active = supported
+	wireVersionGauge.With(n.chainID).Set(float64(n.active))
+}
+
+// metadata encodes the orderer metadata of a block, persisting the state of
+// the negotiation along with the last offset persisted
+func (n *negotiator) metadata(lastOffsetPersisted int64) []byte {
+	kafkaMetadata := &ab.KafkaMetadata{LastOffsetPersisted: lastOffsetPersisted}
+	if n != nil && len(n.advertised) > 0 {
+		kafkaMetadata.WireVersion = n.active
+		nodeIDs := make([]string, 0, len(n.advertised))
+		for nodeID := range n.advertised {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+		sort.Strings(nodeIDs)
+		for _, nodeID := range nodeIDs {
+			kafkaMetadata.AdvertisedWireVersions = append(kafkaMetadata.AdvertisedWireVersions, &ab.KafkaWireVersion{NodeId: nodeID, MaxWireVersion: n.advertised[nodeID]})
+		}
+	}
+	return utils.MarshalOrPanic(kafkaMetadata)
+}
+
+// getKafkaMetadata decodes the orderer metadata of the last block of a chain,
+// which is empty for a new chain
+func getKafkaMetadata(metadataValue []byte, chainID string) *ab.KafkaMetadata {
+	kafkaMetadata := &ab.KafkaMetadata{}
+	if err := proto.Unmarshal(metadataValue, kafkaMetadata); err != nil {
+		flogging.WithChannel(logger, chainID).Panicf("Ledger may be corrupted:" +
+			"cannot unmarshal orderer metadata in most recent block")
+	}
+	return kafkaMetadata
+}
+
+func contains(ss []string, s string) bool {
+	for _, candidate := range ss {
+		if candidate == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func advertisement(nodeID string, version uint32) *ab.KafkaMessageConnect {
+	return &ab.KafkaMessageConnect{NodeId: nodeID, MaxWireVersion: version}
+}
+
+func TestNegotiator(t *testing.T) {
+	cluster := localconfig.Cluster{NodeID: "a", Members: []string{"a", "b"}}
+	n := newNegotiator(channelNameForTest(t), cluster, 3, &ab.KafkaMetadata{})
+	assert.Equal(t, baseWireVersion, n.version())
+
+	n.advertise(advertisement("a", 2), 10)
+	n.advertise(advertisement("c", 1), 11)
+	n.advertise(&ab.KafkaMessageConnect{}, 12)
+	assert.Equal(t, baseWireVersion, n.version(), "A version should not be activated before every member advertises it")
+
+	n.advertise(advertisement("b", 3), 13)
+	assert.Equal(t, uint32(2), n.version(), "The newest version every member supports should be activated")
+
+	kafkaMetadata := &ab.KafkaMetadata{}
+	require.NoError(t, proto.Unmarshal(n.metadata(14), kafkaMetadata))
+	assert.Equal(t, int64(14), kafkaMetadata.LastOffsetPersisted)
+	assert.Equal(t, uint32(2), kafkaMetadata.WireVersion)
+	assert.Equal(t, []*ab.KafkaWireVersion{
+		{NodeId: "a", MaxWireVersion: 2},
+		{NodeId: "b", MaxWireVersion: 3},
+	}, kafkaMetadata.AdvertisedWireVersions, "Only the members should be recorded, ordered by node ID")
+
+	n.advertise(advertisement("a", 1), 15)
+	assert.Equal(t, uint32(2), n.version(), "An active version should never be deactivated")
+
+	resumed := newNegotiator(channelNameForTest(t), cluster, 3, kafkaMetadata)
+	assert.Equal(t, uint32(2), resumed.version(), "The negotiation should resume from the metadata")
+	resumed.advertise(advertisement("a", 3), 16)
+	assert.Equal(t, uint32(3), resumed.version())
+}
+
+func TestNegotiatorUnsupportedVersion(t *testing.T) {
+	cluster := localconfig.Cluster{NodeID: "a", Members: []string{"b"}}
+	assert.Panics(t, func() {
+		newNegotiator(channelNameForTest(t), cluster, maxWireVersion, &ab.KafkaMetadata{WireVersion: maxWireVersion + 1})
+	}, "A chain on which a newer version is active should not be resumed")
+
+	n := newNegotiator(channelNameForTest(t), cluster, maxWireVersion, &ab.KafkaMetadata{})
+	assert.Panics(t, func() {
+		n.advertise(advertisement("b", maxWireVersion+1), 10)
+	}, "A version this orderer does not support should not be activated")
+}
+
+func TestNegotiatorDisabled(t *testing.T) {
+	var n *negotiator
+	assert.Equal(t, baseWireVersion, n.version())
+	n.advertise(advertisement("a", 2), 10)
+	assert.Equal(t, utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: 10}), n.metadata(10))
+
+	n = newNegotiator(channelNameForTest(t), localconfig.Cluster{}, maxWireVersion, &ab.KafkaMetadata{})
+	n.advertise(advertisement("a", 2), 10)
+	assert.Equal(t, utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: 10}), n.metadata(10),
+		"The metadata should be unchanged when there are no cluster members")
+}
+
+func TestConnectMessageAdvertisement(t *testing.T) {
+	assert.Equal(t, &ab.KafkaMessageConnect{}, newConnectMessage("").GetConnect(), "Nothing should be advertised without a node ID")
+	assert.Equal(t, advertisement("a", maxWireVersion), newConnectMessage("a").GetConnect())
+}
@@ -24,6 +24,7 @@ import (
 	. "github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 type ledgerTestable interface {
@@ -256,3 +257,85 @@ func testMultichain(lf ledgerTestFactory, t *testing.T) {
 		t.Fatalf("Did not properly store block 1 on chain 1")
 	}
 }
+
+func TestTxIDSeek(t *testing.T) {
+	allTest(t, testTxIDSeek)
+}
+
+func makeTx(txID string) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{TxId: txID})},
+		}),
+	}
+}
+
+func seekTxID(txID string) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_TxId{TxId: &ab.SeekTxID{TxId: txID}}}
+}
+
+func testTxIDSeek(lf ledgerTestFactory, t *testing.T) {
+	_, li := lf.New()
+	li.Append(CreateNextBlock(li, []*cb.Envelope{makeTx("tx1"), makeTx("tx2")}))
+	li.Append(CreateNextBlock(li, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Data")}}))
+	li.Append(CreateNextBlock(li, []*cb.Envelope{makeTx("tx3")}))
+
+	for txID, expected := range map[string]uint64{"tx1": 1, "tx2": 1, "tx3": 3} {
+		it, num := li.Iterator(seekTxID(txID))
+		if num != expected {
+			t.Fatalf("Expected iterator for %s at %d, but got %d", txID, expected, num)
+		}
+		block, status := it.Next()
+		if status != cb.Status_SUCCESS {
+			t.Fatalf("Expected to successfully read the block containing %s", txID)
+		}
+		if block.Header.Number != expected {
+			t.Fatalf("Expected block %d to contain %s, but got block %d", expected, txID, block.Header.Number)
+		}
+	}
+
+	// Transactions appended after the ledger was first searched are found
+	li.Append(CreateNextBlock(li, []*cb.Envelope{makeTx("tx4")}))
+	if _, num := li.Iterator(seekTxID("tx4")); num != 4 {
+		t.Fatalf("Expected iterator for tx4 at 4, but got %d", num)
+	}
+
+	for _, txID := range []string{"unknown", ""} {
+		it, _ := li.Iterator(seekTxID(txID))
+		if _, status := it.Next(); status != cb.Status_NOT_FOUND {
+			t.Fatalf("Expected transaction %q not to be found, but got %v", txID, status)
+		}
+	}
+}
+
+func TestHashSeek(t *testing.T) {
+	allTest(t, testHashSeek)
+}
+
+func seekHash(hash []byte) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Hash{Hash: &ab.SeekBlockHash{Hash: hash}}}
+}
+
+func testHashSeek(lf ledgerTestFactory, t *testing.T) {
+	_, li := lf.New()
+	li.Append(CreateNextBlock(li, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Data")}}))
+	li.Append(CreateNextBlock(li, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Other Data")}}))
+
+	for number := uint64(0); number < li.Height(); number++ {
+		it, num := li.Iterator(seekHash(GetBlock(li, number).Header.Hash()))
+		if num != number {
+			t.Fatalf("Expected iterator at %d, but got %d", number, num)
+		}
+		block, status := it.Next()
+		if status != cb.Status_SUCCESS || block.Header.Number != number {
+			t.Fatalf("Expected to successfully read block %d", number)
+		}
+	}
+
+	for _, hash := range [][]byte{[]byte("unknown"), nil} {
+		it, _ := li.Iterator(seekHash(hash))
+		if _, status := it.Next(); status != cb.Status_NOT_FOUND {
+			t.Fatalf("Expected hash %x not to be found, but got %v", hash, status)
+		}
+	}
+}
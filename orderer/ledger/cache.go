@@ -0,0 +1,207 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+var (
+	cacheHits = metrics.NewCounter(metrics.Opts{
+		Namespace:  "orderer",
+		Subsystem:  "ledger_cache",
+		Name:       "hits_total",
+		Help:       "The number of blocks read by iterators which were served from the cache of recent blocks.",
+		LabelNames: []string{"channel"},
+	})
+	cacheMisses = metrics.NewCounter(metrics.Opts{
+		Namespace:  "orderer",
+		Subsystem:  "ledger_cache",
+		Name:       "misses_total",
+		Help:       "The number of blocks read by iterators which were read from the ledger.",
+		LabelNames: []string{"channel"},
+	})
+)
+
+// NewCachingFactory wraps the ledgers of the Factory so that the blocks most
+// recently appended to each, up to size per chain, are kept in memory and
+// served to iterators from there, so that the readers following the tip of a
+// chain never touch the underlying ledger. The cache of a chain is filled with
+// its most recent blocks when its ledger is first opened, so that the readers
+// which resume following the tip after a restart do not all read them from
+// the underlying ledger, and then with the blocks appended through the
+// returned Factory. The Factory is returned as is if size is not positive.
+func NewCachingFactory(factory Factory, size int) Factory {
+	if size <= 0 {
+		return factory
+	}
+	return &cachingFactory{
+		Factory: factory,
+		size:    size,
+		ledgers: make(map[string]*cachingLedger),
+	}
+}
+
+type cachingFactory struct {
+	Factory
+	size int
+
+	lock    sync.Mutex
+	ledgers map[string]*cachingLedger
+}
+
+// GetOrCreate returns the caching ledger of the chain, the same one each time,
+// so that every reader of the chain sees the blocks appended by its writer
+func (cf *cachingFactory) GetOrCreate(chainID string) (ReadWriter, error) {
+	cf.lock.Lock()
+	defer cf.lock.Unlock()
+	if cl, ok := cf.ledgers[chainID]; ok {
+		return cl, nil
+	}
+	rw, err := cf.Factory.GetOrCreate(chainID)
+	if err != nil {
+		return nil, err
+	}
+	cl := &cachingLedger{
+		ReadWriter: rw,
+		chainID:    chainID,
+		blocks:     make([]*cb.Block, cf.size),
+		signal:     make(chan struct{}),
+	}
+	cl.warm()
+	cf.ledgers[chainID] = cl
+	return cl, nil
+}
+
+type cachingLedger struct {
+	ReadWriter
+	chainID string
+
+	lock sync.RWMutex
+	// blocks holds each cached block at its number modulo the size of the
+	// cache
+	blocks []*cb.Block
+	// signal is closed, and replaced, whenever a block is cached
+	signal chan struct{}
+}
+
+// warm caches the most recent blocks already on the underlying ledger. The
+// cache is only an optimization, so warming stops at the first block which
+// cannot be read.
+func (cl *cachingLedger) warm() {
+	height := cl.ReadWriter.Height()
+	count := uint64(len(cl.blocks))
+	if height < count {
+		count = height
+	}
+	if count == 0 {
+		return
+	}
+	it, _ := cl.ReadWriter.Iterator(&ab.SeekPosition{
+		Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: height - count}},
+	})
+	for i := uint64(0); i < count; i++ {
+		block, status := it.Next()
+		if status != cb.Status_SUCCESS {
+			return
+		}
+		cl.blocks[block.Header.Number%uint64(len(cl.blocks))] = block
+	}
+}
+
+// Append appends the block to the underlying ledger and, once it is
+// committed, caches it
+func (cl *cachingLedger) Append(block *cb.Block) error {
+	if err := cl.ReadWriter.Append(block); err != nil {
+		return err
+	}
+	cl.lock.Lock()
+	defer cl.lock.Unlock()
+	cl.blocks[block.Header.Number%uint64(len(cl.blocks))] = block
+	close(cl.signal)
+	cl.signal = make(chan struct{})
+	return nil
+}
+
+// Iterator returns an Iterator which serves the blocks from the cache while
+// they are there, and from the underlying ledger otherwise
+func (cl *cachingLedger) Iterator(startPosition *ab.SeekPosition) (Iterator, uint64) {
+	it, number := cl.ReadWriter.Iterator(startPosition)
+	if _, ok := it.(*NotFoundErrorIterator); ok {
+		return it, number
+	}
+	return &cachingIterator{ledger: cl, next: number, it: it, itNext: number}, number
+}
+
+// cached returns the cached block with the number, or nil, along with the
+// signal to wait on for the next block to be cached
+func (cl *cachingLedger) cached(number uint64) (*cb.Block, <-chan struct{}) {
+	cl.lock.RLock()
+	defer cl.lock.RUnlock()
+	block := cl.blocks[number%uint64(len(cl.blocks))]
+	if block == nil || block.Header.Number != number {
+		return nil, cl.signal
+	}
+	return block, cl.signal
+}
+
+type cachingIterator struct {
+	ledger *cachingLedger
+	next   uint64
+	// it is the iterator of the underlying ledger, positioned at itNext,
+	// which lags next while the blocks are served from the cache
+	it     Iterator
+	itNext uint64
+}
+
+// Next blocks until there is a new block available, or returns an error if the
+// next block is no longer retrievable
+func (ci *cachingIterator) Next() (*cb.Block, cb.Status) {
+	for {
+		block, signal := ci.ledger.cached(ci.next)
+		if block != nil {
+			cacheHits.With(ci.ledger.chainID).Add(1)
+			ci.next++
+			return block, cb.Status_SUCCESS
+		}
+		if ci.next < ci.ledger.Height() {
+			cacheMisses.With(ci.ledger.chainID).Add(1)
+			return ci.read()
+		}
+		<-signal
+	}
+}
+
+// ReadyChan supplies a channel which will block until Next will not block
+func (ci *cachingIterator) ReadyChan() <-chan struct{} {
+	block, signal := ci.ledger.cached(ci.next)
+	if block != nil || ci.next < ci.ledger.Height() {
+		return closedChan
+	}
+	return signal
+}
+
+// read reads the next block from the underlying ledger, repositioning its
+// iterator first if blocks have been served from the cache since it was last
+// read
+func (ci *cachingIterator) read() (*cb.Block, cb.Status) {
+	if ci.itNext != ci.next {
+		ci.it, ci.itNext = ci.ledger.ReadWriter.Iterator(&ab.SeekPosition{
+			Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: ci.next}},
+		})
+	}
+	block, status := ci.it.Next()
+	if status == cb.Status_SUCCESS {
+		ci.next++
+		ci.itNext++
+	}
+	return block, status
+}
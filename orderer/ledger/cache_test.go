@@ -0,0 +1,202 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	. "github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	testables = append(testables, &cachingLedgerTestEnv{})
+}
+
+type cachingLedgerTestFactory struct{}
+
+type cachingLedgerTestEnv struct{}
+
+func (env *cachingLedgerTestEnv) Initialize() (ledgerTestFactory, error) {
+	return &cachingLedgerTestFactory{}, nil
+}
+
+func (env *cachingLedgerTestEnv) Name() string {
+	return "cachingledger"
+}
+
+func (env *cachingLedgerTestFactory) Destroy() error {
+	return nil
+}
+
+func (env *cachingLedgerTestFactory) Persistent() bool {
+	return false
+}
+
+func (env *cachingLedgerTestFactory) New() (Factory, ReadWriter) {
+	clf := NewCachingFactory(ramledger.New(10), 3)
+	cl, err := clf.GetOrCreate(provisional.TestChainID)
+	if err != nil {
+		panic(err)
+	}
+	err = cl.Append(genesisBlock)
+	if err != nil {
+		panic(err)
+	}
+	return clf, cl
+}
+
+// countingFactory counts the blocks read from the iterators of its ledgers
+type countingFactory struct {
+	Factory
+	reads int
+}
+
+func (cf *countingFactory) GetOrCreate(chainID string) (ReadWriter, error) {
+	rw, err := cf.Factory.GetOrCreate(chainID)
+	return &countingLedger{ReadWriter: rw, factory: cf}, err
+}
+
+type countingLedger struct {
+	ReadWriter
+	factory *countingFactory
+}
+
+func (cl *countingLedger) Iterator(startPosition *ab.SeekPosition) (Iterator, uint64) {
+	it, number := cl.ReadWriter.Iterator(startPosition)
+	return &countingIterator{Iterator: it, factory: cl.factory}, number
+}
+
+type countingIterator struct {
+	Iterator
+	factory *countingFactory
+}
+
+func (ci *countingIterator) Next() (*cb.Block, cb.Status) {
+	ci.factory.reads++
+	return ci.Iterator.Next()
+}
+
+func seekTo(number uint64) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: number}}}
+}
+
+func TestCachingFactory(t *testing.T) {
+	rlf := ramledger.New(10)
+	assert.Equal(t, rlf, NewCachingFactory(rlf, 0), "The factory should not be wrapped when the cache is disabled")
+
+	clf := NewCachingFactory(rlf, 3)
+	first, err := clf.GetOrCreate("foo")
+	require.NoError(t, err)
+	second, err := clf.GetOrCreate("foo")
+	require.NoError(t, err)
+	assert.True(t, first == second, "Each chain should have a single caching ledger")
+}
+
+func TestCacheHits(t *testing.T) {
+	cf := &countingFactory{Factory: ramledger.New(10)}
+	cl, err := NewCachingFactory(cf, 3).GetOrCreate(provisional.TestChainID)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, cl.Append(CreateNextBlock(cl, []*cb.Envelope{{Payload: []byte("foo")}})))
+	}
+	cf.reads = 0
+
+	it, number := cl.Iterator(seekTo(2))
+	assert.Equal(t, uint64(2), number)
+	for i := uint64(2); i < 5; i++ {
+		block, status := it.Next()
+		require.Equal(t, cb.Status_SUCCESS, status)
+		assert.Equal(t, i, block.Header.Number)
+	}
+	assert.Equal(t, 0, cf.reads, "The most recent blocks should be served from the cache")
+
+	it, _ = cl.Iterator(seekTo(0))
+	for i := uint64(0); i < 5; i++ {
+		block, status := it.Next()
+		require.Equal(t, cb.Status_SUCCESS, status)
+		assert.Equal(t, i, block.Header.Number)
+	}
+	assert.Equal(t, 2, cf.reads, "The blocks no longer cached should be read from the ledger")
+}
+
+func TestCacheWarm(t *testing.T) {
+	cf := &countingFactory{Factory: ramledger.New(10)}
+	l, err := cf.GetOrCreate(provisional.TestChainID)
+	require.NoError(t, err)
+	require.NoError(t, l.Append(genesisBlock))
+	for i := 1; i < 5; i++ {
+		require.NoError(t, l.Append(CreateNextBlock(l, []*cb.Envelope{{Payload: []byte("foo")}})))
+	}
+
+	cf.reads = 0
+
+	// The blocks already on the ledger are cached when it is opened
+	cl, err := NewCachingFactory(cf, 3).GetOrCreate(provisional.TestChainID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, cf.reads, "The most recent blocks should be read once to fill the cache")
+	cf.reads = 0
+
+	it, _ := cl.Iterator(seekTo(2))
+	for i := uint64(2); i < 5; i++ {
+		block, status := it.Next()
+		require.Equal(t, cb.Status_SUCCESS, status)
+		assert.Equal(t, i, block.Header.Number)
+	}
+	assert.Equal(t, 0, cf.reads, "The most recent blocks should be served from the cache")
+
+	// A ledger holding fewer blocks than the cache is cached whole
+	cf = &countingFactory{Factory: ramledger.New(10)}
+	l, err = cf.GetOrCreate(provisional.TestChainID)
+	require.NoError(t, err)
+	require.NoError(t, l.Append(genesisBlock))
+	cf.reads = 0
+	cl, err = NewCachingFactory(cf, 3).GetOrCreate(provisional.TestChainID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cf.reads)
+
+	// An empty ledger has nothing to cache
+	empty, err := NewCachingFactory(ramledger.New(10), 3).GetOrCreate("empty")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), empty.Height())
+}
+
+func TestCacheFollowsTip(t *testing.T) {
+	cf := &countingFactory{Factory: ramledger.New(10)}
+	cl, err := NewCachingFactory(cf, 3).GetOrCreate(provisional.TestChainID)
+	require.NoError(t, err)
+	require.NoError(t, cl.Append(genesisBlock))
+	cf.reads = 0
+
+	it, _ := cl.Iterator(seekTo(1))
+	select {
+	case <-it.ReadyChan():
+		t.Fatal("The iterator should not be ready before the block is appended")
+	default:
+	}
+
+	received := make(chan *cb.Block)
+	go func() {
+		block, _ := it.Next()
+		received <- block
+	}()
+	require.NoError(t, cl.Append(CreateNextBlock(cl, []*cb.Envelope{{Payload: []byte("foo")}})))
+
+	select {
+	case block := <-received:
+		assert.Equal(t, uint64(1), block.Header.Number)
+	case <-time.After(time.Second):
+		t.Fatal("The iterator should return the appended block")
+	}
+	assert.Equal(t, 0, cf.reads, "The appended block should be served from the cache")
+}
@@ -24,6 +24,20 @@ import (
 	"github.com/hyperledger/fabric/orderer/ledger"
 )
 
+// indexConfig indexes blocks by number, by hash, and by the IDs of their
+// transactions, so that deliver clients may seek a block by its hash or by a
+// transaction it contains. The blocks appended before the hashes and
+// transaction IDs were indexed are not found by them.
+var indexConfig = &blkstorage.IndexConfig{
+	AttrsToIndex: []blkstorage.IndexableAttr{blkstorage.IndexableAttrBlockNum, blkstorage.IndexableAttrBlockHash, blkstorage.IndexableAttrBlockTxID},
+}
+
+// verifyIndexConfig is the part of the index which is verified, as the
+// ledgers created before the blocks were indexed by hash lack that index
+var verifyIndexConfig = &blkstorage.IndexConfig{
+	AttrsToIndex: []blkstorage.IndexableAttr{blkstorage.IndexableAttrBlockNum},
+}
+
 type fileLedgerFactory struct {
 	blkstorageProvider blkstorage.BlockStoreProvider
 	ledgers            map[string]ledger.ReadWriter
@@ -68,11 +82,7 @@ func (flf *fileLedgerFactory) Close() {
 // New creates a new ledger factory
 func New(directory string) ledger.Factory {
 	return &fileLedgerFactory{
-		blkstorageProvider: fsblkstorage.NewProvider(
-			fsblkstorage.NewConf(directory, -1),
-			&blkstorage.IndexConfig{
-				AttrsToIndex: []blkstorage.IndexableAttr{blkstorage.IndexableAttrBlockNum}},
-		),
-		ledgers: make(map[string]ledger.ReadWriter),
+		blkstorageProvider: fsblkstorage.NewProvider(fsblkstorage.NewConf(directory, -1), indexConfig),
+		ledgers:            make(map[string]ledger.ReadWriter),
 	}
 }
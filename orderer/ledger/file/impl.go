@@ -86,6 +86,26 @@ func (fl *fileLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 			return &ledger.NotFoundErrorIterator{}, 0
 		}
 		return &fileLedgerIterator{ledger: fl, blockNumber: start.Specified.Number}, start.Specified.Number
+	case *ab.SeekPosition_TxId:
+		if start.TxId.GetTxId() == "" {
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		block, err := fl.blockStore.RetrieveBlockByTxID(start.TxId.GetTxId())
+		if err != nil {
+			logger.Debugf("Transaction %s not found: %s", start.TxId.GetTxId(), err)
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		return &fileLedgerIterator{ledger: fl, blockNumber: block.Header.Number}, block.Header.Number
+	case *ab.SeekPosition_Hash:
+		if len(start.Hash.GetHash()) == 0 {
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		block, err := fl.blockStore.RetrieveBlockByHash(start.Hash.GetHash())
+		if err != nil {
+			logger.Debugf("Block with hash %x not found: %s", start.Hash.GetHash(), err)
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		return &fileLedgerIterator{ledger: fl, blockNumber: block.Header.Number}, block.Header.Number
 	default:
 		return &ledger.NotFoundErrorIterator{}, 0
 	}
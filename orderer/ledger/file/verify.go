@@ -0,0 +1,36 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileledger
+
+import (
+	"path/filepath"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/orderer/ledger"
+)
+
+// ChainIDs returns the chains with a ledger in directory, without opening
+// them
+func ChainIDs(directory string) ([]string, error) {
+	return util.ListSubdirs(filepath.Join(directory, fsblkstorage.ChainsDir))
+}
+
+// Verify checks the block files of the chain in directory form a hash chain
+// and that their index by number and checkpoints are consistent with them. It must not
+// be run while an orderer is using the ledger.
+func Verify(directory, chainID string) (*ledger.VerifyResult, error) {
+	result, err := fsblkstorage.Verify(fsblkstorage.NewConf(directory, -1), verifyIndexConfig, chainID)
+	if err != nil {
+		return nil, err
+	}
+	verified := &ledger.VerifyResult{Height: result.Height}
+	for _, problem := range result.Problems {
+		verified.Problems = append(verified.Problems, ledger.Problem{BlockNumber: problem.BlockNum, Description: problem.Description})
+	}
+	return verified, nil
+}
@@ -0,0 +1,43 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileledger
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hyperledger_fabric")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lf := New(dir)
+	rl, err := lf.GetOrCreate("foo")
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, rl.Append(ledger.CreateNextBlock(rl, []*cb.Envelope{{Payload: []byte{byte(i)}}})))
+	}
+	lf.Close()
+
+	chainIDs, err := ChainIDs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, chainIDs)
+
+	result, err := Verify(dir, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, &ledger.VerifyResult{Height: 4}, result)
+
+	_, err = Verify(dir, "bar")
+	assert.Error(t, err)
+}
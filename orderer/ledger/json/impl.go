@@ -56,6 +56,14 @@ type jsonLedger struct {
 	signal    chan struct{}
 	lastHash  []byte
 	marshaler *jsonpb.Marshaler
+
+	indexLock sync.Mutex
+	// txIndex maps the ID of each transaction to the number of the most
+	// recent block containing it, and hashIndex the hash of the header of each
+	// block to its number, for the blocks below indexedHeight
+	txIndex       map[string]uint64
+	hashIndex     map[string]uint64
+	indexedHeight uint64
 }
 
 // readBlock returns the block or nil, and whether the block was found or not, (nil,true) generally indicates an irrecoverable problem
@@ -122,11 +130,72 @@ func (jl *jsonLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 			return &ledger.NotFoundErrorIterator{}, 0
 		}
 		return &cursor{jl: jl, blockNumber: start.Specified.Number}, start.Specified.Number
+	case *ab.SeekPosition_TxId:
+		number, ok := jl.blockByTxID(start.TxId.GetTxId())
+		if !ok {
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		return &cursor{jl: jl, blockNumber: number}, number
+	case *ab.SeekPosition_Hash:
+		number, ok := jl.blockByHash(start.Hash.GetHash())
+		if !ok {
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		return &cursor{jl: jl, blockNumber: number}, number
 	default:
 		return &ledger.NotFoundErrorIterator{}, 0
 	}
 }
 
+// blockByTxID returns the number of the most recent block containing the
+// transaction with the ID
+func (jl *jsonLedger) blockByTxID(txID string) (uint64, bool) {
+	if txID == "" {
+		return 0, false
+	}
+	jl.indexLock.Lock()
+	defer jl.indexLock.Unlock()
+	jl.index()
+	number, ok := jl.txIndex[txID]
+	return number, ok
+}
+
+// blockByHash returns the number of the block whose header has the hash
+func (jl *jsonLedger) blockByHash(hash []byte) (uint64, bool) {
+	if len(hash) == 0 {
+		return 0, false
+	}
+	jl.indexLock.Lock()
+	defer jl.indexLock.Unlock()
+	jl.index()
+	number, ok := jl.hashIndex[string(hash)]
+	return number, ok
+}
+
+// index indexes the blocks appended since it was last called. The index is
+// built when it is first consulted, so that ledgers which are never searched
+// pay nothing for it. The indexLock must be held.
+func (jl *jsonLedger) index() {
+	if jl.txIndex == nil {
+		jl.txIndex = make(map[string]uint64)
+		jl.hashIndex = make(map[string]uint64)
+	}
+	for height := jl.Height(); jl.indexedHeight < height; jl.indexedHeight++ {
+		block, _ := jl.readBlock(jl.indexedHeight)
+		if block == nil {
+			// The block is indexed when the index is next consulted
+			logger.Warningf("Could not read block %d to index it", jl.indexedHeight)
+			return
+		}
+		jl.hashIndex[string(block.Header.Hash())] = jl.indexedHeight
+		for _, id := range ledger.TxIDs(block) {
+			if id != "" {
+				jl.txIndex[id] = jl.indexedHeight
+			}
+		}
+	}
+}
+
 // Height returns the number of blocks on the ledger
 func (jl *jsonLedger) Height() uint64 {
 	return jl.height
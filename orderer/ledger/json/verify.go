@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jsonledger
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// ChainIDs returns the chains with a ledger in directory
+func ChainIDs(directory string) ([]string, error) {
+	infos, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf(chainDirectoryFormatString, "")
+	var chainIDs []string
+	for _, info := range infos {
+		if info.IsDir() && strings.HasPrefix(info.Name(), prefix) {
+			chainIDs = append(chainIDs, strings.TrimPrefix(info.Name(), prefix))
+		}
+	}
+	return chainIDs, nil
+}
+
+// Verify checks the block files of the chain in directory are consecutive,
+// each holding the block its name numbers, and form a hash chain
+func Verify(directory, chainID string) (*ledger.VerifyResult, error) {
+	chainDir := filepath.Join(directory, fmt.Sprintf(chainDirectoryFormatString, chainID))
+	infos, err := ioutil.ReadDir(chainDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ledger.VerifyResult{}
+	problem := func(number uint64, format string, args ...interface{}) {
+		result.Problems = append(result.Problems, ledger.Problem{BlockNumber: number, Description: fmt.Sprintf(format, args...)})
+	}
+
+	var previous *cb.BlockHeader
+	for _, info := range infos {
+		var number uint64
+		if info.IsDir() {
+			continue
+		}
+		if _, err := fmt.Sscanf(info.Name(), blockFileFormatString, &number); err != nil {
+			continue
+		}
+		if number != result.Height {
+			problem(result.Height, "block file is missing, found block file %d", number)
+			break
+		}
+
+		block, err := readBlockFile(filepath.Join(chainDir, info.Name()))
+		if err != nil {
+			problem(number, "%s", err)
+			break
+		}
+		if block.Header.Number != number {
+			problem(number, "block file holds block %d", block.Header.Number)
+			break
+		}
+		if previous != nil && !bytes.Equal(block.Header.PreviousHash, previous.Hash()) {
+			problem(number, "previous hash %x does not match the hash %x of block %d", block.Header.PreviousHash, previous.Hash(), previous.Number)
+		}
+		if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+			problem(number, "data hash %x does not match the hash %x of its data", block.Header.DataHash, block.Data.Hash())
+		}
+		previous = block.Header
+		result.Height++
+	}
+	return result, nil
+}
+
+func readBlockFile(name string) (*cb.Block, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	block := &cb.Block{}
+	if err := jsonpb.Unmarshal(file, block); err != nil {
+		return nil, fmt.Errorf("could not decode block file: %s", err)
+	}
+	if block.Header == nil || block.Data == nil {
+		return nil, fmt.Errorf("block file holds no header or data")
+	}
+	return block, nil
+}
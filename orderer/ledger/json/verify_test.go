@@ -0,0 +1,64 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jsonledger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hyperledger_fabric")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rl, err := New(dir).GetOrCreate("foo")
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, rl.Append(ledger.CreateNextBlock(rl, []*cb.Envelope{{Payload: []byte{byte(i)}}})))
+	}
+
+	chainIDs, err := ChainIDs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, chainIDs)
+
+	result, err := Verify(dir, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, &ledger.VerifyResult{Height: 4}, result)
+
+	// Replace the data of block 2 without updating its header
+	chainDir := filepath.Join(dir, "chain_foo")
+	block, err := readBlockFile(filepath.Join(chainDir, "block_00000000000000000002.json"))
+	require.NoError(t, err)
+	block.Data.Data = [][]byte{[]byte("tampered")}
+	marshaled, err := (&jsonpb.Marshaler{}).MarshalToString(block)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(chainDir, "block_00000000000000000002.json"), []byte(marshaled), 0600))
+
+	result, err = Verify(dir, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), result.Height)
+	require.Len(t, result.Problems, 1)
+	assert.Equal(t, uint64(2), result.Problems[0].BlockNumber)
+
+	require.NoError(t, os.Remove(filepath.Join(chainDir, "block_00000000000000000001.json")))
+	result, err = Verify(dir, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), result.Height)
+	assert.Equal(t, []ledger.Problem{{BlockNumber: 1, Description: "block file is missing, found block file 2"}}, result.Problems)
+
+	_, err = Verify(dir, "bar")
+	assert.Error(t, err)
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package ledger
 
 import (
+	"fmt"
+
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 )
@@ -63,3 +65,21 @@ type ReadWriter interface {
 	Reader
 	Writer
 }
+
+// Problem is an inconsistency found verifying the ledger of a chain, in or
+// after block BlockNumber
+type Problem struct {
+	BlockNumber uint64
+	Description string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("block %d: %s", p.BlockNumber, p.Description)
+}
+
+// VerifyResult is the outcome of verifying the ledger of a chain offline
+type VerifyResult struct {
+	// Height is the number of consecutive, intact blocks from block 0
+	Height   uint64
+	Problems []Problem
+}
@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	fileledger "github.com/hyperledger/fabric/orderer/ledger/file"
+	jsonledger "github.com/hyperledger/fabric/orderer/ledger/json"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+const (
+	// stagingDir holds the file ledger while it is written
+	stagingDir = "migrating"
+	// BackupDir is where the chains of a JSON ledger are kept once it is
+	// migrated to a file ledger
+	BackupDir = "json-backup"
+)
+
+var jsonToFile = Migration{
+	From:        Format{Type: "json", Version: 1},
+	To:          Format{Type: "file", Version: 1},
+	Description: "copy the blocks of each chain into segmented block files, keeping the JSON chains in " + BackupDir,
+	Migrate:     migrateJSONToFile,
+}
+
+// migrateJSONToFile writes the blocks of the JSON ledger in directory to a
+// file ledger in a staging directory, and only once every block is written
+// moves the file ledger into place and the JSON chains aside, so that an
+// interrupted migration leaves the JSON ledger intact
+func migrateJSONToFile(directory string) error {
+	chainIDs, err := jsonledger.ChainIDs(directory)
+	if err != nil {
+		return err
+	}
+
+	staging := filepath.Join(directory, stagingDir)
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(staging, fsblkstorage.ChainsDir), 0700); err != nil {
+		return err
+	}
+
+	src := jsonledger.New(directory)
+	dst := fileledger.New(staging)
+	for _, chainID := range chainIDs {
+		if err := copyChain(src, dst, chainID); err != nil {
+			dst.Close()
+			return fmt.Errorf("could not copy chain %s: %s", chainID, err)
+		}
+	}
+	dst.Close()
+
+	for _, dir := range []string{fsblkstorage.ChainsDir, fsblkstorage.IndexDir} {
+		if err := os.Rename(filepath.Join(staging, dir), filepath.Join(directory, dir)); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+
+	backup := filepath.Join(directory, BackupDir)
+	if err := os.MkdirAll(backup, 0700); err != nil {
+		return err
+	}
+	infos, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if !info.IsDir() || !strings.HasPrefix(info.Name(), "chain_") {
+			continue
+		}
+		if err := os.Rename(filepath.Join(directory, info.Name()), filepath.Join(backup, info.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyChain appends every block of the chain in src to the chain in dst
+func copyChain(src, dst ledger.Factory, chainID string) error {
+	from, err := src.GetOrCreate(chainID)
+	if err != nil {
+		return err
+	}
+	to, err := dst.GetOrCreate(chainID)
+	if err != nil {
+		return err
+	}
+
+	height := from.Height()
+	it, _ := from.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{}})
+	for i := uint64(0); i < height; i++ {
+		block, status := it.Next()
+		if status != cb.Status_SUCCESS {
+			return fmt.Errorf("could not read block %d: %s", i, status)
+		}
+		if err := to.Append(block); err != nil {
+			return fmt.Errorf("could not write block %d: %s", i, err)
+		}
+	}
+	logger.Infof("Copied %d blocks of chain %s", height, chainID)
+	return nil
+}
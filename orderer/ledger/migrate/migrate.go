@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package migrate stamps ledger directories with the format of the ledger
+// they hold, and upgrades ledgers written in an older format, or by another
+// ledger type, to the format the orderer expects. Ledgers written before the
+// stamp was introduced are recognized by their layout.
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/ledger/migrate")
+
+// StampFile is the file of a ledger directory recording its format
+const StampFile = "FORMAT"
+
+// Format is the type and layout version of a ledger
+type Format struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+func (f Format) String() string {
+	return fmt.Sprintf("%s v%d", f.Type, f.Version)
+}
+
+// currentVersions are the layout versions written by this orderer
+var currentVersions = map[string]int{
+	"file": 1,
+	"json": 1,
+}
+
+// Current returns the format this orderer writes ledgers of the given type in
+func Current(ledgerType string) Format {
+	return Format{Type: ledgerType, Version: currentVersions[ledgerType]}
+}
+
+// Migration upgrades a ledger directory from one format to another
+type Migration struct {
+	From        Format
+	To          Format
+	Description string
+	Migrate     func(directory string) error
+}
+
+var migrations = []Migration{jsonToFile}
+
+// Stamp records the format of the ledger in directory
+func Stamp(directory string, format Format) error {
+	data, err := json.Marshal(format)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(directory, StampFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		return fmt.Errorf("could not stamp ledger %s: %s", directory, err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("could not stamp ledger %s: %s", directory, err)
+	}
+	return nil
+}
+
+// Detect returns the format of the ledger in directory, and whether it is
+// stamped. The format of an unstamped ledger is inferred from its layout, and
+// is the zero Format if directory holds no ledger.
+func Detect(directory string) (Format, bool, error) {
+	data, err := ioutil.ReadFile(filepath.Join(directory, StampFile))
+	if err == nil {
+		format := Format{}
+		if err := json.Unmarshal(data, &format); err != nil {
+			return Format{}, false, fmt.Errorf("malformed stamp in ledger %s: %s", directory, err)
+		}
+		return format, true, nil
+	}
+	if !os.IsNotExist(err) {
+		return Format{}, false, err
+	}
+
+	infos, err := ioutil.ReadDir(directory)
+	if os.IsNotExist(err) {
+		return Format{}, false, nil
+	}
+	if err != nil {
+		return Format{}, false, err
+	}
+	format := Format{}
+	for _, info := range infos {
+		switch {
+		case !info.IsDir():
+		case info.Name() == fsblkstorage.ChainsDir:
+			return Format{Type: "file", Version: 1}, false, nil
+		case strings.HasPrefix(info.Name(), "chain_"):
+			format = Format{Type: "json", Version: 1}
+		}
+	}
+	return format, false, nil
+}
+
+// Prepare readies the ledger in directory to be opened in the target format,
+// stamping it if it is new or unstamped, and upgrading it if it is in an
+// older format and upgrade is true
+func Prepare(directory string, target Format, upgrade bool) error {
+	format, stamped, err := Detect(directory)
+	if err != nil {
+		return err
+	}
+	switch {
+	case format == Format{} || format == target:
+		if stamped {
+			return nil
+		}
+		if err := os.MkdirAll(directory, 0700); err != nil {
+			return err
+		}
+		return Stamp(directory, target)
+	case format.Type == target.Type && format.Version > target.Version:
+		return fmt.Errorf("ledger %s is in format %s, which is newer than the %s this orderer supports", directory, format, target)
+	case !upgrade:
+		return fmt.Errorf("ledger %s is in format %s rather than %s, migrate it with ledgerutil migrate", directory, format, target)
+	}
+	return Upgrade(directory, target)
+}
+
+// Upgrade migrates the ledger in directory to the target format, stamping it
+// after each migration so that an interrupted upgrade resumes where it
+// stopped
+func Upgrade(directory string, target Format) error {
+	format, _, err := Detect(directory)
+	if err != nil {
+		return err
+	}
+	if format == (Format{}) {
+		return fmt.Errorf("no ledger found in %s", directory)
+	}
+	path, err := plan(format, target)
+	if err != nil {
+		return err
+	}
+	for _, m := range path {
+		logger.Infof("Migrating ledger %s from %s to %s: %s", directory, m.From, m.To, m.Description)
+		if err := m.Migrate(directory); err != nil {
+			return fmt.Errorf("migration of ledger %s from %s to %s failed: %s", directory, m.From, m.To, err)
+		}
+		if err := Stamp(directory, m.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plan returns the shortest sequence of migrations from one format to another
+func plan(from, to Format) ([]Migration, error) {
+	paths := map[Format][]Migration{from: nil}
+	frontier := []Format{from}
+	for len(frontier) > 0 {
+		format := frontier[0]
+		frontier = frontier[1:]
+		if format == to {
+			return paths[format], nil
+		}
+		for _, m := range migrations {
+			if _, seen := paths[m.To]; m.From != format || seen {
+				continue
+			}
+			paths[m.To] = append(append([]Migration(nil), paths[format]...), m)
+			frontier = append(frontier, m.To)
+		}
+	}
+	return nil, fmt.Errorf("no migration from %s to %s", from, to)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migrate
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	fileledger "github.com/hyperledger/fabric/orderer/ledger/file"
+	jsonledger "github.com/hyperledger/fabric/orderer/ledger/json"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "migrate")
+	require.NoError(t, err)
+	return dir
+}
+
+// writeJSONLedger writes a JSON ledger holding blocks blocks on each chain
+func writeJSONLedger(t *testing.T, dir string, blocks int, chainIDs ...string) {
+	jlf := jsonledger.New(dir)
+	for _, chainID := range chainIDs {
+		rw, err := jlf.GetOrCreate(chainID)
+		require.NoError(t, err)
+		require.NoError(t, rw.Append(cb.NewBlock(0, nil)))
+		for i := 1; i < blocks; i++ {
+			require.NoError(t, rw.Append(ledger.CreateNextBlock(rw, []*cb.Envelope{{Payload: []byte(chainID)}})))
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	format, stamped, err := Detect(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, Format{}, format)
+	assert.False(t, stamped)
+
+	format, _, err = Detect(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Format{}, format, "An empty directory holds no ledger")
+
+	writeJSONLedger(t, dir, 1, "foo")
+	format, stamped, err = Detect(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Format{Type: "json", Version: 1}, format)
+	assert.False(t, stamped)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "chains"), 0700))
+	format, _, err = Detect(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Format{Type: "file", Version: 1}, format)
+
+	require.NoError(t, Stamp(dir, Format{Type: "file", Version: 7}))
+	format, stamped, err = Detect(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Format{Type: "file", Version: 7}, format)
+	assert.True(t, stamped)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, StampFile), []byte("garbage"), 0600))
+	_, _, err = Detect(dir)
+	assert.Error(t, err)
+}
+
+func TestPrepare(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	fresh := filepath.Join(dir, "fresh")
+	require.NoError(t, Prepare(fresh, Current("file"), false))
+	format, stamped, err := Detect(fresh)
+	require.NoError(t, err)
+	assert.Equal(t, Current("file"), format, "A new ledger should be stamped in the target format")
+	assert.True(t, stamped)
+
+	newer := filepath.Join(dir, "newer")
+	require.NoError(t, os.Mkdir(newer, 0700))
+	require.NoError(t, Stamp(newer, Format{Type: "file", Version: 99}))
+	assert.Error(t, Prepare(newer, Current("file"), true), "A ledger written by a newer orderer should not be opened")
+
+	old := filepath.Join(dir, "old")
+	writeJSONLedger(t, old, 1, "foo")
+	require.NoError(t, Prepare(old, Current("json"), false))
+	_, stamped, err = Detect(old)
+	require.NoError(t, err)
+	assert.True(t, stamped, "A ledger in the target layout should be stamped")
+	assert.Error(t, Prepare(old, Current("file"), false), "A ledger of another format should not be migrated unless asked")
+	assert.NoError(t, Prepare(old, Current("file"), true))
+}
+
+func TestMigrateJSONToFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeJSONLedger(t, dir, 3, "foo", "bar")
+
+	require.NoError(t, Upgrade(dir, Current("file")))
+	format, stamped, err := Detect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Current("file"), format)
+	assert.True(t, stamped)
+
+	flf := fileledger.New(dir)
+	defer flf.Close()
+	chainIDs := flf.ChainIDs()
+	sort.Strings(chainIDs)
+	assert.Equal(t, []string{"bar", "foo"}, chainIDs)
+	jlf := jsonledger.New(filepath.Join(dir, BackupDir))
+	for _, chainID := range []string{"foo", "bar"} {
+		migrated, err := flf.GetOrCreate(chainID)
+		require.NoError(t, err)
+		original, err := jlf.GetOrCreate(chainID)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), migrated.Height())
+		for i := uint64(0); i < 3; i++ {
+			assert.True(t, proto.Equal(ledger.GetBlock(original, i), ledger.GetBlock(migrated, i)), "Block %d should be copied as is", i)
+		}
+	}
+
+	_, err = os.Stat(filepath.Join(dir, stagingDir))
+	assert.True(t, os.IsNotExist(err), "The staging directory should be removed")
+	chainIDs, err = jsonledger.ChainIDs(dir)
+	require.NoError(t, err)
+	assert.Empty(t, chainIDs, "The JSON chains should be moved aside")
+}
+
+func TestUpgradePlan(t *testing.T) {
+	defer func(saved []Migration) { migrations = saved }(migrations)
+
+	var ran []string
+	step := func(from, to Format, err error) Migration {
+		return Migration{From: from, To: to, Migrate: func(string) error {
+			ran = append(ran, to.String())
+			return err
+		}}
+	}
+	v1, v2, v3 := Format{"file", 1}, Format{"file", 2}, Format{"file", 3}
+	migrations = []Migration{step(v2, v3, nil), step(v1, v2, nil)}
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	require.NoError(t, Stamp(dir, v1))
+	require.NoError(t, Upgrade(dir, v3))
+	assert.Equal(t, []string{"file v2", "file v3"}, ran, "The migrations should run in order")
+
+	_, err := plan(v3, v1)
+	assert.Error(t, err, "There should be no path back to an older format")
+
+	migrations = []Migration{step(v1, v2, nil), step(v2, v3, errors.New("failed"))}
+	require.NoError(t, Stamp(dir, v1))
+	assert.Error(t, Upgrade(dir, v3))
+	format, _, err := Detect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, v2, format, "The ledger should be stamped with the last format migrated to")
+
+	assert.Error(t, Upgrade(filepath.Join(dir, "missing"), v3), "There should be no ledger to upgrade")
+}
@@ -19,6 +19,7 @@ package ramledger
 import (
 	"bytes"
 	"fmt"
+	"sync"
 
 	"github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -39,6 +40,7 @@ type simpleList struct {
 }
 
 type ramLedger struct {
+	lock    sync.RWMutex
 	maxSize int
 	size    int
 	oldest  *simpleList
@@ -48,14 +50,11 @@ type ramLedger struct {
 // Next blocks until there is a new block available, or returns an error if the
 // next block is no longer retrievable
 func (cu *cursor) Next() (*cb.Block, cb.Status) {
-	// This only loops once, as signal reading indicates non-nil next
-	for {
-		if cu.list.next != nil {
-			cu.list = cu.list.next
-			return cu.list.block, cb.Status_SUCCESS
-		}
-		<-cu.list.signal
-	}
+	// The signal is closed once next is set, so that next may be read
+	// without holding the lock of the ledger
+	<-cu.list.signal
+	cu.list = cu.list.next
+	return cu.list.block, cb.Status_SUCCESS
 }
 
 // ReadyChan supplies a channel which will block until Next will not block
@@ -66,6 +65,9 @@ func (cu *cursor) ReadyChan() <-chan struct{} {
 // Iterator returns an Iterator, as specified by a cb.SeekInfo message, and its
 // starting block number
 func (rl *ramLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator, uint64) {
+	rl.lock.RLock()
+	defer rl.lock.RUnlock()
+
 	var list *simpleList
 	switch start := startPosition.Type.(type) {
 	case *ab.SeekPosition_Oldest:
@@ -113,6 +115,42 @@ func (rl *ramLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 			}
 			list = list.next // No need for nil check, because of range check above
 		}
+	case *ab.SeekPosition_TxId:
+		// The ledger holds few enough blocks that they are searched rather
+		// than indexed, and the most recent block containing the
+		// transaction is found
+		var found *simpleList
+		for item := rl.oldest; item != nil; item = item.next {
+			if ledger.ContainsTxID(item.block, start.TxId.GetTxId()) {
+				found = item
+			}
+		}
+		if found == nil {
+			logger.Debugf("Returning error iterator because transaction %s was not found", start.TxId.GetTxId())
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		list = before(found)
+	case *ab.SeekPosition_Hash:
+		var found *simpleList
+		if len(start.Hash.GetHash()) > 0 {
+			for item := rl.oldest; item != nil; item = item.next {
+				// The header of the pre-genesis block cannot be hashed
+				if item.block.Header.Number == ^uint64(0) {
+					continue
+				}
+				if bytes.Equal(item.block.Header.Hash(), start.Hash.GetHash()) {
+					found = item
+					break
+				}
+			}
+		}
+		if found == nil {
+			logger.Debugf("Returning error iterator because block with hash %x was not found", start.Hash.GetHash())
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		list = before(found)
+	default:
+		return &ledger.NotFoundErrorIterator{}, 0
 	}
 	cursor := &cursor{list: list}
 	blockNum := list.block.Header.Number + 1
@@ -126,13 +164,29 @@ func (rl *ramLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 	return cursor, blockNum
 }
 
+// before returns an item preceding the item, from which a cursor reads it next
+func before(item *simpleList) *simpleList {
+	list := &simpleList{
+		block:  &cb.Block{Header: &cb.BlockHeader{Number: item.block.Header.Number - 1}},
+		next:   item,
+		signal: make(chan struct{}),
+	}
+	close(list.signal)
+	return list
+}
+
 // Height returns the number of blocks on the ledger
 func (rl *ramLedger) Height() uint64 {
+	rl.lock.RLock()
+	defer rl.lock.RUnlock()
 	return rl.newest.block.Header.Number + 1
 }
 
 // Append appends a new block to the ledger
 func (rl *ramLedger) Append(block *cb.Block) error {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
 	if block.Header.Number != rl.newest.block.Header.Number+1 {
 		return fmt.Errorf("Block number should have been %d but was %d",
 			rl.newest.block.Header.Number+1, block.Header.Number)
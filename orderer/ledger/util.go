@@ -20,6 +20,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 var closedChan chan struct{}
@@ -103,3 +104,40 @@ func GetBlock(rl Reader, index uint64) *cb.Block {
 		return nil
 	}
 }
+
+// TxIDs returns the IDs of the transactions of the block, in order, with an
+// empty ID for each transaction which cannot be decoded
+func TxIDs(block *cb.Block) []string {
+	data := block.GetData().GetData()
+	txIDs := make([]string, len(data))
+	for i, envBytes := range data {
+		env, err := utils.UnmarshalEnvelope(envBytes)
+		if err != nil {
+			continue
+		}
+		payload, err := utils.UnmarshalPayload(env.Payload)
+		if err != nil || payload.Header == nil {
+			continue
+		}
+		chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		if err != nil {
+			continue
+		}
+		txIDs[i] = chdr.TxId
+	}
+	return txIDs
+}
+
+// ContainsTxID returns whether the block contains a transaction with the ID,
+// which must not be empty
+func ContainsTxID(block *cb.Block, txID string) bool {
+	if txID == "" {
+		return false
+	}
+	for _, id := range TxIDs(block) {
+		if id == txID {
+			return true
+		}
+	}
+	return false
+}
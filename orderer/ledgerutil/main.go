@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// ledgerutil works on the ledgers of a stopped orderer
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/orderer/ledger"
+	fileledger "github.com/hyperledger/fabric/orderer/ledger/file"
+	jsonledger "github.com/hyperledger/fabric/orderer/ledger/json"
+	"github.com/hyperledger/fabric/orderer/ledger/migrate"
+	"github.com/hyperledger/fabric/orderer/localconfig"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	app = kingpin.New("ledgerutil", "Hyperledger Fabric orderer ledger utility")
+
+	verifyCmd      = app.Command("verify", "Verify the hash chain and indexes of the ledgers of a stopped orderer, reporting corruption by block number.")
+	verifyType     = verifyCmd.Flag("type", "The ledger type, General.LedgerType of the orderer config if unset.").Enum("file", "json")
+	verifyLocation = verifyCmd.Flag("location", "The ledger directory, FileLedger.Location of the orderer config if unset.").String()
+	verifyChannels = verifyCmd.Flag("channel", "A channel to verify, every channel of the ledger if unset.").Short('c').Strings()
+
+	migrateCmd      = app.Command("migrate", "Upgrade the ledger of a stopped orderer from an older format, or from another ledger type, to the format the orderer writes.")
+	migrateType     = migrateCmd.Flag("type", "The ledger type to migrate to, General.LedgerType of the orderer config if unset.").Enum("file", "json")
+	migrateLocation = migrateCmd.Flag("location", "The ledger directory, FileLedger.Location of the orderer config if unset.").String()
+)
+
+type backend struct {
+	chainIDs func(directory string) ([]string, error)
+	verify   func(directory, chainID string) (*ledger.VerifyResult, error)
+}
+
+var backends = map[string]backend{
+	"file": {chainIDs: fileledger.ChainIDs, verify: fileledger.Verify},
+	"json": {chainIDs: jsonledger.ChainIDs, verify: jsonledger.Verify},
+}
+
+func main() {
+	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	case verifyCmd.FullCommand():
+		corrupt, err := verify()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if corrupt {
+			os.Exit(2)
+		}
+	case migrateCmd.FullCommand():
+		if err := upgrade(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// locate returns the ledger type and directory set by flags, defaulting to
+// those of the orderer config
+func locate(ledgerType, location string) (string, string) {
+	if ledgerType == "" || location == "" {
+		conf := config.Load()
+		if ledgerType == "" {
+			ledgerType = conf.General.LedgerType
+		}
+		if location == "" {
+			location = conf.FileLedger.Location
+		}
+	}
+	return ledgerType, location
+}
+
+// upgrade migrates the ledger to the current format of its type
+func upgrade() error {
+	ledgerType, location := locate(*migrateType, *migrateLocation)
+	if _, ok := backends[ledgerType]; !ok {
+		return fmt.Errorf("ledger type %s can not be migrated to", ledgerType)
+	}
+	target := migrate.Current(ledgerType)
+	format, _, err := migrate.Detect(location)
+	if err != nil {
+		return err
+	}
+	if format == target {
+		fmt.Printf("%s: already %s\n", location, target)
+		return migrate.Prepare(location, target, false)
+	}
+	if err := migrate.Upgrade(location, target); err != nil {
+		return err
+	}
+	fmt.Printf("%s: migrated from %s to %s\n", location, format, target)
+	return nil
+}
+
+// verify reports the problems of each channel, returning whether any was
+// found
+func verify() (bool, error) {
+	ledgerType, location := locate(*verifyType, *verifyLocation)
+	b, ok := backends[ledgerType]
+	if !ok {
+		return false, fmt.Errorf("ledger type %s can not be verified", ledgerType)
+	}
+
+	chainIDs := *verifyChannels
+	if len(chainIDs) == 0 {
+		var err error
+		if chainIDs, err = b.chainIDs(location); err != nil {
+			return false, fmt.Errorf("could not list channels in %s: %s", location, err)
+		}
+	}
+
+	corrupt := false
+	for _, chainID := range chainIDs {
+		result, err := b.verify(location, chainID)
+		if err != nil {
+			return corrupt, fmt.Errorf("could not verify channel %s: %s", chainID, err)
+		}
+		if len(result.Problems) == 0 {
+			fmt.Printf("%s: OK, %d blocks\n", chainID, result.Height)
+			continue
+		}
+		corrupt = true
+		fmt.Printf("%s: CORRUPT, %d intact blocks\n", chainID, result.Height)
+		for _, problem := range result.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+	return corrupt, nil
+}
@@ -68,14 +68,46 @@ type TopLevel struct {
 
 // General contains config which should be common among all orderer types.
 type General struct {
+	Role           string
 	LedgerType     string
 	ListenAddress  string
 	ListenPort     uint16
+	UnixSocket     UnixSocket
 	TLS            TLS
+	Services       []string
+	Listeners      map[string]Listener
+	Keepalive      Keepalive
+	Limits         Limits
+	Backpressure   Backpressure
+	Recording      Recording
+	Chaos          map[string]Chaos
+	Scheduler      Scheduler
+	Priority       Priority
+	Extensions     Extensions
+	TxStatus       TxStatus
+	Receipts       Receipts
+	Tracing        Tracing
+	Timestamps     Timestamps
+	JoinTokens     JoinTokens
+	Throttle       Throttle
+	TokenAuth      TokenAuth
+	GRPC           GRPC
+	DrainTimeout   time.Duration
+	Broadcast      Broadcast
+	Deliver        Deliver
+	Gateway        Gateway
 	GenesisMethod  string
 	GenesisProfile string
 	GenesisFile    string
+	GenesisDeliver GenesisDeliver
+	Follower       Follower
+	Cosign         Cosign
 	Profile        Profile
+	Admin          Admin
+	Operations     Operations
+	StatsD         StatsD
+	Audit          Audit
+	Events         Events
 	LogLevel       string
 	LogFormat      string
 	LocalMSPDir    string
@@ -83,16 +115,431 @@ type General struct {
 	BCCSP          *bccsp.FactoryOpts
 }
 
-// TLS contains config for TLS connections.
+// TLS contains config for TLS connections. If BCCSPKey is set, the private
+// key corresponding to Certificate is held by the BCCSP, such as a PKCS#11
+// token, and PrivateKey is not used.
 type TLS struct {
 	Enabled           bool
 	PrivateKey        string
+	BCCSPKey          bool
 	Certificate       string
 	RootCAs           []string
 	ClientAuthEnabled bool
 	ClientRootCAs     []string
 }
 
+// GenesisDeliver contains configuration for fetching the genesis block of the
+// system channel ChainID from the orderer at Address when GenesisMethod is
+// "deliver". If Hash is set, the block must have the hex encoded header hash.
+// Attempts are retried every RetryInterval until Timeout. The TLS RootCAs
+// verify the orderer, and the Certificate and PrivateKey, if set, are
+// presented as a client certificate.
+type GenesisDeliver struct {
+	Address            string
+	ChainID            string
+	Hash               string
+	Timeout            time.Duration
+	RetryInterval      time.Duration
+	ServerNameOverride string
+	TLS                TLS
+}
+
+// Follower contains configuration for following the chains of the ordering
+// service at Endpoints when Role is "archive" or "replica": the system channel ChainID is
+// followed, along with each channel it creates. The blocks of a chain are
+// requested again every RetryInterval after the orderers fail, accepting
+// them compressed with the Compression algorithms, gzip or snappy, by
+// preference. The TLS RootCAs verify the orderers, and the Certificate and
+// PrivateKey, if set, are presented as a client certificate.
+type Follower struct {
+	Endpoints          []string
+	ChainID            string
+	RetryInterval      time.Duration
+	Compression        []string
+	ServerNameOverride string
+	TLS                TLS
+}
+
+// Cosign contains configuration for the collection of the signatures of the
+// other orderers of a channel on its blocks, when the BlockValidation policy
+// of the channel requires more than the signature of one orderer. If Enabled,
+// the orderer asks the orderers at the addresses of the channel for their
+// signatures on each block it writes, for up to Timeout, and signs the blocks
+// they ask it to once it has produced the same block. The TLS RootCAs verify
+// the other orderers, and the Certificate and PrivateKey are presented as a
+// client certificate, by which the other orderers authorize the orderer as
+// one of the channel. The orderer does not ask itself, at its
+// listen address or at any of the Addresses it is listed at by channels.
+type Cosign struct {
+	Enabled            bool
+	Timeout            time.Duration
+	Addresses          []string
+	ServerNameOverride string
+	TLS                TLS
+}
+
+// UnixSocket contains configuration for an additional listener on a Unix
+// domain socket, served without TLS. Mode is the octal permission mode of the
+// socket file. If Exclusive is set, the orderer does not listen on TCP.
+type UnixSocket struct {
+	Path      string
+	Mode      string
+	Exclusive bool
+}
+
+// Listener contains configuration for an additional TCP listener, such as a
+// cluster facing or IPv6 endpoint, with its own TLS settings, including
+// whether clients must authenticate with a TLS certificate. TokenAuth, if
+// set, overrides General.TokenAuth on the listener. Services names the
+// services offered on the listener, any of Broadcast, Deliver and Admin; all
+// of them are offered if it is empty.
+type Listener struct {
+	ListenAddress string
+	ListenPort    uint16
+	TLS           TLS
+	TokenAuth     *ListenerTokenAuth
+	Services      []string
+}
+
+// ListenerTokenAuth contains the bearer token authentication settings of a
+// listener. If Enabled, the tokens signed by the issuers of General.TokenAuth,
+// which must then be enabled, are accepted on the listener, and if Required,
+// the clients which present neither a token nor a TLS client certificate are
+// rejected.
+type ListenerTokenAuth struct {
+	Enabled  bool
+	Required bool
+}
+
+// Keepalive contains configuration for the keepalive behavior of the gRPC
+// server. Connections on which no RPC is active for ServerMaxConnectionIdle
+// are closed, and clients are asked to move to a new connection after
+// ServerMaxConnectionAge, after which the RPCs still active are given
+// ServerMaxConnectionAgeGrace to complete. A value of 0 for any of the three
+// means no limit.
+type Keepalive struct {
+	ServerMinInterval           time.Duration
+	ServerInterval              time.Duration
+	ServerTimeout               time.Duration
+	ServerMaxConnectionIdle     time.Duration
+	ServerMaxConnectionAge      time.Duration
+	ServerMaxConnectionAgeGrace time.Duration
+}
+
+// Limits contains configuration for the message size, stream and connection
+// limits of the gRPC server. StreamMessageRate and StreamByteRate are the
+// ceilings, per second, on the messages and bytes a client may send on a
+// single stream. MaxPendingBytes caps the bytes of the messages held pending
+// ordering across every chain. A value of 0 for MaxConcurrentStreams,
+// MaxConnections, either rate or MaxPendingBytes means no limit.
+type Limits struct {
+	MaxRecvMsgSize       uint32
+	MaxSendMsgSize       uint32
+	MaxConcurrentStreams uint32
+	MaxConnections       uint32
+	StreamMessageRate    float64
+	StreamByteRate       float64
+	MaxPendingBytes      int64
+}
+
+// Backpressure contains the thresholds at which the chains push back on a
+// slow ledger. Beyond SlowAppendLatency the batch timeout is lengthened, and
+// beyond MaxAppendLatency, or with more than MaxQueueDepth messages waiting
+// on the consenter, messages are rejected. Config and other urgent messages
+// wait in a priority lane instead, which holds at most MaxUrgentQueueDepth.
+// A value of 0 disables a threshold.
+type Backpressure struct {
+	SlowAppendLatency   time.Duration
+	MaxAppendLatency    time.Duration
+	MaxQueueDepth       int
+	MaxUrgentQueueDepth int
+}
+
+// Scheduler contains configuration for sharing the writing of blocks among
+// the channels. At most Slots blocks are written at once, and while more are
+// waiting, each channel is granted slots in proportion to its weight in
+// Weights, or DefaultWeight if it is not listed. A Slots of 0 disables the
+// scheduler.
+type Scheduler struct {
+	Slots         int
+	DefaultWeight int
+	Weights       map[string]int
+}
+
+// Priority contains the entitlements of the creators of messages to the
+// priority classes they request in the orderer extension of their channel
+// header: LOW, NORMAL, HIGH or CONTROL. The creators of the MSPs listed in
+// Entitlements, by MSP ID, may request up to the listed class, and others up
+// to DefaultEntitlement. Messages requesting more are rejected.
+type Priority struct {
+	DefaultEntitlement string
+	Entitlements       map[string]string
+}
+
+// Extensions contains the limits on the extensions clients attach to their
+// messages in the orderer extension of their channel header, which are
+// recorded in the EXTENSIONS metadata of the blocks. Messages carrying more
+// than MaxBytes of extensions are rejected, all of them if MaxBytes is 0.
+type Extensions struct {
+	MaxBytes uint32
+}
+
+// TxStatus contains configuration for the index of the transactions of each
+// channel queried by the TransactionStatus service. The IndexSize most
+// recently committed transactions are indexed, and transactions enqueued by
+// this orderer are reported as pending for up to PendingExpiry. An IndexSize
+// of 0 disables the index of committed transactions, and a PendingExpiry of
+// 0 the tracking of pending ones.
+type TxStatus struct {
+	IndexSize     int
+	PendingExpiry time.Duration
+}
+
+// Receipts contains configuration for the receipts of accepted broadcasts.
+// If Enabled, the signed responses to the Size most recently accepted
+// messages are kept, persisted to File if set, and returned by the
+// TransactionStatus service. A message submitted again within Window of its
+// acceptance is answered with its receipt instead of being ordered again.
+type Receipts struct {
+	Enabled bool
+	File    string
+	Size    int
+	Window  time.Duration
+}
+
+// Tracing contains configuration for the tracing of transactions from their
+// receipt by Broadcast to the append of their block. If Enabled, the
+// transactions sent with the context of a sampled span of their client, and
+// a SampleRate fraction of the others, are traced, and their spans appended
+// to File as lines of JSON, or logged if File is not set.
+type Tracing struct {
+	Enabled    bool
+	SampleRate float64
+	File       string
+}
+
+// Timestamps contains configuration for the hybrid logical clock timestamps
+// with which each block is stamped when it is ordered. Its transactions are
+// also stamped, in order, if PerTransaction is set.
+type Timestamps struct {
+	PerTransaction bool
+}
+
+// Recording contains configuration for recording the messages enqueued on
+// the Channels, to a file per channel in Directory, so that they may be
+// replayed in tests.
+type Recording struct {
+	Directory string
+	Channels  []string
+}
+
+// Chaos contains the failures injected into a channel for testing: the
+// append of block AppendErrorAt fails if it is non-zero, each message is
+// delayed by ConsenterStall before it is handed to the consenter, and each
+// message is held in the filters for FilterTimeout, if positive, and then
+// rejected.
+type Chaos struct {
+	AppendErrorAt  uint64
+	ConsenterStall time.Duration
+	FilterTimeout  time.Duration
+}
+
+// JoinTokens contains configuration for the ChannelJoin service, with which
+// the admins of a channel mint tokens granting its config block to new
+// orderers and consumers. If Enabled, the service is offered wherever Deliver
+// is, and the tokens minted expire after MaxValidity at most.
+type JoinTokens struct {
+	Enabled     bool
+	MaxValidity time.Duration
+}
+
+// Throttle contains configuration for the per client rate limits enforced
+// by the gRPC server. Rates are per second, a rate of 0 means no limit.
+type Throttle struct {
+	Burst       uint32
+	PerIdentity ThrottleRates
+	PerIP       ThrottleRates
+}
+
+// ThrottleRates contains the rates at which a single client may open streams
+// and issue unary RPCs.
+type ThrottleRates struct {
+	StreamRate float64
+	RPCRate    float64
+}
+
+// TokenAuth contains configuration for authenticating clients by bearer
+// tokens signed by a trusted issuer, as an alternative to TLS client
+// certificates. The services authorize the clients as the principal their
+// token vouches for.
+type TokenAuth struct {
+	Enabled            bool
+	Required           bool
+	IssuerCertificates []string
+}
+
+// GRPC contains configuration for the introspection of the gRPC services.
+// If Reflection is set, the server reflection service is offered on every
+// listener. If StreamErrors is set, Broadcast and Deliver streams end with an
+// error whose google.rpc.Status details describe the failure of a request
+// once its failure status is sent, those of unavailable services telling
+// clients to retry after RetryDelay.
+type GRPC struct {
+	Reflection   bool
+	StreamErrors bool
+	RetryDelay   time.Duration
+}
+
+// Admin contains configuration for the admin service, which is only served
+// when TLS is enabled.
+type Admin struct {
+	Enabled       bool
+	ClientRootCAs []string
+}
+
+// Operations contains configuration for the operations listener, which serves
+// pprof profiles and expvar counters separately from client traffic.
+type Operations struct {
+	Enabled       bool
+	ListenAddress string
+	TLS           TLS
+	Health        Health
+}
+
+// Health contains configuration for the /healthz and /readyz probes of the
+// operations listener. A check is only reported failing once it has failed
+// FailureThreshold times in a row, or the number of times given for its name
+// in FailureThresholds.
+type Health struct {
+	FailureThreshold  int
+	FailureThresholds map[string]int
+}
+
+// StatsD contains configuration for pushing metrics to a StatsD collector at
+// Address every FlushInterval. Prefix, if set, is prepended to every metric
+// name, and labels are sent as tags if DogStatsD is set.
+type StatsD struct {
+	Enabled       bool
+	Address       string
+	Prefix        string
+	FlushInterval time.Duration
+	DogStatsD     bool
+}
+
+// Broadcast contains configuration for Broadcast streams. If SignResponses is
+// set, each response carries an acknowledgment of the message signed by the
+// orderer's local MSP identity. Messages are verified in parallel by
+// VerifyWorkers workers, or by GOMAXPROCS workers if it is not positive.
+// Clients which ask to await the commit of a message are answered once it is
+// committed, or after CommitTimeout. The batches of BroadcastBatch hold at
+// most MaxBatchMessages messages, 1000 if it is not positive.
+type Broadcast struct {
+	SignResponses    bool
+	VerifyWorkers    int
+	RateLimit        BroadcastRateLimit
+	CommitTimeout    time.Duration
+	MaxBatchMessages int
+}
+
+// BroadcastRateLimit contains the rates, in messages per second, at which a
+// single connection, and a single identity across its connections, may
+// broadcast, with bursts of up to Burst messages. A rate of 0 means no limit.
+type BroadcastRateLimit struct {
+	PerConnection float64
+	PerIdentity   float64
+	Burst         uint32
+}
+
+// Deliver contains configuration for Deliver streams. The authorization of an
+// idle stream is re-evaluated every RevalidationInterval, which disables the
+// periodic check if negative. Clients which accept batches of blocks are sent
+// those already available in responses of up to about MaxBatchBytes, which
+// disables batching if negative. The CacheBlocks most recently committed blocks
+// of each chain are kept in memory for the streams following the tip, which
+// disables the cache if negative. Seek requests which are not bound to the
+// TLS client certificate of their stream are refused if RequireTLSBinding is
+// set. At most MaxStreams streams are served at once, MaxStreamsPerClient of
+// them to a single client, and the blocks of each are sent at up to
+// MaxBytesPerSecond; 0 means no limit. Streams which ask for heartbeats are
+// sent one whenever they have waited for blocks for HeartbeatInterval, which
+// disables heartbeats if negative. Clients which accept one of the
+// Compression algorithms, gzip or snappy, are sent their blocks compressed;
+// none are by default.
+type Deliver struct {
+	RevalidationInterval time.Duration
+	MaxBatchBytes        int
+	CacheBlocks          int
+	RequireTLSBinding    bool
+	MaxStreams           int
+	MaxStreamsPerClient  int
+	MaxBytesPerSecond    int
+	HeartbeatInterval    time.Duration
+	Compression          []string
+}
+
+// Gateway contains configuration for the HTTP gateway to the Deliver service,
+// which serves the blocks of the chains as JSON on ListenAddress. Its clients
+// are authorized by their TLS client certificates, so it requires TLS with
+// ClientAuthEnabled.
+type Gateway struct {
+	Enabled       bool
+	ListenAddress string
+	TLS           TLS
+}
+
+// Audit contains configuration for the security audit trail. Entries are
+// appended to File, if set, which is synced every SyncInterval, and written
+// to standard output by a dedicated audit logger if Logger is set.
+type Audit struct {
+	Enabled      bool
+	File         string
+	SyncInterval time.Duration
+	Logger       bool
+}
+
+// Events contains configuration for publishing each committed block to
+// external systems. A message in the Payload format, one of block, filtered
+// or header, is published to each configured sink. The blocks published to
+// each sink are recorded in ProgressFile, if set, so that publication resumes
+// after a restart; blocks of channels without recorded progress are published
+// from the newest block if FromNewest is set and from the oldest otherwise.
+// Failed publications, and failed reads of the ledgers, are retried after
+// RetryInitial, doubling up to RetryMax. A message is dropped after
+// MaxRetries failed retries, unless MaxRetries is negative.
+type Events struct {
+	Enabled      bool
+	Payload      string
+	ProgressFile string
+	FromNewest   bool
+	RetryInitial time.Duration
+	RetryMax     time.Duration
+	MaxRetries   int
+	Webhook      EventsWebhook
+	Kafka        EventsKafka
+	NATS         EventsNATS
+}
+
+// EventsWebhook contains configuration for posting events to URL.
+type EventsWebhook struct {
+	URL     string
+	Timeout time.Duration
+}
+
+// EventsKafka contains configuration for producing events to Topic on the
+// Kafka cluster of Brokers.
+type EventsKafka struct {
+	Brokers []string
+	Topic   string
+}
+
+// EventsNATS contains configuration for publishing events on the NATS server
+// at Address, to Subject followed by the channel ID.
+type EventsNATS struct {
+	Address string
+	Subject string
+	Timeout time.Duration
+}
+
 // Profile contains configuration for Go pprof profiling.
 type Profile struct {
 	Enabled bool
@@ -103,6 +550,9 @@ type Profile struct {
 type FileLedger struct {
 	Location string
 	Prefix   string
+	// AutoMigrate upgrades a ledger found in an older format, or written
+	// by another ledger type, on startup rather than refusing to start
+	AutoMigrate bool
 }
 
 // RAMLedger contains configuration for the RAM ledger.
@@ -116,6 +566,16 @@ type Kafka struct {
 	Verbose bool
 	Version sarama.KafkaVersion // TODO Move this to global config
 	TLS     TLS
+	Cluster Cluster
+}
+
+// Cluster contains configuration for negotiating the wire version used by the
+// orderers of the consenter set, which advertise the newest version they
+// support as NodeID. A version is used once each of the Members, which must
+// be the same on every orderer, has advertised it.
+type Cluster struct {
+	NodeID  string
+	Members []string
 }
 
 // Retry contains configuration related to retries and timeouts when the
@@ -163,16 +623,89 @@ type Consumer struct {
 
 var defaults = TopLevel{
 	General: General{
-		LedgerType:     "file",
-		ListenAddress:  "127.0.0.1",
-		ListenPort:     7050,
+		Role:          "orderer",
+		LedgerType:    "file",
+		ListenAddress: "127.0.0.1",
+		ListenPort:    7050,
+		UnixSocket: UnixSocket{
+			Mode: "0660",
+		},
 		GenesisMethod:  "provisional",
 		GenesisProfile: "SampleSingleMSPSolo",
 		GenesisFile:    "genesisblock",
+		GenesisDeliver: GenesisDeliver{
+			ChainID:       "testchainid",
+			Timeout:       5 * time.Minute,
+			RetryInterval: 5 * time.Second,
+		},
+		Follower: Follower{
+			ChainID:       "testchainid",
+			RetryInterval: 5 * time.Second,
+		},
+		Cosign: Cosign{
+			Timeout: 5 * time.Second,
+		},
+		Extensions: Extensions{
+			MaxBytes: 1024,
+		},
+		Keepalive: Keepalive{
+			ServerMinInterval: 60 * time.Second,
+			ServerInterval:    7200 * time.Second,
+			ServerTimeout:     20 * time.Second,
+		},
+		Limits: Limits{
+			MaxRecvMsgSize: 100 * 1024 * 1024,
+			MaxSendMsgSize: 100 * 1024 * 1024,
+		},
+		GRPC: GRPC{
+			RetryDelay: time.Second,
+		},
+		DrainTimeout: 10 * time.Second,
+		Broadcast: Broadcast{
+			CommitTimeout: 30 * time.Second,
+		},
+		Deliver: Deliver{
+			RevalidationInterval: time.Minute,
+			MaxBatchBytes:        1024 * 1024,
+			CacheBlocks:          64,
+			HeartbeatInterval:    30 * time.Second,
+		},
+		Gateway: Gateway{
+			Enabled:       false,
+			ListenAddress: "127.0.0.1:7080",
+		},
 		Profile: Profile{
 			Enabled: false,
 			Address: "0.0.0.0:6060",
 		},
+		Operations: Operations{
+			Enabled:       false,
+			ListenAddress: "127.0.0.1:8443",
+			Health: Health{
+				FailureThreshold: 1,
+			},
+		},
+		StatsD: StatsD{
+			Enabled:       false,
+			Address:       "127.0.0.1:8125",
+			FlushInterval: 10 * time.Second,
+		},
+		Audit: Audit{
+			SyncInterval: time.Second,
+		},
+		Events: Events{
+			Payload:      "header",
+			RetryInitial: time.Second,
+			RetryMax:     time.Minute,
+			MaxRetries:   10,
+			Webhook: EventsWebhook{
+				Timeout: 10 * time.Second,
+			},
+			NATS: EventsNATS{
+				Subject: "fabric.blocks",
+				Timeout: 10 * time.Second,
+			},
+		},
 		LogLevel:    "INFO",
 		LogFormat:   "%{color}%{time:2006-01-02 15:04:05.000 MST} [%{module}] %{shortfunc} -> %{level:.4s} %{id:03x}%{color:reset} %{message}",
 		LocalMSPDir: "msp",
@@ -249,17 +782,75 @@ func (c *TopLevel) completeInitialization(configDir string) {
 		// Translate any paths
 		c.General.TLS.RootCAs = translateCAs(configDir, c.General.TLS.RootCAs)
 		c.General.TLS.ClientRootCAs = translateCAs(configDir, c.General.TLS.ClientRootCAs)
+		c.General.Admin.ClientRootCAs = translateCAs(configDir, c.General.Admin.ClientRootCAs)
+		c.General.TokenAuth.IssuerCertificates = translateCAs(configDir, c.General.TokenAuth.IssuerCertificates)
+		c.General.Operations.TLS.ClientRootCAs = translateCAs(configDir, c.General.Operations.TLS.ClientRootCAs)
+		cf.TranslatePathInPlace(configDir, &c.General.Operations.TLS.PrivateKey)
+		cf.TranslatePathInPlace(configDir, &c.General.Operations.TLS.Certificate)
 		cf.TranslatePathInPlace(configDir, &c.General.TLS.PrivateKey)
 		cf.TranslatePathInPlace(configDir, &c.General.TLS.Certificate)
+		if c.General.UnixSocket.Path != "" {
+			cf.TranslatePathInPlace(configDir, &c.General.UnixSocket.Path)
+		}
+		if c.General.Audit.File != "" {
+			cf.TranslatePathInPlace(configDir, &c.General.Audit.File)
+		}
+		if c.General.Events.ProgressFile != "" {
+			cf.TranslatePathInPlace(configDir, &c.General.Events.ProgressFile)
+		}
+		for name, listener := range c.General.Listeners {
+			listener.TLS.RootCAs = translateCAs(configDir, listener.TLS.RootCAs)
+			listener.TLS.ClientRootCAs = translateCAs(configDir, listener.TLS.ClientRootCAs)
+			cf.TranslatePathInPlace(configDir, &listener.TLS.PrivateKey)
+			cf.TranslatePathInPlace(configDir, &listener.TLS.Certificate)
+			c.General.Listeners[name] = listener
+		}
 		cf.TranslatePathInPlace(configDir, &c.General.GenesisFile)
+		c.General.GenesisDeliver.TLS.RootCAs = translateCAs(configDir, c.General.GenesisDeliver.TLS.RootCAs)
+		if c.General.GenesisDeliver.TLS.Certificate != "" {
+			cf.TranslatePathInPlace(configDir, &c.General.GenesisDeliver.TLS.Certificate)
+			cf.TranslatePathInPlace(configDir, &c.General.GenesisDeliver.TLS.PrivateKey)
+		}
+		c.General.Follower.TLS.RootCAs = translateCAs(configDir, c.General.Follower.TLS.RootCAs)
+		if c.General.Follower.TLS.Certificate != "" {
+			cf.TranslatePathInPlace(configDir, &c.General.Follower.TLS.Certificate)
+			cf.TranslatePathInPlace(configDir, &c.General.Follower.TLS.PrivateKey)
+		}
+		c.General.Cosign.TLS.RootCAs = translateCAs(configDir, c.General.Cosign.TLS.RootCAs)
+		if c.General.Cosign.TLS.Certificate != "" {
+			cf.TranslatePathInPlace(configDir, &c.General.Cosign.TLS.Certificate)
+			cf.TranslatePathInPlace(configDir, &c.General.Cosign.TLS.PrivateKey)
+		}
 		cf.TranslatePathInPlace(configDir, &c.General.LocalMSPDir)
 	}()
 
+	for name, listener := range c.General.Listeners {
+		switch {
+		case listener.ListenPort == 0:
+			logger.Panicf("General.Listeners.%s.ListenPort must be set.", name)
+		case listener.TLS.Enabled && listener.TLS.Certificate == "":
+			logger.Panicf("General.Listeners.%s.TLS.Certificate must be set if General.Listeners.%s.TLS.Enabled is set to true.", name, name)
+		case listener.TLS.Enabled && listener.TLS.PrivateKey == "" && !listener.TLS.BCCSPKey:
+			logger.Panicf("General.Listeners.%s.TLS.PrivateKey must be set if General.Listeners.%s.TLS.Enabled is set to true and General.Listeners.%s.TLS.BCCSPKey is not.", name, name, name)
+		case listener.ListenAddress == "":
+			logger.Infof("General.Listeners.%s.ListenAddress unset, setting to %s", name, defaults.General.ListenAddress)
+			listener.ListenAddress = defaults.General.ListenAddress
+			c.General.Listeners[name] = listener
+		}
+	}
+
 	for {
 		switch {
+		case c.General.Role == "":
+			logger.Infof("General.Role unset, setting to %s", defaults.General.Role)
+			c.General.Role = defaults.General.Role
+		case c.General.Role != "orderer" && c.General.Role != "archive" && c.General.Role != "replica":
+			logger.Panicf("General.Role must be one of orderer, archive or replica, not %s.", c.General.Role)
 		case c.General.LedgerType == "":
 			logger.Infof("General.LedgerType unset, setting to %s", defaults.General.LedgerType)
 			c.General.LedgerType = defaults.General.LedgerType
+		case c.General.Role == "archive" && c.General.LedgerType == "ram":
+			logger.Panicf("General.LedgerType must not be ram if General.Role is set to archive, as the RAM ledger discards old blocks.")
 
 		case c.General.ListenAddress == "":
 			logger.Infof("General.ListenAddress unset, setting to %s", defaults.General.ListenAddress)
@@ -268,6 +859,52 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			logger.Infof("General.ListenPort unset, setting to %s", defaults.General.ListenPort)
 			c.General.ListenPort = defaults.General.ListenPort
 
+		case c.General.UnixSocket.Path != "" && c.General.UnixSocket.Mode == "":
+			logger.Infof("General.UnixSocket.Mode unset, setting to %s", defaults.General.UnixSocket.Mode)
+			c.General.UnixSocket.Mode = defaults.General.UnixSocket.Mode
+
+		case c.General.TLS.Enabled && c.General.TLS.Certificate == "":
+			logger.Panicf("General.TLS.Certificate must be set if General.TLS.Enabled is set to true.")
+		case c.General.TLS.Enabled && c.General.TLS.PrivateKey == "" && !c.General.TLS.BCCSPKey:
+			logger.Panicf("General.TLS.PrivateKey must be set if General.TLS.Enabled is set to true and General.TLS.BCCSPKey is not.")
+
+		case c.General.Keepalive.ServerMinInterval == 0*time.Second:
+			logger.Infof("General.Keepalive.ServerMinInterval unset, setting to %v", defaults.General.Keepalive.ServerMinInterval)
+			c.General.Keepalive.ServerMinInterval = defaults.General.Keepalive.ServerMinInterval
+		case c.General.Keepalive.ServerInterval == 0*time.Second:
+			logger.Infof("General.Keepalive.ServerInterval unset, setting to %v", defaults.General.Keepalive.ServerInterval)
+			c.General.Keepalive.ServerInterval = defaults.General.Keepalive.ServerInterval
+		case c.General.Keepalive.ServerTimeout == 0*time.Second:
+			logger.Infof("General.Keepalive.ServerTimeout unset, setting to %v", defaults.General.Keepalive.ServerTimeout)
+			c.General.Keepalive.ServerTimeout = defaults.General.Keepalive.ServerTimeout
+		case c.General.Limits.MaxRecvMsgSize == 0:
+			logger.Infof("General.Limits.MaxRecvMsgSize unset, setting to %d", defaults.General.Limits.MaxRecvMsgSize)
+			c.General.Limits.MaxRecvMsgSize = defaults.General.Limits.MaxRecvMsgSize
+		case c.General.Limits.MaxSendMsgSize == 0:
+			logger.Infof("General.Limits.MaxSendMsgSize unset, setting to %d", defaults.General.Limits.MaxSendMsgSize)
+			c.General.Limits.MaxSendMsgSize = defaults.General.Limits.MaxSendMsgSize
+
+		case c.General.DrainTimeout == 0:
+			logger.Infof("General.DrainTimeout unset, setting to %v", defaults.General.DrainTimeout)
+			c.General.DrainTimeout = defaults.General.DrainTimeout
+		case c.General.Broadcast.CommitTimeout == 0:
+			logger.Infof("General.Broadcast.CommitTimeout unset, setting to %v", defaults.General.Broadcast.CommitTimeout)
+			c.General.Broadcast.CommitTimeout = defaults.General.Broadcast.CommitTimeout
+		case c.General.Deliver.RevalidationInterval == 0:
+			logger.Infof("General.Deliver.RevalidationInterval unset, setting to %v", defaults.General.Deliver.RevalidationInterval)
+			c.General.Deliver.RevalidationInterval = defaults.General.Deliver.RevalidationInterval
+		case c.General.Deliver.MaxBatchBytes == 0:
+			logger.Infof("General.Deliver.MaxBatchBytes unset, setting to %d", defaults.General.Deliver.MaxBatchBytes)
+			c.General.Deliver.MaxBatchBytes = defaults.General.Deliver.MaxBatchBytes
+		case c.General.Deliver.CacheBlocks == 0:
+			logger.Infof("General.Deliver.CacheBlocks unset, setting to %d", defaults.General.Deliver.CacheBlocks)
+			c.General.Deliver.CacheBlocks = defaults.General.Deliver.CacheBlocks
+		case c.General.Deliver.HeartbeatInterval == 0:
+			logger.Infof("General.Deliver.HeartbeatInterval unset, setting to %v", defaults.General.Deliver.HeartbeatInterval)
+			c.General.Deliver.HeartbeatInterval = defaults.General.Deliver.HeartbeatInterval
+		case !knownCompression(c.General.Deliver.Compression):
+			logger.Panicf("General.Deliver.Compression may only list gzip and snappy, not %v.", c.General.Deliver.Compression)
+
 		case c.General.LogLevel == "":
 			logger.Infof("General.LogLevel unset, setting to %s", defaults.General.LogLevel)
 			c.General.LogLevel = defaults.General.LogLevel
@@ -281,6 +918,30 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			c.General.GenesisFile = defaults.General.GenesisFile
 		case c.General.GenesisProfile == "":
 			c.General.GenesisProfile = defaults.General.GenesisProfile
+		case c.General.GenesisMethod == "deliver" && c.General.GenesisDeliver.Address == "":
+			logger.Panicf("General.GenesisDeliver.Address must be set if General.GenesisMethod is set to deliver.")
+		case c.General.GenesisDeliver.ChainID == "":
+			c.General.GenesisDeliver.ChainID = defaults.General.GenesisDeliver.ChainID
+		case c.General.GenesisDeliver.Timeout == 0:
+			c.General.GenesisDeliver.Timeout = defaults.General.GenesisDeliver.Timeout
+		case c.General.GenesisDeliver.RetryInterval == 0:
+			c.General.GenesisDeliver.RetryInterval = defaults.General.GenesisDeliver.RetryInterval
+		case c.General.GenesisDeliver.TLS.BCCSPKey:
+			logger.Panicf("General.GenesisDeliver.TLS.BCCSPKey is not supported.")
+		case c.General.Role != "orderer" && len(c.General.Follower.Endpoints) == 0:
+			logger.Panicf("General.Follower.Endpoints must be set if General.Role is set to %s.", c.General.Role)
+		case c.General.Follower.ChainID == "":
+			c.General.Follower.ChainID = defaults.General.Follower.ChainID
+		case c.General.Follower.RetryInterval == 0:
+			c.General.Follower.RetryInterval = defaults.General.Follower.RetryInterval
+		case !knownCompression(c.General.Follower.Compression):
+			logger.Panicf("General.Follower.Compression may only list gzip and snappy, not %v.", c.General.Follower.Compression)
+		case c.General.Follower.TLS.BCCSPKey:
+			logger.Panicf("General.Follower.TLS.BCCSPKey is not supported.")
+		case c.General.Cosign.Timeout == 0:
+			c.General.Cosign.Timeout = defaults.General.Cosign.Timeout
+		case c.General.Cosign.TLS.BCCSPKey:
+			logger.Panicf("General.Cosign.TLS.BCCSPKey is not supported.")
 
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.Certificate == "":
 			logger.Panicf("General.Kafka.TLS.Certificate must be set if General.Kafka.TLS.Enabled is set to true.")
@@ -288,11 +949,70 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			logger.Panicf("General.Kafka.TLS.PrivateKey must be set if General.Kafka.TLS.Enabled is set to true.")
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.RootCAs == nil:
 			logger.Panicf("General.Kafka.TLS.CertificatePool must be set if General.Kafka.TLS.Enabled is set to true.")
+		case c.Kafka.TLS.BCCSPKey:
+			logger.Panicf("Kafka.TLS.BCCSPKey is not supported.")
 
 		case c.General.Profile.Enabled && c.General.Profile.Address == "":
 			logger.Infof("Profiling enabled and General.Profile.Address unset, setting to %s", defaults.General.Profile.Address)
 			c.General.Profile.Address = defaults.General.Profile.Address
 
+		case c.General.Gateway.Enabled && c.General.Gateway.ListenAddress == "":
+			logger.Infof("Gateway enabled and General.Gateway.ListenAddress unset, setting to %s", defaults.General.Gateway.ListenAddress)
+			c.General.Gateway.ListenAddress = defaults.General.Gateway.ListenAddress
+		case c.General.Gateway.TLS.Enabled && c.General.Gateway.TLS.Certificate == "":
+			logger.Panicf("General.Gateway.TLS.Certificate must be set if General.Gateway.TLS.Enabled is set to true.")
+		case c.General.Gateway.TLS.Enabled && c.General.Gateway.TLS.PrivateKey == "":
+			logger.Panicf("General.Gateway.TLS.PrivateKey must be set if General.Gateway.TLS.Enabled is set to true.")
+		case c.General.Gateway.TLS.BCCSPKey:
+			logger.Panicf("General.Gateway.TLS.BCCSPKey is not supported.")
+
+		case c.General.Operations.Enabled && c.General.Operations.ListenAddress == "":
+			logger.Infof("Operations enabled and General.Operations.ListenAddress unset, setting to %s", defaults.General.Operations.ListenAddress)
+			c.General.Operations.ListenAddress = defaults.General.Operations.ListenAddress
+		case c.General.Operations.TLS.Enabled && c.General.Operations.TLS.Certificate == "":
+			logger.Panicf("General.Operations.TLS.Certificate must be set if General.Operations.TLS.Enabled is set to true.")
+		case c.General.Operations.TLS.Enabled && c.General.Operations.TLS.PrivateKey == "":
+			logger.Panicf("General.Operations.TLS.PrivateKey must be set if General.Operations.TLS.Enabled is set to true.")
+		case c.General.Operations.TLS.BCCSPKey:
+			logger.Panicf("General.Operations.TLS.BCCSPKey is not supported.")
+		case c.General.Operations.Health.FailureThreshold <= 0:
+			logger.Infof("General.Operations.Health.FailureThreshold unset, setting to %d", defaults.General.Operations.Health.FailureThreshold)
+			c.General.Operations.Health.FailureThreshold = defaults.General.Operations.Health.FailureThreshold
+
+		case c.General.StatsD.Enabled && c.General.StatsD.Address == "":
+			logger.Infof("StatsD enabled and General.StatsD.Address unset, setting to %s", defaults.General.StatsD.Address)
+			c.General.StatsD.Address = defaults.General.StatsD.Address
+		case c.General.StatsD.Enabled && c.General.StatsD.FlushInterval <= 0:
+			logger.Infof("StatsD enabled and General.StatsD.FlushInterval unset, setting to %s", defaults.General.StatsD.FlushInterval)
+			c.General.StatsD.FlushInterval = defaults.General.StatsD.FlushInterval
+
+		case c.General.Audit.Enabled && c.General.Audit.File == "" && !c.General.Audit.Logger:
+			logger.Panicf("General.Audit.File or General.Audit.Logger must be set if General.Audit.Enabled is set to true.")
+		case c.General.Audit.SyncInterval <= 0:
+			c.General.Audit.SyncInterval = defaults.General.Audit.SyncInterval
+
+		case c.General.Events.Enabled && c.General.Events.Webhook.URL == "" && len(c.General.Events.Kafka.Brokers) == 0 && c.General.Events.NATS.Address == "":
+			logger.Panicf("General.Events.Webhook.URL, General.Events.Kafka.Brokers or General.Events.NATS.Address must be set if General.Events.Enabled is set to true.")
+		case len(c.General.Events.Kafka.Brokers) > 0 && c.General.Events.Kafka.Topic == "":
+			logger.Panicf("General.Events.Kafka.Topic must be set if General.Events.Kafka.Brokers is set.")
+		case c.General.Events.Payload == "":
+			logger.Infof("General.Events.Payload unset, setting to %s", defaults.General.Events.Payload)
+			c.General.Events.Payload = defaults.General.Events.Payload
+		case c.General.Events.Payload != "block" && c.General.Events.Payload != "filtered" && c.General.Events.Payload != "header":
+			logger.Panicf("General.Events.Payload must be one of block, filtered or header, not %s.", c.General.Events.Payload)
+		case c.General.Events.RetryInitial <= 0:
+			c.General.Events.RetryInitial = defaults.General.Events.RetryInitial
+		case c.General.Events.RetryMax <= 0:
+			c.General.Events.RetryMax = defaults.General.Events.RetryMax
+		case c.General.Events.MaxRetries == 0:
+			c.General.Events.MaxRetries = defaults.General.Events.MaxRetries
+		case c.General.Events.Webhook.Timeout <= 0:
+			c.General.Events.Webhook.Timeout = defaults.General.Events.Webhook.Timeout
+		case c.General.Events.NATS.Subject == "":
+			c.General.Events.NATS.Subject = defaults.General.Events.NATS.Subject
+		case c.General.Events.NATS.Timeout <= 0:
+			c.General.Events.NATS.Timeout = defaults.General.Events.NATS.Timeout
+
 		case c.General.LocalMSPDir == "":
 			logger.Infof("General.LocalMSPDir unset, setting to %s", defaults.General.LocalMSPDir)
 			c.General.LocalMSPDir = defaults.General.LocalMSPDir
@@ -363,3 +1083,16 @@ func translateCAs(configDir string, certificateAuthorities []string) []string {
 	}
 	return results
 }
+
+// knownCompression returns whether the compression algorithms are
+// all supported
+func knownCompression(algorithms []string) bool {
+	for _, algorithm := range algorithms {
+		switch strings.ToLower(algorithm) {
+		case "gzip", "snappy":
+		default:
+			return false
+		}
+	}
+	return true
+}
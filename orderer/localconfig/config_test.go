@@ -116,3 +116,208 @@ func TestProfileConfig(t *testing.T) {
 	uconf.completeInitialization(DummyPath)
 	assert.Equal(t, defaults.General.Profile.Address, uconf.General.Profile.Address, "Expected profile address to be filled with default value")
 }
+
+func TestLimitsConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Limits: Limits{MaxSendMsgSize: 1024, MaxConcurrentStreams: 10}}}
+	uconf.completeInitialization("/dummy/path")
+	assert.Equal(t, defaults.General.Limits.MaxRecvMsgSize, uconf.General.Limits.MaxRecvMsgSize, "Expected max receive size to be filled with default value")
+	assert.Equal(t, uint32(1024), uconf.General.Limits.MaxSendMsgSize, "Expected configured max send size to be retained")
+	assert.Equal(t, uint32(10), uconf.General.Limits.MaxConcurrentStreams, "Expected configured max concurrent streams to be retained")
+	assert.Equal(t, uint32(0), uconf.General.Limits.MaxConnections, "Expected connections to be unlimited by default")
+}
+
+func TestOperationsConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Operations: Operations{Enabled: true}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Operations.ListenAddress, uconf.General.Operations.ListenAddress, "Expected listen address to be filled with default value")
+
+	uconf = &TopLevel{General: General{Operations: Operations{TLS: TLS{Enabled: true, PrivateKey: "private.key"}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a panic without an operations TLS certificate")
+}
+
+func TestGatewayConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Gateway: Gateway{Enabled: true}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Gateway.ListenAddress, uconf.General.Gateway.ListenAddress, "Expected listen address to be filled with default value")
+
+	uconf = &TopLevel{General: General{Gateway: Gateway{TLS: TLS{Enabled: true, Certificate: "cert.pem"}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a panic without a gateway TLS private key")
+}
+
+func TestStatsDConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{StatsD: StatsD{Enabled: true}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.StatsD.Address, uconf.General.StatsD.Address, "Expected address to be filled with default value")
+	assert.Equal(t, defaults.General.StatsD.FlushInterval, uconf.General.StatsD.FlushInterval, "Expected flush interval to be filled with default value")
+}
+
+func TestUnixSocketConfig(t *testing.T) {
+	uconf := &TopLevel{}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, "", uconf.General.UnixSocket.Path, "Expected no socket by default")
+
+	uconf = &TopLevel{General: General{UnixSocket: UnixSocket{Path: "orderer.sock"}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, filepath.Join(DummyPath, "orderer.sock"), uconf.General.UnixSocket.Path, "Expected a relative path to be translated")
+	assert.Equal(t, defaults.General.UnixSocket.Mode, uconf.General.UnixSocket.Mode, "Expected mode to be filled with default value")
+}
+
+func TestListenersConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Listeners: map[string]Listener{
+		"cluster": {ListenPort: 7051, TLS: TLS{Enabled: true, Certificate: "cluster.crt", PrivateKey: "cluster.key"}},
+	}}}
+	uconf.completeInitialization(DummyPath)
+	cluster := uconf.General.Listeners["cluster"]
+	assert.Equal(t, defaults.General.ListenAddress, cluster.ListenAddress, "Expected listen address to be filled with default value")
+	assert.Equal(t, filepath.Join(DummyPath, "cluster.crt"), cluster.TLS.Certificate, "Expected a relative path to be translated")
+
+	for _, listener := range []Listener{
+		{},
+		{ListenPort: 7051, TLS: TLS{Enabled: true, PrivateKey: "cluster.key"}},
+		{ListenPort: 7051, TLS: TLS{Enabled: true, Certificate: "cluster.crt"}},
+	} {
+		uconf := &TopLevel{General: General{Listeners: map[string]Listener{"cluster": listener}}}
+		assert.Panics(t, func() { uconf.completeInitialization(DummyPath) })
+	}
+}
+
+func TestAuditConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Audit: Audit{Enabled: true, File: "audit.log"}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, filepath.Join(DummyPath, "audit.log"), uconf.General.Audit.File, "Expected a relative path to be translated")
+	assert.Equal(t, time.Second, uconf.General.Audit.SyncInterval, "Expected the default sync interval")
+
+	uconf = &TopLevel{General: General{Audit: Audit{Enabled: true}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a sink to be required")
+}
+
+func TestGenesisDeliverConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{GenesisMethod: "deliver", GenesisDeliver: GenesisDeliver{
+		Address: "orderer0:7050",
+		TLS:     TLS{Enabled: true, RootCAs: []string{"ca.pem"}},
+	}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.GenesisDeliver.ChainID, uconf.General.GenesisDeliver.ChainID, "Expected chain ID to be filled with default value")
+	assert.Equal(t, defaults.General.GenesisDeliver.Timeout, uconf.General.GenesisDeliver.Timeout, "Expected timeout to be filled with default value")
+	assert.Equal(t, defaults.General.GenesisDeliver.RetryInterval, uconf.General.GenesisDeliver.RetryInterval, "Expected retry interval to be filled with default value")
+	assert.Equal(t, []string{filepath.Join(DummyPath, "ca.pem")}, uconf.General.GenesisDeliver.TLS.RootCAs, "Expected a relative path to be translated")
+
+	uconf = &TopLevel{General: General{GenesisMethod: "deliver"}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected an address to be required")
+}
+
+func TestFollowerConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Role: "archive", Follower: Follower{
+		Endpoints: []string{"orderer0:7050"},
+		TLS:       TLS{Enabled: true, RootCAs: []string{"ca.pem"}},
+	}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Follower.ChainID, uconf.General.Follower.ChainID, "Expected chain ID to be filled with default value")
+	assert.Equal(t, defaults.General.Follower.RetryInterval, uconf.General.Follower.RetryInterval, "Expected retry interval to be filled with default value")
+	assert.Equal(t, []string{filepath.Join(DummyPath, "ca.pem")}, uconf.General.Follower.TLS.RootCAs, "Expected a relative path to be translated")
+
+	uconf = &TopLevel{}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Role, uconf.General.Role, "Expected role to be filled with default value")
+
+	uconf = &TopLevel{General: General{Role: "archive"}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected endpoints to be required")
+
+	uconf = &TopLevel{General: General{Role: "replica"}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected endpoints to be required")
+
+	uconf = &TopLevel{General: General{Role: "archive", LedgerType: "ram", Follower: Follower{Endpoints: []string{"orderer0:7050"}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected the RAM ledger to be rejected")
+
+	uconf = &TopLevel{General: General{Role: "replica", LedgerType: "ram", Follower: Follower{Endpoints: []string{"orderer0:7050"}}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, "ram", uconf.General.LedgerType, "Expected a replica to accept the RAM ledger")
+
+	uconf = &TopLevel{General: General{Role: "archive", Follower: Follower{Endpoints: []string{"orderer0:7050"}, Compression: []string{"lz4"}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected an unknown compression algorithm to be rejected")
+
+	uconf = &TopLevel{General: General{Role: "peer"}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected an unknown role to be rejected")
+}
+
+func TestEventsConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Events: Events{Enabled: true, ProgressFile: "events.json", Webhook: EventsWebhook{URL: "http://example.com"}}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Events.Payload, uconf.General.Events.Payload, "Expected payload to be filled with default value")
+	assert.Equal(t, defaults.General.Events.RetryInitial, uconf.General.Events.RetryInitial, "Expected retry delay to be filled with default value")
+	assert.Equal(t, defaults.General.Events.RetryMax, uconf.General.Events.RetryMax, "Expected maximum retry delay to be filled with default value")
+	assert.Equal(t, defaults.General.Events.MaxRetries, uconf.General.Events.MaxRetries, "Expected maximum retries to be filled with default value")
+	assert.Equal(t, defaults.General.Events.Webhook.Timeout, uconf.General.Events.Webhook.Timeout, "Expected webhook timeout to be filled with default value")
+	assert.Equal(t, defaults.General.Events.NATS.Subject, uconf.General.Events.NATS.Subject, "Expected NATS subject to be filled with default value")
+	assert.Equal(t, filepath.Join(DummyPath, "events.json"), uconf.General.Events.ProgressFile, "Expected a relative path to be translated")
+
+	uconf = &TopLevel{General: General{Events: Events{Enabled: true}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a sink to be required")
+
+	uconf = &TopLevel{General: General{Events: Events{Kafka: EventsKafka{Brokers: []string{"kafka0:9092"}}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a Kafka topic to be required")
+
+	uconf = &TopLevel{General: General{Events: Events{Payload: "everything"}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected an unknown payload to be rejected")
+}
+
+func TestDrainTimeoutConfig(t *testing.T) {
+	uconf := &TopLevel{}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.DrainTimeout, uconf.General.DrainTimeout, "Expected drain timeout to be filled with default value")
+}
+
+func TestDeliverConfig(t *testing.T) {
+	uconf := &TopLevel{}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Deliver.RevalidationInterval, uconf.General.Deliver.RevalidationInterval, "Expected revalidation interval to be filled with default value")
+	assert.Equal(t, defaults.General.Deliver.MaxBatchBytes, uconf.General.Deliver.MaxBatchBytes, "Expected max batch bytes to be filled with default value")
+	assert.Equal(t, defaults.General.Deliver.CacheBlocks, uconf.General.Deliver.CacheBlocks, "Expected cache blocks to be filled with default value")
+	assert.Equal(t, defaults.General.Deliver.HeartbeatInterval, uconf.General.Deliver.HeartbeatInterval, "Expected heartbeat interval to be filled with default value")
+
+	uconf = &TopLevel{General: General{Deliver: Deliver{RevalidationInterval: -1, MaxBatchBytes: -1, CacheBlocks: -1, HeartbeatInterval: -1}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, time.Duration(-1), uconf.General.Deliver.RevalidationInterval, "Expected a negative interval to be retained")
+	assert.Equal(t, -1, uconf.General.Deliver.MaxBatchBytes, "Expected a negative max batch bytes to be retained")
+	assert.Equal(t, -1, uconf.General.Deliver.CacheBlocks, "Expected a negative cache size to be retained")
+	assert.Equal(t, time.Duration(-1), uconf.General.Deliver.HeartbeatInterval, "Expected a negative heartbeat interval to be retained")
+
+	uconf = &TopLevel{General: General{Deliver: Deliver{Compression: []string{"gzip", "Snappy"}}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, []string{"gzip", "Snappy"}, uconf.General.Deliver.Compression)
+
+	uconf = &TopLevel{General: General{Deliver: Deliver{Compression: []string{"gzip", "lz4"}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected an unknown compression algorithm to be rejected")
+}
+
+func TestKeepaliveConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Keepalive: Keepalive{ServerTimeout: 5 * time.Second}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Keepalive.ServerMinInterval, uconf.General.Keepalive.ServerMinInterval, "Expected keepalive min interval to be filled with default value")
+	assert.Equal(t, defaults.General.Keepalive.ServerInterval, uconf.General.Keepalive.ServerInterval, "Expected keepalive interval to be filled with default value")
+	assert.Equal(t, 5*time.Second, uconf.General.Keepalive.ServerTimeout, "Expected configured keepalive timeout to be retained")
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tls         TLS
+		shouldPanic bool
+	}{
+		{"Disabled", TLS{Enabled: false}, false},
+		{"EnabledNoPrivateKey", TLS{Enabled: true, Certificate: "public.key"}, true},
+		{"EnabledNoCertificate", TLS{Enabled: true, PrivateKey: "private.key"}, true},
+		{"Enabled", TLS{Enabled: true, PrivateKey: "private.key", Certificate: "public.key"}, false},
+		{"EnabledBCCSPKey", TLS{Enabled: true, BCCSPKey: true, Certificate: "public.key"}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uconf := &TopLevel{General: General{TLS: tc.tls}}
+			if tc.shouldPanic {
+				assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "should panic")
+			} else {
+				assert.NotPanics(t, func() { uconf.completeInitialization(DummyPath) }, "should not panic")
+			}
+		})
+	}
+}
@@ -65,7 +65,8 @@ func noopCommitters(size int) []filter.Committer {
 }
 
 // Ordered will add or cut the batch according to the state of Receiver, it blocks reading from Block on return
-func (mbc *Receiver) Ordered(env *cb.Envelope) ([][]*cb.Envelope, [][]filter.Committer, bool, bool) {
+func (mbc *Receiver) Ordered(msg *filter.Message) ([][]*cb.Envelope, [][]filter.Committer, bool, bool) {
+	env := msg.Envelope
 	defer func() {
 		<-mbc.Block
 	}()
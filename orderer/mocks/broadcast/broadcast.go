@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"fmt"
+	"sync"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// Support mocks the broadcast.Support interface, recording the messages
+// enqueued
+type Support struct {
+	// FiltersVal is the value returned by Filters()
+	FiltersVal *filter.RuleSet
+
+	// RejectEnqueue causes Enqueue to return false without enqueueing the
+	// message when set to true
+	RejectEnqueue bool
+
+	// PressureVal is the value returned by Pressure()
+	PressureVal backpressure.Status
+
+	// PolicyManagerVal is the value returned by PolicyManager(), which
+	// returns a manager whose policies accept any message if it is nil
+	PolicyManagerVal policies.Manager
+
+	// TxIndex is the index whose appended blocks commit the messages watched
+	// by WatchCommit, which never commits any if it is nil
+	TxIndex *txstatus.Index
+
+	// BroadcastQuotaVal is the value returned by BroadcastQuota()
+	BroadcastQuotaVal *ab.BroadcastQuota
+
+	lock     sync.Mutex
+	enqueued []*filter.Message
+}
+
+// Filters returns FiltersVal
+func (ms *Support) Filters() *filter.RuleSet {
+	return ms.FiltersVal
+}
+
+// Pressure returns PressureVal
+func (ms *Support) Pressure() backpressure.Status {
+	return ms.PressureVal
+}
+
+// PolicyManager returns PolicyManagerVal, or a manager whose policies accept
+// any message if it is nil
+func (ms *Support) PolicyManager() policies.Manager {
+	if ms.PolicyManagerVal == nil {
+		return &mockpolicies.Manager{Policy: &mockpolicies.Policy{}}
+	}
+	return ms.PolicyManagerVal
+}
+
+// WatchCommit watches the commit of the message in TxIndex
+func (ms *Support) WatchCommit(txHash []byte) (<-chan *ab.TransactionStatusResponse, func()) {
+	return ms.TxIndex.Watch(txHash)
+}
+
+// BroadcastQuota returns BroadcastQuotaVal
+func (ms *Support) BroadcastQuota() *ab.BroadcastQuota {
+	return ms.BroadcastQuotaVal
+}
+
+// Enqueue records the message as enqueued and returns true, unless
+// RejectEnqueue is set
+func (ms *Support) Enqueue(msg *filter.Message) bool {
+	if ms.RejectEnqueue {
+		return false
+	}
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	ms.enqueued = append(ms.enqueued, msg)
+	return true
+}
+
+// Enqueued returns the messages enqueued so far, in order
+func (ms *Support) Enqueued() []*filter.Message {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	return append([]*filter.Message(nil), ms.enqueued...)
+}
+
+// SupportManager mocks the broadcast.SupportManager interface
+type SupportManager struct {
+	// Chains holds the Support returned by GetChain for each chain
+	Chains map[string]broadcast.Support
+
+	// ProcessVal is the value returned by Process, which returns an error
+	// if it is nil
+	ProcessVal *cb.Envelope
+}
+
+// NewSupportManager returns a SupportManager with a single chain
+func NewSupportManager(chainID string, support broadcast.Support) *SupportManager {
+	return &SupportManager{Chains: map[string]broadcast.Support{chainID: support}}
+}
+
+// GetChain returns the Support of the chain in Chains
+func (mm *SupportManager) GetChain(chainID string) (broadcast.Support, bool) {
+	support, ok := mm.Chains[chainID]
+	return support, ok
+}
+
+// Process returns ProcessVal, or an error if it is nil
+func (mm *SupportManager) Process(configTx *cb.Envelope) (*cb.Envelope, error) {
+	if mm.ProcessVal == nil {
+		return nil, fmt.Errorf("Nil result implies error")
+	}
+	return mm.ProcessVal, nil
+}
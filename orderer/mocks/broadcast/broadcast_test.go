@@ -0,0 +1,44 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportInterface(t *testing.T) {
+	_ = broadcast.Support(&Support{})
+	_ = broadcast.SupportManager(&SupportManager{})
+}
+
+func TestEnqueue(t *testing.T) {
+	ms := &Support{}
+	msg := &filter.Message{}
+	assert.True(t, ms.Enqueue(msg))
+	assert.Equal(t, []*filter.Message{msg}, ms.Enqueued())
+
+	ms.RejectEnqueue = true
+	assert.False(t, ms.Enqueue(&filter.Message{}))
+	assert.Len(t, ms.Enqueued(), 1, "A rejected message should not be recorded")
+}
+
+func TestGetChain(t *testing.T) {
+	ms := &Support{}
+	mm := NewSupportManager("foo", ms)
+	support, ok := mm.GetChain("foo")
+	assert.True(t, ok)
+	assert.Equal(t, ms, support)
+	_, ok = mm.GetChain("bar")
+	assert.False(t, ok)
+
+	_, err := mm.Process(nil)
+	assert.Error(t, err)
+}
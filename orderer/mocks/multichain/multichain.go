@@ -17,6 +17,8 @@ limitations under the License.
 package multichain
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric/common/config"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
@@ -62,6 +64,11 @@ func (mcs *ConsenterSupport) SharedConfig() config.Orderer {
 	return mcs.SharedConfigVal
 }
 
+// BatchTimeout returns the batch timeout of SharedConfigVal
+func (mcs *ConsenterSupport) BatchTimeout() time.Duration {
+	return mcs.SharedConfigVal.BatchTimeout()
+}
+
 // CreateNextBlock creates a simple block structure with the given data
 func (mcs *ConsenterSupport) CreateNextBlock(data []*cb.Envelope) *cb.Block {
 	block := cb.NewBlock(0, nil)
@@ -17,18 +17,36 @@ limitations under the License.
 package multichain
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric/common/config"
 	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/clock"
 	"github.com/hyperledger/fabric/orderer/common/configtxfilter"
+	"github.com/hyperledger/fabric/orderer/common/cosign"
+	"github.com/hyperledger/fabric/orderer/common/extensions"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/hlc"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	"github.com/hyperledger/fabric/orderer/common/latency"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	"github.com/hyperledger/fabric/orderer/common/recording"
+	"github.com/hyperledger/fabric/orderer/common/replayfilter"
+	"github.com/hyperledger/fabric/orderer/common/scheduler"
 	"github.com/hyperledger/fabric/orderer/common/sigfilter"
 	"github.com/hyperledger/fabric/orderer/common/sizefilter"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
@@ -51,7 +69,7 @@ type Consenter interface {
 // 2. Messages are cut into blocks, the blocks are ordered, then the blocks are committed (sbft)
 type Chain interface {
 	// Enqueue accepts a message and returns true on acceptance, or false on failure
-	Enqueue(env *cb.Envelope) bool
+	Enqueue(msg *filter.Message) bool
 
 	// Errored returns a channel which will close when an error has occurred
 	// This is especially useful for the Deliver client, who must terminate waiting
@@ -72,6 +90,7 @@ type ConsenterSupport interface {
 	crypto.LocalSigner
 	BlockCutter() blockcutter.Receiver
 	SharedConfig() config.Orderer
+	BatchTimeout() time.Duration // BatchTimeout returns the batch timeout of the chain, lengthened while its ledger is slow
 	CreateNextBlock(messages []*cb.Envelope) *cb.Block
 	WriteBlock(block *cb.Block, committers []filter.Committer, encodedMetadataValue []byte) *cb.Block
 	ChainID() string // ChainID returns the chain ID this specific consenter instance is associated with
@@ -92,6 +111,14 @@ type ChainSupport interface {
 	// Errored returns whether the backing consenter has errored
 	Errored() <-chan struct{}
 
+	// IdentityValidator returns the cached validator for identities issued
+	// by the MSPs of the chain, for use by filters and access control
+	IdentityValidator() *identity.Validator
+
+	// TxIndex returns the index of the transactions of the chain, which
+	// reports whether each is pending or committed
+	TxIndex() *txstatus.Index
+
 	broadcast.Support
 	ConsenterSupport
 
@@ -100,6 +127,9 @@ type ChainSupport interface {
 
 	// ProposeConfigUpdate applies a CONFIG_UPDATE to an existing config to produce a *cb.ConfigEnvelope
 	ProposeConfigUpdate(env *cb.Envelope) (*cb.ConfigEnvelope, error)
+
+	// ConfigEnvelope returns the envelope of the current config
+	ConfigEnvelope() *cb.ConfigEnvelope
 }
 
 type chainSupport struct {
@@ -110,6 +140,17 @@ type chainSupport struct {
 	signer        crypto.LocalSigner
 	lastConfig    uint64
 	lastConfigSeq uint64
+	pressure      *backpressure.Monitor
+	recorder      *recording.Recorder
+	scheduler     *scheduler.Scheduler
+	txIndex       *txstatus.Index
+	clock         *hlc.Clock
+	stampTxs      bool
+	cosigner      *cosign.Cosigner
+	pipeline      *pipeline.Timers
+	latency       *latency.Tracker
+	tracer        *tracing.Tracer
+	metrics       *chainMetrics
 }
 
 func newChainSupport(
@@ -117,9 +158,11 @@ func newChainSupport(
 	ledgerResources *ledgerResources,
 	consenters map[string]Consenter,
 	signer crypto.LocalSigner,
+	conf Config,
+	cm *chainMetrics,
 ) *chainSupport {
 
-	cutter := blockcutter.NewReceiverImpl(ledgerResources.SharedConfig(), filters)
+	cutter := blockcutter.NewReceiverImpl(ledgerResources.ChainID(), ledgerResources.SharedConfig(), filters, conf.Memory, conf.Tracer, conf.Latency, conf.Metrics)
 	consenterType := ledgerResources.SharedConfig().ConsensusType()
 	consenter, ok := consenters[consenterType]
 	if !ok {
@@ -131,12 +174,28 @@ func newChainSupport(
 		cutter:          cutter,
 		filters:         filters,
 		signer:          signer,
+		pressure:        backpressure.NewMonitor(ledgerResources.ChainID(), conf.Backpressure, conf.Metrics),
+		scheduler:       conf.Scheduler,
+		txIndex:         txstatus.NewIndex(conf.TxStatus),
+		stampTxs:        conf.Timestamps.Transactions,
+		cosigner:        conf.Cosigner,
+		pipeline:        conf.Pipeline,
+		latency:         conf.Latency,
+		tracer:          conf.Tracer,
+		metrics:         cm,
 	}
+	cs.txIndex.Restore(cs.Reader())
 
 	cs.lastConfigSeq = cs.Sequence()
+	cs.applyQueueDepth()
+	cs.metrics.started(cs.ChainID(), cs.Reader().Height())
 
 	var err error
 
+	if cs.recorder, err = recording.Open(conf.Recording, cs.ChainID()); err != nil {
+		cs.logger().Errorf("Not recording the messages of the chain: %s", err)
+	}
+
 	lastBlock := ledger.GetBlock(cs.Reader(), cs.Reader().Height()-1)
 
 	// If this is the genesis block, the lastconfig field may be empty, and, the last config is necessary 0
@@ -144,48 +203,82 @@ func newChainSupport(
 	if lastBlock.Header.Number != 0 {
 		cs.lastConfig, err = utils.GetLastConfigIndexFromBlock(lastBlock)
 		if err != nil {
-			logger.Fatalf("[channel: %s] Error extracting last config block from block metadata: %s", cs.ChainID(), err)
+			cs.logger().Fatalf("Error extracting last config block from block metadata: %s", err)
 		}
 	}
 
+	// The clock resumes from the tip of the chain, so that blocks are stamped
+	// in order even if the wall clock of this orderer lags that of the
+	// orderer which wrote the tip
+	lastTimestamp, err := hlc.Latest(lastBlock)
+	if err != nil {
+		cs.logger().Fatalf("Error extracting timestamp from block metadata: %s", err)
+	}
+	cs.clock = hlc.NewClock(clock.Real(), lastTimestamp)
+
 	metadata, err := utils.GetMetadataFromBlock(lastBlock, cb.BlockMetadataIndex_ORDERER)
 	// Assuming a block created with cb.NewBlock(), this should not
 	// error even if the orderer metadata is an empty byte slice
 	if err != nil {
-		logger.Fatalf("[channel: %s] Error extracting orderer metadata: %s", cs.ChainID(), err)
+		cs.logger().Fatalf("Error extracting orderer metadata: %s", err)
 	}
-	logger.Debugf("[channel: %s] Retrieved metadata for tip of chain (blockNumber=%d, lastConfig=%d, lastConfigSeq=%d): %+v", cs.ChainID(), lastBlock.Header.Number, cs.lastConfig, cs.lastConfigSeq, metadata)
+	cs.logger().Debugf("Retrieved metadata for tip of chain (blockNumber=%d, lastConfig=%d, lastConfigSeq=%d): %+v", lastBlock.Header.Number, cs.lastConfig, cs.lastConfigSeq, metadata)
 
 	cs.chain, err = consenter.HandleChain(cs, metadata)
 	if err != nil {
-		logger.Fatalf("[channel: %s] Error creating consenter: %s", cs.ChainID(), err)
+		cs.logger().Fatalf("Error creating consenter: %s", err)
 	}
 
 	return cs
 }
 
 // createStandardFilters creates the set of filters for a normal (non-system) chain
-func createStandardFilters(ledgerResources *ledgerResources) *filter.RuleSet {
-	return filter.NewRuleSet([]filter.Rule{
+func createStandardFilters(ledgerResources *ledgerResources, conf Config) *filter.RuleSet {
+	return filter.NewRuleSet(ledgerResources.faults.Filters([]filter.Rule{
 		filter.EmptyRejectRule,
 		sizefilter.MaxBytesRule(ledgerResources.SharedConfig()),
-		sigfilter.New(policies.ChannelWriters, ledgerResources.PolicyManager()),
+		priority.Rule(conf.Priorities),
+		extensions.Rule(conf.Extensions),
+		sigfilter.NewValidating(policies.ChannelWriters, ledgerResources.PolicyManager(), ledgerResources.IdentityValidator(), conf.Audit),
 		configtxfilter.NewFilter(ledgerResources),
+		newReplayFilter(ledgerResources),
 		filter.AcceptRule,
-	})
+	}))
 
 }
 
 // createSystemChainFilters creates the set of filters for the ordering system chain
 func createSystemChainFilters(ml *multiLedger, ledgerResources *ledgerResources) *filter.RuleSet {
-	return filter.NewRuleSet([]filter.Rule{
+	return filter.NewRuleSet(ledgerResources.faults.Filters([]filter.Rule{
 		filter.EmptyRejectRule,
 		sizefilter.MaxBytesRule(ledgerResources.SharedConfig()),
-		sigfilter.New(policies.ChannelWriters, ledgerResources.PolicyManager()),
+		priority.Rule(ml.config.Priorities),
+		extensions.Rule(ml.config.Extensions),
+		sigfilter.NewValidating(policies.ChannelWriters, ledgerResources.PolicyManager(), ledgerResources.IdentityValidator(), ml.config.Audit),
 		newSystemChainFilter(ledgerResources, ml),
 		configtxfilter.NewFilter(ledgerResources),
+		newReplayFilter(ledgerResources),
 		filter.AcceptRule,
-	})
+	}))
+}
+
+// newReplayFilter creates a replay filter for the chain, with the window of
+// its config, tracking the messages already on its ledger
+func newReplayFilter(ledgerResources *ledgerResources) *replayfilter.Filter {
+	rf := replayfilter.NewWindow(epoch, replayWindow(ledgerResources.SharedConfig()), clock.Real())
+	rf.Restore(ledgerResources.ledger)
+	ledgerResources.replay = rf
+	return rf
+}
+
+// replayWindow returns the replay window of the orderer config of a chain
+func replayWindow(sharedConfig config.Orderer) replayfilter.Window {
+	return replayfilter.Window{Size: int(sharedConfig.ReplayWindowSize()), TTL: sharedConfig.ReplayTTL()}
+}
+
+// logger returns a logger attaching the channel to its records
+func (cs *chainSupport) logger() *flogging.FieldLogger {
+	return flogging.WithChannel(logger, cs.ChainID())
 }
 
 func (cs *chainSupport) start() {
@@ -212,14 +305,64 @@ func (cs *chainSupport) Reader() ledger.Reader {
 	return cs.ledger
 }
 
-func (cs *chainSupport) Enqueue(env *cb.Envelope) bool {
-	return cs.chain.Enqueue(env)
+// applyReplayWindow has the replay filter of the chain track the messages in
+// the window of its config. It is applied whenever a config block is
+// committed, at the same block on every orderer of the chain.
+func (cs *chainSupport) applyReplayWindow() {
+	cs.replay.SetWindow(replayWindow(cs.SharedConfig()))
+}
+
+// applyQueueDepth has the chain overloaded beyond the queue depth of its
+// config, if set, or else that of the local config. It is applied when the
+// chain is created and whenever a config block is committed.
+func (cs *chainSupport) applyQueueDepth() {
+	cs.pressure.SetMaxQueueDepth(int(cs.SharedConfig().BroadcastQueueDepth()))
+}
+
+// Enqueue refuses the message while the chain is overloaded, so that
+// broadcast clients receive SERVICE_UNAVAILABLE, unless it is of an urgent
+// priority class, as config messages are, in which case it is only refused
+// while the priority lane of the chain is full.
+func (cs *chainSupport) Enqueue(msg *filter.Message) bool {
+	if priority.Urgent(priority.Of(msg)) {
+		if !cs.pressure.EnterUrgent() {
+			cs.logger().Warningf("Refusing urgent message, the priority lane of the chain is full")
+			return false
+		}
+		defer cs.pressure.ExitUrgent()
+	} else {
+		if !cs.pressure.Enter() {
+			cs.logger().Warningf("Refusing message, the chain is overloaded")
+			return false
+		}
+		defer cs.pressure.Exit()
+	}
+	cs.faults.Stall()
+	if !cs.chain.Enqueue(msg) {
+		return false
+	}
+	cs.txIndex.Enqueued(msg)
+	if cs.recorder != nil {
+		if err := cs.recorder.Record(msg.Envelope, time.Now()); err != nil {
+			cs.logger().Warningf("Could not record message: %s", err)
+		}
+	}
+	return true
+}
+
+// Pressure returns the load of the chain, by which it refuses messages
+func (cs *chainSupport) Pressure() backpressure.Status {
+	return cs.pressure.Status()
 }
 
 func (cs *chainSupport) Errored() <-chan struct{} {
 	return cs.chain.Errored()
 }
 
+func (cs *chainSupport) BatchTimeout() time.Duration {
+	return cs.pressure.BatchTimeout(cs.SharedConfig().BatchTimeout())
+}
+
 func (cs *chainSupport) CreateNextBlock(messages []*cb.Envelope) *cb.Block {
 	return ledger.CreateNextBlock(cs.ledger, messages)
 }
@@ -246,10 +389,28 @@ func (cs *chainSupport) addBlockSignature(block *cb.Block) {
 	})
 }
 
+// collectBlockSignatures has the other orderers of the channel sign the
+// block as well when the block validation policy of the channel requires more
+// signatures than that of this orderer. A block which cannot collect enough
+// is written regardless, as the chain cannot skip it, and its consumers
+// fetch it from an orderer which collected them instead.
+func (cs *chainSupport) collectBlockSignatures(block *cb.Block) {
+	if cs.cosigner == nil {
+		return
+	}
+	// Recorded first, so that orderers collecting their own signatures on
+	// the block at the same time are given this one
+	cs.cosigner.Produced(cs.ChainID(), block.Header)
+	policy, _ := cs.PolicyManager().GetPolicy(policies.BlockValidation)
+	if err := cs.cosigner.Collect(cs.ChainID(), block, policy, cs.IdentityValidator(), cs.ChannelConfig().OrdererAddresses()); err != nil {
+		cs.logger().Errorf("Writing block %d without the signatures required: %s", block.Header.Number, err)
+	}
+}
+
 func (cs *chainSupport) addLastConfigSignature(block *cb.Block) {
 	configSeq := cs.Sequence()
 	if configSeq > cs.lastConfigSeq {
-		logger.Debugf("[channel: %s] Detected lastConfigSeq transitioning from %d to %d, setting lastConfig from %d to %d", cs.ChainID(), cs.lastConfigSeq, configSeq, cs.lastConfig, block.Header.Number)
+		cs.logger().Debugf("Detected lastConfigSeq transitioning from %d to %d, setting lastConfig from %d to %d", cs.lastConfigSeq, configSeq, cs.lastConfig, block.Header.Number)
 		cs.lastConfig = block.Header.Number
 		cs.lastConfigSeq = configSeq
 	}
@@ -259,7 +420,7 @@ func (cs *chainSupport) addLastConfigSignature(block *cb.Block) {
 	}
 
 	lastConfigValue := utils.MarshalOrPanic(&cb.LastConfig{Index: cs.lastConfig})
-	logger.Debugf("[channel: %s] About to write block, setting its LAST_CONFIG to %d", cs.ChainID(), cs.lastConfig)
+	cs.logger().Debugf("About to write block, setting its LAST_CONFIG to %d", cs.lastConfig)
 
 	lastConfigSignature.Signature = utils.SignOrPanic(cs.signer, util.ConcatenateBytes(lastConfigValue, lastConfigSignature.SignatureHeader, block.Header.Bytes()))
 
@@ -271,26 +432,103 @@ func (cs *chainSupport) addLastConfigSignature(block *cb.Block) {
 	})
 }
 
+// addTimestamp stamps the block with the time it is ordered, signed as the
+// last config is
+func (cs *chainSupport) addTimestamp(block *cb.Block) {
+	for len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_TIMESTAMP) {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, []byte{})
+	}
+
+	timestampSignature := &cb.MetadataSignature{
+		SignatureHeader: utils.MarshalOrPanic(utils.NewSignatureHeaderOrPanic(cs.signer)),
+	}
+
+	timestampValue := cs.clock.Stamp(block, cs.stampTxs)
+
+	timestampSignature.Signature = utils.SignOrPanic(cs.signer, util.ConcatenateBytes(timestampValue, timestampSignature.SignatureHeader, block.Header.Bytes()))
+
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TIMESTAMP] = utils.MarshalOrPanic(&cb.Metadata{
+		Value: timestampValue,
+		Signatures: []*cb.MetadataSignature{
+			timestampSignature,
+		},
+	})
+}
+
+// addExtensions records the extensions carried by the transactions of the
+// block. They are signed by their creators as part of the transactions,
+// which the data hash of the block commits to, so the metadata is not signed.
+func (cs *chainSupport) addExtensions(block *cb.Block) {
+	txExtensions := extensions.Collect(block)
+	if txExtensions == nil {
+		return
+	}
+	for len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_EXTENSIONS) {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, []byte{})
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_EXTENSIONS] = utils.MarshalOrPanic(&cb.Metadata{Value: txExtensions})
+}
+
 func (cs *chainSupport) WriteBlock(block *cb.Block, committers []filter.Committer, encodedMetadataValue []byte) *cb.Block {
+	start := time.Now()
+	size := 0
+	for _, data := range block.GetData().GetData() {
+		size += len(data)
+	}
+	// The signatures of the other orderers are collected before taking a
+	// slot of the scheduler, as they may take up to the cosign timeout, and
+	// the other orderers may themselves be waiting for a slot
+	cs.addBlockSignature(block)
+	cs.collectBlockSignatures(block)
+
+	cs.scheduler.Acquire(cs.ChainID(), size)
+	defer cs.scheduler.Release()
+
 	for _, committer := range committers {
 		committer.Commit()
 	}
+	if cs.Sequence() > cs.lastConfigSeq {
+		cs.applyQueueDepth()
+		cs.applyReplayWindow()
+	}
 	// Set the orderer-related metadata field
 	if encodedMetadataValue != nil {
 		block.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&cb.Metadata{Value: encodedMetadataValue})
 	}
-	cs.addBlockSignature(block)
 	cs.addLastConfigSignature(block)
+	cs.addTimestamp(block)
+	cs.addExtensions(block)
 
+	appendStart := time.Now()
+	cs.pressure.AppendStarted()
 	err := cs.ledger.Append(block)
 	if err != nil {
-		logger.Panicf("[channel: %s] Could not append block: %s", cs.ChainID(), err)
+		cs.logger().Panicf("Could not append block: %s", err)
 	}
-	logger.Debugf("[channel: %s] Wrote block %d", cs.ChainID(), block.GetHeader().Number)
+	appended := time.Now()
+	cs.pressure.Appended(appended.Sub(appendStart))
+	cs.pipeline.Observe(pipeline.StageAppend, appended.Sub(appendStart))
+	cs.latency.Appended(block, start, appended)
+	cs.tracer.Appended(block, start, appended)
+	cs.txIndex.Appended(block)
+	cs.metrics.written(cs.ChainID(), block, start, appendStart, appended)
+	cs.logger().Debugf("Wrote block %d", block.GetHeader().Number)
 
 	return block
 }
 
+func (cs *chainSupport) TxIndex() *txstatus.Index {
+	return cs.txIndex
+}
+
+func (cs *chainSupport) BroadcastQuota() *ab.BroadcastQuota {
+	return cs.SharedConfig().BroadcastQuota()
+}
+
+func (cs *chainSupport) WatchCommit(txHash []byte) (<-chan *ab.TransactionStatusResponse, func()) {
+	return cs.txIndex.Watch(txHash)
+}
+
 func (cs *chainSupport) Height() uint64 {
 	return cs.Reader().Height()
 }
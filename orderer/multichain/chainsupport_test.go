@@ -18,16 +18,24 @@ package multichain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
 	"github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/extensions"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/hlc"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockLedgerReadWriter struct {
@@ -66,7 +74,7 @@ func (mc *mockCommitter) Commit() {
 func TestCommitConfig(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto()}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
 	assert.Equal(t, uint64(0), cs.Height(), "Should has height of 0")
 
 	txs := []*cb.Envelope{makeNormalTx("foo", 0), makeNormalTx("bar", 1)}
@@ -90,16 +98,75 @@ func TestCommitConfig(t *testing.T) {
 func TestWriteBlockSignatures(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto()}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
 
 	actual := utils.GetMetadataFromBlockOrPanic(cs.WriteBlock(cb.NewBlock(0, nil), nil, nil), cb.BlockMetadataIndex_SIGNATURES)
 	assert.NotNil(t, actual, "Block should have block signature")
 }
 
+func TestWriteBlockTimestamp(t *testing.T) {
+	ml := &mockLedgerReadWriter{}
+	cm := &mockconfigtx.Manager{}
+	// The wall clock of this orderer lags the timestamp of the tip
+	wall := clock.NewManual(time.Unix(100, 0))
+	tip := hlc.Timestamp{Physical: time.Unix(200, 0).UnixNano(), Logical: 3}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(wall, tip)}
+
+	txs := &cb.BlockData{Data: [][]byte{[]byte("foo"), []byte("bar")}}
+	block := cb.NewBlock(0, nil)
+	block.Data = txs
+	block = cs.WriteBlock(block, nil, nil)
+	ts, err := hlc.FromBlock(block)
+	assert.NoError(t, err)
+	assert.Equal(t, hlc.Timestamp{Physical: tip.Physical, Logical: 4}, hlc.FromProto(ts.Block), "The block should be stamped after the tip")
+	assert.Empty(t, ts.Transactions, "Transactions should not be stamped by default")
+	md := utils.GetMetadataFromBlockOrPanic(block, cb.BlockMetadataIndex_TIMESTAMP)
+	assert.Len(t, md.Signatures, 1, "The timestamp should be signed")
+
+	cs.stampTxs = true
+	wall.Advance(time.Hour)
+	block = cb.NewBlock(1, nil)
+	block.Data = txs
+	block = cs.WriteBlock(block, nil, nil)
+	ts, err = hlc.FromBlock(block)
+	assert.NoError(t, err)
+	now := wall.Now().UnixNano()
+	assert.Equal(t, hlc.Timestamp{Physical: now}, hlc.FromProto(ts.Block), "The block should be stamped with the wall clock once it catches up")
+	assert.Equal(t, []*cb.HybridTimestamp{{Physical: now, Logical: 1}, {Physical: now, Logical: 2}}, ts.Transactions)
+	latest, err := hlc.Latest(block)
+	assert.NoError(t, err)
+	assert.Equal(t, hlc.Timestamp{Physical: now, Logical: 2}, latest)
+}
+
+func TestWriteBlockExtensions(t *testing.T) {
+	ml := &mockLedgerReadWriter{}
+	cm := &mockconfigtx.Manager{}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
+
+	block := cs.WriteBlock(cs.CreateNextBlock([]*cb.Envelope{makeNormalTx("foo", 0)}), nil, nil)
+	assert.Empty(t, block.Metadata.Metadata[cb.BlockMetadataIndex_EXTENSIONS], "Blocks without extensions should not record any")
+
+	payload := utils.UnmarshalPayloadOrPanic(makeNormalTx("foo", 1).Payload)
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	chdr.OrdererExtension = utils.MarshalOrPanic(&ab.OrdererHeaderExtension{Extensions: []byte("trace")})
+	payload.Header.ChannelHeader = utils.MarshalOrPanic(chdr)
+	traced := &cb.Envelope{Payload: utils.MarshalOrPanic(payload)}
+
+	block = cb.NewBlock(1, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(makeNormalTx("foo", 2)), utils.MarshalOrPanic(traced)}
+	block = cs.WriteBlock(block, nil, nil)
+	txExtensions, err := extensions.FromBlock(block)
+	require.NoError(t, err)
+	require.Len(t, txExtensions, 2)
+	assert.Empty(t, txExtensions[0])
+	assert.Equal(t, []byte("trace"), txExtensions[1])
+}
+
 func TestWriteBlockOrdererMetadata(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto()}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
 
 	value := []byte("foo")
 	expected := &cb.Metadata{Value: value}
@@ -111,7 +178,7 @@ func TestWriteBlockOrdererMetadata(t *testing.T) {
 func TestSignature(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto()}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
 
 	message := []byte("Darth Vader")
 	signed, _ := cs.Sign(message)
@@ -124,8 +191,8 @@ func TestSignature(t *testing.T) {
 
 func TestWriteLastConfig(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
-	cm := &mockconfigtx.Manager{}
-	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto()}
+	cm := &mockconfigtx.Manager{Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{OrdererConfigVal: &mockconfig.Orderer{}}}}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
 
 	expected := uint64(0)
 	lc := utils.GetLastConfigIndexFromBlockOrPanic(cs.WriteBlock(cb.NewBlock(0, nil), nil, nil))
@@ -145,7 +212,7 @@ func TestWriteLastConfig(t *testing.T) {
 		cm.SequenceVal = 2
 		expected = uint64(4)
 
-		cs = &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto()}
+		cs = &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
 		lc := utils.GetLastConfigIndexFromBlockOrPanic(cs.WriteBlock(cb.NewBlock(4, nil), nil, nil))
 		assert.Equal(t, expected, lc, "Second block should have config block index of %d, but got %d", expected, lc)
 
@@ -153,3 +220,120 @@ func TestWriteLastConfig(t *testing.T) {
 		assert.Equal(t, expected, lc, "Second block should have config block index of %d, but got %d")
 	})
 }
+
+func TestBlockMetrics(t *testing.T) {
+	cm := &mockconfigtx.Manager{ChainIDVal: "blockmetrics"}
+	registry := metrics.NewRegistry()
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: &mockLedgerReadWriter{}}, signer: mockCrypto(), pressure: backpressure.NewMonitor("", backpressure.Config{}, nil), clock: hlc.NewClock(clock.Real(), hlc.Timestamp{}), metrics: newChainMetrics(registry)}
+	cs.metrics.started(cs.ChainID(), 0)
+
+	before := time.Now()
+	cs.WriteBlock(cb.NewBlock(0, nil), nil, nil)
+	cs.WriteBlock(cb.NewBlock(1, nil), nil, nil)
+
+	gathered := map[string]float64{}
+	for _, f := range registry.Gather() {
+		for _, s := range f.Series {
+			if len(s.LabelValues) == 1 && s.LabelValues[0] == "blockmetrics" {
+				gathered[f.Name] = s.Value
+			}
+		}
+	}
+	assert.Equal(t, float64(2), gathered["orderer_consensus_blocks_produced_total"])
+	assert.InDelta(t, float64(before.Unix()), gathered["orderer_consensus_last_block_timestamp_seconds"], 5)
+	assert.Contains(t, gathered, "orderer_consensus_seconds_since_last_block")
+	assert.InDelta(t, 0, gathered["orderer_consensus_seconds_since_last_block"], 5)
+}
+
+func TestEnqueueOverloaded(t *testing.T) {
+	cm := &mockconfigtx.Manager{ChainIDVal: "overloaded", Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{OrdererConfigVal: &mockconfig.Orderer{}}}}
+	pressure := backpressure.NewMonitor("overloaded", backpressure.Config{MaxAppendLatency: time.Millisecond}, nil)
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: &mockLedgerReadWriter{}}, signer: mockCrypto(), pressure: pressure, clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
+
+	cs.WriteBlock(cb.NewBlock(0, nil), nil, nil)
+	pressure.Appended(time.Second)
+	assert.False(t, cs.Enqueue(&filter.Message{}), "Messages should be refused while the ledger is slow")
+}
+
+func TestEnqueueUrgentOverloaded(t *testing.T) {
+	cm := &mockconfigtx.Manager{ChainIDVal: "overloaded", Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{OrdererConfigVal: &mockconfig.Orderer{}}}}
+	pressure := backpressure.NewMonitor("overloaded", backpressure.Config{MaxQueueDepth: 1}, nil)
+	chain := &mockChain{queue: make(chan *filter.Message, 2)}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: &mockLedgerReadWriter{}}, signer: mockCrypto(), pressure: pressure, chain: chain}
+
+	assert.True(t, pressure.Enter())
+	assert.False(t, cs.Enqueue(&filter.Message{}), "Messages should be refused while the queue is full")
+	assert.True(t, cs.Enqueue(&filter.Message{Extension: &ab.OrdererHeaderExtension{Priority: ab.PriorityClass_CONTROL}}),
+		"Urgent messages should be enqueued while the chain is overloaded")
+	assert.Len(t, chain.queue, 1)
+}
+
+func TestEnqueueUrgentLaneFull(t *testing.T) {
+	cm := &mockconfigtx.Manager{ChainIDVal: "overloaded", Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{OrdererConfigVal: &mockconfig.Orderer{}}}}
+	pressure := backpressure.NewMonitor("overloaded", backpressure.Config{MaxUrgentQueueDepth: 1}, nil)
+	chain := &mockChain{queue: make(chan *filter.Message, 2)}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: &mockLedgerReadWriter{}}, signer: mockCrypto(), pressure: pressure, chain: chain}
+	config := &filter.Message{ChannelHeader: &cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG)}}
+
+	assert.True(t, pressure.EnterUrgent())
+	assert.False(t, cs.Enqueue(config), "Config messages should be refused while the priority lane is full")
+	assert.True(t, cs.Enqueue(&filter.Message{}), "Other messages should not wait in the priority lane")
+	pressure.ExitUrgent()
+	assert.True(t, cs.Enqueue(config))
+	assert.Len(t, chain.queue, 2)
+}
+
+func TestEnqueueConfiguredQueueDepth(t *testing.T) {
+	oc := &mockconfig.Orderer{BroadcastQueueDepthVal: 2}
+	cm := &mockconfigtx.Manager{ChainIDVal: "deep", Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{OrdererConfigVal: oc}}}
+	pressure := backpressure.NewMonitor("deep", backpressure.Config{MaxQueueDepth: 1}, nil)
+	chain := &mockChain{queue: make(chan *filter.Message, 2)}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: &mockLedgerReadWriter{}}, signer: mockCrypto(), pressure: pressure, chain: chain, clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
+	cs.applyQueueDepth()
+
+	assert.True(t, pressure.Enter())
+	assert.True(t, cs.Enqueue(&filter.Message{}), "The queue depth of the channel config should replace that of the local config")
+	assert.True(t, pressure.Enter())
+	assert.False(t, cs.Enqueue(&filter.Message{}), "Messages beyond the queue depth of the channel config should be refused")
+	assert.EqualValues(t, 2, cs.Pressure().Config.MaxQueueDepth)
+
+	oc.BroadcastQueueDepthVal = 0
+	pressure.Exit()
+	assert.True(t, cs.Enqueue(&filter.Message{}), "The queue depth should only change once a config block is committed")
+
+	cm.SequenceVal++
+	cs.WriteBlock(cb.NewBlock(0, nil), nil, nil)
+	assert.False(t, cs.Enqueue(&filter.Message{}), "The local queue depth should apply once the channel config no longer sets one")
+	assert.EqualValues(t, 1, cs.Pressure().Config.MaxQueueDepth)
+}
+
+func TestReplayWindowConfigured(t *testing.T) {
+	tx := func(nonce string) *filter.Message {
+		msg, err := filter.NewMessage(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{Header: &cb.Header{
+			ChannelHeader:   utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_ENDORSER_TRANSACTION), ChannelId: "replay"}),
+			SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte("alice"), Nonce: []byte(nonce)}),
+		}})})
+		require.NoError(t, err)
+		return msg
+	}
+	oc := &mockconfig.Orderer{ReplayWindowSizeVal: 2}
+	cm := &mockconfigtx.Manager{ChainIDVal: "replay", Initializer: mockconfigtx.Initializer{Resources: mockconfigtx.Resources{OrdererConfigVal: oc}}}
+	pressure := backpressure.NewMonitor("replay", backpressure.Config{}, nil)
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: &mockLedgerReadWriter{}}, signer: mockCrypto(), pressure: pressure, clock: hlc.NewClock(clock.Real(), hlc.Timestamp{})}
+	rf := newReplayFilter(cs.ledgerResources)
+	for _, nonce := range []string{"1", "2", "3"} {
+		rf.ApplyOrdering(tx(nonce))
+	}
+	action, _ := rf.Apply(tx("1"))
+	assert.EqualValues(t, filter.Forward, action, "Only the messages in the window of the channel config should be tracked")
+	action, _ = rf.Apply(tx("2"))
+	assert.EqualValues(t, filter.Reject, action)
+
+	oc.ReplayWindowSizeVal = 1
+	cm.SequenceVal++
+	cs.WriteBlock(cb.NewBlock(0, nil), nil, nil)
+	action, _ = rf.Apply(tx("2"))
+	assert.EqualValues(t, filter.Forward, action, "The window should shrink once a config block is committed")
+	action, _ = rf.Apply(tx("3"))
+	assert.EqualValues(t, filter.Reject, action)
+}
@@ -7,11 +7,30 @@ package multichain
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/hyperledger/fabric/common/config"
 	"github.com/hyperledger/fabric/common/configtx"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
+	"github.com/hyperledger/fabric/orderer/common/chaos"
+	"github.com/hyperledger/fabric/orderer/common/cosign"
+	"github.com/hyperledger/fabric/orderer/common/extensions"
+	"github.com/hyperledger/fabric/orderer/common/hlc"
+	"github.com/hyperledger/fabric/orderer/common/identity"
+	"github.com/hyperledger/fabric/orderer/common/latency"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	"github.com/hyperledger/fabric/orderer/common/recording"
+	"github.com/hyperledger/fabric/orderer/common/replayfilter"
+	"github.com/hyperledger/fabric/orderer/common/scheduler"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -36,13 +55,37 @@ type Manager interface {
 	// SystemChannelID returns the channel ID for the system channel
 	SystemChannelID() string
 
+	// ChannelIDs returns the IDs of all chains, in sorted order
+	ChannelIDs() []string
+
+	// JoinChannel creates and starts a chain for a standard channel from its
+	// genesis config block
+	JoinChannel(configBlock *cb.Block) error
+
 	// NewChannelConfig returns a bare bones configuration ready for channel
 	// creation request to be applied on top of it
 	NewChannelConfig(envConfigUpdate *cb.Envelope) (configtxapi.Manager, error)
+
+	// Halt stops the consenters of all chains, the system chain last, and
+	// then closes the ledgers. It is only called once the orderer has stopped
+	// serving clients.
+	Halt()
 }
 
 type configResources struct {
 	configtxapi.Manager
+
+	validatorOnce sync.Once
+	validator     *identity.Validator
+}
+
+// IdentityValidator returns the validator for identities issued by the MSPs
+// of the chain, creating it on first use
+func (cr *configResources) IdentityValidator() *identity.Validator {
+	cr.validatorOnce.Do(func() {
+		cr.validator = identity.NewValidator(cr.Manager, identity.DefaultCacheSize)
+	})
+	return cr.validator
 }
 
 func (cr *configResources) SharedConfig() config.Orderer {
@@ -56,13 +99,63 @@ func (cr *configResources) SharedConfig() config.Orderer {
 type ledgerResources struct {
 	*configResources
 	ledger ledger.ReadWriter
+	faults chaos.Faults
+	// replay is the replay filter of the chain, once its filters are created
+	replay *replayfilter.Filter
+}
+
+// Config is the configuration of the chains of a Manager. Its zero value
+// orders the messages of each chain without pushing back, scheduling, faults or
+// recording, rejects the messages carrying extensions, cosigns no block,
+// neither accounts for the pending batches nor times, follows, traces or
+// indexes the transactions of the chains, and records nothing in an audit
+// trail.
+type Config struct {
+	// Backpressure are the thresholds past which a chain is overloaded
+	Backpressure backpressure.Config
+	// Recording names the chains whose messages are recorded
+	Recording recording.Config
+	// Faults are the faults injected into the chains, by chain ID
+	Faults map[string]chaos.Faults
+	// Scheduler, if set, schedules the commits of the chains
+	Scheduler *scheduler.Scheduler
+	// Priorities are the priority classes the creators of messages are
+	// entitled to
+	Priorities priority.Config
+	// Extensions are the limits on the extensions of the messages
+	Extensions extensions.Config
+	// TxStatus is the configuration of the transaction index of each chain
+	TxStatus txstatus.Config
+	// Timestamps is the configuration of the timestamps of the blocks
+	Timestamps hlc.Config
+	// Cosigner, if set, collects the signatures of the other orderers on
+	// the blocks produced
+	Cosigner *cosign.Cosigner
+	// Memory, if set, accounts for the pending batches of the chains
+	Memory *memory.Accountant
+	// Pipeline, if set, times the appends of the blocks
+	Pipeline *pipeline.Timers
+	// Latency, if set, follows the transactions until they are appended
+	Latency *latency.Tracker
+	// Tracer, if set, traces the sampled transactions
+	Tracer *tracing.Tracer
+	// Audit, if set, records the messages denied by the chains
+	Audit *audit.Trail
+	// Metrics, if set, creates the metrics of the chains instead of the
+	// default registry
+	Metrics metrics.Provider
 }
 
 type multiLedger struct {
+	// lock serializes the replacement of the chains map, readers see either
+	// the old or new map without locking
+	lock            sync.Mutex
 	chains          map[string]*chainSupport
 	consenters      map[string]Consenter
 	ledgerFactory   ledger.Factory
 	signer          crypto.LocalSigner
+	config          Config
+	metrics         *chainMetrics
 	systemChannelID string
 	systemChannel   *chainSupport
 }
@@ -81,13 +174,16 @@ func getConfigTx(reader ledger.Reader) *cb.Envelope {
 	return utils.ExtractEnvelopeOrPanic(configBlock, 0)
 }
 
-// NewManagerImpl produces an instance of a Manager
-func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consenter, signer crypto.LocalSigner) Manager {
+// NewManagerImpl produces an instance of a Manager, whose chains are
+// configured by conf
+func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consenter, signer crypto.LocalSigner, conf Config) Manager {
 	ml := &multiLedger{
 		chains:        make(map[string]*chainSupport),
 		ledgerFactory: ledgerFactory,
 		consenters:    consenters,
 		signer:        signer,
+		config:        conf,
+		metrics:       newChainMetrics(conf.Metrics),
 	}
 
 	existingChains := ledgerFactory.ChainIDs()
@@ -110,7 +206,9 @@ func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consente
 			chain := newChainSupport(createSystemChainFilters(ml, ledgerResources),
 				ledgerResources,
 				consenters,
-				signer)
+				signer,
+				conf,
+				ml.metrics)
 			logger.Infof("Starting with system channel %s and orderer type %s", chainID, chain.SharedConfig().ConsensusType())
 			ml.chains[chainID] = chain
 			ml.systemChannelID = chainID
@@ -119,10 +217,12 @@ func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consente
 			defer chain.start()
 		} else {
 			logger.Debugf("Starting chain: %s", chainID)
-			chain := newChainSupport(createStandardFilters(ledgerResources),
+			chain := newChainSupport(createStandardFilters(ledgerResources, conf),
 				ledgerResources,
 				consenters,
-				signer)
+				signer,
+				conf,
+				ml.metrics)
 			ml.chains[chainID] = chain
 			chain.start()
 		}
@@ -160,24 +260,66 @@ func (ml *multiLedger) newLedgerResources(configTx *cb.Envelope) *ledgerResource
 		logger.Panicf("Error getting ledger for %s", chainID)
 	}
 
+	faults := chaos.For(ml.config.Faults, chainID)
 	return &ledgerResources{
 		configResources: &configResources{Manager: configManager},
-		ledger:          ledger,
+		ledger:          faults.Ledger(ledger),
+		faults:          faults,
 	}
 }
 
+// Halt stops the consenters of all chains and closes their ledgers
+func (ml *multiLedger) Halt() {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
+	for chainID, cs := range ml.chains {
+		if chainID == ml.systemChannelID {
+			continue
+		}
+		logger.Debugf("Halting chain %s", chainID)
+		cs.chain.Halt()
+	}
+	if ml.systemChannel != nil {
+		logger.Debugf("Halting system chain %s", ml.systemChannelID)
+		ml.systemChannel.chain.Halt()
+	}
+
+	ml.ledgerFactory.Close()
+	logger.Infof("Halted %d chains and closed their ledgers", len(ml.chains))
+}
+
+// ChannelIDs returns the IDs of all chains, in sorted order
+func (ml *multiLedger) ChannelIDs() []string {
+	chains := ml.chains
+	chainIDs := make([]string, 0, len(chains))
+	for chainID := range chains {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	return chainIDs
+}
+
 func (ml *multiLedger) newChain(configtx *cb.Envelope) {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
 	ledgerResources := ml.newLedgerResources(configtx)
 	ledgerResources.ledger.Append(ledger.CreateNextBlock(ledgerResources.ledger, []*cb.Envelope{configtx}))
 
+	ml.addChain(newChainSupport(createStandardFilters(ledgerResources, ml.config), ledgerResources, ml.consenters, ml.signer, ml.config, ml.metrics))
+}
+
+// addChain starts the chain and publishes it in a copy of the chains map, the
+// caller must hold the lock
+func (ml *multiLedger) addChain(cs *chainSupport) {
 	// Copy the map to allow concurrent reads from broadcast/deliver while the new chainSupport is
 	newChains := make(map[string]*chainSupport)
 	for key, value := range ml.chains {
 		newChains[key] = value
 	}
 
-	cs := newChainSupport(createStandardFilters(ledgerResources), ledgerResources, ml.consenters, ml.signer)
-	chainID := ledgerResources.ChainID()
+	chainID := cs.ChainID()
 
 	logger.Infof("Created and starting new chain %s", chainID)
 
@@ -187,6 +329,73 @@ func (ml *multiLedger) newChain(configtx *cb.Envelope) {
 	ml.chains = newChains
 }
 
+// JoinChannel creates and starts a chain for a standard channel from its
+// genesis config block
+func (ml *multiLedger) JoinChannel(configBlock *cb.Block) error {
+	if configBlock == nil || configBlock.Header == nil || configBlock.Data == nil {
+		return fmt.Errorf("Config block is malformed")
+	}
+
+	if configBlock.Header.Number != 0 {
+		return fmt.Errorf("A channel may only be joined from its genesis block, not block %d", configBlock.Header.Number)
+	}
+
+	configTx, err := utils.ExtractEnvelope(configBlock, 0)
+	if err != nil {
+		return fmt.Errorf("Config block does not contain a transaction: %s", err)
+	}
+
+	configManager, err := configtx.NewManagerImpl(configTx, configtx.NewInitializer(), nil)
+	if err != nil {
+		return fmt.Errorf("Config block does not contain a valid config transaction: %s", err)
+	}
+
+	chainID := configManager.ChainID()
+
+	if _, ok := configManager.ConsortiumsConfig(); ok {
+		return fmt.Errorf("Channel %s is a system channel and cannot be joined", chainID)
+	}
+
+	ordererConfig, ok := configManager.OrdererConfig()
+	if !ok {
+		return fmt.Errorf("Channel %s has no orderer configuration", chainID)
+	}
+
+	if _, ok := ml.consenters[ordererConfig.ConsensusType()]; !ok {
+		return fmt.Errorf("Channel %s requires unsupported consensus type %s", chainID, ordererConfig.ConsensusType())
+	}
+
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+
+	if _, ok := ml.chains[chainID]; ok {
+		return fmt.Errorf("Channel %s already exists", chainID)
+	}
+
+	rl, err := ml.ledgerFactory.GetOrCreate(chainID)
+	if err != nil {
+		return fmt.Errorf("Error creating ledger for channel %s: %s", chainID, err)
+	}
+
+	if rl.Height() != 0 {
+		return fmt.Errorf("Ledger for channel %s already contains %d blocks", chainID, rl.Height())
+	}
+
+	if err := rl.Append(configBlock); err != nil {
+		return fmt.Errorf("Error appending config block to ledger for channel %s: %s", chainID, err)
+	}
+
+	faults := chaos.For(ml.config.Faults, chainID)
+	ledgerResources := &ledgerResources{
+		configResources: &configResources{Manager: configManager},
+		ledger:          faults.Ledger(rl),
+		faults:          faults,
+	}
+
+	ml.addChain(newChainSupport(createStandardFilters(ledgerResources, ml.config), ledgerResources, ml.consenters, ml.signer, ml.config, ml.metrics))
+	return nil
+}
+
 func (ml *multiLedger) channelsCount() int {
 	return len(ml.chains)
 }
@@ -206,6 +415,9 @@ func (ml *multiLedger) NewChannelConfig(envConfigUpdate *cb.Envelope) (configtxa
 		return nil, fmt.Errorf("Failed initial channel config creation because config update header was missing")
 	}
 	channelHeader, err := utils.UnmarshalChannelHeader(configUpdatePayload.Header.ChannelHeader)
+	if err != nil {
+		return nil, fmt.Errorf("Failing initial channel config creation because of channel header unmarshaling error: %s", err)
+	}
 
 	configUpdate, err := configtx.UnmarshalConfigUpdate(configUpdateEnv.ConfigUpdate)
 	if err != nil {
@@ -239,6 +451,10 @@ func (ml *multiLedger) NewChannelConfig(envConfigUpdate *cb.Envelope) (configtxa
 		return nil, fmt.Errorf("Error reading unmarshaling consortium name: %s", err)
 	}
 
+	if consortium.Name == "" {
+		return nil, fmt.Errorf("Channel creation request does not reference a consortium")
+	}
+
 	applicationGroup := cb.NewConfigGroup()
 	consortiumsConfig, ok := ml.systemChannel.ConsortiumsConfig()
 	if !ok {
@@ -250,6 +466,12 @@ func (ml *multiLedger) NewChannelConfig(envConfigUpdate *cb.Envelope) (configtxa
 		return nil, fmt.Errorf("Unknown consortium name: %s", consortium.Name)
 	}
 
+	// Without a creation policy, the consortium would not constrain who may
+	// create channels on its behalf, so refuse rather than allow anyone
+	if creationPolicy := consortiumConf.ChannelCreationPolicy(); creationPolicy == nil || creationPolicy.Type == int32(cb.Policy_UNKNOWN) {
+		return nil, fmt.Errorf("Consortium %s does not define a channel creation policy", consortium.Name)
+	}
+
 	applicationGroup.Policies[config.ChannelCreationPolicyKey] = &cb.ConfigPolicy{
 		Policy: consortiumConf.ChannelCreationPolicy(),
 	}
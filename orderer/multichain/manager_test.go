@@ -8,6 +8,9 @@ package multichain
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -19,6 +22,9 @@ import (
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
 	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/chaos"
+	"github.com/hyperledger/fabric/orderer/common/recording"
+	"github.com/hyperledger/fabric/orderer/common/scheduler"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -28,6 +34,7 @@ import (
 	mmsp "github.com/hyperledger/fabric/common/mocks/msp"
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var conf, singleMSPConf, noConsortiumConf *genesisconfig.Profile
@@ -136,7 +143,7 @@ func TestNoSystemChain(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto()) }, "Should have panicked when starting without a system chain")
+	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto(), Config{}) }, "Should have panicked when starting without a system chain")
 }
 
 // This test checks to make sure that the orderer refuses to come up if there are multiple system channels
@@ -154,7 +161,7 @@ func TestMultiSystemChannel(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto()) }, "Two system channels should have caused panic")
+	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto(), Config{}) }, "Two system channels should have caused panic")
 }
 
 // This test checks to make sure that the orderer creates different type of filters given different type of channel
@@ -182,7 +189,7 @@ func TestFilterCreation(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
 
 	_, ok := manager.GetChain(provisional.TestChainID)
 	assert.True(t, ok, "Should have found chain: %d", provisional.TestChainID)
@@ -209,7 +216,7 @@ func TestFilterCreation(t *testing.T) {
 			}),
 		}
 
-		assert.True(t, chainSupport.Enqueue(messages[i]), "Should have successfully enqueued message")
+		assert.True(t, chainSupport.Enqueue(makeMessage(messages[i])), "Should have successfully enqueued message")
 	}
 
 	it, _ := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
@@ -232,7 +239,7 @@ func TestManagerImpl(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
 
 	_, ok := manager.GetChain("Fake")
 	assert.False(t, ok, "Should not have found a chain that was not created")
@@ -246,7 +253,7 @@ func TestManagerImpl(t *testing.T) {
 	}
 
 	for _, message := range messages {
-		chainSupport.Enqueue(message)
+		chainSupport.Enqueue(makeMessage(message))
 	}
 
 	it, _ := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
@@ -262,12 +269,104 @@ func TestManagerImpl(t *testing.T) {
 	}
 }
 
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	consenters := map[string]Consenter{conf.Orderer.OrdererType: &mockConsenter{}}
+	lf, rl := NewRAMLedgerAndFactory(10)
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{
+		Recording: recording.Config{Directory: dir, Chains: []string{provisional.TestChainID}},
+	})
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+	count := int(conf.Orderer.BatchSize.MaxMessageCount)
+	for i := 0; i < 2*count+1; i++ {
+		require.True(t, chainSupport.Enqueue(makeMessage(makeNormalTx(provisional.TestChainID, i))))
+	}
+	it, _ := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
+	var ordered []*cb.Block
+	for i := 0; i < 2; i++ {
+		select {
+		case <-it.ReadyChan():
+			block, _ := it.Next()
+			ordered = append(ordered, block)
+		case <-time.After(time.Second):
+			t.Fatalf("Block %d not produced after timeout", i+1)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, provisional.TestChainID+".rec"))
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := recording.Read(f)
+	require.NoError(t, err)
+	require.Len(t, records, 2*count+1)
+
+	replay := func() []*cb.Block {
+		lf, _ := NewRAMLedgerAndFactory(10)
+		chainSupport, _ := NewManagerImpl(lf, consenters, mockCrypto(), Config{}).GetChain(provisional.TestChainID)
+		blocks, err := recording.Replay(records, chainSupport)
+		require.NoError(t, err)
+		return blocks
+	}
+	first, second := replay(), replay()
+	require.Len(t, first, 3, "The last message should be cut once the records run out")
+	require.Len(t, second, 3)
+	for i := range first {
+		assert.Equal(t, first[i].Header.DataHash, second[i].Header.DataHash, "Replays should produce the same blocks")
+	}
+	for i := range ordered {
+		assert.Equal(t, ordered[i].Header.DataHash, first[i].Header.DataHash, "Replays should produce the blocks ordered originally")
+	}
+}
+
+func TestChaos(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	manager := NewManagerImpl(lf, map[string]Consenter{conf.Orderer.OrdererType: &mockConsenter{}}, mockCrypto(), Config{
+		Faults: map[string]chaos.Faults{provisional.TestChainID: {AppendErrorAt: 1, FilterTimeout: time.Millisecond}},
+	})
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+
+	tx := makeNormalTx(provisional.TestChainID, 0)
+	_, err := chainSupport.Filters().Apply(makeMessage(tx))
+	assert.Error(t, err, "The message should be rejected once the filters time out")
+	assert.Panics(t, func() {
+		chainSupport.WriteBlock(chainSupport.CreateNextBlock([]*cb.Envelope{tx}), nil, nil)
+	}, "The append of block 1 should fail")
+}
+
+func TestScheduler(t *testing.T) {
+	s := scheduler.New(scheduler.Config{Slots: 1})
+	lf, rl := NewRAMLedgerAndFactory(10)
+	manager := NewManagerImpl(lf, map[string]Consenter{conf.Orderer.OrdererType: &mockConsenter{}}, mockCrypto(), Config{Scheduler: s})
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+
+	s.Acquire("other", 1)
+	written := make(chan struct{})
+	go func() {
+		chainSupport.WriteBlock(chainSupport.CreateNextBlock([]*cb.Envelope{makeNormalTx(provisional.TestChainID, 0)}), nil, nil)
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("The block should wait for the slot held by another chain")
+	case <-time.After(50 * time.Millisecond):
+	}
+	s.Release()
+	<-written
+	assert.Equal(t, uint64(2), rl.Height())
+
+	chainSupport.WriteBlock(chainSupport.CreateNextBlock([]*cb.Envelope{makeNormalTx(provisional.TestChainID, 1)}), nil, nil)
+	assert.Equal(t, uint64(3), rl.Height(), "The slot should be released once the block is written")
+}
+
 func TestNewChannelConfig(t *testing.T) {
 	lf, _ := NewRAMLedgerAndFactoryWithMSP()
 
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
 
 	t.Run("BadPayload", func(t *testing.T) {
 		_, err := manager.NewChannelConfig(&cb.Envelope{Payload: []byte("bad payload")})
@@ -404,6 +503,31 @@ func TestNewChannelConfig(t *testing.T) {
 			},
 			"^Error reading unmarshaling consortium name:",
 		},
+		{
+			"EmptyConsortiumName",
+			&cb.Payload{
+				Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(utils.MakeChannelHeader(cb.HeaderType_CONFIG_UPDATE, 0, "", epoch))},
+				Data: utils.MarshalOrPanic(&cb.ConfigUpdateEnvelope{
+					ConfigUpdate: utils.MarshalOrPanic(
+						&cb.ConfigUpdate{
+							WriteSet: &cb.ConfigGroup{
+								Groups: map[string]*cb.ConfigGroup{
+									config.ApplicationGroupKey: &cb.ConfigGroup{
+										Version: 1,
+									},
+								},
+								Values: map[string]*cb.ConfigValue{
+									config.ConsortiumKey: &cb.ConfigValue{
+										Value: utils.MarshalOrPanic(&cb.Consortium{}),
+									},
+								},
+							},
+						},
+					),
+				}),
+			},
+			"^Channel creation request does not reference a consortium$",
+		},
 		{
 			"UnknownConsortiumName",
 			&cb.Payload{
@@ -505,6 +629,38 @@ func TestNewChannelConfig(t *testing.T) {
 	// SampleConsortium
 }
 
+func TestNewChannelConfigNoCreationPolicy(t *testing.T) {
+	// Strip the channel creation policy from the sample consortium in the genesis config
+	genesisEnv := utils.ExtractEnvelopeOrPanic(genesisBlock, 0)
+	genesisPayload := utils.ExtractPayloadOrPanic(genesisEnv)
+	configEnv, err := configtx.UnmarshalConfigEnvelope(genesisPayload.Data)
+	assert.NoError(t, err)
+	consortiumGroup := configEnv.Config.ChannelGroup.Groups[config.ConsortiumsGroupKey].Groups[genesisconfig.SampleConsortiumName]
+	delete(consortiumGroup.Values, config.ChannelCreationPolicyKey)
+	genesisPayload.Data = utils.MarshalOrPanic(configEnv)
+	genesisEnv.Payload = utils.MarshalOrPanic(genesisPayload)
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(genesisEnv)}
+	block.Header.DataHash = block.Data.Hash()
+
+	lf := ramledger.New(10)
+	rl, err := lf.GetOrCreate(provisional.TestChainID)
+	assert.NoError(t, err)
+	assert.NoError(t, rl.Append(block))
+
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
+
+	envConfigUpdate, err := configtx.MakeChainCreationTransaction("foo", genesisconfig.SampleConsortiumName, mockSigningIdentity)
+	assert.NoError(t, err, "Constructing chain creation tx")
+
+	_, err = manager.NewChannelConfig(envConfigUpdate)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "does not define a channel creation policy$", err.Error())
+	}
+}
+
 func TestMismatchedChannelIDs(t *testing.T) {
 	innerChannelID := "foo"
 	outerChannelID := "bar"
@@ -518,7 +674,7 @@ func TestMismatchedChannelIDs(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
 
 	_, err = manager.NewChannelConfig(createTx)
 	assert.Error(t, err, "Mismatched channel IDs")
@@ -536,7 +692,7 @@ func TestNewChain(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
 
 	envConfigUpdate, err := configtx.MakeChainCreationTransaction(newChainID, genesisconfig.SampleConsortiumName, mockSigningIdentity)
 	assert.NoError(t, err, "Constructing chain creation tx")
@@ -556,7 +712,7 @@ func TestNewChain(t *testing.T) {
 	chainSupport, ok := manager.GetChain(manager.SystemChannelID())
 	assert.True(t, ok, "Could not find system channel")
 
-	chainSupport.Enqueue(wrapped)
+	chainSupport.Enqueue(makeMessage(wrapped))
 
 	it, _ := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
 	select {
@@ -586,7 +742,7 @@ func TestNewChain(t *testing.T) {
 	}
 
 	for _, message := range messages {
-		chainSupport.Enqueue(message)
+		chainSupport.Enqueue(makeMessage(message))
 	}
 
 	it, _ = chainSupport.Reader().Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 0}}})
@@ -628,7 +784,7 @@ func TestNewChain(t *testing.T) {
 func testRestartedChainSupport(t *testing.T, cs ChainSupport, consenters map[string]Consenter, expectedLastConfigSeq uint64) {
 	ccs, ok := cs.(*chainSupport)
 	assert.True(t, ok, "Casting error")
-	rcs := newChainSupport(ccs.filters, ccs.ledgerResources, consenters, mockCrypto())
+	rcs := newChainSupport(ccs.filters, ccs.ledgerResources, consenters, mockCrypto(), Config{}, nil)
 	assert.Equal(t, expectedLastConfigSeq, rcs.lastConfigSeq, "On restart, incorrect lastConfigSeq")
 }
 
@@ -641,3 +797,128 @@ func testLastConfigBlockNumber(t *testing.T, block *cb.Block, expectedBlockNumbe
 	assert.NoError(t, err, "LAST_CONFIG metadata item should carry last config value")
 	assert.Equal(t, expectedBlockNumber, lastConfig.Index, "LAST_CONFIG value should point to last config block")
 }
+
+func TestChannelIDs(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
+
+	assert.Equal(t, []string{provisional.TestChainID}, manager.ChannelIDs())
+}
+
+func TestHalt(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
+	assert.NoError(t, manager.JoinChannel(noConsortiumGenesisBlock))
+
+	var chains []*mockChain
+	for _, chainID := range manager.ChannelIDs() {
+		cs, _ := manager.GetChain(chainID)
+		chains = append(chains, cs.(*chainSupport).chain.(*mockChain))
+	}
+
+	manager.Halt()
+	for _, chain := range chains {
+		select {
+		case <-chain.done:
+		case <-time.After(time.Second):
+			t.Fatalf("Chain should have been halted")
+		}
+	}
+}
+
+func TestIdentityValidator(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
+
+	cs, ok := manager.GetChain(provisional.TestChainID)
+	assert.True(t, ok)
+	validator := cs.IdentityValidator()
+	assert.NotNil(t, validator)
+	assert.True(t, validator == cs.IdentityValidator(), "The validator and its cache should be shared")
+}
+
+func TestJoinChannel(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
+
+	t.Run("Malformed", func(t *testing.T) {
+		assert.EqualError(t, manager.JoinChannel(&cb.Block{}), "Config block is malformed")
+	})
+
+	t.Run("NotGenesis", func(t *testing.T) {
+		block := proto.Clone(noConsortiumGenesisBlock).(*cb.Block)
+		block.Header.Number = 3
+		assert.EqualError(t, manager.JoinChannel(block), "A channel may only be joined from its genesis block, not block 3")
+	})
+
+	t.Run("NoTransaction", func(t *testing.T) {
+		block := cb.NewBlock(0, nil)
+		err := manager.JoinChannel(block)
+		if assert.Error(t, err) {
+			assert.Regexp(t, "^Config block does not contain a transaction", err.Error())
+		}
+	})
+
+	t.Run("SystemChannel", func(t *testing.T) {
+		assert.EqualError(t, manager.JoinChannel(genesisBlock),
+			fmt.Sprintf("Channel %s is a system channel and cannot be joined", provisional.TestChainID))
+	})
+
+	t.Run("UnsupportedConsensus", func(t *testing.T) {
+		otherLf, _ := NewRAMLedgerAndFactory(10)
+		noSoloManager := NewManagerImpl(otherLf, consenters, mockCrypto(), Config{}).(*multiLedger)
+		noSoloManager.consenters = map[string]Consenter{}
+		err := noSoloManager.JoinChannel(noConsortiumGenesisBlock)
+		if assert.Error(t, err) {
+			assert.Regexp(t, "requires unsupported consensus type", err.Error())
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		assert.NoError(t, manager.JoinChannel(noConsortiumGenesisBlock))
+		assert.Equal(t, []string{NoConsortiumChain, provisional.TestChainID}, manager.ChannelIDs())
+
+		cs, ok := manager.GetChain(NoConsortiumChain)
+		assert.True(t, ok, "Joined chain should be available")
+		assert.Equal(t, uint64(1), cs.Reader().Height())
+
+		assert.EqualError(t, manager.JoinChannel(noConsortiumGenesisBlock),
+			fmt.Sprintf("Channel %s already exists", NoConsortiumChain))
+	})
+}
+
+// FuzzNewChannelConfig processes channel creation requests carrying arbitrary
+// config updates, as Broadcast does for CONFIG_UPDATE messages for channels
+// which do not exist
+func FuzzNewChannelConfig(f *testing.F) {
+	lf, _ := NewRAMLedgerAndFactoryWithMSP()
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), Config{})
+
+	envConfigUpdate, err := configtx.MakeChainCreationTransaction("foo", genesisconfig.SampleConsortiumName, mockSigningIdentity)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(utils.ExtractPayloadOrPanic(envConfigUpdate).Data)
+	f.Add(utils.MarshalOrPanic(&cb.ConfigUpdateEnvelope{ConfigUpdate: utils.MarshalOrPanic(&cb.ConfigUpdate{})}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		env := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(utils.MakeChannelHeader(cb.HeaderType_CONFIG_UPDATE, 0, "foo", epoch))},
+			Data:   data,
+		})}
+		ctxm, err := manager.NewChannelConfig(env)
+		if err != nil {
+			return
+		}
+		ctxm.ProposeConfigUpdate(env)
+	})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package multichain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// chainMetrics are the metrics updated by the chains of a manager
+type chainMetrics struct {
+	commitDuration     metrics.Histogram
+	appendDuration     metrics.Histogram
+	ledgerHeight       metrics.Gauge
+	blocksProduced     metrics.Counter
+	lastBlockTimestamp metrics.Gauge
+	sinceLastBlock     metrics.Gauge
+
+	// lastBlockTimes holds the time each chain last wrote a block, from
+	// which sinceLastBlock is computed whenever metrics are gathered
+	lastBlockTimes sync.Map
+}
+
+// gatherer is implemented by the providers which run hooks before their
+// metrics are gathered, such as metrics.Registry
+type gatherer interface {
+	OnGather(fn func())
+}
+
+// newChainMetrics creates the metrics of the chains with the provider, or in
+// the default registry if it is nil. The time since the last block of each
+// chain is only kept up to date in providers which are gatherers.
+func newChainMetrics(provider metrics.Provider) *chainMetrics {
+	if provider == nil {
+		provider = metrics.DefaultRegistry()
+	}
+	cm := &chainMetrics{
+		commitDuration: provider.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "consensus",
+			Name:       "commit_duration_seconds",
+			Help:       "The time taken to commit, sign and append each block written by the consenter.",
+			LabelNames: []string{"channel"},
+		}}),
+		appendDuration: provider.NewHistogram(metrics.HistogramOpts{Opts: metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "ledger",
+			Name:       "append_duration_seconds",
+			Help:       "The time taken to append each block to the ledger.",
+			LabelNames: []string{"channel"},
+		}}),
+		ledgerHeight: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "ledger",
+			Name:       "height",
+			Help:       "The number of blocks in the ledger of the channel.",
+			LabelNames: []string{"channel"},
+		}),
+		blocksProduced: provider.NewCounter(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "consensus",
+			Name:       "blocks_produced_total",
+			Help:       "The number of blocks written by the consenter.",
+			LabelNames: []string{"channel"},
+		}),
+		lastBlockTimestamp: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "consensus",
+			Name:       "last_block_timestamp_seconds",
+			Help:       "The Unix time at which the consenter last wrote a block.",
+			LabelNames: []string{"channel"},
+		}),
+		sinceLastBlock: provider.NewGauge(metrics.Opts{
+			Namespace:  "orderer",
+			Subsystem:  "consensus",
+			Name:       "seconds_since_last_block",
+			Help:       "The time since the consenter last wrote a block, since the orderer started if it has not.",
+			LabelNames: []string{"channel"},
+		}),
+	}
+	if g, ok := provider.(gatherer); ok {
+		g.OnGather(func() {
+			cm.lastBlockTimes.Range(func(chainID, written interface{}) bool {
+				cm.sinceLastBlock.With(chainID.(string)).Set(time.Since(written.(time.Time)).Seconds())
+				return true
+			})
+		})
+	}
+	return cm
+}
+
+// started records the height of the ledger of the chain once its support is
+// created, and counts the time since its last block from then on
+func (cm *chainMetrics) started(chainID string, height uint64) {
+	if cm == nil {
+		return
+	}
+	cm.ledgerHeight.With(chainID).Set(float64(height))
+	cm.lastBlockTimes.LoadOrStore(chainID, time.Now())
+}
+
+// written records the block written by the chain, whose commit started at
+// start and whose append took from appendStart until appended
+func (cm *chainMetrics) written(chainID string, block *cb.Block, start, appendStart, appended time.Time) {
+	if cm == nil {
+		return
+	}
+	cm.appendDuration.With(chainID).Observe(appended.Sub(appendStart).Seconds())
+	cm.commitDuration.With(chainID).Observe(appended.Sub(start).Seconds())
+	cm.blocksProduced.With(chainID).Add(1)
+	cm.lastBlockTimestamp.With(chainID).Set(float64(appended.UnixNano()) / 1e9)
+	cm.lastBlockTimes.Store(chainID, appended)
+	cm.ledgerHeight.With(chainID).Set(float64(block.GetHeader().Number + 1))
+}
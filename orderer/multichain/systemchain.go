@@ -65,25 +65,14 @@ func newSystemChainFilter(ls limitedSupport, cc chainCreator) filter.Rule {
 	}
 }
 
-func (scf *systemChainFilter) Apply(env *cb.Envelope) (filter.Action, filter.Committer) {
-	msgData := &cb.Payload{}
-
-	err := proto.Unmarshal(env.Payload, msgData)
-	if err != nil {
-		return filter.Forward, nil
-	}
-
-	if msgData.Header == nil {
-		return filter.Forward, nil
-	}
-
-	chdr, err := utils.UnmarshalChannelHeader(msgData.Header.ChannelHeader)
-	if err != nil {
-		return filter.Forward, nil
-	}
+func (scf *systemChainFilter) Apply(msg *filter.Message) (filter.Action, filter.Committer) {
+	action, committer, _ := scf.Explain(msg)
+	return action, committer
+}
 
-	if chdr.Type != int32(cb.HeaderType_ORDERER_TRANSACTION) {
-		return filter.Forward, nil
+func (scf *systemChainFilter) Explain(msg *filter.Message) (filter.Action, filter.Committer, string) {
+	if msg.Type() != cb.HeaderType_ORDERER_TRANSACTION {
+		return filter.Forward, nil, ""
 	}
 
 	maxChannels := scf.support.SharedConfig().MaxChannelsCount()
@@ -91,26 +80,26 @@ func (scf *systemChainFilter) Apply(env *cb.Envelope) (filter.Action, filter.Com
 		// We check for strictly greater than to accommodate the system channel
 		if uint64(scf.cc.channelsCount()) > maxChannels {
 			logger.Warningf("Rejecting channel creation because the orderer has reached the maximum number of channels, %d", maxChannels)
-			return filter.Reject, nil
+			return filter.Reject, nil, fmt.Sprintf("the orderer has reached the maximum number of channels, %d", maxChannels)
 		}
 	}
 
 	configTx := &cb.Envelope{}
-	err = proto.Unmarshal(msgData.Data, configTx)
+	err := proto.Unmarshal(msg.Payload.Data, configTx)
 	if err != nil {
-		return filter.Reject, nil
+		return filter.Reject, nil, fmt.Sprintf("malformed channel creation transaction: %s", err)
 	}
 
 	err = scf.authorizeAndInspect(configTx)
 	if err != nil {
 		logger.Debugf("Rejecting channel creation because: %s", err)
-		return filter.Reject, nil
+		return filter.Reject, nil, err.Error()
 	}
 
 	return filter.Accept, &systemChainCommitter{
 		filter:   scf,
 		configTx: configTx,
-	}
+	}, ""
 }
 
 func (scf *systemChainFilter) authorize(configEnvelope *cb.ConfigEnvelope) (configtxapi.Manager, error) {
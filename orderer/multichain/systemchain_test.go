@@ -101,7 +101,7 @@ func TestGoodProposal(t *testing.T) {
 	wrapped := wrapConfigTx(ingressTx)
 
 	sysFilter := newSystemChainFilter(mcc.ms, mcc)
-	action, committer := sysFilter.Apply(wrapped)
+	action, committer := sysFilter.Apply(makeMessage(wrapped))
 
 	assert.EqualValues(t, action, filter.Accept, "Did not accept valid transaction")
 	assert.True(t, committer.Isolated(), "Channel creation belong in its own block")
@@ -133,7 +133,7 @@ func TestProposalRejectedByConfig(t *testing.T) {
 	wrapped := wrapConfigTx(ingressTx)
 
 	sysFilter := newSystemChainFilter(mcc.ms, mcc)
-	action, _ := sysFilter.Apply(wrapped)
+	action, _ := sysFilter.Apply(makeMessage(wrapped))
 
 	assert.EqualValues(t, action, filter.Reject, "Did not accept valid transaction")
 	assert.Len(t, mcc.newChains, 0, "Proposal should not have created a new chain")
@@ -161,7 +161,7 @@ func TestNumChainsExceeded(t *testing.T) {
 	wrapped := wrapConfigTx(ingressTx)
 
 	sysFilter := newSystemChainFilter(mcc.ms, mcc)
-	action, _ := sysFilter.Apply(wrapped)
+	action, _ := sysFilter.Apply(makeMessage(wrapped))
 
 	assert.EqualValues(t, filter.Reject, action, "Transaction had created too many channels")
 }
@@ -170,12 +170,6 @@ func TestBadProposal(t *testing.T) {
 	mcc := newMockChainCreator()
 	sysFilter := newSystemChainFilter(mcc.ms, mcc)
 	// logging.SetLevel(logging.DEBUG, "orderer/multichain")
-	t.Run("BadPayload", func(t *testing.T) {
-		action, committer := sysFilter.Apply(&cb.Envelope{Payload: []byte("bad payload")})
-		assert.EqualValues(t, action, filter.Forward, "Should of skipped invalid tx")
-		assert.Nil(t, committer)
-	})
-
 	// set logger to logger with a backend that writes to a byte buffer
 	var buffer bytes.Buffer
 	logger.SetBackend(logging.AddModuleLevel(logging.NewLogBackend(&buffer, "", 0)))
@@ -190,22 +184,6 @@ func TestBadProposal(t *testing.T) {
 		action  filter.Action
 		regexp  string
 	}{
-		{
-			"MissingPayloadHeader",
-			&cb.Payload{},
-			filter.Forward,
-			"",
-		},
-		{
-			"BadChannelHeader",
-			&cb.Payload{
-				Header: &cb.Header{
-					ChannelHeader: []byte("bad channel header"),
-				},
-			},
-			filter.Forward,
-			"",
-		},
 		{
 			"BadConfigTx",
 			&cb.Payload{
@@ -380,7 +358,7 @@ func TestBadProposal(t *testing.T) {
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			buffer.Reset()
-			action, committer := sysFilter.Apply(&cb.Envelope{Payload: utils.MarshalOrPanic(tc.payload)})
+			action, committer := sysFilter.Apply(makeMessage(&cb.Envelope{Payload: utils.MarshalOrPanic(tc.payload)}))
 			assert.EqualValues(t, tc.action, action, "Expected tx to be %sed, but instead the tx will be %sed.", filterActionToString(tc.action), filterActionToString(action))
 			assert.Nil(t, committer)
 			assert.Regexp(t, tc.regexp, buffer.String())
@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	"github.com/hyperledger/fabric/orderer/common/filter"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -32,7 +33,7 @@ type mockConsenter struct {
 
 func (mc *mockConsenter) HandleChain(support ConsenterSupport, metadata *cb.Metadata) (Chain, error) {
 	return &mockChain{
-		queue:    make(chan *cb.Envelope),
+		queue:    make(chan *filter.Message),
 		cutter:   support.BlockCutter(),
 		support:  support,
 		metadata: metadata,
@@ -41,7 +42,7 @@ func (mc *mockConsenter) HandleChain(support ConsenterSupport, metadata *cb.Meta
 }
 
 type mockChain struct {
-	queue    chan *cb.Envelope
+	queue    chan *filter.Message
 	cutter   blockcutter.Receiver
 	support  ConsenterSupport
 	metadata *cb.Metadata
@@ -52,8 +53,8 @@ func (mch *mockChain) Errored() <-chan struct{} {
 	return nil
 }
 
-func (mch *mockChain) Enqueue(env *cb.Envelope) bool {
-	mch.queue <- env
+func (mch *mockChain) Enqueue(msg *filter.Message) bool {
+	mch.queue <- msg
 	return true
 }
 
@@ -130,3 +131,11 @@ func makeNormalTx(chainID string, i int) *cb.Envelope {
 		Payload: utils.MarshalOrPanic(payload),
 	}
 }
+
+func makeMessage(env *cb.Envelope) *filter.Message {
+	msg, err := filter.NewMessage(env)
+	if err != nil {
+		panic(err)
+	}
+	return msg
+}
@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	bccsputils "github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+)
+
+// LoadSigner returns a signer for the identity of the MSP directory dir,
+// signing with the key of its keystore which matches its signing
+// certificate. Unlike the local MSP, any number of identities may be loaded,
+// so that load may be generated on behalf of several clients.
+func LoadSigner(dir, mspID string) (crypto.LocalSigner, error) {
+	certPEM, cert, err := readSignCert(filepath.Join(dir, "signcerts"))
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T in %s", cert.PublicKey, dir)
+	}
+	key, err := readKey(filepath.Join(dir, "keystore"), pub)
+	if err != nil {
+		return nil, err
+	}
+	creator, err := proto.Marshal(&mspproto.SerializedIdentity{Mspid: mspID, IdBytes: certPEM})
+	if err != nil {
+		return nil, err
+	}
+	return &fileSigner{creator: creator, key: key}, nil
+}
+
+// readSignCert returns the first certificate of dir, PEM encoded and parsed
+func readSignCert(dir string) ([]byte, *x509.Certificate, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read signing certificates: %s", err)
+	}
+	for _, file := range files {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+		block, _ := pem.Decode(raw)
+		if block == nil || block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse %s: %s", file.Name(), err)
+		}
+		return pem.EncodeToMemory(block), cert, nil
+	}
+	return nil, nil, fmt.Errorf("no signing certificate in %s", dir)
+}
+
+// readKey returns the private key of dir matching pub
+func readKey(dir string, pub *ecdsa.PublicKey) (*ecdsa.PrivateKey, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read keystore: %s", err)
+	}
+	for _, file := range files {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		key, err := bccsputils.PEMtoPrivateKey(raw, nil)
+		if err != nil {
+			continue
+		}
+		if ecKey, ok := key.(*ecdsa.PrivateKey); ok && ecKey.X.Cmp(pub.X) == 0 && ecKey.Y.Cmp(pub.Y) == 0 {
+			return ecKey, nil
+		}
+	}
+	return nil, fmt.Errorf("no private key in %s matches the signing certificate", dir)
+}
+
+// fileSigner signs as the software BCCSP does, with low-S ECDSA signatures
+// over the SHA-256 digest of the message
+type fileSigner struct {
+	creator []byte
+	key     *ecdsa.PrivateKey
+}
+
+func (s *fileSigner) NewSignatureHeader() (*cb.SignatureHeader, error) {
+	nonce, err := crypto.GetRandomNonce()
+	if err != nil {
+		return nil, fmt.Errorf("could not create nonce: %s", err)
+	}
+	return &cb.SignatureHeader{Creator: s.creator, Nonce: nonce}, nil
+}
+
+func (s *fileSigner) Sign(message []byte) ([]byte, error) {
+	r, sigS, err := ecdsa.Sign(rand.Reader, s.key, util.ComputeSHA256(message))
+	if err != nil {
+		return nil, err
+	}
+	if sigS, _, err = sw.ToLowS(&s.key.PublicKey, sigS); err != nil {
+		return nil, err
+	}
+	return sw.MarshalECDSASignature(r, sigS)
+}
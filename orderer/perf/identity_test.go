@@ -0,0 +1,40 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/config"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSigner(t *testing.T) {
+	require.NoError(t, mspmgmt.LoadDevMsp())
+	dir, err := config.GetDevMspDir()
+	require.NoError(t, err)
+
+	signer, err := LoadSigner(dir, "DEFAULT")
+	require.NoError(t, err)
+	shdr, err := signer.NewSignatureHeader()
+	require.NoError(t, err)
+	assert.NotEmpty(t, shdr.Nonce)
+
+	message := []byte("message")
+	signature, err := signer.Sign(message)
+	require.NoError(t, err)
+
+	identity, err := mspmgmt.GetLocalMSP().DeserializeIdentity(shdr.Creator)
+	require.NoError(t, err)
+	assert.NoError(t, identity.Verify(message, signature))
+	assert.Error(t, identity.Verify([]byte("other"), signature))
+
+	_, err = LoadSigner(t.TempDir(), "DEFAULT")
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"fmt"
+
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/common/crypto"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	config "github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	"github.com/hyperledger/fabric/orderer/server"
+	"github.com/hyperledger/fabric/orderer/solo"
+)
+
+// SystemChannelID is the ID of the system channel of the in-process orderer
+const SystemChannelID = provisional.TestChainID
+
+// historySize is the number of blocks kept by the ram ledger of each channel
+// of the in-process orderer
+const historySize = 100
+
+// InProcess is a solo orderer backed by ram ledgers, serving the atomic
+// broadcast service on a loopback port, so that the performance of the
+// ordering path may be measured without a deployment
+type InProcess struct {
+	// Address is the host and port the orderer is listening on
+	Address string
+
+	orderer *server.Orderer
+}
+
+// StartInProcess starts an in-process orderer with the sample insecure solo
+// system channel and a channel, without consortiums, for each of channels.
+// The orderer signs blocks with signer.
+func StartInProcess(channels []string, signer crypto.LocalSigner) (*InProcess, error) {
+	lf := ramledger.New(historySize)
+
+	system, err := lf.GetOrCreate(SystemChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := system.Append(provisional.New(genesisconfig.Load(genesisconfig.SampleInsecureProfile)).GenesisBlock()); err != nil {
+		return nil, fmt.Errorf("could not create system channel: %s", err)
+	}
+	generator := provisional.New(genesisconfig.Load("SampleNoConsortium"))
+	for _, chainID := range channels {
+		if chainID == SystemChannelID {
+			continue
+		}
+		rl, err := lf.GetOrCreate(chainID)
+		if err != nil {
+			return nil, err
+		}
+		if err := rl.Append(generator.GenesisBlockForChannel(chainID)); err != nil {
+			return nil, fmt.Errorf("could not create channel %s: %s", chainID, err)
+		}
+	}
+
+	// Without a drain timeout, the connections of the clients are closed as
+	// soon as the orderer is stopped
+	orderer, err := server.New(server.Config{
+		TopLevel:      &config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}},
+		LedgerFactory: lf,
+		Consenters:    map[string]multichain.Consenter{"solo": solo.New()},
+		Signer:        signer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	go orderer.Start()
+
+	return &InProcess{
+		Address: orderer.Address(),
+		orderer: orderer,
+	}, nil
+}
+
+// Stop closes the connections of the clients and halts the chains
+func (ip *InProcess) Stop() {
+	ip.orderer.Stop()
+}
@@ -0,0 +1,166 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// loadgen sends messages to an orderer following a workload profile, and
+// writes a report of the throughput and latencies achieved over time as
+// text, CSV or JSON. Without -server it starts an in-process solo orderer,
+// signing blocks with the local MSP of the orderer config.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/common/localmsp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/perf"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"google.golang.org/grpc"
+)
+
+// identities are the MSP directory and ID pairs given by -identity
+type identities []string
+
+func (i *identities) String() string {
+	return strings.Join(*i, ",")
+}
+
+func (i *identities) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("expected an MSP directory and ID separated by ':'")
+	}
+	*i = append(*i, value)
+	return nil
+}
+
+func main() {
+	var serverAddr, channels, profile, sizes, format, output string
+	var rate, rampTo, burstRate float64
+	var rampOver, burstEvery, burstLength, duration, commitTimeout, interval time.Duration
+	var streams int
+	var signers identities
+
+	flag.StringVar(&serverAddr, "server", "", "The RPC server to connect to, an in-process orderer is started if unset.")
+	flag.StringVar(&channels, "channels", perf.SystemChannelID, "The comma separated channels to broadcast to in turn.")
+	flag.StringVar(&profile, "profile", "constant", "The rate profile, one of constant, ramp or burst.")
+	flag.Float64Var(&rate, "rate", 100, "The rate in messages per second, the initial rate of a ramp and the base rate of bursts.")
+	flag.Float64Var(&rampTo, "rampTo", 1000, "The rate a ramp reaches.")
+	flag.DurationVar(&rampOver, "rampOver", time.Minute, "How long a ramp takes to reach its rate.")
+	flag.Float64Var(&burstRate, "burstRate", 1000, "The rate during bursts.")
+	flag.DurationVar(&burstEvery, "burstEvery", 10*time.Second, "The period of bursts.")
+	flag.DurationVar(&burstLength, "burstLength", time.Second, "How long each burst lasts.")
+	flag.DurationVar(&duration, "duration", time.Minute, "How long to send messages for.")
+	flag.StringVar(&sizes, "sizes", "1024", "The size distribution of the data of messages, a size, uniform:MIN-MAX or normal:MEAN,STDDEV.")
+	flag.IntVar(&streams, "streams", 10, "The number of broadcast streams per channel.")
+	flag.Var(&signers, "identity", "An MSP directory and ID, as DIR:ID, to sign messages as, repeatable. Messages are unsigned if unset.")
+	flag.DurationVar(&commitTimeout, "commitTimeout", 30*time.Second, "How long to wait for the messages to be delivered in blocks, commit latency is not measured if zero.")
+	flag.DurationVar(&interval, "interval", time.Second, "The period of the intervals of the report.")
+	flag.StringVar(&format, "format", "text", "The report format, one of text, csv or json.")
+	flag.StringVar(&output, "output", "", "The file to write the report to, standard output if unset.")
+	flag.Parse()
+
+	w, err := workload(profile, rate, rampTo, rampOver, burstRate, burstEvery, burstLength, sizes, signers)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	w.Channels = strings.Split(channels, ",")
+	w.Duration = duration
+	w.Streams = streams
+	w.CommitTimeout = commitTimeout
+	w.Interval = interval
+
+	report, err := run(serverAddr, w)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := write(report, format, output); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func workload(profile string, rate, rampTo float64, rampOver time.Duration, burstRate float64, burstEvery, burstLength time.Duration, sizes string, signers identities) (perf.Workload, error) {
+	w := perf.Workload{}
+	switch profile {
+	case "constant":
+		w.Rate = perf.ConstantRate(rate)
+	case "ramp":
+		w.Rate = perf.RampRate{From: rate, To: rampTo, Over: rampOver}
+	case "burst":
+		w.Rate = perf.BurstRate{Base: rate, Peak: burstRate, Every: burstEvery, Length: burstLength}
+	default:
+		return w, fmt.Errorf("Unknown profile %s", profile)
+	}
+
+	var err error
+	if w.Sizes, err = perf.ParseSizes(sizes); err != nil {
+		return w, err
+	}
+
+	for _, identity := range signers {
+		i := strings.LastIndex(identity, ":")
+		signer, err := perf.LoadSigner(identity[:i], identity[i+1:])
+		if err != nil {
+			return w, fmt.Errorf("Error loading identity %s: %s", identity, err)
+		}
+		w.Signers = append(w.Signers, signer)
+	}
+	return w, nil
+}
+
+func run(serverAddr string, w perf.Workload) (*perf.Report, error) {
+	if serverAddr == "" {
+		conf := config.Load()
+		if err := mspmgmt.LoadLocalMsp(conf.General.LocalMSPDir, conf.General.BCCSP, conf.General.LocalMSPID); err != nil {
+			return nil, fmt.Errorf("Failed to initialize local MSP: %s", err)
+		}
+		orderer, err := perf.StartInProcess(w.Channels, localmsp.NewSigner())
+		if err != nil {
+			return nil, fmt.Errorf("Error starting in-process orderer: %s", err)
+		}
+		defer orderer.Stop()
+		serverAddr = orderer.Address
+	}
+
+	conn, err := grpc.Dial(serverAddr, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting: %s", err)
+	}
+	defer conn.Close()
+
+	return perf.Generate(ab.NewAtomicBroadcastClient(conn), w)
+}
+
+func write(report *perf.Report, format, output string) error {
+	var out io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("Error creating report: %s", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch format {
+	case "text":
+		_, err := fmt.Fprintln(out, report)
+		return err
+	case "csv":
+		return report.WriteCSV(out)
+	case "json":
+		return report.WriteJSON(out)
+	default:
+		return fmt.Errorf("Unknown format %s", format)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// ordererperf drives synthetic load against an orderer and prints the
+// throughput and latency percentiles achieved. Without -server it starts an
+// in-process solo orderer, signing with the local MSP of the orderer config.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/common/localmsp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/perf"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	var serverAddr, channels string
+	var concurrency, messages, minSize, maxSize int
+	var commitTimeout time.Duration
+
+	flag.StringVar(&serverAddr, "server", "", "The RPC server to connect to, an in-process orderer is started if unset.")
+	flag.StringVar(&channels, "channels", perf.SystemChannelID, "The comma separated channels to broadcast to.")
+	flag.IntVar(&concurrency, "concurrency", 1, "The number of broadcast streams per channel.")
+	flag.IntVar(&messages, "messages", 1000, "The number of messages to send on each stream.")
+	flag.IntVar(&minSize, "minSize", 1024, "The minimum size in bytes of the data of each message.")
+	flag.IntVar(&maxSize, "maxSize", 1024, "The maximum size in bytes of the data of each message.")
+	flag.DurationVar(&commitTimeout, "commitTimeout", 0, "How long to wait for the messages to be delivered in blocks, commit latency is not measured if zero.")
+	flag.Parse()
+
+	load := perf.Config{
+		Channels:      strings.Split(channels, ","),
+		Concurrency:   concurrency,
+		Messages:      messages,
+		MinSize:       minSize,
+		MaxSize:       maxSize,
+		CommitTimeout: commitTimeout,
+	}
+
+	result, err := run(serverAddr, load)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println(result)
+}
+
+func run(serverAddr string, load perf.Config) (*perf.Result, error) {
+	if serverAddr == "" {
+		conf := config.Load()
+		if err := mspmgmt.LoadLocalMsp(conf.General.LocalMSPDir, conf.General.BCCSP, conf.General.LocalMSPID); err != nil {
+			return nil, fmt.Errorf("Failed to initialize local MSP: %s", err)
+		}
+		orderer, err := perf.StartInProcess(load.Channels, localmsp.NewSigner())
+		if err != nil {
+			return nil, fmt.Errorf("Error starting in-process orderer: %s", err)
+		}
+		defer orderer.Stop()
+		serverAddr = orderer.Address
+	}
+
+	conn, err := grpc.Dial(serverAddr, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting: %s", err)
+	}
+	defer conn.Close()
+
+	return perf.Run(ab.NewAtomicBroadcastClient(conn), load)
+}
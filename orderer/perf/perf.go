@@ -0,0 +1,427 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package perf drives synthetic load against an orderer and measures the
+// throughput and latency it achieves, so that performance may be compared
+// between commits.
+package perf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+var logger = logging.MustGetLogger("orderer/perf")
+
+// Config describes the load generated by Run
+type Config struct {
+	// Channels are the channels broadcast to
+	Channels []string
+	// Concurrency is the number of broadcast streams per channel
+	Concurrency int
+	// Messages is the number of messages sent on each stream
+	Messages int
+	// MinSize and MaxSize bound the size of the data of each message, which
+	// is uniformly distributed between them
+	MinSize int
+	MaxSize int
+	// CommitTimeout, if positive, is how long to wait for every message to
+	// be delivered in a block, measuring the commit latency. Only the
+	// acknowledgment latency is measured otherwise.
+	CommitTimeout time.Duration
+}
+
+// Latencies summarizes a set of latencies
+type Latencies struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func summarize(samples []time.Duration) Latencies {
+	if len(samples) == 0 {
+		return Latencies{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	percentile := func(p float64) time.Duration {
+		return samples[int(math.Ceil(p*float64(len(samples))))-1]
+	}
+	return Latencies{
+		Count: len(samples),
+		P50:   percentile(.5),
+		P90:   percentile(.9),
+		P99:   percentile(.99),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+func (l Latencies) String() string {
+	return fmt.Sprintf("n=%d p50=%s p90=%s p99=%s max=%s", l.Count, l.P50, l.P90, l.P99, l.Max)
+}
+
+// Result is the outcome of a Run
+type Result struct {
+	// Sent is the number of messages sent, Accepted the number acknowledged
+	// with SUCCESS and Bytes the total size of the accepted messages
+	Sent     int
+	Accepted int
+	Bytes    int
+	// Duration is the time from the first message sent to the last
+	// acknowledged
+	Duration time.Duration
+	// Ack is the latency from sending each message to its acknowledgment
+	Ack Latencies
+	// Commit is the latency from sending each message to its delivery in a
+	// block, it is empty unless Config.CommitTimeout is set
+	Commit Latencies
+}
+
+// Throughput returns the accepted messages per second
+func (r *Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Accepted) / r.Duration.Seconds()
+}
+
+func (r *Result) String() string {
+	lines := []string{
+		fmt.Sprintf("sent=%d accepted=%d duration=%s", r.Sent, r.Accepted, r.Duration),
+		fmt.Sprintf("throughput=%.1f msg/s %.1f KiB/s", r.Throughput(), float64(r.Bytes)/1024/r.Duration.Seconds()),
+		fmt.Sprintf("ack latency: %s", r.Ack),
+	}
+	if r.Commit.Count > 0 {
+		lines = append(lines, fmt.Sprintf("commit latency: %s", r.Commit))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pending records the time each message was sent, by the hash of the
+// marshaled envelope, until it is found in a delivered block
+type pending struct {
+	lock    sync.Mutex
+	sent    map[string]time.Time
+	commits []commit
+}
+
+// commit is the delivery of a message sent at sent after latency
+type commit struct {
+	sent    time.Time
+	latency time.Duration
+}
+
+func newPending() *pending {
+	return &pending{sent: make(map[string]time.Time)}
+}
+
+func (p *pending) add(key string, sent time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.sent[key] = sent
+}
+
+func (p *pending) committed(key string, at time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	sent, ok := p.sent[key]
+	if !ok {
+		return
+	}
+	delete(p.sent, key)
+	p.commits = append(p.commits, commit{sent: sent, latency: at.Sub(sent)})
+}
+
+func (p *pending) forget(key string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.sent, key)
+}
+
+func (p *pending) remaining() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.sent)
+}
+
+// wait waits until every message was delivered or the timeout expires
+func (p *pending) wait(timeout time.Duration) {
+	deadline := time.After(timeout)
+	for {
+		remaining := p.remaining()
+		if remaining == 0 {
+			return
+		}
+		select {
+		case <-deadline:
+			logger.Warningf("%d messages were not delivered within %s", remaining, timeout)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (p *pending) latencies() []time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	latencies := make([]time.Duration, len(p.commits))
+	for i, c := range p.commits {
+		latencies[i] = c.latency
+	}
+	return latencies
+}
+
+func (p *pending) recorded() []commit {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]commit(nil), p.commits...)
+}
+
+// startDelivery records the commits of tracked messages, delivered on the
+// channels, until ctx is done, which the returned WaitGroup waits for
+func startDelivery(ctx context.Context, client ab.AtomicBroadcastClient, channels []string, tracked *pending) (*sync.WaitGroup, error) {
+	delivering := &sync.WaitGroup{}
+	for _, chainID := range channels {
+		stream, err := client.Deliver(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not open deliver stream for channel %s: %s", chainID, err)
+		}
+		if err := stream.Send(seekNewest(chainID)); err != nil {
+			return nil, fmt.Errorf("could not seek on channel %s: %s", chainID, err)
+		}
+		// The newest block is delivered at once, and every message sent
+		// after it is in a later block
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("could not deliver from channel %s: %s", chainID, err)
+		}
+		if resp.GetBlock() == nil {
+			return nil, fmt.Errorf("could not deliver from channel %s: %s", chainID, resp.GetStatus())
+		}
+		delivering.Add(1)
+		go func() {
+			defer delivering.Done()
+			recordCommits(stream, tracked)
+		}()
+	}
+	return delivering, nil
+}
+
+// Run drives the load described by config through client and reports the
+// results. Messages are unsigned, so the channels must admit them.
+func Run(client ab.AtomicBroadcastClient, config Config) (*Result, error) {
+	if config.Concurrency < 1 || config.Messages < 1 || len(config.Channels) == 0 {
+		return nil, fmt.Errorf("at least one channel, stream and message is required")
+	}
+	if config.MinSize < 0 || config.MaxSize < config.MinSize {
+		return nil, fmt.Errorf("invalid message size range %d-%d", config.MinSize, config.MaxSize)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracked := newPending()
+	var delivering *sync.WaitGroup
+	if config.CommitTimeout > 0 {
+		var err error
+		if delivering, err = startDelivery(ctx, client, config.Channels, tracked); err != nil {
+			return nil, err
+		}
+	}
+
+	type streamResult struct {
+		accepted, bytes int
+		acks            []time.Duration
+		err             error
+	}
+	results := make(chan streamResult, len(config.Channels)*config.Concurrency)
+	start := time.Now()
+	for _, chainID := range config.Channels {
+		for i := 0; i < config.Concurrency; i++ {
+			go func(chainID string, worker int) {
+				var r streamResult
+				r.accepted, r.bytes, r.acks, r.err = sendMessages(ctx, client, chainID, worker, config, tracked)
+				results <- r
+			}(chainID, i)
+		}
+	}
+
+	result := &Result{}
+	var acks []time.Duration
+	var firstErr error
+	for i := 0; i < cap(results); i++ {
+		r := <-results
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+		}
+		result.Accepted += r.accepted
+		result.Bytes += r.bytes
+		acks = append(acks, r.acks...)
+	}
+	result.Duration = time.Since(start)
+	result.Sent = len(acks)
+	result.Ack = summarize(acks)
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if config.CommitTimeout > 0 {
+		tracked.wait(config.CommitTimeout)
+		cancel()
+		delivering.Wait()
+		result.Commit = summarize(tracked.latencies())
+	}
+	return result, nil
+}
+
+// sendMessages sends the messages of one stream, each after the previous was
+// acknowledged, returning the number accepted, their size and the latency of
+// each acknowledgment
+func sendMessages(ctx context.Context, client ab.AtomicBroadcastClient, chainID string, worker int, config Config, tracked *pending) (int, int, []time.Duration, error) {
+	stream, err := client.Broadcast(ctx)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("could not open broadcast stream for channel %s: %s", chainID, err)
+	}
+	defer stream.CloseSend()
+
+	random := rand.New(rand.NewSource(int64(worker)))
+	var accepted, bytes int
+	acks := make([]time.Duration, 0, config.Messages)
+	for seq := 0; seq < config.Messages; seq++ {
+		size := config.MinSize + random.Intn(config.MaxSize-config.MinSize+1)
+		env, err := makeEnvelope(chainID, makeData(random, worker, seq, size), nil)
+		if err != nil {
+			return accepted, bytes, acks, err
+		}
+		marshaled := utils.MarshalOrPanic(env)
+
+		key := string(util.ComputeSHA256(marshaled))
+		sent := time.Now()
+		if config.CommitTimeout > 0 {
+			tracked.add(key, sent)
+		}
+		if err := stream.Send(env); err != nil {
+			return accepted, bytes, acks, fmt.Errorf("could not broadcast to channel %s: %s", chainID, err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return accepted, bytes, acks, fmt.Errorf("could not receive acknowledgment from channel %s: %s", chainID, err)
+		}
+		acks = append(acks, time.Since(sent))
+		if resp.Status != cb.Status_SUCCESS {
+			logger.Debugf("Message to channel %s rejected with %s", chainID, resp.Status)
+			tracked.forget(key)
+			continue
+		}
+		accepted++
+		bytes += len(marshaled)
+	}
+	return accepted, bytes, acks, nil
+}
+
+// recordCommits records the commit of each tracked message delivered on the stream
+// until it is closed
+func recordCommits(stream ab.AtomicBroadcast_DeliverClient, tracked *pending) {
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				logger.Debugf("Deliver stream closed: %s", err)
+			}
+			return
+		}
+		block := resp.GetBlock()
+		if block == nil {
+			logger.Warningf("Deliver stream ended with status %s", resp.GetStatus())
+			return
+		}
+		now := time.Now()
+		for _, data := range block.GetData().GetData() {
+			tracked.committed(string(util.ComputeSHA256(data)), now)
+		}
+	}
+}
+
+// makeData returns size bytes, the first of which identify the stream and
+// message so that no two messages are identical
+func makeData(random *rand.Rand, worker, seq, size int) []byte {
+	data := make([]byte, size)
+	random.Read(data)
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(worker))
+	binary.BigEndian.PutUint64(id[8:], uint64(seq))
+	copy(data, id[:])
+	if size < len(id) {
+		data = append(data[:0], id[:]...)
+	}
+	return data
+}
+
+// makeEnvelope returns a message to the channel holding data, signed by
+// signer unless it is nil
+func makeEnvelope(chainID string, data []byte, signer crypto.LocalSigner) (*cb.Envelope, error) {
+	signatureHeader := &cb.SignatureHeader{}
+	if signer != nil {
+		var err error
+		if signatureHeader, err = signer.NewSignatureHeader(); err != nil {
+			return nil, err
+		}
+	}
+	payload := utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{
+			ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+				Type:      int32(cb.HeaderType_MESSAGE),
+				ChannelId: chainID,
+				Timestamp: util.CreateUtcTimestamp(),
+			}),
+			SignatureHeader: utils.MarshalOrPanic(signatureHeader),
+		},
+		Data: data,
+	})
+	env := &cb.Envelope{Payload: payload}
+	if signer != nil {
+		var err error
+		if env.Signature, err = signer.Sign(payload); err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+func seekNewest(chainID string) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+					Type:      int32(cb.HeaderType_DELIVER_SEEK_INFO),
+					ChannelId: chainID,
+				}),
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{}),
+			},
+			Data: utils.MarshalOrPanic(&ab.SeekInfo{
+				Start:    &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}},
+				Stop:     &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: math.MaxUint64}}},
+				Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+			}),
+		}),
+	}
+}
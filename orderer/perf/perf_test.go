@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"testing"
+	"time"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestSummarize(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i > 0; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, Latencies{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}, summarize(samples))
+	assert.Equal(t, Latencies{}, summarize(nil))
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	_, err := Run(nil, Config{Channels: []string{"foo"}, Concurrency: 1})
+	assert.Error(t, err)
+	_, err = Run(nil, Config{Channels: []string{"foo"}, Concurrency: 1, Messages: 1, MinSize: 2, MaxSize: 1})
+	assert.Error(t, err)
+}
+
+func TestRunInProcess(t *testing.T) {
+	orderer, err := StartInProcess([]string{"foo", "bar"}, mockcrypto.FakeLocalSigner)
+	require.NoError(t, err)
+	defer orderer.Stop()
+
+	conn, err := grpc.Dial(orderer.Address, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Each channel receives the ten messages of a full batch, so blocks are
+	// cut without waiting for the batch timeout
+	result, err := Run(ab.NewAtomicBroadcastClient(conn), Config{
+		Channels:      []string{"foo", "bar"},
+		Concurrency:   2,
+		Messages:      5,
+		MinSize:       10,
+		MaxSize:       100,
+		CommitTimeout: time.Minute,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 20, result.Sent)
+	assert.Equal(t, 20, result.Accepted)
+	assert.Equal(t, 20, result.Ack.Count)
+	assert.Equal(t, 20, result.Commit.Count)
+	assert.True(t, result.Throughput() > 0)
+	assert.Contains(t, result.String(), "commit latency")
+}
@@ -0,0 +1,236 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report is the outcome of a workload run by Generate
+type Report struct {
+	// Profile describes the rate profile of the workload
+	Profile string
+	// Sent is the number of messages sent, of which Accepted were
+	// acknowledged with SUCCESS and Rejected were not, Skipped the number of
+	// messages scheduled but not sent as every stream was busy, and Bytes
+	// the total size of the accepted messages
+	Sent     int
+	Accepted int
+	Rejected int
+	Skipped  int
+	Bytes    int
+	// Duration is the time from the start of the workload to the last
+	// acknowledgment
+	Duration time.Duration
+	// Ack and Commit are the latencies of all messages, Commit is empty
+	// unless Workload.CommitTimeout is set
+	Ack    Latencies
+	Commit Latencies
+	// Intervals break the report down by when the messages were sent
+	Intervals []Interval
+}
+
+// Interval is the outcome of the messages sent during a period of a workload
+type Interval struct {
+	// Start is the offset of the period from the start of the workload
+	Start    time.Duration
+	Sent     int
+	Accepted int
+	Rejected int
+	Bytes    int
+	Ack      Latencies
+	Commit   Latencies
+}
+
+// summarize fills in the report from the samples and commits of messages
+// sent since start, broken down by interval
+func (r *Report) summarize(samples []sample, commits []commit, start time.Time, interval time.Duration) {
+	// Every message was sent before the end of the duration
+	count := int((r.Duration-1)/interval) + 1
+	acks := make([][]time.Duration, count)
+	latencies := make([][]time.Duration, count)
+	r.Intervals = make([]Interval, count)
+	for i := range r.Intervals {
+		r.Intervals[i].Start = time.Duration(i) * interval
+	}
+
+	var allAcks []time.Duration
+	for _, s := range samples {
+		i := int(s.offset / interval)
+		in := &r.Intervals[i]
+		in.Sent++
+		if s.accepted {
+			in.Accepted++
+			in.Bytes += s.bytes
+		} else {
+			in.Rejected++
+		}
+		acks[i] = append(acks[i], s.ack)
+		allAcks = append(allAcks, s.ack)
+	}
+
+	var allCommits []time.Duration
+	for _, c := range commits {
+		i := int(c.sent.Sub(start) / interval)
+		latencies[i] = append(latencies[i], c.latency)
+		allCommits = append(allCommits, c.latency)
+	}
+
+	for i := range r.Intervals {
+		in := &r.Intervals[i]
+		in.Ack = summarize(acks[i])
+		in.Commit = summarize(latencies[i])
+		r.Sent += in.Sent
+		r.Accepted += in.Accepted
+		r.Rejected += in.Rejected
+		r.Bytes += in.Bytes
+	}
+	r.Ack = summarize(allAcks)
+	r.Commit = summarize(allCommits)
+}
+
+// Throughput returns the accepted messages per second
+func (r *Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Accepted) / r.Duration.Seconds()
+}
+
+func (r *Report) String() string {
+	lines := []string{
+		fmt.Sprintf("profile: %s", r.Profile),
+		fmt.Sprintf("sent=%d accepted=%d rejected=%d skipped=%d duration=%s", r.Sent, r.Accepted, r.Rejected, r.Skipped, r.Duration),
+		fmt.Sprintf("throughput=%.1f msg/s %.1f KiB/s", r.Throughput(), float64(r.Bytes)/1024/r.Duration.Seconds()),
+		fmt.Sprintf("ack latency: %s", r.Ack),
+	}
+	if r.Commit.Count > 0 {
+		lines = append(lines, fmt.Sprintf("commit latency: %s", r.Commit))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// csvHeader names the columns written by WriteCSV, latencies are in seconds
+var csvHeader = []string{
+	"start", "sent", "accepted", "rejected", "bytes",
+	"ack_p50", "ack_p90", "ack_p99", "ack_max",
+	"committed", "commit_p50", "commit_p90", "commit_p99", "commit_max",
+}
+
+// WriteCSV writes a row for each interval of the report
+func (r *Report) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, in := range r.Intervals {
+		err := out.Write([]string{
+			seconds(in.Start),
+			fmt.Sprint(in.Sent),
+			fmt.Sprint(in.Accepted),
+			fmt.Sprint(in.Rejected),
+			fmt.Sprint(in.Bytes),
+			seconds(in.Ack.P50),
+			seconds(in.Ack.P90),
+			seconds(in.Ack.P99),
+			seconds(in.Ack.Max),
+			fmt.Sprint(in.Commit.Count),
+			seconds(in.Commit.P50),
+			seconds(in.Commit.P90),
+			seconds(in.Commit.P99),
+			seconds(in.Commit.Max),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.6f", d.Seconds())
+}
+
+type jsonLatencies struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+type jsonInterval struct {
+	Start    float64       `json:"start"`
+	Sent     int           `json:"sent"`
+	Accepted int           `json:"accepted"`
+	Rejected int           `json:"rejected"`
+	Bytes    int           `json:"bytes"`
+	Ack      jsonLatencies `json:"ack"`
+	Commit   jsonLatencies `json:"commit"`
+}
+
+type jsonReport struct {
+	Profile    string         `json:"profile"`
+	Sent       int            `json:"sent"`
+	Accepted   int            `json:"accepted"`
+	Rejected   int            `json:"rejected"`
+	Skipped    int            `json:"skipped"`
+	Bytes      int            `json:"bytes"`
+	Duration   float64        `json:"duration"`
+	Throughput float64        `json:"throughput"`
+	Ack        jsonLatencies  `json:"ack"`
+	Commit     jsonLatencies  `json:"commit"`
+	Intervals  []jsonInterval `json:"intervals"`
+}
+
+func toJSONLatencies(l Latencies) jsonLatencies {
+	return jsonLatencies{
+		Count: l.Count,
+		P50:   l.P50.Seconds(),
+		P90:   l.P90.Seconds(),
+		P99:   l.P99.Seconds(),
+		Max:   l.Max.Seconds(),
+	}
+}
+
+// WriteJSON writes the report as indented JSON, durations and latencies are
+// in seconds
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := jsonReport{
+		Profile:    r.Profile,
+		Sent:       r.Sent,
+		Accepted:   r.Accepted,
+		Rejected:   r.Rejected,
+		Skipped:    r.Skipped,
+		Bytes:      r.Bytes,
+		Duration:   r.Duration.Seconds(),
+		Throughput: r.Throughput(),
+		Ack:        toJSONLatencies(r.Ack),
+		Commit:     toJSONLatencies(r.Commit),
+		Intervals:  make([]jsonInterval, 0, len(r.Intervals)),
+	}
+	for _, in := range r.Intervals {
+		out.Intervals = append(out.Intervals, jsonInterval{
+			Start:    in.Start.Seconds(),
+			Sent:     in.Sent,
+			Accepted: in.Accepted,
+			Rejected: in.Rejected,
+			Bytes:    in.Bytes,
+			Ack:      toJSONLatencies(in.Ack),
+			Commit:   toJSONLatencies(in.Commit),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *Report {
+	start := time.Now()
+	report := &Report{Profile: ConstantRate(2).String(), Duration: 2500 * time.Millisecond}
+	samples := []sample{
+		{offset: 100 * time.Millisecond, ack: 10 * time.Millisecond, bytes: 100, accepted: true},
+		{offset: 600 * time.Millisecond, ack: 20 * time.Millisecond, bytes: 100, accepted: true},
+		{offset: 1100 * time.Millisecond, ack: 30 * time.Millisecond, bytes: 100},
+		{offset: 2100 * time.Millisecond, ack: 40 * time.Millisecond, bytes: 200, accepted: true},
+	}
+	commits := []commit{
+		{sent: start.Add(100 * time.Millisecond), latency: time.Second},
+		{sent: start.Add(600 * time.Millisecond), latency: 2 * time.Second},
+		{sent: start.Add(2100 * time.Millisecond), latency: 3 * time.Second},
+	}
+	report.summarize(samples, commits, start, time.Second)
+	return report
+}
+
+func TestReportSummarize(t *testing.T) {
+	report := testReport()
+	assert.Equal(t, 4, report.Sent)
+	assert.Equal(t, 3, report.Accepted)
+	assert.Equal(t, 1, report.Rejected)
+	assert.Equal(t, 400, report.Bytes)
+	assert.Equal(t, 40*time.Millisecond, report.Ack.Max)
+	assert.Equal(t, 3, report.Commit.Count)
+
+	require.Len(t, report.Intervals, 3)
+	assert.Equal(t, Interval{
+		Start:    0,
+		Sent:     2,
+		Accepted: 2,
+		Bytes:    200,
+		Ack:      summarize([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond}),
+		Commit:   summarize([]time.Duration{time.Second, 2 * time.Second}),
+	}, report.Intervals[0])
+	assert.Equal(t, 1, report.Intervals[1].Rejected)
+	assert.Equal(t, 0, report.Intervals[1].Commit.Count)
+	assert.Equal(t, 2*time.Second, report.Intervals[2].Start)
+	assert.Contains(t, report.String(), "rejected=1")
+}
+
+func TestReportWriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, testReport().WriteCSV(buf))
+	rows, err := csv.NewReader(buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, csvHeader, rows[0])
+	assert.Equal(t, []string{"0.000000", "2", "2", "0", "200"}, rows[1][:5])
+	assert.Equal(t, "2.000000", rows[1][11])
+}
+
+func TestReportWriteJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, testReport().WriteJSON(buf))
+	decoded := &jsonReport{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), decoded))
+	assert.Equal(t, 4, decoded.Sent)
+	assert.Equal(t, 2.5, decoded.Duration)
+	assert.Equal(t, 3.0, decoded.Commit.Max)
+	require.Len(t, decoded.Intervals, 3)
+	assert.Equal(t, 1.0, decoded.Intervals[1].Start)
+}
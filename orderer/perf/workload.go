@@ -0,0 +1,356 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+)
+
+// tick is how often the rate of a workload is sampled to schedule messages
+const tick = 10 * time.Millisecond
+
+// queueSize is the number of scheduled messages which may wait for a free
+// stream on each channel before further messages are skipped
+const queueSize = 1000
+
+// RateProfile is the rate, in messages per second across all channels, at
+// which a workload sends messages over time
+type RateProfile interface {
+	// Rate returns the rate elapsed after the start of the workload
+	Rate(elapsed time.Duration) float64
+	String() string
+}
+
+// ConstantRate sends messages at a fixed rate
+type ConstantRate float64
+
+func (r ConstantRate) Rate(elapsed time.Duration) float64 {
+	return float64(r)
+}
+
+func (r ConstantRate) String() string {
+	return fmt.Sprintf("constant %.1f/s", float64(r))
+}
+
+// RampRate changes the rate linearly from From to To over the duration Over,
+// and keeps it at To afterwards
+type RampRate struct {
+	From float64
+	To   float64
+	Over time.Duration
+}
+
+func (r RampRate) Rate(elapsed time.Duration) float64 {
+	if r.Over <= 0 || elapsed >= r.Over {
+		return r.To
+	}
+	return r.From + (r.To-r.From)*elapsed.Seconds()/r.Over.Seconds()
+}
+
+func (r RampRate) String() string {
+	return fmt.Sprintf("ramp %.1f/s to %.1f/s over %s", r.From, r.To, r.Over)
+}
+
+// BurstRate sends messages at the Base rate, raised to the Peak rate for
+// Length at the start of every period Every
+type BurstRate struct {
+	Base   float64
+	Peak   float64
+	Every  time.Duration
+	Length time.Duration
+}
+
+func (r BurstRate) Rate(elapsed time.Duration) float64 {
+	if r.Every > 0 && elapsed%r.Every < r.Length {
+		return r.Peak
+	}
+	return r.Base
+}
+
+func (r BurstRate) String() string {
+	return fmt.Sprintf("burst %.1f/s, %.1f/s for %s every %s", r.Base, r.Peak, r.Length, r.Every)
+}
+
+// SizeDistribution is the distribution of the size in bytes of the data of
+// each message
+type SizeDistribution interface {
+	Size(random *rand.Rand) int
+}
+
+// FixedSize is a distribution of a single size
+type FixedSize int
+
+func (s FixedSize) Size(random *rand.Rand) int {
+	return int(s)
+}
+
+// UniformSize is a uniform distribution between Min and Max inclusive
+type UniformSize struct {
+	Min int
+	Max int
+}
+
+func (s UniformSize) Size(random *rand.Rand) int {
+	return s.Min + random.Intn(s.Max-s.Min+1)
+}
+
+// NormalSize is a normal distribution, truncated at zero
+type NormalSize struct {
+	Mean   int
+	StdDev int
+}
+
+func (s NormalSize) Size(random *rand.Rand) int {
+	size := int(math.Floor(random.NormFloat64()*float64(s.StdDev) + float64(s.Mean) + .5))
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// ParseSizes parses a size distribution, either a fixed size such as
+// "1024", a uniform distribution such as "uniform:100-1000" or a normal
+// distribution of a mean and standard deviation such as "normal:1024,256"
+func ParseSizes(spec string) (SizeDistribution, error) {
+	kind, params := "fixed", spec
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind, params = spec[:i], spec[i+1:]
+	}
+
+	var sep string
+	switch kind {
+	case "fixed":
+		size, err := strconv.Atoi(params)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid size %q", params)
+		}
+		return FixedSize(size), nil
+	case "uniform":
+		sep = "-"
+	case "normal":
+		sep = ","
+	default:
+		return nil, fmt.Errorf("unknown size distribution %q", kind)
+	}
+
+	parts := strings.Split(params, sep)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid %s size distribution %q", kind, params)
+	}
+	a, errA := strconv.Atoi(parts[0])
+	b, errB := strconv.Atoi(parts[1])
+	if errA != nil || errB != nil || a < 0 || b < 0 {
+		return nil, fmt.Errorf("invalid %s size distribution %q", kind, params)
+	}
+	if kind == "normal" {
+		return NormalSize{Mean: a, StdDev: b}, nil
+	}
+	if b < a {
+		return nil, fmt.Errorf("invalid size range %d-%d", a, b)
+	}
+	return UniformSize{Min: a, Max: b}, nil
+}
+
+// Workload describes the load generated by Generate
+type Workload struct {
+	// Channels are the channels broadcast to, in turn
+	Channels []string
+	// Rate is the rate at which messages are sent over time
+	Rate RateProfile
+	// Duration is how long messages are sent for
+	Duration time.Duration
+	// Sizes is the distribution of the size of the data of each message
+	Sizes SizeDistribution
+	// Signers sign the messages in turn, messages are unsigned if empty
+	Signers []crypto.LocalSigner
+	// Streams is the number of broadcast streams per channel. Each stream
+	// waits for the acknowledgment of a message before sending the next, so
+	// there must be enough streams to sustain the rate.
+	Streams int
+	// CommitTimeout, if positive, is how long to wait after the last message
+	// for every message to be delivered in a block, measuring the commit
+	// latency
+	CommitTimeout time.Duration
+	// Interval is the period of the intervals of the report, one second if
+	// zero
+	Interval time.Duration
+}
+
+// sample is the outcome of sending a message offset after the start of the
+// workload
+type sample struct {
+	offset   time.Duration
+	ack      time.Duration
+	bytes    int
+	accepted bool
+}
+
+// Generate drives the workload through client, scheduling messages at the
+// rate of its profile and reporting the outcome over time. Messages which
+// cannot be sent when scheduled, because every stream of their channel is
+// busy, are skipped rather than delayed, so that the offered load follows
+// the profile.
+func Generate(client ab.AtomicBroadcastClient, w Workload) (*Report, error) {
+	if len(w.Channels) == 0 || w.Streams < 1 || w.Rate == nil || w.Sizes == nil || w.Duration <= 0 {
+		return nil, fmt.Errorf("a channel, stream, rate, size distribution and duration are required")
+	}
+	if w.Interval <= 0 {
+		w.Interval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracked := newPending()
+	var delivering *sync.WaitGroup
+	if w.CommitTimeout > 0 {
+		var err error
+		if delivering, err = startDelivery(ctx, client, w.Channels, tracked); err != nil {
+			return nil, err
+		}
+	}
+
+	type streamResult struct {
+		samples []sample
+		err     error
+	}
+	queues := make([]chan struct{}, len(w.Channels))
+	results := make(chan streamResult, len(w.Channels)*w.Streams)
+	start := time.Now()
+	for i, chainID := range w.Channels {
+		queues[i] = make(chan struct{}, queueSize)
+		for j := 0; j < w.Streams; j++ {
+			go func(chainID string, worker int, queue chan struct{}) {
+				var r streamResult
+				r.samples, r.err = generateMessages(ctx, client, chainID, worker, w, queue, start, tracked)
+				results <- r
+			}(chainID, i*w.Streams+j, queues[i])
+		}
+	}
+
+	skipped := schedule(w, queues, start)
+
+	report := &Report{Profile: w.Rate.String(), Skipped: skipped}
+	var samples []sample
+	var firstErr error
+	for i := 0; i < cap(results); i++ {
+		r := <-results
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+		}
+		samples = append(samples, r.samples...)
+	}
+	report.Duration = time.Since(start)
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if w.CommitTimeout > 0 {
+		tracked.wait(w.CommitTimeout)
+		cancel()
+		delivering.Wait()
+	}
+	report.summarize(samples, tracked.recorded(), start, w.Interval)
+	return report, nil
+}
+
+// schedule hands out the messages due at the rate of the workload to the
+// queues of the channels in turn until the duration of the workload has
+// elapsed, closing the queues and returning the number of messages skipped
+// because their queue was full
+func schedule(w Workload, queues []chan struct{}, start time.Time) int {
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+	}()
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	var due float64
+	var next, skipped int
+	last := start
+	for now := range ticker.C {
+		elapsed := now.Sub(start)
+		if elapsed >= w.Duration {
+			return skipped
+		}
+		due += w.Rate.Rate(elapsed) * now.Sub(last).Seconds()
+		last = now
+		for ; due >= 1; due-- {
+			select {
+			case queues[next%len(queues)] <- struct{}{}:
+			default:
+				skipped++
+			}
+			next++
+		}
+	}
+	return skipped
+}
+
+// generateMessages sends a message on one stream for each entry of the
+// queue until it is closed
+func generateMessages(ctx context.Context, client ab.AtomicBroadcastClient, chainID string, worker int, w Workload, queue chan struct{}, start time.Time, tracked *pending) ([]sample, error) {
+	stream, err := client.Broadcast(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not open broadcast stream for channel %s: %s", chainID, err)
+	}
+	defer stream.CloseSend()
+
+	random := rand.New(rand.NewSource(int64(worker)))
+	var samples []sample
+	seq := 0
+	for range queue {
+		var signer crypto.LocalSigner
+		if len(w.Signers) > 0 {
+			signer = w.Signers[(worker+seq)%len(w.Signers)]
+		}
+		env, err := makeEnvelope(chainID, makeData(random, worker, seq, w.Sizes.Size(random)), signer)
+		if err != nil {
+			return samples, fmt.Errorf("could not create message: %s", err)
+		}
+		seq++
+		marshaled := utils.MarshalOrPanic(env)
+
+		key := string(util.ComputeSHA256(marshaled))
+		sent := time.Now()
+		if w.CommitTimeout > 0 {
+			tracked.add(key, sent)
+		}
+		if err := stream.Send(env); err != nil {
+			return samples, fmt.Errorf("could not broadcast to channel %s: %s", chainID, err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return samples, fmt.Errorf("could not receive acknowledgment from channel %s: %s", chainID, err)
+		}
+		s := sample{offset: sent.Sub(start), ack: time.Since(sent), bytes: len(marshaled)}
+		if resp.Status == cb.Status_SUCCESS {
+			s.accepted = true
+		} else {
+			logger.Debugf("Message to channel %s rejected with %s", chainID, resp.Status)
+			tracked.forget(key)
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package perf
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/core/config"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestRateProfiles(t *testing.T) {
+	assert.Equal(t, 10.0, ConstantRate(10).Rate(time.Hour))
+
+	ramp := RampRate{From: 10, To: 110, Over: 10 * time.Second}
+	assert.Equal(t, 10.0, ramp.Rate(0))
+	assert.Equal(t, 60.0, ramp.Rate(5*time.Second))
+	assert.Equal(t, 110.0, ramp.Rate(time.Minute))
+
+	burst := BurstRate{Base: 1, Peak: 100, Every: 10 * time.Second, Length: 2 * time.Second}
+	assert.Equal(t, 100.0, burst.Rate(time.Second))
+	assert.Equal(t, 1.0, burst.Rate(5*time.Second))
+	assert.Equal(t, 100.0, burst.Rate(21*time.Second))
+}
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := ParseSizes("1024")
+	require.NoError(t, err)
+	assert.Equal(t, FixedSize(1024), sizes)
+
+	sizes, err = ParseSizes("uniform:10-20")
+	require.NoError(t, err)
+	assert.Equal(t, UniformSize{Min: 10, Max: 20}, sizes)
+	random := rand.New(rand.NewSource(0))
+	for i := 0; i < 100; i++ {
+		size := sizes.Size(random)
+		assert.True(t, size >= 10 && size <= 20, "size %d out of range", size)
+	}
+
+	sizes, err = ParseSizes("normal:1024,256")
+	require.NoError(t, err)
+	assert.Equal(t, NormalSize{Mean: 1024, StdDev: 256}, sizes)
+	assert.Equal(t, 0, NormalSize{Mean: -10}.Size(random))
+
+	for _, spec := range []string{"", "-1", "uniform:20-10", "uniform:10", "normal:a,b", "poisson:10"} {
+		_, err := ParseSizes(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestGenerateInvalidWorkload(t *testing.T) {
+	_, err := Generate(nil, Workload{Channels: []string{"foo"}, Streams: 1, Sizes: FixedSize(1), Duration: time.Second})
+	assert.Error(t, err)
+}
+
+func TestGenerateInProcess(t *testing.T) {
+	orderer, err := StartInProcess([]string{"foo", "bar"}, mockcrypto.FakeLocalSigner)
+	require.NoError(t, err)
+	defer orderer.Stop()
+
+	conn, err := grpc.Dial(orderer.Address, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	dir, err := config.GetDevMspDir()
+	require.NoError(t, err)
+	signer, err := LoadSigner(dir, "DEFAULT")
+	require.NoError(t, err)
+
+	report, err := Generate(ab.NewAtomicBroadcastClient(conn), Workload{
+		Channels:      []string{"foo", "bar"},
+		Rate:          ConstantRate(100),
+		Duration:      500 * time.Millisecond,
+		Sizes:         UniformSize{Min: 10, Max: 100},
+		Signers:       []crypto.LocalSigner{signer, nil},
+		Streams:       2,
+		CommitTimeout: time.Minute,
+		Interval:      100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.True(t, report.Sent > 20, "only %d messages sent", report.Sent)
+	assert.Equal(t, report.Sent, report.Accepted)
+	assert.Equal(t, report.Sent, report.Commit.Count)
+	assert.True(t, len(report.Intervals) >= 5)
+
+	var sent int
+	for _, in := range report.Intervals {
+		sent += in.Sent
+	}
+	assert.Equal(t, report.Sent, sent)
+}
@@ -0,0 +1,1340 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp/factory"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/client"
+	"github.com/hyperledger/fabric/orderer/common/admin"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/bootstrap"
+	"github.com/hyperledger/fabric/orderer/common/bootstrap/deliver"
+	"github.com/hyperledger/fabric/orderer/common/bootstrap/file"
+	"github.com/hyperledger/fabric/orderer/common/compat"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	"github.com/hyperledger/fabric/orderer/common/cosign"
+	"github.com/hyperledger/fabric/orderer/common/events"
+	"github.com/hyperledger/fabric/orderer/common/extensions"
+	"github.com/hyperledger/fabric/orderer/common/health"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	"github.com/hyperledger/fabric/orderer/common/jointoken"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/operations"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	"github.com/hyperledger/fabric/orderer/common/receipts"
+	"github.com/hyperledger/fabric/orderer/common/reflection"
+	"github.com/hyperledger/fabric/orderer/common/sdnotify"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
+	"github.com/hyperledger/fabric/orderer/follower"
+	"github.com/hyperledger/fabric/orderer/kafka"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	"github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/metadata"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	"github.com/hyperledger/fabric/orderer/solo"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	abv2 "github.com/hyperledger/fabric/protos/orderer/v2"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/netutil"
+
+	"github.com/Shopify/sarama"
+	"github.com/hyperledger/fabric/common/localmsp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	logging "github.com/op/go-logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var logger = logging.MustGetLogger("orderer/main")
+
+//command line flags
+var (
+	app = kingpin.New("orderer", "Hyperledger Fabric orderer node")
+
+	start   = app.Command("start", "Start the orderer node").Default()
+	devMode = start.Flag("dev", "Start a development orderer, with a RAM ledger, solo consensus, no TLS, permissive policies and debug logging").Bool()
+	version = app.Command("version", "Show version information")
+)
+
+// Main runs the orderer command line, as the orderer binary does
+func Main() {
+
+	kingpin.Version("0.0.1")
+	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+
+	// "start" command
+	case start.FullCommand():
+		logger.Infof("Starting %s", metadata.GetVersionInfo())
+		load := config.Load
+		if *devMode {
+			load = loadDevConfig
+			logger.Warning("Development mode enabled, the orderer accepts any request and must not be used in production")
+		}
+		if sdnotify.IsWindowsService() {
+			if err := sdnotify.RunWindowsService(windowsServiceName, func(notifier *sdnotify.Notifier, stop <-chan struct{}) {
+				serve(load, notifier, stop)
+			}); err != nil {
+				logger.Fatalf("Failed to run as a Windows service: %s", err)
+			}
+			return
+		}
+		serve(load, nil, nil)
+	// "version" command
+	case version.FullCommand():
+		fmt.Println(metadata.GetVersionInfo())
+	}
+
+}
+
+// windowsServiceName is the name of the orderer when it runs as a Windows
+// service
+const windowsServiceName = "orderer"
+
+// serve runs the orderer until it is stopped by a signal or, if it runs as a
+// Windows service, once stop is closed. Its lifecycle is reported to
+// notifier, if set, and otherwise to the service manager which started it,
+// if any.
+func serve(load func() *config.TopLevel, notifier *sdnotify.Notifier, stop <-chan struct{}) {
+	conf := load()
+	initializeLoggingLevel(conf)
+	initializeProfilingService(conf)
+	timers := pipeline.NewTimers()
+	operations := initializeOperationsSystem(conf, timers)
+	initializeStatsD(conf)
+	trail := initializeAudit(conf)
+	orderer, err := New(Config{TopLevel: conf, Audit: trail, Pipeline: timers, Notifier: notifier})
+	if err != nil {
+		logger.Fatal("Failed to initialize the orderer:", err)
+	}
+	registerProbes(operations, orderer.healthServer)
+	handleReload(load, orderer.endpoints, orderer.notifier)
+	handleShutdown(orderer.drain)
+	if stop != nil {
+		go func() {
+			<-stop
+			logger.Info("Service control manager requested a stop, draining connections")
+			orderer.drain()
+		}()
+	}
+	if err := orderer.Start(); err != nil {
+		logger.Errorf("gRPC server stopped: %s", err)
+	}
+	if err := trail.Close(); err != nil {
+		logger.Errorf("Failed to close the audit trail: %s", err)
+	}
+	logger.Info("Orderer stopped")
+}
+
+// loadDevConfig loads the configuration and overrides it for development
+func loadDevConfig() *config.TopLevel {
+	conf := config.Load()
+	applyDevMode(conf)
+	return conf
+}
+
+// applyDevMode overrides the configuration so that the orderer needs no
+// crypto material beyond the local MSP and keeps no state: the system channel
+// is bootstrapped in a RAM ledger from the sample profile ordered by solo,
+// which has no MSPs and accepts any transaction or channel creation request,
+// TLS and token authentication are disabled on every listener and logging is
+// at debug level. It must never be used in production.
+func applyDevMode(conf *config.TopLevel) {
+	general := &conf.General
+	general.LedgerType = "ram"
+	general.GenesisMethod = "provisional"
+	general.GenesisProfile = genesisconfig.SampleInsecureProfile
+	general.TLS.Enabled = false
+	general.TLS.ClientAuthEnabled = false
+	for name, listener := range general.Listeners {
+		listener.TLS.Enabled = false
+		listener.TLS.ClientAuthEnabled = false
+		general.Listeners[name] = listener
+	}
+	general.TokenAuth.Enabled = false
+	// The admin service requires TLS
+	general.Admin.Enabled = false
+	general.LogLevel = "debug"
+}
+
+// The logging spec currently in effect, so that module overrides which are
+// dropped from the spec on a reload can be reverted
+var (
+	logSpecLock    sync.Mutex
+	currentLogSpec string
+)
+
+// Set the logging level
+func initializeLoggingLevel(conf *config.TopLevel) {
+	flogging.InitBackend(flogging.SetFormat(conf.General.LogFormat), os.Stderr)
+	applyLoggingSpec(conf.General.LogLevel)
+	if conf.Kafka.Verbose {
+		sarama.Logger = log.New(os.Stdout, "[sarama] ", log.Ldate|log.Lmicroseconds|log.Lshortfile)
+	}
+}
+
+// applyLoggingSpec replaces the logging spec currently in effect with the
+// supplied one, of the form accepted by flogging.InitFromSpec, e.g.
+// "orderer/kafka=warning:orderer/common/broadcast=debug:info". Modules that were
+// overridden by the previous spec but are not named by the new one revert to
+// the new default level.
+func applyLoggingSpec(spec string) string {
+	logSpecLock.Lock()
+	defer logSpecLock.Unlock()
+
+	levelAll := flogging.InitFromSpec(spec)
+	level, _ := logging.LogLevel(levelAll)
+
+	overridden := make(map[string]struct{})
+	for _, module := range specModules(spec) {
+		overridden[module] = struct{}{}
+	}
+	for _, module := range specModules(currentLogSpec) {
+		if _, ok := overridden[module]; !ok {
+			logging.SetLevel(level, module)
+		}
+	}
+
+	currentLogSpec = spec
+	return levelAll
+}
+
+// loggingSpec returns the logging spec currently in effect
+func loggingSpec() string {
+	logSpecLock.Lock()
+	defer logSpecLock.Unlock()
+	return currentLogSpec
+}
+
+// specModules returns the names of the modules which have a level override in
+// the given logging spec
+func specModules(spec string) []string {
+	var modules []string
+	for _, field := range strings.Split(spec, ":") {
+		split := strings.Split(field, "=")
+		if len(split) != 2 || split[0] == "" {
+			continue
+		}
+		modules = append(modules, strings.Split(split[0], ",")...)
+	}
+	return modules
+}
+
+// handleReload re-reads the configuration on SIGHUP and applies its logging
+// spec and the TLS credentials of each endpoint, so that verbosity may be
+// adjusted and certificates rotated without a restart. The service manager is
+// told while the configuration is reloading.
+func handleReload(load func() *config.TopLevel, endpoints []*endpoint, notifier *sdnotify.Notifier) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			notifier.Report(sdnotify.Reloading, "Reloading configuration")
+			reload(load, endpoints)
+			notifier.Report(sdnotify.Ready, "Serving requests")
+		}
+	}()
+}
+
+func reload(load func() *config.TopLevel, endpoints []*endpoint) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Errorf("Failed to reload configuration, keeping current settings: %v", err)
+		}
+	}()
+	conf := load()
+
+	logger.Infof("Reloading logging spec '%s'", conf.General.LogLevel)
+	applyLoggingSpec(conf.General.LogLevel)
+
+	for _, e := range endpoints {
+		if !e.TLSEnabled() {
+			continue
+		}
+		tlsConf, ok := e.tlsConfig(conf)
+		if !ok {
+			logger.Warningf("Listener %s is no longer configured, keeping its TLS credentials", e.name)
+			continue
+		}
+		if err := reloadTLSCredentials(tlsConf, e); err != nil {
+			logger.Errorf("Failed to reload TLS credentials of listener %s, keeping current ones: %s", e.name, err)
+		} else {
+			logger.Infof("Reloaded TLS credentials of listener %s", e.name)
+		}
+	}
+}
+
+// reloadTLSCredentials replaces the server certificate and, if client
+// authentication is enabled, the client root CAs of the gRPC server with
+// those of the TLS settings currently on disk. Established connections keep
+// the credentials they were negotiated with.
+func reloadTLSCredentials(tlsConf config.TLS, grpcServer comm.GRPCServer) error {
+	serverCertificate, err := ioutil.ReadFile(tlsConf.Certificate)
+	if err != nil {
+		return fmt.Errorf("failed to load ServerCertificate file '%s' (%s)", tlsConf.Certificate, err)
+	}
+	var serverKey []byte
+	if !tlsConf.BCCSPKey {
+		serverKey, err = ioutil.ReadFile(tlsConf.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to load PrivateKey file '%s' (%s)", tlsConf.PrivateKey, err)
+		}
+	}
+
+	var clientRootCAs [][]byte
+	if tlsConf.ClientAuthEnabled {
+		for _, clientRoot := range tlsConf.ClientRootCAs {
+			root, err := ioutil.ReadFile(clientRoot)
+			if err != nil {
+				return fmt.Errorf("failed to load ClientRootCAs file '%s' (%s)", clientRoot, err)
+			}
+			clientRootCAs = append(clientRootCAs, root)
+		}
+	}
+
+	if tlsConf.BCCSPKey {
+		signer, err := bccspSigner(factory.GetDefault(), serverCertificate)
+		if err != nil {
+			return fmt.Errorf("failed to find the TLS private key in the BCCSP (%s)", err)
+		}
+		if err := grpcServer.SetServerCertificateSigner(serverCertificate, signer); err != nil {
+			return err
+		}
+	} else if err := grpcServer.SetServerCertificate(serverCertificate, serverKey); err != nil {
+		return err
+	}
+	if len(clientRootCAs) > 0 {
+		return grpcServer.SetClientRootCAs(clientRootCAs)
+	}
+	return nil
+}
+
+// Start the profiling service if enabled.
+func initializeProfilingService(conf *config.TopLevel) {
+	if conf.General.Profile.Enabled {
+		go func() {
+			logger.Info("Starting Go pprof profiling service on:", conf.General.Profile.Address)
+			// The ListenAndServe() call does not return unless an error occurs.
+			logger.Panic("Go pprof service failed:", http.ListenAndServe(conf.General.Profile.Address, nil))
+		}()
+	}
+}
+
+// Start the operations listener if enabled, returning nil otherwise. The
+// timings of the stages of the pipeline are served from the timers.
+func initializeOperationsSystem(conf *config.TopLevel, timers *pipeline.Timers) *operations.System {
+	if !conf.General.Operations.Enabled {
+		return nil
+	}
+
+	opsTLS := conf.General.Operations.TLS
+	tlsConfig, err := operations.TLS{
+		Enabled:            opsTLS.Enabled,
+		CertFile:           opsTLS.Certificate,
+		KeyFile:            opsTLS.PrivateKey,
+		ClientCertRequired: opsTLS.ClientAuthEnabled,
+		ClientRootCAs:      opsTLS.ClientRootCAs,
+	}.Config()
+	if err != nil {
+		logger.Fatalf("Failed to configure the operations listener: %s", err)
+	}
+
+	system := operations.NewSystem(conf.General.Operations.ListenAddress, tlsConfig)
+	system.Handle("/metrics", metrics.PrometheusHandler(metrics.DefaultRegistry()))
+	system.Handle("/debug/stages", pipeline.Handler(timers))
+	if err := system.Start(); err != nil {
+		logger.Fatalf("Failed to start the operations listener: %s", err)
+	}
+	return system
+}
+
+// Serve the liveness and readiness probes of the orderer on the operations
+// listener, if it is enabled. The listener starts before the orderer is
+// created, so that it can be profiled while bootstrapping, and the probes are
+// only served once it is.
+func registerProbes(system *operations.System, healthServer *health.Server) {
+	if system == nil {
+		return
+	}
+	system.Handle("/healthz", healthServer.Handler(health.Liveness))
+	system.Handle("/readyz", healthServer.Handler(health.Readiness))
+}
+
+// Start pushing metrics to a StatsD collector if enabled, returning nil
+// otherwise
+func initializeStatsD(conf *config.TopLevel) *metrics.StatsD {
+	if !conf.General.StatsD.Enabled {
+		return nil
+	}
+
+	statsd, err := metrics.NewStatsD(metrics.DefaultRegistry(), conf.General.StatsD.Address, conf.General.StatsD.Prefix, conf.General.StatsD.DogStatsD)
+	if err != nil {
+		logger.Fatalf("Failed to initialize StatsD: %s", err)
+	}
+	statsd.Start(conf.General.StatsD.FlushInterval)
+	return statsd
+}
+
+// Create the audit trail, or return nil if auditing is disabled, continuing
+// the chain of an existing audit log
+func initializeAudit(conf *config.TopLevel) *audit.Trail {
+	if !conf.General.Audit.Enabled {
+		return nil
+	}
+
+	var sinks []audit.Sink
+	var last *audit.Entry
+	if conf.General.Audit.File != "" {
+		fileSink, lastEntry, err := audit.NewFileSink(conf.General.Audit.File, conf.General.Audit.SyncInterval)
+		if err != nil {
+			logger.Fatalf("Failed to open audit log %s: %s", conf.General.Audit.File, err)
+		}
+		sinks = append(sinks, fileSink)
+		last = lastEntry
+	}
+	if conf.General.Audit.Logger {
+		sinks = append(sinks, audit.NewLoggerSink(os.Stdout))
+	}
+	logger.Info("Security audit trail enabled")
+	return audit.NewTrail(last, sinks...)
+}
+
+// Create the publisher of the committed blocks, or return nil if events are
+// disabled
+func initializeEventPublisher(conf *config.TopLevel, support events.Support, provider metrics.Provider) *events.Publisher {
+	eventsConf := conf.General.Events
+	if !eventsConf.Enabled {
+		return nil
+	}
+
+	var sinks []events.Sink
+	if eventsConf.Webhook.URL != "" {
+		sinks = append(sinks, events.NewWebhookSink(eventsConf.Webhook.URL, eventsConf.Webhook.Timeout))
+	}
+	if len(eventsConf.Kafka.Brokers) > 0 {
+		kafkaSink, err := events.NewKafkaSink(eventsConf.Kafka.Brokers, eventsConf.Kafka.Topic, conf.Kafka.Version)
+		if err != nil {
+			logger.Panicf("Failed to connect to the Kafka brokers of General.Events.Kafka: %s", err)
+		}
+		sinks = append(sinks, kafkaSink)
+	}
+	if eventsConf.NATS.Address != "" {
+		sinks = append(sinks, events.NewNATSSink(eventsConf.NATS.Address, eventsConf.NATS.Subject, eventsConf.NATS.Timeout))
+	}
+
+	progress := events.NewMemoryProgress()
+	if eventsConf.ProgressFile != "" {
+		var err error
+		if progress, err = events.LoadProgress(eventsConf.ProgressFile); err != nil {
+			logger.Panicf("Failed to load the event progress file %s: %s", eventsConf.ProgressFile, err)
+		}
+	}
+
+	publisher, err := events.NewPublisher(support, events.Config{
+		Format:       eventsConf.Payload,
+		Sinks:        sinks,
+		Progress:     progress,
+		FromNewest:   eventsConf.FromNewest,
+		RetryInitial: eventsConf.RetryInitial,
+		RetryMax:     eventsConf.RetryMax,
+		MaxRetries:   eventsConf.MaxRetries,
+		Metrics:      provider,
+	})
+	if err != nil {
+		logger.Panicf("Failed to create the event publisher: %s", err)
+	}
+	logger.Infof("Publishing %s events to %d sinks", eventsConf.Payload, len(sinks))
+	return publisher
+}
+
+// handshakeErrorRecorder returns a handler which records the failed TLS
+// handshakes in the audit trail
+func handshakeErrorRecorder(trail *audit.Trail) func(remote net.Addr, err error) {
+	return func(remote net.Addr, err error) {
+		trail.Record(audit.Event{
+			Type:   audit.TLSHandshakeFailure,
+			Remote: remote.String(),
+			Detail: err.Error(),
+		})
+	}
+}
+
+func initializeSecureServerConfig(conf *config.TopLevel, trail *audit.Trail) comm.SecureServerConfig {
+	return secureServerConfig(conf.General.TLS, trail)
+}
+
+func secureServerConfig(tlsConf config.TLS, trail *audit.Trail) comm.SecureServerConfig {
+	// secure server config
+	secureConfig := comm.SecureServerConfig{
+		UseTLS:            tlsConf.Enabled,
+		RequireClientCert: tlsConf.ClientAuthEnabled,
+	}
+	// check to see if TLS is enabled
+	if secureConfig.UseTLS {
+		logger.Info("Starting orderer with TLS enabled")
+		// load crypto material from files
+		serverCertificate, err := ioutil.ReadFile(tlsConf.Certificate)
+		if err != nil {
+			logger.Panicf("Failed to load ServerCertificate file '%s' (%s)",
+				tlsConf.Certificate, err)
+		}
+		if tlsConf.BCCSPKey {
+			signer, err := bccspSigner(factory.GetDefault(), serverCertificate)
+			if err != nil {
+				logger.Panicf("Failed to find the TLS private key in the BCCSP (%s)", err)
+			}
+			secureConfig.ServerSigner = signer
+		} else {
+			serverKey, err := ioutil.ReadFile(tlsConf.PrivateKey)
+			if err != nil {
+				logger.Panicf("Failed to load PrivateKey file '%s' (%s)",
+					tlsConf.PrivateKey, err)
+			}
+			secureConfig.ServerKey = serverKey
+		}
+		var serverRootCAs, clientRootCAs [][]byte
+		for _, serverRoot := range tlsConf.RootCAs {
+			root, err := ioutil.ReadFile(serverRoot)
+			if err != nil {
+				logger.Panicf("Failed to load ServerRootCAs file '%s' (%s)",
+					err, serverRoot)
+			}
+			serverRootCAs = append(serverRootCAs, root)
+		}
+		if secureConfig.RequireClientCert {
+			for _, clientRoot := range tlsConf.ClientRootCAs {
+				root, err := ioutil.ReadFile(clientRoot)
+				if err != nil {
+					logger.Panicf("Failed to load ClientRootCAs file '%s' (%s)",
+						err, clientRoot)
+				}
+				clientRootCAs = append(clientRootCAs, root)
+			}
+		}
+		secureConfig.ServerCertificate = serverCertificate
+		secureConfig.ServerRootCAs = serverRootCAs
+		secureConfig.ClientRootCAs = clientRootCAs
+		secureConfig.HandshakeErrorHandler = handshakeErrorRecorder(trail)
+	}
+	return secureConfig
+}
+
+// genesisProviders create the bootstrap helper of each genesis method
+var genesisProviders = map[string]func(conf *config.TopLevel) bootstrap.Helper{
+	"provisional": func(conf *config.TopLevel) bootstrap.Helper {
+		return provisional.New(genesisconfig.Load(conf.General.GenesisProfile))
+	},
+	"file": func(conf *config.TopLevel) bootstrap.Helper {
+		return file.New(conf.General.GenesisFile)
+	},
+	"deliver": newDeliverBootstrapper,
+}
+
+func newDeliverBootstrapper(conf *config.TopLevel) bootstrap.Helper {
+	genesisConf := conf.General.GenesisDeliver
+	var hash []byte
+	if genesisConf.Hash != "" {
+		var err error
+		if hash, err = hex.DecodeString(genesisConf.Hash); err != nil {
+			logger.Panicf("Invalid General.GenesisDeliver.Hash: %s", err)
+		}
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if genesisConf.TLS.Enabled {
+		tlsConfig := clientTLSConfig("General.GenesisDeliver", genesisConf.TLS, genesisConf.ServerNameOverride)
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+	}
+
+	return deliver.New(deliver.Options{
+		Address:       genesisConf.Address,
+		ChainID:       genesisConf.ChainID,
+		Hash:          hash,
+		DialOptions:   dialOpts,
+		Signer:        localmsp.NewSigner(),
+		Timeout:       genesisConf.Timeout,
+		RetryInterval: genesisConf.RetryInterval,
+	})
+}
+
+// clientTLSConfig loads the TLS configuration of the connections to other
+// orderers from the section key of the configuration
+func clientTLSConfig(key string, tlsConf config.TLS, serverNameOverride string) *tls.Config {
+	tlsConfig := &tls.Config{ServerName: serverNameOverride}
+	if len(tlsConf.RootCAs) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		for _, rootCA := range tlsConf.RootCAs {
+			root, err := ioutil.ReadFile(rootCA)
+			if err != nil {
+				logger.Panicf("Failed to load %s.TLS.RootCAs file '%s': %s", key, rootCA, err)
+			}
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(root) {
+				logger.Panicf("No certificates found in %s.TLS.RootCAs file '%s'", key, rootCA)
+			}
+		}
+	}
+	if tlsConf.Certificate != "" {
+		cert, err := tls.LoadX509KeyPair(tlsConf.Certificate, tlsConf.PrivateKey)
+		if err != nil {
+			logger.Panicf("Failed to load %s.TLS client certificate: %s", key, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig
+}
+
+func initializeBootstrapChannel(conf *config.TopLevel, lf ledger.Factory) {
+	// Select the bootstrapping mechanism
+	provider, ok := genesisProviders[conf.General.GenesisMethod]
+	if !ok {
+		logger.Panic("Unknown genesis method:", conf.General.GenesisMethod)
+	}
+	genesisBlock := provider(conf).GenesisBlock()
+
+	chainID, err := utils.GetChainIDFromBlock(genesisBlock)
+	if err != nil {
+		logger.Panic("Failed to parse chain ID from genesis block:", err)
+	}
+	gl, err := lf.GetOrCreate(chainID)
+	if err != nil {
+		logger.Panic("Failed to create the system chain:", err)
+	}
+
+	err = gl.Append(genesisBlock)
+	if err != nil {
+		logger.Panic("Could not write genesis block to ledger:", err)
+	}
+}
+
+func initializeKeepaliveOptions(conf *config.TopLevel) comm.KeepaliveOptions {
+	ka := comm.DefaultKeepaliveOptions()
+	// The server enforces the minimum interval between client pings,
+	// which must match the interval that clients are expected to use
+	ka.ClientKeepaliveTime = int(conf.General.Keepalive.ServerMinInterval.Seconds())
+	ka.ServerKeepaliveTime = int(conf.General.Keepalive.ServerInterval.Seconds())
+	ka.ServerKeepaliveTimeout = int(conf.General.Keepalive.ServerTimeout.Seconds())
+	ka.ServerMaxConnectionIdle = int(conf.General.Keepalive.ServerMaxConnectionIdle.Seconds())
+	ka.ServerMaxConnectionAge = int(conf.General.Keepalive.ServerMaxConnectionAge.Seconds())
+	ka.ServerMaxConnectionAgeGrace = int(conf.General.Keepalive.ServerMaxConnectionAgeGrace.Seconds())
+	return ka
+}
+
+// Set the message size and stream limits of the gRPC server, leaving the
+// package defaults in place for any which are not configured
+func initializeServerLimits(conf *config.TopLevel) {
+	if conf.General.Limits.MaxRecvMsgSize > 0 {
+		comm.SetMaxRecvMsgSize(int(conf.General.Limits.MaxRecvMsgSize))
+	}
+	if conf.General.Limits.MaxSendMsgSize > 0 {
+		comm.SetMaxSendMsgSize(int(conf.General.Limits.MaxSendMsgSize))
+	}
+	comm.SetMaxConcurrentStreams(conf.General.Limits.MaxConcurrentStreams)
+}
+
+// Create the bearer token authenticator, or return nil if token
+// authentication is disabled
+func initializeTokenAuth(conf *config.TopLevel, trail *audit.Trail) *interceptor.TokenAuthenticator {
+	if !conf.General.TokenAuth.Enabled {
+		return nil
+	}
+
+	var issuerCerts [][]byte
+	for _, issuerCert := range conf.General.TokenAuth.IssuerCertificates {
+		cert, err := ioutil.ReadFile(issuerCert)
+		if err != nil {
+			logger.Panicf("Failed to load token IssuerCertificates file '%s' (%s)", issuerCert, err)
+		}
+		issuerCerts = append(issuerCerts, cert)
+	}
+
+	tokenAuth, err := interceptor.NewTokenAuthenticator(issuerCerts, conf.General.TokenAuth.Required, trail)
+	if err != nil {
+		logger.Panicf("Failed to configure token authentication: %s", err)
+	}
+	return tokenAuth
+}
+
+// Create the per client rate limiter, or return nil if no limit is configured.
+// It runs innermost, so rejected requests are still logged and counted.
+func initializeThrottle(conf *config.TopLevel) *interceptor.Throttle {
+	rates := func(r config.ThrottleRates) interceptor.Rates {
+		return interceptor.Rates{StreamRate: r.StreamRate, RPCRate: r.RPCRate}
+	}
+	throttle := conf.General.Throttle
+	return interceptor.NewThrottle(rates(throttle.PerIdentity), rates(throttle.PerIP), throttle.Burst)
+}
+
+// Return the entitlements of the creators of messages to priority classes, an
+// unset DefaultEntitlement being NORMAL
+func initializePriority(conf *config.TopLevel) priority.Config {
+	parse := func(key, name string) ab.PriorityClass {
+		class, err := priority.ParseClass(name)
+		if err != nil {
+			logger.Panicf("Failed to parse %s: %s", key, err)
+		}
+		return class
+	}
+
+	priorityConf := priority.Config{DefaultEntitlement: ab.PriorityClass_NORMAL}
+	if conf.General.Priority.DefaultEntitlement != "" {
+		priorityConf.DefaultEntitlement = parse("General.Priority.DefaultEntitlement", conf.General.Priority.DefaultEntitlement)
+	}
+	if len(conf.General.Priority.Entitlements) > 0 {
+		priorityConf.Entitlements = make(map[string]ab.PriorityClass, len(conf.General.Priority.Entitlements))
+		for mspID, name := range conf.General.Priority.Entitlements {
+			priorityConf.Entitlements[mspID] = parse("General.Priority.Entitlements."+mspID, name)
+		}
+	}
+	return priorityConf
+}
+
+// Return the limits on the extensions of messages, validated by the validator
+// if it is not nil
+func initializeExtensions(conf *config.TopLevel, validator extensions.Validator) extensions.Config {
+	return extensions.Config{
+		MaxBytes:  conf.General.Extensions.MaxBytes,
+		Validator: validator,
+	}
+}
+
+// Open the log of the receipts of accepted broadcasts, or return nil if
+// receipts are disabled
+func initializeReceipts(conf *config.TopLevel) *receipts.Log {
+	receiptsConf := conf.General.Receipts
+	if !receiptsConf.Enabled {
+		return nil
+	}
+	log, err := receipts.Open(receipts.Config{
+		File:   receiptsConf.File,
+		Size:   receiptsConf.Size,
+		Window: receiptsConf.Window,
+	})
+	if err != nil {
+		logger.Panicf("Failed to open receipts log %s: %s", receiptsConf.File, err)
+	}
+	return log
+}
+
+// Create the tracer of transactions, or return nil if tracing is disabled
+func initializeTracing(conf *config.TopLevel) *tracing.Tracer {
+	tracingConf := conf.General.Tracing
+	if !tracingConf.Enabled {
+		return nil
+	}
+	var exporter tracing.Exporter = tracing.LogExporter{}
+	if tracingConf.File != "" {
+		fileExporter, err := tracing.NewFileExporter(tracingConf.File)
+		if err != nil {
+			logger.Panicf("Failed to open trace file %s: %s", tracingConf.File, err)
+		}
+		exporter = fileExporter
+	}
+	tracer := tracing.NewTracer(tracing.Config{SampleRate: tracingConf.SampleRate}, exporter)
+	return tracer
+}
+
+// Services which may be offered on a listener
+const (
+	broadcastService = "Broadcast"
+	deliverService   = "Deliver"
+	adminService     = "Admin"
+)
+
+// endpoint is a gRPC server along with the services offered on it
+type endpoint struct {
+	comm.GRPCServer
+	name     string
+	services []string
+}
+
+// tlsConfig returns the TLS settings of the endpoint in the configuration, or
+// false if it has none there, as is the case of the Unix domain socket and of
+// listeners since removed
+func (e *endpoint) tlsConfig(conf *config.TopLevel) (config.TLS, bool) {
+	switch e.name {
+	case "default":
+		return conf.General.TLS, true
+	case "unix":
+		return config.TLS{}, false
+	}
+	listener, ok := conf.General.Listeners[e.name]
+	return listener.TLS, ok
+}
+
+// exposes reports whether the service is offered on the endpoint, which is
+// the case for all services if none are named
+func (e *endpoint) exposes(service string) bool {
+	if len(e.services) == 0 {
+		return true
+	}
+	for _, s := range e.services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+func servers(endpoints []*endpoint) []comm.GRPCServer {
+	grpcServers := make([]comm.GRPCServer, len(endpoints))
+	for i, e := range endpoints {
+		grpcServers[i] = e.GRPCServer
+	}
+	return grpcServers
+}
+
+// Create the per stream rate ceilings, or return nil if none are configured
+func initializeStreamLimiter(conf *config.TopLevel) *interceptor.StreamLimiter {
+	return interceptor.NewStreamLimiter(interceptor.StreamLimits{
+		MessageRate: conf.General.Limits.StreamMessageRate,
+		ByteRate:    conf.General.Limits.StreamByteRate,
+	})
+}
+
+// Create the gRPC servers, the first of which is the primary server. Unless
+// the Unix domain socket is configured to be the exclusive listener, the
+// primary server listens on the configured TCP address and is followed by a
+// server for each additional listener, in order of name. A server is created
+// last for the socket if one is configured. The keepalive options and the
+// message size and stream limits, which are those of the comm package, apply
+// to all of them, as do the interceptors.
+func initializeGrpcServers(conf *config.TopLevel, trail *audit.Trail, interceptors *serverInterceptors) []*endpoint {
+	comm.SetKeepaliveOptions(initializeKeepaliveOptions(conf))
+	initializeServerLimits(conf)
+
+	var endpoints []*endpoint
+	if !conf.General.UnixSocket.Exclusive {
+		endpoints = append(endpoints, &endpoint{
+			GRPCServer: initializeGrpcServer(conf, trail, interceptors),
+			name:       "default",
+			services:   checkServices("General.Services", conf.General.Services),
+		})
+
+		var names []string
+		for name := range conf.General.Listeners {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			listener := conf.General.Listeners[name]
+			endpoints = append(endpoints, &endpoint{
+				GRPCServer: initializeListenerServer(conf, name, listener, trail, interceptors),
+				name:       name,
+				services:   checkServices(fmt.Sprintf("General.Listeners.%s.Services", name), listener.Services),
+			})
+		}
+	}
+	if socketServer := initializeUnixSocketServer(conf, interceptors); socketServer != nil {
+		endpoints = append(endpoints, &endpoint{GRPCServer: socketServer, name: "unix"})
+	}
+	if len(endpoints) == 0 {
+		logger.Panic("General.UnixSocket.Exclusive is set but General.UnixSocket.Path is not")
+	}
+	return endpoints
+}
+
+func checkServices(key string, services []string) []string {
+	for _, service := range services {
+		switch service {
+		case broadcastService, deliverService, adminService:
+		default:
+			logger.Panicf("Unknown service '%s' in %s, expected one of %s, %s or %s",
+				service, key, broadcastService, deliverService, adminService)
+		}
+	}
+	return services
+}
+
+// Register both versions of the AtomicBroadcast service on the endpoint if
+// it offers either of Broadcast or Deliver, rejecting whichever of the two it
+// does not offer. Version 2 is served by translating for version 1.
+func registerAtomicBroadcast(e *endpoint, server ab.AtomicBroadcastServer) {
+	broadcast, deliver := e.exposes(broadcastService), e.exposes(deliverService)
+	switch {
+	case broadcast && deliver:
+	case broadcast || deliver:
+		server = restrictedServer{AtomicBroadcastServer: server, broadcast: broadcast, deliver: deliver}
+	default:
+		return
+	}
+	ab.RegisterAtomicBroadcastServer(e.Server(), server)
+	abv2.RegisterAtomicBroadcastServer(e.Server(), compat.NewServer(server))
+}
+
+// Register the BatchBroadcast service on the endpoint if it offers Broadcast
+func registerBatchBroadcast(e *endpoint, server ab.BatchBroadcastServer) {
+	if !e.exposes(broadcastService) {
+		return
+	}
+	ab.RegisterBatchBroadcastServer(e.Server(), server)
+}
+
+// Register the TransactionStatus service on the endpoint if it offers
+// Deliver, as both are authorized by the readers of the channel
+func registerTransactionStatus(e *endpoint, manager multichain.Manager, log *receipts.Log, trail *audit.Trail) {
+	if !e.exposes(deliverService) {
+		return
+	}
+	ab.RegisterTransactionStatusServer(e.Server(), txstatus.NewServer(txStatusSupport{Manager: manager}, log, trail))
+}
+
+// Register the BlockCosigner service, with which the other orderers of a
+// channel collect the signature of this one, wherever Broadcast is offered,
+// as other orderers connect to the orderer addresses of the channel
+func registerBlockCosigner(e *endpoint, manager multichain.Manager, signer crypto.LocalSigner, cosigner *cosign.Cosigner) {
+	if cosigner == nil || !e.exposes(broadcastService) {
+		return
+	}
+	ab.RegisterBlockCosignerServer(e.Server(), cosign.NewServer(cosigner, cosignSupport{Manager: manager}, signer))
+}
+
+// Create the collector of the signatures of other orderers on blocks, or
+// return nil if cosigning is disabled
+func initializeCosign(conf *config.TopLevel) *cosign.Cosigner {
+	cosignConf := conf.General.Cosign
+	var tlsConfig *tls.Config
+	if cosignConf.Enabled && cosignConf.TLS.Enabled {
+		tlsConfig = clientTLSConfig("General.Cosign", cosignConf.TLS, cosignConf.ServerNameOverride)
+	}
+	self := append([]string{net.JoinHostPort(conf.General.ListenAddress, strconv.Itoa(int(conf.General.ListenPort)))}, cosignConf.Addresses...)
+	return cosign.New(cosign.Config{
+		Enabled: cosignConf.Enabled,
+		TLS:     tlsConfig,
+		Timeout: cosignConf.Timeout,
+		Self:    self,
+	})
+}
+
+// Register the ChannelJoin service, granting the config blocks of channels
+// to the bearers of join tokens, wherever Deliver is offered if enabled
+func registerChannelJoin(e *endpoint, conf *config.TopLevel, manager multichain.Manager, signer crypto.LocalSigner, trail *audit.Trail) {
+	if !conf.General.JoinTokens.Enabled || !e.exposes(deliverService) {
+		return
+	}
+	ab.RegisterChannelJoinServer(e.Server(), jointoken.NewServer(joinTokenSupport{Manager: manager}, signer, conf.General.JoinTokens.MaxValidity, trail))
+}
+
+// Create a gRPC server without TLS listening on the configured Unix domain
+// socket, or return nil if none is configured. Access is controlled by the
+// permissions of the socket file.
+func initializeUnixSocketServer(conf *config.TopLevel, interceptors *serverInterceptors) comm.GRPCServer {
+	path := conf.General.UnixSocket.Path
+	if path == "" {
+		return nil
+	}
+	mode, err := strconv.ParseUint(conf.General.UnixSocket.Mode, 8, 32)
+	if err != nil {
+		logger.Panicf("Invalid General.UnixSocket.Mode '%s': %s", conf.General.UnixSocket.Mode, err)
+	}
+
+	// Remove a socket left behind by a previous run, but nothing else
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			logger.Panicf("Failed to remove stale socket %s: %s", path, err)
+		}
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		logger.Panic("Failed to listen:", err)
+	}
+	if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+		logger.Panicf("Failed to set the permissions of socket %s: %s", path, err)
+	}
+
+	secureConfig := comm.SecureServerConfig{}
+	secureConfig.UnaryInterceptors, secureConfig.StreamInterceptors = interceptors.chains(nil)
+	socketServer, err := comm.NewGRPCServerFromListener(lis, secureConfig)
+	if err != nil {
+		logger.Panic("Failed to return new GRPC server:", err)
+	}
+	logger.Infof("Listening on Unix domain socket %s", path)
+	return socketServer
+}
+
+// serverInterceptors are the interceptors of the gRPC servers, which are
+// created once so that the limits the throttle and the stream limiter keep
+// per client are shared by all the servers, and a client cannot escape them
+// by connecting to another listener. A nil serverInterceptors only installs
+// the standard interceptors.
+type serverInterceptors struct {
+	tokenAuth *interceptor.TokenAuthenticator
+	throttle  *interceptor.Throttle
+	limiter   *interceptor.StreamLimiter
+}
+
+// Create the interceptors shared by all gRPC servers
+func initializeInterceptors(conf *config.TopLevel, trail *audit.Trail) *serverInterceptors {
+	return &serverInterceptors{
+		tokenAuth: initializeTokenAuth(conf, trail),
+		throttle:  initializeThrottle(conf),
+		limiter:   initializeStreamLimiter(conf),
+	}
+}
+
+// chains returns the interceptor chains of a gRPC server, outermost first.
+// Bearer tokens are authenticated as General.TokenAuth configures, unless
+// the token authentication settings of the listener override it.
+func (si *serverInterceptors) chains(listenerAuth *config.ListenerTokenAuth) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	unary := interceptor.UnaryInterceptors()
+	stream := interceptor.StreamInterceptors()
+	if si == nil {
+		return unary, stream
+	}
+	tokenAuth := si.tokenAuth
+	if listenerAuth != nil {
+		tokenAuth = nil
+		if listenerAuth.Enabled {
+			tokenAuth = si.tokenAuth.Requiring(listenerAuth.Required)
+		}
+	}
+	if tokenAuth != nil {
+		unary = append(unary, tokenAuth.Unary)
+		stream = append(stream, tokenAuth.Stream)
+	}
+	if si.throttle != nil {
+		unary = append(unary, si.throttle.Unary)
+		stream = append(stream, si.throttle.Stream)
+	}
+	if si.limiter != nil {
+		stream = append(stream, si.limiter.Stream)
+	}
+	return unary, stream
+}
+
+func initializeGrpcServer(conf *config.TopLevel, trail *audit.Trail, interceptors *serverInterceptors) comm.GRPCServer {
+	secureConfig := initializeSecureServerConfig(conf, trail)
+	return newTCPServer(conf, conf.General.ListenAddress, conf.General.ListenPort, secureConfig, interceptors, nil)
+}
+
+// Create a gRPC server for an additional listener, which shares the
+// interceptors and limits of the primary server but has its own TLS and
+// token authentication settings
+func initializeListenerServer(conf *config.TopLevel, name string, listener config.Listener, trail *audit.Trail, interceptors *serverInterceptors) comm.GRPCServer {
+	if listener.TokenAuth != nil && listener.TokenAuth.Enabled && !conf.General.TokenAuth.Enabled {
+		logger.Panicf("General.Listeners.%s.TokenAuth is enabled but General.TokenAuth, which configures the token issuers, is not", name)
+	}
+	grpcServer := newTCPServer(conf, listener.ListenAddress, listener.ListenPort, secureServerConfig(listener.TLS, trail), interceptors, listener.TokenAuth)
+	logger.Infof("Listening on %s for listener %s", grpcServer.Address(), name)
+	return grpcServer
+}
+
+func newTCPServer(conf *config.TopLevel, address string, port uint16, secureConfig comm.SecureServerConfig, interceptors *serverInterceptors, listenerAuth *config.ListenerTokenAuth) comm.GRPCServer {
+	secureConfig.UnaryInterceptors, secureConfig.StreamInterceptors = interceptors.chains(listenerAuth)
+
+	lis, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(int(port))))
+	if err != nil {
+		logger.Panic("Failed to listen:", err)
+	}
+	if conf.General.Limits.MaxConnections > 0 {
+		lis = netutil.LimitListener(lis, int(conf.General.Limits.MaxConnections))
+	}
+
+	// Create GRPC server - return if an error occurs
+	grpcServer, err := comm.NewGRPCServerFromListener(lis, secureConfig)
+	if err != nil {
+		logger.Panic("Failed to return new GRPC server:", err)
+	}
+
+	return grpcServer
+}
+
+// atomicBroadcastService is the name under which the health of the
+// AtomicBroadcast service is reported
+const atomicBroadcastService = "orderer.AtomicBroadcast"
+
+// Register the gRPC health service, which reports NOT_SERVING until startup
+// completes, on each of the gRPC servers
+func initializeHealthServer(grpcServers ...comm.GRPCServer) *health.Server {
+	healthServer := health.NewServer()
+	for _, grpcServer := range grpcServers {
+		healthpb.RegisterHealthServer(grpcServer.Server(), healthServer)
+	}
+	return healthServer
+}
+
+// Register the gRPC server reflection service on each of the gRPC servers if
+// it is enabled
+func initializeReflection(conf *config.TopLevel, grpcServers ...comm.GRPCServer) {
+	if !conf.General.GRPC.Reflection {
+		return
+	}
+	for _, grpcServer := range grpcServers {
+		reflection.Register(grpcServer.Server())
+	}
+	logger.Info("gRPC server reflection enabled")
+}
+
+// consenterChecker reports the AtomicBroadcast service as unhealthy while the
+// consenter of the system channel is unavailable, e.g. while a Kafka based
+// chain is still connecting to the cluster
+func consenterChecker(manager multichain.Manager) health.Checker {
+	return func() error {
+		chainID := manager.SystemChannelID()
+		cs, ok := manager.GetChain(chainID)
+		if !ok {
+			return fmt.Errorf("system channel %s not found", chainID)
+		}
+		select {
+		case <-cs.Errored():
+			return fmt.Errorf("consenter for channel %s is not available", chainID)
+		default:
+			return nil
+		}
+	}
+}
+
+// configChecker reports the config of the system channel as not loaded
+// until the manager holds the channel with its config
+func configChecker(manager multichain.Manager) health.Checker {
+	return func() error {
+		chainID := manager.SystemChannelID()
+		cs, ok := manager.GetChain(chainID)
+		if !ok {
+			return fmt.Errorf("system channel %s not found", chainID)
+		}
+		if cs.ConfigEnvelope().GetConfig() == nil {
+			return fmt.Errorf("config of channel %s is not loaded", chainID)
+		}
+		return nil
+	}
+}
+
+// ledgerChecker reports the ledger as unhealthy while no file can be written
+// to its directory, e.g. once the disk is full or remounted read-only
+func ledgerChecker(dir string) health.Checker {
+	return func() error {
+		f, err := ioutil.TempFile(dir, ".healthz")
+		if err != nil {
+			return fmt.Errorf("ledger directory %s is not writable: %s", dir, err)
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write([]byte{0})
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write to ledger directory %s: %s", dir, err)
+		}
+		return nil
+	}
+}
+
+// registerCheck registers the check of a subsystem with the probe, failing
+// it only after the configured number of failures in a row
+func registerCheck(conf *config.TopLevel, healthServer *health.Server, name string, probe health.Probe, checker health.Checker) {
+	healthConf := conf.General.Operations.Health
+	threshold, ok := healthConf.FailureThresholds[name]
+	if !ok {
+		threshold = healthConf.FailureThreshold
+	}
+	healthServer.RegisterCheck(name, probe, health.Threshold(checker, threshold))
+}
+
+// followerChecker reports the AtomicBroadcast service of an archive node as
+// unhealthy until it holds the genesis block of the system channel
+func followerChecker(f *follower.Follower, chainID string) health.Checker {
+	return func() error {
+		if _, ok := f.GetChain(chainID); !ok {
+			return fmt.Errorf("system channel %s has not been replicated yet", chainID)
+		}
+		return nil
+	}
+}
+
+// Register the admin service if it is enabled. The service authenticates
+// callers by their TLS client certificate, so it is only offered over TLS.
+func initializeAdminServer(conf *config.TopLevel, grpcServer comm.GRPCServer, manager multichain.Manager, signer crypto.LocalSigner, maintenance *admin.MaintenanceMode, shutdown func(), trail *audit.Trail) {
+	if !conf.General.Admin.Enabled {
+		return
+	}
+	if !grpcServer.TLSEnabled() {
+		logger.Warning("Not starting the admin service because TLS is not enabled")
+		return
+	}
+
+	var clientRootCAs [][]byte
+	for _, clientRoot := range conf.General.Admin.ClientRootCAs {
+		root, err := ioutil.ReadFile(clientRoot)
+		if err != nil {
+			logger.Panicf("Failed to load admin ClientRootCAs file '%s' (%s)", clientRoot, err)
+		}
+		clientRootCAs = append(clientRootCAs, root)
+	}
+
+	adminServer, err := admin.NewServer(adminSupport{Manager: manager, signer: signer}, maintenance, shutdown, clientRootCAs, trail)
+	if err != nil {
+		logger.Panicf("Failed to create the admin service: %s", err)
+	}
+	ab.RegisterAdminServer(grpcServer.Server(), adminServer)
+	logger.Info("Admin service enabled")
+}
+
+// handleShutdown drains the orderer when it receives SIGTERM or SIGINT
+func handleShutdown(drain func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		sig := <-signals
+		logger.Infof("Received %s, draining connections", sig)
+		drain()
+	}()
+}
+
+// drainOnce wraps drain so that it only runs once, later callers block until
+// the first call completes
+func drainOnce(drain func()) func() {
+	var once sync.Once
+	return func() {
+		once.Do(drain)
+	}
+}
+
+// gracefulStopAll returns a function which gracefully stops each of the gRPC
+// servers in parallel, closing the connections which remain open after the
+// given timeout
+func gracefulStopAll(grpcServers []comm.GRPCServer, timeout time.Duration) func() {
+	return func() {
+		var wg sync.WaitGroup
+		for _, grpcServer := range grpcServers {
+			wg.Add(1)
+			go func(stop func()) {
+				defer wg.Done()
+				stop()
+			}(gracefulStop(grpcServer, timeout))
+		}
+		wg.Wait()
+	}
+}
+
+// gracefulStop returns a function which stops accepting connections, sends
+// GOAWAY to clients and stops the gRPC server once in flight RPCs complete,
+// closing any which remain open after the given timeout
+func gracefulStop(grpcServer comm.GRPCServer, timeout time.Duration) func() {
+	return func() {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.Server().GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(timeout):
+			logger.Warningf("Timed out after %s waiting for streams to close, stopping", timeout)
+			grpcServer.Stop()
+		}
+	}
+}
+
+func initializeLocalMsp(conf *config.TopLevel) {
+	// Load local MSP
+	err := mspmgmt.LoadLocalMsp(conf.General.LocalMSPDir, conf.General.BCCSP, conf.General.LocalMSPID)
+	if err != nil { // Handle errors reading the config file
+		logger.Panic("Failed to initialize local MSP:", err)
+	}
+}
+
+// Create the manager of the chains held by the ledger factory, bootstrapping
+// the system channel if it holds none. The configured ledger and the solo and
+// kafka consenters are used unless others are supplied. The chains are
+// created with the components of managerConf.
+func initializeMultiChainManager(conf *config.TopLevel, lf ledger.Factory, consenters map[string]multichain.Consenter, signer crypto.LocalSigner, managerConf multichain.Config) multichain.Manager {
+	if lf == nil {
+		lf, _ = createLedgerFactory(conf)
+	}
+	// Are we bootstrapping?
+	if len(lf.ChainIDs()) == 0 {
+		initializeBootstrapChannel(conf, lf)
+	} else {
+		logger.Info("Not bootstrapping because of existing chains")
+	}
+
+	if consenters == nil {
+		consenters = make(map[string]multichain.Consenter)
+		consenters["solo"] = solo.New()
+		consenters["kafka"] = kafka.New(conf.Kafka.TLS, conf.Kafka.Retry, conf.Kafka.Version, conf.Kafka.Cluster)
+	}
+
+	return multichain.NewManagerImpl(lf, consenters, signer, managerConf)
+}
+
+// Create the follower of the ordering service of General.Follower, which
+// replicates its chains into the ledger factory, and the client it requests
+// the blocks with. The configured ledger is used unless another is supplied.
+func initializeFollower(conf *config.TopLevel, lf ledger.Factory, signer crypto.LocalSigner) (*follower.Follower, *client.Client) {
+	if lf == nil {
+		lf, _ = createLedgerFactory(conf)
+	}
+	followerConf := conf.General.Follower
+	var tlsConfig *tls.Config
+	if followerConf.TLS.Enabled {
+		tlsConfig = clientTLSConfig("General.Follower", followerConf.TLS, followerConf.ServerNameOverride)
+	}
+	var endpoints []client.Endpoint
+	for _, address := range followerConf.Endpoints {
+		endpoints = append(endpoints, client.Endpoint{Address: address, TLS: tlsConfig})
+	}
+	algorithms, err := compression.ParseAll(followerConf.Compression)
+	if err != nil {
+		logger.Panicf("Invalid General.Follower.Compression: %s", err)
+	}
+	upstream, err := client.New(client.Config{
+		Endpoints:           endpoints,
+		Signer:              signer,
+		Compression:         algorithms,
+		MaxDecompressedSize: int(conf.General.Limits.MaxRecvMsgSize),
+	})
+	if err != nil {
+		logger.Panicf("Failed to create the client of General.Follower.Endpoints: %s", err)
+	}
+	f, err := follower.New(upstream, lf, follower.Config{
+		SystemChainID: followerConf.ChainID,
+		RetryInterval: followerConf.RetryInterval,
+	})
+	if err != nil {
+		upstream.Close()
+		logger.Panicf("Failed to load the followed chains: %s", err)
+	}
+	logger.Infof("Following system channel %s from %s", followerConf.ChainID, strings.Join(followerConf.Endpoints, ", "))
+	return f, upstream
+}
@@ -0,0 +1,1096 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/core/comm"
+	coreconfig "github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/orderer/common/admin"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/health"
+	"github.com/hyperledger/fabric/orderer/common/interceptor"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	"github.com/hyperledger/fabric/orderer/common/priority"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	config "github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	logging "github.com/op/go-logging"
+	// logging "github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestInitializeLoggingLevel(t *testing.T) {
+	initializeLoggingLevel(
+		&config.TopLevel{
+			General: config.General{LogLevel: "debug"},
+			Kafka:   config.Kafka{Verbose: true},
+		},
+	)
+	assert.Equal(t, flogging.GetModuleLevel("orderer/main"), "DEBUG")
+	assert.NotNil(t, sarama.Logger)
+}
+
+func TestApplyLoggingSpec(t *testing.T) {
+	defer applyLoggingSpec("info")
+
+	applyLoggingSpec("orderer/kafka=warning:orderer/common/broadcast,orderer/common/deliver=debug:info")
+	assert.Equal(t, "WARNING", flogging.GetModuleLevel("orderer/kafka"))
+	assert.Equal(t, "DEBUG", flogging.GetModuleLevel("orderer/common/broadcast"))
+	assert.Equal(t, "DEBUG", flogging.GetModuleLevel("orderer/common/deliver"))
+	assert.Equal(t, "INFO", flogging.GetModuleLevel("orderer/main"))
+
+	// Overrides which are dropped from the spec revert to the new default
+	applyLoggingSpec("orderer/common/deliver=error:warning")
+	assert.Equal(t, "WARNING", flogging.GetModuleLevel("orderer/kafka"))
+	assert.Equal(t, "WARNING", flogging.GetModuleLevel("orderer/common/broadcast"))
+	assert.Equal(t, "ERROR", flogging.GetModuleLevel("orderer/common/deliver"))
+	assert.Equal(t, "WARNING", flogging.GetModuleLevel("orderer/main"))
+}
+
+func TestAdminSupportLogSpec(t *testing.T) {
+	defer applyLoggingSpec("info")
+
+	as := adminSupport{}
+	as.SetLogSpec("orderer/main=debug:info")
+	assert.Equal(t, "orderer/main=debug:info", as.LogSpec())
+	assert.Equal(t, "DEBUG", flogging.GetModuleLevel("orderer/main"))
+}
+
+func TestReload(t *testing.T) {
+	defer applyLoggingSpec("info")
+	applyLoggingSpec("info")
+
+	reload(func() *config.TopLevel {
+		return &config.TopLevel{General: config.General{LogLevel: "orderer/main=debug:info"}}
+	}, nil)
+	assert.Equal(t, "DEBUG", flogging.GetModuleLevel("orderer/main"))
+
+	t.Run("LoadFailure", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			reload(func() *config.TopLevel { panic("bad config") }, nil)
+		})
+		assert.Equal(t, "DEBUG", flogging.GetModuleLevel("orderer/main"), "Spec should be unchanged")
+	})
+}
+
+func TestReloadTLSCredentials(t *testing.T) {
+	certDir := filepath.Join("..", "..", "core", "comm", "testdata", "certs")
+	tlsConf := func(server string) config.TLS {
+		return config.TLS{
+			Enabled:           true,
+			ClientAuthEnabled: true,
+			Certificate:       filepath.Join(certDir, server+"-cert.pem"),
+			PrivateKey:        filepath.Join(certDir, server+"-key.pem"),
+			ClientRootCAs:     []string{filepath.Join(certDir, "Org2-cert.pem")},
+		}
+	}
+	conf := &config.TopLevel{General: config.General{
+		ListenAddress: "127.0.0.1",
+		TLS:           tlsConf("Org1-server1"),
+	}}
+	grpcServer := initializeGrpcServer(conf, nil, nil)
+	defer grpcServer.Listener().Close()
+	original := grpcServer.ServerCertificate()
+
+	t.Run("MissingFile", func(t *testing.T) {
+		assert.Error(t, reloadTLSCredentials(tlsConf("Org1-missing"), grpcServer))
+		assert.Equal(t, original, grpcServer.ServerCertificate())
+	})
+
+	t.Run("Rotated", func(t *testing.T) {
+		assert.NoError(t, reloadTLSCredentials(tlsConf("Org1-server2"), grpcServer))
+		assert.NotEqual(t, original.Certificate[0], grpcServer.ServerCertificate().Certificate[0])
+	})
+
+	t.Run("EveryListener", func(t *testing.T) {
+		listenerConf := func(server string) map[string]config.Listener {
+			return map[string]config.Listener{"peers": {ListenAddress: "127.0.0.1", TLS: tlsConf(server)}}
+		}
+		conf := &config.TopLevel{General: config.General{
+			ListenAddress: "127.0.0.1",
+			TLS:           tlsConf("Org1-server1"),
+			Listeners:     listenerConf("Org2-server1"),
+		}}
+		endpoints := initializeGrpcServers(conf, nil, nil)
+		for _, e := range endpoints {
+			defer e.Listener().Close()
+		}
+		require.Len(t, endpoints, 2)
+		primary, peers := endpoints[0].ServerCertificate(), endpoints[1].ServerCertificate()
+
+		// Each listener is reloaded from its own TLS settings
+		reload(func() *config.TopLevel {
+			return &config.TopLevel{General: config.General{
+				LogLevel:  "info",
+				TLS:       tlsConf("Org1-server1"),
+				Listeners: listenerConf("Org2-server2"),
+			}}
+		}, endpoints)
+		assert.Equal(t, primary.Certificate[0], endpoints[0].ServerCertificate().Certificate[0])
+		assert.NotEqual(t, peers.Certificate[0], endpoints[1].ServerCertificate().Certificate[0], "The certificate of the listener should have been rotated")
+
+		// and keeps its credentials once it is no longer configured
+		peers = endpoints[1].ServerCertificate()
+		reload(func() *config.TopLevel {
+			return &config.TopLevel{General: config.General{LogLevel: "info", TLS: tlsConf("Org1-server2")}}
+		}, endpoints)
+		assert.NotEqual(t, primary.Certificate[0], endpoints[0].ServerCertificate().Certificate[0], "The certificate of the primary server should have been rotated")
+		assert.Equal(t, peers.Certificate[0], endpoints[1].ServerCertificate().Certificate[0])
+	})
+}
+
+func TestInitializeProfilingService(t *testing.T) {
+	// get a free random port
+	listenAddr := func() string {
+		l, _ := net.Listen("tcp", "localhost:0")
+		l.Close()
+		return l.Addr().String()
+	}()
+	initializeProfilingService(
+		&config.TopLevel{
+			General: config.General{
+				LogLevel: "debug",
+				Profile: config.Profile{
+					Enabled: true,
+					Address: listenAddr,
+				}},
+			Kafka: config.Kafka{Verbose: true},
+		},
+	)
+	time.Sleep(500 * time.Millisecond)
+	if _, err := http.Get("http://" + listenAddr + "/" + "/debug/"); err != nil {
+		t.Logf("Expected pprof to be up (will retry again in 3 seconds): %s", err)
+		time.Sleep(3 * time.Second)
+		if _, err := http.Get("http://" + listenAddr + "/" + "/debug/"); err != nil {
+			t.Fatalf("Expected pprof to be up: %s", err)
+		}
+	}
+}
+
+func TestInitializeOperationsSystem(t *testing.T) {
+	assert.Nil(t, initializeOperationsSystem(&config.TopLevel{}, pipeline.NewTimers()), "Operations should be disabled by default")
+
+	system := initializeOperationsSystem(&config.TopLevel{General: config.General{
+		Operations: config.Operations{Enabled: true, ListenAddress: "127.0.0.1:0"},
+	}}, pipeline.NewTimers())
+	assert.NotNil(t, system)
+	defer system.Stop()
+
+	resp, err := http.Get("http://" + system.Addr() + "/debug/vars")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get("http://" + system.Addr() + "/metrics")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, metrics.PrometheusContentType, resp.Header.Get("Content-Type"))
+}
+
+func TestInitializeStatsD(t *testing.T) {
+	assert.Nil(t, initializeStatsD(&config.TopLevel{}), "StatsD should be disabled by default")
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	statsd := initializeStatsD(&config.TopLevel{General: config.General{
+		StatsD: config.StatsD{Enabled: true, Address: conn.LocalAddr().String(), FlushInterval: time.Hour},
+	}})
+	assert.NotNil(t, statsd)
+	statsd.Stop()
+}
+
+func TestInitializeAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orderer-audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	trail := initializeAudit(&config.TopLevel{General: config.General{Audit: config.Audit{File: path}}})
+	assert.Nil(t, trail, "Nothing should be recorded unless auditing is enabled")
+	handshakeErrorRecorder(trail)(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1000}, fmt.Errorf("tls: bad certificate"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Nothing should be recorded unless auditing is enabled")
+
+	trail = initializeAudit(&config.TopLevel{General: config.General{Audit: config.Audit{Enabled: true, File: path}}})
+	handshakeErrorRecorder(trail)(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1000}, fmt.Errorf("tls: bad certificate"))
+	assert.NoError(t, trail.Close())
+	entries, err := audit.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, audit.TLSHandshakeFailure, entries[0].Type)
+	assert.Equal(t, "127.0.0.1:1000", entries[0].Remote)
+}
+
+func TestInitializeSecureServerConfig(t *testing.T) {
+	initializeSecureServerConfig(
+		&config.TopLevel{
+			General: config.General{
+				TLS: config.TLS{
+					Enabled:           true,
+					ClientAuthEnabled: true,
+					Certificate:       "main.go",
+					PrivateKey:        "main.go",
+					RootCAs:           []string{"main.go"},
+					ClientRootCAs:     []string{"main.go"},
+				},
+			},
+		}, nil)
+
+	goodFile := "main.go"
+	badFile := "does_not_exist"
+
+	logger.SetBackend(logging.AddModuleLevel(newPanicOnCriticalBackend()))
+	defer func() {
+		logger = logging.MustGetLogger("orderer/main")
+	}()
+
+	testCases := []struct {
+		name              string
+		certificate       string
+		privateKey        string
+		rootCA            string
+		clientCertificate string
+	}{
+		{"BadCertificate", badFile, goodFile, goodFile, goodFile},
+		{"BadPrivateKey", goodFile, badFile, goodFile, goodFile},
+		{"BadRootCA", goodFile, goodFile, badFile, goodFile},
+		{"BadClientCertificate", goodFile, goodFile, goodFile, badFile},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Panics(t, func() {
+				initializeSecureServerConfig(
+					&config.TopLevel{
+						General: config.General{
+							TLS: config.TLS{
+								Enabled:           true,
+								ClientAuthEnabled: true,
+								Certificate:       tc.certificate,
+								PrivateKey:        tc.privateKey,
+								RootCAs:           []string{tc.rootCA},
+								ClientRootCAs:     []string{tc.clientCertificate},
+							},
+						},
+					}, nil)
+			},
+			)
+		})
+	}
+}
+
+func TestInitializeBootstrapChannel(t *testing.T) {
+	testCases := []struct {
+		genesisMethod string
+		ledgerType    string
+		panics        bool
+	}{
+		{"provisional", "ram", false},
+		{"provisional", "file", false},
+		{"provisional", "json", false},
+		{"invalid", "ram", true},
+		{"file", "ram", true},
+		{"deliver", "ram", true},
+	}
+
+	for _, tc := range testCases {
+
+		t.Run(tc.genesisMethod+"/"+tc.ledgerType, func(t *testing.T) {
+
+			fileLedgerLocation, _ := ioutil.TempDir("", "test-ledger")
+			ledgerFactory, _ := createLedgerFactory(
+				&config.TopLevel{
+					General: config.General{LedgerType: tc.ledgerType},
+					FileLedger: config.FileLedger{
+						Location: fileLedgerLocation,
+					},
+				},
+			)
+
+			bootstrapConfig := &config.TopLevel{
+				General: config.General{
+					GenesisMethod:  tc.genesisMethod,
+					GenesisProfile: "SampleSingleMSPSolo",
+					GenesisFile:    "genesisblock",
+				},
+			}
+
+			if tc.panics {
+				assert.Panics(t, func() {
+					initializeBootstrapChannel(bootstrapConfig, ledgerFactory)
+				})
+			} else {
+				initializeBootstrapChannel(bootstrapConfig, ledgerFactory)
+			}
+
+		})
+	}
+}
+
+func TestInitializeBootstrapChannelDeliver(t *testing.T) {
+	localMSPDir, _ := coreconfig.GetDevMspDir()
+	conf := &config.TopLevel{
+		General: config.General{
+			GenesisMethod: "deliver",
+			LocalMSPDir:   localMSPDir,
+			LocalMSPID:    "DEFAULT",
+			BCCSP: &factory.FactoryOpts{
+				ProviderName: "SW",
+				SwOpts: &factory.SwOpts{
+					HashFamily: "SHA2",
+					SecLevel:   256,
+					Ephemeral:  true,
+				},
+			},
+		},
+	}
+	initializeLocalMsp(conf)
+
+	existingConf := embeddedConfig()
+	existingConf.Signer = localmsp.NewSigner()
+	existing, err := New(existingConf)
+	require.NoError(t, err)
+	go existing.Start()
+	defer existing.Stop()
+
+	conf.General.GenesisDeliver = config.GenesisDeliver{
+		Address:       existing.Address(),
+		ChainID:       provisional.TestChainID,
+		Timeout:       5 * time.Second,
+		RetryInterval: 100 * time.Millisecond,
+	}
+	lf := ramledger.New(10)
+	initializeBootstrapChannel(conf, lf)
+	assert.Equal(t, []string{provisional.TestChainID}, lf.ChainIDs())
+
+	conf.General.GenesisDeliver.Hash = "00"
+	assert.Panics(t, func() { initializeBootstrapChannel(conf, ramledger.New(10)) })
+}
+
+func TestInitializeLocalMsp(t *testing.T) {
+	t.Run("Happy", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			localMSPDir, _ := coreconfig.GetDevMspDir()
+			initializeLocalMsp(
+				&config.TopLevel{
+					General: config.General{
+						LocalMSPDir: localMSPDir,
+						LocalMSPID:  "DEFAULT",
+						BCCSP: &factory.FactoryOpts{
+							ProviderName: "SW",
+							SwOpts: &factory.SwOpts{
+								HashFamily: "SHA2",
+								SecLevel:   256,
+								Ephemeral:  true,
+							},
+						},
+					},
+				})
+		})
+	})
+	t.Run("Error", func(t *testing.T) {
+		logger.SetBackend(logging.AddModuleLevel(newPanicOnCriticalBackend()))
+		defer func() {
+			logger = logging.MustGetLogger("orderer/main")
+		}()
+		assert.Panics(t, func() {
+			initializeLocalMsp(
+				&config.TopLevel{
+					General: config.General{
+						LocalMSPDir: "",
+						LocalMSPID:  "",
+					},
+				})
+		})
+	})
+}
+
+func TestInitializeMultiChainManager(t *testing.T) {
+	localMSPDir, _ := coreconfig.GetDevMspDir()
+	conf := &config.TopLevel{
+		General: config.General{
+			LedgerType:     "ram",
+			GenesisMethod:  "provisional",
+			GenesisProfile: "SampleSingleMSPSolo",
+			LocalMSPDir:    localMSPDir,
+			LocalMSPID:     "DEFAULT",
+			BCCSP: &factory.FactoryOpts{
+				ProviderName: "SW",
+				SwOpts: &factory.SwOpts{
+					HashFamily: "SHA2",
+					SecLevel:   256,
+					Ephemeral:  true,
+				},
+			},
+		},
+	}
+	assert.NotPanics(t, func() {
+		initializeLocalMsp(conf)
+		initializeMultiChainManager(conf, nil, nil, localmsp.NewSigner(), multichain.Config{})
+	})
+}
+
+func TestApplyDevMode(t *testing.T) {
+	conf := config.Load()
+	conf.General.TLS.Enabled = true
+	conf.General.Listeners = map[string]config.Listener{"peers": {TLS: config.TLS{Enabled: true, ClientAuthEnabled: true}}}
+	conf.General.Admin.Enabled = true
+	applyDevMode(conf)
+
+	assert.Equal(t, "ram", conf.General.LedgerType)
+	assert.Equal(t, "provisional", conf.General.GenesisMethod)
+	assert.Equal(t, genesisconfig.SampleInsecureProfile, conf.General.GenesisProfile)
+	assert.False(t, conf.General.TLS.Enabled)
+	assert.False(t, conf.General.Listeners["peers"].TLS.Enabled)
+	assert.False(t, conf.General.Listeners["peers"].TLS.ClientAuthEnabled)
+	assert.False(t, conf.General.Admin.Enabled)
+	assert.Equal(t, "debug", conf.General.LogLevel)
+
+	conf.General.ListenPort = 0
+	conf.General.Listeners = nil
+	orderer, err := New(Config{TopLevel: conf})
+	require.NoError(t, err)
+	defer orderer.Stop()
+	assert.Equal(t, []string{provisional.TestChainID}, orderer.Manager().ChannelIDs())
+}
+
+type mockManager struct {
+	multichain.Manager
+}
+
+func (mm mockManager) SystemChannelID() string {
+	return "missing"
+}
+
+func (mm mockManager) GetChain(chainID string) (multichain.ChainSupport, bool) {
+	return nil, false
+}
+
+func TestConsenterChecker(t *testing.T) {
+	localMSPDir, _ := coreconfig.GetDevMspDir()
+	conf := &config.TopLevel{
+		General: config.General{
+			LedgerType:     "ram",
+			GenesisMethod:  "provisional",
+			GenesisProfile: "SampleSingleMSPSolo",
+			LocalMSPDir:    localMSPDir,
+			LocalMSPID:     "DEFAULT",
+			BCCSP: &factory.FactoryOpts{
+				ProviderName: "SW",
+				SwOpts: &factory.SwOpts{
+					HashFamily: "SHA2",
+					SecLevel:   256,
+					Ephemeral:  true,
+				},
+			},
+		},
+	}
+	initializeLocalMsp(conf)
+	manager := initializeMultiChainManager(conf, nil, nil, localmsp.NewSigner(), multichain.Config{})
+	assert.NoError(t, consenterChecker(manager)(), "Solo consenter should always be available")
+
+	assert.EqualError(t, consenterChecker(mockManager{})(), "system channel missing not found")
+
+	assert.NoError(t, configChecker(manager)(), "The config of the system channel should be loaded")
+	assert.EqualError(t, configChecker(mockManager{})(), "system channel missing not found")
+}
+
+func TestLedgerChecker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ledger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ledgerChecker(dir)())
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files, "The check should not leave files in the ledger")
+
+	assert.Error(t, ledgerChecker(filepath.Join(dir, "missing"))())
+}
+
+func TestRegisterProbes(t *testing.T) {
+	registerProbes(nil, health.NewServer())
+
+	system := initializeOperationsSystem(&config.TopLevel{General: config.General{
+		Operations: config.Operations{Enabled: true, ListenAddress: "127.0.0.1:0"},
+	}}, pipeline.NewTimers())
+	require.NotNil(t, system)
+	defer system.Stop()
+
+	conf := &config.TopLevel{General: config.General{Operations: config.Operations{Health: config.Health{
+		FailureThreshold:  1,
+		FailureThresholds: map[string]int{"consenter": 2},
+	}}}}
+	healthServer := health.NewServer()
+	registerCheck(conf, healthServer, "consenter", health.Readiness, func() error { return fmt.Errorf("disconnected") })
+	registerCheck(conf, healthServer, "ledger", health.Liveness, func() error { return nil })
+	registerProbes(system, healthServer)
+	healthServer.SetReady()
+
+	probe := func(path string) int {
+		resp, err := http.Get("http://" + system.Addr() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, probe("/healthz"))
+	assert.Equal(t, http.StatusOK, probe("/readyz"), "A single failure should be below the threshold of the check")
+	assert.Equal(t, http.StatusServiceUnavailable, probe("/readyz"))
+}
+
+func TestInitializeHealthServer(t *testing.T) {
+	grpcServer := initializeGrpcServer(&config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}}, nil, nil)
+	healthServer := initializeHealthServer(grpcServer)
+	go grpcServer.Start()
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(grpcServer.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status, "Should not serve before startup completes")
+
+	healthServer.Register(atomicBroadcastService, func() error { return nil })
+	healthServer.SetReady()
+	resp, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: atomicBroadcastService})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestInitializeAdminServer(t *testing.T) {
+	certDir := filepath.Join("..", "..", "core", "comm", "testdata", "certs")
+	registered := func(conf *config.TopLevel) bool {
+		conf.General.ListenAddress = "127.0.0.1"
+		grpcServer := initializeGrpcServer(conf, nil, nil)
+		defer grpcServer.Listener().Close()
+		initializeAdminServer(conf, grpcServer, mockManager{}, nil, &admin.MaintenanceMode{}, func() {}, nil)
+		_, ok := grpcServer.Server().GetServiceInfo()["orderer.Admin"]
+		return ok
+	}
+
+	adminConf := config.Admin{Enabled: true, ClientRootCAs: []string{filepath.Join(certDir, "Org1-cert.pem")}}
+	tlsConf := config.TLS{
+		Enabled:     true,
+		Certificate: filepath.Join(certDir, "Org1-server1-cert.pem"),
+		PrivateKey:  filepath.Join(certDir, "Org1-server1-key.pem"),
+	}
+
+	assert.False(t, registered(&config.TopLevel{General: config.General{TLS: tlsConf}}), "Admin service is disabled by default")
+	assert.False(t, registered(&config.TopLevel{General: config.General{Admin: adminConf}}), "Admin service requires TLS")
+	assert.True(t, registered(&config.TopLevel{General: config.General{Admin: adminConf, TLS: tlsConf}}))
+}
+
+func TestGracefulStop(t *testing.T) {
+	grpcServer := initializeGrpcServer(&config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}}, nil, nil)
+	served := make(chan struct{})
+	go func() {
+		grpcServer.Start()
+		close(served)
+	}()
+
+	gracefulStop(grpcServer, time.Second)()
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Fatalf("Server should have stopped serving")
+	}
+}
+
+func TestDrainOnce(t *testing.T) {
+	calls := 0
+	release := make(chan struct{})
+	drain := drainOnce(func() {
+		<-release
+		calls++
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		drain()
+		close(finished)
+	}()
+	go drain()
+
+	close(release)
+	<-finished
+	drain()
+	assert.Equal(t, 1, calls, "Drain should only run once")
+}
+
+func TestGracefulStopTimeout(t *testing.T) {
+	grpcServer := initializeGrpcServer(&config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}}, nil, nil)
+	healthServer := initializeHealthServer(grpcServer)
+	healthServer.SetReady()
+	go grpcServer.Start()
+
+	conn, err := grpc.Dial(grpcServer.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	gracefulStop(grpcServer, 100*time.Millisecond)()
+	assert.True(t, time.Since(start) < time.Second, "Idle connections should not hold up the drain beyond its deadline")
+}
+
+func TestInitializeGrpcServers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orderer-socket")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "orderer.sock")
+
+	t.Run("TCPOnly", func(t *testing.T) {
+		endpoints := initializeGrpcServers(&config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}}, nil, nil)
+		assert.Len(t, endpoints, 1)
+		endpoints[0].Listener().Close()
+	})
+
+	t.Run("Exclusive", func(t *testing.T) {
+		defer comm.SetMaxRecvMsgSize(100 * 1024 * 1024)
+		endpoints := initializeGrpcServers(&config.TopLevel{General: config.General{
+			UnixSocket: config.UnixSocket{Path: socket, Mode: "0600", Exclusive: true},
+			Limits:     config.Limits{MaxRecvMsgSize: 1024},
+		}}, nil, nil)
+		assert.Len(t, endpoints, 1)
+		defer endpoints[0].Listener().Close()
+		assert.Equal(t, socket, endpoints[0].Address())
+		assert.Equal(t, 1024, comm.MaxRecvMsgSize(), "The limits should apply to the socket server")
+
+		info, err := os.Stat(socket)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("Additional", func(t *testing.T) {
+		// The socket left behind by the previous listener should be replaced
+		grpcServers := servers(initializeGrpcServers(&config.TopLevel{General: config.General{
+			ListenAddress: "127.0.0.1",
+			UnixSocket:    config.UnixSocket{Path: socket, Mode: "0660"},
+		}}, nil, nil))
+		assert.Len(t, grpcServers, 2)
+		healthServer := initializeHealthServer(grpcServers...)
+		healthServer.SetReady()
+		for _, s := range grpcServers {
+			go s.Start()
+		}
+		defer gracefulStopAll(grpcServers, time.Second)()
+
+		conn, err := grpc.Dial(socket, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second),
+			grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+				return net.DialTimeout("unix", addr, timeout)
+			}))
+		assert.NoError(t, err)
+		defer conn.Close()
+		resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	})
+
+	t.Run("Listeners", func(t *testing.T) {
+		endpoints := initializeGrpcServers(&config.TopLevel{General: config.General{
+			ListenAddress: "127.0.0.1",
+			Services:      []string{broadcastService},
+			Listeners: map[string]config.Listener{
+				"internal": {ListenAddress: "127.0.0.1", Services: []string{deliverService, adminService}},
+				"external": {ListenAddress: "127.0.0.1"},
+			},
+		}}, nil, nil)
+		assert.Len(t, endpoints, 3)
+		for _, e := range endpoints {
+			defer e.Listener().Close()
+		}
+		assert.Equal(t, "default", endpoints[0].name)
+		assert.Equal(t, "external", endpoints[1].name, "Listeners should follow the primary in order of name")
+		assert.Equal(t, "internal", endpoints[2].name)
+
+		assert.False(t, endpoints[0].exposes(adminService))
+		assert.True(t, endpoints[1].exposes(adminService), "All services should be offered if none are named")
+		assert.True(t, endpoints[2].exposes(deliverService))
+		assert.False(t, endpoints[2].exposes(broadcastService))
+	})
+}
+
+func TestInitializeGrpcServer(t *testing.T) {
+	// get a free random port
+	listenAddr := func() string {
+		l, _ := net.Listen("tcp", "localhost:0")
+		l.Close()
+		return l.Addr().String()
+	}()
+	host := strings.Split(listenAddr, ":")[0]
+	port, _ := strconv.ParseUint(strings.Split(listenAddr, ":")[1], 10, 16)
+	assert.NotPanics(t, func() {
+		grpcServer := initializeGrpcServer(
+			&config.TopLevel{
+				General: config.General{
+					ListenAddress: host,
+					ListenPort:    uint16(port),
+					TLS: config.TLS{
+						Enabled:           false,
+						ClientAuthEnabled: false,
+					},
+				},
+			}, nil, nil)
+		grpcServer.Listener().Close()
+	})
+}
+
+func TestInitializeTokenAuth(t *testing.T) {
+	assert.Nil(t, initializeTokenAuth(&config.TopLevel{}, nil), "Token authentication should be disabled by default")
+	assert.NotNil(t, initializeTokenAuth(&config.TopLevel{General: config.General{
+		TokenAuth: config.TokenAuth{
+			Enabled:            true,
+			IssuerCertificates: []string{filepath.Join("..", "..", "core", "comm", "testdata", "certs", "Org1-cert.pem")},
+		},
+	}}, nil))
+}
+
+func TestInitializeThrottle(t *testing.T) {
+	assert.Nil(t, initializeThrottle(&config.TopLevel{}), "No throttle should be installed by default")
+	assert.NotNil(t, initializeThrottle(&config.TopLevel{General: config.General{
+		Throttle: config.Throttle{Burst: 5, PerIP: config.ThrottleRates{StreamRate: 10}},
+	}}))
+}
+
+func TestInitializePriority(t *testing.T) {
+	assert.Equal(t, priority.Config{DefaultEntitlement: ab.PriorityClass_NORMAL}, initializePriority(&config.TopLevel{}))
+
+	assert.Equal(t, priority.Config{
+		DefaultEntitlement: ab.PriorityClass_LOW,
+		Entitlements:       map[string]ab.PriorityClass{"OrdererMSP": ab.PriorityClass_CONTROL},
+	}, initializePriority(&config.TopLevel{General: config.General{Priority: config.Priority{
+		DefaultEntitlement: "LOW",
+		Entitlements:       map[string]string{"OrdererMSP": "CONTROL"},
+	}}}))
+
+	assert.Panics(t, func() {
+		initializePriority(&config.TopLevel{General: config.General{Priority: config.Priority{
+			Entitlements: map[string]string{"OrdererMSP": "URGENT"},
+		}}})
+	})
+}
+
+func TestInitializeExtensions(t *testing.T) {
+	validated := false
+	conf := initializeExtensions(&config.TopLevel{General: config.General{Extensions: config.Extensions{MaxBytes: 16}}}, func(string, []byte) error {
+		validated = true
+		return nil
+	})
+	assert.Equal(t, uint32(16), conf.MaxBytes)
+	require.NotNil(t, conf.Validator)
+	conf.Validator("foo", nil)
+	assert.True(t, validated, "The validator should be set")
+}
+
+func TestInitializeReceipts(t *testing.T) {
+	assert.Nil(t, initializeReceipts(&config.TopLevel{}), "No receipts should be kept by default")
+
+	dir, err := ioutil.TempDir("", "receipts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	log := initializeReceipts(&config.TopLevel{General: config.General{Receipts: config.Receipts{
+		Enabled: true,
+		File:    filepath.Join(dir, "receipts"),
+		Size:    10,
+	}}})
+	assert.NotNil(t, log)
+	assert.NoError(t, log.Close())
+
+	assert.Panics(t, func() {
+		initializeReceipts(&config.TopLevel{General: config.General{Receipts: config.Receipts{
+			Enabled: true,
+			File:    filepath.Join(dir, "missing", "receipts"),
+		}}})
+	})
+}
+
+func TestInitializeTracing(t *testing.T) {
+	assert.Nil(t, initializeTracing(&config.TopLevel{}), "No transaction should be traced by default")
+
+	tracer := initializeTracing(&config.TopLevel{General: config.General{Tracing: config.Tracing{Enabled: true}}})
+	assert.NotNil(t, tracer)
+	assert.NoError(t, tracer.Close(), "Closing a tracer logging its spans should succeed")
+
+	dir, err := ioutil.TempDir("", "tracing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tracer = initializeTracing(&config.TopLevel{General: config.General{Tracing: config.Tracing{
+		Enabled:    true,
+		SampleRate: 0.5,
+		File:       filepath.Join(dir, "spans"),
+	}}})
+	assert.NotNil(t, tracer)
+	assert.NoError(t, tracer.Close())
+	_, err = os.Stat(filepath.Join(dir, "spans"))
+	assert.NoError(t, err, "The trace file should have been created")
+
+	assert.Panics(t, func() {
+		initializeTracing(&config.TopLevel{General: config.General{Tracing: config.Tracing{
+			Enabled: true,
+			File:    filepath.Join(dir, "missing", "spans"),
+		}}})
+	})
+}
+
+func TestInitializeStreamLimiter(t *testing.T) {
+	assert.Nil(t, initializeStreamLimiter(&config.TopLevel{}), "No stream ceilings should be installed by default")
+	_, stream := initializeInterceptors(&config.TopLevel{General: config.General{
+		Limits: config.Limits{StreamByteRate: 1024 * 1024},
+	}}, nil).chains(nil)
+	assert.Len(t, stream, len(interceptor.StreamInterceptors())+1)
+}
+
+func TestSharedInterceptors(t *testing.T) {
+	conf := &config.TopLevel{General: config.General{
+		ListenAddress: "127.0.0.1",
+		Listeners:     map[string]config.Listener{"external": {ListenAddress: "127.0.0.1"}},
+		Throttle:      config.Throttle{Burst: 1, PerIP: config.ThrottleRates{RPCRate: 0.001}},
+	}}
+	grpcServers := servers(initializeGrpcServers(conf, nil, initializeInterceptors(conf, nil)))
+	require.Len(t, grpcServers, 2)
+	initializeHealthServer(grpcServers...)
+	for _, s := range grpcServers {
+		go s.Start()
+	}
+	defer gracefulStopAll(grpcServers, time.Second)()
+
+	check := func(s comm.GRPCServer) error {
+		conn, err := grpc.Dial(s.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err
+	}
+	assert.NoError(t, check(grpcServers[0]))
+	assert.Equal(t, codes.ResourceExhausted, grpc.Code(check(grpcServers[1])), "The client should not escape its rate limit on another listener")
+}
+
+func TestListenerTokenAuth(t *testing.T) {
+	conf := &config.TopLevel{General: config.General{
+		ListenAddress: "127.0.0.1",
+		Listeners: map[string]config.Listener{"external": {
+			ListenAddress: "127.0.0.1",
+			TokenAuth:     &config.ListenerTokenAuth{Enabled: true, Required: true},
+		}},
+		TokenAuth: config.TokenAuth{
+			Enabled:            true,
+			IssuerCertificates: []string{filepath.Join("..", "..", "core", "comm", "testdata", "certs", "Org1-cert.pem")},
+		},
+	}}
+	grpcServers := servers(initializeGrpcServers(conf, nil, initializeInterceptors(conf, nil)))
+	require.Len(t, grpcServers, 2)
+	initializeHealthServer(grpcServers...)
+	for _, s := range grpcServers {
+		go s.Start()
+	}
+	defer gracefulStopAll(grpcServers, time.Second)()
+
+	check := func(s comm.GRPCServer) error {
+		conn, err := grpc.Dial(s.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err
+	}
+	assert.NoError(t, check(grpcServers[0]), "The primary listener should not require a token")
+	assert.Equal(t, codes.Unauthenticated, grpc.Code(check(grpcServers[1])), "The listener should require a token")
+
+	conf.General.TokenAuth.Enabled = false
+	assert.Panics(t, func() {
+		initializeListenerServer(conf, "external", conf.General.Listeners["external"], nil, nil)
+	}, "Token authentication cannot be enabled on a listener without issuers")
+}
+
+func TestInitializeServerLimits(t *testing.T) {
+	defer func() {
+		comm.SetMaxRecvMsgSize(100 * 1024 * 1024)
+		comm.SetMaxSendMsgSize(100 * 1024 * 1024)
+		comm.SetMaxConcurrentStreams(0)
+	}()
+
+	initializeServerLimits(
+		&config.TopLevel{
+			General: config.General{
+				Limits: config.Limits{
+					MaxRecvMsgSize:       1024,
+					MaxConcurrentStreams: 10,
+				},
+			},
+		})
+	assert.Equal(t, 1024, comm.MaxRecvMsgSize())
+	assert.Equal(t, 100*1024*1024, comm.MaxSendMsgSize(), "Unset limit should keep the default")
+	assert.Equal(t, uint32(10), comm.MaxConcurrentStreams())
+}
+
+func TestInitializeKeepaliveOptions(t *testing.T) {
+	ka := initializeKeepaliveOptions(
+		&config.TopLevel{
+			General: config.General{
+				Keepalive: config.Keepalive{
+					ServerMinInterval:           30 * time.Second,
+					ServerInterval:              time.Hour,
+					ServerTimeout:               10 * time.Second,
+					ServerMaxConnectionIdle:     5 * time.Minute,
+					ServerMaxConnectionAge:      time.Hour,
+					ServerMaxConnectionAgeGrace: time.Minute,
+				},
+			},
+		})
+	assert.Equal(t, 30, ka.ClientKeepaliveTime)
+	assert.Equal(t, 3600, ka.ServerKeepaliveTime)
+	assert.Equal(t, 10, ka.ServerKeepaliveTimeout)
+	assert.Equal(t, 300, ka.ServerMaxConnectionIdle)
+	assert.Equal(t, 3600, ka.ServerMaxConnectionAge)
+	assert.Equal(t, 60, ka.ServerMaxConnectionAgeGrace)
+	assert.Equal(t, comm.DefaultKeepaliveOptions().ClientKeepaliveTimeout, ka.ClientKeepaliveTimeout)
+}
+
+// var originalLogger *Logger
+
+func newPanicOnCriticalBackend() *panicOnCriticalBackend {
+	return &panicOnCriticalBackend{
+		backend: logging.AddModuleLevel(logging.NewLogBackend(os.Stderr, "", log.LstdFlags)),
+	}
+}
+
+type panicOnCriticalBackend struct {
+	backend logging.Backend
+}
+
+func (b *panicOnCriticalBackend) Log(level logging.Level, calldepth int, record *logging.Record) error {
+	err := b.backend.Log(level, calldepth, record)
+	if level == logging.CRITICAL {
+		panic(record.Formatted(calldepth))
+	}
+	return err
+}
+
+func TestRegisterAtomicBroadcast(t *testing.T) {
+	for _, testCase := range []struct {
+		services   []string
+		registered bool
+	}{
+		{nil, true},
+		{[]string{broadcastService}, true},
+		{[]string{deliverService}, true},
+		{[]string{adminService}, false},
+	} {
+		grpcServer := initializeGrpcServer(&config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}}, nil, nil)
+		registerAtomicBroadcast(&endpoint{GRPCServer: grpcServer, services: testCase.services}, &mockAtomicBroadcastServer{})
+		_, ok := grpcServer.Server().GetServiceInfo()["orderer.AtomicBroadcast"]
+		assert.Equal(t, testCase.registered, ok, "Unexpected registration for services %v", testCase.services)
+		_, ok = grpcServer.Server().GetServiceInfo()["orderer.v2.AtomicBroadcast"]
+		assert.Equal(t, testCase.registered, ok, "Version 2 should be registered along with version 1")
+		grpcServer.Listener().Close()
+	}
+}
+
+func TestRegisterBatchBroadcast(t *testing.T) {
+	for _, testCase := range []struct {
+		services   []string
+		registered bool
+	}{
+		{nil, true},
+		{[]string{broadcastService}, true},
+		{[]string{deliverService}, false},
+	} {
+		grpcServer := initializeGrpcServer(&config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}}, nil, nil)
+		registerBatchBroadcast(&endpoint{GRPCServer: grpcServer, services: testCase.services}, &server{})
+		_, ok := grpcServer.Server().GetServiceInfo()["orderer.BatchBroadcast"]
+		assert.Equal(t, testCase.registered, ok, "Unexpected registration for services %v", testCase.services)
+		grpcServer.Listener().Close()
+	}
+}
+
+func TestRegisterChannelJoin(t *testing.T) {
+	for _, testCase := range []struct {
+		enabled    bool
+		services   []string
+		registered bool
+	}{
+		{false, nil, false},
+		{true, nil, true},
+		{true, []string{broadcastService}, false},
+		{true, []string{deliverService}, true},
+	} {
+		conf := &config.TopLevel{General: config.General{ListenAddress: "127.0.0.1", JoinTokens: config.JoinTokens{Enabled: testCase.enabled}}}
+		grpcServer := initializeGrpcServer(conf, nil, nil)
+		registerChannelJoin(&endpoint{GRPCServer: grpcServer, services: testCase.services}, conf, nil, nil, nil)
+		_, ok := grpcServer.Server().GetServiceInfo()["orderer.ChannelJoin"]
+		assert.Equal(t, testCase.registered, ok, "Unexpected registration for services %v", testCase.services)
+		grpcServer.Listener().Close()
+	}
+}
+
+func TestInitializeReflection(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		conf := &config.TopLevel{General: config.General{ListenAddress: "127.0.0.1", GRPC: config.GRPC{Reflection: enabled}}}
+		grpcServer := initializeGrpcServer(conf, nil, nil)
+		initializeReflection(conf, grpcServer)
+		_, ok := grpcServer.Server().GetServiceInfo()["grpc.reflection.v1alpha.ServerReflection"]
+		assert.Equal(t, enabled, ok)
+		grpcServer.Listener().Close()
+	}
+}
+
+func TestRegisterTransactionStatus(t *testing.T) {
+	for _, testCase := range []struct {
+		services   []string
+		registered bool
+	}{
+		{nil, true},
+		{[]string{broadcastService}, false},
+		{[]string{deliverService}, true},
+	} {
+		grpcServer := initializeGrpcServer(&config.TopLevel{General: config.General{ListenAddress: "127.0.0.1"}}, nil, nil)
+		registerTransactionStatus(&endpoint{GRPCServer: grpcServer, services: testCase.services}, nil, nil, nil)
+		_, ok := grpcServer.Server().GetServiceInfo()["orderer.TransactionStatus"]
+		assert.Equal(t, testCase.registered, ok, "Unexpected registration for services %v", testCase.services)
+		grpcServer.Listener().Close()
+	}
+}
@@ -0,0 +1,456 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/client"
+	"github.com/hyperledger/fabric/orderer/common/admin"
+	"github.com/hyperledger/fabric/orderer/common/audit"
+	"github.com/hyperledger/fabric/orderer/common/backpressure"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/chaos"
+	"github.com/hyperledger/fabric/orderer/common/compression"
+	"github.com/hyperledger/fabric/orderer/common/cosign"
+	"github.com/hyperledger/fabric/orderer/common/deliver"
+	"github.com/hyperledger/fabric/orderer/common/events"
+	"github.com/hyperledger/fabric/orderer/common/extensions"
+	"github.com/hyperledger/fabric/orderer/common/health"
+	"github.com/hyperledger/fabric/orderer/common/hlc"
+	"github.com/hyperledger/fabric/orderer/common/latency"
+	"github.com/hyperledger/fabric/orderer/common/memory"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/operations"
+	"github.com/hyperledger/fabric/orderer/common/pipeline"
+	"github.com/hyperledger/fabric/orderer/common/receipts"
+	"github.com/hyperledger/fabric/orderer/common/recording"
+	"github.com/hyperledger/fabric/orderer/common/rpcstatus"
+	"github.com/hyperledger/fabric/orderer/common/scheduler"
+	"github.com/hyperledger/fabric/orderer/common/sdnotify"
+	"github.com/hyperledger/fabric/orderer/common/tracing"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
+	"github.com/hyperledger/fabric/orderer/follower"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	"github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/multichain"
+)
+
+// Config is the configuration of an orderer created with New. Only TopLevel
+// is required, the other fields replace the components which the orderer
+// would otherwise create from it.
+type Config struct {
+	// TopLevel is the orderer configuration, as loaded by config.Load
+	TopLevel *config.TopLevel
+	// LedgerFactory, if set, holds the chains instead of the ledger
+	// configured by General.LedgerType. It is bootstrapped with the genesis
+	// block if it holds no chain.
+	LedgerFactory ledger.Factory
+	// Consenters, if set, replace the solo and kafka consenters, by
+	// consensus type
+	Consenters map[string]multichain.Consenter
+	// Signer, if set, signs on behalf of the orderer instead of the local
+	// MSP, which is then not loaded
+	Signer crypto.LocalSigner
+	// ExtensionValidator, if set, validates the extensions of the messages
+	// received, in addition to their configured size limit
+	ExtensionValidator extensions.Validator
+	// Audit, if set, records the security relevant events of the orderer
+	Audit *audit.Trail
+	// Pipeline, if set, times the stages of the messages broadcast and the
+	// blocks delivered
+	Pipeline *pipeline.Timers
+	// Metrics, if set, creates the metrics of the broadcast and deliver
+	// streams, of the chains and of the components they share instead of
+	// the default registry
+	Metrics metrics.Provider
+	// Notifier, if set, is reported the lifecycle of the orderer instead of
+	// the service manager named by the environment, such as by the service
+	// control handler of a Windows service
+	Notifier *sdnotify.Notifier
+}
+
+// Orderer is a complete ordering service node, serving the AtomicBroadcast,
+// health and admin services on its configured listeners. In the archive and
+// replica roles it follows the chains of the ordering service instead of
+// ordering, and only serves Deliver.
+type Orderer struct {
+	endpoints    []*endpoint
+	grpcServers  []comm.GRPCServer
+	healthServer *health.Server
+	manager      multichain.Manager
+	verifier     *broadcast.Verifier
+	follower     *follower.Follower
+	upstream     *client.Client
+	publisher    *events.Publisher
+	gateway      *gateway
+	receipts     *receipts.Log
+	tracer       *tracing.Tracer
+	cosigner     *cosign.Cosigner
+	notifier     *sdnotify.Notifier
+	stopped      chan struct{}
+	drain        func()
+	halt         sync.Once
+}
+
+// New creates an orderer from the configuration, bootstrapping the system
+// channel if needed, unless it is in a role which follows the chains of the
+// ordering service. Its listeners are open once it returns, but requests are
+// only served after Start is called.
+func New(conf Config) (o *Orderer, err error) {
+	if conf.TopLevel == nil {
+		return nil, fmt.Errorf("the orderer configuration is required")
+	}
+	notifier := conf.Notifier
+	if notifier == nil {
+		notifier = sdnotify.NewNotifier()
+	}
+	o = &Orderer{notifier: notifier, stopped: make(chan struct{})}
+	// The initialization of each component panics on failure, as the
+	// orderer binary exits on a misconfiguration
+	defer func() {
+		if r := recover(); r != nil {
+			for _, grpcServer := range o.grpcServers {
+				grpcServer.Listener().Close()
+			}
+			if o.manager != nil {
+				o.manager.Halt()
+			}
+			o.gateway.stop()
+			o.cosigner.Close()
+			o.receipts.Close()
+			o.tracer.Close()
+			if o.verifier != nil {
+				o.verifier.Stop()
+			}
+			if o.follower != nil {
+				o.follower.Stop()
+				o.upstream.Close()
+			}
+			o, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	signer := conf.Signer
+	if signer == nil {
+		initializeLocalMsp(conf.TopLevel)
+		signer = localmsp.NewSigner()
+	}
+	// The interceptors are shared by all the servers
+	o.endpoints = initializeGrpcServers(conf.TopLevel, conf.Audit, initializeInterceptors(conf.TopLevel, conf.Audit))
+	o.grpcServers = servers(o.endpoints)
+	o.healthServer = initializeHealthServer(o.grpcServers...)
+	// Ledgers in temporary directories, or supplied by the caller, are not
+	// checked
+	if conf.LedgerFactory == nil && conf.TopLevel.General.LedgerType != "ram" && conf.TopLevel.FileLedger.Location != "" {
+		registerCheck(conf.TopLevel, o.healthServer, "ledger", health.Liveness, ledgerChecker(conf.TopLevel.FileLedger.Location))
+	}
+	stopAll := gracefulStopAll(o.grpcServers, conf.TopLevel.General.DrainTimeout)
+	o.drain = drainOnce(func() {
+		o.notifier.Report(sdnotify.Stopping, "Draining connections")
+		stopAll()
+	})
+	status := rpcstatus.Config{
+		StreamErrors: conf.TopLevel.General.GRPC.StreamErrors,
+		RetryDelay:   conf.TopLevel.General.GRPC.RetryDelay,
+	}
+	deliverConf := initializeDeliver(conf, status)
+	switch conf.TopLevel.General.Role {
+	case "archive", "replica":
+		o.initializeFollowing(conf, signer, deliverConf)
+	default:
+		o.initializeOrderer(conf, signer, status, deliverConf)
+	}
+	// Reflection describes the services registered on each server
+	initializeReflection(conf.TopLevel, o.grpcServers...)
+	return o, nil
+}
+
+// initializeDeliver returns the configuration of the deliver streams, which
+// report their errors as configured by status
+func initializeDeliver(conf Config, status rpcstatus.Config) deliver.Config {
+	deliverConf := conf.TopLevel.General.Deliver
+	algorithms, err := compression.ParseAll(deliverConf.Compression)
+	if err != nil {
+		logger.Panicf("Invalid General.Deliver.Compression: %s", err)
+	}
+	return deliver.Config{
+		RevalidationInterval: deliverConf.RevalidationInterval,
+		MaxBatchBytes:        deliverConf.MaxBatchBytes,
+		RequireTLSBinding:    deliverConf.RequireTLSBinding,
+		Limits: deliver.Limits{
+			MaxStreams:          deliverConf.MaxStreams,
+			MaxStreamsPerClient: deliverConf.MaxStreamsPerClient,
+			BytesPerSecond:      deliverConf.MaxBytesPerSecond,
+		},
+		HeartbeatInterval: deliverConf.HeartbeatInterval,
+		Compression:       algorithms,
+		Pipeline:          conf.Pipeline,
+		Status:            status,
+		Audit:             conf.Audit,
+		Metrics:           conf.Metrics,
+	}
+}
+
+// initializeOrderer creates the chains and serves them as an ordering
+// service node
+func (o *Orderer) initializeOrderer(conf Config, signer crypto.LocalSigner, status rpcstatus.Config, deliverConf deliver.Config) {
+	general := conf.TopLevel.General
+	// The chains and the broadcast streams share the accounting of the
+	// memory held by pending messages, and the tracking of transactions
+	accountant := memory.NewAccountant(general.Limits.MaxPendingBytes, conf.Metrics)
+	tracker := latency.NewTracker(latency.DefaultExpiry, latency.DefaultMaxPending, conf.Pipeline, conf.Metrics)
+	faults := make(map[string]chaos.Faults, len(general.Chaos))
+	for chainID, c := range general.Chaos {
+		faults[chainID] = chaos.Faults{
+			AppendErrorAt:  c.AppendErrorAt,
+			ConsenterStall: c.ConsenterStall,
+			FilterTimeout:  c.FilterTimeout,
+		}
+	}
+	priorities := initializePriority(conf.TopLevel)
+	o.cosigner = initializeCosign(conf.TopLevel)
+	o.receipts = initializeReceipts(conf.TopLevel)
+	o.tracer = initializeTracing(conf.TopLevel)
+	o.manager = initializeMultiChainManager(conf.TopLevel, conf.LedgerFactory, conf.Consenters, signer, multichain.Config{
+		Backpressure: backpressure.Config{
+			SlowAppendLatency:   general.Backpressure.SlowAppendLatency,
+			MaxAppendLatency:    general.Backpressure.MaxAppendLatency,
+			MaxQueueDepth:       general.Backpressure.MaxQueueDepth,
+			MaxUrgentQueueDepth: general.Backpressure.MaxUrgentQueueDepth,
+		},
+		Recording: recording.Config{
+			Directory: general.Recording.Directory,
+			Chains:    general.Recording.Channels,
+		},
+		Faults: faults,
+		Scheduler: scheduler.New(scheduler.Config{
+			Slots:         general.Scheduler.Slots,
+			Weights:       general.Scheduler.Weights,
+			DefaultWeight: general.Scheduler.DefaultWeight,
+			Metrics:       conf.Metrics,
+		}),
+		Priorities: priorities,
+		Extensions: initializeExtensions(conf.TopLevel, conf.ExtensionValidator),
+		TxStatus: txstatus.Config{
+			Size:          general.TxStatus.IndexSize,
+			PendingExpiry: general.TxStatus.PendingExpiry,
+		},
+		Timestamps: hlc.Config{Transactions: general.Timestamps.PerTransaction},
+		Cosigner:   o.cosigner,
+		Memory:     accountant,
+		Pipeline:   conf.Pipeline,
+		Latency:    tracker,
+		Tracer:     o.tracer,
+		Audit:      conf.Audit,
+		Metrics:    conf.Metrics,
+	})
+	o.publisher = initializeEventPublisher(conf.TopLevel, eventsSupport{Manager: o.manager}, conf.Metrics)
+
+	maintenance := &admin.MaintenanceMode{}
+	o.verifier = broadcast.NewVerifier(general.Broadcast.VerifyWorkers)
+	// Receipts are the signed responses to the broadcasts accepted
+	signResponses := general.Broadcast.SignResponses || general.Receipts.Enabled
+	server := NewServer(o.manager, signer, maintenance, o.verifier, signResponses, broadcast.Config{
+		Priorities: priorities,
+		Receipts:   o.receipts,
+		RateLimits: broadcast.RateLimits{
+			PerConnection: general.Broadcast.RateLimit.PerConnection,
+			PerIdentity:   general.Broadcast.RateLimit.PerIdentity,
+			Burst:         general.Broadcast.RateLimit.Burst,
+		},
+		CommitTimeout:    general.Broadcast.CommitTimeout,
+		MaxBatchMessages: general.Broadcast.MaxBatchMessages,
+		Memory:           accountant,
+		Pipeline:         conf.Pipeline,
+		Latency:          tracker,
+		Tracer:           o.tracer,
+		Status:           status,
+		Audit:            conf.Audit,
+		Metrics:          conf.Metrics,
+	}, deliverConf)
+	o.gateway = initializeGateway(conf.TopLevel, server)
+	for _, e := range o.endpoints {
+		registerAtomicBroadcast(e, server)
+		registerBatchBroadcast(e, server)
+		registerTransactionStatus(e, o.manager, o.receipts, conf.Audit)
+		registerChannelJoin(e, conf.TopLevel, o.manager, signer, conf.Audit)
+		registerBlockCosigner(e, o.manager, signer, o.cosigner)
+		if e.exposes(adminService) {
+			initializeAdminServer(conf.TopLevel, e, o.manager, signer, maintenance, o.drain, conf.Audit)
+		}
+	}
+	o.healthServer.Register(atomicBroadcastService, consenterChecker(o.manager))
+	registerCheck(conf.TopLevel, o.healthServer, "consenter", health.Readiness, consenterChecker(o.manager))
+	registerCheck(conf.TopLevel, o.healthServer, "config", health.Readiness, configChecker(o.manager))
+}
+
+// initializeFollowing follows the chains of the ordering service and serves
+// their blocks as an archive node or read replica, depending on the role. The
+// admin service, which manages chains, is not offered.
+func (o *Orderer) initializeFollowing(conf Config, signer crypto.LocalSigner, deliverConf deliver.Config) {
+	o.follower, o.upstream = initializeFollower(conf.TopLevel, conf.LedgerFactory, signer)
+	o.publisher = initializeEventPublisher(conf.TopLevel, o.follower, conf.Metrics)
+
+	general := conf.TopLevel.General
+	server := NewArchiveServer(o.follower, deliverConf)
+	if general.Role == "replica" {
+		server = NewReplicaServer(o.follower, general.Follower.Endpoints, deliverConf)
+	}
+	o.gateway = initializeGateway(conf.TopLevel, server)
+	for _, e := range o.endpoints {
+		registerAtomicBroadcast(e, server)
+	}
+	if general.Admin.Enabled {
+		logger.Warningf("Not starting the admin service because it is not offered by %s nodes", general.Role)
+	}
+	o.healthServer.Register(atomicBroadcastService, followerChecker(o.follower, general.Follower.ChainID))
+	// The config of the system channel is loaded once it is replicated
+	registerCheck(conf.TopLevel, o.healthServer, "config", health.Readiness, followerChecker(o.follower, general.Follower.ChainID))
+}
+
+// Address returns the address of the primary listener of the orderer
+func (o *Orderer) Address() string {
+	return o.grpcServers[0].Address()
+}
+
+// Manager returns the manager of the chains of the orderer, which is nil in
+// the archive and replica roles
+func (o *Orderer) Manager() multichain.Manager {
+	return o.manager
+}
+
+// Follower returns the follower of the chains of an orderer in the archive or
+// replica role, which is nil otherwise
+func (o *Orderer) Follower() *follower.Follower {
+	return o.follower
+}
+
+// Start serves requests on every listener of the orderer until it is
+// stopped, returning once the streams have drained and the chains have halted
+func (o *Orderer) Start() error {
+	if o.follower != nil {
+		o.follower.Start()
+	}
+	if o.publisher != nil {
+		o.publisher.Start()
+	}
+	o.gateway.start()
+	o.healthServer.SetReady()
+	// The service manager tracks the readiness reported by the health
+	// service, and restarts the orderer if the watchdog is no longer pinged
+	o.notifier.Report(sdnotify.Ready, "Serving requests")
+	go o.notifier.RunWatchdog(o.healthServer.Ready, o.stopped)
+	logger.Info("Beginning to serve requests")
+	for _, s := range o.grpcServers[1:] {
+		go s.Start()
+	}
+	err := o.grpcServers[0].Start()
+	// Start returns as soon as the listener closes, wait for the
+	// streams to drain before halting the chains beneath them
+	o.Stop()
+	return err
+}
+
+// Stop stops accepting connections, waits for the open streams to drain,
+// up to General.DrainTimeout, and halts or stops following the chains
+func (o *Orderer) Stop() {
+	o.drain()
+	o.halt.Do(func() {
+		close(o.stopped)
+		// Servers which were never started do not close their listeners
+		for _, grpcServer := range o.grpcServers {
+			grpcServer.Listener().Close()
+		}
+		o.gateway.stop()
+		if o.publisher != nil {
+			o.publisher.Stop()
+		}
+		if o.follower != nil {
+			o.follower.Stop()
+			o.upstream.Close()
+			return
+		}
+		o.verifier.Stop()
+		o.manager.Halt()
+		// Once the chains halt, no block is cosigned anymore
+		o.cosigner.Close()
+		if err := o.receipts.Close(); err != nil {
+			logger.Warningf("Failed to close receipts log: %s", err)
+		}
+		if err := o.tracer.Close(); err != nil {
+			logger.Warningf("Failed to close trace file: %s", err)
+		}
+	})
+}
+
+// gateway serves the HTTP gateway to the Deliver service. A nil gateway is
+// not enabled.
+type gateway struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// initializeGateway listens for the HTTP gateway to the Deliver service of
+// the server, if enabled, returning nil otherwise. The clients of the gateway
+// are authorized by their TLS client certificates, so it requires TLS client
+// authentication.
+func initializeGateway(conf *config.TopLevel, server deliver.Service) *gateway {
+	gatewayConf := conf.General.Gateway
+	if !gatewayConf.Enabled {
+		return nil
+	}
+	if !gatewayConf.TLS.Enabled || !gatewayConf.TLS.ClientAuthEnabled {
+		logger.Panicf("The gateway requires TLS client authentication, by which its clients are authorized")
+	}
+	tlsConfig, err := operations.TLS{
+		Enabled:            gatewayConf.TLS.Enabled,
+		CertFile:           gatewayConf.TLS.Certificate,
+		KeyFile:            gatewayConf.TLS.PrivateKey,
+		ClientCertRequired: gatewayConf.TLS.ClientAuthEnabled,
+		ClientRootCAs:      gatewayConf.TLS.ClientRootCAs,
+	}.Config()
+	if err != nil {
+		logger.Panicf("Failed to configure the gateway listener: %s", err)
+	}
+	listener, err := net.Listen("tcp", gatewayConf.ListenAddress)
+	if err != nil {
+		logger.Panicf("Failed to listen for the gateway on %s: %s", gatewayConf.ListenAddress, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/channels/", deliver.NewGateway(server))
+	return &gateway{server: &http.Server{Handler: mux}, listener: tls.NewListener(listener, tlsConfig)}
+}
+
+// start serves the gateway in the background
+func (g *gateway) start() {
+	if g == nil {
+		return
+	}
+	logger.Infof("Serving the Deliver gateway on %s", g.listener.Addr())
+	go func() {
+		if err := g.server.Serve(g.listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Gateway server failed: %s", err)
+		}
+	}()
+}
+
+// stop closes the listener of the gateway and its connections
+func (g *gateway) stop() {
+	if g == nil {
+		return
+	}
+	g.server.Close()
+	g.listener.Close()
+}
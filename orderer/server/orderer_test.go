@@ -0,0 +1,329 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	coreconfig "github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/orderer/cli"
+	"github.com/hyperledger/fabric/orderer/common/events"
+	"github.com/hyperledger/fabric/orderer/common/health"
+	"github.com/hyperledger/fabric/orderer/common/sdnotify"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	config "github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	"github.com/hyperledger/fabric/orderer/solo"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func embeddedConfig() Config {
+	return Config{
+		TopLevel: &config.TopLevel{General: config.General{
+			ListenAddress:  "127.0.0.1",
+			GenesisMethod:  "provisional",
+			GenesisProfile: genesisconfig.SampleInsecureProfile,
+			DrainTimeout:   time.Second,
+		}},
+		LedgerFactory: ramledger.New(10),
+		Consenters:    map[string]multichain.Consenter{"solo": solo.New()},
+		Signer:        mockcrypto.FakeLocalSigner,
+	}
+}
+
+func TestNew(t *testing.T) {
+	conf := embeddedConfig()
+	orderer, err := New(conf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{provisional.TestChainID}, conf.LedgerFactory.ChainIDs(), "System channel should be bootstrapped in the supplied ledger")
+
+	started := make(chan error)
+	go func() {
+		started <- orderer.Start()
+	}()
+
+	conn, err := grpc.Dial(orderer.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(5*time.Second))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := ab.NewAtomicBroadcastClient(conn)
+
+	env, err := cli.NewEnvelope(cb.HeaderType_MESSAGE, provisional.TestChainID, []byte("embedded"), nil)
+	require.NoError(t, err)
+	resp, err := cli.Broadcast(client, env, cli.RetryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_SUCCESS, resp.Status)
+
+	seekInfo, err := cli.ParseSeek("1", nil)
+	require.NoError(t, err)
+	var blocks []*cb.Block
+	err = cli.Fetch(client, provisional.TestChainID, seekInfo, nil, func(block *cb.Block) error {
+		blocks = append(blocks, block)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Len(t, blocks[0].Data.Data, 1, "Block should hold the broadcast message")
+
+	results, ready := orderer.healthServer.Run(health.Readiness)
+	assert.True(t, ready, "The orderer should be ready once serving")
+	assert.Equal(t, []health.Result{{Name: "startup"}, {Name: "config"}, {Name: "consenter"}}, results)
+
+	orderer.Stop()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start should return once the orderer is stopped")
+	}
+	orderer.Stop()
+}
+
+func TestNewEvents(t *testing.T) {
+	published := make(chan string, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		published <- r.Header.Get(events.HeaderChannel) + "/" + r.Header.Get(events.HeaderNumber)
+	}))
+	defer webhook.Close()
+
+	conf := embeddedConfig()
+	conf.TopLevel.General.Events = config.Events{
+		Enabled:      true,
+		Payload:      events.FormatHeader,
+		RetryInitial: time.Millisecond,
+		Webhook:      config.EventsWebhook{URL: webhook.URL, Timeout: time.Second},
+	}
+	orderer, err := New(conf)
+	require.NoError(t, err)
+	go orderer.Start()
+	defer orderer.Stop()
+
+	select {
+	case event := <-published:
+		assert.Equal(t, provisional.TestChainID+"/0", event, "Genesis block should be published")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Genesis block was not published")
+	}
+}
+
+func TestNewGateway(t *testing.T) {
+	mspDir, err := coreconfig.GetDevMspDir()
+	require.NoError(t, err)
+	certDir := filepath.Join("..", "..", "core", "comm", "testdata", "certs")
+	gatewayConf := config.Gateway{Enabled: true, ListenAddress: "127.0.0.1:0", TLS: config.TLS{
+		Enabled:     true,
+		Certificate: filepath.Join(certDir, "Org1-server1-cert.pem"),
+		PrivateKey:  filepath.Join(certDir, "Org1-server1-key.pem"),
+	}}
+
+	conf := embeddedConfig()
+	conf.TopLevel.General.Gateway = gatewayConf
+	_, err = New(conf)
+	assert.Error(t, err, "The gateway requires TLS client authentication")
+
+	// The client is authorized with the identity of its certificate, issued
+	// by the MSP of the channel
+	gatewayConf.TLS.ClientAuthEnabled = true
+	gatewayConf.TLS.ClientRootCAs = []string{filepath.Join(mspDir, "cacerts", "cacert.pem")}
+	conf = embeddedConfig()
+	conf.TopLevel.General.GenesisProfile = genesisconfig.SampleSingleMSPSoloProfile
+	conf.TopLevel.General.Gateway = gatewayConf
+	orderer, err := New(conf)
+	require.NoError(t, err)
+	go orderer.Start()
+	defer orderer.Stop()
+
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(mspDir, "signcerts", "peer.pem"), filepath.Join(mspDir, "keystore", "key.pem"))
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		Certificates:       []tls.Certificate{clientCert},
+		InsecureSkipVerify: true,
+	}}}
+	var resp *http.Response
+	for i := 0; ; i++ {
+		resp, err = client.Get("https://" + orderer.gateway.listener.Addr().String() + "/channels/" + provisional.TestChainID + "/blocks/0")
+		if err == nil || i == 100 {
+			break
+		}
+		// The gateway is served once the orderer starts
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	block := &cb.Block{}
+	require.NoError(t, jsonpb.Unmarshal(resp.Body, block))
+	assert.Equal(t, uint64(0), block.Header.Number, "Genesis block should be served")
+}
+
+// followingConfig is the configuration of a node in the role following the
+// orderer at address
+func followingConfig(role, address string) Config {
+	conf := embeddedConfig()
+	conf.LedgerFactory = ramledger.New(100)
+	conf.TopLevel.General.Role = role
+	conf.TopLevel.General.Follower = config.Follower{
+		Endpoints:     []string{address},
+		ChainID:       provisional.TestChainID,
+		RetryInterval: 10 * time.Millisecond,
+	}
+	return conf
+}
+
+func TestNewArchive(t *testing.T) {
+	active, err := New(embeddedConfig())
+	require.NoError(t, err)
+	go active.Start()
+	defer active.Stop()
+
+	archive, err := New(followingConfig("archive", active.Address()))
+	require.NoError(t, err)
+	assert.Nil(t, archive.Manager())
+	assert.NotNil(t, archive.Follower())
+	go archive.Start()
+	defer archive.Stop()
+
+	activeConn, err := grpc.Dial(active.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(5*time.Second))
+	require.NoError(t, err)
+	defer activeConn.Close()
+	env, err := cli.NewEnvelope(cb.HeaderType_MESSAGE, provisional.TestChainID, []byte("archived"), nil)
+	require.NoError(t, err)
+	resp, err := cli.Broadcast(ab.NewAtomicBroadcastClient(activeConn), env, cli.RetryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_SUCCESS, resp.Status)
+
+	archiveConn, err := grpc.Dial(archive.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(5*time.Second))
+	require.NoError(t, err)
+	defer archiveConn.Close()
+	client := ab.NewAtomicBroadcastClient(archiveConn)
+
+	// The archive waits for the block to be replicated before delivering it
+	seekInfo, err := cli.ParseSeek("1", nil)
+	require.NoError(t, err)
+	var blocks []*cb.Block
+	err = cli.Fetch(client, provisional.TestChainID, seekInfo, nil, func(block *cb.Block) error {
+		blocks = append(blocks, block)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, uint64(1), blocks[0].Header.Number)
+	assert.Len(t, blocks[0].Data.Data, 1, "Block should hold the broadcast message")
+
+	_, err = cli.Broadcast(client, env, cli.RetryOptions{})
+	assert.Error(t, err, "Broadcast should not be offered by the archive")
+	assert.Contains(t, err.Error(), "Broadcast is not offered by archive nodes")
+}
+
+func TestNewReplica(t *testing.T) {
+	active, err := New(embeddedConfig())
+	require.NoError(t, err)
+	go active.Start()
+	defer active.Stop()
+
+	replica, err := New(followingConfig("replica", active.Address()))
+	require.NoError(t, err)
+	go replica.Start()
+	defer replica.Stop()
+
+	conn, err := grpc.Dial(replica.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(5*time.Second))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := ab.NewAtomicBroadcastClient(conn)
+
+	seekInfo, err := cli.ParseSeek("0", nil)
+	require.NoError(t, err)
+	var blocks []*cb.Block
+	err = cli.Fetch(client, provisional.TestChainID, seekInfo, nil, func(block *cb.Block) error {
+		blocks = append(blocks, block)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, blocks, 1, "The replica should deliver the genesis block")
+
+	env, err := cli.NewEnvelope(cb.HeaderType_MESSAGE, provisional.TestChainID, []byte("redirected"), nil)
+	require.NoError(t, err)
+	resp, err := cli.Broadcast(client, env, cli.RetryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_TEMPORARY_REDIRECT, resp.Status)
+	profile := genesisconfig.Load(genesisconfig.SampleInsecureProfile)
+	assert.Equal(t, profile.Orderer.Addresses, resp.Endpoints, "Clients should be redirected to the orderers of the channel")
+
+	env, err = cli.NewEnvelope(cb.HeaderType_MESSAGE, "unknown", []byte("redirected"), nil)
+	require.NoError(t, err)
+	resp, err = cli.Broadcast(client, env, cli.RetryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{active.Address()}, resp.Endpoints, "Clients of unknown channels should be redirected to the followed orderers")
+}
+
+func TestNewStopWithoutStart(t *testing.T) {
+	orderer, err := New(embeddedConfig())
+	require.NoError(t, err)
+	orderer.Stop()
+
+	_, err = grpc.Dial(orderer.Address(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(100*time.Millisecond))
+	assert.Error(t, err, "Listener should be closed")
+}
+
+func TestNewNotifiesServiceManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notify")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+	os.Setenv("NOTIFY_SOCKET", socket)
+	defer os.Unsetenv("NOTIFY_SOCKET")
+
+	orderer, err := New(embeddedConfig())
+	require.NoError(t, err)
+	go orderer.Start()
+
+	receive := func() string {
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+	assert.True(t, strings.HasPrefix(receive(), sdnotify.Ready), "Readiness should be reported once requests are served")
+	orderer.Stop()
+	assert.True(t, strings.HasPrefix(receive(), sdnotify.Stopping), "Stopping should be reported when draining begins")
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(Config{})
+	assert.EqualError(t, err, "the orderer configuration is required")
+
+	conf := embeddedConfig()
+	conf.TopLevel.General.GenesisMethod = "unknown"
+	_, err = New(conf)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown genesis method")
+
+	conf = embeddedConfig()
+	conf.TopLevel.General.Services = []string{"Gossip"}
+	_, err = New(conf)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown service 'Gossip' in General.Services")
+}
@@ -0,0 +1,291 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/common/admin"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/cosign"
+	"github.com/hyperledger/fabric/orderer/common/deliver"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/jointoken"
+	"github.com/hyperledger/fabric/orderer/common/txstatus"
+	"github.com/hyperledger/fabric/orderer/configupdate"
+	"github.com/hyperledger/fabric/orderer/follower"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"runtime/debug"
+)
+
+type configUpdateSupport struct {
+	multichain.Manager
+}
+
+func (cus configUpdateSupport) GetChain(chainID string) (configupdate.Support, bool) {
+	return cus.Manager.GetChain(chainID)
+}
+
+type broadcastSupport struct {
+	multichain.Manager
+	broadcast.ConfigUpdateProcessor
+	maintenance *admin.MaintenanceMode
+}
+
+func (bs broadcastSupport) GetChain(chainID string) (broadcast.Support, bool) {
+	cs, ok := bs.Manager.GetChain(chainID)
+	if !ok || bs.maintenance == nil {
+		return cs, ok
+	}
+	return maintenanceSupport{Support: cs, maintenance: bs.maintenance}, true
+}
+
+// maintenanceSupport refuses to enqueue messages while the orderer is in
+// maintenance mode, so that broadcast clients receive SERVICE_UNAVAILABLE
+type maintenanceSupport struct {
+	broadcast.Support
+	maintenance *admin.MaintenanceMode
+}
+
+func (ms maintenanceSupport) Enqueue(msg *filter.Message) bool {
+	if ms.maintenance.Enabled() {
+		logger.Warningf("Rejecting broadcast message, the orderer is in maintenance mode")
+		return false
+	}
+	return ms.Support.Enqueue(msg)
+}
+
+type adminSupport struct {
+	multichain.Manager
+	signer crypto.LocalSigner
+}
+
+func (as adminSupport) GetChain(chainID string) (admin.ChainSupport, bool) {
+	return as.Manager.GetChain(chainID)
+}
+
+func (as adminSupport) LogSpec() string {
+	return loggingSpec()
+}
+
+func (as adminSupport) SetLogSpec(spec string) {
+	applyLoggingSpec(spec)
+}
+
+func (as adminSupport) Signer() crypto.LocalSigner {
+	return as.signer
+}
+
+type eventsSupport struct {
+	multichain.Manager
+}
+
+func (es eventsSupport) Reader(chainID string) (ledger.Reader, bool) {
+	cs, ok := es.Manager.GetChain(chainID)
+	if !ok {
+		return nil, false
+	}
+	return cs.Reader(), true
+}
+
+type deliverSupport struct {
+	multichain.Manager
+}
+
+func (bs deliverSupport) GetChain(chainID string) (deliver.Support, bool) {
+	return bs.Manager.GetChain(chainID)
+}
+
+type joinTokenSupport struct {
+	multichain.Manager
+}
+
+func (js joinTokenSupport) GetChain(chainID string) (jointoken.ChainSupport, bool) {
+	return js.Manager.GetChain(chainID)
+}
+
+type cosignSupport struct {
+	multichain.Manager
+}
+
+func (cs cosignSupport) GetChain(chainID string) (cosign.ChainSupport, bool) {
+	return cs.Manager.GetChain(chainID)
+}
+
+type txStatusSupport struct {
+	multichain.Manager
+}
+
+func (ts txStatusSupport) GetChain(chainID string) (txstatus.ChainSupport, bool) {
+	return ts.Manager.GetChain(chainID)
+}
+
+type followerSupport struct {
+	*follower.Follower
+}
+
+func (fs followerSupport) GetChain(chainID string) (deliver.Support, bool) {
+	return fs.Follower.GetChain(chainID)
+}
+
+// readOnlyHandler rejects every Broadcast stream, as nodes which do not order
+// transactions do
+type readOnlyHandler struct {
+	role string
+}
+
+func (rh readOnlyHandler) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
+	return grpc.Errorf(codes.Unimplemented, "Broadcast is not offered by %s nodes", rh.role)
+}
+
+// replicaRedirector redirects broadcast clients of a read replica to the
+// orderers of the channel config, or to the followed orderers if the channel
+// is not known
+type replicaRedirector struct {
+	follower  *follower.Follower
+	endpoints []string
+}
+
+func (rr replicaRedirector) Endpoints(chainID string) []string {
+	if chain, ok := rr.follower.GetChain(chainID); ok {
+		if addresses := chain.OrdererAddresses(); len(addresses) > 0 {
+			return addresses
+		}
+	}
+	return rr.endpoints
+}
+
+type server struct {
+	bh broadcast.Handler
+	dh deliver.Handler
+}
+
+// Server is the AtomicBroadcast service of an ordering service node, along
+// with the BatchBroadcast service, which accepts messages in batches
+type Server interface {
+	ab.AtomicBroadcastServer
+	ab.BatchBroadcastServer
+}
+
+// NewServer creates a Server based on the broadcast target and ledger Reader,
+// which rejects broadcast messages while maintenance mode is enabled, verifies broadcast messages
+// with the verifier, signs broadcast responses if signResponses is set and handles broadcast and
+// deliver streams as configured by broadcastConf and deliverConf
+func NewServer(ml multichain.Manager, signer crypto.LocalSigner, maintenance *admin.MaintenanceMode, verifier *broadcast.Verifier, signResponses bool, broadcastConf broadcast.Config, deliverConf deliver.Config) Server {
+	var responseSigner crypto.LocalSigner
+	if signResponses {
+		responseSigner = signer
+	}
+	s := &server{
+		dh: deliver.NewHandlerImpl(deliverSupport{Manager: ml}, deliverConf),
+		bh: broadcast.NewParallelHandler(broadcastSupport{
+			Manager:               ml,
+			ConfigUpdateProcessor: configupdate.New(ml.SystemChannelID(), configUpdateSupport{Manager: ml}, signer),
+			maintenance:           maintenance,
+		}, responseSigner, verifier, broadcastConf),
+	}
+	return s
+}
+
+// NewArchiveServer creates an ab.AtomicBroadcastServer which delivers the
+// blocks of the chains replicated by the follower, serving deliver streams as
+// configured by deliverConf, and rejects broadcast streams
+func NewArchiveServer(f *follower.Follower, deliverConf deliver.Config) ab.AtomicBroadcastServer {
+	return &server{
+		dh: deliver.NewHandlerImpl(followerSupport{Follower: f}, deliverConf),
+		bh: readOnlyHandler{role: "archive"},
+	}
+}
+
+// NewReplicaServer creates an ab.AtomicBroadcastServer which delivers the
+// blocks of the chains replicated by the follower, serving deliver streams as
+// configured by deliverConf, and redirects broadcast clients to the orderers
+// of each channel, or to endpoints if the channel is unknown, ending their
+// streams as deliverConf does
+func NewReplicaServer(f *follower.Follower, endpoints []string, deliverConf deliver.Config) ab.AtomicBroadcastServer {
+	return &server{
+		dh: deliver.NewHandlerImpl(followerSupport{Follower: f}, deliverConf),
+		bh: broadcast.NewRedirectHandler(replicaRedirector{follower: f, endpoints: endpoints}, deliverConf.Status, deliverConf.Metrics),
+	}
+}
+
+// Broadcast receives a stream of messages from a client for ordering
+func (s *server) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	logger.Debugf("Starting new Broadcast handler")
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Criticalf("Broadcast client triggered panic: %s\n%s", r, debug.Stack())
+		}
+		logger.Debugf("Closing Broadcast stream")
+	}()
+	return s.bh.Handle(srv)
+}
+
+// BroadcastBatch receives a stream of batches of messages from a client for
+// ordering, on the nodes which order messages
+func (s *server) BroadcastBatch(srv ab.BatchBroadcast_BroadcastBatchServer) error {
+	bh, ok := s.bh.(broadcast.BatchHandler)
+	if !ok {
+		return grpc.Errorf(codes.Unimplemented, "BroadcastBatch is not offered by this node")
+	}
+	logger.Debugf("Starting new BroadcastBatch handler")
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Criticalf("BroadcastBatch client triggered panic: %s\n%s", r, debug.Stack())
+		}
+		logger.Debugf("Closing BroadcastBatch stream")
+	}()
+	return bh.HandleBatch(srv)
+}
+
+// Deliver sends a stream of blocks to a client after ordering
+func (s *server) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	logger.Debugf("Starting new Deliver handler")
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Criticalf("Deliver client triggered panic: %s\n%s", r, debug.Stack())
+		}
+		logger.Debugf("Closing Deliver stream")
+	}()
+	return s.dh.Handle(srv)
+}
+
+// restrictedServer offers only those of the Broadcast and Deliver RPCs which
+// are exposed on a listener, rejecting the other as unimplemented
+type restrictedServer struct {
+	ab.AtomicBroadcastServer
+	broadcast bool
+	deliver   bool
+}
+
+func (rs restrictedServer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	if !rs.broadcast {
+		return grpc.Errorf(codes.Unimplemented, "Broadcast is not offered on this listener")
+	}
+	return rs.AtomicBroadcastServer.Broadcast(srv)
+}
+
+func (rs restrictedServer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	if !rs.deliver {
+		return grpc.Errorf(codes.Unimplemented, "Deliver is not offered on this listener")
+	}
+	return rs.AtomicBroadcastServer.Deliver(srv)
+}
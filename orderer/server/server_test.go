@@ -0,0 +1,85 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+                 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/admin"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	mockbroadcast "github.com/hyperledger/fabric/orderer/mocks/broadcast"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestBroadcastNoPanic(t *testing.T) {
+	// Defer recovers from the panic
+	_ = (&server{}).Broadcast(nil)
+}
+
+func TestDeliverNoPanic(t *testing.T) {
+	// Defer recovers from the panic
+	_ = (&server{}).Deliver(nil)
+}
+
+func TestMaintenanceSupport(t *testing.T) {
+	mm := &admin.MaintenanceMode{}
+	mbs := &mockbroadcast.Support{}
+	ms := maintenanceSupport{Support: mbs, maintenance: mm}
+
+	assert.True(t, ms.Enqueue(&filter.Message{}))
+	assert.Len(t, mbs.Enqueued(), 1)
+
+	mm.Set(true)
+	assert.False(t, ms.Enqueue(&filter.Message{}), "Messages should be rejected in maintenance mode")
+	assert.Len(t, mbs.Enqueued(), 1)
+
+	mm.Set(false)
+	assert.True(t, ms.Enqueue(&filter.Message{}))
+	assert.Len(t, mbs.Enqueued(), 2)
+}
+
+type mockAtomicBroadcastServer struct {
+	broadcasts, delivers int
+}
+
+func (mabs *mockAtomicBroadcastServer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	mabs.broadcasts++
+	return nil
+}
+
+func (mabs *mockAtomicBroadcastServer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	mabs.delivers++
+	return nil
+}
+
+func TestRestrictedServer(t *testing.T) {
+	mabs := &mockAtomicBroadcastServer{}
+
+	rs := restrictedServer{AtomicBroadcastServer: mabs, deliver: true}
+	assert.Equal(t, codes.Unimplemented, grpc.Code(rs.Broadcast(nil)))
+	assert.NoError(t, rs.Deliver(nil))
+
+	rs = restrictedServer{AtomicBroadcastServer: mabs, broadcast: true}
+	assert.NoError(t, rs.Broadcast(nil))
+	assert.Equal(t, codes.Unimplemented, grpc.Code(rs.Deliver(nil)))
+
+	assert.Equal(t, 1, mabs.broadcasts)
+	assert.Equal(t, 1, mabs.delivers)
+}
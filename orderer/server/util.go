@@ -14,17 +14,25 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package server
 
 import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/signer"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	fileledger "github.com/hyperledger/fabric/orderer/ledger/file"
 	jsonledger "github.com/hyperledger/fabric/orderer/ledger/json"
+	"github.com/hyperledger/fabric/orderer/ledger/migrate"
 	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
 	config "github.com/hyperledger/fabric/orderer/localconfig"
 )
@@ -39,6 +47,7 @@ func createLedgerFactory(conf *config.TopLevel) (ledger.Factory, string) {
 			ld = createTempDir(conf.FileLedger.Prefix)
 		}
 		logger.Debug("Ledger dir:", ld)
+		prepareLedgerDir(ld, conf)
 		lf = fileledger.New(ld)
 		// The file-based ledger stores the blocks for each channel
 		// in a fsblkstorage.ChainsDir sub-directory that we have
@@ -51,13 +60,23 @@ func createLedgerFactory(conf *config.TopLevel) (ledger.Factory, string) {
 			ld = createTempDir(conf.FileLedger.Prefix)
 		}
 		logger.Debug("Ledger dir:", ld)
+		prepareLedgerDir(ld, conf)
 		lf = jsonledger.New(ld)
 	case "ram":
 		fallthrough
 	default:
-		lf = ramledger.New(int(conf.RAMLedger.HistorySize))
+		// The RAM ledger already holds its blocks in memory
+		return ramledger.New(int(conf.RAMLedger.HistorySize)), ld
+	}
+	return ledger.NewCachingFactory(lf, conf.General.Deliver.CacheBlocks), ld
+}
+
+// prepareLedgerDir stamps the ledger directory with its format, upgrading a
+// ledger in an older format if FileLedger.AutoMigrate is set
+func prepareLedgerDir(ld string, conf *config.TopLevel) {
+	if err := migrate.Prepare(ld, migrate.Current(conf.General.LedgerType), conf.FileLedger.AutoMigrate); err != nil {
+		logger.Panic("Error preparing ledger:", err)
 	}
-	return lf, ld
 }
 
 func createTempDir(dirPrefix string) string {
@@ -83,3 +102,29 @@ func createSubDir(parentDirPath string, subDir string) (string, bool) {
 	}
 	return subDirPath, created
 }
+
+// bccspSigner returns a signer for the private key, held by csp, which
+// corresponds to the public key of the PEM-encoded certificate, so that TLS
+// keys may be kept in a PKCS#11 token rather than in files
+func bccspSigner(csp bccsp.BCCSP, certPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := csp.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed importing certificate public key: %s", err)
+	}
+	key, err := csp.GetKey(pub.SKI())
+	if err != nil {
+		return nil, fmt.Errorf("failed finding the private key: %s", err)
+	}
+	if !key.Private() {
+		return nil, errors.New("the private key is not held by the BCCSP")
+	}
+	return signer.New(csp, key)
+}
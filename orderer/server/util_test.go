@@ -14,12 +14,22 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package server
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/signer"
+	"github.com/hyperledger/fabric/bccsp/sw"
 	config "github.com/hyperledger/fabric/orderer/localconfig"
 	"github.com/stretchr/testify/assert"
 )
@@ -69,6 +79,25 @@ func TestCreateLedgerFactory(t *testing.T) {
 	}
 }
 
+func TestCreateLedgerFactoryMigration(t *testing.T) {
+	conf := config.Load()
+	conf.General.LedgerType = "json"
+	conf.FileLedger.Location = createTempDir("test-dir")
+	defer os.RemoveAll(conf.FileLedger.Location)
+	lf, _ := createLedgerFactory(conf)
+	_, err := lf.GetOrCreate("foo")
+	assert.NoError(t, err)
+	lf.Close()
+
+	conf.General.LedgerType = "file"
+	assert.Panics(t, func() { createLedgerFactory(conf) }, "A JSON ledger should not be opened as a file ledger")
+
+	conf.FileLedger.AutoMigrate = true
+	lf, _ = createLedgerFactory(conf)
+	defer lf.Close()
+	assert.Equal(t, []string{"foo"}, lf.ChainIDs(), "The JSON ledger should be migrated to a file ledger")
+}
+
 func TestCreateSubDir(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -124,3 +153,43 @@ func TestCreateTempDir(t *testing.T) {
 	})
 
 }
+
+// newBCCSPCertificate generates a key held by csp and returns a PEM-encoded
+// self-signed certificate for it
+func newBCCSPCertificate(t *testing.T, csp bccsp.BCCSP, temporary bool) []byte {
+	key, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: temporary})
+	assert.NoError(t, err)
+	s, err := signer.New(csp, key)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "orderer"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, s.Public(), s)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestBCCSPSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	csp, err := sw.NewDefaultSecurityLevel(dir)
+	assert.NoError(t, err)
+
+	certPEM := newBCCSPCertificate(t, csp, false)
+	s, err := bccspSigner(csp, certPEM)
+	assert.NoError(t, err)
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, cert.PublicKey, s.Public(), "The signer should hold the key of the certificate")
+
+	_, err = bccspSigner(csp, newBCCSPCertificate(t, csp, true))
+	assert.Error(t, err, "Keys which are not in the key store should not be found")
+
+	_, err = bccspSigner(csp, []byte("garbage"))
+	assert.EqualError(t, err, "no PEM-encoded certificate found")
+}
@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package simulation runs a whole ordering service node in process, with
+// RAM ledgers, solo consenters driven by a manual clock and streams which
+// connect clients to the Broadcast and Deliver handlers without a network,
+// so that tests may exercise broadcast, consensus and deliver together
+// hermetically and in unit test time.
+package simulation
+
+import (
+	"fmt"
+	"time"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/orderer/common/admin"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/deliver"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	"github.com/hyperledger/fabric/orderer/server"
+	"github.com/hyperledger/fabric/orderer/solo"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// HistorySize is the number of blocks kept by the RAM ledger of each chain
+const HistorySize = 1000
+
+// Start is the time at which the clock of a simulation starts
+var Start = time.Unix(1500000000, 0)
+
+// Simulation is an ordering service node bootstrapped from a genesis block.
+// Its chains are ordered by the solo consenter, whatever their consensus
+// type, so chains configured for kafka are simulated without brokers. It is
+// an ab.AtomicBroadcastClient, whose streams are served in process.
+type Simulation struct {
+	// Manager is the manager of the chains of the node
+	Manager multichain.Manager
+	// Clock drives the batch timers of the chains. Once a chain has received
+	// a message which does not fill a batch, BlockUntil(1) waits for its batch
+	// timer to start, and advancing the clock by the batch timeout cuts the
+	// batch.
+	Clock *clock.Manual
+
+	verifier *broadcast.Verifier
+	server   ab.AtomicBroadcastServer
+}
+
+// New creates a simulation whose system channel is bootstrapped from the
+// genesis block, signing as a fake identity
+func New(genesisBlock *cb.Block) (s *Simulation, err error) {
+	chainID, err := utils.GetChainIDFromBlock(genesisBlock)
+	if err != nil {
+		return nil, err
+	}
+	lf := ramledger.New(HistorySize)
+	rl, err := lf.GetOrCreate(chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := rl.Append(genesisBlock); err != nil {
+		return nil, err
+	}
+
+	s = &Simulation{
+		Clock:    clock.NewManual(Start),
+		verifier: broadcast.NewVerifier(0),
+	}
+	// The manager panics if the genesis block does not hold a valid
+	// system channel config
+	defer func() {
+		if r := recover(); r != nil {
+			s.verifier.Stop()
+			s, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	consenter := solo.NewWithClock(s.Clock)
+	s.Manager = multichain.NewManagerImpl(lf, map[string]multichain.Consenter{"solo": consenter, "kafka": consenter}, mockcrypto.FakeLocalSigner, multichain.Config{})
+	s.server = server.NewServer(s.Manager, mockcrypto.FakeLocalSigner, &admin.MaintenanceMode{}, s.verifier, false, broadcast.Config{}, deliver.Config{})
+	return s, nil
+}
+
+// Broadcast opens a Broadcast stream to the node, which ends once ctx is done
+func (s *Simulation) Broadcast(ctx context.Context, opts ...grpc.CallOption) (ab.AtomicBroadcast_BroadcastClient, error) {
+	st := newStream(ctx, func(st *stream) error {
+		return s.server.Broadcast(broadcastServer{stream: st})
+	})
+	return broadcastClient{stream: st}, nil
+}
+
+// Deliver opens a Deliver stream to the node, which ends once ctx is done
+func (s *Simulation) Deliver(ctx context.Context, opts ...grpc.CallOption) (ab.AtomicBroadcast_DeliverClient, error) {
+	st := newStream(ctx, func(st *stream) error {
+		return s.server.Deliver(deliverServer{stream: st})
+	})
+	return deliverClient{stream: st}, nil
+}
+
+// Halt halts the chains of the node
+func (s *Simulation) Halt() {
+	s.Manager.Halt()
+	s.verifier.Stop()
+}
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simulation
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
+	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+var genesisBlock = provisional.New(genesisconfig.Load(genesisconfig.SampleInsecureProfile)).GenesisBlock()
+
+func makeEnvelope(data string) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(
+			utils.MakeChannelHeader(cb.HeaderType_MESSAGE, 0, provisional.TestChainID, 0),
+			&cb.SignatureHeader{},
+		),
+		Data: []byte(data),
+	})}
+}
+
+func makeSeek(start, stop uint64) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: utils.MakePayloadHeader(
+			utils.MakeChannelHeader(cb.HeaderType_DELIVER_SEEK_INFO, 0, provisional.TestChainID, 0),
+			&cb.SignatureHeader{},
+		),
+		Data: utils.MarshalOrPanic(&ab.SeekInfo{
+			Start:    &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: start}}},
+			Stop:     &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: stop}}},
+			Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		}),
+	})}
+}
+
+func TestInterface(t *testing.T) {
+	_ = ab.AtomicBroadcastClient(&Simulation{})
+}
+
+func TestNewMalformed(t *testing.T) {
+	_, err := New(&cb.Block{})
+	assert.Error(t, err)
+
+	_, err = New(cb.NewBlock(0, nil))
+	assert.Error(t, err)
+}
+
+func TestBroadcastAndDeliver(t *testing.T) {
+	sim, err := New(genesisBlock)
+	require.NoError(t, err)
+	defer sim.Halt()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bc, err := sim.Broadcast(ctx)
+	require.NoError(t, err)
+	for _, data := range []string{"foo", "bar"} {
+		require.NoError(t, bc.Send(makeEnvelope(data)))
+		reply, err := bc.Recv()
+		require.NoError(t, err)
+		require.Equal(t, cb.Status_SUCCESS, reply.Status)
+	}
+	require.NoError(t, bc.CloseSend())
+	_, err = bc.Recv()
+	assert.Equal(t, io.EOF, err, "The stream should end once the client closes it")
+
+	// The batch is cut once the clock passes the batch timeout
+	sim.Clock.BlockUntil(1)
+	sim.Clock.Advance(genesisconfig.Load(genesisconfig.SampleInsecureProfile).Orderer.BatchTimeout)
+
+	dc, err := sim.Deliver(ctx)
+	require.NoError(t, err)
+	require.NoError(t, dc.Send(makeSeek(1, 1)))
+	reply, err := dc.Recv()
+	require.NoError(t, err)
+	block := reply.GetBlock()
+	require.NotNil(t, block, "Expected a block, got %v", reply)
+	assert.Equal(t, uint64(1), block.Header.Number)
+	assert.Len(t, block.Data.Data, 2)
+	reply, err = dc.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_SUCCESS, reply.GetStatus())
+}
+
+func TestStreamCancel(t *testing.T) {
+	sim, err := New(genesisBlock)
+	require.NoError(t, err)
+	defer sim.Halt()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dc, err := sim.Deliver(ctx)
+	require.NoError(t, err)
+	require.NoError(t, dc.Send(makeSeek(1, 1)))
+
+	received := make(chan error)
+	go func() {
+		_, err := dc.Recv()
+		received <- err
+	}()
+	select {
+	case <-received:
+		t.Fatal("No block should be delivered before a message is ordered")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-received:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("The stream should end once its context is done")
+	}
+}
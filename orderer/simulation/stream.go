@@ -0,0 +1,189 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simulation
+
+import (
+	"io"
+	"sync"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// stream connects a client to the handler of a stream in process, in place
+// of a gRPC transport. The handler runs until it returns, as it would for a
+// gRPC stream, after which the client receives its error, or io.EOF.
+type stream struct {
+	clientCtx context.Context
+	ctx       context.Context
+	requests  chan *cb.Envelope
+	responses chan interface{}
+	closeSend sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func newStream(clientCtx context.Context, handle func(*stream) error) *stream {
+	ctx, cancel := context.WithCancel(clientCtx)
+	s := &stream{
+		clientCtx: clientCtx,
+		ctx:       ctx,
+		requests:  make(chan *cb.Envelope),
+		responses: make(chan interface{}),
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		defer cancel()
+		s.err = handle(s)
+	}()
+	return s
+}
+
+// send is called by the client to send a request to the handler
+func (s *stream) send(env *cb.Envelope) error {
+	select {
+	case s.requests <- env:
+		return nil
+	case <-s.done:
+		return io.EOF
+	case <-s.clientCtx.Done():
+		return s.clientCtx.Err()
+	}
+}
+
+// recv is called by the client to receive a response from the handler, and
+// returns as soon as the context of the client is done, as a gRPC client does,
+// even if the handler has yet to notice
+func (s *stream) recv() (interface{}, error) {
+	select {
+	case response := <-s.responses:
+		return response, nil
+	case <-s.done:
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	case <-s.clientCtx.Done():
+		return nil, s.clientCtx.Err()
+	}
+}
+
+// closeRequests is called by the client once it has sent its last request
+func (s *stream) closeRequests() error {
+	s.closeSend.Do(func() { close(s.requests) })
+	return nil
+}
+
+// serverRecv is called by the handler to receive a request from the client
+func (s *stream) serverRecv() (*cb.Envelope, error) {
+	select {
+	case env, ok := <-s.requests:
+		if !ok {
+			return nil, io.EOF
+		}
+		return env, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// serverSend is called by the handler to send a response to the client
+func (s *stream) serverSend(response interface{}) error {
+	select {
+	case s.responses <- response:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+type broadcastServer struct {
+	grpc.ServerStream
+	stream *stream
+}
+
+func (bs broadcastServer) Context() context.Context {
+	return bs.stream.ctx
+}
+
+func (bs broadcastServer) Send(response *ab.BroadcastResponse) error {
+	return bs.stream.serverSend(response)
+}
+
+func (bs broadcastServer) Recv() (*cb.Envelope, error) {
+	return bs.stream.serverRecv()
+}
+
+type broadcastClient struct {
+	grpc.ClientStream
+	stream *stream
+}
+
+func (bc broadcastClient) Context() context.Context {
+	return bc.stream.clientCtx
+}
+
+func (bc broadcastClient) Send(env *cb.Envelope) error {
+	return bc.stream.send(env)
+}
+
+func (bc broadcastClient) Recv() (*ab.BroadcastResponse, error) {
+	response, err := bc.stream.recv()
+	if err != nil {
+		return nil, err
+	}
+	return response.(*ab.BroadcastResponse), nil
+}
+
+func (bc broadcastClient) CloseSend() error {
+	return bc.stream.closeRequests()
+}
+
+type deliverServer struct {
+	grpc.ServerStream
+	stream *stream
+}
+
+func (ds deliverServer) Context() context.Context {
+	return ds.stream.ctx
+}
+
+func (ds deliverServer) Send(response *ab.DeliverResponse) error {
+	return ds.stream.serverSend(response)
+}
+
+func (ds deliverServer) Recv() (*cb.Envelope, error) {
+	return ds.stream.serverRecv()
+}
+
+type deliverClient struct {
+	grpc.ClientStream
+	stream *stream
+}
+
+func (dc deliverClient) Context() context.Context {
+	return dc.stream.clientCtx
+}
+
+func (dc deliverClient) Send(env *cb.Envelope) error {
+	return dc.stream.send(env)
+}
+
+func (dc deliverClient) Recv() (*ab.DeliverResponse, error) {
+	response, err := dc.stream.recv()
+	if err != nil {
+		return nil, err
+	}
+	return response.(*ab.DeliverResponse), nil
+}
+
+func (dc deliverClient) CloseSend() error {
+	return dc.stream.closeRequests()
+}
@@ -19,6 +19,11 @@ package solo
 import (
 	"time"
 
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/priority"
 	"github.com/hyperledger/fabric/orderer/multichain"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/op/go-logging"
@@ -26,12 +31,26 @@ import (
 
 var logger = logging.MustGetLogger("orderer/solo")
 
-type consenter struct{}
+// leader is 1 while this orderer leads the ordering of a channel, which a solo
+// orderer always does while the chain runs
+var leader = metrics.NewGauge(metrics.Opts{
+	Namespace:  "orderer",
+	Subsystem:  "consensus",
+	Name:       "leader",
+	Help:       "Whether this orderer is the leader of the channel.",
+	LabelNames: []string{"channel"},
+})
+
+type consenter struct {
+	clock clock.Clock
+}
 
 type chain struct {
-	support  multichain.ConsenterSupport
-	sendChan chan *cb.Envelope
-	exitChan chan struct{}
+	support    multichain.ConsenterSupport
+	clock      clock.Clock
+	sendChan   chan *filter.Message
+	urgentChan chan *filter.Message
+	exitChan   chan struct{}
 }
 
 // New creates a new consenter for the solo consensus scheme.
@@ -39,22 +58,31 @@ type chain struct {
 // It accepts messages being delivered via Enqueue, orders them, and then uses the blockcutter to form the messages
 // into blocks before writing to the given ledger
 func New() multichain.Consenter {
-	return &consenter{}
+	return NewWithClock(clock.Real())
+}
+
+// NewWithClock creates a new consenter for the solo consensus scheme whose
+// batch timers are driven by the clock
+func NewWithClock(clock clock.Clock) multichain.Consenter {
+	return &consenter{clock: clock}
 }
 
 func (solo *consenter) HandleChain(support multichain.ConsenterSupport, metadata *cb.Metadata) (multichain.Chain, error) {
-	return newChain(support), nil
+	return newChain(support, solo.clock), nil
 }
 
-func newChain(support multichain.ConsenterSupport) *chain {
+func newChain(support multichain.ConsenterSupport, clock clock.Clock) *chain {
 	return &chain{
-		support:  support,
-		sendChan: make(chan *cb.Envelope),
-		exitChan: make(chan struct{}),
+		support:    support,
+		clock:      clock,
+		sendChan:   make(chan *filter.Message),
+		urgentChan: make(chan *filter.Message),
+		exitChan:   make(chan struct{}),
 	}
 }
 
 func (ch *chain) Start() {
+	leader.With(ch.support.ChainID()).Set(1)
 	go ch.main()
 }
 
@@ -64,13 +92,20 @@ func (ch *chain) Halt() {
 		// Allow multiple halts without panic
 	default:
 		close(ch.exitChan)
+		leader.With(ch.support.ChainID()).Set(0)
 	}
 }
 
-// Enqueue accepts a message and returns true on acceptance, or false on shutdown
-func (ch *chain) Enqueue(env *cb.Envelope) bool {
+// Enqueue accepts a message and returns true on acceptance, or false on shutdown.
+// Messages of urgent priority classes, such as config messages, are ordered
+// before the other messages waiting to be.
+func (ch *chain) Enqueue(msg *filter.Message) bool {
+	send := ch.sendChan
+	if priority.Urgent(priority.Of(msg)) {
+		send = ch.urgentChan
+	}
 	select {
-	case ch.sendChan <- env:
+	case send <- msg:
 		return true
 	case <-ch.exitChan:
 		return false
@@ -84,36 +119,51 @@ func (ch *chain) Errored() <-chan struct{} {
 
 func (ch *chain) main() {
 	var timer <-chan time.Time
+	chainLogger := flogging.WithChannel(logger, ch.support.ChainID())
+
+	order := func(msg *filter.Message) {
+		batches, committers, ok, _ := ch.support.BlockCutter().Ordered(msg)
+		if ok && len(batches) == 0 && timer == nil {
+			timer = ch.clock.After(ch.support.BatchTimeout())
+			return
+		}
+		for i, batch := range batches {
+			block := ch.support.CreateNextBlock(batch)
+			ch.support.WriteBlock(block, committers[i], nil)
+		}
+		if len(batches) > 0 {
+			timer = nil
+		}
+	}
 
 	for {
+		// Urgent messages waiting are ordered first
+		select {
+		case msg := <-ch.urgentChan:
+			order(msg)
+			continue
+		default:
+		}
+
 		select {
+		case msg := <-ch.urgentChan:
+			order(msg)
 		case msg := <-ch.sendChan:
-			batches, committers, ok, _ := ch.support.BlockCutter().Ordered(msg)
-			if ok && len(batches) == 0 && timer == nil {
-				timer = time.After(ch.support.SharedConfig().BatchTimeout())
-				continue
-			}
-			for i, batch := range batches {
-				block := ch.support.CreateNextBlock(batch)
-				ch.support.WriteBlock(block, committers[i], nil)
-			}
-			if len(batches) > 0 {
-				timer = nil
-			}
+			order(msg)
 		case <-timer:
 			//clear the timer
 			timer = nil
 
 			batch, committers := ch.support.BlockCutter().Cut()
 			if len(batch) == 0 {
-				logger.Warningf("Batch timer expired with no pending requests, this might indicate a bug")
+				chainLogger.Warningf("Batch timer expired with no pending requests, this might indicate a bug")
 				continue
 			}
-			logger.Debugf("Batch timer expired, creating block")
+			chainLogger.Debugf("Batch timer expired, creating block")
 			block := ch.support.CreateNextBlock(batch)
 			ch.support.WriteBlock(block, committers, nil)
 		case <-ch.exitChan:
-			logger.Debugf("Exiting")
+			chainLogger.Debugf("Exiting")
 			return
 		}
 	}
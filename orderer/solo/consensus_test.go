@@ -21,6 +21,8 @@ import (
 	"time"
 
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/orderer/common/clock"
+	"github.com/hyperledger/fabric/orderer/common/filter"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/blockcutter"
 	mockmultichain "github.com/hyperledger/fabric/orderer/mocks/multichain"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -33,9 +35,9 @@ func init() {
 	logging.SetLevel(logging.DEBUG, "")
 }
 
-var testMessage = &cb.Envelope{Payload: []byte("TEST_MESSAGE")}
+var testMessage = &filter.Message{Envelope: &cb.Envelope{Payload: []byte("TEST_MESSAGE")}}
 
-func syncQueueMessage(msg *cb.Envelope, chain *chain, bc *mockblockcutter.Receiver) {
+func syncQueueMessage(msg *filter.Message, chain *chain, bc *mockblockcutter.Receiver) {
 	chain.Enqueue(msg)
 	bc.Block <- struct{}{}
 }
@@ -64,7 +66,7 @@ func TestHaltBeforeTimeout(t *testing.T) {
 		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
 	}
 	defer close(support.BlockCutterVal.Block)
-	bs := newChain(support)
+	bs := newChain(support, clock.Real())
 	wg := goWithWait(bs.main)
 	defer bs.Halt()
 
@@ -106,7 +108,7 @@ func TestEnqueueAfterHalt(t *testing.T) {
 		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
 	}
 	defer close(support.BlockCutterVal.Block)
-	bs := newChain(support)
+	bs := newChain(support, clock.Real())
 	bs.Halt()
 	assert.False(t, bs.Enqueue(testMessage), "Enqueue should not be accepted after halt")
 	select {
@@ -124,11 +126,14 @@ func TestBatchTimer(t *testing.T) {
 		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
 	}
 	defer close(support.BlockCutterVal.Block)
-	bs := newChain(support)
+	clk := clock.NewManual(time.Unix(0, 0))
+	bs := newChain(support, clk)
 	wg := goWithWait(bs.main)
 	defer bs.Halt()
 
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
+	clk.BlockUntil(1)
+	clk.Advance(batchTimeout)
 
 	select {
 	case <-support.Blocks:
@@ -137,6 +142,8 @@ func TestBatchTimer(t *testing.T) {
 	}
 
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
+	clk.BlockUntil(1)
+	clk.Advance(batchTimeout)
 	select {
 	case <-support.Blocks:
 	case <-time.After(time.Second):
@@ -145,10 +152,18 @@ func TestBatchTimer(t *testing.T) {
 
 	support.SharedConfigVal.BatchTimeoutVal, _ = time.ParseDuration("10s")
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
+	clk.BlockUntil(1)
+	clk.Advance(batchTimeout)
 	select {
 	case <-support.Blocks:
 		t.Fatalf("Created another batch, indicating that the timer was not appopriately re-read")
-	case <-time.After(100 * time.Millisecond):
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Advance(10 * time.Second)
+	select {
+	case <-support.Blocks:
+	case <-time.After(time.Second):
+		t.Fatalf("Did not create the third batch once the new batch timeout passed")
 	}
 
 	bs.Halt()
@@ -168,7 +183,8 @@ func TestBatchTimerHaltOnFilledBatch(t *testing.T) {
 	}
 	defer close(support.BlockCutterVal.Block)
 
-	bs := newChain(support)
+	clk := clock.NewManual(time.Unix(0, 0))
+	bs := newChain(support, clk)
 	wg := goWithWait(bs.main)
 	defer bs.Halt()
 
@@ -182,11 +198,13 @@ func TestBatchTimerHaltOnFilledBatch(t *testing.T) {
 		t.Fatalf("Expected a block to be cut because the batch was filled, but did not")
 	}
 
-	// Change the batch timeout to be near instant, if the timer was not reset, it will still be waiting an hour
+	// Shorten the batch timeout, if the timer was not reset, the chain will still be waiting an hour
 	support.SharedConfigVal.BatchTimeoutVal = time.Millisecond
 
 	support.BlockCutterVal.CutNext = false
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
+	clk.BlockUntil(2)
+	clk.Advance(time.Millisecond)
 
 	select {
 	case <-support.Blocks:
@@ -210,7 +228,7 @@ func TestConfigStyleMultiBatch(t *testing.T) {
 		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
 	}
 	defer close(support.BlockCutterVal.Block)
-	bs := newChain(support)
+	bs := newChain(support, clock.Real())
 	wg := goWithWait(bs.main)
 	defer bs.Halt()
 
@@ -238,6 +256,33 @@ func TestConfigStyleMultiBatch(t *testing.T) {
 	}
 }
 
+func TestUrgentFirst(t *testing.T) {
+	batchTimeout, _ := time.ParseDuration("1h")
+	support := &mockmultichain.ConsenterSupport{
+		Blocks:          make(chan *cb.Block),
+		BlockCutterVal:  mockblockcutter.NewReceiver(),
+		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+	}
+	defer close(support.BlockCutterVal.Block)
+	bs := newChain(support, clock.Real())
+	defer bs.Halt()
+
+	configMessage := &filter.Message{
+		Envelope:      &cb.Envelope{Payload: []byte("CONFIG_MESSAGE")},
+		ChannelHeader: &cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG)},
+	}
+	go bs.Enqueue(testMessage)
+	go bs.Enqueue(configMessage)
+	// Let both messages wait before the chain starts ordering
+	time.Sleep(100 * time.Millisecond)
+
+	go bs.main()
+	support.BlockCutterVal.Block <- struct{}{}
+	support.BlockCutterVal.Block <- struct{}{}
+	assert.Equal(t, []*cb.Envelope{configMessage.Envelope, testMessage.Envelope}, support.BlockCutterVal.CurBatch,
+		"The config message should have been ordered before the message which waited longer")
+}
+
 // This test checks that solo consenter could recover from an erroneous situation
 // where empty batch is cut
 func TestRecoverFromError(t *testing.T) {
@@ -248,12 +293,15 @@ func TestRecoverFromError(t *testing.T) {
 		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
 	}
 	defer close(support.BlockCutterVal.Block)
-	bs := newChain(support)
+	clk := clock.NewManual(time.Unix(0, 0))
+	bs := newChain(support, clk)
 	_ = goWithWait(bs.main)
 	defer bs.Halt()
 
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
+	clk.BlockUntil(1)
 	support.BlockCutterVal.CurBatch = nil
+	clk.Advance(batchTimeout)
 
 	select {
 	case <-support.Blocks:
@@ -12,6 +12,9 @@ It is generated from these files:
 	common/policies.proto
 
 It has these top-level messages:
+	TransactionExtensions
+	HybridTimestamp
+	OrderingTimestamps
 	LastConfig
 	Metadata
 	MetadataSignature
@@ -69,6 +72,7 @@ type Status int32
 const (
 	Status_UNKNOWN                  Status = 0
 	Status_SUCCESS                  Status = 200
+	Status_TEMPORARY_REDIRECT       Status = 307
 	Status_BAD_REQUEST              Status = 400
 	Status_FORBIDDEN                Status = 403
 	Status_NOT_FOUND                Status = 404
@@ -80,6 +84,7 @@ const (
 var Status_name = map[int32]string{
 	0:   "UNKNOWN",
 	200: "SUCCESS",
+	307: "TEMPORARY_REDIRECT",
 	400: "BAD_REQUEST",
 	403: "FORBIDDEN",
 	404: "NOT_FOUND",
@@ -90,6 +95,7 @@ var Status_name = map[int32]string{
 var Status_value = map[string]int32{
 	"UNKNOWN":                  0,
 	"SUCCESS":                  200,
+	"TEMPORARY_REDIRECT":       307,
 	"BAD_REQUEST":              400,
 	"FORBIDDEN":                403,
 	"NOT_FOUND":                404,
@@ -147,6 +153,9 @@ const (
 	BlockMetadataIndex_LAST_CONFIG         BlockMetadataIndex = 1
 	BlockMetadataIndex_TRANSACTIONS_FILTER BlockMetadataIndex = 2
 	BlockMetadataIndex_ORDERER             BlockMetadataIndex = 3
+	// e.g. For Kafka, this is where we store the last offset written to the local ledger.
+	BlockMetadataIndex_TIMESTAMP  BlockMetadataIndex = 4
+	BlockMetadataIndex_EXTENSIONS BlockMetadataIndex = 5
 )
 
 var BlockMetadataIndex_name = map[int32]string{
@@ -154,12 +163,16 @@ var BlockMetadataIndex_name = map[int32]string{
 	1: "LAST_CONFIG",
 	2: "TRANSACTIONS_FILTER",
 	3: "ORDERER",
+	4: "TIMESTAMP",
+	5: "EXTENSIONS",
 }
 var BlockMetadataIndex_value = map[string]int32{
 	"SIGNATURES":          0,
 	"LAST_CONFIG":         1,
 	"TRANSACTIONS_FILTER": 2,
 	"ORDERER":             3,
+	"TIMESTAMP":           4,
+	"EXTENSIONS":          5,
 }
 
 func (x BlockMetadataIndex) String() string {
@@ -167,6 +180,82 @@ func (x BlockMetadataIndex) String() string {
 }
 func (BlockMetadataIndex) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+// TransactionExtensions is the encoded value for the Metadata message which
+// is encoded in the EXTENSIONS block metadata index. It holds the extensions
+// each transaction of the block carries in the orderer extension of its
+// channel header, by the position of the transaction in the block, and is
+// empty for the transactions which carry none.
+type TransactionExtensions struct {
+	Transactions [][]byte `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+}
+
+func (m *TransactionExtensions) Reset()                    { *m = TransactionExtensions{} }
+func (m *TransactionExtensions) String() string            { return proto.CompactTextString(m) }
+func (*TransactionExtensions) ProtoMessage()               {}
+func (*TransactionExtensions) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *TransactionExtensions) GetTransactions() [][]byte {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+// HybridTimestamp is a time of a hybrid logical clock: the physical time, in
+// nanoseconds since the Unix epoch, which never goes backwards, and a logical
+// counter ordering the events stamped with the same physical time
+type HybridTimestamp struct {
+	Physical int64  `protobuf:"varint,1,opt,name=physical" json:"physical,omitempty"`
+	Logical  uint32 `protobuf:"varint,2,opt,name=logical" json:"logical,omitempty"`
+}
+
+func (m *HybridTimestamp) Reset()                    { *m = HybridTimestamp{} }
+func (m *HybridTimestamp) String() string            { return proto.CompactTextString(m) }
+func (*HybridTimestamp) ProtoMessage()               {}
+func (*HybridTimestamp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *HybridTimestamp) GetPhysical() int64 {
+	if m != nil {
+		return m.Physical
+	}
+	return 0
+}
+
+func (m *HybridTimestamp) GetLogical() uint32 {
+	if m != nil {
+		return m.Logical
+	}
+	return 0
+}
+
+// OrderingTimestamps is the encoded value for the Metadata message which is
+// encoded in the TIMESTAMP block metadata index. Each block is stamped later
+// than the block before it, and its transactions, if stamped, in the order of
+// their positions in the block.
+type OrderingTimestamps struct {
+	Block        *HybridTimestamp   `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+	Transactions []*HybridTimestamp `protobuf:"bytes,2,rep,name=transactions" json:"transactions,omitempty"`
+}
+
+func (m *OrderingTimestamps) Reset()                    { *m = OrderingTimestamps{} }
+func (m *OrderingTimestamps) String() string            { return proto.CompactTextString(m) }
+func (*OrderingTimestamps) ProtoMessage()               {}
+func (*OrderingTimestamps) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *OrderingTimestamps) GetBlock() *HybridTimestamp {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (m *OrderingTimestamps) GetTransactions() []*HybridTimestamp {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
 // LastConfig is the encoded value for the Metadata message which is encoded in the LAST_CONFIGURATION block metadata index
 type LastConfig struct {
 	Index uint64 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
@@ -175,7 +264,7 @@ type LastConfig struct {
 func (m *LastConfig) Reset()                    { *m = LastConfig{} }
 func (m *LastConfig) String() string            { return proto.CompactTextString(m) }
 func (*LastConfig) ProtoMessage()               {}
-func (*LastConfig) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+func (*LastConfig) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 func (m *LastConfig) GetIndex() uint64 {
 	if m != nil {
@@ -193,7 +282,7 @@ type Metadata struct {
 func (m *Metadata) Reset()                    { *m = Metadata{} }
 func (m *Metadata) String() string            { return proto.CompactTextString(m) }
 func (*Metadata) ProtoMessage()               {}
-func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 func (m *Metadata) GetValue() []byte {
 	if m != nil {
@@ -217,7 +306,7 @@ type MetadataSignature struct {
 func (m *MetadataSignature) Reset()                    { *m = MetadataSignature{} }
 func (m *MetadataSignature) String() string            { return proto.CompactTextString(m) }
 func (*MetadataSignature) ProtoMessage()               {}
-func (*MetadataSignature) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*MetadataSignature) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 func (m *MetadataSignature) GetSignatureHeader() []byte {
 	if m != nil {
@@ -241,7 +330,7 @@ type Header struct {
 func (m *Header) Reset()                    { *m = Header{} }
 func (m *Header) String() string            { return proto.CompactTextString(m) }
 func (*Header) ProtoMessage()               {}
-func (*Header) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*Header) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *Header) GetChannelHeader() []byte {
 	if m != nil {
@@ -284,12 +373,20 @@ type ChannelHeader struct {
 	Epoch uint64 `protobuf:"varint,6,opt,name=epoch" json:"epoch,omitempty"`
 	// Extension that may be attached based on the header type
 	Extension []byte `protobuf:"bytes,7,opt,name=extension,proto3" json:"extension,omitempty"`
+	// Orderer extension is a marshaled orderer.OrdererHeaderExtension, which
+	// may be attached to a header of any type to request its treatment by the
+	// ordering service
+	OrdererExtension []byte `protobuf:"bytes,8,opt,name=orderer_extension,json=ordererExtension,proto3" json:"orderer_extension,omitempty"`
+	// TLS cert hash is the SHA256 hash of the TLS client certificate of the
+	// sender, binding the message to the connection it is sent on, so that
+	// it cannot be replayed on another
+	TlsCertHash []byte `protobuf:"bytes,9,opt,name=tls_cert_hash,json=tlsCertHash,proto3" json:"tls_cert_hash,omitempty"`
 }
 
 func (m *ChannelHeader) Reset()                    { *m = ChannelHeader{} }
 func (m *ChannelHeader) String() string            { return proto.CompactTextString(m) }
 func (*ChannelHeader) ProtoMessage()               {}
-func (*ChannelHeader) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*ChannelHeader) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *ChannelHeader) GetType() int32 {
 	if m != nil {
@@ -340,6 +437,20 @@ func (m *ChannelHeader) GetExtension() []byte {
 	return nil
 }
 
+func (m *ChannelHeader) GetOrdererExtension() []byte {
+	if m != nil {
+		return m.OrdererExtension
+	}
+	return nil
+}
+
+func (m *ChannelHeader) GetTlsCertHash() []byte {
+	if m != nil {
+		return m.TlsCertHash
+	}
+	return nil
+}
+
 type SignatureHeader struct {
 	// Creator of the message, specified as a certificate chain
 	Creator []byte `protobuf:"bytes,1,opt,name=creator,proto3" json:"creator,omitempty"`
@@ -350,7 +461,7 @@ type SignatureHeader struct {
 func (m *SignatureHeader) Reset()                    { *m = SignatureHeader{} }
 func (m *SignatureHeader) String() string            { return proto.CompactTextString(m) }
 func (*SignatureHeader) ProtoMessage()               {}
-func (*SignatureHeader) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*SignatureHeader) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *SignatureHeader) GetCreator() []byte {
 	if m != nil {
@@ -377,7 +488,7 @@ type Payload struct {
 func (m *Payload) Reset()                    { *m = Payload{} }
 func (m *Payload) String() string            { return proto.CompactTextString(m) }
 func (*Payload) ProtoMessage()               {}
-func (*Payload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*Payload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 func (m *Payload) GetHeader() *Header {
 	if m != nil {
@@ -404,7 +515,7 @@ type Envelope struct {
 func (m *Envelope) Reset()                    { *m = Envelope{} }
 func (m *Envelope) String() string            { return proto.CompactTextString(m) }
 func (*Envelope) ProtoMessage()               {}
-func (*Envelope) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*Envelope) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *Envelope) GetPayload() []byte {
 	if m != nil {
@@ -433,7 +544,7 @@ type Block struct {
 func (m *Block) Reset()                    { *m = Block{} }
 func (m *Block) String() string            { return proto.CompactTextString(m) }
 func (*Block) ProtoMessage()               {}
-func (*Block) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*Block) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *Block) GetHeader() *BlockHeader {
 	if m != nil {
@@ -468,7 +579,7 @@ type BlockHeader struct {
 func (m *BlockHeader) Reset()                    { *m = BlockHeader{} }
 func (m *BlockHeader) String() string            { return proto.CompactTextString(m) }
 func (*BlockHeader) ProtoMessage()               {}
-func (*BlockHeader) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*BlockHeader) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 func (m *BlockHeader) GetNumber() uint64 {
 	if m != nil {
@@ -498,7 +609,7 @@ type BlockData struct {
 func (m *BlockData) Reset()                    { *m = BlockData{} }
 func (m *BlockData) String() string            { return proto.CompactTextString(m) }
 func (*BlockData) ProtoMessage()               {}
-func (*BlockData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*BlockData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *BlockData) GetData() [][]byte {
 	if m != nil {
@@ -514,7 +625,7 @@ type BlockMetadata struct {
 func (m *BlockMetadata) Reset()                    { *m = BlockMetadata{} }
 func (m *BlockMetadata) String() string            { return proto.CompactTextString(m) }
 func (*BlockMetadata) ProtoMessage()               {}
-func (*BlockMetadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*BlockMetadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 func (m *BlockMetadata) GetMetadata() [][]byte {
 	if m != nil {
@@ -524,6 +635,9 @@ func (m *BlockMetadata) GetMetadata() [][]byte {
 }
 
 func init() {
+	proto.RegisterType((*TransactionExtensions)(nil), "common.TransactionExtensions")
+	proto.RegisterType((*HybridTimestamp)(nil), "common.HybridTimestamp")
+	proto.RegisterType((*OrderingTimestamps)(nil), "common.OrderingTimestamps")
 	proto.RegisterType((*LastConfig)(nil), "common.LastConfig")
 	proto.RegisterType((*Metadata)(nil), "common.Metadata")
 	proto.RegisterType((*MetadataSignature)(nil), "common.MetadataSignature")
@@ -544,62 +658,73 @@ func init() {
 func init() { proto.RegisterFile("common/common.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 900 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x55, 0xdf, 0x6e, 0xe3, 0xc4,
-	0x1b, 0xad, 0xe3, 0xfc, 0x69, 0xbe, 0x34, 0xad, 0x3b, 0xd9, 0xfe, 0xd6, 0xbf, 0xc2, 0x6a, 0x23,
-	0xc3, 0xa2, 0xd2, 0x4a, 0x89, 0x28, 0x37, 0x70, 0xe9, 0xd8, 0x93, 0xd6, 0x6a, 0xd6, 0x2e, 0x63,
-	0x67, 0x11, 0xbb, 0x48, 0x96, 0x93, 0x4c, 0x13, 0x8b, 0xc4, 0x8e, 0x6c, 0xa7, 0x6a, 0x5f, 0x02,
-	0x21, 0xc1, 0x0d, 0x17, 0xbc, 0x00, 0x4f, 0xc2, 0x5b, 0xf0, 0x12, 0x48, 0xdc, 0xa2, 0xf1, 0x8c,
-	0xbd, 0x49, 0x59, 0x89, 0xab, 0xcc, 0x39, 0x73, 0x3c, 0xdf, 0x99, 0xf3, 0x7d, 0xb1, 0xa1, 0x33,
-	0x8d, 0x57, 0xab, 0x38, 0xea, 0xf3, 0x9f, 0xde, 0x3a, 0x89, 0xb3, 0x18, 0xd5, 0x39, 0x3a, 0x7d,
-	0x39, 0x8f, 0xe3, 0xf9, 0x92, 0xf6, 0x73, 0x76, 0xb2, 0xb9, 0xeb, 0x67, 0xe1, 0x8a, 0xa6, 0x59,
-	0xb0, 0x5a, 0x73, 0xa1, 0xa6, 0x01, 0x8c, 0x82, 0x34, 0x33, 0xe2, 0xe8, 0x2e, 0x9c, 0xa3, 0x67,
-	0x50, 0x0b, 0xa3, 0x19, 0x7d, 0x50, 0xa5, 0xae, 0x74, 0x56, 0x25, 0x1c, 0x68, 0xef, 0x60, 0xff,
-	0x35, 0xcd, 0x82, 0x59, 0x90, 0x05, 0x4c, 0x71, 0x1f, 0x2c, 0x37, 0x34, 0x57, 0x1c, 0x10, 0x0e,
-	0xd0, 0xd7, 0x00, 0x69, 0x38, 0x8f, 0x82, 0x6c, 0x93, 0xd0, 0x54, 0xad, 0x74, 0xe5, 0xb3, 0xd6,
-	0xe5, 0xff, 0x7b, 0xc2, 0x51, 0xf1, 0xac, 0x5b, 0x28, 0xc8, 0x96, 0x58, 0xfb, 0x1e, 0x8e, 0xff,
-	0x25, 0x40, 0x9f, 0x83, 0x52, 0x4a, 0xfc, 0x05, 0x0d, 0x66, 0x34, 0x11, 0x05, 0x8f, 0x4a, 0xfe,
-	0x3a, 0xa7, 0xd1, 0xc7, 0xd0, 0x2c, 0x29, 0xb5, 0x92, 0x6b, 0xde, 0x13, 0xda, 0x5b, 0xa8, 0x0b,
-	0xdd, 0x2b, 0x38, 0x9c, 0x2e, 0x82, 0x28, 0xa2, 0xcb, 0xdd, 0x03, 0xdb, 0x82, 0x15, 0xb2, 0x0f,
-	0x55, 0xae, 0x7c, 0xb0, 0xb2, 0xf6, 0xa7, 0x04, 0x6d, 0x63, 0xe7, 0x61, 0x04, 0xd5, 0xec, 0x71,
-	0xcd, 0xb3, 0xa9, 0x91, 0x7c, 0x8d, 0x54, 0x68, 0xdc, 0xd3, 0x24, 0x0d, 0xe3, 0x28, 0x3f, 0xa7,
-	0x46, 0x0a, 0x88, 0xbe, 0x82, 0x66, 0xd9, 0x0d, 0x55, 0xee, 0x4a, 0x67, 0xad, 0xcb, 0xd3, 0x1e,
-	0xef, 0x57, 0xaf, 0xe8, 0x57, 0xcf, 0x2b, 0x14, 0xe4, 0xbd, 0x18, 0xbd, 0x00, 0x28, 0xee, 0x12,
-	0xce, 0xd4, 0x6a, 0x57, 0x3a, 0x6b, 0x92, 0xa6, 0x60, 0xac, 0x19, 0xea, 0x40, 0x2d, 0x7b, 0x60,
-	0x3b, 0xb5, 0x7c, 0xa7, 0x9a, 0x3d, 0x58, 0x33, 0xd6, 0x38, 0xba, 0x8e, 0xa7, 0x0b, 0xb5, 0xce,
-	0x5b, 0x9b, 0x03, 0x96, 0x1e, 0x7d, 0xc8, 0x68, 0x94, 0xfb, 0x6b, 0xf0, 0xf4, 0x4a, 0x42, 0xd3,
-	0xe1, 0xc8, 0x7d, 0x12, 0xb7, 0x0a, 0x8d, 0x69, 0x42, 0x83, 0x2c, 0x2e, 0xf2, 0x2b, 0x20, 0x2b,
-	0x10, 0xc5, 0xd1, 0xb4, 0x68, 0x02, 0x07, 0x1a, 0x86, 0xc6, 0x6d, 0xf0, 0xb8, 0x8c, 0x83, 0x19,
-	0xfa, 0x0c, 0xea, 0x5b, 0xc9, 0xb7, 0x2e, 0x0f, 0x8b, 0x01, 0xe1, 0x47, 0x13, 0xb1, 0xcb, 0x52,
-	0x64, 0xd3, 0x20, 0xce, 0xc9, 0xd7, 0xda, 0x00, 0xf6, 0x71, 0x74, 0x4f, 0x97, 0x31, 0x4f, 0x74,
-	0xcd, 0x8f, 0x2c, 0x2c, 0x08, 0xf8, 0x1f, 0xb3, 0xf0, 0xa3, 0x04, 0xb5, 0xc1, 0x32, 0x9e, 0xfe,
-	0x80, 0x2e, 0x9e, 0x38, 0xe9, 0x14, 0x4e, 0xf2, 0xed, 0x27, 0x76, 0x5e, 0x6d, 0xd9, 0x69, 0x5d,
-	0x1e, 0xef, 0x48, 0xcd, 0x20, 0x0b, 0xb8, 0x43, 0xf4, 0x05, 0xec, 0xaf, 0xc4, 0x1c, 0x8b, 0x66,
-	0x9e, 0xec, 0x48, 0x8b, 0x21, 0x27, 0xa5, 0x4c, 0x9b, 0x43, 0x6b, 0xab, 0x20, 0xfa, 0x1f, 0xd4,
-	0xa3, 0xcd, 0x6a, 0x22, 0x5c, 0x55, 0x89, 0x40, 0xe8, 0x13, 0x68, 0xaf, 0x13, 0x7a, 0x1f, 0xc6,
-	0x9b, 0xd4, 0x5f, 0x04, 0xe9, 0x42, 0xdc, 0xec, 0xa0, 0x20, 0xaf, 0x83, 0x74, 0x81, 0x3e, 0x82,
-	0x26, 0x3b, 0x93, 0x0b, 0xe4, 0x5c, 0xb0, 0xcf, 0x08, 0xb6, 0xa9, 0xbd, 0x84, 0x66, 0x69, 0xb7,
-	0x8c, 0x57, 0xea, 0xca, 0x65, 0xbc, 0x17, 0xd0, 0xde, 0x31, 0x89, 0x4e, 0xb7, 0x6e, 0xc3, 0x85,
-	0x25, 0x3e, 0xff, 0x5d, 0x82, 0xba, 0x9b, 0x05, 0xd9, 0x26, 0x45, 0x2d, 0x68, 0x8c, 0xed, 0x1b,
-	0xdb, 0xf9, 0xd6, 0x56, 0xf6, 0xd0, 0x01, 0x34, 0xdc, 0xb1, 0x61, 0x60, 0xd7, 0x55, 0xfe, 0x90,
-	0x90, 0x02, 0xad, 0x81, 0x6e, 0xfa, 0x04, 0x7f, 0x33, 0xc6, 0xae, 0xa7, 0xfc, 0x24, 0xa3, 0x43,
-	0x68, 0x0e, 0x1d, 0x32, 0xb0, 0x4c, 0x13, 0xdb, 0xca, 0xcf, 0x39, 0xb6, 0x1d, 0xcf, 0x1f, 0x3a,
-	0x63, 0xdb, 0x54, 0x7e, 0x91, 0xd1, 0x0b, 0x50, 0x85, 0xda, 0xc7, 0xb6, 0x67, 0x79, 0xdf, 0xf9,
-	0x9e, 0xe3, 0xf8, 0x23, 0x9d, 0x5c, 0x61, 0xe5, 0x37, 0x19, 0x9d, 0xc2, 0x89, 0x65, 0x7b, 0x98,
-	0xd8, 0xfa, 0xc8, 0x77, 0x31, 0x79, 0x83, 0x89, 0x8f, 0x09, 0x71, 0x88, 0xf2, 0x97, 0x8c, 0x54,
-	0xe8, 0x30, 0xca, 0x32, 0xb0, 0x3f, 0xb6, 0xf5, 0x37, 0xba, 0x35, 0xd2, 0x07, 0x23, 0xac, 0xfc,
-	0x2d, 0x9f, 0xff, 0x2a, 0x01, 0xf0, 0x7c, 0x3d, 0xf6, 0x6f, 0x6c, 0x41, 0xe3, 0x35, 0x76, 0x5d,
-	0xfd, 0x0a, 0x2b, 0x7b, 0x08, 0xa0, 0x6e, 0x38, 0xf6, 0xd0, 0xba, 0x52, 0x24, 0x74, 0x0c, 0x6d,
-	0xbe, 0xf6, 0xc7, 0xb7, 0xa6, 0xee, 0x61, 0xa5, 0x82, 0x54, 0x78, 0x86, 0x6d, 0xd3, 0x21, 0x2e,
-	0x26, 0xbe, 0x47, 0x74, 0xdb, 0xd5, 0x0d, 0xcf, 0x72, 0x6c, 0x45, 0x46, 0xcf, 0xa1, 0xe3, 0x10,
-	0x13, 0x93, 0x27, 0x1b, 0x55, 0x74, 0x02, 0xc7, 0x26, 0x1e, 0x59, 0xcc, 0x9b, 0x8b, 0xf1, 0x8d,
-	0x6f, 0xd9, 0x43, 0x47, 0xa9, 0x31, 0xda, 0xb8, 0xd6, 0x2d, 0xdb, 0x70, 0x4c, 0xec, 0xdf, 0xea,
-	0xc6, 0x0d, 0xab, 0x5f, 0x3f, 0x7f, 0x07, 0x68, 0x27, 0x75, 0x8b, 0xbd, 0x6d, 0xd1, 0x21, 0x80,
-	0x6b, 0x5d, 0xd9, 0xba, 0x37, 0x26, 0xd8, 0x55, 0xf6, 0xd0, 0x11, 0xb4, 0x46, 0xba, 0xeb, 0xf9,
-	0xa5, 0xd5, 0xe7, 0xd0, 0xd9, 0xaa, 0xea, 0xfa, 0x43, 0x6b, 0xe4, 0x61, 0xa2, 0x54, 0xd8, 0xe5,
-	0x84, 0x2d, 0x45, 0x1e, 0xb8, 0xf0, 0x69, 0x9c, 0xcc, 0x7b, 0x8b, 0xc7, 0x35, 0x4d, 0x96, 0x74,
-	0x36, 0xa7, 0x49, 0xef, 0x2e, 0x98, 0x24, 0xe1, 0x94, 0xbf, 0x5b, 0x52, 0x31, 0x9c, 0x6f, 0x2f,
-	0xe6, 0x61, 0xb6, 0xd8, 0x4c, 0x18, 0xec, 0x6f, 0x89, 0xfb, 0x5c, 0xcc, 0x3f, 0x1c, 0xa9, 0xf8,
-	0xb8, 0x4c, 0xea, 0x39, 0xfc, 0xf2, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xa1, 0xcb, 0xe1, 0xb4,
-	0x74, 0x06, 0x00, 0x00,
+	// 1081 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xdd, 0x6e, 0xe3, 0x44,
+	0x14, 0x6e, 0xe2, 0xfc, 0x9e, 0x24, 0xad, 0x3b, 0xd9, 0x52, 0x53, 0x58, 0x6d, 0x65, 0x58, 0x54,
+	0x5a, 0x91, 0x8a, 0x72, 0x03, 0xda, 0x2b, 0xc7, 0x99, 0xb6, 0x56, 0x53, 0xbb, 0x8c, 0x9d, 0x85,
+	0x5d, 0x90, 0x2c, 0x27, 0x99, 0x26, 0x16, 0x89, 0x1d, 0xd9, 0x4e, 0xd5, 0x72, 0xc5, 0x13, 0x20,
+	0x24, 0xb8, 0xe1, 0x82, 0x77, 0x40, 0xe2, 0x11, 0xb8, 0xe1, 0x81, 0x90, 0xb8, 0x45, 0x33, 0x63,
+	0xbb, 0x49, 0x58, 0xb1, 0x57, 0xf1, 0x77, 0xe6, 0x9b, 0x73, 0xbe, 0x39, 0xdf, 0x99, 0x09, 0xb4,
+	0x47, 0xe1, 0x7c, 0x1e, 0x06, 0xa7, 0xe2, 0xa7, 0xb3, 0x88, 0xc2, 0x24, 0x44, 0x15, 0x81, 0x0e,
+	0x9e, 0x4d, 0xc2, 0x70, 0x32, 0xa3, 0xa7, 0x3c, 0x3a, 0x5c, 0xde, 0x9e, 0x26, 0xfe, 0x9c, 0xc6,
+	0x89, 0x37, 0x5f, 0x08, 0xa2, 0xfa, 0x02, 0xf6, 0x9c, 0xc8, 0x0b, 0x62, 0x6f, 0x94, 0xf8, 0x61,
+	0x80, 0xef, 0x13, 0x1a, 0xc4, 0x7e, 0x18, 0xc4, 0x48, 0x85, 0x66, 0xf2, 0xb8, 0x10, 0x2b, 0x85,
+	0x43, 0xe9, 0xa8, 0x49, 0xd6, 0x62, 0xea, 0x05, 0xec, 0x5c, 0x3e, 0x0c, 0x23, 0x7f, 0xec, 0x64,
+	0x59, 0xd1, 0x01, 0xd4, 0x16, 0xd3, 0x87, 0xd8, 0x1f, 0x79, 0x33, 0xa5, 0x70, 0x58, 0x38, 0x92,
+	0x48, 0x8e, 0x91, 0x02, 0xd5, 0x59, 0x38, 0xe1, 0x4b, 0xc5, 0xc3, 0xc2, 0x51, 0x8b, 0x64, 0x50,
+	0xfd, 0xa1, 0x00, 0xc8, 0x8a, 0xc6, 0x34, 0xf2, 0x83, 0x49, 0x9e, 0x2b, 0x46, 0x9f, 0x40, 0x79,
+	0x38, 0x0b, 0x47, 0xdf, 0xf1, 0x4c, 0x8d, 0xb3, 0xfd, 0x4e, 0x7a, 0xc6, 0x8d, 0xa2, 0x44, 0xb0,
+	0xd0, 0x8b, 0x0d, 0xc9, 0xc5, 0x43, 0xe9, 0xff, 0x76, 0xad, 0x9f, 0x45, 0x05, 0xe8, 0x7b, 0x71,
+	0xa2, 0x87, 0xc1, 0xad, 0x3f, 0x41, 0x4f, 0xa0, 0xec, 0x07, 0x63, 0x7a, 0xcf, 0x2b, 0x97, 0x88,
+	0x00, 0xea, 0x37, 0x50, 0xbb, 0xa6, 0x89, 0x37, 0xf6, 0x12, 0x8f, 0x31, 0xee, 0xbc, 0xd9, 0x92,
+	0x72, 0x46, 0x93, 0x08, 0x80, 0xbe, 0x00, 0x88, 0xfd, 0x49, 0xe0, 0x25, 0xcb, 0x88, 0x66, 0x02,
+	0xde, 0xcd, 0x04, 0x64, 0x7b, 0xed, 0x8c, 0x41, 0x56, 0xc8, 0xea, 0xb7, 0xb0, 0xfb, 0x1f, 0x02,
+	0xfa, 0x18, 0xe4, 0x9c, 0xe2, 0x4e, 0xa9, 0x37, 0xa6, 0x51, 0x5a, 0x70, 0x27, 0x8f, 0x5f, 0xf2,
+	0x30, 0x7a, 0x1f, 0xea, 0x79, 0x88, 0xf7, 0xb7, 0x49, 0x1e, 0x03, 0xea, 0x6b, 0xa8, 0xa4, 0xbc,
+	0xe7, 0xb0, 0x3d, 0x9a, 0x7a, 0x41, 0x40, 0x67, 0xeb, 0x09, 0x5b, 0x69, 0x34, 0xa5, 0xbd, 0xa9,
+	0x72, 0xf1, 0x8d, 0x95, 0xd5, 0xdf, 0x8b, 0xd0, 0xd2, 0xd7, 0x36, 0x23, 0x28, 0x25, 0x0f, 0x0b,
+	0xd1, 0x9b, 0x32, 0xe1, 0xdf, 0xcc, 0xfd, 0x3b, 0x1a, 0xb1, 0xe1, 0xe2, 0x79, 0xca, 0x24, 0x83,
+	0xe8, 0x73, 0xa8, 0xe7, 0x63, 0xa9, 0x48, 0xdc, 0xea, 0x83, 0x8e, 0x18, 0xdc, 0x4e, 0x36, 0xb8,
+	0x9d, 0x47, 0xdf, 0x1e, 0xc9, 0xe8, 0x29, 0x40, 0x76, 0x16, 0x7f, 0xac, 0x94, 0x0e, 0x0b, 0x47,
+	0x75, 0x52, 0x4f, 0x23, 0xc6, 0x18, 0xb5, 0xa1, 0x9c, 0xdc, 0xb3, 0x95, 0x32, 0x5f, 0x29, 0x25,
+	0xf7, 0xc6, 0x98, 0x19, 0x47, 0x17, 0xe1, 0x68, 0xaa, 0x54, 0x84, 0xb5, 0x1c, 0xb0, 0xee, 0xd1,
+	0x6c, 0xf8, 0x95, 0xaa, 0xe8, 0x5e, 0x1e, 0x40, 0x27, 0xb0, 0x1b, 0xb2, 0xf1, 0xa4, 0x91, 0xfb,
+	0xc8, 0xaa, 0x71, 0x96, 0x9c, 0x2e, 0xe4, 0x57, 0x07, 0xa9, 0xd0, 0x4a, 0x66, 0xb1, 0x3b, 0xa2,
+	0x51, 0xe2, 0x4e, 0xbd, 0x78, 0xaa, 0xd4, 0x39, 0xb1, 0x91, 0xcc, 0x62, 0x9d, 0x46, 0xc9, 0xa5,
+	0x17, 0x4f, 0x55, 0x0d, 0x76, 0xec, 0x0d, 0xff, 0x14, 0xa8, 0x8e, 0x22, 0xea, 0x25, 0x61, 0x66,
+	0x48, 0x06, 0x99, 0xe2, 0x20, 0x0c, 0x46, 0x99, 0xab, 0x02, 0xa8, 0x18, 0xaa, 0x37, 0xde, 0xc3,
+	0x2c, 0xf4, 0xc6, 0xe8, 0x23, 0xa8, 0xac, 0x58, 0xd9, 0x38, 0xdb, 0xce, 0x47, 0x9e, 0x47, 0x49,
+	0x65, 0x9a, 0xdb, 0xc2, 0xc6, 0x2b, 0xcd, 0xc3, 0xbf, 0xd5, 0x2e, 0xd4, 0x70, 0x70, 0x47, 0x67,
+	0xa1, 0xb0, 0x68, 0x21, 0x52, 0x66, 0x12, 0x52, 0xf8, 0x96, 0xe1, 0xfa, 0xb1, 0x00, 0xe5, 0x2e,
+	0xbf, 0x82, 0x27, 0x1b, 0x4a, 0xda, 0x99, 0x12, 0xbe, 0xbc, 0x21, 0xe7, 0xf9, 0x8a, 0x9c, 0xc6,
+	0xd9, 0xee, 0x1a, 0xb5, 0xe7, 0x25, 0x9e, 0x50, 0x88, 0x3e, 0x85, 0xda, 0x3c, 0xbd, 0x18, 0xe9,
+	0x74, 0xec, 0xad, 0x51, 0xb3, 0x5b, 0x43, 0x72, 0x9a, 0x3a, 0x81, 0xc6, 0x4a, 0x41, 0xf4, 0x0e,
+	0x54, 0x82, 0xe5, 0x7c, 0x98, 0xaa, 0x2a, 0x91, 0x14, 0xa1, 0x0f, 0xa0, 0xb5, 0x88, 0xe8, 0x9d,
+	0x1f, 0x2e, 0x63, 0xe1, 0x94, 0x38, 0x59, 0x33, 0x0b, 0x32, 0xab, 0xd0, 0x7b, 0x50, 0x67, 0x39,
+	0x05, 0x41, 0xe2, 0x84, 0x1a, 0x0b, 0x70, 0x1f, 0x9f, 0x41, 0x3d, 0x97, 0x9b, 0xb7, 0x57, 0x3c,
+	0x95, 0xa2, 0xbd, 0x27, 0xd0, 0x5a, 0x13, 0xc9, 0x1e, 0xc8, 0xfc, 0x34, 0x82, 0x98, 0xe3, 0xe3,
+	0x3f, 0x0b, 0x50, 0xb1, 0x13, 0x2f, 0x59, 0xc6, 0xa8, 0x01, 0xd5, 0x81, 0x79, 0x65, 0x5a, 0x5f,
+	0x99, 0xf2, 0x16, 0x6a, 0x42, 0xd5, 0x1e, 0xe8, 0x3a, 0xb6, 0x6d, 0xf9, 0xaf, 0x02, 0xda, 0x07,
+	0xe4, 0xe0, 0xeb, 0x1b, 0x8b, 0x68, 0xe4, 0x95, 0x4b, 0x70, 0xcf, 0x20, 0x58, 0x77, 0xe4, 0x3f,
+	0x8a, 0x48, 0x86, 0x46, 0x57, 0xeb, 0xb9, 0x04, 0x7f, 0x39, 0xc0, 0xb6, 0x23, 0xff, 0x24, 0xa1,
+	0x6d, 0xa8, 0x9f, 0x5b, 0xa4, 0x6b, 0xf4, 0x7a, 0xd8, 0x94, 0x7f, 0xe6, 0xd8, 0xb4, 0x1c, 0xf7,
+	0xdc, 0x1a, 0x98, 0x3d, 0xf9, 0x17, 0x09, 0x3d, 0x05, 0x25, 0x65, 0xbb, 0xd8, 0x74, 0x0c, 0xe7,
+	0x95, 0xeb, 0x58, 0x96, 0xdb, 0xd7, 0xc8, 0x05, 0x96, 0x7f, 0x93, 0xd0, 0x01, 0xec, 0x19, 0xa6,
+	0x83, 0x89, 0xa9, 0xf5, 0x5d, 0x1b, 0x93, 0x97, 0x98, 0xb8, 0x98, 0x10, 0x8b, 0xc8, 0x7f, 0x4b,
+	0x48, 0x81, 0x36, 0x0b, 0x19, 0x3a, 0x76, 0x07, 0xa6, 0xf6, 0x52, 0x33, 0xfa, 0x5a, 0xb7, 0x8f,
+	0xe5, 0x7f, 0xa4, 0xe3, 0x5f, 0x0b, 0x00, 0xa2, 0xf1, 0x0e, 0xbb, 0xf7, 0x0d, 0xa8, 0x5e, 0x63,
+	0xdb, 0xd6, 0x2e, 0xb0, 0xbc, 0x85, 0x00, 0x2a, 0xba, 0x65, 0x9e, 0x1b, 0x17, 0x72, 0x01, 0xed,
+	0x42, 0x4b, 0x7c, 0xbb, 0x83, 0x9b, 0x9e, 0xe6, 0x60, 0xb9, 0x88, 0x14, 0x78, 0x82, 0xcd, 0x9e,
+	0x45, 0x6c, 0x4c, 0x5c, 0x87, 0x68, 0xa6, 0xad, 0xe9, 0x8e, 0x61, 0x99, 0xb2, 0x84, 0xf6, 0xa1,
+	0x6d, 0x91, 0x1e, 0x26, 0x1b, 0x0b, 0x25, 0xb4, 0x07, 0xbb, 0x3d, 0xdc, 0x37, 0x98, 0x36, 0x1b,
+	0xe3, 0x2b, 0xd7, 0x30, 0xcf, 0x2d, 0xb9, 0xcc, 0xc2, 0xfa, 0xa5, 0x66, 0x98, 0xba, 0xd5, 0xc3,
+	0xee, 0x8d, 0xa6, 0x5f, 0xb1, 0xfa, 0x95, 0xe3, 0xef, 0x01, 0xad, 0xd9, 0x61, 0xb0, 0x77, 0x1d,
+	0x6d, 0x03, 0xd8, 0xc6, 0x85, 0xa9, 0x39, 0x03, 0x82, 0x6d, 0x79, 0x0b, 0xed, 0x40, 0xa3, 0xaf,
+	0xd9, 0x8e, 0x9b, 0x4b, 0xdd, 0x87, 0xf6, 0x4a, 0x55, 0xdb, 0x3d, 0x37, 0xfa, 0x0e, 0x26, 0x72,
+	0x91, 0x1d, 0x2e, 0x95, 0x25, 0x4b, 0xa8, 0x05, 0x75, 0xc7, 0xb8, 0xc6, 0xb6, 0xa3, 0x5d, 0xdf,
+	0xc8, 0x25, 0x96, 0x15, 0x7f, 0xed, 0x60, 0xd3, 0x66, 0x5b, 0xe4, 0x72, 0xd7, 0x86, 0x0f, 0xc3,
+	0x68, 0xd2, 0x99, 0x3e, 0x2c, 0x68, 0x34, 0xa3, 0xe3, 0x09, 0x8d, 0x3a, 0xb7, 0xde, 0x30, 0xf2,
+	0x47, 0xe2, 0x91, 0x8b, 0xd3, 0xa1, 0x7e, 0x7d, 0x32, 0xf1, 0x93, 0xe9, 0x72, 0xc8, 0xe0, 0xe9,
+	0x0a, 0xf9, 0x54, 0x90, 0xc5, 0x5f, 0x79, 0x9c, 0xfe, 0xdd, 0x0f, 0x2b, 0x1c, 0x7e, 0xf6, 0xef,
+	0x00, 0x1c, 0x80, 0x98, 0x27, 0x06, 0x08, 0x00, 0x00,
 }
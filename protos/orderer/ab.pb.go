@@ -6,27 +6,77 @@ Package orderer is a generated protocol buffer package.
 
 It is generated from these files:
 	orderer/ab.proto
+	orderer/admin.proto
+	orderer/batch.proto
 	orderer/configuration.proto
+	orderer/cosign.proto
+	orderer/errdetails.proto
+	orderer/jointoken.proto
 	orderer/kafka.proto
+	orderer/txstatus.proto
 
 It has these top-level messages:
 	BroadcastResponse
+	BroadcastCommit
+	Backpressure
+	BroadcastAcknowledgment
 	SeekNewest
 	SeekOldest
 	SeekSpecified
+	SeekTxID
+	SeekBlockHash
+	SeekResume
 	SeekPosition
 	SeekInfo
+	CompressedBlocks
+	ResumeToken
+	Heartbeat
+	ResumePoint
+	Blocks
+	FilteredTransaction
+	FilteredBlock
 	DeliverResponse
+	OrdererHeaderExtension
+	ChannelList
+	ChannelStatusRequest
+	ChannelStatus
+	JoinChannelRequest
+	MaintenanceMode
+	LogSpec
+	ModuleLevel
+	StageOrganizationRequest
+	ChannelMembershipRequest
+	ProposalRequest
+	ProposalSignatures
+	ConfigUpdateProposal
+	ConfigUpdateProposals
+	BroadcastBatchRequest
+	BroadcastBatchResponse
 	ConsensusType
 	BatchSize
 	BatchTimeout
 	KafkaBrokers
 	ChannelRestrictions
+	BroadcastQueue
+	BroadcastQuota
+	ReplayWindow
+	CosignRequest
+	ErrorInfo
+	RetryInfo
+	RedirectInfo
+	JoinTokenRequest
+	JoinTokenContent
+	JoinToken
 	KafkaMessage
 	KafkaMessageRegular
 	KafkaMessageTimeToCut
 	KafkaMessageConnect
 	KafkaMetadata
+	KafkaWireVersion
+	TransactionStatusRequest
+	TransactionStatusResponse
+	TransactionGroupRequest
+	TransactionGroupResponse
 */
 package orderer
 
@@ -34,6 +84,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import common "github.com/hyperledger/fabric/protos/common"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -51,6 +102,61 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// Compression is an algorithm by which the blocks of a deliver stream may be
+// compressed
+type Compression int32
+
+const (
+	Compression_NONE   Compression = 0
+	Compression_GZIP   Compression = 1
+	Compression_SNAPPY Compression = 2
+)
+
+var Compression_name = map[int32]string{
+	0: "NONE",
+	1: "GZIP",
+	2: "SNAPPY",
+}
+var Compression_value = map[string]int32{
+	"NONE":   0,
+	"GZIP":   1,
+	"SNAPPY": 2,
+}
+
+func (x Compression) String() string {
+	return proto.EnumName(Compression_name, int32(x))
+}
+func (Compression) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+// PriorityClass is the ordering treatment requested for a message. A message
+// is admitted at its requested class only if its creator is entitled to it.
+type PriorityClass int32
+
+const (
+	PriorityClass_NORMAL  PriorityClass = 0
+	PriorityClass_LOW     PriorityClass = 1
+	PriorityClass_HIGH    PriorityClass = 2
+	PriorityClass_CONTROL PriorityClass = 3
+)
+
+var PriorityClass_name = map[int32]string{
+	0: "NORMAL",
+	1: "LOW",
+	2: "HIGH",
+	3: "CONTROL",
+}
+var PriorityClass_value = map[string]int32{
+	"NORMAL":  0,
+	"LOW":     1,
+	"HIGH":    2,
+	"CONTROL": 3,
+}
+
+func (x PriorityClass) String() string {
+	return proto.EnumName(PriorityClass_name, int32(x))
+}
+func (PriorityClass) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
 type SeekInfo_SeekBehavior int32
 
 const (
@@ -70,10 +176,34 @@ var SeekInfo_SeekBehavior_value = map[string]int32{
 func (x SeekInfo_SeekBehavior) String() string {
 	return proto.EnumName(SeekInfo_SeekBehavior_name, int32(x))
 }
-func (SeekInfo_SeekBehavior) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5, 0} }
+func (SeekInfo_SeekBehavior) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{11, 0} }
 
 type BroadcastResponse struct {
 	Status common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	// acknowledgment is a marshaled BroadcastAcknowledgment, set only when the
+	// orderer is configured to sign its responses
+	Acknowledgment []byte `protobuf:"bytes,2,opt,name=acknowledgment,proto3" json:"acknowledgment,omitempty"`
+	// signature_header is a marshaled common.SignatureHeader identifying the orderer
+	SignatureHeader []byte `protobuf:"bytes,3,opt,name=signature_header,json=signatureHeader,proto3" json:"signature_header,omitempty"`
+	// signature is over the concatenation of acknowledgment and signature_header
+	Signature []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	// endpoints are the addresses of the orderers to broadcast to instead, set
+	// when status is TEMPORARY_REDIRECT
+	Endpoints []string `protobuf:"bytes,5,rep,name=endpoints" json:"endpoints,omitempty"`
+	// retry_delay_ms is the delay after which the message may be sent again,
+	// set when status is SERVICE_UNAVAILABLE because the client exceeded its
+	// rate limit or the channel is overloaded
+	RetryDelayMs int64 `protobuf:"varint,6,opt,name=retry_delay_ms,json=retryDelayMs" json:"retry_delay_ms,omitempty"`
+	// backpressure is the load of the channel, set when status is
+	// SERVICE_UNAVAILABLE because the channel is overloaded
+	Backpressure *Backpressure `protobuf:"bytes,7,opt,name=backpressure" json:"backpressure,omitempty"`
+	// info is a human readable description of why the message was not
+	// accepted, such as the filter which rejected it and why
+	Info string `protobuf:"bytes,8,opt,name=info" json:"info,omitempty"`
+	// commit locates the message on the chain, set when the client awaited
+	// its commit. It is not covered by the signature, as the block holding
+	// the message attests to it.
+	Commit *BroadcastCommit `protobuf:"bytes,9,opt,name=commit" json:"commit,omitempty"`
 }
 
 func (m *BroadcastResponse) Reset()                    { *m = BroadcastResponse{} }
@@ -88,13 +218,188 @@ func (m *BroadcastResponse) GetStatus() common.Status {
 	return common.Status_UNKNOWN
 }
 
+func (m *BroadcastResponse) GetAcknowledgment() []byte {
+	if m != nil {
+		return m.Acknowledgment
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetSignatureHeader() []byte {
+	if m != nil {
+		return m.SignatureHeader
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetEndpoints() []string {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetRetryDelayMs() int64 {
+	if m != nil {
+		return m.RetryDelayMs
+	}
+	return 0
+}
+
+func (m *BroadcastResponse) GetBackpressure() *Backpressure {
+	if m != nil {
+		return m.Backpressure
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetInfo() string {
+	if m != nil {
+		return m.Info
+	}
+	return ""
+}
+
+func (m *BroadcastResponse) GetCommit() *BroadcastCommit {
+	if m != nil {
+		return m.Commit
+	}
+	return nil
+}
+
+// BroadcastCommit is the position of a message in the block it was
+// committed in
+type BroadcastCommit struct {
+	BlockNumber uint64 `protobuf:"varint,1,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	TxIndex     uint32 `protobuf:"varint,2,opt,name=tx_index,json=txIndex" json:"tx_index,omitempty"`
+}
+
+func (m *BroadcastCommit) Reset()                    { *m = BroadcastCommit{} }
+func (m *BroadcastCommit) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastCommit) ProtoMessage()               {}
+func (*BroadcastCommit) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *BroadcastCommit) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *BroadcastCommit) GetTxIndex() uint32 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+// Backpressure describes the load of a channel which refuses messages
+type Backpressure struct {
+	// queue_depth is the number of messages waiting to be enqueued on the
+	// consenter of the channel
+	QueueDepth uint32 `protobuf:"varint,1,opt,name=queue_depth,json=queueDepth" json:"queue_depth,omitempty"`
+	// max_queue_depth is the queue depth beyond which messages are refused,
+	// 0 if there is no limit
+	MaxQueueDepth uint32 `protobuf:"varint,2,opt,name=max_queue_depth,json=maxQueueDepth" json:"max_queue_depth,omitempty"`
+	// append_latency_ms is the moving average of the time taken to append a
+	// block to the ledger of the channel
+	AppendLatencyMs int64 `protobuf:"varint,3,opt,name=append_latency_ms,json=appendLatencyMs" json:"append_latency_ms,omitempty"`
+	// max_append_latency_ms is the append latency beyond which messages are
+	// refused, 0 if there is no limit
+	MaxAppendLatencyMs int64 `protobuf:"varint,4,opt,name=max_append_latency_ms,json=maxAppendLatencyMs" json:"max_append_latency_ms,omitempty"`
+}
+
+func (m *Backpressure) Reset()                    { *m = Backpressure{} }
+func (m *Backpressure) String() string            { return proto.CompactTextString(m) }
+func (*Backpressure) ProtoMessage()               {}
+func (*Backpressure) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Backpressure) GetQueueDepth() uint32 {
+	if m != nil {
+		return m.QueueDepth
+	}
+	return 0
+}
+
+func (m *Backpressure) GetMaxQueueDepth() uint32 {
+	if m != nil {
+		return m.MaxQueueDepth
+	}
+	return 0
+}
+
+func (m *Backpressure) GetAppendLatencyMs() int64 {
+	if m != nil {
+		return m.AppendLatencyMs
+	}
+	return 0
+}
+
+func (m *Backpressure) GetMaxAppendLatencyMs() int64 {
+	if m != nil {
+		return m.MaxAppendLatencyMs
+	}
+	return 0
+}
+
+// BroadcastAcknowledgment is what the orderer attests to when it signs a
+// BroadcastResponse, so that the client holds a receipt of the fate of its message
+type BroadcastAcknowledgment struct {
+	Status common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	// channel_id is empty if the message was rejected before its channel was known
+	ChannelId string `protobuf:"bytes,2,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	// tx_hash is the SHA256 hash of the envelope as it was received
+	TxHash    []byte                     `protobuf:"bytes,3,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+func (m *BroadcastAcknowledgment) Reset()                    { *m = BroadcastAcknowledgment{} }
+func (m *BroadcastAcknowledgment) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastAcknowledgment) ProtoMessage()               {}
+func (*BroadcastAcknowledgment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *BroadcastAcknowledgment) GetStatus() common.Status {
+	if m != nil {
+		return m.Status
+	}
+	return common.Status_UNKNOWN
+}
+
+func (m *BroadcastAcknowledgment) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *BroadcastAcknowledgment) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *BroadcastAcknowledgment) GetTimestamp() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
 type SeekNewest struct {
 }
 
 func (m *SeekNewest) Reset()                    { *m = SeekNewest{} }
 func (m *SeekNewest) String() string            { return proto.CompactTextString(m) }
 func (*SeekNewest) ProtoMessage()               {}
-func (*SeekNewest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*SeekNewest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 type SeekOldest struct {
 }
@@ -102,7 +407,7 @@ type SeekOldest struct {
 func (m *SeekOldest) Reset()                    { *m = SeekOldest{} }
 func (m *SeekOldest) String() string            { return proto.CompactTextString(m) }
 func (*SeekOldest) ProtoMessage()               {}
-func (*SeekOldest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*SeekOldest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 type SeekSpecified struct {
 	Number uint64 `protobuf:"varint,1,opt,name=number" json:"number,omitempty"`
@@ -111,7 +416,7 @@ type SeekSpecified struct {
 func (m *SeekSpecified) Reset()                    { *m = SeekSpecified{} }
 func (m *SeekSpecified) String() string            { return proto.CompactTextString(m) }
 func (*SeekSpecified) ProtoMessage()               {}
-func (*SeekSpecified) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*SeekSpecified) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *SeekSpecified) GetNumber() uint64 {
 	if m != nil {
@@ -120,18 +425,74 @@ func (m *SeekSpecified) GetNumber() uint64 {
 	return 0
 }
 
+// SeekTxID is the position of the block containing the transaction with the
+// ID, the most recent such block if several do
+type SeekTxID struct {
+	TxId string `protobuf:"bytes,1,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+}
+
+func (m *SeekTxID) Reset()                    { *m = SeekTxID{} }
+func (m *SeekTxID) String() string            { return proto.CompactTextString(m) }
+func (*SeekTxID) ProtoMessage()               {}
+func (*SeekTxID) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *SeekTxID) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+// SeekBlockHash is the position of the block whose header has the hash
+type SeekBlockHash struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (m *SeekBlockHash) Reset()                    { *m = SeekBlockHash{} }
+func (m *SeekBlockHash) String() string            { return proto.CompactTextString(m) }
+func (*SeekBlockHash) ProtoMessage()               {}
+func (*SeekBlockHash) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *SeekBlockHash) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+// SeekResume is the position at which a deliver stream was left, as recorded
+// by the last resume token sent on it. It may only be the start of a seek.
+type SeekResume struct {
+	Token []byte `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (m *SeekResume) Reset()                    { *m = SeekResume{} }
+func (m *SeekResume) String() string            { return proto.CompactTextString(m) }
+func (*SeekResume) ProtoMessage()               {}
+func (*SeekResume) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *SeekResume) GetToken() []byte {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
 type SeekPosition struct {
 	// Types that are valid to be assigned to Type:
 	//	*SeekPosition_Newest
 	//	*SeekPosition_Oldest
 	//	*SeekPosition_Specified
+	//	*SeekPosition_TxId
+	//	*SeekPosition_Hash
+	//	*SeekPosition_Resume
 	Type isSeekPosition_Type `protobuf_oneof:"Type"`
 }
 
 func (m *SeekPosition) Reset()                    { *m = SeekPosition{} }
 func (m *SeekPosition) String() string            { return proto.CompactTextString(m) }
 func (*SeekPosition) ProtoMessage()               {}
-func (*SeekPosition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*SeekPosition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 type isSeekPosition_Type interface {
 	isSeekPosition_Type()
@@ -146,10 +507,22 @@ type SeekPosition_Oldest struct {
 type SeekPosition_Specified struct {
 	Specified *SeekSpecified `protobuf:"bytes,3,opt,name=specified,oneof"`
 }
+type SeekPosition_TxId struct {
+	TxId *SeekTxID `protobuf:"bytes,4,opt,name=tx_id,json=txId,oneof"`
+}
+type SeekPosition_Hash struct {
+	Hash *SeekBlockHash `protobuf:"bytes,5,opt,name=hash,oneof"`
+}
+type SeekPosition_Resume struct {
+	Resume *SeekResume `protobuf:"bytes,6,opt,name=resume,oneof"`
+}
 
 func (*SeekPosition_Newest) isSeekPosition_Type()    {}
 func (*SeekPosition_Oldest) isSeekPosition_Type()    {}
 func (*SeekPosition_Specified) isSeekPosition_Type() {}
+func (*SeekPosition_TxId) isSeekPosition_Type()      {}
+func (*SeekPosition_Hash) isSeekPosition_Type()      {}
+func (*SeekPosition_Resume) isSeekPosition_Type()    {}
 
 func (m *SeekPosition) GetType() isSeekPosition_Type {
 	if m != nil {
@@ -179,12 +552,36 @@ func (m *SeekPosition) GetSpecified() *SeekSpecified {
 	return nil
 }
 
+func (m *SeekPosition) GetTxId() *SeekTxID {
+	if x, ok := m.GetType().(*SeekPosition_TxId); ok {
+		return x.TxId
+	}
+	return nil
+}
+
+func (m *SeekPosition) GetHash() *SeekBlockHash {
+	if x, ok := m.GetType().(*SeekPosition_Hash); ok {
+		return x.Hash
+	}
+	return nil
+}
+
+func (m *SeekPosition) GetResume() *SeekResume {
+	if x, ok := m.GetType().(*SeekPosition_Resume); ok {
+		return x.Resume
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*SeekPosition) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _SeekPosition_OneofMarshaler, _SeekPosition_OneofUnmarshaler, _SeekPosition_OneofSizer, []interface{}{
 		(*SeekPosition_Newest)(nil),
 		(*SeekPosition_Oldest)(nil),
 		(*SeekPosition_Specified)(nil),
+		(*SeekPosition_TxId)(nil),
+		(*SeekPosition_Hash)(nil),
+		(*SeekPosition_Resume)(nil),
 	}
 }
 
@@ -207,6 +604,21 @@ func _SeekPosition_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Specified); err != nil {
 			return err
 		}
+	case *SeekPosition_TxId:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxId); err != nil {
+			return err
+		}
+	case *SeekPosition_Hash:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Hash); err != nil {
+			return err
+		}
+	case *SeekPosition_Resume:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Resume); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("SeekPosition.Type has unexpected type %T", x)
@@ -241,6 +653,30 @@ func _SeekPosition_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.B
 		err := b.DecodeMessage(msg)
 		m.Type = &SeekPosition_Specified{msg}
 		return true, err
+	case 4: // Type.tx_id
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SeekTxID)
+		err := b.DecodeMessage(msg)
+		m.Type = &SeekPosition_TxId{msg}
+		return true, err
+	case 5: // Type.hash
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SeekBlockHash)
+		err := b.DecodeMessage(msg)
+		m.Type = &SeekPosition_Hash{msg}
+		return true, err
+	case 6: // Type.resume
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SeekResume)
+		err := b.DecodeMessage(msg)
+		m.Type = &SeekPosition_Resume{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -265,6 +701,21 @@ func _SeekPosition_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(3<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *SeekPosition_TxId:
+		s := proto.Size(x.TxId)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *SeekPosition_Hash:
+		s := proto.Size(x.Hash)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *SeekPosition_Resume:
+		s := proto.Size(x.Resume)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -280,16 +731,25 @@ func _SeekPosition_OneofSizer(msg proto.Message) (n int) {
 // error indicating that the block is not found.  To request that all blocks be returned indefinitely
 // as they are created, behavior should be set to BLOCK_UNTIL_READY and the stop should be set to
 // specified with a number of MAX_UINT64
+// A client which sets max_batch_size above 1 accepts responses carrying up to that many blocks,
+// which the orderer sends when several blocks are already available, such as when catching up
+// A client which lists compression algorithms is sent its blocks compressed with the first of
+// them which the orderer offers, or uncompressed if it offers none of them
 type SeekInfo struct {
-	Start    *SeekPosition         `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
-	Stop     *SeekPosition         `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
-	Behavior SeekInfo_SeekBehavior `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	Start          *SeekPosition         `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
+	Stop           *SeekPosition         `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
+	Behavior       SeekInfo_SeekBehavior `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	MaxBatchSize   uint32                `protobuf:"varint,4,opt,name=max_batch_size,json=maxBatchSize" json:"max_batch_size,omitempty"`
+	Filtered       bool                  `protobuf:"varint,5,opt,name=filtered" json:"filtered,omitempty"`
+	ResumeInterval uint32                `protobuf:"varint,6,opt,name=resume_interval,json=resumeInterval" json:"resume_interval,omitempty"`
+	Heartbeats     bool                  `protobuf:"varint,7,opt,name=heartbeats" json:"heartbeats,omitempty"`
+	Compression    []Compression         `protobuf:"varint,8,rep,packed,name=compression,enum=orderer.Compression" json:"compression,omitempty"`
 }
 
 func (m *SeekInfo) Reset()                    { *m = SeekInfo{} }
 func (m *SeekInfo) String() string            { return proto.CompactTextString(m) }
 func (*SeekInfo) ProtoMessage()               {}
-func (*SeekInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*SeekInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *SeekInfo) GetStart() *SeekPosition {
 	if m != nil {
@@ -312,17 +772,244 @@ func (m *SeekInfo) GetBehavior() SeekInfo_SeekBehavior {
 	return SeekInfo_BLOCK_UNTIL_READY
 }
 
+func (m *SeekInfo) GetMaxBatchSize() uint32 {
+	if m != nil {
+		return m.MaxBatchSize
+	}
+	return 0
+}
+
+func (m *SeekInfo) GetFiltered() bool {
+	if m != nil {
+		return m.Filtered
+	}
+	return false
+}
+
+func (m *SeekInfo) GetResumeInterval() uint32 {
+	if m != nil {
+		return m.ResumeInterval
+	}
+	return 0
+}
+
+func (m *SeekInfo) GetHeartbeats() bool {
+	if m != nil {
+		return m.Heartbeats
+	}
+	return false
+}
+
+func (m *SeekInfo) GetCompression() []Compression {
+	if m != nil {
+		return m.Compression
+	}
+	return nil
+}
+
+// CompressedBlocks carries the Blocks message of a response, marshaled and
+// compressed with the algorithm the orderer chose for the stream
+type CompressedBlocks struct {
+	Compression Compression `protobuf:"varint,1,opt,name=compression,enum=orderer.Compression" json:"compression,omitempty"`
+	Data        []byte      `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *CompressedBlocks) Reset()                    { *m = CompressedBlocks{} }
+func (m *CompressedBlocks) String() string            { return proto.CompactTextString(m) }
+func (*CompressedBlocks) ProtoMessage()               {}
+func (*CompressedBlocks) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *CompressedBlocks) GetCompression() Compression {
+	if m != nil {
+		return m.Compression
+	}
+	return Compression_NONE
+}
+
+func (m *CompressedBlocks) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// ResumeToken is sent on a deliver stream to record the position reached, so
+// that a client reconnecting may resume the stream with a SeekResume. The
+// token is opaque to clients.
+type ResumeToken struct {
+	Token []byte `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (m *ResumeToken) Reset()                    { *m = ResumeToken{} }
+func (m *ResumeToken) String() string            { return proto.CompactTextString(m) }
+func (*ResumeToken) ProtoMessage()               {}
+func (*ResumeToken) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ResumeToken) GetToken() []byte {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
+// Heartbeat is sent on a deliver stream which asked for heartbeats whenever
+// it has waited for new blocks for the heartbeat interval of the orderer, so
+// that clients and intermediaries can tell an idle stream from a dead one
+type Heartbeat struct {
+	Height uint64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+}
+
+func (m *Heartbeat) Reset()                    { *m = Heartbeat{} }
+func (m *Heartbeat) String() string            { return proto.CompactTextString(m) }
+func (*Heartbeat) ProtoMessage()               {}
+func (*Heartbeat) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *Heartbeat) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+// ResumePoint is the content of a resume token: the number of the next block
+// to deliver, and the hash of the header of the block delivered before it, by
+// which the orderer checks its ledger still holds the blocks delivered
+type ResumePoint struct {
+	ChannelId    string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Number       uint64 `protobuf:"varint,2,opt,name=number" json:"number,omitempty"`
+	PreviousHash []byte `protobuf:"bytes,3,opt,name=previous_hash,json=previousHash,proto3" json:"previous_hash,omitempty"`
+}
+
+func (m *ResumePoint) Reset()                    { *m = ResumePoint{} }
+func (m *ResumePoint) String() string            { return proto.CompactTextString(m) }
+func (*ResumePoint) ProtoMessage()               {}
+func (*ResumePoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ResumePoint) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *ResumePoint) GetNumber() uint64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *ResumePoint) GetPreviousHash() []byte {
+	if m != nil {
+		return m.PreviousHash
+	}
+	return nil
+}
+
+// Blocks are consecutive blocks delivered in a single response
+type Blocks struct {
+	Blocks []*common.Block `protobuf:"bytes,1,rep,name=blocks" json:"blocks,omitempty"`
+}
+
+func (m *Blocks) Reset()                    { *m = Blocks{} }
+func (m *Blocks) String() string            { return proto.CompactTextString(m) }
+func (*Blocks) ProtoMessage()               {}
+func (*Blocks) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *Blocks) GetBlocks() []*common.Block {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+// FilteredTransaction identifies a transaction of a filtered block
+type FilteredTransaction struct {
+	TxId string            `protobuf:"bytes,1,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+	Type common.HeaderType `protobuf:"varint,2,opt,name=type,enum=common.HeaderType" json:"type,omitempty"`
+	// validation_code is the code recorded for the transaction in the
+	// TRANSACTIONS_FILTER metadata of the block. Orderers do not validate
+	// transactions, so it is 0 (VALID) unless the block was validated by a
+	// peer before being written to the ledger of the orderer.
+	ValidationCode uint32 `protobuf:"varint,3,opt,name=validation_code,json=validationCode" json:"validation_code,omitempty"`
+}
+
+func (m *FilteredTransaction) Reset()                    { *m = FilteredTransaction{} }
+func (m *FilteredTransaction) String() string            { return proto.CompactTextString(m) }
+func (*FilteredTransaction) ProtoMessage()               {}
+func (*FilteredTransaction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *FilteredTransaction) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+func (m *FilteredTransaction) GetType() common.HeaderType {
+	if m != nil {
+		return m.Type
+	}
+	return common.HeaderType_MESSAGE
+}
+
+func (m *FilteredTransaction) GetValidationCode() uint32 {
+	if m != nil {
+		return m.ValidationCode
+	}
+	return 0
+}
+
+// FilteredBlock is a lightweight block which carries the IDs and validation
+// codes of its transactions rather than their payloads
+type FilteredBlock struct {
+	ChannelId            string                 `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Number               uint64                 `protobuf:"varint,2,opt,name=number" json:"number,omitempty"`
+	FilteredTransactions []*FilteredTransaction `protobuf:"bytes,3,rep,name=filtered_transactions,json=filteredTransactions" json:"filtered_transactions,omitempty"`
+}
+
+func (m *FilteredBlock) Reset()                    { *m = FilteredBlock{} }
+func (m *FilteredBlock) String() string            { return proto.CompactTextString(m) }
+func (*FilteredBlock) ProtoMessage()               {}
+func (*FilteredBlock) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *FilteredBlock) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *FilteredBlock) GetNumber() uint64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *FilteredBlock) GetFilteredTransactions() []*FilteredTransaction {
+	if m != nil {
+		return m.FilteredTransactions
+	}
+	return nil
+}
+
 type DeliverResponse struct {
 	// Types that are valid to be assigned to Type:
 	//	*DeliverResponse_Status
 	//	*DeliverResponse_Block
+	//	*DeliverResponse_Blocks
+	//	*DeliverResponse_FilteredBlock
+	//	*DeliverResponse_ResumeToken
+	//	*DeliverResponse_Heartbeat
+	//	*DeliverResponse_CompressedBlocks
 	Type isDeliverResponse_Type `protobuf_oneof:"Type"`
 }
 
 func (m *DeliverResponse) Reset()                    { *m = DeliverResponse{} }
 func (m *DeliverResponse) String() string            { return proto.CompactTextString(m) }
 func (*DeliverResponse) ProtoMessage()               {}
-func (*DeliverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*DeliverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 type isDeliverResponse_Type interface {
 	isDeliverResponse_Type()
@@ -334,9 +1021,29 @@ type DeliverResponse_Status struct {
 type DeliverResponse_Block struct {
 	Block *common.Block `protobuf:"bytes,2,opt,name=block,oneof"`
 }
+type DeliverResponse_Blocks struct {
+	Blocks *Blocks `protobuf:"bytes,3,opt,name=blocks,oneof"`
+}
+type DeliverResponse_FilteredBlock struct {
+	FilteredBlock *FilteredBlock `protobuf:"bytes,4,opt,name=filtered_block,json=filteredBlock,oneof"`
+}
+type DeliverResponse_ResumeToken struct {
+	ResumeToken *ResumeToken `protobuf:"bytes,5,opt,name=resume_token,json=resumeToken,oneof"`
+}
+type DeliverResponse_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,6,opt,name=heartbeat,oneof"`
+}
+type DeliverResponse_CompressedBlocks struct {
+	CompressedBlocks *CompressedBlocks `protobuf:"bytes,7,opt,name=compressed_blocks,json=compressedBlocks,oneof"`
+}
 
-func (*DeliverResponse_Status) isDeliverResponse_Type() {}
-func (*DeliverResponse_Block) isDeliverResponse_Type()  {}
+func (*DeliverResponse_Status) isDeliverResponse_Type()           {}
+func (*DeliverResponse_Block) isDeliverResponse_Type()            {}
+func (*DeliverResponse_Blocks) isDeliverResponse_Type()           {}
+func (*DeliverResponse_FilteredBlock) isDeliverResponse_Type()    {}
+func (*DeliverResponse_ResumeToken) isDeliverResponse_Type()      {}
+func (*DeliverResponse_Heartbeat) isDeliverResponse_Type()        {}
+func (*DeliverResponse_CompressedBlocks) isDeliverResponse_Type() {}
 
 func (m *DeliverResponse) GetType() isDeliverResponse_Type {
 	if m != nil {
@@ -359,11 +1066,51 @@ func (m *DeliverResponse) GetBlock() *common.Block {
 	return nil
 }
 
+func (m *DeliverResponse) GetBlocks() *Blocks {
+	if x, ok := m.GetType().(*DeliverResponse_Blocks); ok {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (m *DeliverResponse) GetFilteredBlock() *FilteredBlock {
+	if x, ok := m.GetType().(*DeliverResponse_FilteredBlock); ok {
+		return x.FilteredBlock
+	}
+	return nil
+}
+
+func (m *DeliverResponse) GetResumeToken() *ResumeToken {
+	if x, ok := m.GetType().(*DeliverResponse_ResumeToken); ok {
+		return x.ResumeToken
+	}
+	return nil
+}
+
+func (m *DeliverResponse) GetHeartbeat() *Heartbeat {
+	if x, ok := m.GetType().(*DeliverResponse_Heartbeat); ok {
+		return x.Heartbeat
+	}
+	return nil
+}
+
+func (m *DeliverResponse) GetCompressedBlocks() *CompressedBlocks {
+	if x, ok := m.GetType().(*DeliverResponse_CompressedBlocks); ok {
+		return x.CompressedBlocks
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*DeliverResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _DeliverResponse_OneofMarshaler, _DeliverResponse_OneofUnmarshaler, _DeliverResponse_OneofSizer, []interface{}{
 		(*DeliverResponse_Status)(nil),
 		(*DeliverResponse_Block)(nil),
+		(*DeliverResponse_Blocks)(nil),
+		(*DeliverResponse_FilteredBlock)(nil),
+		(*DeliverResponse_ResumeToken)(nil),
+		(*DeliverResponse_Heartbeat)(nil),
+		(*DeliverResponse_CompressedBlocks)(nil),
 	}
 }
 
@@ -379,6 +1126,31 @@ func _DeliverResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Block); err != nil {
 			return err
 		}
+	case *DeliverResponse_Blocks:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Blocks); err != nil {
+			return err
+		}
+	case *DeliverResponse_FilteredBlock:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.FilteredBlock); err != nil {
+			return err
+		}
+	case *DeliverResponse_ResumeToken:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ResumeToken); err != nil {
+			return err
+		}
+	case *DeliverResponse_Heartbeat:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Heartbeat); err != nil {
+			return err
+		}
+	case *DeliverResponse_CompressedBlocks:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.CompressedBlocks); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("DeliverResponse.Type has unexpected type %T", x)
@@ -404,6 +1176,46 @@ func _DeliverResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *prot
 		err := b.DecodeMessage(msg)
 		m.Type = &DeliverResponse_Block{msg}
 		return true, err
+	case 3: // Type.blocks
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Blocks)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_Blocks{msg}
+		return true, err
+	case 4: // Type.filtered_block
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(FilteredBlock)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_FilteredBlock{msg}
+		return true, err
+	case 5: // Type.resume_token
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ResumeToken)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_ResumeToken{msg}
+		return true, err
+	case 6: // Type.heartbeat
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Heartbeat)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_Heartbeat{msg}
+		return true, err
+	case 7: // Type.compressed_blocks
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(CompressedBlocks)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_CompressedBlocks{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -421,6 +1233,31 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(2<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *DeliverResponse_Blocks:
+		s := proto.Size(x.Blocks)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *DeliverResponse_FilteredBlock:
+		s := proto.Size(x.FilteredBlock)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *DeliverResponse_ResumeToken:
+		s := proto.Size(x.ResumeToken)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *DeliverResponse_Heartbeat:
+		s := proto.Size(x.Heartbeat)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *DeliverResponse_CompressedBlocks:
+		s := proto.Size(x.CompressedBlocks)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -428,14 +1265,80 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// OrdererHeaderExtension is carried marshaled in the orderer_extension of a
+// common.ChannelHeader to request the treatment of the message by the orderer
+type OrdererHeaderExtension struct {
+	Priority PriorityClass `protobuf:"varint,1,opt,name=priority,enum=orderer.PriorityClass" json:"priority,omitempty"`
+	// group_id tags the transactions submitted to several channels as one
+	// group, whose inclusion in each channel may be queried by the group ID
+	GroupId string `protobuf:"bytes,2,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	// extensions are opaque to the orderer, such as tracing IDs or
+	// compliance tags, and are recorded in the EXTENSIONS metadata of the
+	// block the message is ordered in
+	Extensions []byte `protobuf:"bytes,3,opt,name=extensions,proto3" json:"extensions,omitempty"`
+	// await_commit asks the orderer to answer the broadcast of the message
+	// only once the block holding it is appended to the ledger, rather than
+	// once it is enqueued for ordering
+	AwaitCommit bool `protobuf:"varint,4,opt,name=await_commit,json=awaitCommit" json:"await_commit,omitempty"`
+}
+
+func (m *OrdererHeaderExtension) Reset()                    { *m = OrdererHeaderExtension{} }
+func (m *OrdererHeaderExtension) String() string            { return proto.CompactTextString(m) }
+func (*OrdererHeaderExtension) ProtoMessage()               {}
+func (*OrdererHeaderExtension) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *OrdererHeaderExtension) GetPriority() PriorityClass {
+	if m != nil {
+		return m.Priority
+	}
+	return PriorityClass_NORMAL
+}
+
+func (m *OrdererHeaderExtension) GetGroupId() string {
+	if m != nil {
+		return m.GroupId
+	}
+	return ""
+}
+
+func (m *OrdererHeaderExtension) GetExtensions() []byte {
+	if m != nil {
+		return m.Extensions
+	}
+	return nil
+}
+
+func (m *OrdererHeaderExtension) GetAwaitCommit() bool {
+	if m != nil {
+		return m.AwaitCommit
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*BroadcastResponse)(nil), "orderer.BroadcastResponse")
+	proto.RegisterType((*BroadcastCommit)(nil), "orderer.BroadcastCommit")
+	proto.RegisterType((*Backpressure)(nil), "orderer.Backpressure")
+	proto.RegisterType((*BroadcastAcknowledgment)(nil), "orderer.BroadcastAcknowledgment")
 	proto.RegisterType((*SeekNewest)(nil), "orderer.SeekNewest")
 	proto.RegisterType((*SeekOldest)(nil), "orderer.SeekOldest")
 	proto.RegisterType((*SeekSpecified)(nil), "orderer.SeekSpecified")
+	proto.RegisterType((*SeekTxID)(nil), "orderer.SeekTxID")
+	proto.RegisterType((*SeekBlockHash)(nil), "orderer.SeekBlockHash")
+	proto.RegisterType((*SeekResume)(nil), "orderer.SeekResume")
 	proto.RegisterType((*SeekPosition)(nil), "orderer.SeekPosition")
 	proto.RegisterType((*SeekInfo)(nil), "orderer.SeekInfo")
+	proto.RegisterType((*CompressedBlocks)(nil), "orderer.CompressedBlocks")
+	proto.RegisterType((*ResumeToken)(nil), "orderer.ResumeToken")
+	proto.RegisterType((*Heartbeat)(nil), "orderer.Heartbeat")
+	proto.RegisterType((*ResumePoint)(nil), "orderer.ResumePoint")
+	proto.RegisterType((*Blocks)(nil), "orderer.Blocks")
+	proto.RegisterType((*FilteredTransaction)(nil), "orderer.FilteredTransaction")
+	proto.RegisterType((*FilteredBlock)(nil), "orderer.FilteredBlock")
 	proto.RegisterType((*DeliverResponse)(nil), "orderer.DeliverResponse")
+	proto.RegisterType((*OrdererHeaderExtension)(nil), "orderer.OrdererHeaderExtension")
+	proto.RegisterEnum("orderer.Compression", Compression_name, Compression_value)
+	proto.RegisterEnum("orderer.PriorityClass", PriorityClass_name, PriorityClass_value)
 	proto.RegisterEnum("orderer.SeekInfo_SeekBehavior", SeekInfo_SeekBehavior_name, SeekInfo_SeekBehavior_value)
 }
 
@@ -615,36 +1518,100 @@ var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("orderer/ab.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 492 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x93, 0xdb, 0x6e, 0xd3, 0x4c,
-	0x10, 0xc7, 0xe3, 0xef, 0x4b, 0xd3, 0x76, 0x68, 0xd3, 0x74, 0xab, 0x56, 0x51, 0x2e, 0x10, 0xb2,
-	0x54, 0x08, 0x02, 0x6c, 0x14, 0x24, 0x2e, 0x28, 0x12, 0x8a, 0x69, 0xab, 0x44, 0x44, 0x09, 0x72,
-	0xd2, 0x0b, 0xb8, 0x89, 0x7c, 0x98, 0x24, 0xa6, 0x8e, 0xd7, 0xda, 0xdd, 0x04, 0xf5, 0x29, 0x78,
-	0x11, 0x1e, 0x89, 0x87, 0x41, 0x7b, 0xb0, 0x43, 0xa0, 0x70, 0x65, 0xcf, 0xcc, 0xef, 0x3f, 0x27,
-	0xcd, 0x42, 0x83, 0xb2, 0x18, 0x19, 0x32, 0x37, 0x08, 0x9d, 0x9c, 0x51, 0x41, 0xc9, 0xae, 0xf1,
-	0xb4, 0x4e, 0x22, 0xba, 0x5c, 0xd2, 0xcc, 0xd5, 0x1f, 0x1d, 0xb5, 0x2f, 0xe0, 0xd8, 0x63, 0x34,
-	0x88, 0xa3, 0x80, 0x0b, 0x1f, 0x79, 0x4e, 0x33, 0x8e, 0xe4, 0x31, 0xd4, 0xb8, 0x08, 0xc4, 0x8a,
-	0x37, 0xad, 0x47, 0x56, 0xbb, 0xde, 0xa9, 0x3b, 0x46, 0x33, 0x56, 0x5e, 0xdf, 0x44, 0xed, 0x03,
-	0x80, 0x31, 0xe2, 0xed, 0x10, 0xbf, 0x22, 0x17, 0x85, 0x35, 0x4a, 0x63, 0x69, 0x3d, 0x81, 0x43,
-	0x69, 0x8d, 0x73, 0x8c, 0x92, 0x59, 0x82, 0x31, 0x39, 0x83, 0x5a, 0xb6, 0x5a, 0x86, 0xc8, 0x54,
-	0xd2, 0xaa, 0x6f, 0x2c, 0xfb, 0xbb, 0x05, 0x07, 0x92, 0xfc, 0x48, 0x79, 0x22, 0x12, 0x9a, 0x91,
-	0x17, 0x50, 0xcb, 0x54, 0x46, 0x05, 0x3e, 0xe8, 0x9c, 0x38, 0x66, 0x02, 0x67, 0x53, 0xac, 0x57,
-	0xf1, 0x0d, 0x24, 0x71, 0xaa, 0x4a, 0x36, 0xff, 0xbb, 0x07, 0xd7, 0xdd, 0x48, 0x5c, 0x43, 0xe4,
-	0x35, 0xec, 0xf3, 0xa2, 0xa7, 0xe6, 0xff, 0x4a, 0x71, 0xb6, 0xa5, 0x28, 0x3b, 0xee, 0x55, 0xfc,
-	0x0d, 0xea, 0xd5, 0xa0, 0x3a, 0xb9, 0xcb, 0xd1, 0xfe, 0x61, 0xc1, 0x9e, 0xc4, 0xfa, 0xd9, 0x8c,
-	0x92, 0x67, 0xb0, 0xc3, 0x45, 0xc0, 0x8a, 0x4e, 0x4f, 0xb7, 0x12, 0x15, 0x03, 0xf9, 0x9a, 0x21,
-	0x4f, 0xa1, 0xca, 0x05, 0xcd, 0x4d, 0x9b, 0x7f, 0x61, 0x15, 0x42, 0xde, 0xc0, 0x5e, 0x88, 0x8b,
-	0x60, 0x9d, 0x50, 0xa6, 0x7a, 0xac, 0x77, 0x1e, 0x6e, 0xe1, 0xb2, 0xb8, 0xfa, 0xf1, 0x0c, 0xe5,
-	0x97, 0xbc, 0xfd, 0x56, 0xaf, 0xb3, 0x88, 0x90, 0x53, 0x38, 0xf6, 0x06, 0xa3, 0xf7, 0x1f, 0xa6,
-	0x37, 0xc3, 0x49, 0x7f, 0x30, 0xf5, 0xaf, 0xba, 0x97, 0x9f, 0x1a, 0x15, 0xe9, 0xbe, 0xee, 0xf6,
-	0x07, 0xd3, 0xfe, 0xf5, 0x74, 0x38, 0x9a, 0x18, 0xb7, 0x65, 0x7f, 0x81, 0xa3, 0x4b, 0x4c, 0x93,
-	0x35, 0xb2, 0xf2, 0x1a, 0xda, 0xff, 0xbe, 0x06, 0xb9, 0x5b, 0x1d, 0x27, 0xe7, 0xb0, 0x13, 0xa6,
-	0x34, 0xba, 0x35, 0x23, 0x1e, 0x16, 0xa0, 0x27, 0x9d, 0xbd, 0x8a, 0xaf, 0xa3, 0xc5, 0x2a, 0x3b,
-	0xdf, 0x2c, 0x38, 0xea, 0x0a, 0xba, 0x4c, 0xa2, 0xf2, 0x04, 0xc9, 0x3b, 0xd8, 0xdf, 0x18, 0x8d,
-	0x22, 0xc1, 0x55, 0xb6, 0xc6, 0x94, 0xe6, 0xd8, 0x6a, 0x95, 0x6b, 0xf8, 0xe3, 0x6a, 0xed, 0x4a,
-	0xdb, 0x7a, 0x69, 0x91, 0x0b, 0xd8, 0x35, 0x03, 0xdc, 0x23, 0x6f, 0x96, 0xf2, 0xdf, 0x86, 0xd4,
-	0x62, 0xef, 0x06, 0xce, 0x29, 0x9b, 0x3b, 0x8b, 0xbb, 0x1c, 0x59, 0x8a, 0xf1, 0x1c, 0x99, 0x33,
-	0x0b, 0x42, 0x96, 0x44, 0xfa, 0xb5, 0xf0, 0x42, 0xfe, 0xf9, 0xf9, 0x3c, 0x11, 0x8b, 0x55, 0x28,
-	0x0b, 0xb8, 0xbf, 0xd0, 0xae, 0xa6, 0x5d, 0x4d, 0xbb, 0x86, 0x0e, 0x6b, 0xca, 0x7e, 0xf5, 0x33,
-	0x00, 0x00, 0xff, 0xff, 0xee, 0xc5, 0xfc, 0x14, 0x9d, 0x03, 0x00, 0x00,
+	// 1507 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0x5b, 0x6f, 0x13, 0x47,
+	0x14, 0xf6, 0xda, 0x8e, 0x2f, 0xc7, 0xd7, 0x4c, 0x08, 0x98, 0x88, 0x42, 0xba, 0x29, 0xc1, 0x50,
+	0xb0, 0xa9, 0x2b, 0xa1, 0x52, 0x2a, 0xa1, 0xdc, 0xc0, 0x56, 0x83, 0x1d, 0x26, 0x46, 0x15, 0x3c,
+	0x74, 0x35, 0xde, 0x9d, 0xd8, 0xab, 0x78, 0x2f, 0xdd, 0x19, 0x07, 0x87, 0xfe, 0x08, 0x9e, 0xfb,
+	0xd4, 0xf7, 0xbe, 0x22, 0xf5, 0x7f, 0xf5, 0x1f, 0x54, 0x33, 0xb3, 0x17, 0x3b, 0x49, 0x69, 0xd5,
+	0xa7, 0x78, 0xbe, 0xf3, 0xcd, 0xd9, 0x39, 0xdf, 0x9e, 0xf3, 0xed, 0x04, 0xea, 0x5e, 0x60, 0xd1,
+	0x80, 0x06, 0x6d, 0x32, 0x6a, 0xf9, 0x81, 0xc7, 0x3d, 0x94, 0x0f, 0x91, 0x8d, 0x35, 0xd3, 0x73,
+	0x1c, 0xcf, 0x6d, 0xab, 0x3f, 0x2a, 0xba, 0x71, 0x67, 0xec, 0x79, 0xe3, 0x29, 0x6d, 0xcb, 0xd5,
+	0x68, 0x76, 0xd2, 0xe6, 0xb6, 0x43, 0x19, 0x27, 0x8e, 0xaf, 0x08, 0xfa, 0x5f, 0x69, 0x58, 0xdd,
+	0x0d, 0x3c, 0x62, 0x99, 0x84, 0x71, 0x4c, 0x99, 0xef, 0xb9, 0x8c, 0xa2, 0x6d, 0xc8, 0x31, 0x4e,
+	0xf8, 0x8c, 0x35, 0xb4, 0x4d, 0xad, 0x59, 0xed, 0x54, 0x5b, 0x61, 0xd6, 0x63, 0x89, 0xe2, 0x30,
+	0x8a, 0xb6, 0xa1, 0x4a, 0xcc, 0x53, 0xd7, 0x7b, 0x3f, 0xa5, 0xd6, 0xd8, 0xa1, 0x2e, 0x6f, 0xa4,
+	0x37, 0xb5, 0x66, 0x19, 0x5f, 0x40, 0xd1, 0x7d, 0xa8, 0x33, 0x7b, 0xec, 0x12, 0x3e, 0x0b, 0xa8,
+	0x31, 0xa1, 0xc4, 0xa2, 0x41, 0x23, 0x23, 0x99, 0xb5, 0x18, 0xef, 0x4a, 0x18, 0xdd, 0x82, 0x62,
+	0x0c, 0x35, 0xb2, 0x92, 0x93, 0x00, 0x22, 0x4a, 0x5d, 0xcb, 0xf7, 0x6c, 0x97, 0xb3, 0xc6, 0xca,
+	0x66, 0xa6, 0x59, 0xc4, 0x09, 0x80, 0xbe, 0x82, 0x6a, 0x40, 0x79, 0x70, 0x6e, 0x58, 0x74, 0x4a,
+	0xce, 0x0d, 0x87, 0x35, 0x72, 0x9b, 0x5a, 0x33, 0x83, 0xcb, 0x12, 0xdd, 0x17, 0xe0, 0x2b, 0x86,
+	0x9e, 0x42, 0x79, 0x44, 0xcc, 0x53, 0x3f, 0xa0, 0x8c, 0x89, 0x87, 0xe4, 0x37, 0xb5, 0x66, 0xa9,
+	0xb3, 0xde, 0x0a, 0x85, 0x6c, 0xed, 0x2e, 0x04, 0xf1, 0x12, 0x15, 0x21, 0xc8, 0xda, 0xee, 0x89,
+	0xd7, 0x28, 0x6c, 0x6a, 0xcd, 0x22, 0x96, 0xbf, 0xd1, 0x63, 0xc8, 0x09, 0x71, 0x6c, 0xde, 0x28,
+	0xca, 0x44, 0x8d, 0x24, 0x51, 0xa4, 0xeb, 0x9e, 0x8c, 0xe3, 0x90, 0xa7, 0x0f, 0xa0, 0x76, 0x21,
+	0x84, 0xbe, 0x84, 0xf2, 0x68, 0xea, 0x99, 0xa7, 0x86, 0x3b, 0x73, 0x46, 0x34, 0x90, 0xb2, 0x67,
+	0x71, 0x49, 0x62, 0x7d, 0x09, 0xa1, 0x9b, 0x50, 0xe0, 0x73, 0xc3, 0x76, 0x2d, 0x3a, 0x97, 0x2a,
+	0x57, 0x70, 0x9e, 0xcf, 0x7b, 0x62, 0xa9, 0xff, 0xa9, 0x41, 0x79, 0xf1, 0xd4, 0xe8, 0x0e, 0x94,
+	0x7e, 0x99, 0xd1, 0x19, 0x35, 0x2c, 0xea, 0xf3, 0x89, 0xcc, 0x56, 0xc1, 0x20, 0xa1, 0x7d, 0x81,
+	0xa0, 0x6d, 0xa8, 0x39, 0x64, 0x6e, 0x2c, 0x92, 0x54, 0xce, 0x8a, 0x43, 0xe6, 0xaf, 0x13, 0xde,
+	0x03, 0x58, 0x25, 0xbe, 0x4f, 0x5d, 0xcb, 0x98, 0x12, 0x4e, 0x5d, 0x53, 0x8a, 0x9a, 0x91, 0xa2,
+	0xd6, 0x54, 0xe0, 0x50, 0xe1, 0xaf, 0x18, 0xfa, 0x06, 0xd6, 0x45, 0xce, 0xcb, 0xfc, 0xac, 0xe4,
+	0x23, 0x87, 0xcc, 0x77, 0x96, 0xb7, 0xe8, 0x9f, 0x34, 0xb8, 0x11, 0x4b, 0xb1, 0xb3, 0xdc, 0x33,
+	0xff, 0xb5, 0x07, 0xbf, 0x00, 0x30, 0x27, 0xc4, 0x75, 0xe9, 0xd4, 0xb0, 0x2d, 0x59, 0x45, 0x11,
+	0x17, 0x43, 0xa4, 0x67, 0xa1, 0x1b, 0x90, 0xe7, 0x73, 0x63, 0x42, 0xd8, 0x24, 0xec, 0xb8, 0x1c,
+	0x9f, 0x77, 0x09, 0x9b, 0xa0, 0xef, 0xa0, 0x18, 0x0f, 0x83, 0x3c, 0x62, 0xa9, 0xb3, 0xd1, 0x52,
+	0xe3, 0xd2, 0x8a, 0xc6, 0xa5, 0x35, 0x8c, 0x18, 0x38, 0x21, 0xeb, 0x65, 0x80, 0x63, 0x4a, 0x4f,
+	0xfb, 0xf4, 0x3d, 0x65, 0x3c, 0x5a, 0x0d, 0xa6, 0x96, 0x58, 0xdd, 0x83, 0x8a, 0x58, 0x1d, 0xfb,
+	0xd4, 0xb4, 0x4f, 0x6c, 0x6a, 0xa1, 0xeb, 0x90, 0x5b, 0x7a, 0xa7, 0xe1, 0x4a, 0xbf, 0x03, 0x05,
+	0x41, 0x1c, 0xce, 0x7b, 0xfb, 0x68, 0x0d, 0x56, 0xc4, 0xab, 0xb5, 0x24, 0xa5, 0x88, 0xb3, 0x7c,
+	0xde, 0xb3, 0xf4, 0x2d, 0x95, 0x69, 0x57, 0xb4, 0x80, 0x3c, 0x30, 0x82, 0xac, 0x2c, 0x43, 0x93,
+	0x65, 0xc8, 0xdf, 0xba, 0xae, 0x1e, 0x8e, 0x29, 0x9b, 0x39, 0x14, 0x5d, 0x83, 0x15, 0xee, 0x9d,
+	0x52, 0x37, 0xa4, 0xa8, 0x85, 0xfe, 0x29, 0x0d, 0x65, 0x41, 0x3a, 0xf2, 0x98, 0xcd, 0x6d, 0xcf,
+	0x45, 0x8f, 0x20, 0xe7, 0xca, 0xb3, 0x4b, 0x5e, 0xa9, 0xb3, 0x16, 0x77, 0x6c, 0x52, 0x56, 0x37,
+	0x85, 0x43, 0x92, 0xa0, 0x7b, 0xb2, 0xb8, 0x46, 0xfa, 0x0a, 0xba, 0xaa, 0x5b, 0xd0, 0x15, 0x09,
+	0x3d, 0x81, 0x22, 0x8b, 0xaa, 0x97, 0x92, 0x97, 0x3a, 0xd7, 0x97, 0x76, 0xc4, 0xda, 0x74, 0x53,
+	0x38, 0xa1, 0xa2, 0x66, 0x24, 0x82, 0x7a, 0x17, 0xab, 0x4b, 0x7b, 0x84, 0x4c, 0xdd, 0x94, 0x52,
+	0x06, 0x3d, 0x0c, 0x85, 0x58, 0xb9, 0x22, 0x79, 0x2c, 0x97, 0x60, 0x0b, 0x96, 0x38, 0x7e, 0x20,
+	0xe5, 0x69, 0xe4, 0xae, 0x38, 0xbe, 0x52, 0x4e, 0x1c, 0x5f, 0x91, 0x76, 0x73, 0x90, 0x1d, 0x9e,
+	0xfb, 0x54, 0xff, 0x3d, 0xa3, 0x5e, 0x50, 0x4f, 0xcc, 0xf8, 0xd7, 0xb0, 0xc2, 0x38, 0x09, 0x22,
+	0xc1, 0xd6, 0x97, 0x52, 0x44, 0xba, 0x62, 0xc5, 0x41, 0xf7, 0x21, 0xcb, 0xb8, 0xe7, 0x37, 0xd2,
+	0x9f, 0xe3, 0x4a, 0x0a, 0xfa, 0x1e, 0x0a, 0x23, 0x3a, 0x21, 0x67, 0xb6, 0xa7, 0xfc, 0xb0, 0xda,
+	0xb9, 0xbd, 0x44, 0x17, 0x0f, 0x57, 0x65, 0x85, 0x2c, 0x1c, 0xf3, 0x85, 0xd9, 0x89, 0x71, 0x1b,
+	0x11, 0x6e, 0x4e, 0x0c, 0x66, 0x7f, 0x50, 0x6e, 0x59, 0xc1, 0x65, 0x87, 0xcc, 0x77, 0x05, 0x78,
+	0x6c, 0x7f, 0xa0, 0x68, 0x03, 0x0a, 0x27, 0xf6, 0x94, 0xd3, 0x80, 0x5a, 0x52, 0xaf, 0x02, 0x8e,
+	0xd7, 0xe8, 0x1e, 0xd4, 0x54, 0xd1, 0x86, 0xed, 0x72, 0x1a, 0x9c, 0x91, 0xa9, 0x94, 0xa8, 0x82,
+	0xab, 0x0a, 0xee, 0x85, 0x28, 0xba, 0x0d, 0x30, 0xa1, 0x24, 0xe0, 0x23, 0x4a, 0x38, 0x93, 0x7e,
+	0x59, 0xc0, 0x0b, 0x08, 0x7a, 0x02, 0x25, 0xd3, 0x73, 0xa4, 0xfb, 0xd8, 0x9e, 0xdb, 0x28, 0x6c,
+	0x66, 0x9a, 0xd5, 0xce, 0xb5, 0xb8, 0x92, 0xbd, 0x24, 0x86, 0x17, 0x89, 0xfa, 0x0f, 0x50, 0x5e,
+	0x2c, 0x0e, 0xad, 0xc3, 0xea, 0xee, 0xe1, 0x60, 0xef, 0x47, 0xe3, 0x4d, 0x7f, 0xd8, 0x3b, 0x34,
+	0xf0, 0xc1, 0xce, 0xfe, 0xdb, 0x7a, 0x4a, 0xc0, 0x2f, 0x76, 0x7a, 0x87, 0x46, 0xef, 0x85, 0xd1,
+	0x1f, 0x0c, 0x43, 0x58, 0xd3, 0x7f, 0x86, 0x7a, 0x94, 0x99, 0x5a, 0xf2, 0xbd, 0x5f, 0x3a, 0x89,
+	0x72, 0x8e, 0x7f, 0x3f, 0x89, 0x98, 0x2d, 0x8b, 0x70, 0x12, 0x7e, 0xbe, 0xe4, 0x6f, 0x7d, 0x0b,
+	0x4a, 0xaa, 0x3b, 0x86, 0x62, 0x8c, 0xfe, 0x61, 0xb8, 0xb6, 0xa0, 0xd8, 0x8d, 0x84, 0x10, 0xb3,
+	0x3e, 0xa1, 0xf6, 0x78, 0xc2, 0xa3, 0x59, 0x57, 0x2b, 0xdd, 0x8e, 0x32, 0x1d, 0x89, 0xef, 0xd4,
+	0x05, 0xc7, 0xd2, 0x2e, 0x3a, 0x56, 0xe2, 0x18, 0xe9, 0x45, 0xc7, 0x40, 0x5b, 0x50, 0xf1, 0x03,
+	0x7a, 0x66, 0x7b, 0x33, 0xb6, 0xe8, 0x67, 0xe5, 0x08, 0x14, 0x5d, 0xaf, 0xb7, 0x21, 0x17, 0x4a,
+	0x71, 0x17, 0x72, 0xf2, 0xf3, 0x21, 0xfc, 0x33, 0xd3, 0x2c, 0x75, 0x2a, 0x91, 0x7f, 0xca, 0x38,
+	0x0e, 0x83, 0xfa, 0xaf, 0xb0, 0xf6, 0x22, 0x6c, 0x88, 0x61, 0x40, 0x5c, 0x46, 0x4c, 0xe9, 0x11,
+	0x57, 0x59, 0x12, 0xda, 0x86, 0x2c, 0x3f, 0xf7, 0xa9, 0x3c, 0x57, 0xb5, 0x83, 0xa2, 0x84, 0xea,
+	0xcb, 0x2d, 0xa6, 0x06, 0xcb, 0xb8, 0x68, 0xac, 0x33, 0x32, 0xb5, 0x2d, 0x22, 0x52, 0x19, 0xa6,
+	0x67, 0x51, 0x79, 0xd6, 0x0a, 0xae, 0x26, 0xf0, 0x9e, 0x67, 0x51, 0xfd, 0x37, 0x0d, 0x2a, 0xd1,
+	0xd3, 0xe5, 0xb1, 0xfe, 0xaf, 0x36, 0xaf, 0x61, 0x3d, 0x6a, 0x6b, 0x83, 0x27, 0x65, 0x88, 0x6f,
+	0x95, 0xa8, 0xfd, 0x56, 0xdc, 0x01, 0x57, 0xd4, 0x8a, 0xaf, 0x9d, 0x5c, 0x06, 0x99, 0xfe, 0x31,
+	0x03, 0xb5, 0x7d, 0x3a, 0xb5, 0xcf, 0x68, 0x10, 0xdf, 0x8b, 0x9a, 0x9f, 0xff, 0x26, 0x09, 0x1b,
+	0x51, 0x71, 0x74, 0x17, 0x56, 0xa4, 0xc0, 0xa1, 0x0b, 0x2c, 0x8b, 0xdf, 0x4d, 0x61, 0x15, 0x45,
+	0xf7, 0xe3, 0x97, 0xa4, 0x9c, 0xb2, 0x96, 0x5c, 0x1e, 0x24, 0x2c, 0x32, 0x2a, 0x02, 0x7a, 0x0e,
+	0xd5, 0xb8, 0x44, 0x95, 0x3a, 0x7b, 0xc1, 0xff, 0x96, 0x94, 0xec, 0xa6, 0x70, 0xe5, 0x64, 0x49,
+	0xda, 0xa7, 0x50, 0x0e, 0xc7, 0x5d, 0xf5, 0xb1, 0xb2, 0xcf, 0x64, 0x38, 0x16, 0x9a, 0xbd, 0x9b,
+	0xc2, 0xa5, 0x20, 0x59, 0xa2, 0x0e, 0x14, 0xe3, 0x71, 0x0f, 0x6d, 0x14, 0xc5, 0xfb, 0xe2, 0xfe,
+	0x17, 0x7e, 0x1e, 0xd3, 0x50, 0x17, 0x56, 0xcd, 0x78, 0x3c, 0x8d, 0xb0, 0x4a, 0x75, 0xd7, 0xba,
+	0x79, 0x69, 0x20, 0xa3, 0x01, 0xee, 0xa6, 0x70, 0xdd, 0xbc, 0x80, 0xc5, 0x96, 0xfc, 0x87, 0x06,
+	0xd7, 0x07, 0x6a, 0xa3, 0x6a, 0xb9, 0x83, 0x39, 0xa7, 0xae, 0x9c, 0xdf, 0x0e, 0x14, 0xfc, 0xc0,
+	0xf6, 0x02, 0x9b, 0x9f, 0x87, 0xaf, 0x26, 0x91, 0xe5, 0x28, 0x0c, 0xec, 0x4d, 0x09, 0x63, 0x38,
+	0xe6, 0x89, 0x0b, 0xd5, 0x38, 0xf0, 0x66, 0x7e, 0x72, 0x6d, 0xc8, 0xcb, 0x75, 0xcf, 0x12, 0x86,
+	0x47, 0xa3, 0xdc, 0x2c, 0x9c, 0xb3, 0x05, 0x44, 0x5c, 0xd7, 0xc8, 0x7b, 0x62, 0x73, 0x23, 0xbc,
+	0xf9, 0x65, 0xa5, 0x25, 0x96, 0x24, 0xa6, 0x6e, 0x74, 0x0f, 0x1e, 0x41, 0x69, 0xc1, 0x6d, 0x50,
+	0x01, 0xb2, 0xfd, 0x41, 0xff, 0xa0, 0x9e, 0x12, 0xbf, 0x5e, 0xbe, 0xeb, 0x1d, 0xd5, 0x35, 0x04,
+	0x90, 0x3b, 0xee, 0xef, 0x1c, 0x1d, 0xbd, 0xad, 0xa7, 0x1f, 0x3c, 0x83, 0xca, 0xd2, 0x39, 0x45,
+	0xb0, 0x3f, 0xc0, 0xaf, 0x76, 0x0e, 0xeb, 0x29, 0x94, 0x87, 0xcc, 0xe1, 0xe0, 0xa7, 0xba, 0x26,
+	0xf6, 0x76, 0x7b, 0x2f, 0xbb, 0xf5, 0x34, 0x2a, 0x41, 0x7e, 0x6f, 0xd0, 0x1f, 0xe2, 0xc1, 0x61,
+	0x3d, 0xd3, 0xf9, 0xa8, 0x41, 0x6d, 0x87, 0x7b, 0x8e, 0x6d, 0xc6, 0x97, 0x29, 0xf4, 0x1c, 0x8a,
+	0xc9, 0xa2, 0x1e, 0xb5, 0xdf, 0x81, 0x7b, 0x46, 0xa7, 0x9e, 0x4f, 0x37, 0x36, 0x2e, 0xdf, 0x52,
+	0xa3, 0x2e, 0xd7, 0x53, 0x4d, 0xed, 0xb1, 0x86, 0x9e, 0x41, 0x3e, 0x6c, 0xff, 0x2b, 0xb6, 0x27,
+	0x97, 0xdc, 0x0b, 0x23, 0xa2, 0x36, 0xef, 0xbe, 0x81, 0xbb, 0x5e, 0x30, 0x6e, 0x4d, 0xce, 0x7d,
+	0x1a, 0x88, 0x1b, 0x1d, 0x0d, 0x5a, 0x27, 0x64, 0x14, 0xd8, 0xa6, 0xba, 0x5a, 0xb1, 0x68, 0xfb,
+	0xbb, 0x87, 0x63, 0x9b, 0x4f, 0x66, 0x23, 0xf1, 0x80, 0xf6, 0x02, 0xbb, 0xad, 0xd8, 0xea, 0xff,
+	0x16, 0xd6, 0x0e, 0xd9, 0xa3, 0x9c, 0x5c, 0x7f, 0xfb, 0xf7, 0x00, 0xe9, 0x48, 0x84, 0x31, 0x07,
+	0x0d, 0x00, 0x00,
 }
@@ -0,0 +1,1013 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/admin.proto
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+import common3 "github.com/hyperledger/fabric/protos/common"
+import msp "github.com/hyperledger/fabric/protos/msp"
+import google_protobuf1 "github.com/golang/protobuf/ptypes/empty"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type ChannelList struct {
+	ChannelIds []string `protobuf:"bytes,1,rep,name=channel_ids,json=channelIds" json:"channel_ids,omitempty"`
+}
+
+func (m *ChannelList) Reset()                    { *m = ChannelList{} }
+func (m *ChannelList) String() string            { return proto.CompactTextString(m) }
+func (*ChannelList) ProtoMessage()               {}
+func (*ChannelList) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
+func (m *ChannelList) GetChannelIds() []string {
+	if m != nil {
+		return m.ChannelIds
+	}
+	return nil
+}
+
+type ChannelStatusRequest struct {
+	ChannelId string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+}
+
+func (m *ChannelStatusRequest) Reset()                    { *m = ChannelStatusRequest{} }
+func (m *ChannelStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChannelStatusRequest) ProtoMessage()               {}
+func (*ChannelStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+
+func (m *ChannelStatusRequest) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+type ChannelStatus struct {
+	ChannelId          string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Height             uint64 `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	ConsensusType      string `protobuf:"bytes,3,opt,name=consensus_type,json=consensusType" json:"consensus_type,omitempty"`
+	SystemChannel      bool   `protobuf:"varint,4,opt,name=system_channel,json=systemChannel" json:"system_channel,omitempty"`
+	ConsenterAvailable bool   `protobuf:"varint,5,opt,name=consenter_available,json=consenterAvailable" json:"consenter_available,omitempty"`
+}
+
+func (m *ChannelStatus) Reset()                    { *m = ChannelStatus{} }
+func (m *ChannelStatus) String() string            { return proto.CompactTextString(m) }
+func (*ChannelStatus) ProtoMessage()               {}
+func (*ChannelStatus) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+
+func (m *ChannelStatus) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *ChannelStatus) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *ChannelStatus) GetConsensusType() string {
+	if m != nil {
+		return m.ConsensusType
+	}
+	return ""
+}
+
+func (m *ChannelStatus) GetSystemChannel() bool {
+	if m != nil {
+		return m.SystemChannel
+	}
+	return false
+}
+
+func (m *ChannelStatus) GetConsenterAvailable() bool {
+	if m != nil {
+		return m.ConsenterAvailable
+	}
+	return false
+}
+
+type JoinChannelRequest struct {
+	ConfigBlock *common.Block `protobuf:"bytes,1,opt,name=config_block,json=configBlock" json:"config_block,omitempty"`
+}
+
+func (m *JoinChannelRequest) Reset()                    { *m = JoinChannelRequest{} }
+func (m *JoinChannelRequest) String() string            { return proto.CompactTextString(m) }
+func (*JoinChannelRequest) ProtoMessage()               {}
+func (*JoinChannelRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+func (m *JoinChannelRequest) GetConfigBlock() *common.Block {
+	if m != nil {
+		return m.ConfigBlock
+	}
+	return nil
+}
+
+type MaintenanceMode struct {
+	Enabled bool `protobuf:"varint,1,opt,name=enabled" json:"enabled,omitempty"`
+}
+
+func (m *MaintenanceMode) Reset()                    { *m = MaintenanceMode{} }
+func (m *MaintenanceMode) String() string            { return proto.CompactTextString(m) }
+func (*MaintenanceMode) ProtoMessage()               {}
+func (*MaintenanceMode) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+func (m *MaintenanceMode) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+// LogSpec is a logging spec of the form of General.LogLevel, e.g.
+// "orderer/kafka=warning:orderer/common/broadcast=debug:info"
+type LogSpec struct {
+	Spec string `protobuf:"bytes,1,opt,name=spec" json:"spec,omitempty"`
+}
+
+func (m *LogSpec) Reset()                    { *m = LogSpec{} }
+func (m *LogSpec) String() string            { return proto.CompactTextString(m) }
+func (*LogSpec) ProtoMessage()               {}
+func (*LogSpec) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+
+func (m *LogSpec) GetSpec() string {
+	if m != nil {
+		return m.Spec
+	}
+	return ""
+}
+
+type ModuleLevel struct {
+	Module string `protobuf:"bytes,1,opt,name=module" json:"module,omitempty"`
+	Level  string `protobuf:"bytes,2,opt,name=level" json:"level,omitempty"`
+}
+
+func (m *ModuleLevel) Reset()                    { *m = ModuleLevel{} }
+func (m *ModuleLevel) String() string            { return proto.CompactTextString(m) }
+func (*ModuleLevel) ProtoMessage()               {}
+func (*ModuleLevel) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+
+func (m *ModuleLevel) GetModule() string {
+	if m != nil {
+		return m.Module
+	}
+	return ""
+}
+
+func (m *ModuleLevel) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type StageOrganizationRequest struct {
+	Consortium string         `protobuf:"bytes,1,opt,name=consortium" json:"consortium,omitempty"`
+	MspConfig  *msp.MSPConfig `protobuf:"bytes,2,opt,name=msp_config,json=mspConfig" json:"msp_config,omitempty"`
+}
+
+func (m *StageOrganizationRequest) Reset()                    { *m = StageOrganizationRequest{} }
+func (m *StageOrganizationRequest) String() string            { return proto.CompactTextString(m) }
+func (*StageOrganizationRequest) ProtoMessage()               {}
+func (*StageOrganizationRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
+
+func (m *StageOrganizationRequest) GetConsortium() string {
+	if m != nil {
+		return m.Consortium
+	}
+	return ""
+}
+
+func (m *StageOrganizationRequest) GetMspConfig() *msp.MSPConfig {
+	if m != nil {
+		return m.MspConfig
+	}
+	return nil
+}
+
+type ChannelMembershipRequest struct {
+	MspId      string   `protobuf:"bytes,1,opt,name=msp_id,json=mspId" json:"msp_id,omitempty"`
+	ChannelIds []string `protobuf:"bytes,2,rep,name=channel_ids,json=channelIds" json:"channel_ids,omitempty"`
+}
+
+func (m *ChannelMembershipRequest) Reset()                    { *m = ChannelMembershipRequest{} }
+func (m *ChannelMembershipRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChannelMembershipRequest) ProtoMessage()               {}
+func (*ChannelMembershipRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{8} }
+
+func (m *ChannelMembershipRequest) GetMspId() string {
+	if m != nil {
+		return m.MspId
+	}
+	return ""
+}
+
+func (m *ChannelMembershipRequest) GetChannelIds() []string {
+	if m != nil {
+		return m.ChannelIds
+	}
+	return nil
+}
+
+type ProposalRequest struct {
+	ProposalId string `protobuf:"bytes,1,opt,name=proposal_id,json=proposalId" json:"proposal_id,omitempty"`
+}
+
+func (m *ProposalRequest) Reset()                    { *m = ProposalRequest{} }
+func (m *ProposalRequest) String() string            { return proto.CompactTextString(m) }
+func (*ProposalRequest) ProtoMessage()               {}
+func (*ProposalRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{9} }
+
+func (m *ProposalRequest) GetProposalId() string {
+	if m != nil {
+		return m.ProposalId
+	}
+	return ""
+}
+
+type ProposalSignatures struct {
+	ProposalId string                     `protobuf:"bytes,1,opt,name=proposal_id,json=proposalId" json:"proposal_id,omitempty"`
+	Signatures []*common3.ConfigSignature `protobuf:"bytes,2,rep,name=signatures" json:"signatures,omitempty"`
+}
+
+func (m *ProposalSignatures) Reset()                    { *m = ProposalSignatures{} }
+func (m *ProposalSignatures) String() string            { return proto.CompactTextString(m) }
+func (*ProposalSignatures) ProtoMessage()               {}
+func (*ProposalSignatures) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{10} }
+
+func (m *ProposalSignatures) GetProposalId() string {
+	if m != nil {
+		return m.ProposalId
+	}
+	return ""
+}
+
+func (m *ProposalSignatures) GetSignatures() []*common3.ConfigSignature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+// ConfigUpdateProposal is a config update to be signed by the admins of the
+// organizations whose policies govern the modified config
+type ConfigUpdateProposal struct {
+	ProposalId   string                     `protobuf:"bytes,1,opt,name=proposal_id,json=proposalId" json:"proposal_id,omitempty"`
+	ChannelId    string                     `protobuf:"bytes,2,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	MspId        string                     `protobuf:"bytes,3,opt,name=msp_id,json=mspId" json:"msp_id,omitempty"`
+	ConfigUpdate []byte                     `protobuf:"bytes,4,opt,name=config_update,json=configUpdate,proto3" json:"config_update,omitempty"`
+	Signatures   []*common3.ConfigSignature `protobuf:"bytes,5,rep,name=signatures" json:"signatures,omitempty"`
+	Ready        bool                       `protobuf:"varint,6,opt,name=ready" json:"ready,omitempty"`
+	Pending      string                     `protobuf:"bytes,7,opt,name=pending" json:"pending,omitempty"`
+	Envelope     *common.Envelope           `protobuf:"bytes,8,opt,name=envelope" json:"envelope,omitempty"`
+}
+
+func (m *ConfigUpdateProposal) Reset()                    { *m = ConfigUpdateProposal{} }
+func (m *ConfigUpdateProposal) String() string            { return proto.CompactTextString(m) }
+func (*ConfigUpdateProposal) ProtoMessage()               {}
+func (*ConfigUpdateProposal) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{11} }
+
+func (m *ConfigUpdateProposal) GetProposalId() string {
+	if m != nil {
+		return m.ProposalId
+	}
+	return ""
+}
+
+func (m *ConfigUpdateProposal) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *ConfigUpdateProposal) GetMspId() string {
+	if m != nil {
+		return m.MspId
+	}
+	return ""
+}
+
+func (m *ConfigUpdateProposal) GetConfigUpdate() []byte {
+	if m != nil {
+		return m.ConfigUpdate
+	}
+	return nil
+}
+
+func (m *ConfigUpdateProposal) GetSignatures() []*common3.ConfigSignature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+func (m *ConfigUpdateProposal) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+func (m *ConfigUpdateProposal) GetPending() string {
+	if m != nil {
+		return m.Pending
+	}
+	return ""
+}
+
+func (m *ConfigUpdateProposal) GetEnvelope() *common.Envelope {
+	if m != nil {
+		return m.Envelope
+	}
+	return nil
+}
+
+type ConfigUpdateProposals struct {
+	Proposals []*ConfigUpdateProposal `protobuf:"bytes,1,rep,name=proposals" json:"proposals,omitempty"`
+}
+
+func (m *ConfigUpdateProposals) Reset()                    { *m = ConfigUpdateProposals{} }
+func (m *ConfigUpdateProposals) String() string            { return proto.CompactTextString(m) }
+func (*ConfigUpdateProposals) ProtoMessage()               {}
+func (*ConfigUpdateProposals) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{12} }
+
+func (m *ConfigUpdateProposals) GetProposals() []*ConfigUpdateProposal {
+	if m != nil {
+		return m.Proposals
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ChannelList)(nil), "orderer.ChannelList")
+	proto.RegisterType((*ChannelStatusRequest)(nil), "orderer.ChannelStatusRequest")
+	proto.RegisterType((*ChannelStatus)(nil), "orderer.ChannelStatus")
+	proto.RegisterType((*JoinChannelRequest)(nil), "orderer.JoinChannelRequest")
+	proto.RegisterType((*MaintenanceMode)(nil), "orderer.MaintenanceMode")
+	proto.RegisterType((*LogSpec)(nil), "orderer.LogSpec")
+	proto.RegisterType((*ModuleLevel)(nil), "orderer.ModuleLevel")
+	proto.RegisterType((*StageOrganizationRequest)(nil), "orderer.StageOrganizationRequest")
+	proto.RegisterType((*ChannelMembershipRequest)(nil), "orderer.ChannelMembershipRequest")
+	proto.RegisterType((*ProposalRequest)(nil), "orderer.ProposalRequest")
+	proto.RegisterType((*ProposalSignatures)(nil), "orderer.ProposalSignatures")
+	proto.RegisterType((*ConfigUpdateProposal)(nil), "orderer.ConfigUpdateProposal")
+	proto.RegisterType((*ConfigUpdateProposals)(nil), "orderer.ConfigUpdateProposals")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Admin service
+
+type AdminClient interface {
+	// ListChannels returns the IDs of the channels served by this node
+	ListChannels(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ChannelList, error)
+	// GetChannelStatus returns the status of a single channel
+	GetChannelStatus(ctx context.Context, in *ChannelStatusRequest, opts ...grpc.CallOption) (*ChannelStatus, error)
+	// JoinChannel starts serving a standard channel from its genesis block
+	JoinChannel(ctx context.Context, in *JoinChannelRequest, opts ...grpc.CallOption) (*ChannelStatus, error)
+	// SetMaintenanceMode enables or disables maintenance mode, in which
+	// broadcast requests are rejected with SERVICE_UNAVAILABLE
+	SetMaintenanceMode(ctx context.Context, in *MaintenanceMode, opts ...grpc.CallOption) (*MaintenanceMode, error)
+	// Shutdown gracefully stops the node
+	Shutdown(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// GetLogSpec returns the logging spec currently in effect
+	GetLogSpec(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*LogSpec, error)
+	// SetLogSpec replaces the logging spec, as reloading the configuration
+	// does.  It remains in effect until the next reload.
+	SetLogSpec(ctx context.Context, in *LogSpec, opts ...grpc.CallOption) (*LogSpec, error)
+	// GetModuleLevel returns the level at which a module logs
+	GetModuleLevel(ctx context.Context, in *ModuleLevel, opts ...grpc.CallOption) (*ModuleLevel, error)
+	// SetModuleLevel overrides the level of a single module in the logging
+	// spec, returning the resulting spec
+	SetModuleLevel(ctx context.Context, in *ModuleLevel, opts ...grpc.CallOption) (*LogSpec, error)
+	// StageOrganization registers the MSP material of an organization to be
+	// added to a consortium, returning the proposal to add it to the
+	// consortium on the system channel
+	StageOrganization(ctx context.Context, in *StageOrganizationRequest, opts ...grpc.CallOption) (*ConfigUpdateProposal, error)
+	// ProposeChannelMembership returns the proposals to add an organization,
+	// staged or already member of a consortium, to application channels
+	ProposeChannelMembership(ctx context.Context, in *ChannelMembershipRequest, opts ...grpc.CallOption) (*ConfigUpdateProposals, error)
+	// AddProposalSignatures adds signatures of the config update of a
+	// proposal, returning the signature collection progress
+	AddProposalSignatures(ctx context.Context, in *ProposalSignatures, opts ...grpc.CallOption) (*ConfigUpdateProposal, error)
+	// GetProposal returns the signature collection progress of a proposal
+	GetProposal(ctx context.Context, in *ProposalRequest, opts ...grpc.CallOption) (*ConfigUpdateProposal, error)
+	// ListProposals returns the pending proposals
+	ListProposals(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ConfigUpdateProposals, error)
+	// DiscardProposal forgets a proposal, once submitted or abandoned
+	DiscardProposal(ctx context.Context, in *ProposalRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListChannels(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ChannelList, error) {
+	out := new(ChannelList)
+	err := grpc.Invoke(ctx, "/orderer.Admin/ListChannels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetChannelStatus(ctx context.Context, in *ChannelStatusRequest, opts ...grpc.CallOption) (*ChannelStatus, error) {
+	out := new(ChannelStatus)
+	err := grpc.Invoke(ctx, "/orderer.Admin/GetChannelStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) JoinChannel(ctx context.Context, in *JoinChannelRequest, opts ...grpc.CallOption) (*ChannelStatus, error) {
+	out := new(ChannelStatus)
+	err := grpc.Invoke(ctx, "/orderer.Admin/JoinChannel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetMaintenanceMode(ctx context.Context, in *MaintenanceMode, opts ...grpc.CallOption) (*MaintenanceMode, error) {
+	out := new(MaintenanceMode)
+	err := grpc.Invoke(ctx, "/orderer.Admin/SetMaintenanceMode", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Shutdown(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/orderer.Admin/Shutdown", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetLogSpec(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*LogSpec, error) {
+	out := new(LogSpec)
+	err := grpc.Invoke(ctx, "/orderer.Admin/GetLogSpec", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetLogSpec(ctx context.Context, in *LogSpec, opts ...grpc.CallOption) (*LogSpec, error) {
+	out := new(LogSpec)
+	err := grpc.Invoke(ctx, "/orderer.Admin/SetLogSpec", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetModuleLevel(ctx context.Context, in *ModuleLevel, opts ...grpc.CallOption) (*ModuleLevel, error) {
+	out := new(ModuleLevel)
+	err := grpc.Invoke(ctx, "/orderer.Admin/GetModuleLevel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetModuleLevel(ctx context.Context, in *ModuleLevel, opts ...grpc.CallOption) (*LogSpec, error) {
+	out := new(LogSpec)
+	err := grpc.Invoke(ctx, "/orderer.Admin/SetModuleLevel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) StageOrganization(ctx context.Context, in *StageOrganizationRequest, opts ...grpc.CallOption) (*ConfigUpdateProposal, error) {
+	out := new(ConfigUpdateProposal)
+	err := grpc.Invoke(ctx, "/orderer.Admin/StageOrganization", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ProposeChannelMembership(ctx context.Context, in *ChannelMembershipRequest, opts ...grpc.CallOption) (*ConfigUpdateProposals, error) {
+	out := new(ConfigUpdateProposals)
+	err := grpc.Invoke(ctx, "/orderer.Admin/ProposeChannelMembership", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) AddProposalSignatures(ctx context.Context, in *ProposalSignatures, opts ...grpc.CallOption) (*ConfigUpdateProposal, error) {
+	out := new(ConfigUpdateProposal)
+	err := grpc.Invoke(ctx, "/orderer.Admin/AddProposalSignatures", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetProposal(ctx context.Context, in *ProposalRequest, opts ...grpc.CallOption) (*ConfigUpdateProposal, error) {
+	out := new(ConfigUpdateProposal)
+	err := grpc.Invoke(ctx, "/orderer.Admin/GetProposal", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListProposals(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ConfigUpdateProposals, error) {
+	out := new(ConfigUpdateProposals)
+	err := grpc.Invoke(ctx, "/orderer.Admin/ListProposals", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DiscardProposal(ctx context.Context, in *ProposalRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/orderer.Admin/DiscardProposal", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Admin service
+
+type AdminServer interface {
+	// ListChannels returns the IDs of the channels served by this node
+	ListChannels(context.Context, *google_protobuf1.Empty) (*ChannelList, error)
+	// GetChannelStatus returns the status of a single channel
+	GetChannelStatus(context.Context, *ChannelStatusRequest) (*ChannelStatus, error)
+	// JoinChannel starts serving a standard channel from its genesis block
+	JoinChannel(context.Context, *JoinChannelRequest) (*ChannelStatus, error)
+	// SetMaintenanceMode enables or disables maintenance mode, in which
+	// broadcast requests are rejected with SERVICE_UNAVAILABLE
+	SetMaintenanceMode(context.Context, *MaintenanceMode) (*MaintenanceMode, error)
+	// Shutdown gracefully stops the node
+	Shutdown(context.Context, *google_protobuf1.Empty) (*google_protobuf1.Empty, error)
+	// GetLogSpec returns the logging spec currently in effect
+	GetLogSpec(context.Context, *google_protobuf1.Empty) (*LogSpec, error)
+	// SetLogSpec replaces the logging spec, as reloading the configuration
+	// does.  It remains in effect until the next reload.
+	SetLogSpec(context.Context, *LogSpec) (*LogSpec, error)
+	// GetModuleLevel returns the level at which a module logs
+	GetModuleLevel(context.Context, *ModuleLevel) (*ModuleLevel, error)
+	// SetModuleLevel overrides the level of a single module in the logging
+	// spec, returning the resulting spec
+	SetModuleLevel(context.Context, *ModuleLevel) (*LogSpec, error)
+	// StageOrganization registers the MSP material of an organization to be
+	// added to a consortium, returning the proposal to add it to the
+	// consortium on the system channel
+	StageOrganization(context.Context, *StageOrganizationRequest) (*ConfigUpdateProposal, error)
+	// ProposeChannelMembership returns the proposals to add an organization,
+	// staged or already member of a consortium, to application channels
+	ProposeChannelMembership(context.Context, *ChannelMembershipRequest) (*ConfigUpdateProposals, error)
+	// AddProposalSignatures adds signatures of the config update of a
+	// proposal, returning the signature collection progress
+	AddProposalSignatures(context.Context, *ProposalSignatures) (*ConfigUpdateProposal, error)
+	// GetProposal returns the signature collection progress of a proposal
+	GetProposal(context.Context, *ProposalRequest) (*ConfigUpdateProposal, error)
+	// ListProposals returns the pending proposals
+	ListProposals(context.Context, *google_protobuf1.Empty) (*ConfigUpdateProposals, error)
+	// DiscardProposal forgets a proposal, once submitted or abandoned
+	DiscardProposal(context.Context, *ProposalRequest) (*google_protobuf1.Empty, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_ListChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/ListChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListChannels(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetChannelStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetChannelStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/GetChannelStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetChannelStatus(ctx, req.(*ChannelStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_JoinChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).JoinChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/JoinChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).JoinChannel(ctx, req.(*JoinChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetMaintenanceMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintenanceMode)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetMaintenanceMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/SetMaintenanceMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetMaintenanceMode(ctx, req.(*MaintenanceMode))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Shutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/Shutdown",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Shutdown(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetLogSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetLogSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/GetLogSpec",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetLogSpec(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetLogSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogSpec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetLogSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/SetLogSpec",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetLogSpec(ctx, req.(*LogSpec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetModuleLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModuleLevel)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetModuleLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/GetModuleLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetModuleLevel(ctx, req.(*ModuleLevel))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetModuleLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModuleLevel)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetModuleLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/SetModuleLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetModuleLevel(ctx, req.(*ModuleLevel))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_StageOrganization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StageOrganizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).StageOrganization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/StageOrganization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).StageOrganization(ctx, req.(*StageOrganizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ProposeChannelMembership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelMembershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ProposeChannelMembership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/ProposeChannelMembership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ProposeChannelMembership(ctx, req.(*ChannelMembershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_AddProposalSignatures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalSignatures)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).AddProposalSignatures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/AddProposalSignatures",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).AddProposalSignatures(ctx, req.(*ProposalSignatures))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/GetProposal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetProposal(ctx, req.(*ProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListProposals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListProposals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/ListProposals",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListProposals(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DiscardProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DiscardProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.Admin/DiscardProposal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DiscardProposal(ctx, req.(*ProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListChannels",
+			Handler:    _Admin_ListChannels_Handler,
+		},
+		{
+			MethodName: "GetChannelStatus",
+			Handler:    _Admin_GetChannelStatus_Handler,
+		},
+		{
+			MethodName: "JoinChannel",
+			Handler:    _Admin_JoinChannel_Handler,
+		},
+		{
+			MethodName: "SetMaintenanceMode",
+			Handler:    _Admin_SetMaintenanceMode_Handler,
+		},
+		{
+			MethodName: "Shutdown",
+			Handler:    _Admin_Shutdown_Handler,
+		},
+		{
+			MethodName: "GetLogSpec",
+			Handler:    _Admin_GetLogSpec_Handler,
+		},
+		{
+			MethodName: "SetLogSpec",
+			Handler:    _Admin_SetLogSpec_Handler,
+		},
+		{
+			MethodName: "GetModuleLevel",
+			Handler:    _Admin_GetModuleLevel_Handler,
+		},
+		{
+			MethodName: "SetModuleLevel",
+			Handler:    _Admin_SetModuleLevel_Handler,
+		},
+		{
+			MethodName: "StageOrganization",
+			Handler:    _Admin_StageOrganization_Handler,
+		},
+		{
+			MethodName: "ProposeChannelMembership",
+			Handler:    _Admin_ProposeChannelMembership_Handler,
+		},
+		{
+			MethodName: "AddProposalSignatures",
+			Handler:    _Admin_AddProposalSignatures_Handler,
+		},
+		{
+			MethodName: "GetProposal",
+			Handler:    _Admin_GetProposal_Handler,
+		},
+		{
+			MethodName: "ListProposals",
+			Handler:    _Admin_ListProposals_Handler,
+		},
+		{
+			MethodName: "DiscardProposal",
+			Handler:    _Admin_DiscardProposal_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "orderer/admin.proto",
+}
+
+func init() { proto.RegisterFile("orderer/admin.proto", fileDescriptor1) }
+
+var fileDescriptor1 = []byte{
+	// 943 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0x6d, 0x6f, 0xe3, 0x44,
+	0x10, 0x4e, 0x7a, 0x7d, 0x49, 0xc6, 0x4d, 0x5b, 0xb6, 0x69, 0xb1, 0x72, 0xea, 0x5d, 0x59, 0x74,
+	0x52, 0x25, 0x0e, 0x07, 0x05, 0x21, 0x10, 0x87, 0x4e, 0xea, 0xdd, 0x95, 0xd2, 0xa3, 0x11, 0x27,
+	0xe7, 0x2a, 0x24, 0xbe, 0x44, 0x8e, 0x3d, 0x75, 0x2c, 0xec, 0x5d, 0xe3, 0x5d, 0x17, 0xc2, 0x3f,
+	0xe2, 0x37, 0xf0, 0x99, 0xff, 0x85, 0xec, 0x5d, 0xbf, 0x34, 0x2f, 0x4d, 0x3f, 0x35, 0xf3, 0xcc,
+	0x33, 0x33, 0xbb, 0xe3, 0x67, 0x1f, 0x15, 0x0e, 0x79, 0xe2, 0x61, 0x82, 0x49, 0xdf, 0xf1, 0xa2,
+	0x80, 0x59, 0x71, 0xc2, 0x25, 0x27, 0x3b, 0x1a, 0xec, 0x1d, 0xba, 0x3c, 0x8a, 0x38, 0xeb, 0xab,
+	0x3f, 0x2a, 0xdb, 0x3b, 0x2a, 0x41, 0x76, 0x1b, 0xf8, 0xf2, 0x2f, 0x0d, 0x77, 0x23, 0x11, 0xf7,
+	0x23, 0x11, 0x8f, 0x15, 0xae, 0xd1, 0xa7, 0x3e, 0xe7, 0x7e, 0x88, 0xfd, 0x3c, 0x9a, 0xa4, 0xb7,
+	0x7d, 0x8c, 0x62, 0x39, 0x53, 0x49, 0x6a, 0x81, 0xf1, 0x76, 0xea, 0x30, 0x86, 0xe1, 0x75, 0x20,
+	0x24, 0x79, 0x0e, 0x86, 0xab, 0xc2, 0x71, 0xe0, 0x09, 0xb3, 0x79, 0xfa, 0xe4, 0xac, 0x6d, 0x83,
+	0x86, 0xae, 0x3c, 0x41, 0xbf, 0x81, 0xae, 0xe6, 0x8f, 0xa4, 0x23, 0x53, 0x61, 0xe3, 0x1f, 0x29,
+	0x0a, 0x49, 0x4e, 0x00, 0xaa, 0x42, 0xb3, 0x79, 0xda, 0x3c, 0x6b, 0xdb, 0xed, 0xb2, 0x8e, 0xfe,
+	0xd7, 0x84, 0xce, 0xbd, 0xba, 0x35, 0x05, 0xe4, 0x18, 0xb6, 0xa7, 0x18, 0xf8, 0x53, 0x69, 0x6e,
+	0x9c, 0x36, 0xcf, 0x36, 0x6d, 0x1d, 0x91, 0x17, 0xb0, 0xe7, 0x72, 0x26, 0x90, 0x89, 0x54, 0x8c,
+	0xe5, 0x2c, 0x46, 0xf3, 0x49, 0x5e, 0xda, 0x29, 0xd1, 0x8f, 0xb3, 0x18, 0x33, 0x9a, 0x98, 0x09,
+	0x89, 0xd1, 0x58, 0xb7, 0x34, 0x37, 0x4f, 0x9b, 0x67, 0x2d, 0xbb, 0xa3, 0x50, 0x7d, 0x14, 0xd2,
+	0x87, 0x43, 0x55, 0x27, 0x31, 0x19, 0x3b, 0x77, 0x4e, 0x10, 0x3a, 0x93, 0x10, 0xcd, 0xad, 0x9c,
+	0x4b, 0xca, 0xd4, 0x79, 0x91, 0xa1, 0x3f, 0x02, 0x79, 0xcf, 0x03, 0xa6, 0xeb, 0x8b, 0xcb, 0x7f,
+	0x05, 0xbb, 0x6a, 0xe3, 0xe3, 0x49, 0xc8, 0xdd, 0xdf, 0xf3, 0xdb, 0x18, 0x83, 0x8e, 0xa5, 0xbf,
+	0xd9, 0x9b, 0x0c, 0xb4, 0x0d, 0x45, 0xc9, 0x03, 0xfa, 0x05, 0xec, 0x0f, 0x9d, 0x80, 0x49, 0x64,
+	0x0e, 0x73, 0x71, 0xc8, 0x3d, 0x24, 0x26, 0xec, 0x20, 0xcb, 0x86, 0xa8, 0x6d, 0xb4, 0xec, 0x22,
+	0xa4, 0x27, 0xb0, 0x73, 0xcd, 0xfd, 0x51, 0x8c, 0x2e, 0x21, 0xb0, 0x29, 0x62, 0x74, 0xf5, 0xbe,
+	0xf2, 0xdf, 0xf4, 0x15, 0x18, 0x43, 0xee, 0xa5, 0x21, 0x5e, 0xe3, 0x1d, 0x86, 0xd9, 0xe6, 0xa2,
+	0x3c, 0xd4, 0x24, 0x1d, 0x91, 0x2e, 0x6c, 0x85, 0x19, 0x21, 0x5f, 0x68, 0xdb, 0x56, 0x01, 0x0d,
+	0xc0, 0x1c, 0x49, 0xc7, 0xc7, 0x5f, 0x12, 0xdf, 0x61, 0xc1, 0xdf, 0x8e, 0x0c, 0x38, 0x2b, 0xae,
+	0xf5, 0x0c, 0x20, 0x5b, 0x01, 0x4f, 0x64, 0x90, 0x46, 0xba, 0x5b, 0x0d, 0x21, 0x5f, 0x02, 0x54,
+	0x62, 0xcb, 0xdb, 0x1a, 0x83, 0x3d, 0x2b, 0x12, 0xb1, 0x35, 0x1c, 0x7d, 0x78, 0x9b, 0xa3, 0x76,
+	0x3b, 0x12, 0xb1, 0xfa, 0x49, 0x6d, 0x30, 0xf5, 0xde, 0x86, 0x18, 0x4d, 0x30, 0x11, 0xd3, 0x20,
+	0x2e, 0x46, 0x1d, 0xc1, 0x76, 0xd6, 0xaa, 0x54, 0xc2, 0x56, 0x24, 0xe2, 0x2b, 0x6f, 0x5e, 0x8e,
+	0x1b, 0x0b, 0x72, 0x1c, 0xc0, 0xfe, 0x87, 0x84, 0xc7, 0x5c, 0x38, 0xe5, 0xc7, 0x78, 0x0e, 0x46,
+	0xac, 0xa1, 0xaa, 0x1f, 0x14, 0xd0, 0x95, 0x47, 0x19, 0x90, 0xa2, 0x66, 0x14, 0xf8, 0xcc, 0x91,
+	0x69, 0x82, 0x62, 0x6d, 0x19, 0xf9, 0x16, 0x40, 0x94, 0xf4, 0xfc, 0x28, 0xc6, 0xe0, 0xd3, 0xe2,
+	0x13, 0xab, 0x2b, 0x96, 0xed, 0xec, 0x1a, 0x95, 0xfe, 0xb3, 0x01, 0x5d, 0x95, 0xbf, 0x89, 0x3d,
+	0x47, 0x62, 0x31, 0x7c, 0xfd, 0xc8, 0xfb, 0x6f, 0x64, 0x63, 0xfe, 0x8d, 0x54, 0x4b, 0x7b, 0x52,
+	0x5f, 0xda, 0xe7, 0xd0, 0xd1, 0x6a, 0x4c, 0xf3, 0x79, 0xb9, 0xf4, 0x77, 0xed, 0x5d, 0xb7, 0x76,
+	0x86, 0xb9, 0xdb, 0x6c, 0x3d, 0xfa, 0x36, 0x99, 0x8c, 0x12, 0x74, 0xbc, 0x99, 0xb9, 0x9d, 0x8b,
+	0x54, 0x05, 0x99, 0x78, 0x63, 0x64, 0x5e, 0xc0, 0x7c, 0x73, 0x27, 0x3f, 0x4b, 0x11, 0x92, 0x97,
+	0xd0, 0x42, 0x76, 0x87, 0x21, 0x8f, 0xd1, 0x6c, 0xe5, 0x12, 0x39, 0x28, 0xc6, 0x5c, 0x68, 0xdc,
+	0x2e, 0x19, 0xf4, 0x23, 0x1c, 0x2d, 0x5b, 0x95, 0x20, 0xaf, 0xa0, 0x5d, 0x2c, 0x46, 0xd9, 0x92,
+	0x31, 0x38, 0xb1, 0xb4, 0x47, 0x5a, 0xcb, 0x4a, 0xec, 0x8a, 0x3f, 0xf8, 0xb7, 0x05, 0x5b, 0xe7,
+	0x99, 0xb9, 0x92, 0xd7, 0xb0, 0x9b, 0xf9, 0x9c, 0xd6, 0xa1, 0x20, 0xc7, 0x96, 0x32, 0x47, 0xab,
+	0x30, 0x47, 0xeb, 0x22, 0x33, 0xc7, 0x5e, 0xb7, 0xea, 0x5d, 0xb9, 0x23, 0x6d, 0x90, 0x9f, 0xe1,
+	0xe0, 0x12, 0xe5, 0x9c, 0x93, 0xcd, 0x73, 0xef, 0x39, 0x63, 0xef, 0x78, 0x79, 0x9a, 0x36, 0xc8,
+	0x3b, 0x30, 0x6a, 0x66, 0x42, 0x9e, 0x96, 0xc4, 0x45, 0x8b, 0x79, 0xa0, 0xcb, 0x7b, 0x20, 0x23,
+	0x94, 0x0b, 0x6e, 0x52, 0xf2, 0xe7, 0x32, 0xbd, 0x95, 0x19, 0xda, 0x20, 0x3f, 0x40, 0x6b, 0x34,
+	0x4d, 0xa5, 0xc7, 0xff, 0x64, 0x2b, 0x57, 0xb3, 0x02, 0xa7, 0x0d, 0xf2, 0x1d, 0xc0, 0x25, 0xca,
+	0xc2, 0xaa, 0x56, 0xd5, 0x1f, 0x94, 0xf3, 0x35, 0x93, 0x36, 0xc8, 0x00, 0x60, 0x54, 0x55, 0x2e,
+	0x30, 0x96, 0xd6, 0xbc, 0x86, 0xbd, 0x4b, 0x94, 0x75, 0xe7, 0xab, 0x3e, 0x5a, 0x0d, 0xed, 0x2d,
+	0x45, 0x69, 0x83, 0x7c, 0x0f, 0x7b, 0xa3, 0xc7, 0xd4, 0x2f, 0x9b, 0xfd, 0x2b, 0x7c, 0xb2, 0xe0,
+	0x9a, 0xe4, 0xb3, 0x92, 0xb8, 0xca, 0x51, 0x7b, 0x0f, 0x4b, 0x96, 0x36, 0xc8, 0x18, 0x4c, 0x15,
+	0xe1, 0x82, 0x55, 0xd6, 0xfa, 0xaf, 0xb2, 0xd1, 0xde, 0xb3, 0x07, 0xfb, 0x67, 0x6a, 0xb9, 0x81,
+	0xa3, 0x73, 0xcf, 0x5b, 0xe2, 0x7f, 0x95, 0xfa, 0x16, 0x93, 0xeb, 0xcf, 0xfd, 0x13, 0x18, 0x97,
+	0x28, 0x0b, 0xa0, 0xa6, 0xbe, 0x39, 0x77, 0x5e, 0xdf, 0xe9, 0x0a, 0x3a, 0xd9, 0x5b, 0xab, 0x5e,
+	0xfe, 0x2a, 0x1d, 0xad, 0xbf, 0xeb, 0x05, 0xec, 0xbf, 0x0b, 0x84, 0xeb, 0x24, 0xde, 0x23, 0x0e,
+	0xb6, 0x52, 0xd6, 0x6f, 0x6e, 0xe0, 0x05, 0x4f, 0x7c, 0x6b, 0x3a, 0x8b, 0x31, 0x09, 0xd1, 0xf3,
+	0x31, 0xb1, 0x6e, 0x9d, 0x49, 0x12, 0xb8, 0x8a, 0x2a, 0x8a, 0x96, 0xbf, 0xbd, 0xf4, 0x03, 0x39,
+	0x4d, 0x27, 0x99, 0xbd, 0xf5, 0x6b, 0xec, 0xbe, 0x62, 0xab, 0xff, 0xbf, 0x44, 0x5f, 0xb3, 0x27,
+	0xdb, 0x79, 0xfc, 0xf5, 0xff, 0x03, 0x00, 0x71, 0x39, 0x00, 0xfe, 0xff, 0x09, 0x00, 0x00,
+}
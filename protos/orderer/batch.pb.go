@@ -0,0 +1,196 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/batch.proto
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// BroadcastBatchRequest carries several envelopes submitted at once, to the same or
+// different channels
+type BroadcastBatchRequest struct {
+	Envelopes []*common.Envelope `protobuf:"bytes,1,rep,name=envelopes" json:"envelopes,omitempty"`
+}
+
+func (m *BroadcastBatchRequest) Reset()                    { *m = BroadcastBatchRequest{} }
+func (m *BroadcastBatchRequest) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastBatchRequest) ProtoMessage()               {}
+func (*BroadcastBatchRequest) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
+
+func (m *BroadcastBatchRequest) GetEnvelopes() []*common.Envelope {
+	if m != nil {
+		return m.Envelopes
+	}
+	return nil
+}
+
+// BroadcastBatchResponse holds the responses to the envelopes of a batch, in
+// the order of the envelopes
+type BroadcastBatchResponse struct {
+	Responses []*BroadcastResponse `protobuf:"bytes,1,rep,name=responses" json:"responses,omitempty"`
+}
+
+func (m *BroadcastBatchResponse) Reset()                    { *m = BroadcastBatchResponse{} }
+func (m *BroadcastBatchResponse) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastBatchResponse) ProtoMessage()               {}
+func (*BroadcastBatchResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
+
+func (m *BroadcastBatchResponse) GetResponses() []*BroadcastResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*BroadcastBatchRequest)(nil), "orderer.BroadcastBatchRequest")
+	proto.RegisterType((*BroadcastBatchResponse)(nil), "orderer.BroadcastBatchResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for BatchBroadcast service
+
+type BatchBroadcastClient interface {
+	// BroadcastBatch receives a stream of batches of messages for ordering,
+	// answering each batch with the responses to its messages. The messages
+	// admitted are enqueued in the order of the batch, and once one cannot
+	// be enqueued, none of those after it are. Unlike Broadcast, a rejected
+	// message does not end the stream.
+	BroadcastBatch(ctx context.Context, opts ...grpc.CallOption) (BatchBroadcast_BroadcastBatchClient, error)
+}
+
+type batchBroadcastClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBatchBroadcastClient(cc *grpc.ClientConn) BatchBroadcastClient {
+	return &batchBroadcastClient{cc}
+}
+
+func (c *batchBroadcastClient) BroadcastBatch(ctx context.Context, opts ...grpc.CallOption) (BatchBroadcast_BroadcastBatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_BatchBroadcast_serviceDesc.Streams[0], c.cc, "/orderer.BatchBroadcast/BroadcastBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &batchBroadcastBroadcastBatchClient{stream}
+	return x, nil
+}
+
+type BatchBroadcast_BroadcastBatchClient interface {
+	Send(*BroadcastBatchRequest) error
+	Recv() (*BroadcastBatchResponse, error)
+	grpc.ClientStream
+}
+
+type batchBroadcastBroadcastBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *batchBroadcastBroadcastBatchClient) Send(m *BroadcastBatchRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *batchBroadcastBroadcastBatchClient) Recv() (*BroadcastBatchResponse, error) {
+	m := new(BroadcastBatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for BatchBroadcast service
+
+type BatchBroadcastServer interface {
+	// BroadcastBatch receives a stream of batches of messages for ordering,
+	// answering each batch with the responses to its messages. The messages
+	// admitted are enqueued in the order of the batch, and once one cannot
+	// be enqueued, none of those after it are. Unlike Broadcast, a rejected
+	// message does not end the stream.
+	BroadcastBatch(BatchBroadcast_BroadcastBatchServer) error
+}
+
+func RegisterBatchBroadcastServer(s *grpc.Server, srv BatchBroadcastServer) {
+	s.RegisterService(&_BatchBroadcast_serviceDesc, srv)
+}
+
+func _BatchBroadcast_BroadcastBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BatchBroadcastServer).BroadcastBatch(&batchBroadcastBroadcastBatchServer{stream})
+}
+
+type BatchBroadcast_BroadcastBatchServer interface {
+	Send(*BroadcastBatchResponse) error
+	Recv() (*BroadcastBatchRequest, error)
+	grpc.ServerStream
+}
+
+type batchBroadcastBroadcastBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *batchBroadcastBroadcastBatchServer) Send(m *BroadcastBatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *batchBroadcastBroadcastBatchServer) Recv() (*BroadcastBatchRequest, error) {
+	m := new(BroadcastBatchRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _BatchBroadcast_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.BatchBroadcast",
+	HandlerType: (*BatchBroadcastServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BroadcastBatch",
+			Handler:       _BatchBroadcast_BroadcastBatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "orderer/batch.proto",
+}
+
+func init() { proto.RegisterFile("orderer/batch.proto", fileDescriptor2) }
+
+var fileDescriptor2 = []byte{
+	// 241 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x90, 0xd1, 0x4b, 0xc3, 0x30,
+	0x10, 0x87, 0x2d, 0x82, 0xb2, 0x13, 0xc6, 0xc8, 0x50, 0xa4, 0x0f, 0x2a, 0x05, 0x61, 0x0f, 0x92,
+	0xc8, 0x7c, 0xf1, 0xb9, 0x20, 0xbe, 0x17, 0x44, 0xf0, 0x2d, 0x69, 0xcf, 0xb6, 0xb0, 0xf5, 0xe2,
+	0x25, 0x13, 0xfc, 0xef, 0x65, 0x4b, 0xb2, 0x55, 0xc5, 0xa7, 0x36, 0xf7, 0xfb, 0xee, 0xbb, 0xe4,
+	0x60, 0x4e, 0xdc, 0x20, 0x23, 0x2b, 0xa3, 0x7d, 0xdd, 0x49, 0xcb, 0xe4, 0x49, 0x9c, 0xc6, 0x62,
+	0x3e, 0xaf, 0x69, 0xbd, 0xa6, 0x41, 0x85, 0x4f, 0x48, 0xf3, 0x59, 0x6a, 0xd1, 0x26, 0x54, 0x8a,
+	0x67, 0x38, 0x2f, 0x99, 0x74, 0x53, 0x6b, 0xe7, 0xcb, 0xad, 0xa7, 0xc2, 0x8f, 0x0d, 0x3a, 0x2f,
+	0x24, 0x4c, 0x70, 0xf8, 0xc4, 0x15, 0x59, 0x74, 0x97, 0xd9, 0xcd, 0xf1, 0xe2, 0x6c, 0x39, 0x93,
+	0x51, 0xf6, 0x14, 0x83, 0xea, 0x80, 0x14, 0x15, 0x5c, 0xfc, 0x16, 0x39, 0x4b, 0x83, 0x43, 0xf1,
+	0x08, 0x13, 0x8e, 0xff, 0xc9, 0x94, 0xcb, 0x78, 0x11, 0xb9, 0xef, 0x49, 0x78, 0x75, 0x80, 0x97,
+	0x3d, 0x4c, 0x77, 0xaa, 0x3d, 0x24, 0x5e, 0x61, 0xfa, 0x73, 0x8a, 0xb8, 0xfa, 0xab, 0x1a, 0xbf,
+	0x23, 0xbf, 0xfe, 0x37, 0x0f, 0x53, 0x8a, 0xa3, 0x45, 0x76, 0x9f, 0x95, 0x2f, 0x70, 0x4b, 0xdc,
+	0xca, 0xee, 0xcb, 0x22, 0xaf, 0xb0, 0x69, 0x91, 0xe5, 0xbb, 0x36, 0xdc, 0xd7, 0x61, 0x4f, 0x2e,
+	0x59, 0xde, 0xee, 0xda, 0xde, 0x77, 0x1b, 0xb3, 0x5d, 0x85, 0x1a, 0xd1, 0x2a, 0xd0, 0x2a, 0xd0,
+	0x2a, 0xd2, 0xe6, 0x64, 0x77, 0x7e, 0xf8, 0x1e, 0x00, 0x44, 0x5c, 0x83, 0xa3, 0xac, 0x01, 0x00,
+	0x00,
+}
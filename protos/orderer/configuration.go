@@ -109,6 +109,12 @@ func (docv *DynamicOrdererConfigValue) VariablyOpaqueFieldProto(name string) (pr
 		return &KafkaBrokers{}, nil
 	case "ChannelRestrictions":
 		return &ChannelRestrictions{}, nil
+	case "BroadcastQueue":
+		return &BroadcastQueue{}, nil
+	case "BroadcastQuota":
+		return &BroadcastQuota{}, nil
+	case "ReplayWindow":
+		return &ReplayWindow{}, nil
 	default:
 		return nil, fmt.Errorf("unknown Orderer ConfigValue name: %s", docv.name)
 	}
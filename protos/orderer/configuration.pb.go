@@ -19,7 +19,7 @@ type ConsensusType struct {
 func (m *ConsensusType) Reset()                    { *m = ConsensusType{} }
 func (m *ConsensusType) String() string            { return proto.CompactTextString(m) }
 func (*ConsensusType) ProtoMessage()               {}
-func (*ConsensusType) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+func (*ConsensusType) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
 
 func (m *ConsensusType) GetType() string {
 	if m != nil {
@@ -43,7 +43,7 @@ type BatchSize struct {
 func (m *BatchSize) Reset()                    { *m = BatchSize{} }
 func (m *BatchSize) String() string            { return proto.CompactTextString(m) }
 func (*BatchSize) ProtoMessage()               {}
-func (*BatchSize) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+func (*BatchSize) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
 
 func (m *BatchSize) GetMaxMessageCount() uint32 {
 	if m != nil {
@@ -75,7 +75,7 @@ type BatchTimeout struct {
 func (m *BatchTimeout) Reset()                    { *m = BatchTimeout{} }
 func (m *BatchTimeout) String() string            { return proto.CompactTextString(m) }
 func (*BatchTimeout) ProtoMessage()               {}
-func (*BatchTimeout) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+func (*BatchTimeout) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
 
 func (m *BatchTimeout) GetTimeout() string {
 	if m != nil {
@@ -95,7 +95,7 @@ type KafkaBrokers struct {
 func (m *KafkaBrokers) Reset()                    { *m = KafkaBrokers{} }
 func (m *KafkaBrokers) String() string            { return proto.CompactTextString(m) }
 func (*KafkaBrokers) ProtoMessage()               {}
-func (*KafkaBrokers) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*KafkaBrokers) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
 
 func (m *KafkaBrokers) GetBrokers() []string {
 	if m != nil {
@@ -112,7 +112,7 @@ type ChannelRestrictions struct {
 func (m *ChannelRestrictions) Reset()                    { *m = ChannelRestrictions{} }
 func (m *ChannelRestrictions) String() string            { return proto.CompactTextString(m) }
 func (*ChannelRestrictions) ProtoMessage()               {}
-func (*ChannelRestrictions) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+func (*ChannelRestrictions) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
 
 func (m *ChannelRestrictions) GetMaxCount() uint64 {
 	if m != nil {
@@ -121,36 +121,121 @@ func (m *ChannelRestrictions) GetMaxCount() uint64 {
 	return 0
 }
 
+// BroadcastQueue is the message which conveys the limit on the broadcast messages of a channel waiting to be ordered
+type BroadcastQueue struct {
+	MaxDepth uint32 `protobuf:"varint,1,opt,name=max_depth,json=maxDepth" json:"max_depth,omitempty"`
+}
+
+func (m *BroadcastQueue) Reset()                    { *m = BroadcastQueue{} }
+func (m *BroadcastQueue) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastQueue) ProtoMessage()               {}
+func (*BroadcastQueue) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+
+func (m *BroadcastQueue) GetMaxDepth() uint32 {
+	if m != nil {
+		return m.MaxDepth
+	}
+	return 0
+}
+
+// BroadcastQuota is the message which conveys the limits on the broadcast messages of a channel submitted by each identity, across all its connections
+type BroadcastQuota struct {
+	MaxInFlight uint32  `protobuf:"varint,1,opt,name=max_in_flight,json=maxInFlight" json:"max_in_flight,omitempty"`
+	Rate        float64 `protobuf:"fixed64,2,opt,name=rate" json:"rate,omitempty"`
+	Burst       uint32  `protobuf:"varint,3,opt,name=burst" json:"burst,omitempty"`
+}
+
+func (m *BroadcastQuota) Reset()                    { *m = BroadcastQuota{} }
+func (m *BroadcastQuota) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastQuota) ProtoMessage()               {}
+func (*BroadcastQuota) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
+
+func (m *BroadcastQuota) GetMaxInFlight() uint32 {
+	if m != nil {
+		return m.MaxInFlight
+	}
+	return 0
+}
+
+func (m *BroadcastQuota) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *BroadcastQuota) GetBurst() uint32 {
+	if m != nil {
+		return m.Burst
+	}
+	return 0
+}
+
+// ReplayWindow is the message which conveys the extent of the recently ordered messages of a channel tracked to reject their replays
+type ReplayWindow struct {
+	Size uint32 `protobuf:"varint,1,opt,name=size" json:"size,omitempty"`
+	Ttl  string `protobuf:"bytes,2,opt,name=ttl" json:"ttl,omitempty"`
+}
+
+func (m *ReplayWindow) Reset()                    { *m = ReplayWindow{} }
+func (m *ReplayWindow) String() string            { return proto.CompactTextString(m) }
+func (*ReplayWindow) ProtoMessage()               {}
+func (*ReplayWindow) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7} }
+
+func (m *ReplayWindow) GetSize() uint32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *ReplayWindow) GetTtl() string {
+	if m != nil {
+		return m.Ttl
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ConsensusType)(nil), "orderer.ConsensusType")
 	proto.RegisterType((*BatchSize)(nil), "orderer.BatchSize")
 	proto.RegisterType((*BatchTimeout)(nil), "orderer.BatchTimeout")
 	proto.RegisterType((*KafkaBrokers)(nil), "orderer.KafkaBrokers")
 	proto.RegisterType((*ChannelRestrictions)(nil), "orderer.ChannelRestrictions")
+	proto.RegisterType((*BroadcastQueue)(nil), "orderer.BroadcastQueue")
+	proto.RegisterType((*BroadcastQuota)(nil), "orderer.BroadcastQuota")
+	proto.RegisterType((*ReplayWindow)(nil), "orderer.ReplayWindow")
 }
 
-func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor1) }
-
-var fileDescriptor1 = []byte{
-	// 313 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x4c, 0xd0, 0xcd, 0x4a, 0xc3, 0x40,
-	0x10, 0x07, 0x70, 0x62, 0x8b, 0xb5, 0x8b, 0x45, 0xbb, 0xbd, 0x04, 0x7a, 0x29, 0x11, 0xa1, 0x48,
-	0x49, 0x40, 0xdf, 0x20, 0x3d, 0x4a, 0x2f, 0xb1, 0x5e, 0xbc, 0x94, 0x4d, 0x3a, 0x49, 0x96, 0x36,
-	0x3b, 0x61, 0x76, 0x03, 0x89, 0xef, 0xe1, 0xfb, 0xca, 0x6e, 0x52, 0xed, 0x6d, 0x3e, 0x7e, 0x0b,
-	0xb3, 0x7f, 0xb6, 0x44, 0x3a, 0x02, 0x01, 0x45, 0x19, 0xaa, 0x5c, 0x16, 0x0d, 0x09, 0x23, 0x51,
-	0x85, 0x35, 0xa1, 0x41, 0x3e, 0x19, 0x96, 0xc1, 0x13, 0x9b, 0x6d, 0x51, 0x69, 0x50, 0xba, 0xd1,
-	0xfb, 0xae, 0x06, 0xce, 0xd9, 0xd8, 0x74, 0x35, 0xf8, 0xde, 0xca, 0x5b, 0x4f, 0x13, 0x57, 0x07,
-	0x3f, 0x1e, 0x9b, 0xc6, 0xc2, 0x64, 0xe5, 0x87, 0xfc, 0x06, 0xfe, 0xc2, 0xe6, 0x95, 0x68, 0x0f,
-	0x15, 0x68, 0x2d, 0x0a, 0x38, 0x64, 0xd8, 0x28, 0xe3, 0xf8, 0x2c, 0x79, 0xa8, 0x44, 0xbb, 0xeb,
-	0xe7, 0x5b, 0x3b, 0xe6, 0x1b, 0xc6, 0x45, 0xaa, 0xf1, 0xdc, 0x18, 0x38, 0xd8, 0x47, 0x69, 0x67,
-	0x40, 0xfb, 0x37, 0x0e, 0x3f, 0x5e, 0x36, 0x3b, 0xd1, 0xc6, 0x76, 0xce, 0x43, 0xb6, 0xa8, 0x09,
-	0x72, 0x20, 0x82, 0xe3, 0x15, 0x1f, 0x39, 0x3e, 0xff, 0x5b, 0x5d, 0x7c, 0xb0, 0x66, 0xf7, 0xee,
-	0xac, 0xbd, 0xac, 0x00, 0x1b, 0xc3, 0x7d, 0x36, 0x31, 0x7d, 0x39, 0x9c, 0x7f, 0x69, 0xad, 0x7c,
-	0x17, 0xf9, 0x49, 0xc4, 0x84, 0x27, 0x20, 0x6d, 0x65, 0xda, 0x97, 0xbe, 0xb7, 0x1a, 0x59, 0x39,
-	0xb4, 0xc1, 0x2b, 0x5b, 0x6c, 0x4b, 0xa1, 0x14, 0x9c, 0x13, 0xd0, 0x86, 0x64, 0x66, 0x53, 0xd3,
-	0x7c, 0xc9, 0xa6, 0xf6, 0xa0, 0xff, 0xcf, 0x8e, 0x93, 0xbb, 0x4a, 0xb4, 0xee, 0x97, 0xf1, 0x27,
-	0x7b, 0x46, 0x2a, 0xc2, 0xb2, 0xab, 0x81, 0xce, 0x70, 0x2c, 0x80, 0xc2, 0x5c, 0xa4, 0x24, 0xb3,
-	0x3e, 0x6d, 0x1d, 0x0e, 0x69, 0x7f, 0x6d, 0x0a, 0x69, 0xca, 0x26, 0x0d, 0x33, 0xac, 0xa2, 0x2b,
-	0x1d, 0xf5, 0x3a, 0xea, 0x75, 0x34, 0xe8, 0xf4, 0xd6, 0xf5, 0x6f, 0xbf, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x0c, 0x47, 0xa2, 0x55, 0xca, 0x01, 0x00, 0x00,
+func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor3) }
+
+var fileDescriptor3 = []byte{
+	// 417 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x92, 0x51, 0x6b, 0xd4, 0x40,
+	0x10, 0xc7, 0x89, 0x57, 0xad, 0xb7, 0xf6, 0xb4, 0xdd, 0xfa, 0x70, 0xd0, 0x97, 0x12, 0x11, 0x8a,
+	0xd4, 0x04, 0xd4, 0x4f, 0x90, 0x13, 0x41, 0xa4, 0x0f, 0xc6, 0x8a, 0xe0, 0x83, 0xc7, 0x24, 0x99,
+	0x24, 0x4b, 0x93, 0xdd, 0x30, 0x3b, 0xc1, 0xa4, 0xdf, 0xc3, 0xef, 0x2b, 0xbb, 0x49, 0xf4, 0xfa,
+	0xf6, 0xff, 0xcf, 0xfc, 0x76, 0x99, 0xfd, 0xef, 0x88, 0x0b, 0x43, 0x05, 0x12, 0x52, 0x9c, 0x1b,
+	0x5d, 0xaa, 0xaa, 0x27, 0x60, 0x65, 0x74, 0xd4, 0x91, 0x61, 0x23, 0x8f, 0xe7, 0x66, 0xf8, 0x4a,
+	0x6c, 0x76, 0x46, 0x5b, 0xd4, 0xb6, 0xb7, 0xb7, 0x63, 0x87, 0x52, 0x8a, 0x23, 0x1e, 0x3b, 0xdc,
+	0x06, 0x97, 0xc1, 0xd5, 0x3a, 0xf5, 0x3a, 0xfc, 0x13, 0x88, 0x75, 0x02, 0x9c, 0xd7, 0xdf, 0xd4,
+	0x3d, 0xca, 0x37, 0xe2, 0xac, 0x85, 0x61, 0xdf, 0xa2, 0xb5, 0x50, 0xe1, 0x3e, 0x37, 0xbd, 0x66,
+	0x8f, 0x6f, 0xd2, 0x17, 0x2d, 0x0c, 0x37, 0x53, 0x7d, 0xe7, 0xca, 0xf2, 0x5a, 0x48, 0xc8, 0xac,
+	0x69, 0x7a, 0xc6, 0xbd, 0x3b, 0x94, 0x8d, 0x8c, 0x76, 0xfb, 0xc8, 0xc3, 0xa7, 0x4b, 0xe7, 0x06,
+	0x86, 0xc4, 0xd5, 0x65, 0x24, 0xce, 0x3b, 0xc2, 0x12, 0x89, 0xb0, 0x38, 0xc0, 0x57, 0x1e, 0x3f,
+	0xfb, 0xd7, 0x5a, 0xf8, 0xf0, 0x4a, 0x9c, 0xf8, 0xb1, 0x6e, 0x55, 0x8b, 0xa6, 0x67, 0xb9, 0x15,
+	0xc7, 0x3c, 0xc9, 0x79, 0xfc, 0xc5, 0x3a, 0xf2, 0x0b, 0x94, 0x77, 0x90, 0x90, 0xb9, 0x43, 0xb2,
+	0x8e, 0xcc, 0x26, 0xb9, 0x0d, 0x2e, 0x57, 0x8e, 0x9c, 0x6d, 0xf8, 0x4e, 0x9c, 0xef, 0x6a, 0xd0,
+	0x1a, 0x9b, 0x14, 0x2d, 0x93, 0xca, 0x5d, 0x6a, 0x56, 0x5e, 0x88, 0xb5, 0x1b, 0xe8, 0xff, 0x63,
+	0x8f, 0xd2, 0xa7, 0x2d, 0x0c, 0xfe, 0x95, 0xe1, 0x5b, 0xf1, 0x3c, 0x21, 0x03, 0x45, 0x0e, 0x96,
+	0xbf, 0xf6, 0xd8, 0xe3, 0x82, 0x17, 0xd8, 0x71, 0x3d, 0x67, 0xe3, 0xf0, 0x8f, 0xce, 0x87, 0xbf,
+	0x1e, 0xe0, 0x86, 0x41, 0x86, 0x62, 0xe3, 0x70, 0xa5, 0xf7, 0x65, 0xa3, 0xaa, 0x7a, 0x89, 0xf3,
+	0x59, 0x0b, 0xc3, 0x67, 0xfd, 0xc9, 0x97, 0xdc, 0xc7, 0x10, 0x30, 0xfa, 0xf0, 0x82, 0xd4, 0x6b,
+	0xf9, 0x52, 0x3c, 0xce, 0x7a, 0xb2, 0x3c, 0x47, 0x34, 0x99, 0xf0, 0x83, 0x38, 0x49, 0xb1, 0x6b,
+	0x60, 0xfc, 0xa1, 0x74, 0x61, 0x7e, 0xbb, 0x93, 0x56, 0xdd, 0xe3, 0x7c, 0xa9, 0xd7, 0xf2, 0x54,
+	0xac, 0x98, 0x1b, 0x7f, 0xd9, 0x3a, 0x75, 0x32, 0xf9, 0x2e, 0x5e, 0x1b, 0xaa, 0xa2, 0x7a, 0xec,
+	0x90, 0x1a, 0x2c, 0x2a, 0xa4, 0xa8, 0x84, 0x8c, 0x54, 0x3e, 0xad, 0x8c, 0x8d, 0xe6, 0x95, 0xf9,
+	0x79, 0x5d, 0x29, 0xae, 0xfb, 0x2c, 0xca, 0x4d, 0x1b, 0x1f, 0xd0, 0xf1, 0x44, 0xc7, 0x13, 0x1d,
+	0xcf, 0x74, 0xf6, 0xc4, 0xfb, 0xf7, 0x7f, 0x07, 0x00, 0x12, 0xc7, 0xd5, 0x7e, 0x8f, 0x02, 0x00,
+	0x00,
 }
@@ -0,0 +1,149 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/cosign.proto
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// CosignRequest asks an orderer to sign the header of a block of a channel
+type CosignRequest struct {
+	ChannelId string              `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Header    *common.BlockHeader `protobuf:"bytes,2,opt,name=header" json:"header,omitempty"`
+}
+
+func (m *CosignRequest) Reset()                    { *m = CosignRequest{} }
+func (m *CosignRequest) String() string            { return proto.CompactTextString(m) }
+func (*CosignRequest) ProtoMessage()               {}
+func (*CosignRequest) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{0} }
+
+func (m *CosignRequest) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *CosignRequest) GetHeader() *common.BlockHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*CosignRequest)(nil), "orderer.CosignRequest")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for BlockCosigner service
+
+type BlockCosignerClient interface {
+	// Cosign returns the signature of the orderer on the header, to be added
+	// to the SIGNATURES metadata of the block, once the orderer has produced
+	// the same header itself.  Headers which differ from the one it produced
+	// are refused with FAILED_PRECONDITION.
+	Cosign(ctx context.Context, in *CosignRequest, opts ...grpc.CallOption) (*common.MetadataSignature, error)
+}
+
+type blockCosignerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBlockCosignerClient(cc *grpc.ClientConn) BlockCosignerClient {
+	return &blockCosignerClient{cc}
+}
+
+func (c *blockCosignerClient) Cosign(ctx context.Context, in *CosignRequest, opts ...grpc.CallOption) (*common.MetadataSignature, error) {
+	out := new(common.MetadataSignature)
+	err := grpc.Invoke(ctx, "/orderer.BlockCosigner/Cosign", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for BlockCosigner service
+
+type BlockCosignerServer interface {
+	// Cosign returns the signature of the orderer on the header, to be added
+	// to the SIGNATURES metadata of the block, once the orderer has produced
+	// the same header itself.  Headers which differ from the one it produced
+	// are refused with FAILED_PRECONDITION.
+	Cosign(context.Context, *CosignRequest) (*common.MetadataSignature, error)
+}
+
+func RegisterBlockCosignerServer(s *grpc.Server, srv BlockCosignerServer) {
+	s.RegisterService(&_BlockCosigner_serviceDesc, srv)
+}
+
+func _BlockCosigner_Cosign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CosignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockCosignerServer).Cosign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.BlockCosigner/Cosign",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockCosignerServer).Cosign(ctx, req.(*CosignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _BlockCosigner_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.BlockCosigner",
+	HandlerType: (*BlockCosignerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Cosign",
+			Handler:    _BlockCosigner_Cosign_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "orderer/cosign.proto",
+}
+
+func init() { proto.RegisterFile("orderer/cosign.proto", fileDescriptor4) }
+
+var fileDescriptor4 = []byte{
+	// 232 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x8f, 0xb1, 0x4b, 0x03, 0x31,
+	0x14, 0xc6, 0x3d, 0x87, 0x93, 0x3e, 0xe9, 0x92, 0x8a, 0xd4, 0x82, 0x50, 0x0a, 0x42, 0x41, 0x49,
+	0xa0, 0xce, 0x2e, 0x75, 0xd1, 0x41, 0x87, 0x13, 0x17, 0x1d, 0x24, 0x97, 0x3c, 0x73, 0xc1, 0x6b,
+	0x5e, 0x7d, 0x97, 0x1b, 0xfc, 0xef, 0xc5, 0x4b, 0x0e, 0xec, 0x94, 0xe4, 0x7b, 0x3f, 0x7e, 0x5f,
+	0x1e, 0x9c, 0x11, 0x5b, 0x64, 0x64, 0x65, 0xa8, 0xf3, 0x2e, 0xc8, 0x3d, 0x53, 0x24, 0x71, 0x92,
+	0xd3, 0xc5, 0xcc, 0xd0, 0x6e, 0x47, 0x41, 0xa5, 0x23, 0x4d, 0x57, 0xef, 0x30, 0xbd, 0x1f, 0xe8,
+	0x0a, 0xbf, 0x7b, 0xec, 0xa2, 0xb8, 0x04, 0x30, 0x8d, 0x0e, 0x01, 0xdb, 0x0f, 0x6f, 0xe7, 0xc5,
+	0xb2, 0x58, 0x4f, 0xaa, 0x49, 0x4e, 0x1e, 0xad, 0xb8, 0x86, 0xb2, 0x41, 0x6d, 0x91, 0xe7, 0xc7,
+	0xcb, 0x62, 0x7d, 0xba, 0x99, 0xc9, 0xac, 0xdb, 0xb6, 0x64, 0xbe, 0x1e, 0x86, 0x51, 0x95, 0x91,
+	0xcd, 0x33, 0x4c, 0x87, 0x38, 0x35, 0x20, 0x8b, 0x3b, 0x28, 0xd3, 0x5d, 0x9c, 0xcb, 0xfc, 0x2d,
+	0x79, 0x50, 0xbf, 0xb8, 0x18, 0x7d, 0x4f, 0x18, 0xb5, 0xd5, 0x51, 0xbf, 0x78, 0x17, 0x74, 0xec,
+	0x19, 0x57, 0x47, 0xdb, 0x57, 0xb8, 0x22, 0x76, 0xb2, 0xf9, 0xd9, 0x23, 0xb7, 0x68, 0x1d, 0xb2,
+	0xfc, 0xd4, 0x35, 0x7b, 0x93, 0x96, 0xe9, 0x46, 0xe7, 0xdb, 0x8d, 0xf3, 0xb1, 0xe9, 0xeb, 0x3f,
+	0x97, 0xfa, 0x47, 0xab, 0x44, 0xab, 0x44, 0xab, 0x4c, 0xd7, 0xe5, 0xf0, 0xbe, 0xfd, 0x1d, 0x00,
+	0xdc, 0x86, 0x5c, 0x88, 0x40, 0x01, 0x00, 0x00,
+}
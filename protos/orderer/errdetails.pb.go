@@ -0,0 +1,111 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/errdetails.proto
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// ErrorInfo describes why a request was rejected
+type ErrorInfo struct {
+	Status    common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	Reason    string        `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+	ChannelId string        `protobuf:"bytes,3,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+}
+
+func (m *ErrorInfo) Reset()                    { *m = ErrorInfo{} }
+func (m *ErrorInfo) String() string            { return proto.CompactTextString(m) }
+func (*ErrorInfo) ProtoMessage()               {}
+func (*ErrorInfo) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{0} }
+
+func (m *ErrorInfo) GetStatus() common.Status {
+	if m != nil {
+		return m.Status
+	}
+	return common.Status_UNKNOWN
+}
+
+func (m *ErrorInfo) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ErrorInfo) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+// RetryInfo tells the client when it may retry a request which failed
+// transiently
+type RetryInfo struct {
+	RetryDelayMs int64 `protobuf:"varint,1,opt,name=retry_delay_ms,json=retryDelayMs" json:"retry_delay_ms,omitempty"`
+}
+
+func (m *RetryInfo) Reset()                    { *m = RetryInfo{} }
+func (m *RetryInfo) String() string            { return proto.CompactTextString(m) }
+func (*RetryInfo) ProtoMessage()               {}
+func (*RetryInfo) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{1} }
+
+func (m *RetryInfo) GetRetryDelayMs() int64 {
+	if m != nil {
+		return m.RetryDelayMs
+	}
+	return 0
+}
+
+// RedirectInfo names the orderers which the client should send the request
+// to instead
+type RedirectInfo struct {
+	Endpoints []string `protobuf:"bytes,1,rep,name=endpoints" json:"endpoints,omitempty"`
+}
+
+func (m *RedirectInfo) Reset()                    { *m = RedirectInfo{} }
+func (m *RedirectInfo) String() string            { return proto.CompactTextString(m) }
+func (*RedirectInfo) ProtoMessage()               {}
+func (*RedirectInfo) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{2} }
+
+func (m *RedirectInfo) GetEndpoints() []string {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ErrorInfo)(nil), "orderer.ErrorInfo")
+	proto.RegisterType((*RetryInfo)(nil), "orderer.RetryInfo")
+	proto.RegisterType((*RedirectInfo)(nil), "orderer.RedirectInfo")
+}
+
+func init() { proto.RegisterFile("orderer/errdetails.proto", fileDescriptor5) }
+
+var fileDescriptor5 = []byte{
+	// 255 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0x4d, 0x4b, 0xc4, 0x30,
+	0x10, 0x86, 0x59, 0x0b, 0x95, 0x0e, 0x4b, 0x0f, 0x15, 0xa4, 0x88, 0xc2, 0x52, 0x54, 0xf6, 0xb0,
+	0x34, 0xa8, 0xff, 0x40, 0xf4, 0xb0, 0x07, 0x2f, 0x15, 0x2f, 0x5e, 0x4a, 0xda, 0xcc, 0xb6, 0x91,
+	0x36, 0x29, 0x93, 0xec, 0xa1, 0xff, 0x5e, 0xf2, 0x81, 0x78, 0x0a, 0xf3, 0xbc, 0xcf, 0xf0, 0x32,
+	0x81, 0x52, 0x93, 0x40, 0x42, 0x62, 0x48, 0x24, 0xd0, 0x72, 0x39, 0x99, 0x7a, 0x21, 0x6d, 0x75,
+	0x71, 0x19, 0x93, 0x9b, 0xab, 0x5e, 0xcf, 0xb3, 0x56, 0x2c, 0x3c, 0x21, 0xad, 0x7e, 0x20, 0x7b,
+	0x27, 0xd2, 0x74, 0x54, 0x27, 0x5d, 0x3c, 0x42, 0x6a, 0x2c, 0xb7, 0x67, 0x53, 0x6e, 0x76, 0x9b,
+	0x7d, 0xfe, 0x9c, 0xd7, 0xd1, 0xfd, 0xf4, 0xb4, 0x89, 0x69, 0x71, 0x0d, 0x29, 0x21, 0x37, 0x5a,
+	0x95, 0x17, 0xbb, 0xcd, 0x3e, 0x6b, 0xe2, 0x54, 0xdc, 0x01, 0xf4, 0x23, 0x57, 0x0a, 0xa7, 0x56,
+	0x8a, 0x32, 0xf1, 0x59, 0x16, 0xc9, 0x51, 0x54, 0x4f, 0x90, 0x35, 0x68, 0x69, 0xf5, 0x5d, 0xf7,
+	0x90, 0x93, 0x1b, 0x5a, 0x81, 0x13, 0x5f, 0xdb, 0x39, 0x74, 0x26, 0xcd, 0xd6, 0xd3, 0x37, 0x07,
+	0x3f, 0x4c, 0x75, 0x80, 0x6d, 0x83, 0x42, 0x12, 0xf6, 0xd6, 0x6f, 0xdd, 0x42, 0x86, 0x4a, 0x2c,
+	0x5a, 0x2a, 0xeb, 0x16, 0x12, 0x57, 0xf0, 0x07, 0x5e, 0xbf, 0xe0, 0x41, 0xd3, 0x50, 0x8f, 0xeb,
+	0x82, 0x34, 0xa1, 0x18, 0x90, 0xea, 0x13, 0xef, 0x48, 0xf6, 0xe1, 0x58, 0x53, 0xc7, 0xaf, 0xf8,
+	0x3e, 0x0c, 0xd2, 0x8e, 0xe7, 0xce, 0x9d, 0xc7, 0xfe, 0xd9, 0x2c, 0xd8, 0x2c, 0xd8, 0x2c, 0xda,
+	0x5d, 0xea, 0xe7, 0x97, 0xdf, 0x01, 0x00, 0x3c, 0xbe, 0xa3, 0xd9, 0x64, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,263 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/jointoken.proto
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// JoinTokenRequest requests a token granting the config block of a channel
+type JoinTokenRequest struct {
+	Expires *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=expires" json:"expires,omitempty"`
+}
+
+func (m *JoinTokenRequest) Reset()                    { *m = JoinTokenRequest{} }
+func (m *JoinTokenRequest) String() string            { return proto.CompactTextString(m) }
+func (*JoinTokenRequest) ProtoMessage()               {}
+func (*JoinTokenRequest) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{0} }
+
+func (m *JoinTokenRequest) GetExpires() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Expires
+	}
+	return nil
+}
+
+// JoinTokenContent is what a join token grants
+type JoinTokenContent struct {
+	ChannelId string                     `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Expires   *google_protobuf.Timestamp `protobuf:"bytes,2,opt,name=expires" json:"expires,omitempty"`
+	Nonce     []byte                     `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	IssuedTo  []byte                     `protobuf:"bytes,4,opt,name=issued_to,json=issuedTo,proto3" json:"issued_to,omitempty"`
+}
+
+func (m *JoinTokenContent) Reset()                    { *m = JoinTokenContent{} }
+func (m *JoinTokenContent) String() string            { return proto.CompactTextString(m) }
+func (*JoinTokenContent) ProtoMessage()               {}
+func (*JoinTokenContent) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{1} }
+
+func (m *JoinTokenContent) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *JoinTokenContent) GetExpires() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Expires
+	}
+	return nil
+}
+
+func (m *JoinTokenContent) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+func (m *JoinTokenContent) GetIssuedTo() []byte {
+	if m != nil {
+		return m.IssuedTo
+	}
+	return nil
+}
+
+// JoinToken grants its bearer the config block of a channel until it
+// expires.  It is signed by an orderer of the channel, and is accepted by any.
+type JoinToken struct {
+	Content         []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	SignatureHeader []byte `protobuf:"bytes,2,opt,name=signature_header,json=signatureHeader,proto3" json:"signature_header,omitempty"`
+	Signature       []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *JoinToken) Reset()                    { *m = JoinToken{} }
+func (m *JoinToken) String() string            { return proto.CompactTextString(m) }
+func (*JoinToken) ProtoMessage()               {}
+func (*JoinToken) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{2} }
+
+func (m *JoinToken) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (m *JoinToken) GetSignatureHeader() []byte {
+	if m != nil {
+		return m.SignatureHeader
+	}
+	return nil
+}
+
+func (m *JoinToken) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*JoinTokenRequest)(nil), "orderer.JoinTokenRequest")
+	proto.RegisterType((*JoinTokenContent)(nil), "orderer.JoinTokenContent")
+	proto.RegisterType((*JoinToken)(nil), "orderer.JoinToken")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for ChannelJoin service
+
+type ChannelJoinClient interface {
+	// MintJoinToken requires an Envelope whose payload data is a marshaled
+	// JoinTokenRequest, signed by an admin of the application organizations
+	// or of the orderer organizations of the channel of its channel header
+	MintJoinToken(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*JoinToken, error)
+	// GetConfigBlock returns the latest config block of the channel of the
+	// token, from which its bearer may start serving or following it
+	GetConfigBlock(ctx context.Context, in *JoinToken, opts ...grpc.CallOption) (*common.Block, error)
+}
+
+type channelJoinClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewChannelJoinClient(cc *grpc.ClientConn) ChannelJoinClient {
+	return &channelJoinClient{cc}
+}
+
+func (c *channelJoinClient) MintJoinToken(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*JoinToken, error) {
+	out := new(JoinToken)
+	err := grpc.Invoke(ctx, "/orderer.ChannelJoin/MintJoinToken", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *channelJoinClient) GetConfigBlock(ctx context.Context, in *JoinToken, opts ...grpc.CallOption) (*common.Block, error) {
+	out := new(common.Block)
+	err := grpc.Invoke(ctx, "/orderer.ChannelJoin/GetConfigBlock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for ChannelJoin service
+
+type ChannelJoinServer interface {
+	// MintJoinToken requires an Envelope whose payload data is a marshaled
+	// JoinTokenRequest, signed by an admin of the application organizations
+	// or of the orderer organizations of the channel of its channel header
+	MintJoinToken(context.Context, *common.Envelope) (*JoinToken, error)
+	// GetConfigBlock returns the latest config block of the channel of the
+	// token, from which its bearer may start serving or following it
+	GetConfigBlock(context.Context, *JoinToken) (*common.Block, error)
+}
+
+func RegisterChannelJoinServer(s *grpc.Server, srv ChannelJoinServer) {
+	s.RegisterService(&_ChannelJoin_serviceDesc, srv)
+}
+
+func _ChannelJoin_MintJoinToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChannelJoinServer).MintJoinToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.ChannelJoin/MintJoinToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChannelJoinServer).MintJoinToken(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChannelJoin_GetConfigBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinToken)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChannelJoinServer).GetConfigBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.ChannelJoin/GetConfigBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChannelJoinServer).GetConfigBlock(ctx, req.(*JoinToken))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ChannelJoin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.ChannelJoin",
+	HandlerType: (*ChannelJoinServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MintJoinToken",
+			Handler:    _ChannelJoin_MintJoinToken_Handler,
+		},
+		{
+			MethodName: "GetConfigBlock",
+			Handler:    _ChannelJoin_GetConfigBlock_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "orderer/jointoken.proto",
+}
+
+func init() { proto.RegisterFile("orderer/jointoken.proto", fileDescriptor6) }
+
+var fileDescriptor6 = []byte{
+	// 381 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xcd, 0x6a, 0xdb, 0x40,
+	0x14, 0x85, 0xad, 0xfe, 0xb9, 0x1a, 0xdb, 0xad, 0x99, 0x16, 0x2a, 0xd4, 0x96, 0x1a, 0x41, 0xc1,
+	0x85, 0x32, 0x02, 0xb7, 0xa5, 0x7b, 0x9b, 0x52, 0xb7, 0x90, 0x8d, 0x70, 0x36, 0xd9, 0x18, 0xfd,
+	0x5c, 0x4b, 0x13, 0x4b, 0x73, 0x95, 0x99, 0x51, 0x48, 0x16, 0x79, 0x91, 0x3c, 0x6d, 0xf0, 0x8c,
+	0x24, 0x7b, 0x91, 0x45, 0x56, 0xe2, 0x9c, 0x7b, 0xf4, 0xdd, 0x23, 0xcd, 0x90, 0x0f, 0x28, 0x33,
+	0x90, 0x20, 0xc3, 0x4b, 0xe4, 0x42, 0xe3, 0x1e, 0x04, 0xab, 0x25, 0x6a, 0xa4, 0xc3, 0x76, 0xe0,
+	0xbf, 0x4b, 0xb1, 0xaa, 0x50, 0x84, 0xf6, 0x61, 0xa7, 0xfe, 0x97, 0x1c, 0x31, 0x2f, 0x21, 0x34,
+	0x2a, 0x69, 0x76, 0xa1, 0xe6, 0x15, 0x28, 0x1d, 0x57, 0xb5, 0x0d, 0x04, 0x6b, 0x32, 0xfd, 0x8f,
+	0x5c, 0x6c, 0x0e, 0xc4, 0x08, 0xae, 0x1a, 0x50, 0x9a, 0xfe, 0x24, 0x43, 0xb8, 0xa9, 0xb9, 0x04,
+	0xe5, 0x39, 0x33, 0x67, 0x3e, 0x5a, 0xf8, 0xcc, 0x62, 0x58, 0x87, 0x61, 0x9b, 0x0e, 0x13, 0x75,
+	0xd1, 0xe0, 0xde, 0x39, 0x41, 0xad, 0x50, 0x68, 0x10, 0x9a, 0x7e, 0x26, 0x24, 0x2d, 0x62, 0x21,
+	0xa0, 0xdc, 0xf2, 0xcc, 0xd0, 0xdc, 0xc8, 0x6d, 0x9d, 0x7f, 0xd9, 0xe9, 0xa6, 0x67, 0x4f, 0xde,
+	0x44, 0xdf, 0x93, 0x97, 0x02, 0x45, 0x0a, 0xde, 0xf3, 0x99, 0x33, 0x1f, 0x47, 0x56, 0xd0, 0x8f,
+	0xc4, 0xe5, 0x4a, 0x35, 0x90, 0x6d, 0x35, 0x7a, 0x2f, 0xcc, 0xe4, 0xb5, 0x35, 0x36, 0x18, 0x08,
+	0xe2, 0xf6, 0xdd, 0xa8, 0x47, 0x86, 0xa9, 0xed, 0x67, 0x1a, 0x8d, 0xa3, 0x4e, 0xd2, 0x6f, 0x64,
+	0xaa, 0x78, 0x2e, 0x62, 0xdd, 0x48, 0xd8, 0x16, 0x10, 0x67, 0x20, 0x4d, 0xb1, 0x71, 0xf4, 0xb6,
+	0xf7, 0xd7, 0xc6, 0xa6, 0x9f, 0x88, 0xdb, 0x5b, 0x6d, 0x91, 0xa3, 0xb1, 0xb8, 0x23, 0xa3, 0x95,
+	0xfd, 0xca, 0xc3, 0x5a, 0xfa, 0x9b, 0x4c, 0xce, 0xb8, 0xd0, 0xc7, 0x0a, 0x53, 0xd6, 0x1e, 0xd3,
+	0x1f, 0x71, 0x0d, 0x25, 0xd6, 0xe0, 0x53, 0xd6, 0x1e, 0x24, 0xeb, 0x53, 0xc1, 0x80, 0xfe, 0x22,
+	0x6f, 0xfe, 0x82, 0x5e, 0xa1, 0xd8, 0xf1, 0x7c, 0x59, 0x62, 0xba, 0xa7, 0x8f, 0xe4, 0xfc, 0x49,
+	0x47, 0x33, 0x91, 0x60, 0xb0, 0x3c, 0x27, 0x5f, 0x51, 0xe6, 0xac, 0xb8, 0xad, 0x41, 0x96, 0x90,
+	0xe5, 0x20, 0xd9, 0x2e, 0x4e, 0x24, 0x4f, 0xed, 0x7f, 0x55, 0x1d, 0xe3, 0xe2, 0x7b, 0xce, 0x75,
+	0xd1, 0x24, 0x87, 0xf7, 0xc3, 0x93, 0x74, 0x68, 0xd3, 0xf6, 0xda, 0xa8, 0xb0, 0x4d, 0x27, 0xaf,
+	0x8c, 0xfe, 0xf1, 0x30, 0x00, 0xb7, 0xa8, 0x12, 0x77, 0x8d, 0x02, 0x00, 0x00,
+}
@@ -25,7 +25,7 @@ type KafkaMessage struct {
 func (m *KafkaMessage) Reset()                    { *m = KafkaMessage{} }
 func (m *KafkaMessage) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessage) ProtoMessage()               {}
-func (*KafkaMessage) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
+func (*KafkaMessage) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{0} }
 
 type isKafkaMessage_Type interface {
 	isKafkaMessage_Type()
@@ -174,7 +174,7 @@ type KafkaMessageRegular struct {
 func (m *KafkaMessageRegular) Reset()                    { *m = KafkaMessageRegular{} }
 func (m *KafkaMessageRegular) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessageRegular) ProtoMessage()               {}
-func (*KafkaMessageRegular) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
+func (*KafkaMessageRegular) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{1} }
 
 func (m *KafkaMessageRegular) GetPayload() []byte {
 	if m != nil {
@@ -192,7 +192,7 @@ type KafkaMessageTimeToCut struct {
 func (m *KafkaMessageTimeToCut) Reset()                    { *m = KafkaMessageTimeToCut{} }
 func (m *KafkaMessageTimeToCut) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessageTimeToCut) ProtoMessage()               {}
-func (*KafkaMessageTimeToCut) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+func (*KafkaMessageTimeToCut) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{2} }
 
 func (m *KafkaMessageTimeToCut) GetBlockNumber() uint64 {
 	if m != nil {
@@ -207,12 +207,16 @@ func (m *KafkaMessageTimeToCut) GetBlockNumber() uint64 {
 // orderers when processing the partition.
 type KafkaMessageConnect struct {
 	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The orderer posting the message, and the newest wire version it
+	// supports, unset by orderers which predate wire version negotiation
+	NodeId         string `protobuf:"bytes,2,opt,name=node_id,json=nodeId" json:"node_id,omitempty"`
+	MaxWireVersion uint32 `protobuf:"varint,3,opt,name=max_wire_version,json=maxWireVersion" json:"max_wire_version,omitempty"`
 }
 
 func (m *KafkaMessageConnect) Reset()                    { *m = KafkaMessageConnect{} }
 func (m *KafkaMessageConnect) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessageConnect) ProtoMessage()               {}
-func (*KafkaMessageConnect) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+func (*KafkaMessageConnect) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{3} }
 
 func (m *KafkaMessageConnect) GetPayload() []byte {
 	if m != nil {
@@ -221,17 +225,35 @@ func (m *KafkaMessageConnect) GetPayload() []byte {
 	return nil
 }
 
+func (m *KafkaMessageConnect) GetNodeId() string {
+	if m != nil {
+		return m.NodeId
+	}
+	return ""
+}
+
+func (m *KafkaMessageConnect) GetMaxWireVersion() uint32 {
+	if m != nil {
+		return m.MaxWireVersion
+	}
+	return 0
+}
+
 // LastOffsetPersisted is the encoded value for the Metadata message
 // which is encoded in the ORDERER block metadata index for the case
 // of the Kafka-based orderer.
 type KafkaMetadata struct {
 	LastOffsetPersisted int64 `protobuf:"varint,1,opt,name=last_offset_persisted,json=lastOffsetPersisted" json:"last_offset_persisted,omitempty"`
+	// The wire version active as of the block, and the newest wire version
+	// advertised by each orderer of the consenter set, ordered by node ID
+	WireVersion            uint32              `protobuf:"varint,2,opt,name=wire_version,json=wireVersion" json:"wire_version,omitempty"`
+	AdvertisedWireVersions []*KafkaWireVersion `protobuf:"bytes,3,rep,name=advertised_wire_versions,json=advertisedWireVersions" json:"advertised_wire_versions,omitempty"`
 }
 
 func (m *KafkaMetadata) Reset()                    { *m = KafkaMetadata{} }
 func (m *KafkaMetadata) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMetadata) ProtoMessage()               {}
-func (*KafkaMetadata) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+func (*KafkaMetadata) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{4} }
 
 func (m *KafkaMetadata) GetLastOffsetPersisted() int64 {
 	if m != nil {
@@ -240,36 +262,82 @@ func (m *KafkaMetadata) GetLastOffsetPersisted() int64 {
 	return 0
 }
 
+func (m *KafkaMetadata) GetWireVersion() uint32 {
+	if m != nil {
+		return m.WireVersion
+	}
+	return 0
+}
+
+func (m *KafkaMetadata) GetAdvertisedWireVersions() []*KafkaWireVersion {
+	if m != nil {
+		return m.AdvertisedWireVersions
+	}
+	return nil
+}
+
+type KafkaWireVersion struct {
+	NodeId         string `protobuf:"bytes,1,opt,name=node_id,json=nodeId" json:"node_id,omitempty"`
+	MaxWireVersion uint32 `protobuf:"varint,2,opt,name=max_wire_version,json=maxWireVersion" json:"max_wire_version,omitempty"`
+}
+
+func (m *KafkaWireVersion) Reset()                    { *m = KafkaWireVersion{} }
+func (m *KafkaWireVersion) String() string            { return proto.CompactTextString(m) }
+func (*KafkaWireVersion) ProtoMessage()               {}
+func (*KafkaWireVersion) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{5} }
+
+func (m *KafkaWireVersion) GetNodeId() string {
+	if m != nil {
+		return m.NodeId
+	}
+	return ""
+}
+
+func (m *KafkaWireVersion) GetMaxWireVersion() uint32 {
+	if m != nil {
+		return m.MaxWireVersion
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*KafkaMessage)(nil), "orderer.KafkaMessage")
 	proto.RegisterType((*KafkaMessageRegular)(nil), "orderer.KafkaMessageRegular")
 	proto.RegisterType((*KafkaMessageTimeToCut)(nil), "orderer.KafkaMessageTimeToCut")
 	proto.RegisterType((*KafkaMessageConnect)(nil), "orderer.KafkaMessageConnect")
 	proto.RegisterType((*KafkaMetadata)(nil), "orderer.KafkaMetadata")
+	proto.RegisterType((*KafkaWireVersion)(nil), "orderer.KafkaWireVersion")
 }
 
-func init() { proto.RegisterFile("orderer/kafka.proto", fileDescriptor2) }
-
-var fileDescriptor2 = []byte{
-	// 316 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x91, 0x4f, 0x6b, 0xc2, 0x40,
-	0x10, 0xc5, 0xb5, 0x8a, 0xd2, 0xd1, 0x5e, 0x22, 0x42, 0x0e, 0xa5, 0xb4, 0x42, 0xa1, 0x87, 0x92,
-	0x80, 0xbd, 0x94, 0x9e, 0x8a, 0x5e, 0x84, 0xd2, 0x3f, 0x2c, 0xf6, 0xd2, 0x4b, 0xd8, 0x6c, 0x26,
-	0x31, 0x98, 0xb8, 0x61, 0x77, 0x72, 0xf0, 0x3b, 0xf6, 0x43, 0x95, 0xec, 0x6e, 0x40, 0x4a, 0xf0,
-	0x38, 0xf3, 0x7e, 0x8f, 0xf7, 0x76, 0x16, 0x66, 0x52, 0x25, 0xa8, 0x50, 0x85, 0x7b, 0x9e, 0xee,
-	0x79, 0x50, 0x29, 0x49, 0xd2, 0x1b, 0xbb, 0xe5, 0xe2, 0xb7, 0x0f, 0xd3, 0xb7, 0x46, 0x78, 0x47,
-	0xad, 0x79, 0x86, 0xde, 0x33, 0x8c, 0x15, 0x66, 0x75, 0xc1, 0x95, 0xdf, 0xbf, 0xed, 0x3f, 0x4c,
-	0x96, 0xd7, 0x81, 0x63, 0x83, 0x53, 0x8e, 0x59, 0x66, 0xd3, 0x63, 0x2d, 0xee, 0xbd, 0xc2, 0x84,
-	0xf2, 0x12, 0x23, 0x92, 0x91, 0xa8, 0xc9, 0xbf, 0x30, 0xee, 0x9b, 0x4e, 0xf7, 0x36, 0x2f, 0x71,
-	0x2b, 0xd7, 0x35, 0x6d, 0x7a, 0xec, 0x92, 0xda, 0xa1, 0xc9, 0x16, 0xf2, 0x70, 0x40, 0x41, 0xfe,
-	0xe0, 0x4c, 0xf6, 0xda, 0x32, 0x4d, 0xb6, 0xc3, 0x57, 0x23, 0x18, 0x6e, 0x8f, 0x15, 0x2e, 0x42,
-	0x98, 0x75, 0xb4, 0xf4, 0x7c, 0x18, 0x57, 0xfc, 0x58, 0x48, 0x9e, 0x98, 0x47, 0x4d, 0x59, 0x3b,
-	0x2e, 0x5e, 0x60, 0xde, 0x59, 0xcc, 0xbb, 0x83, 0x69, 0x5c, 0x48, 0xb1, 0x8f, 0x0e, 0x75, 0x19,
-	0xa3, 0x3d, 0xc6, 0x90, 0x4d, 0xcc, 0xee, 0xc3, 0xac, 0xfe, 0x87, 0xb9, 0x5a, 0x67, 0xc2, 0xd6,
-	0x70, 0xe5, 0x0c, 0xc4, 0x13, 0x4e, 0xdc, 0x5b, 0xc2, 0xbc, 0xe0, 0x9a, 0x22, 0x99, 0xa6, 0x1a,
-	0x29, 0xaa, 0x50, 0xe9, 0x5c, 0x13, 0x5a, 0xe3, 0x80, 0xcd, 0x1a, 0xf1, 0xd3, 0x68, 0x5f, 0xad,
-	0xb4, 0xfa, 0x86, 0x7b, 0xa9, 0xb2, 0x60, 0x77, 0xac, 0x50, 0x15, 0x98, 0x64, 0xa8, 0x82, 0x94,
-	0xc7, 0x2a, 0x17, 0xf6, 0x6b, 0x75, 0x7b, 0xb2, 0x9f, 0xc7, 0x2c, 0xa7, 0x5d, 0x1d, 0x07, 0x42,
-	0x96, 0xe1, 0x09, 0x1d, 0x5a, 0x3a, 0xb4, 0x74, 0xe8, 0xe8, 0x78, 0x64, 0xe6, 0xa7, 0xbf, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x15, 0x78, 0x4d, 0xd8, 0x2f, 0x02, 0x00, 0x00,
+func init() { proto.RegisterFile("orderer/kafka.proto", fileDescriptor7) }
+
+var fileDescriptor7 = []byte{
+	// 422 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0x5f, 0x6b, 0xd4, 0x40,
+	0x14, 0xc5, 0x9b, 0xdd, 0xb2, 0x4b, 0x6f, 0xb6, 0x52, 0x66, 0xa9, 0x46, 0x10, 0xa9, 0x01, 0x61,
+	0x1f, 0x24, 0x81, 0xf5, 0x45, 0x7c, 0x92, 0xf6, 0xa5, 0x22, 0xfe, 0x61, 0xdc, 0x2a, 0xf8, 0x12,
+	0x26, 0x99, 0x9b, 0x74, 0xd8, 0x24, 0x13, 0x66, 0x26, 0x6d, 0xf7, 0xb3, 0xf9, 0x15, 0xfc, 0x50,
+	0x92, 0x49, 0xc2, 0x66, 0x25, 0xea, 0xe3, 0x3d, 0xf7, 0x77, 0x39, 0x67, 0x4e, 0x02, 0x4b, 0xa9,
+	0x38, 0x2a, 0x54, 0xe1, 0x96, 0xa5, 0x5b, 0x16, 0x54, 0x4a, 0x1a, 0x49, 0xe6, 0x9d, 0xe8, 0xff,
+	0x72, 0x60, 0xf1, 0xa1, 0x59, 0x7c, 0x44, 0xad, 0x59, 0x86, 0xe4, 0x0d, 0xcc, 0x15, 0x66, 0x75,
+	0xce, 0x94, 0xe7, 0x5c, 0x38, 0x2b, 0x77, 0xfd, 0x2c, 0xe8, 0xd8, 0x60, 0xc8, 0xd1, 0x96, 0xb9,
+	0x3e, 0xa2, 0x3d, 0x4e, 0xde, 0x81, 0x6b, 0x44, 0x81, 0x91, 0x91, 0x51, 0x52, 0x1b, 0x6f, 0x62,
+	0xaf, 0x9f, 0x8f, 0x5e, 0x6f, 0x44, 0x81, 0x1b, 0x79, 0x55, 0x9b, 0xeb, 0x23, 0x7a, 0x62, 0xfa,
+	0xa1, 0xf1, 0x4e, 0x64, 0x59, 0x62, 0x62, 0xbc, 0xe9, 0x3f, 0xbc, 0xaf, 0x5a, 0xa6, 0xf1, 0xee,
+	0xf0, 0xcb, 0x19, 0x1c, 0x6f, 0x76, 0x15, 0xfa, 0x21, 0x2c, 0x47, 0x52, 0x12, 0x0f, 0xe6, 0x15,
+	0xdb, 0xe5, 0x92, 0x71, 0xfb, 0xa8, 0x05, 0xed, 0x47, 0xff, 0x2d, 0x9c, 0x8f, 0x06, 0x23, 0x2f,
+	0x60, 0x11, 0xe7, 0x32, 0xd9, 0x46, 0x65, 0x5d, 0xc4, 0xd8, 0x96, 0x71, 0x4c, 0x5d, 0xab, 0x7d,
+	0xb2, 0x92, 0xaf, 0x60, 0x39, 0x12, 0xeb, 0xef, 0x66, 0xe4, 0x09, 0xcc, 0x4b, 0xc9, 0x31, 0x12,
+	0xdc, 0xb6, 0x73, 0x42, 0x67, 0xcd, 0xf8, 0x9e, 0x93, 0x15, 0x9c, 0x15, 0xec, 0x21, 0xba, 0x17,
+	0x0a, 0xa3, 0x3b, 0x54, 0x5a, 0xc8, 0xd2, 0x36, 0x70, 0x4a, 0x1f, 0x15, 0xec, 0xe1, 0xbb, 0x50,
+	0xf8, 0xad, 0x55, 0xfd, 0x9f, 0x0e, 0x9c, 0x76, 0xa6, 0x86, 0x71, 0x66, 0x18, 0x59, 0xc3, 0x79,
+	0xce, 0xb4, 0x89, 0x64, 0x9a, 0x6a, 0x34, 0x51, 0xd5, 0x80, 0xda, 0x60, 0x6b, 0x3e, 0xa5, 0xcb,
+	0x66, 0xf9, 0xd9, 0xee, 0xbe, 0xf4, 0xab, 0xe6, 0x71, 0x07, 0x5e, 0x13, 0xeb, 0xe5, 0xde, 0xef,
+	0x8d, 0xc8, 0x57, 0xf0, 0x18, 0xbf, 0x43, 0x65, 0x84, 0x46, 0x7e, 0x90, 0x4c, 0x7b, 0xd3, 0x8b,
+	0xe9, 0xca, 0x5d, 0x3f, 0x3d, 0xfc, 0x38, 0x83, 0x94, 0xf4, 0xf1, 0xfe, 0x74, 0x20, 0x6b, 0xff,
+	0x06, 0xce, 0xfe, 0x64, 0x87, 0xa5, 0x38, 0xff, 0x2d, 0x65, 0x32, 0x56, 0xca, 0xe5, 0x0d, 0xbc,
+	0x94, 0x2a, 0x0b, 0x6e, 0x77, 0x15, 0xaa, 0x1c, 0x79, 0x86, 0x2a, 0x48, 0x59, 0xac, 0x44, 0xd2,
+	0xfe, 0xed, 0xba, 0x0f, 0xfa, 0xe3, 0x55, 0x26, 0xcc, 0x6d, 0x1d, 0x07, 0x89, 0x2c, 0xc2, 0x01,
+	0x1d, 0xb6, 0x74, 0xd8, 0xd2, 0x61, 0x47, 0xc7, 0x33, 0x3b, 0xbf, 0xfe, 0x3d, 0x00, 0x55, 0xab,
+	0xd3, 0x12, 0x42, 0x03, 0x00, 0x00,
 }
@@ -0,0 +1,387 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/txstatus.proto
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type TransactionStatusResponse_State int32
+
+const (
+	TransactionStatusResponse_UNKNOWN   TransactionStatusResponse_State = 0
+	TransactionStatusResponse_PENDING   TransactionStatusResponse_State = 1
+	TransactionStatusResponse_COMMITTED TransactionStatusResponse_State = 2
+)
+
+var TransactionStatusResponse_State_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "PENDING",
+	2: "COMMITTED",
+}
+var TransactionStatusResponse_State_value = map[string]int32{
+	"UNKNOWN":   0,
+	"PENDING":   1,
+	"COMMITTED": 2,
+}
+
+func (x TransactionStatusResponse_State) String() string {
+	return proto.EnumName(TransactionStatusResponse_State_name, int32(x))
+}
+func (TransactionStatusResponse_State) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor8, []int{1, 0}
+}
+
+// TransactionStatusRequest identifies the transaction whose fate is queried,
+// by its transaction ID or by the SHA256 hash of its envelope as submitted
+type TransactionStatusRequest struct {
+	TxId   string `protobuf:"bytes,1,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+	TxHash []byte `protobuf:"bytes,2,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+}
+
+func (m *TransactionStatusRequest) Reset()                    { *m = TransactionStatusRequest{} }
+func (m *TransactionStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*TransactionStatusRequest) ProtoMessage()               {}
+func (*TransactionStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{0} }
+
+func (m *TransactionStatusRequest) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+func (m *TransactionStatusRequest) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+// TransactionStatusResponse is the fate of a transaction on a channel
+type TransactionStatusResponse struct {
+	State       TransactionStatusResponse_State `protobuf:"varint,1,opt,name=state,enum=orderer.TransactionStatusResponse_State" json:"state,omitempty"`
+	BlockNumber uint64                          `protobuf:"varint,2,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	TxIndex     uint32                          `protobuf:"varint,3,opt,name=tx_index,json=txIndex" json:"tx_index,omitempty"`
+}
+
+func (m *TransactionStatusResponse) Reset()                    { *m = TransactionStatusResponse{} }
+func (m *TransactionStatusResponse) String() string            { return proto.CompactTextString(m) }
+func (*TransactionStatusResponse) ProtoMessage()               {}
+func (*TransactionStatusResponse) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{1} }
+
+func (m *TransactionStatusResponse) GetState() TransactionStatusResponse_State {
+	if m != nil {
+		return m.State
+	}
+	return TransactionStatusResponse_UNKNOWN
+}
+
+func (m *TransactionStatusResponse) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *TransactionStatusResponse) GetTxIndex() uint32 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+// TransactionGroupRequest identifies the group of transactions, tagged with
+// the group ID in the orderer extension of their channel headers, whose
+// inclusion is queried
+type TransactionGroupRequest struct {
+	GroupId string `protobuf:"bytes,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+}
+
+func (m *TransactionGroupRequest) Reset()                    { *m = TransactionGroupRequest{} }
+func (m *TransactionGroupRequest) String() string            { return proto.CompactTextString(m) }
+func (*TransactionGroupRequest) ProtoMessage()               {}
+func (*TransactionGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{2} }
+
+func (m *TransactionGroupRequest) GetGroupId() string {
+	if m != nil {
+		return m.GroupId
+	}
+	return ""
+}
+
+// TransactionGroupResponse lists the transactions of a group committed on
+// the channels the requester may read
+type TransactionGroupResponse struct {
+	Inclusions []*TransactionGroupResponse_Inclusion `protobuf:"bytes,1,rep,name=inclusions" json:"inclusions,omitempty"`
+}
+
+func (m *TransactionGroupResponse) Reset()                    { *m = TransactionGroupResponse{} }
+func (m *TransactionGroupResponse) String() string            { return proto.CompactTextString(m) }
+func (*TransactionGroupResponse) ProtoMessage()               {}
+func (*TransactionGroupResponse) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{3} }
+
+func (m *TransactionGroupResponse) GetInclusions() []*TransactionGroupResponse_Inclusion {
+	if m != nil {
+		return m.Inclusions
+	}
+	return nil
+}
+
+type TransactionGroupResponse_Inclusion struct {
+	ChannelId   string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	BlockNumber uint64 `protobuf:"varint,2,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	TxIndex     uint32 `protobuf:"varint,3,opt,name=tx_index,json=txIndex" json:"tx_index,omitempty"`
+	TxId        string `protobuf:"bytes,4,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+}
+
+func (m *TransactionGroupResponse_Inclusion) Reset()         { *m = TransactionGroupResponse_Inclusion{} }
+func (m *TransactionGroupResponse_Inclusion) String() string { return proto.CompactTextString(m) }
+func (*TransactionGroupResponse_Inclusion) ProtoMessage()    {}
+func (*TransactionGroupResponse_Inclusion) Descriptor() ([]byte, []int) {
+	return fileDescriptor8, []int{3, 0}
+}
+
+func (m *TransactionGroupResponse_Inclusion) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *TransactionGroupResponse_Inclusion) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *TransactionGroupResponse_Inclusion) GetTxIndex() uint32 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+func (m *TransactionGroupResponse_Inclusion) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*TransactionStatusRequest)(nil), "orderer.TransactionStatusRequest")
+	proto.RegisterType((*TransactionStatusResponse)(nil), "orderer.TransactionStatusResponse")
+	proto.RegisterType((*TransactionGroupRequest)(nil), "orderer.TransactionGroupRequest")
+	proto.RegisterType((*TransactionGroupResponse)(nil), "orderer.TransactionGroupResponse")
+	proto.RegisterType((*TransactionGroupResponse_Inclusion)(nil), "orderer.TransactionGroupResponse.Inclusion")
+	proto.RegisterEnum("orderer.TransactionStatusResponse_State", TransactionStatusResponse_State_name, TransactionStatusResponse_State_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for TransactionStatus service
+
+type TransactionStatusClient interface {
+	// GetTransactionStatus requires an Envelope whose payload data is a
+	// marshaled TransactionStatusRequest, signed by a reader of the channel of
+	// its channel header
+	GetTransactionStatus(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*TransactionStatusResponse, error)
+	// GetReceipt requires an Envelope as GetTransactionStatus does, whose
+	// request identifies the transaction by tx_hash, and returns the signed
+	// response with which the orderer accepted it for ordering on the channel
+	GetReceipt(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*BroadcastResponse, error)
+	// GetTransactionGroup requires an Envelope whose payload data is a
+	// marshaled TransactionGroupRequest, and returns the inclusion of the
+	// transactions of the group on each channel of which the signer of the
+	// Envelope is a reader, whatever the channel of its channel header
+	GetTransactionGroup(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*TransactionGroupResponse, error)
+}
+
+type transactionStatusClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTransactionStatusClient(cc *grpc.ClientConn) TransactionStatusClient {
+	return &transactionStatusClient{cc}
+}
+
+func (c *transactionStatusClient) GetTransactionStatus(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*TransactionStatusResponse, error) {
+	out := new(TransactionStatusResponse)
+	err := grpc.Invoke(ctx, "/orderer.TransactionStatus/GetTransactionStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionStatusClient) GetReceipt(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*BroadcastResponse, error) {
+	out := new(BroadcastResponse)
+	err := grpc.Invoke(ctx, "/orderer.TransactionStatus/GetReceipt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionStatusClient) GetTransactionGroup(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*TransactionGroupResponse, error) {
+	out := new(TransactionGroupResponse)
+	err := grpc.Invoke(ctx, "/orderer.TransactionStatus/GetTransactionGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for TransactionStatus service
+
+type TransactionStatusServer interface {
+	// GetTransactionStatus requires an Envelope whose payload data is a
+	// marshaled TransactionStatusRequest, signed by a reader of the channel of
+	// its channel header
+	GetTransactionStatus(context.Context, *common.Envelope) (*TransactionStatusResponse, error)
+	// GetReceipt requires an Envelope as GetTransactionStatus does, whose
+	// request identifies the transaction by tx_hash, and returns the signed
+	// response with which the orderer accepted it for ordering on the channel
+	GetReceipt(context.Context, *common.Envelope) (*BroadcastResponse, error)
+	// GetTransactionGroup requires an Envelope whose payload data is a
+	// marshaled TransactionGroupRequest, and returns the inclusion of the
+	// transactions of the group on each channel of which the signer of the
+	// Envelope is a reader, whatever the channel of its channel header
+	GetTransactionGroup(context.Context, *common.Envelope) (*TransactionGroupResponse, error)
+}
+
+func RegisterTransactionStatusServer(s *grpc.Server, srv TransactionStatusServer) {
+	s.RegisterService(&_TransactionStatus_serviceDesc, srv)
+}
+
+func _TransactionStatus_GetTransactionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionStatusServer).GetTransactionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.TransactionStatus/GetTransactionStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionStatusServer).GetTransactionStatus(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionStatus_GetReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionStatusServer).GetReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.TransactionStatus/GetReceipt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionStatusServer).GetReceipt(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionStatus_GetTransactionGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionStatusServer).GetTransactionGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.TransactionStatus/GetTransactionGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionStatusServer).GetTransactionGroup(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TransactionStatus_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.TransactionStatus",
+	HandlerType: (*TransactionStatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTransactionStatus",
+			Handler:    _TransactionStatus_GetTransactionStatus_Handler,
+		},
+		{
+			MethodName: "GetReceipt",
+			Handler:    _TransactionStatus_GetReceipt_Handler,
+		},
+		{
+			MethodName: "GetTransactionGroup",
+			Handler:    _TransactionStatus_GetTransactionGroup_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "orderer/txstatus.proto",
+}
+
+func init() { proto.RegisterFile("orderer/txstatus.proto", fileDescriptor8) }
+
+var fileDescriptor8 = []byte{
+	// 485 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
+	0x14, 0x8c, 0xdb, 0xa4, 0x69, 0x5e, 0x5a, 0x14, 0x36, 0x88, 0x3a, 0x91, 0x90, 0x52, 0x4b, 0x48,
+	0x96, 0x40, 0x36, 0x0a, 0x1c, 0x11, 0x87, 0xd2, 0x28, 0xb5, 0x4a, 0x5d, 0x64, 0x52, 0x21, 0x71,
+	0x89, 0xd6, 0xf6, 0x23, 0xb6, 0x70, 0x76, 0xcd, 0xee, 0x1a, 0x99, 0x03, 0xbf, 0x90, 0xff, 0xc1,
+	0x95, 0xbf, 0x80, 0xfc, 0x91, 0x26, 0x55, 0x82, 0x7a, 0xe8, 0xc9, 0x9a, 0xd9, 0x37, 0xb3, 0xa3,
+	0xd9, 0x67, 0x78, 0xca, 0x45, 0x88, 0x02, 0x85, 0xad, 0x72, 0xa9, 0xa8, 0xca, 0xa4, 0x95, 0x0a,
+	0xae, 0x38, 0x69, 0xd7, 0xfc, 0xb0, 0x1f, 0xf0, 0xe5, 0x92, 0x33, 0xbb, 0xfa, 0x54, 0xa7, 0xc3,
+	0xde, 0x4a, 0x45, 0xfd, 0x8a, 0x31, 0x2e, 0x40, 0x9f, 0x09, 0xca, 0x24, 0x0d, 0x54, 0xcc, 0xd9,
+	0xa7, 0xd2, 0xca, 0xc3, 0xef, 0x19, 0x4a, 0x45, 0xfa, 0xd0, 0x52, 0xf9, 0x3c, 0x0e, 0x75, 0x6d,
+	0xa4, 0x99, 0x1d, 0xaf, 0xa9, 0x72, 0x27, 0x24, 0x27, 0xd0, 0x56, 0xf9, 0x3c, 0xa2, 0x32, 0xd2,
+	0xf7, 0x46, 0x9a, 0x79, 0xe4, 0x1d, 0xa8, 0xfc, 0x82, 0xca, 0xc8, 0xf8, 0xad, 0xc1, 0x60, 0x87,
+	0x95, 0x4c, 0x39, 0x93, 0x48, 0xde, 0x41, 0xab, 0xc8, 0x89, 0xa5, 0xd7, 0xa3, 0xb1, 0x69, 0xd5,
+	0x49, 0xac, 0xff, 0x4a, 0xac, 0x02, 0xa2, 0x57, 0xc9, 0xc8, 0x29, 0x1c, 0xf9, 0x09, 0x0f, 0xbe,
+	0xcd, 0x59, 0xb6, 0xf4, 0x51, 0x94, 0x77, 0x37, 0xbd, 0x6e, 0xc9, 0xb9, 0x25, 0x45, 0x06, 0x70,
+	0x58, 0xc4, 0x65, 0x21, 0xe6, 0xfa, 0xfe, 0x48, 0x33, 0x8f, 0xbd, 0xb6, 0xca, 0x9d, 0x02, 0x1a,
+	0xaf, 0xa0, 0x55, 0xba, 0x91, 0x2e, 0xb4, 0x6f, 0xdc, 0x4b, 0xf7, 0xfa, 0xb3, 0xdb, 0x6b, 0x14,
+	0xe0, 0xe3, 0xc4, 0x3d, 0x77, 0xdc, 0x69, 0x4f, 0x23, 0xc7, 0xd0, 0x79, 0x7f, 0x7d, 0x75, 0xe5,
+	0xcc, 0x66, 0x93, 0xf3, 0xde, 0x9e, 0xf1, 0x06, 0x4e, 0x36, 0x92, 0x4d, 0x05, 0xcf, 0xd2, 0x55,
+	0x2d, 0x03, 0x38, 0x5c, 0x14, 0x78, 0xdd, 0x4c, 0xbb, 0xc4, 0x4e, 0x68, 0xfc, 0xd1, 0x40, 0xdf,
+	0x96, 0xd5, 0x15, 0x5c, 0x02, 0xc4, 0x2c, 0x48, 0x32, 0x19, 0x73, 0x26, 0x75, 0x6d, 0xb4, 0x6f,
+	0x76, 0xc7, 0x2f, 0x76, 0xf5, 0x70, 0x47, 0x66, 0x39, 0x2b, 0x8d, 0xb7, 0x21, 0x1f, 0xfe, 0x82,
+	0xce, 0xed, 0x01, 0x79, 0x06, 0x10, 0x44, 0x94, 0x31, 0x4c, 0xd6, 0x99, 0x3a, 0x35, 0xe3, 0x84,
+	0x0f, 0xeb, 0x6e, 0xbd, 0x05, 0xcd, 0xf5, 0x16, 0x8c, 0xff, 0x6a, 0xf0, 0x78, 0xeb, 0xe5, 0x88,
+	0x0b, 0x4f, 0xa6, 0xa8, 0xb6, 0xf9, 0x9e, 0x55, 0x6f, 0xe1, 0x84, 0xfd, 0xc0, 0x84, 0xa7, 0x38,
+	0x34, 0xee, 0x7f, 0x7f, 0xa3, 0x41, 0xde, 0x02, 0x4c, 0x51, 0x79, 0x18, 0x60, 0x9c, 0xaa, 0x1d,
+	0x2e, 0xc3, 0x5b, 0x97, 0x33, 0xc1, 0x69, 0x18, 0x50, 0xa9, 0x36, 0xd4, 0x1f, 0xa0, 0x7f, 0x37,
+	0x4d, 0xd9, 0xeb, 0x0e, 0x9b, 0xd3, 0x7b, 0x1f, 0xc1, 0x68, 0x9c, 0xdd, 0xc0, 0x73, 0x2e, 0x16,
+	0x56, 0xf4, 0x33, 0x45, 0x91, 0x60, 0xb8, 0x40, 0x61, 0x7d, 0xa5, 0xbe, 0x88, 0x83, 0xea, 0x47,
+	0x92, 0x2b, 0x8f, 0x2f, 0x2f, 0x17, 0xb1, 0x8a, 0x32, 0xbf, 0xb8, 0xc5, 0xde, 0x98, 0xb6, 0xab,
+	0x69, 0xbb, 0x9a, 0xb6, 0xeb, 0x69, 0xff, 0xa0, 0xc4, 0xaf, 0xff, 0x0d, 0x00, 0x10, 0xc3, 0xfb,
+	0xdc, 0xd0, 0x03, 0x00, 0x00,
+}
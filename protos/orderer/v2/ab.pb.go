@@ -0,0 +1,552 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/v2/ab.proto
+
+/*
+Package v2 is a generated protocol buffer package.
+
+It is generated from these files:
+	orderer/v2/ab.proto
+
+It has these top-level messages:
+	SeekInfo
+	Status
+	BroadcastResponse
+	DeliverResponse
+*/
+package v2
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+import orderer "github.com/hyperledger/fabric/protos/orderer"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// ContentType is the content of the blocks delivered
+type SeekInfo_ContentType int32
+
+const (
+	SeekInfo_BLOCK   SeekInfo_ContentType = 0
+	SeekInfo_HEADERS SeekInfo_ContentType = 1
+)
+
+var SeekInfo_ContentType_name = map[int32]string{
+	0: "BLOCK",
+	1: "HEADERS",
+}
+var SeekInfo_ContentType_value = map[string]int32{
+	"BLOCK":   0,
+	"HEADERS": 1,
+}
+
+func (x SeekInfo_ContentType) String() string {
+	return proto.EnumName(SeekInfo_ContentType_name, int32(x))
+}
+func (SeekInfo_ContentType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 0} }
+
+// SeekInfo is the payload data of a Deliver request. It is a superset of
+// orderer.SeekInfo: the fields numbered below 16 are those of orderer.SeekInfo,
+// with the same meaning, and the fields from 16 on are only honored by
+// version 2.
+type SeekInfo struct {
+	Start        *orderer.SeekPosition         `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
+	Stop         *orderer.SeekPosition         `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
+	Behavior     orderer.SeekInfo_SeekBehavior `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	MaxBatchSize uint32                        `protobuf:"varint,4,opt,name=max_batch_size,json=maxBatchSize" json:"max_batch_size,omitempty"`
+	Content      SeekInfo_ContentType          `protobuf:"varint,16,opt,name=content,enum=orderer.v2.SeekInfo_ContentType" json:"content,omitempty"`
+}
+
+func (m *SeekInfo) Reset()                    { *m = SeekInfo{} }
+func (m *SeekInfo) String() string            { return proto.CompactTextString(m) }
+func (*SeekInfo) ProtoMessage()               {}
+func (*SeekInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *SeekInfo) GetStart() *orderer.SeekPosition {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *SeekInfo) GetStop() *orderer.SeekPosition {
+	if m != nil {
+		return m.Stop
+	}
+	return nil
+}
+
+func (m *SeekInfo) GetBehavior() orderer.SeekInfo_SeekBehavior {
+	if m != nil {
+		return m.Behavior
+	}
+	return orderer.SeekInfo_BLOCK_UNTIL_READY
+}
+
+func (m *SeekInfo) GetMaxBatchSize() uint32 {
+	if m != nil {
+		return m.MaxBatchSize
+	}
+	return 0
+}
+
+func (m *SeekInfo) GetContent() SeekInfo_ContentType {
+	if m != nil {
+		return m.Content
+	}
+	return SeekInfo_BLOCK
+}
+
+// Status is the status of a request, along with the details of its failure
+type Status struct {
+	Status       common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	Reason       string        `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+	ChannelId    string        `protobuf:"bytes,3,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	RetryDelayMs int64         `protobuf:"varint,4,opt,name=retry_delay_ms,json=retryDelayMs" json:"retry_delay_ms,omitempty"`
+	Endpoints    []string      `protobuf:"bytes,5,rep,name=endpoints" json:"endpoints,omitempty"`
+}
+
+func (m *Status) Reset()                    { *m = Status{} }
+func (m *Status) String() string            { return proto.CompactTextString(m) }
+func (*Status) ProtoMessage()               {}
+func (*Status) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Status) GetStatus() common.Status {
+	if m != nil {
+		return m.Status
+	}
+	return common.Status_UNKNOWN
+}
+
+func (m *Status) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Status) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *Status) GetRetryDelayMs() int64 {
+	if m != nil {
+		return m.RetryDelayMs
+	}
+	return 0
+}
+
+func (m *Status) GetEndpoints() []string {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
+type BroadcastResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	// acknowledgment is a marshaled orderer.BroadcastAcknowledgment, set only
+	// when the orderer is configured to sign its responses
+	Acknowledgment []byte `protobuf:"bytes,2,opt,name=acknowledgment,proto3" json:"acknowledgment,omitempty"`
+	// signature_header is a marshaled common.SignatureHeader identifying the orderer
+	SignatureHeader []byte `protobuf:"bytes,3,opt,name=signature_header,json=signatureHeader,proto3" json:"signature_header,omitempty"`
+	// signature is over the concatenation of acknowledgment and signature_header
+	Signature []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *BroadcastResponse) Reset()                    { *m = BroadcastResponse{} }
+func (m *BroadcastResponse) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastResponse) ProtoMessage()               {}
+func (*BroadcastResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *BroadcastResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetAcknowledgment() []byte {
+	if m != nil {
+		return m.Acknowledgment
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetSignatureHeader() []byte {
+	if m != nil {
+		return m.SignatureHeader
+	}
+	return nil
+}
+
+func (m *BroadcastResponse) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// DeliverResponse carries the blocks delivered, always as a batch, or the
+// status which completes a request
+type DeliverResponse struct {
+	// Types that are valid to be assigned to Type:
+	//	*DeliverResponse_Status
+	//	*DeliverResponse_Blocks
+	Type isDeliverResponse_Type `protobuf_oneof:"Type"`
+}
+
+func (m *DeliverResponse) Reset()                    { *m = DeliverResponse{} }
+func (m *DeliverResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeliverResponse) ProtoMessage()               {}
+func (*DeliverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type isDeliverResponse_Type interface {
+	isDeliverResponse_Type()
+}
+
+type DeliverResponse_Status struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status,oneof"`
+}
+type DeliverResponse_Blocks struct {
+	Blocks *orderer.Blocks `protobuf:"bytes,2,opt,name=blocks,oneof"`
+}
+
+func (*DeliverResponse_Status) isDeliverResponse_Type() {}
+func (*DeliverResponse_Blocks) isDeliverResponse_Type() {}
+
+func (m *DeliverResponse) GetType() isDeliverResponse_Type {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *DeliverResponse) GetStatus() *Status {
+	if x, ok := m.GetType().(*DeliverResponse_Status); ok {
+		return x.Status
+	}
+	return nil
+}
+
+func (m *DeliverResponse) GetBlocks() *orderer.Blocks {
+	if x, ok := m.GetType().(*DeliverResponse_Blocks); ok {
+		return x.Blocks
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*DeliverResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _DeliverResponse_OneofMarshaler, _DeliverResponse_OneofUnmarshaler, _DeliverResponse_OneofSizer, []interface{}{
+		(*DeliverResponse_Status)(nil),
+		(*DeliverResponse_Blocks)(nil),
+	}
+}
+
+func _DeliverResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*DeliverResponse)
+	// Type
+	switch x := m.Type.(type) {
+	case *DeliverResponse_Status:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Status); err != nil {
+			return err
+		}
+	case *DeliverResponse_Blocks:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Blocks); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("DeliverResponse.Type has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _DeliverResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*DeliverResponse)
+	switch tag {
+	case 1: // Type.status
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Status)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_Status{msg}
+		return true, err
+	case 2: // Type.blocks
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(orderer.Blocks)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_Blocks{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*DeliverResponse)
+	// Type
+	switch x := m.Type.(type) {
+	case *DeliverResponse_Status:
+		s := proto.Size(x.Status)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *DeliverResponse_Blocks:
+		s := proto.Size(x.Blocks)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+func init() {
+	proto.RegisterType((*SeekInfo)(nil), "orderer.v2.SeekInfo")
+	proto.RegisterType((*Status)(nil), "orderer.v2.Status")
+	proto.RegisterType((*BroadcastResponse)(nil), "orderer.v2.BroadcastResponse")
+	proto.RegisterType((*DeliverResponse)(nil), "orderer.v2.DeliverResponse")
+	proto.RegisterEnum("orderer.v2.SeekInfo_ContentType", SeekInfo_ContentType_name, SeekInfo_ContentType_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for AtomicBroadcast service
+
+type AtomicBroadcastClient interface {
+	// broadcast receives a response for each common.Envelope in order, the status of a failure carrying its details
+	Broadcast(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_BroadcastClient, error)
+	// deliver first requires an Envelope of type DELIVER_SEEK_INFO with Payload data as a marshaled SeekInfo message, then a stream of block replies is received.
+	Deliver(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_DeliverClient, error)
+}
+
+type atomicBroadcastClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAtomicBroadcastClient(cc *grpc.ClientConn) AtomicBroadcastClient {
+	return &atomicBroadcastClient{cc}
+}
+
+func (c *atomicBroadcastClient) Broadcast(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_BroadcastClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AtomicBroadcast_serviceDesc.Streams[0], c.cc, "/orderer.v2.AtomicBroadcast/Broadcast", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &atomicBroadcastBroadcastClient{stream}
+	return x, nil
+}
+
+type AtomicBroadcast_BroadcastClient interface {
+	Send(*common.Envelope) error
+	Recv() (*BroadcastResponse, error)
+	grpc.ClientStream
+}
+
+type atomicBroadcastBroadcastClient struct {
+	grpc.ClientStream
+}
+
+func (x *atomicBroadcastBroadcastClient) Send(m *common.Envelope) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastBroadcastClient) Recv() (*BroadcastResponse, error) {
+	m := new(BroadcastResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *atomicBroadcastClient) Deliver(ctx context.Context, opts ...grpc.CallOption) (AtomicBroadcast_DeliverClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AtomicBroadcast_serviceDesc.Streams[1], c.cc, "/orderer.v2.AtomicBroadcast/Deliver", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &atomicBroadcastDeliverClient{stream}
+	return x, nil
+}
+
+type AtomicBroadcast_DeliverClient interface {
+	Send(*common.Envelope) error
+	Recv() (*DeliverResponse, error)
+	grpc.ClientStream
+}
+
+type atomicBroadcastDeliverClient struct {
+	grpc.ClientStream
+}
+
+func (x *atomicBroadcastDeliverClient) Send(m *common.Envelope) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastDeliverClient) Recv() (*DeliverResponse, error) {
+	m := new(DeliverResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for AtomicBroadcast service
+
+type AtomicBroadcastServer interface {
+	// broadcast receives a response for each common.Envelope in order, the status of a failure carrying its details
+	Broadcast(AtomicBroadcast_BroadcastServer) error
+	// deliver first requires an Envelope of type DELIVER_SEEK_INFO with Payload data as a marshaled SeekInfo message, then a stream of block replies is received.
+	Deliver(AtomicBroadcast_DeliverServer) error
+}
+
+func RegisterAtomicBroadcastServer(s *grpc.Server, srv AtomicBroadcastServer) {
+	s.RegisterService(&_AtomicBroadcast_serviceDesc, srv)
+}
+
+func _AtomicBroadcast_Broadcast_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AtomicBroadcastServer).Broadcast(&atomicBroadcastBroadcastServer{stream})
+}
+
+type AtomicBroadcast_BroadcastServer interface {
+	Send(*BroadcastResponse) error
+	Recv() (*common.Envelope, error)
+	grpc.ServerStream
+}
+
+type atomicBroadcastBroadcastServer struct {
+	grpc.ServerStream
+}
+
+func (x *atomicBroadcastBroadcastServer) Send(m *BroadcastResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastBroadcastServer) Recv() (*common.Envelope, error) {
+	m := new(common.Envelope)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _AtomicBroadcast_Deliver_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AtomicBroadcastServer).Deliver(&atomicBroadcastDeliverServer{stream})
+}
+
+type AtomicBroadcast_DeliverServer interface {
+	Send(*DeliverResponse) error
+	Recv() (*common.Envelope, error)
+	grpc.ServerStream
+}
+
+type atomicBroadcastDeliverServer struct {
+	grpc.ServerStream
+}
+
+func (x *atomicBroadcastDeliverServer) Send(m *DeliverResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *atomicBroadcastDeliverServer) Recv() (*common.Envelope, error) {
+	m := new(common.Envelope)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.v2.AtomicBroadcast",
+	HandlerType: (*AtomicBroadcastServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Broadcast",
+			Handler:       _AtomicBroadcast_Broadcast_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Deliver",
+			Handler:       _AtomicBroadcast_Deliver_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "orderer/v2/ab.proto",
+}
+
+func init() { proto.RegisterFile("orderer/v2/ab.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 583 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x94, 0x4f, 0x6f, 0xd3, 0x3e,
+	0x18, 0xc7, 0x97, 0xfd, 0xe9, 0xd6, 0xa7, 0xfd, 0xb5, 0xfd, 0x79, 0x02, 0x55, 0x83, 0xa1, 0xaa,
+	0x82, 0xa9, 0x03, 0x94, 0xa0, 0x70, 0xdb, 0x01, 0x69, 0xd9, 0x26, 0x6d, 0x02, 0x04, 0x72, 0x39,
+	0xc1, 0xa1, 0x72, 0x92, 0x67, 0x6d, 0xb4, 0xc4, 0x8e, 0x6c, 0xb7, 0xac, 0x7b, 0x1f, 0xbc, 0x06,
+	0x0e, 0x5c, 0x78, 0x89, 0xc8, 0x4e, 0x9a, 0xb6, 0x03, 0xc1, 0x29, 0xf6, 0xc7, 0xdf, 0xe7, 0xdf,
+	0xd7, 0x49, 0x60, 0x5f, 0xc8, 0x18, 0x25, 0x4a, 0x6f, 0xe6, 0x7b, 0x2c, 0x74, 0x73, 0x29, 0xb4,
+	0x20, 0x50, 0x42, 0x77, 0xe6, 0x1f, 0xec, 0x47, 0x22, 0xcb, 0x04, 0xf7, 0x8a, 0x47, 0x21, 0x38,
+	0xe8, 0x2c, 0xa2, 0x16, 0x21, 0xfd, 0xef, 0x9b, 0xb0, 0x37, 0x44, 0xbc, 0xb9, 0xe2, 0xd7, 0x82,
+	0xbc, 0x80, 0x1d, 0xa5, 0x99, 0xd4, 0x5d, 0xa7, 0xe7, 0x0c, 0x1a, 0xfe, 0x03, 0x77, 0x91, 0xcf,
+	0x28, 0x3e, 0x0a, 0x95, 0xe8, 0x44, 0x70, 0x5a, 0x68, 0xc8, 0x31, 0x6c, 0x2b, 0x2d, 0xf2, 0xee,
+	0xe6, 0xdf, 0xb4, 0x56, 0x42, 0x4e, 0x60, 0x2f, 0xc4, 0x09, 0x9b, 0x25, 0x42, 0x76, 0xb7, 0x7a,
+	0xce, 0xa0, 0xe5, 0x3f, 0x59, 0x93, 0x9b, 0xe2, 0x76, 0x11, 0x94, 0x2a, 0x5a, 0xe9, 0xc9, 0x53,
+	0x68, 0x65, 0xec, 0x76, 0x14, 0x32, 0x1d, 0x4d, 0x46, 0x2a, 0xb9, 0xc3, 0xee, 0x76, 0xcf, 0x19,
+	0xfc, 0x47, 0x9b, 0x19, 0xbb, 0x0d, 0x0c, 0x1c, 0x26, 0x77, 0x48, 0x4e, 0x60, 0x37, 0x12, 0x5c,
+	0x23, 0xd7, 0xdd, 0x8e, 0x2d, 0xd0, 0x73, 0x97, 0x5e, 0x2c, 0x6b, 0x9c, 0x15, 0x9a, 0x4f, 0xf3,
+	0x1c, 0xe9, 0x22, 0xa0, 0xff, 0x0c, 0x1a, 0x2b, 0x9c, 0xd4, 0x61, 0x27, 0x78, 0xf7, 0xe1, 0xec,
+	0x6d, 0x67, 0x83, 0x34, 0x60, 0xf7, 0xf2, 0xe2, 0xf4, 0xfc, 0x82, 0x0e, 0x3b, 0x4e, 0xff, 0x87,
+	0x03, 0xb5, 0xa1, 0x66, 0x7a, 0xaa, 0xc8, 0x11, 0xd4, 0x94, 0x5d, 0x59, 0xa3, 0x5a, 0x7e, 0xcb,
+	0x2d, 0x5d, 0x2e, 0xce, 0x69, 0x79, 0x4a, 0x1e, 0x42, 0x4d, 0x22, 0x53, 0x82, 0x5b, 0x93, 0xea,
+	0xb4, 0xdc, 0x91, 0x43, 0x80, 0x68, 0xc2, 0x38, 0xc7, 0x74, 0x94, 0xc4, 0xd6, 0x91, 0x3a, 0xad,
+	0x97, 0xe4, 0x2a, 0x36, 0x23, 0x4b, 0xd4, 0x72, 0x3e, 0x8a, 0x31, 0x65, 0xf3, 0x51, 0xa6, 0xec,
+	0xc8, 0x5b, 0xb4, 0x69, 0xe9, 0xb9, 0x81, 0xef, 0x15, 0x79, 0x0c, 0x75, 0xe4, 0x71, 0x2e, 0x12,
+	0xae, 0x55, 0x77, 0xa7, 0xb7, 0x65, 0x72, 0x54, 0xa0, 0xff, 0xd3, 0x81, 0xff, 0x03, 0x29, 0x58,
+	0x1c, 0x31, 0xa5, 0x29, 0xaa, 0x5c, 0x70, 0x85, 0xe4, 0xf9, 0x5a, 0xe3, 0x0d, 0x9f, 0xac, 0xb9,
+	0xb4, 0xde, 0xfc, 0x11, 0xb4, 0x58, 0x74, 0xc3, 0xc5, 0xd7, 0x14, 0xe3, 0x71, 0x66, 0x9c, 0x35,
+	0x43, 0x34, 0xe9, 0x3d, 0x4a, 0x8e, 0xa1, 0xa3, 0x92, 0x31, 0x67, 0x7a, 0x2a, 0x71, 0x34, 0x41,
+	0x16, 0x63, 0x71, 0xc9, 0x4d, 0xda, 0xae, 0xf8, 0xa5, 0xc5, 0xa6, 0xe5, 0x0a, 0xd9, 0x99, 0x9a,
+	0x74, 0x09, 0xfa, 0x12, 0xda, 0xe7, 0x98, 0x26, 0x33, 0x94, 0x55, 0xbf, 0x2f, 0xff, 0xdd, 0xef,
+	0xe5, 0x46, 0xd5, 0xf1, 0x31, 0xd4, 0xc2, 0x54, 0x44, 0x37, 0xaa, 0x7c, 0x27, 0xdb, 0x95, 0x3a,
+	0xb0, 0xd8, 0x48, 0x0b, 0x41, 0x50, 0x83, 0x6d, 0x73, 0xd9, 0xfe, 0x37, 0x07, 0xda, 0xa7, 0x5a,
+	0x64, 0x49, 0x54, 0x99, 0x45, 0x02, 0xa8, 0x2f, 0x37, 0x9d, 0xc5, 0xd5, 0x5e, 0xf0, 0x19, 0xa6,
+	0x22, 0xc7, 0x83, 0xc3, 0xd5, 0x1e, 0x7e, 0xb3, 0xb8, 0xbf, 0x31, 0x70, 0x5e, 0x39, 0xe4, 0x0d,
+	0xec, 0x96, 0xb3, 0xfc, 0x21, 0xc3, 0xa3, 0xd5, 0x0c, 0xf7, 0x46, 0x2e, 0xe2, 0x83, 0x2f, 0x30,
+	0x10, 0x72, 0xec, 0x4e, 0xe6, 0x39, 0x4a, 0xe3, 0x34, 0x4a, 0xf7, 0x9a, 0x85, 0x32, 0x89, 0x8a,
+	0xcf, 0x56, 0xad, 0x64, 0xf8, 0xec, 0x8d, 0x13, 0x3d, 0x99, 0x86, 0xa6, 0x8c, 0xb7, 0x12, 0xe0,
+	0x15, 0x01, 0x5e, 0x11, 0xe0, 0x2d, 0xff, 0x17, 0x61, 0xcd, 0xa2, 0xd7, 0xbf, 0x06, 0x00, 0x44,
+	0xc1, 0x04, 0x55, 0x44, 0x04, 0x00, 0x00,
+}
@@ -0,0 +1,748 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: reflection/reflection.proto
+
+/*
+Package reflection is a generated protocol buffer package.
+
+It is generated from these files:
+	reflection/reflection.proto
+
+It has these top-level messages:
+	ServerReflectionRequest
+	ExtensionRequest
+	ServerReflectionResponse
+	FileDescriptorResponse
+	ExtensionNumberResponse
+	ListServiceResponse
+	ServiceResponse
+	ErrorResponse
+*/
+package reflection
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// ServerReflectionRequest is the message sent by the client to the server
+type ServerReflectionRequest struct {
+	Host string `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	// Types that are valid to be assigned to MessageRequest:
+	//	*ServerReflectionRequest_FileByFilename
+	//	*ServerReflectionRequest_FileContainingSymbol
+	//	*ServerReflectionRequest_FileContainingExtension
+	//	*ServerReflectionRequest_AllExtensionNumbersOfType
+	//	*ServerReflectionRequest_ListServices
+	MessageRequest isServerReflectionRequest_MessageRequest `protobuf_oneof:"message_request"`
+}
+
+func (m *ServerReflectionRequest) Reset()                    { *m = ServerReflectionRequest{} }
+func (m *ServerReflectionRequest) String() string            { return proto.CompactTextString(m) }
+func (*ServerReflectionRequest) ProtoMessage()               {}
+func (*ServerReflectionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type isServerReflectionRequest_MessageRequest interface {
+	isServerReflectionRequest_MessageRequest()
+}
+
+type ServerReflectionRequest_FileByFilename struct {
+	FileByFilename string `protobuf:"bytes,3,opt,name=file_by_filename,json=fileByFilename,oneof"`
+}
+type ServerReflectionRequest_FileContainingSymbol struct {
+	FileContainingSymbol string `protobuf:"bytes,4,opt,name=file_containing_symbol,json=fileContainingSymbol,oneof"`
+}
+type ServerReflectionRequest_FileContainingExtension struct {
+	FileContainingExtension *ExtensionRequest `protobuf:"bytes,5,opt,name=file_containing_extension,json=fileContainingExtension,oneof"`
+}
+type ServerReflectionRequest_AllExtensionNumbersOfType struct {
+	AllExtensionNumbersOfType string `protobuf:"bytes,6,opt,name=all_extension_numbers_of_type,json=allExtensionNumbersOfType,oneof"`
+}
+type ServerReflectionRequest_ListServices struct {
+	ListServices string `protobuf:"bytes,7,opt,name=list_services,json=listServices,oneof"`
+}
+
+func (*ServerReflectionRequest_FileByFilename) isServerReflectionRequest_MessageRequest()          {}
+func (*ServerReflectionRequest_FileContainingSymbol) isServerReflectionRequest_MessageRequest()    {}
+func (*ServerReflectionRequest_FileContainingExtension) isServerReflectionRequest_MessageRequest() {}
+func (*ServerReflectionRequest_AllExtensionNumbersOfType) isServerReflectionRequest_MessageRequest() {
+}
+func (*ServerReflectionRequest_ListServices) isServerReflectionRequest_MessageRequest() {}
+
+func (m *ServerReflectionRequest) GetMessageRequest() isServerReflectionRequest_MessageRequest {
+	if m != nil {
+		return m.MessageRequest
+	}
+	return nil
+}
+
+func (m *ServerReflectionRequest) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *ServerReflectionRequest) GetFileByFilename() string {
+	if x, ok := m.GetMessageRequest().(*ServerReflectionRequest_FileByFilename); ok {
+		return x.FileByFilename
+	}
+	return ""
+}
+
+func (m *ServerReflectionRequest) GetFileContainingSymbol() string {
+	if x, ok := m.GetMessageRequest().(*ServerReflectionRequest_FileContainingSymbol); ok {
+		return x.FileContainingSymbol
+	}
+	return ""
+}
+
+func (m *ServerReflectionRequest) GetFileContainingExtension() *ExtensionRequest {
+	if x, ok := m.GetMessageRequest().(*ServerReflectionRequest_FileContainingExtension); ok {
+		return x.FileContainingExtension
+	}
+	return nil
+}
+
+func (m *ServerReflectionRequest) GetAllExtensionNumbersOfType() string {
+	if x, ok := m.GetMessageRequest().(*ServerReflectionRequest_AllExtensionNumbersOfType); ok {
+		return x.AllExtensionNumbersOfType
+	}
+	return ""
+}
+
+func (m *ServerReflectionRequest) GetListServices() string {
+	if x, ok := m.GetMessageRequest().(*ServerReflectionRequest_ListServices); ok {
+		return x.ListServices
+	}
+	return ""
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*ServerReflectionRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _ServerReflectionRequest_OneofMarshaler, _ServerReflectionRequest_OneofUnmarshaler, _ServerReflectionRequest_OneofSizer, []interface{}{
+		(*ServerReflectionRequest_FileByFilename)(nil),
+		(*ServerReflectionRequest_FileContainingSymbol)(nil),
+		(*ServerReflectionRequest_FileContainingExtension)(nil),
+		(*ServerReflectionRequest_AllExtensionNumbersOfType)(nil),
+		(*ServerReflectionRequest_ListServices)(nil),
+	}
+}
+
+func _ServerReflectionRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*ServerReflectionRequest)
+	// message_request
+	switch x := m.MessageRequest.(type) {
+	case *ServerReflectionRequest_FileByFilename:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.FileByFilename)
+	case *ServerReflectionRequest_FileContainingSymbol:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.FileContainingSymbol)
+	case *ServerReflectionRequest_FileContainingExtension:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.FileContainingExtension); err != nil {
+			return err
+		}
+	case *ServerReflectionRequest_AllExtensionNumbersOfType:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.AllExtensionNumbersOfType)
+	case *ServerReflectionRequest_ListServices:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.ListServices)
+	case nil:
+	default:
+		return fmt.Errorf("ServerReflectionRequest.MessageRequest has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _ServerReflectionRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*ServerReflectionRequest)
+	switch tag {
+	case 3: // message_request.file_by_filename
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.MessageRequest = &ServerReflectionRequest_FileByFilename{x}
+		return true, err
+	case 4: // message_request.file_containing_symbol
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.MessageRequest = &ServerReflectionRequest_FileContainingSymbol{x}
+		return true, err
+	case 5: // message_request.file_containing_extension
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ExtensionRequest)
+		err := b.DecodeMessage(msg)
+		m.MessageRequest = &ServerReflectionRequest_FileContainingExtension{msg}
+		return true, err
+	case 6: // message_request.all_extension_numbers_of_type
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.MessageRequest = &ServerReflectionRequest_AllExtensionNumbersOfType{x}
+		return true, err
+	case 7: // message_request.list_services
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.MessageRequest = &ServerReflectionRequest_ListServices{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _ServerReflectionRequest_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*ServerReflectionRequest)
+	// message_request
+	switch x := m.MessageRequest.(type) {
+	case *ServerReflectionRequest_FileByFilename:
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.FileByFilename)))
+		n += len(x.FileByFilename)
+	case *ServerReflectionRequest_FileContainingSymbol:
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.FileContainingSymbol)))
+		n += len(x.FileContainingSymbol)
+	case *ServerReflectionRequest_FileContainingExtension:
+		s := proto.Size(x.FileContainingExtension)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ServerReflectionRequest_AllExtensionNumbersOfType:
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.AllExtensionNumbersOfType)))
+		n += len(x.AllExtensionNumbersOfType)
+	case *ServerReflectionRequest_ListServices:
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.ListServices)))
+		n += len(x.ListServices)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// ExtensionRequest identifies an extension of a message type
+type ExtensionRequest struct {
+	ContainingType  string `protobuf:"bytes,1,opt,name=containing_type,json=containingType" json:"containing_type,omitempty"`
+	ExtensionNumber int32  `protobuf:"varint,2,opt,name=extension_number,json=extensionNumber" json:"extension_number,omitempty"`
+}
+
+func (m *ExtensionRequest) Reset()                    { *m = ExtensionRequest{} }
+func (m *ExtensionRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExtensionRequest) ProtoMessage()               {}
+func (*ExtensionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ExtensionRequest) GetContainingType() string {
+	if m != nil {
+		return m.ContainingType
+	}
+	return ""
+}
+
+func (m *ExtensionRequest) GetExtensionNumber() int32 {
+	if m != nil {
+		return m.ExtensionNumber
+	}
+	return 0
+}
+
+// ServerReflectionResponse is the message sent by the server to answer a
+// ServerReflectionRequest
+type ServerReflectionResponse struct {
+	ValidHost       string                   `protobuf:"bytes,1,opt,name=valid_host,json=validHost" json:"valid_host,omitempty"`
+	OriginalRequest *ServerReflectionRequest `protobuf:"bytes,2,opt,name=original_request,json=originalRequest" json:"original_request,omitempty"`
+	// Types that are valid to be assigned to MessageResponse:
+	//	*ServerReflectionResponse_FileDescriptorResponse
+	//	*ServerReflectionResponse_AllExtensionNumbersResponse
+	//	*ServerReflectionResponse_ListServicesResponse
+	//	*ServerReflectionResponse_ErrorResponse
+	MessageResponse isServerReflectionResponse_MessageResponse `protobuf_oneof:"message_response"`
+}
+
+func (m *ServerReflectionResponse) Reset()                    { *m = ServerReflectionResponse{} }
+func (m *ServerReflectionResponse) String() string            { return proto.CompactTextString(m) }
+func (*ServerReflectionResponse) ProtoMessage()               {}
+func (*ServerReflectionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type isServerReflectionResponse_MessageResponse interface {
+	isServerReflectionResponse_MessageResponse()
+}
+
+type ServerReflectionResponse_FileDescriptorResponse struct {
+	FileDescriptorResponse *FileDescriptorResponse `protobuf:"bytes,4,opt,name=file_descriptor_response,json=fileDescriptorResponse,oneof"`
+}
+type ServerReflectionResponse_AllExtensionNumbersResponse struct {
+	AllExtensionNumbersResponse *ExtensionNumberResponse `protobuf:"bytes,5,opt,name=all_extension_numbers_response,json=allExtensionNumbersResponse,oneof"`
+}
+type ServerReflectionResponse_ListServicesResponse struct {
+	ListServicesResponse *ListServiceResponse `protobuf:"bytes,6,opt,name=list_services_response,json=listServicesResponse,oneof"`
+}
+type ServerReflectionResponse_ErrorResponse struct {
+	ErrorResponse *ErrorResponse `protobuf:"bytes,7,opt,name=error_response,json=errorResponse,oneof"`
+}
+
+func (*ServerReflectionResponse_FileDescriptorResponse) isServerReflectionResponse_MessageResponse() {
+}
+func (*ServerReflectionResponse_AllExtensionNumbersResponse) isServerReflectionResponse_MessageResponse() {
+}
+func (*ServerReflectionResponse_ListServicesResponse) isServerReflectionResponse_MessageResponse() {}
+func (*ServerReflectionResponse_ErrorResponse) isServerReflectionResponse_MessageResponse()        {}
+
+func (m *ServerReflectionResponse) GetMessageResponse() isServerReflectionResponse_MessageResponse {
+	if m != nil {
+		return m.MessageResponse
+	}
+	return nil
+}
+
+func (m *ServerReflectionResponse) GetValidHost() string {
+	if m != nil {
+		return m.ValidHost
+	}
+	return ""
+}
+
+func (m *ServerReflectionResponse) GetOriginalRequest() *ServerReflectionRequest {
+	if m != nil {
+		return m.OriginalRequest
+	}
+	return nil
+}
+
+func (m *ServerReflectionResponse) GetFileDescriptorResponse() *FileDescriptorResponse {
+	if x, ok := m.GetMessageResponse().(*ServerReflectionResponse_FileDescriptorResponse); ok {
+		return x.FileDescriptorResponse
+	}
+	return nil
+}
+
+func (m *ServerReflectionResponse) GetAllExtensionNumbersResponse() *ExtensionNumberResponse {
+	if x, ok := m.GetMessageResponse().(*ServerReflectionResponse_AllExtensionNumbersResponse); ok {
+		return x.AllExtensionNumbersResponse
+	}
+	return nil
+}
+
+func (m *ServerReflectionResponse) GetListServicesResponse() *ListServiceResponse {
+	if x, ok := m.GetMessageResponse().(*ServerReflectionResponse_ListServicesResponse); ok {
+		return x.ListServicesResponse
+	}
+	return nil
+}
+
+func (m *ServerReflectionResponse) GetErrorResponse() *ErrorResponse {
+	if x, ok := m.GetMessageResponse().(*ServerReflectionResponse_ErrorResponse); ok {
+		return x.ErrorResponse
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*ServerReflectionResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _ServerReflectionResponse_OneofMarshaler, _ServerReflectionResponse_OneofUnmarshaler, _ServerReflectionResponse_OneofSizer, []interface{}{
+		(*ServerReflectionResponse_FileDescriptorResponse)(nil),
+		(*ServerReflectionResponse_AllExtensionNumbersResponse)(nil),
+		(*ServerReflectionResponse_ListServicesResponse)(nil),
+		(*ServerReflectionResponse_ErrorResponse)(nil),
+	}
+}
+
+func _ServerReflectionResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*ServerReflectionResponse)
+	// message_response
+	switch x := m.MessageResponse.(type) {
+	case *ServerReflectionResponse_FileDescriptorResponse:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.FileDescriptorResponse); err != nil {
+			return err
+		}
+	case *ServerReflectionResponse_AllExtensionNumbersResponse:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AllExtensionNumbersResponse); err != nil {
+			return err
+		}
+	case *ServerReflectionResponse_ListServicesResponse:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ListServicesResponse); err != nil {
+			return err
+		}
+	case *ServerReflectionResponse_ErrorResponse:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ErrorResponse); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("ServerReflectionResponse.MessageResponse has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _ServerReflectionResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*ServerReflectionResponse)
+	switch tag {
+	case 4: // message_response.file_descriptor_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(FileDescriptorResponse)
+		err := b.DecodeMessage(msg)
+		m.MessageResponse = &ServerReflectionResponse_FileDescriptorResponse{msg}
+		return true, err
+	case 5: // message_response.all_extension_numbers_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ExtensionNumberResponse)
+		err := b.DecodeMessage(msg)
+		m.MessageResponse = &ServerReflectionResponse_AllExtensionNumbersResponse{msg}
+		return true, err
+	case 6: // message_response.list_services_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ListServiceResponse)
+		err := b.DecodeMessage(msg)
+		m.MessageResponse = &ServerReflectionResponse_ListServicesResponse{msg}
+		return true, err
+	case 7: // message_response.error_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ErrorResponse)
+		err := b.DecodeMessage(msg)
+		m.MessageResponse = &ServerReflectionResponse_ErrorResponse{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _ServerReflectionResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*ServerReflectionResponse)
+	// message_response
+	switch x := m.MessageResponse.(type) {
+	case *ServerReflectionResponse_FileDescriptorResponse:
+		s := proto.Size(x.FileDescriptorResponse)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ServerReflectionResponse_AllExtensionNumbersResponse:
+		s := proto.Size(x.AllExtensionNumbersResponse)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ServerReflectionResponse_ListServicesResponse:
+		s := proto.Size(x.ListServicesResponse)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ServerReflectionResponse_ErrorResponse:
+		s := proto.Size(x.ErrorResponse)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// FileDescriptorResponse holds serialized FileDescriptorProtos, the file
+// requested followed by the files it depends on
+type FileDescriptorResponse struct {
+	FileDescriptorProto [][]byte `protobuf:"bytes,1,rep,name=file_descriptor_proto,json=fileDescriptorProto,proto3" json:"file_descriptor_proto,omitempty"`
+}
+
+func (m *FileDescriptorResponse) Reset()                    { *m = FileDescriptorResponse{} }
+func (m *FileDescriptorResponse) String() string            { return proto.CompactTextString(m) }
+func (*FileDescriptorResponse) ProtoMessage()               {}
+func (*FileDescriptorResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *FileDescriptorResponse) GetFileDescriptorProto() [][]byte {
+	if m != nil {
+		return m.FileDescriptorProto
+	}
+	return nil
+}
+
+// ExtensionNumberResponse holds the extension numbers of a message type
+type ExtensionNumberResponse struct {
+	BaseTypeName    string  `protobuf:"bytes,1,opt,name=base_type_name,json=baseTypeName" json:"base_type_name,omitempty"`
+	ExtensionNumber []int32 `protobuf:"varint,2,rep,packed,name=extension_number,json=extensionNumber" json:"extension_number,omitempty"`
+}
+
+func (m *ExtensionNumberResponse) Reset()                    { *m = ExtensionNumberResponse{} }
+func (m *ExtensionNumberResponse) String() string            { return proto.CompactTextString(m) }
+func (*ExtensionNumberResponse) ProtoMessage()               {}
+func (*ExtensionNumberResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *ExtensionNumberResponse) GetBaseTypeName() string {
+	if m != nil {
+		return m.BaseTypeName
+	}
+	return ""
+}
+
+func (m *ExtensionNumberResponse) GetExtensionNumber() []int32 {
+	if m != nil {
+		return m.ExtensionNumber
+	}
+	return nil
+}
+
+// ListServiceResponse holds the services of the server
+type ListServiceResponse struct {
+	Service []*ServiceResponse `protobuf:"bytes,1,rep,name=service" json:"service,omitempty"`
+}
+
+func (m *ListServiceResponse) Reset()                    { *m = ListServiceResponse{} }
+func (m *ListServiceResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListServiceResponse) ProtoMessage()               {}
+func (*ListServiceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ListServiceResponse) GetService() []*ServiceResponse {
+	if m != nil {
+		return m.Service
+	}
+	return nil
+}
+
+// ServiceResponse names a service by its fully qualified name
+type ServiceResponse struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *ServiceResponse) Reset()                    { *m = ServiceResponse{} }
+func (m *ServiceResponse) String() string            { return proto.CompactTextString(m) }
+func (*ServiceResponse) ProtoMessage()               {}
+func (*ServiceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *ServiceResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+// ErrorResponse is returned in place of the response to a request which
+// cannot be answered
+type ErrorResponse struct {
+	ErrorCode    int32  `protobuf:"varint,1,opt,name=error_code,json=errorCode" json:"error_code,omitempty"`
+	ErrorMessage string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage" json:"error_message,omitempty"`
+}
+
+func (m *ErrorResponse) Reset()                    { *m = ErrorResponse{} }
+func (m *ErrorResponse) String() string            { return proto.CompactTextString(m) }
+func (*ErrorResponse) ProtoMessage()               {}
+func (*ErrorResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *ErrorResponse) GetErrorCode() int32 {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return 0
+}
+
+func (m *ErrorResponse) GetErrorMessage() string {
+	if m != nil {
+		return m.ErrorMessage
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*ServerReflectionRequest)(nil), "grpc.reflection.v1alpha.ServerReflectionRequest")
+	proto.RegisterType((*ExtensionRequest)(nil), "grpc.reflection.v1alpha.ExtensionRequest")
+	proto.RegisterType((*ServerReflectionResponse)(nil), "grpc.reflection.v1alpha.ServerReflectionResponse")
+	proto.RegisterType((*FileDescriptorResponse)(nil), "grpc.reflection.v1alpha.FileDescriptorResponse")
+	proto.RegisterType((*ExtensionNumberResponse)(nil), "grpc.reflection.v1alpha.ExtensionNumberResponse")
+	proto.RegisterType((*ListServiceResponse)(nil), "grpc.reflection.v1alpha.ListServiceResponse")
+	proto.RegisterType((*ServiceResponse)(nil), "grpc.reflection.v1alpha.ServiceResponse")
+	proto.RegisterType((*ErrorResponse)(nil), "grpc.reflection.v1alpha.ErrorResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for ServerReflection service
+
+type ServerReflectionClient interface {
+	// The reflection service is structured as a bidirectional stream, ensuring
+	// all related requests go to a single server
+	ServerReflectionInfo(ctx context.Context, opts ...grpc.CallOption) (ServerReflection_ServerReflectionInfoClient, error)
+}
+
+type serverReflectionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewServerReflectionClient(cc *grpc.ClientConn) ServerReflectionClient {
+	return &serverReflectionClient{cc}
+}
+
+func (c *serverReflectionClient) ServerReflectionInfo(ctx context.Context, opts ...grpc.CallOption) (ServerReflection_ServerReflectionInfoClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ServerReflection_serviceDesc.Streams[0], c.cc, "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serverReflectionServerReflectionInfoClient{stream}
+	return x, nil
+}
+
+type ServerReflection_ServerReflectionInfoClient interface {
+	Send(*ServerReflectionRequest) error
+	Recv() (*ServerReflectionResponse, error)
+	grpc.ClientStream
+}
+
+type serverReflectionServerReflectionInfoClient struct {
+	grpc.ClientStream
+}
+
+func (x *serverReflectionServerReflectionInfoClient) Send(m *ServerReflectionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serverReflectionServerReflectionInfoClient) Recv() (*ServerReflectionResponse, error) {
+	m := new(ServerReflectionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for ServerReflection service
+
+type ServerReflectionServer interface {
+	// The reflection service is structured as a bidirectional stream, ensuring
+	// all related requests go to a single server
+	ServerReflectionInfo(ServerReflection_ServerReflectionInfoServer) error
+}
+
+func RegisterServerReflectionServer(s *grpc.Server, srv ServerReflectionServer) {
+	s.RegisterService(&_ServerReflection_serviceDesc, srv)
+}
+
+func _ServerReflection_ServerReflectionInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServerReflectionServer).ServerReflectionInfo(&serverReflectionServerReflectionInfoServer{stream})
+}
+
+type ServerReflection_ServerReflectionInfoServer interface {
+	Send(*ServerReflectionResponse) error
+	Recv() (*ServerReflectionRequest, error)
+	grpc.ServerStream
+}
+
+type serverReflectionServerReflectionInfoServer struct {
+	grpc.ServerStream
+}
+
+func (x *serverReflectionServerReflectionInfoServer) Send(m *ServerReflectionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serverReflectionServerReflectionInfoServer) Recv() (*ServerReflectionRequest, error) {
+	m := new(ServerReflectionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _ServerReflection_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc.reflection.v1alpha.ServerReflection",
+	HandlerType: (*ServerReflectionServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ServerReflectionInfo",
+			Handler:       _ServerReflection_ServerReflectionInfo_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "reflection/reflection.proto",
+}
+
+func init() { proto.RegisterFile("reflection/reflection.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 695 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0x51, 0x73, 0xd2, 0x40,
+	0x10, 0x26, 0x2d, 0xb4, 0xc3, 0x42, 0x01, 0xaf, 0x15, 0x52, 0x3b, 0x75, 0x18, 0xb4, 0x4a, 0x1d,
+	0x07, 0x5a, 0x9c, 0xf1, 0x07, 0x50, 0x75, 0x70, 0xa6, 0xb6, 0x4e, 0xf0, 0x45, 0xfb, 0x90, 0x49,
+	0xc2, 0x26, 0x44, 0x43, 0x2e, 0xde, 0xa5, 0x28, 0x8f, 0xfe, 0x09, 0xff, 0x92, 0x7f, 0xc9, 0x47,
+	0x27, 0x97, 0x10, 0x8e, 0x48, 0x74, 0xfa, 0xc4, 0xcd, 0xb7, 0xb7, 0xf7, 0xed, 0xee, 0xf7, 0x2d,
+	0x81, 0x23, 0x86, 0xb6, 0x87, 0x56, 0xe8, 0x52, 0xbf, 0xbf, 0x3a, 0xf6, 0x02, 0x46, 0x43, 0x4a,
+	0x5a, 0x0e, 0x0b, 0xac, 0x9e, 0x04, 0xcf, 0xcf, 0x0d, 0x2f, 0x98, 0x1a, 0x9d, 0xdf, 0x5b, 0xd0,
+	0x1a, 0x23, 0x9b, 0x23, 0xd3, 0xd2, 0xa0, 0x86, 0x5f, 0x6f, 0x91, 0x87, 0x84, 0x40, 0x71, 0x4a,
+	0x79, 0xa8, 0x2a, 0x6d, 0xa5, 0x5b, 0xd6, 0xc4, 0x99, 0x3c, 0x83, 0x86, 0xed, 0x7a, 0xa8, 0x9b,
+	0x0b, 0x3d, 0xfa, 0xf5, 0x8d, 0x19, 0xaa, 0xdb, 0x51, 0x7c, 0x54, 0xd0, 0x6a, 0x11, 0x32, 0x5c,
+	0xbc, 0x49, 0x70, 0xf2, 0x12, 0x9a, 0xe2, 0xae, 0x45, 0xfd, 0xd0, 0x70, 0x7d, 0xd7, 0x77, 0x74,
+	0xbe, 0x98, 0x99, 0xd4, 0x53, 0x8b, 0x49, 0xc6, 0x41, 0x14, 0xbf, 0x48, 0xc3, 0x63, 0x11, 0x25,
+	0x0e, 0x1c, 0x66, 0xf3, 0xf0, 0x7b, 0x88, 0x3e, 0x77, 0xa9, 0xaf, 0x96, 0xda, 0x4a, 0xb7, 0x32,
+	0x38, 0xed, 0xe5, 0x34, 0xd4, 0x7b, 0xbd, 0xbc, 0x99, 0x74, 0x31, 0x2a, 0x68, 0xad, 0x75, 0x96,
+	0xf4, 0x06, 0x19, 0xc2, 0xb1, 0xe1, 0x79, 0xab, 0xc7, 0x75, 0xff, 0x76, 0x66, 0x22, 0xe3, 0x3a,
+	0xb5, 0xf5, 0x70, 0x11, 0xa0, 0xba, 0x93, 0xd4, 0x79, 0x68, 0x78, 0x5e, 0x9a, 0x76, 0x15, 0x5f,
+	0xba, 0xb6, 0x3f, 0x2c, 0x02, 0x24, 0x27, 0xb0, 0xe7, 0xb9, 0x3c, 0xd4, 0x39, 0xb2, 0xb9, 0x6b,
+	0x21, 0x57, 0x77, 0x93, 0x9c, 0x6a, 0x04, 0x8f, 0x13, 0x74, 0x78, 0x0f, 0xea, 0x33, 0xe4, 0xdc,
+	0x70, 0x50, 0x67, 0x71, 0x61, 0x1d, 0x1b, 0x1a, 0xd9, 0x62, 0xc9, 0x53, 0xa8, 0x4b, 0x5d, 0x8b,
+	0x1a, 0xe2, 0xe9, 0xd7, 0x56, 0xb0, 0xa0, 0x3d, 0x85, 0x46, 0xb6, 0x6c, 0x75, 0xab, 0xad, 0x74,
+	0x4b, 0x5a, 0x1d, 0xd7, 0x0b, 0xed, 0xfc, 0x2a, 0x82, 0xfa, 0xb7, 0xc4, 0x3c, 0xa0, 0x3e, 0x47,
+	0x72, 0x0c, 0x30, 0x37, 0x3c, 0x77, 0xa2, 0x4b, 0x4a, 0x97, 0x05, 0x32, 0x8a, 0xe4, 0xbe, 0x81,
+	0x06, 0x65, 0xae, 0xe3, 0xfa, 0x86, 0xb7, 0xac, 0x5b, 0xd0, 0x54, 0x06, 0x67, 0xb9, 0x0a, 0xe4,
+	0xd8, 0x49, 0xab, 0x2f, 0x5f, 0x5a, 0x36, 0xfb, 0x05, 0x54, 0xa1, 0xf3, 0x04, 0xb9, 0xc5, 0xdc,
+	0x20, 0xa4, 0x4c, 0x67, 0x49, 0x5d, 0xc2, 0x21, 0x95, 0x41, 0x3f, 0x97, 0x24, 0x32, 0xd9, 0xab,
+	0x34, 0x6f, 0xd9, 0xce, 0xa8, 0xa0, 0x35, 0xed, 0x8d, 0x11, 0xf2, 0x0d, 0x1e, 0x6e, 0xd6, 0x3a,
+	0xa5, 0x2c, 0xfd, 0xa7, 0xaf, 0x8c, 0x01, 0x24, 0xce, 0xa3, 0x0d, 0xf6, 0x48, 0x89, 0x27, 0xd0,
+	0x5c, 0x33, 0xc8, 0x8a, 0x70, 0x47, 0x10, 0x3e, 0xcf, 0x25, 0xbc, 0x5c, 0x19, 0x48, 0x22, 0x3b,
+	0x90, 0x7d, 0x95, 0xb2, 0x5c, 0x43, 0x0d, 0x19, 0x93, 0x27, 0xb8, 0x2b, 0x5e, 0x7f, 0x92, 0xdf,
+	0x4e, 0x74, 0x5d, 0x7a, 0x77, 0x0f, 0x65, 0x60, 0x48, 0xa0, 0xb1, 0x32, 0x6c, 0x8c, 0x75, 0x2e,
+	0xa1, 0xb9, 0x79, 0xee, 0x64, 0x00, 0xf7, 0xb3, 0x52, 0x8a, 0x3f, 0x1e, 0x55, 0x69, 0x6f, 0x77,
+	0xab, 0xda, 0xfe, 0xba, 0x28, 0xef, 0xa3, 0x50, 0xe7, 0x33, 0xb4, 0x72, 0x46, 0x4a, 0x1e, 0x43,
+	0xcd, 0x34, 0x38, 0x8a, 0x05, 0xd0, 0xc5, 0x7f, 0x4c, 0xec, 0xcc, 0x6a, 0x84, 0x46, 0xfe, 0xbf,
+	0x32, 0x66, 0x79, 0x3b, 0xb0, 0xbd, 0x69, 0x07, 0x3e, 0xc2, 0xfe, 0x86, 0x69, 0x92, 0x21, 0xec,
+	0x26, 0xb2, 0x88, 0x42, 0x2b, 0x83, 0xee, 0x3f, 0x5d, 0x2d, 0xa5, 0x6a, 0xcb, 0xc4, 0xce, 0x09,
+	0xd4, 0xb3, 0xcf, 0x12, 0x28, 0x4a, 0x45, 0x8b, 0x73, 0x67, 0x0c, 0x7b, 0x6b, 0x13, 0x8f, 0x36,
+	0x2f, 0x56, 0xcc, 0xa2, 0x93, 0xf8, 0x6a, 0x49, 0x2b, 0x0b, 0xe4, 0x82, 0x4e, 0x90, 0x3c, 0x82,
+	0x58, 0x10, 0x3d, 0x51, 0x41, 0xac, 0x5d, 0x59, 0xab, 0x0a, 0xf0, 0x5d, 0x8c, 0x0d, 0x7e, 0x2a,
+	0xd0, 0xc8, 0xae, 0x1b, 0xf9, 0xa1, 0xc0, 0x41, 0x16, 0x7c, 0xeb, 0xdb, 0x94, 0xdc, 0x79, 0x65,
+	0x1f, 0x9c, 0xdf, 0x21, 0x23, 0xf1, 0x48, 0xa1, 0xab, 0x9c, 0x29, 0xc3, 0x1b, 0xe8, 0x52, 0xe6,
+	0xf4, 0xa6, 0x8b, 0x00, 0x99, 0x87, 0x13, 0x07, 0x59, 0xcf, 0x36, 0x4c, 0xe6, 0x5a, 0xf1, 0xf7,
+	0x88, 0x4b, 0x4f, 0x7e, 0xea, 0x3b, 0x6e, 0x38, 0xbd, 0x35, 0x7b, 0x16, 0x9d, 0xf5, 0xa5, 0x84,
+	0x7e, 0x9c, 0xd0, 0x8f, 0x13, 0xa4, 0x4f, 0x9a, 0xb9, 0x23, 0xa0, 0x17, 0x7f, 0x06, 0x00, 0x88,
+	0xbc, 0xa2, 0x72, 0xf2, 0x06, 0x00, 0x00,
+}
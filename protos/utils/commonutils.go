@@ -102,6 +102,10 @@ func UnmarshalEnvelope(encoded []byte) (*cb.Envelope, error) {
 // UnmarshalEnvelopeOfType unmarshals an envelope of the specified type, including
 // the unmarshaling the payload data
 func UnmarshalEnvelopeOfType(envelope *cb.Envelope, headerType cb.HeaderType, message proto.Message) (*cb.ChannelHeader, error) {
+	if envelope == nil {
+		return nil, fmt.Errorf("Envelope must not be nil")
+	}
+
 	payload, err := UnmarshalPayload(envelope.Payload)
 	if err != nil {
 		return nil, err
@@ -90,10 +90,13 @@ func TestUnmarshalEnvelope(t *testing.T) {
 }
 
 func TestUnmarshalEnvelopeOfType(t *testing.T) {
+	_, err := UnmarshalEnvelopeOfType(nil, cb.HeaderType_CONFIG, nil)
+	assert.Error(t, err, "Expected error unmarshaling nil envelope")
+
 	env := &cb.Envelope{}
 
 	env.Payload = []byte("bad payload")
-	_, err := UnmarshalEnvelopeOfType(env, cb.HeaderType_CONFIG, nil)
+	_, err = UnmarshalEnvelopeOfType(env, cb.HeaderType_CONFIG, nil)
 	assert.Error(t, err, "Expected error unmarshaling malformed envelope")
 
 	payload, _ := proto.Marshal(&cb.Payload{